@@ -0,0 +1,138 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithAllowedContextKeys restricts the context keys targeting rules may reference, through var operations,
+// fractional evaluations and missing operations, to the provided keys and their nested paths, e.g. user allows
+// user.email. Configurations referencing other keys are rejected when loaded. Any key may be referenced when empty.
+func WithAllowedContextKeys(keys []string) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if len(keys) == 0 {
+			return
+		}
+		je.allowedContextKeys = make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			je.allowedContextKeys[key] = struct{}{}
+		}
+	}
+}
+
+// validateContextKeys fails if the rule references context keys which aren't allowed, naming every such key. Var
+// operations without a literal path, computed or referencing the whole context, are rejected as the keys they
+// reference are only known when evaluated.
+func (je *JSONEvaluator) validateContextKeys(rule interface{}) error {
+	if je.allowedContextKeys == nil {
+		return nil
+	}
+	referenced := map[string]struct{}{}
+	unbounded := collectGovernedContextKeys(rule, referenced, false)
+
+	var disallowed []string
+	for path := range referenced {
+		if !je.contextKeyAllowed(path) {
+			disallowed = append(disallowed, fmt.Sprintf("'%s'", path))
+		}
+	}
+	switch {
+	case len(disallowed) > 0:
+		sort.Strings(disallowed)
+		return fmt.Errorf("context keys which aren't allowed: %s", strings.Join(disallowed, ", "))
+	case unbounded:
+		return errors.New("var operations without a literal path may reference context keys which aren't allowed")
+	}
+	return nil
+}
+
+// contextKeyAllowed reports whether the context path is an allowed key or nested within one
+func (je *JSONEvaluator) contextKeyAllowed(path string) bool {
+	for key := path; ; {
+		if _, ok := je.allowedContextKeys[key]; ok {
+			return true
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			return false
+		}
+		key = key[:i]
+	}
+}
+
+// iteratingOperators are the json-logic operators evaluating their second argument against each item of their
+// first argument, whose var operations reference the item rather than the context
+var iteratingOperators = map[string]struct{}{
+	"some": {}, "all": {}, "none": {}, "filter": {}, "map": {}, "reduce": {},
+}
+
+// collectGovernedContextKeys adds the context keys referenced by the var, fractional evaluation and missing
+// operations of the rule to the referenced keys, and returns whether a var operation has no literal path. Operations
+// evaluated against the items of iterating operators don't reference the context.
+func collectGovernedContextKeys(rule interface{}, referenced map[string]struct{}, item bool) bool {
+	unbounded := false
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for operator, args := range r {
+			if !item {
+				unbounded = addGovernedContextKeys(operator, args, referenced) || unbounded
+			}
+			list, ok := args.([]interface{})
+			if _, iterating := iteratingOperators[operator]; !iterating || !ok {
+				unbounded = collectGovernedContextKeys(args, referenced, item) || unbounded
+				continue
+			}
+			for i, arg := range list {
+				unbounded = collectGovernedContextKeys(arg, referenced, item || i == 1) || unbounded
+			}
+		}
+	case []interface{}:
+		for _, v := range r {
+			unbounded = collectGovernedContextKeys(v, referenced, item) || unbounded
+		}
+	}
+	return unbounded
+}
+
+// addGovernedContextKeys adds the context keys referenced by an operation, returning whether it's a var operation
+// without a literal path
+func addGovernedContextKeys(operator string, args interface{}, referenced map[string]struct{}) bool {
+	switch operator {
+	case varOperator:
+		path, ok := varPath(args)
+		if !ok || path == "" {
+			return true
+		}
+		addContextKeys([]interface{}{path}, referenced)
+	case fractionalEvaluationOperator:
+		if list, ok := args.([]interface{}); ok && len(list) > 0 {
+			for _, bucketBy := range bucketingKeys(list[0]) {
+				referenced[bucketBy] = struct{}{}
+			}
+		}
+	case "missing":
+		if list, ok := args.([]interface{}); ok {
+			addContextKeys(list, referenced)
+		} else {
+			addContextKeys([]interface{}{args}, referenced)
+		}
+	case "missing_some":
+		if list, ok := args.([]interface{}); ok && len(list) == 2 {
+			if keys, ok := list[1].([]interface{}); ok {
+				addContextKeys(keys, referenced)
+			}
+		}
+	}
+	return false
+}
+
+// addContextKeys adds the literal context paths to the referenced keys, flagd properties aren't part of the context
+func addContextKeys(keys []interface{}, referenced map[string]struct{}) {
+	for _, key := range keys {
+		if path, ok := key.(string); ok && !strings.HasPrefix(path, flagdPropertiesKey) {
+			referenced[path] = struct{}{}
+		}
+	}
+}
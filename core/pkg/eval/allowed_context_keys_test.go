@@ -0,0 +1,130 @@
+package eval_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+)
+
+func allowedContextKeysConfig(targeting string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": %s
+    }
+  }
+}`, targeting)
+}
+
+func TestAllowedContextKeys(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		err       string
+	}{
+		"allowed key": {
+			targeting: `{"if": [{"==": [{"var": "plan"}, "premium"]}, "blue", "red"]}`,
+		},
+		"nested path of an allowed key": {
+			targeting: `{"if": [{"==": [{"var": "user.country"}, "fr"]}, "blue", "red"]}`,
+		},
+		"var with a default value": {
+			targeting: `{"if": [{"==": [{"var": ["plan", "free"]}, "premium"]}, "blue", "red"]}`,
+		},
+		"flagd properties": {
+			targeting: `{"if": [{">": [{"var": "$flagd.timestamp"}, 1700000000]}, "blue", "red"]}`,
+		},
+		"fractional bucketing by an allowed key": {
+			targeting: `{"fractionalEvaluation": ["targetingKey", ["red", 50], ["blue", 50]]}`,
+		},
+		"items of iterating operators": {
+			targeting: `{"if": [{"some": [{"var": "user.roles"}, {"==": [{"var": ""}, "admin"]}]}, "blue", "red"]}`,
+		},
+		"disallowed key": {
+			targeting: `{"if": [{"==": [{"var": "email"}, "jane@faas.com"]}, "blue", "red"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'email'",
+		},
+		"every disallowed key is named": {
+			targeting: `{"if": [{"and": [{"var": "ssn"}, {"in": [{"var": "email"}, ["a", "b"]]}]}, "blue", "red"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'email', 'ssn'",
+		},
+		"prefix of an allowed key": {
+			targeting: `{"if": [{"==": [{"var": "users"}, "x"]}, "blue", "red"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'users'",
+		},
+		"parent of an allowed path": {
+			targeting: `{"if": [{"==": [{"var": "account"}, "x"]}, "blue", "red"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'account'",
+		},
+		"fractional bucketing by a disallowed key": {
+			targeting: `{"fractionalEvaluation": [["targetingKey", "email"], ["red", 50], ["blue", 50]]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'email'",
+		},
+		"missing operation": {
+			targeting: `{"if": [{"missing": ["plan", "ssn"]}, "red", "blue"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'ssn'",
+		},
+		"missing some operation": {
+			targeting: `{"if": [{"missing_some": [1, ["plan", "ssn"]]}, "red", "blue"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'ssn'",
+		},
+		"whole context": {
+			targeting: `{"if": [{"==": [{"var": ""}, "x"]}, "blue", "red"]}`,
+			err: "targeting of flag: 'headerColor': var operations without a literal path may reference context " +
+				"keys which aren't allowed",
+		},
+		"computed path": {
+			targeting: `{"if": [{"==": [{"var": {"cat": ["e", "mail"]}}, "x"]}, "blue", "red"]}`,
+			err: "targeting of flag: 'headerColor': var operations without a literal path may reference context " +
+				"keys which aren't allowed",
+		},
+		"first argument of iterating operators": {
+			targeting: `{"if": [{"some": [{"var": "groups"}, {"==": [{"var": ""}, "admin"]}]}, "blue", "red"]}`,
+			err:       "targeting of flag: 'headerColor': context keys which aren't allowed: 'groups'",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := eval.NewJSONEvaluatorFromConfig(nil, allowedContextKeysConfig(tt.targeting),
+				eval.WithAllowedContextKeys([]string{"plan", "user", "account.id", "targetingKey"}))
+			if tt.err == "" {
+				require.Nil(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAllowedContextKeys_Unrestricted(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, allowedContextKeysConfig(
+		`{"if": [{"==": [{"var": {"cat": ["e", "mail"]}}, "jane@faas.com"]}, "blue", "red"]}`))
+	require.Nil(t, err, "any key should be referenced without an allowlist")
+
+	_, err = eval.NewJSONEvaluatorFromConfig(nil, allowedContextKeysConfig(
+		`{"if": [{"==": [{"var": "email"}, "jane@faas.com"]}, "blue", "red"]}`), eval.WithAllowedContextKeys(nil))
+	require.Nil(t, err)
+}
+
+func TestAllowedContextKeys_Rulesets(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "rulesets": {
+        "contextKey": "plan",
+        "rules": {
+          "premium": { "if": [{ "==": [{ "var": "ssn" }, "x"] }, "blue", "red"] }
+        }
+      }
+    }
+  }
+}`, eval.WithAllowedContextKeys([]string{"plan"}))
+	require.ErrorContains(t, err, "context keys which aren't allowed: 'ssn'")
+}
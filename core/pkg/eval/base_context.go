@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// baseContextKey is the flag property holding its base context, inline or referenced by its name in $baseContexts
+const baseContextKey = "baseContext"
+
+// resolveBaseContexts replaces the base contexts the flag definitions reference by name with the values of the base
+// context, so stored flags hold their base context inline. Referencing a base context which isn't defined fails the
+// configuration.
+func resolveBaseContexts(
+	baseContexts map[string]json.RawMessage, definitions map[string]json.RawMessage,
+) (map[string]json.RawMessage, error) {
+	for key, raw := range definitions {
+		var definition map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &definition); err != nil {
+			// invalid definitions are reported by their validation
+			continue
+		}
+		var name string
+		if err := json.Unmarshal(definition[baseContextKey], &name); err != nil {
+			// inline base contexts are kept as they are
+			continue
+		}
+		baseContext, ok := baseContexts[name]
+		if !ok {
+			return nil, fmt.Errorf("flag: '%s' references the base context: '%s', which isn't defined in "+
+				"$baseContexts", key, name)
+		}
+		definition[baseContextKey] = baseContext
+		resolved, err := json.Marshal(definition)
+		if err != nil {
+			return nil, fmt.Errorf("resolving the base context of flag: '%s': %w", key, err)
+		}
+		definitions[key] = resolved
+	}
+	return definitions, nil
+}
+
+// withBaseContext returns the evaluation context merged over the base context of the flag, values of the evaluation
+// context taking precedence and nested objects being merged
+func withBaseContext(flag model.Flag, context *structpb.Struct) *structpb.Struct {
+	if len(flag.BaseContext) == 0 {
+		return context
+	}
+	return mergeBaseContext(flag.BaseContext, context)
+}
+
+func mergeBaseContext(base map[string]interface{}, context *structpb.Struct) *structpb.Struct {
+	merged := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(base)+len(context.GetFields()))}
+	for key, value := range context.GetFields() {
+		merged.Fields[key] = value
+	}
+	for key, value := range base {
+		current, ok := merged.Fields[key]
+		switch nested := value.(type) {
+		case map[string]interface{}:
+			if !ok {
+				merged.Fields[key] = structpb.NewStructValue(mergeBaseContext(nested, nil))
+			} else if object := current.GetStructValue(); object != nil {
+				merged.Fields[key] = structpb.NewStructValue(mergeBaseContext(nested, object))
+			}
+		default:
+			if ok {
+				continue
+			}
+			// base contexts are decoded json, which are representable
+			if converted, err := structpb.NewValue(value); err == nil {
+				merged.Fields[key] = converted
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,127 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const baseContextFlagConfig = `{
+  "$baseContexts": {
+    "checkoutExperiment": {
+      "experiment": "checkout",
+      "region": "eu",
+      "user": { "plan": "free", "country": "fr" }
+    }
+  },
+  "flags": {
+    "checkoutCopy": {
+      "state": "ENABLED",
+      "variants": { "control": "Buy", "treatment": "Buy now" },
+      "defaultVariant": "control",
+      "baseContext": "checkoutExperiment",
+      "targeting": { "if": [
+        { "and": [
+          { "==": [{ "var": "experiment" }, "checkout"] },
+          { "==": [{ "var": "region" }, "eu"] },
+          { "==": [{ "var": "user.plan" }, "premium"] },
+          { "==": [{ "var": "user.country" }, "fr"] }
+        ] },
+        "treatment", "control"
+      ] }
+    },
+    "checkoutButton": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "baseContext": "checkoutExperiment",
+      "requireContext": ["experiment"],
+      "targeting": { "if": [{ "==": [{ "var": "region" }, "eu"] }, "on", "off"] }
+    },
+    "inlineBase": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "baseContext": { "region": "us" },
+      "targeting": { "if": [{ "==": [{ "var": "region" }, "us"] }, "on", "off"] }
+    },
+    "withoutBase": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "region" }, "eu"] }, "on", "off"] }
+    }
+  }
+}`
+
+func TestBaseContext(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, baseContextFlagConfig)
+	require.Nil(t, err)
+	resolveString := func(values map[string]interface{}) (string, string) {
+		t.Helper()
+		evalCtx, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "checkoutCopy", evalCtx)
+		require.Nil(t, err)
+		return value, reason
+	}
+
+	value, _ := resolveString(map[string]interface{}{"user": map[string]interface{}{"plan": "premium"}})
+	require.Equal(t, "Buy now", value, "nested request values should be merged over the base context")
+
+	value, _ = resolveString(map[string]interface{}{})
+	require.Equal(t, "Buy", value, "base values should apply without request values")
+
+	value, _ = resolveString(map[string]interface{}{
+		"region": "us", "user": map[string]interface{}{"plan": "premium"},
+	})
+	require.Equal(t, "Buy", value, "request values should take precedence over the base context")
+
+	value, _ = resolveString(map[string]interface{}{"user": "premium"})
+	require.Equal(t, "Buy", value, "request values replacing a base object should take precedence")
+
+	tests := map[string]struct {
+		flagKey string
+		context map[string]interface{}
+		want    bool
+	}{
+		"required key of the base context": {flagKey: "checkoutButton", want: true},
+		"inline base context":              {flagKey: "inlineBase", want: true},
+		"inline base context overridden":   {flagKey: "inlineBase", context: map[string]interface{}{"region": "eu"}},
+		"flag without base context":        {flagKey: "withoutBase"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", tt.flagKey, evalCtx)
+			require.Nil(t, err)
+			require.Equal(t, model.TargetingMatchReason, reason)
+			require.Equal(t, tt.want, value)
+		})
+	}
+
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Contains(t, state, `"baseContext":{"experiment":"checkout"`,
+		"flags should hold the base context they reference inline")
+}
+
+func TestBaseContext_MissingReference(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "checkoutCopy": {
+      "state": "ENABLED",
+      "variants": { "control": "Buy", "treatment": "Buy now" },
+      "defaultVariant": "control",
+      "baseContext": "checkoutExperiment"
+    }
+  }
+}`)
+	require.EqualError(t, err, "flag: 'checkoutCopy' references the base context: 'checkoutExperiment', "+
+		"which isn't defined in $baseContexts")
+}
@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"github.com/zeebo/xxh3"
+)
+
+// BucketingHash is the hash function bucketing the context values of fractional evaluations, e.g. to assign the
+// buckets of another experimentation platform hashing the same values
+type BucketingHash string
+
+const (
+	// BucketingHashXXH3 buckets values by their 64-bit xxh3 hash, the default
+	BucketingHashXXH3 BucketingHash = "xxh3"
+	// BucketingHashMurmur3 buckets values by their 32-bit x86 murmur3 hash, seeded with 0
+	BucketingHashMurmur3 BucketingHash = "murmur3"
+	// BucketingHashFNV1a buckets values by their 32-bit fnv-1a hash
+	BucketingHashFNV1a BucketingHash = "fnv1a"
+)
+
+// ParseBucketingHash returns the bucketing hash of its name, an empty name defaults to xxh3
+func ParseBucketingHash(name string) (BucketingHash, error) {
+	switch BucketingHash(name) {
+	case "":
+		return BucketingHashXXH3, nil
+	case BucketingHashXXH3, BucketingHashMurmur3, BucketingHashFNV1a:
+		return BucketingHash(name), nil
+	default:
+		return "", fmt.Errorf("unknown bucketing hash: '%s', expected one of '%s', '%s' or '%s'",
+			name, BucketingHashXXH3, BucketingHashMurmur3, BucketingHashFNV1a)
+	}
+}
+
+// WithBucketingHash sets the hash function bucketing the context values of fractional evaluations. Changing it
+// reassigns most values to other buckets.
+func WithBucketingHash(hash BucketingHash) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.bucketingHash = hash
+	}
+}
+
+// ratio returns the hash of the value divided by the largest possible hash value plus one, in the range [0, 1)
+func (h BucketingHash) ratio(value string) float64 {
+	switch h {
+	case BucketingHashMurmur3:
+		return float64(murmur3Sum32([]byte(value), 0)) / math.Pow(2, 32)
+	case BucketingHashFNV1a:
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(value))
+		return float64(hash.Sum32()) / math.Pow(2, 32)
+	default:
+		return float64(xxh3.HashString(value)) / math.Pow(2, 64)
+	}
+}
+
+// murmur3Sum32 returns the 32-bit x86 murmur3 hash of the data
+func murmur3Sum32(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	blocks := len(data) / 4
+	for i := 0; i < blocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[blocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
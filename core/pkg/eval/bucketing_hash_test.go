@@ -0,0 +1,85 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestBucketingHash(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  map[BucketingHash]int
+	}{
+		"hello": {
+			value: "hello",
+			want:  map[BucketingHash]int{BucketingHashXXH3: 58, BucketingHashMurmur3: 14, BucketingHashFNV1a: 31},
+		},
+		"sentence": {
+			value: "The quick brown fox jumps over the lazy dog",
+			want:  map[BucketingHash]int{BucketingHashXXH3: 80, BucketingHashMurmur3: 18, BucketingHashFNV1a: 1},
+		},
+		"email": {
+			value: "user@faas.com",
+			want:  map[BucketingHash]int{BucketingHashXXH3: 90, BucketingHashMurmur3: 97, BucketingHashFNV1a: 59},
+		},
+		"short": {
+			value: "abc",
+			want:  map[BucketingHash]int{BucketingHashXXH3: 47, BucketingHashMurmur3: 70, BucketingHashFNV1a: 10},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for hash, want := range tt.want {
+				_, bucket := distributeValue(hash, tt.value, []fractionalEvaluationDistribution{{"on", 100}})
+				require.Equal(t, want, bucket, "bucket of %s", hash)
+			}
+			_, bucket := distributeValue("", tt.value, []fractionalEvaluationDistribution{{"on", 100}})
+			require.Equal(t, tt.want[BucketingHashXXH3], bucket, "the default hash should be xxh3")
+		})
+	}
+}
+
+func TestMurmur3Sum32(t *testing.T) {
+	// reference values of the x86 32-bit variant
+	require.Equal(t, uint32(0), murmur3Sum32([]byte(""), 0))
+	require.Equal(t, uint32(0x514e28b7), murmur3Sum32([]byte(""), 1))
+	require.Equal(t, uint32(0x248bfa47), murmur3Sum32([]byte("hello"), 0))
+	require.Equal(t, uint32(0x2e4ff723), murmur3Sum32([]byte("The quick brown fox jumps over the lazy dog"), 0))
+}
+
+func TestWithBucketingHash(t *testing.T) {
+	config := `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 20], ["blue", 80]] }
+    }
+  }
+}`
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "hello"})
+	require.Nil(t, err)
+	for hash, want := range map[BucketingHash]string{BucketingHashXXH3: "blue", BucketingHashMurmur3: "red"} {
+		evaluator, err := NewJSONEvaluatorFromConfig(nil, config, WithBucketingHash(hash))
+		require.Nil(t, err)
+		_, variant, _, metadata, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		require.Equal(t, want, variant, "variant bucketed by %s", hash)
+		require.Contains(t, metadata, BucketMetadataKey)
+	}
+}
+
+func TestParseBucketingHash(t *testing.T) {
+	hash, err := ParseBucketingHash("")
+	require.Nil(t, err)
+	require.Equal(t, BucketingHashXXH3, hash)
+	hash, err = ParseBucketingHash("murmur3")
+	require.Nil(t, err)
+	require.Equal(t, BucketingHashMurmur3, hash)
+	_, err = ParseBucketingHash("md5")
+	require.ErrorContains(t, err, "unknown bucketing hash: 'md5'")
+}
@@ -0,0 +1,38 @@
+package eval
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// FlagError is the error of a flag which failed to resolve in a bulk resolution
+type FlagError struct {
+	FlagKey string
+	// ErrorCode is the error code of the failed resolution, e.g. model.TypeMismatchErrorCode
+	ErrorCode string
+}
+
+// BulkErrors is implemented by evaluators reporting the flags which failed to resolve in bulk resolutions, which
+// ResolveAllValues leaves out of its values
+type BulkErrors interface {
+	// ResolveAllValuesWithErrors resolves every flag like ResolveAllValues, along with the errors of the flags which
+	// failed to resolve, sorted by flag key
+	ResolveAllValuesWithErrors(ctx context.Context, reqID string, context *structpb.Struct) ([]AnyValue, []FlagError)
+}
+
+// resolveAllWithErrors resolves every flag of the evaluator, along with the errors of the flags which failed to
+// resolve if the evaluator reports them
+func resolveAllWithErrors(
+	ctx context.Context, evaluator IEvaluator, reqID string, context *structpb.Struct,
+) ([]AnyValue, []FlagError) {
+	if bulk, ok := evaluator.(BulkErrors); ok {
+		return bulk.ResolveAllValuesWithErrors(ctx, reqID, context)
+	}
+	return evaluator.ResolveAllValues(ctx, reqID, context), nil
+}
+
+func sortFlagErrors(errs []FlagError) {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].FlagKey < errs[j].FlagKey })
+}
@@ -0,0 +1,87 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const bulkErrorsFlagConfig = `{
+  "flags": {
+    "resolvable": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "disabled": {
+      "state": "DISABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "entitlement": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "requireContext": ["plan"]
+    }
+  }
+}`
+
+func resolvedFlagKeys(values []eval.AnyValue) []string {
+	keys := make([]string, 0, len(values))
+	for _, value := range values {
+		keys = append(keys, value.FlagKey)
+	}
+	return keys
+}
+
+func TestResolveAllValuesWithErrors(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, bulkErrorsFlagConfig)
+	require.Nil(t, err)
+
+	values, errs := evaluator.ResolveAllValuesWithErrors(context.Background(), "", &structpb.Struct{})
+	require.Equal(t, []string{"resolvable"}, resolvedFlagKeys(values))
+	require.Equal(t, []eval.FlagError{
+		{FlagKey: "disabled", ErrorCode: model.FlagDisabledErrorCode},
+		{FlagKey: "entitlement", ErrorCode: model.MissingContextErrorCode},
+	}, errs)
+	require.Equal(t, values, evaluator.ResolveAllValues(context.Background(), "", &structpb.Struct{}),
+		"bulk resolutions should leave out the flags failing to resolve")
+}
+
+func TestResolveAllValuesWithErrors_Canary(t *testing.T) {
+	stable, err := eval.NewJSONEvaluatorFromConfig(nil, bulkErrorsFlagConfig)
+	require.Nil(t, err)
+	ce := eval.NewCanaryEvaluator(nil, stable, eval.NewJSONEvaluator(nil, nil), 0, nil)
+
+	values, errs := ce.ResolveAllValuesWithErrors(context.Background(), "", &structpb.Struct{})
+	require.Equal(t, []string{"resolvable"}, resolvedFlagKeys(values))
+	require.Len(t, errs, 2)
+}
+
+func TestResolveAllValuesWithErrors_Tenant(t *testing.T) {
+	shared, err := eval.NewJSONEvaluatorFromConfig(nil, bulkErrorsFlagConfig)
+	require.Nil(t, err)
+	tenant, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "disabled": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" },
+    "tenantOnly": { "state": "DISABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	te := eval.NewTenantEvaluator(nil, "tenantId", shared, map[string]eval.IEvaluator{"acme": tenant})
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"tenantId": "acme"})
+	require.Nil(t, err)
+
+	values, errs := te.ResolveAllValuesWithErrors(context.Background(), "", evalCtx)
+	require.ElementsMatch(t, []string{"disabled", "resolvable"}, resolvedFlagKeys(values),
+		"flags of the tenant should override the shared flags")
+	require.Equal(t, []eval.FlagError{
+		{FlagKey: "entitlement", ErrorCode: model.MissingContextErrorCode},
+		{FlagKey: "tenantOnly", ErrorCode: model.FlagDisabledErrorCode},
+	}, errs)
+}
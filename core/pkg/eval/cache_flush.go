@@ -0,0 +1,49 @@
+package eval
+
+// CacheFlush is implemented by evaluators caching the parsed targeting rules and compiled patterns of their flags
+type CacheFlush interface {
+	// FlushCaches drops the cached rules and patterns, which are parsed again by the next evaluations using them
+	FlushCaches() FlushedCaches
+}
+
+// FlushedCaches counts the entries dropped from the caches of an evaluator
+type FlushedCaches struct {
+	Rules    int `json:"rules"`
+	Patterns int `json:"patterns"`
+}
+
+// FlushCaches drops the parsed targeting rules, including the rules staged by a warmup, and the compiled patterns
+func (je *JSONEvaluator) FlushCaches() FlushedCaches {
+	return FlushedCaches{Rules: je.rules.flush(), Patterns: je.patterns.flush()}
+}
+
+func (c *ruleCache) flush() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	flushed := len(c.rules) + len(c.staged)
+	c.rules, c.staged = nil, nil
+	return flushed
+}
+
+func (c *regexCache) flush() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	flushed := len(c.patterns)
+	c.patterns = nil
+	return flushed
+}
+
+// flushCaches flushes the caches of each of the evaluators, summing the flushed entries
+func flushCaches(evaluators ...IEvaluator) FlushedCaches {
+	var flushed FlushedCaches
+	for _, evaluator := range evaluators {
+		flusher, ok := evaluator.(CacheFlush)
+		if !ok {
+			continue
+		}
+		caches := flusher.FlushCaches()
+		flushed.Rules += caches.Rules
+		flushed.Patterns += caches.Patterns
+	}
+	return flushed
+}
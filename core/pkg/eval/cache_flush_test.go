@@ -0,0 +1,30 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestFlushCaches(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, regexFlagConfig(`Firefox/\d+`))
+	require.Nil(t, err)
+	ctx, err := structpb.NewStruct(map[string]interface{}{"userAgent": "Mozilla/5.0 Firefox/115.0"})
+	require.Nil(t, err)
+	resolve := func() {
+		t.Helper()
+		_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "browserFlag", ctx)
+		require.Nil(t, err)
+	}
+
+	resolve()
+	require.Equal(t, eval.FlushedCaches{Rules: 1, Patterns: 1}, evaluator.FlushCaches())
+	require.Equal(t, eval.FlushedCaches{}, evaluator.FlushCaches(), "the caches must be empty once flushed")
+
+	resolve()
+	require.Equal(t, eval.FlushedCaches{Rules: 1, Patterns: 1}, evaluator.FlushCaches(),
+		"flushed rules and patterns are parsed again when used")
+}
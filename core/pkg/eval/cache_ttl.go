@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// validateCacheTTL rejects negative TTLs, and drops the TTL of flags whose resolutions can't be cached for a fixed
+// duration, i.e. flags whose targeting depends on the time or buckets evaluations
+func (je *JSONEvaluator) validateCacheTTL(key string, ttl *int64, rule interface{}) (*int64, error) {
+	if ttl == nil {
+		return nil, nil
+	}
+	if *ttl < 0 {
+		return nil, fmt.Errorf("cacheTtl: %d of flag: '%s' is negative", *ttl, key)
+	}
+	if rule != nil && !cacheable(rule) {
+		je.Logger.Warn(fmt.Sprintf(
+			"flag: '%s' has time sensitive or fractional targeting, its cacheTtl is ignored", key,
+		))
+		return nil, nil
+	}
+	return ttl, nil
+}
+
+// cacheable reports whether a targeting rule neither references flagd properties, such as $flagd.timestamp, nor
+// uses fractional evaluations
+func cacheable(rule interface{}) bool {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for operator, args := range r {
+			switch operator {
+			case fractionalEvaluationOperator:
+				return false
+			case varOperator:
+				if path, ok := varPath(args); ok && strings.HasPrefix(path, flagdPropertiesKey) {
+					return false
+				}
+			}
+			if !cacheable(args) {
+				return false
+			}
+		}
+	case []interface{}:
+		for _, item := range r {
+			if !cacheable(item) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// varPath returns the path of a var operation, e.g. {"var": "email"} or {"var": ["email", "default"]}
+func varPath(args interface{}) (string, bool) {
+	if list, ok := args.([]interface{}); ok && len(list) > 0 {
+		args = list[0]
+	}
+	path, ok := args.(string)
+	return path, ok
+}
+
+// resolutionMetadata returns the metadata of a resolution of the flag, along with its cache TTL if set. The
+// evaluation metadata is built for each evaluation, as such it can be extended.
+func resolutionMetadata(flag model.Flag, metadata map[string]interface{}) map[string]interface{} {
+	metadata = withFlagMetadata(flag.Metadata, metadata)
+	if flag.CacheTTL == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata[CacheTTLMetadataKey] = *flag.CacheTTL
+	return metadata
+}
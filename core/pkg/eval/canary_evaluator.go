@@ -0,0 +1,277 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync/atomic"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/zeebo/xxh3"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	ConfigVersionMetadataKey = "configVersion"
+	StableConfigVersion      = "stable"
+	CandidateConfigVersion   = "candidate"
+
+	targetingKeyField = "targetingKey"
+)
+
+// CanaryEvaluator splits evaluations between a stable and a candidate configuration. Evaluations are bucketed by
+// the targeting key of the evaluation context, the configured percentage of buckets is served by the candidate.
+// Evaluations without a targeting key are served by the stable configuration until the candidate is promoted.
+type CanaryEvaluator struct {
+	Logger     *logger.Logger
+	stable     IEvaluator
+	candidate  IEvaluator
+	metrics    *otel.MetricsRecorder
+	percentage atomic.Int32
+}
+
+func NewCanaryEvaluator(
+	log *logger.Logger, stable IEvaluator, candidate IEvaluator, percentage int, metrics *otel.MetricsRecorder,
+) *CanaryEvaluator {
+	if log == nil {
+		log = logger.NewLogger(nil, false)
+	}
+	ce := &CanaryEvaluator{
+		Logger:    log.WithFields(zap.String("component", "evaluator"), zap.String("evaluator", "canary")),
+		stable:    stable,
+		candidate: candidate,
+		metrics:   metrics,
+	}
+	ce.SetPercentage(percentage)
+	return ce
+}
+
+// SetPercentage sets the percentage of evaluations served by the candidate, clamped to [0, 100]
+func (ce *CanaryEvaluator) SetPercentage(percentage int) {
+	if percentage < 0 {
+		percentage = 0
+	} else if percentage > 100 {
+		percentage = 100
+	}
+	ce.percentage.Store(int32(percentage))
+}
+
+func (ce *CanaryEvaluator) Percentage() int {
+	return int(ce.percentage.Load())
+}
+
+// Promote serves every evaluation from the candidate configuration
+func (ce *CanaryEvaluator) Promote() {
+	if ce.percentage.Swap(100) != 100 {
+		ce.Logger.Info("candidate configuration promoted")
+	}
+}
+
+// GetState returns the state of the stable configuration
+func (ce *CanaryEvaluator) GetState() (string, error) {
+	return ce.stable.GetState()
+}
+
+// SetState updates the stable configuration
+func (ce *CanaryEvaluator) SetState(payload sync.DataSync) (map[string]interface{}, bool, error) {
+	return ce.stable.SetState(payload)
+}
+
+// EvaluateSandbox evaluates inline flag definitions with the stable evaluator
+func (ce *CanaryEvaluator) EvaluateSandbox(
+	reqID string, flagKey string, definition json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	return evaluateSandbox(ce.stable, reqID, flagKey, definition, context)
+}
+
+// EvaluateWhatIf evaluates stored flags with overridden variants with the stable evaluator
+func (ce *CanaryEvaluator) EvaluateWhatIf(
+	reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	return evaluateWhatIf(ce.stable, reqID, flagKey, overrides, context)
+}
+
+// CompareConfig compares the configuration of the stable evaluator with the candidate configuration
+func (ce *CanaryEvaluator) CompareConfig(
+	reqID string, config string, contexts []*structpb.Struct,
+) (ConfigDivergence, error) {
+	return compareConfig(ce.stable, reqID, config, contexts)
+}
+
+// ValidateConfig validates the candidate configuration with the stable evaluator
+func (ce *CanaryEvaluator) ValidateConfig(config string) ([]ConfigIssue, error) {
+	return validateConfig(ce.stable, config)
+}
+
+// LintRules lints the rules with the stable evaluator
+func (ce *CanaryEvaluator) LintRules(config string, flagKey string) ([]LintWarning, error) {
+	return lintRules(ce.stable, config, flagKey)
+}
+
+// RuleStatistics returns the rule statistics of the stable evaluator
+func (ce *CanaryEvaluator) RuleStatistics() map[string]FlagRuleStatistics {
+	return ruleStatisticsOf(ce.stable)
+}
+
+// CircuitBreakers returns the circuit breakers of the stable evaluator
+func (ce *CanaryEvaluator) CircuitBreakers() map[string]CircuitBreakerState {
+	return circuitBreakersOf(ce.stable)
+}
+
+// FlagType returns the type of the flag in the stable configuration, or in the candidate one for new flags
+func (ce *CanaryEvaluator) FlagType(flagKey string, context *structpb.Struct) (string, bool) {
+	if flagType, ok := flagTypeOf(ce.stable, flagKey, context); ok {
+		return flagType, true
+	}
+	return flagTypeOf(ce.candidate, flagKey, context)
+}
+
+// HeldFlags returns the flags held by the stable or candidate evaluator
+func (ce *CanaryEvaluator) HeldFlags() []string {
+	return mergeHeldFlags(ce.stable, ce.candidate)
+}
+
+// PinnedFlags returns the flags pinned by the stable or candidate evaluator
+func (ce *CanaryEvaluator) PinnedFlags() []PinnedFlag {
+	return pinnedFlagsOf(ce.stable, ce.candidate)
+}
+
+// ApplyPinnedFlag applies the pending definition of the pinned flag to both the stable and candidate evaluator
+func (ce *CanaryEvaluator) ApplyPinnedFlag(flagKey string) (map[string]interface{}, bool) {
+	return applyPinnedFlag(flagKey, ce.stable, ce.candidate)
+}
+
+// OverrideFlag applies the override to both the stable and candidate evaluator
+func (ce *CanaryEvaluator) OverrideFlag(flagKey string, override FlagOverride) (
+	FlagOverride, map[string]interface{}, error,
+) {
+	return overrideFlag(flagKey, override, ce.stable, ce.candidate)
+}
+
+// FlushCaches flushes the caches of both the stable and candidate evaluator
+func (ce *CanaryEvaluator) FlushCaches() FlushedCaches {
+	return flushCaches(ce.stable, ce.candidate)
+}
+
+// SetCandidateState updates the candidate configuration
+func (ce *CanaryEvaluator) SetCandidateState(payload sync.DataSync) (map[string]interface{}, bool, error) {
+	return ce.candidate.SetState(payload)
+}
+
+func (ce *CanaryEvaluator) ResolveAllValues(ctx context.Context, reqID string, context *structpb.Struct) []AnyValue {
+	values, _ := ce.ResolveAllValuesWithErrors(ctx, reqID, context)
+	return values
+}
+
+// ResolveAllValuesWithErrors resolves every flag of the configuration the context is routed to, along with the
+// errors of the flags which failed to resolve
+func (ce *CanaryEvaluator) ResolveAllValuesWithErrors(
+	ctx context.Context, reqID string, context *structpb.Struct,
+) ([]AnyValue, []FlagError) {
+	evaluator, version := ce.route(context)
+	values, errs := resolveAllWithErrors(ctx, evaluator, reqID, context)
+	for i := range values {
+		values[i].Metadata = withConfigVersion(values[i].Metadata, version)
+	}
+	return values, errs
+}
+
+func (ce *CanaryEvaluator) ResolveBooleanValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value bool, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, version := ce.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveBooleanValue(ctx, reqID, flagKey, context)
+	return value, variant, reason, withConfigVersion(metadata, version), err
+}
+
+func (ce *CanaryEvaluator) ResolveStringValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value string, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, version := ce.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveStringValue(ctx, reqID, flagKey, context)
+	return value, variant, reason, withConfigVersion(metadata, version), err
+}
+
+func (ce *CanaryEvaluator) ResolveIntValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value int64, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, version := ce.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveIntValue(ctx, reqID, flagKey, context)
+	return value, variant, reason, withConfigVersion(metadata, version), err
+}
+
+func (ce *CanaryEvaluator) ResolveFloatValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value float64, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, version := ce.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveFloatValue(ctx, reqID, flagKey, context)
+	return value, variant, reason, withConfigVersion(metadata, version), err
+}
+
+func (ce *CanaryEvaluator) ResolveObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value map[string]any, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, version := ce.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveObjectValue(ctx, reqID, flagKey, context)
+	return value, variant, reason, withConfigVersion(metadata, version), err
+}
+
+// ResolveRawObjectValue resolves the raw value of an object flag with the evaluator serving the evaluation context
+func (ce *CanaryEvaluator) ResolveRawObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (RawObjectResolution, error) {
+	evaluator, version := ce.route(context)
+	resolution, err := resolveRawObjectValue(ctx, evaluator, reqID, flagKey, context)
+	resolution.Metadata = withConfigVersion(resolution.Metadata, version)
+	return resolution, err
+}
+
+// route returns the evaluator serving the evaluation context, recording the served configuration version
+func (ce *CanaryEvaluator) route(evalCtx *structpb.Struct) (IEvaluator, string) {
+	evaluator, version := ce.stable, StableConfigVersion
+	if ce.servesCandidate(evalCtx) {
+		evaluator, version = ce.candidate, CandidateConfigVersion
+	}
+	if ce.metrics != nil {
+		ce.metrics.ConfigVersionEvaluation(context.Background(), version)
+	}
+	return evaluator, version
+}
+
+func (ce *CanaryEvaluator) servesCandidate(evalCtx *structpb.Struct) bool {
+	percentage := ce.Percentage()
+	switch percentage {
+	case 0:
+		return false
+	case 100:
+		return true
+	}
+	targetingKey, ok := evalCtx.GetFields()[targetingKeyField].GetKind().(*structpb.Value_StringValue)
+	if !ok {
+		return false
+	}
+
+	hashRatio := float64(xxh3.HashString(targetingKey.StringValue)) / math.Pow(2, 64)
+	return int(hashRatio*100) < percentage
+}
+
+func withConfigVersion(metadata map[string]interface{}, version string) map[string]interface{} {
+	annotated := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		annotated[k] = v
+	}
+	annotated[ConfigVersionMetadataKey] = version
+	return annotated
+}
+
+// ExportRego exports the policies of the flags of the stable evaluator, the candidate only serving a share of the
+// evaluations
+func (ce *CanaryEvaluator) ExportRego() RegoBundle {
+	if exporter, ok := ce.stable.(RegoExport); ok {
+		return exporter.ExportRego()
+	}
+	return RegoBundle{Policies: map[string]string{}, Untranslated: map[string]string{}}
+}
@@ -0,0 +1,109 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func canaryFlagConfig(color string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": %q
+    }
+  }
+}`, color)
+}
+
+func newCanaryEvaluator(t *testing.T, percentage int) *eval.CanaryEvaluator {
+	stable := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := stable.SetState(sync.DataSync{FlagData: canaryFlagConfig("red")})
+	require.Nil(t, err)
+	candidate := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	ce := eval.NewCanaryEvaluator(logger.NewLogger(nil, false), stable, candidate, percentage, nil)
+	_, _, err = ce.SetCandidateState(sync.DataSync{FlagData: canaryFlagConfig("blue")})
+	require.Nil(t, err)
+	return ce
+}
+
+func targetingContext(t *testing.T, targetingKey string) *structpb.Struct {
+	ctx, err := structpb.NewStruct(map[string]interface{}{"targetingKey": targetingKey})
+	require.Nil(t, err)
+	return ctx
+}
+
+func TestCanaryEvaluator_Split(t *testing.T) {
+	const evaluations = 10000
+	ce := newCanaryEvaluator(t, 20)
+
+	candidate := 0
+	for i := 0; i < evaluations; i++ {
+		ctx := targetingContext(t, fmt.Sprintf("user-%d", i))
+		value, _, _, metadata, err := ce.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+		require.Nil(t, err)
+		if value == "#0000FF" {
+			candidate++
+			assert.Equal(t, eval.CandidateConfigVersion, metadata[eval.ConfigVersionMetadataKey])
+		} else {
+			assert.Equal(t, eval.StableConfigVersion, metadata[eval.ConfigVersionMetadataKey])
+		}
+
+		// bucketing is sticky for a targeting key
+		again, _, _, _, err := ce.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+		require.Nil(t, err)
+		require.Equal(t, value, again)
+	}
+	assert.InDelta(t, evaluations*0.2, candidate, evaluations*0.02)
+
+	value, _, _, _, err := ce.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+	require.Nil(t, err)
+	assert.Equal(t, "#FF0000", value, "evaluations without a targeting key are served by the stable configuration")
+}
+
+func TestCanaryEvaluator_CandidateOptions(t *testing.T) {
+	stable, err := eval.NewJSONEvaluatorFromConfig(nil, bucketedFlagConfig)
+	require.Nil(t, err)
+	require.Equal(t, "blue", bucketedVariant(t, stable))
+
+	candidate := eval.NewJSONEvaluator(nil, store.NewFlags(), eval.WithBucketingHash(eval.BucketingHashMurmur3))
+	ce := eval.NewCanaryEvaluator(logger.NewLogger(nil, false), stable, candidate, 0, nil)
+	_, _, err = ce.SetCandidateState(sync.DataSync{FlagData: bucketedFlagConfig})
+	require.Nil(t, err)
+	require.Equal(t, "red", bucketedVariant(t, candidate))
+	require.Equal(t, "blue", bucketedVariant(t, stable),
+		"the stable evaluations shouldn't be changed by the options of the candidate")
+	require.Equal(t, "blue", bucketedVariant(t, ce), "the canary evaluations should be served by the stable evaluator")
+}
+
+func TestCanaryEvaluator_Promote(t *testing.T) {
+	ce := newCanaryEvaluator(t, 0)
+
+	value, _, _, _, err := ce.ResolveStringValue(context.Background(), "", "headerColor", targetingContext(t, "user-1"))
+	require.Nil(t, err)
+	assert.Equal(t, "#FF0000", value)
+
+	ce.Promote()
+	require.Equal(t, 100, ce.Percentage())
+	for _, ctx := range []*structpb.Struct{targetingContext(t, "user-1"), {}} {
+		value, _, _, metadata, err := ce.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+		require.Nil(t, err)
+		assert.Equal(t, "#0000FF", value)
+		assert.Equal(t, eval.CandidateConfigVersion, metadata[eval.ConfigVersionMetadataKey])
+	}
+
+	for _, v := range ce.ResolveAllValues(context.Background(), "", &structpb.Struct{}) {
+		assert.Equal(t, "#0000FF", v.Value)
+		assert.Equal(t, eval.CandidateConfigVersion, v.Metadata[eval.ConfigVersionMetadataKey])
+	}
+}
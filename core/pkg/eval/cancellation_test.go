@@ -0,0 +1,82 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const cancellationFlagConfig = `{
+  "flags": {
+    "betaUser": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "internalUser": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "newCheckout": {
+      "state": "ENABLED",
+      "variants": { "enabled": true, "disabled": false },
+      "defaultVariant": "disabled",
+      "derived": { "and": ["betaUser", "internalUser"] }
+    }
+  }
+}`
+
+// cancelAfter is a context canceled once its error has been checked the given number of times, cancelling
+// evaluations at a deterministic point
+type cancelAfter struct {
+	context.Context
+	checks int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.checks--; c.checks < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestEvaluationCancellation(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, cancellationFlagConfig)
+	require.Nil(t, err)
+
+	// the derived flag and its first prerequisite are evaluated, the client disconnects before the second one
+	ctx := &cancelAfter{Context: context.Background(), checks: 2}
+	_, _, reason, _, err := evaluator.ResolveBooleanValue(ctx, "", "newCheckout", &structpb.Struct{})
+	require.ErrorIs(t, err, context.Canceled, "the evaluation should abort once its context is canceled")
+	require.Equal(t, model.ErrorReason, reason)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, _, _, err = evaluator.ResolveBooleanValue(canceled, "", "betaUser", &structpb.Struct{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "newCheckout", &structpb.Struct{})
+	require.Nil(t, err)
+	require.True(t, value)
+}
+
+func TestEvaluationCancellation_ResolveAll(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, bulkErrorsFlagConfig)
+	require.Nil(t, err)
+
+	// the first flag is evaluated, the client disconnects before the others
+	ctx := &cancelAfter{Context: context.Background(), checks: 2}
+	values, errs := evaluator.ResolveAllValuesWithErrors(ctx, "", &structpb.Struct{})
+	require.Equal(t, 1, len(values)+len(errs), "the flags left once the context is canceled shouldn't be resolved")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	values, errs = evaluator.ResolveAllValuesWithErrors(canceled, "", &structpb.Struct{})
+	require.Empty(t, values)
+	require.Empty(t, errs, "the flags left unresolved shouldn't be reported as failing to resolve")
+}
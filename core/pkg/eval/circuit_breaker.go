@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Circuit breaker states
+const (
+	// CircuitClosed evaluates the targeting of the flag
+	CircuitClosed = "closed"
+	// CircuitOpen short-circuits the flag to its default variant until the cooldown elapsed
+	CircuitOpen = "open"
+	// CircuitHalfOpen lets a single trial evaluation through once the cooldown elapsed, closing the breaker if it
+	// succeeds
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreakers is implemented by evaluators isolating flags whose targeting repeatedly fails or is slow
+type CircuitBreakers interface {
+	CircuitBreakers() map[string]CircuitBreakerState
+}
+
+// CircuitBreakerState is the state of the circuit breaker of a flag
+type CircuitBreakerState struct {
+	State string `json:"state"`
+	// ConsecutiveFailures counts the failed or slow evaluations since the last successful one
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Trips counts the times the breaker opened
+	Trips int64 `json:"trips"`
+	// OpenedAt is the time the breaker last opened, zero if it never did
+	OpenedAt time.Time `json:"openedAt,omitempty"`
+}
+
+// WithCircuitBreaker opens the circuit breaker of a flag once the evaluation of its targeting failed, or took longer
+// than slowEvaluation if set, failures times in a row. The flag then resolves its default variant with the ERROR
+// reason for the cooldown, before a trial evaluation decides whether the breaker closes. Breakers are disabled when
+// failures is 0.
+func WithCircuitBreaker(failures int, slowEvaluation time.Duration, cooldown time.Duration) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if failures > 0 {
+			je.circuitBreakers = &circuitBreakers{
+				failures:       failures,
+				slowEvaluation: slowEvaluation,
+				cooldown:       cooldown,
+				flags:          map[string]*flagCircuitBreaker{},
+			}
+		}
+	}
+}
+
+// CircuitBreakers returns the state of the breakers of the flags whose targeting was evaluated, nil if breakers are
+// disabled
+func (je *JSONEvaluator) CircuitBreakers() map[string]CircuitBreakerState {
+	if je.circuitBreakers == nil {
+		return nil
+	}
+	return je.circuitBreakers.snapshot(je.clock.Now())
+}
+
+type flagCircuitBreaker struct {
+	// targeting is the rule the breaker guards, the breaker is reset when the rule of the flag changes
+	targeting string
+	// trial is set while the trial evaluation of a half-open breaker is in flight
+	trial bool
+	CircuitBreakerState
+}
+
+type circuitBreakers struct {
+	failures       int
+	slowEvaluation time.Duration
+	cooldown       time.Duration
+	mu             sync.Mutex
+	flags          map[string]*flagCircuitBreaker
+}
+
+// allow reports whether the targeting of the flag may be evaluated, once the cooldown of an open breaker elapsed a
+// single trial evaluation is allowed
+func (c *circuitBreakers) allow(flagKey string, targeting string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	breaker := c.breaker(flagKey, targeting)
+	switch breaker.State {
+	case CircuitOpen:
+		if now.Sub(breaker.OpenedAt) < c.cooldown {
+			return false
+		}
+		breaker.State, breaker.trial = CircuitHalfOpen, true
+		return true
+	case CircuitHalfOpen:
+		if breaker.trial {
+			return false
+		}
+		breaker.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of an evaluation of the targeting of the flag, returning true if it opened the breaker
+func (c *circuitBreakers) record(
+	flagKey string, targeting string, failed bool, elapsed time.Duration, now time.Time,
+) bool {
+	if c.slowEvaluation > 0 && elapsed > c.slowEvaluation {
+		failed = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	breaker := c.breaker(flagKey, targeting)
+	breaker.trial = false
+	if !failed {
+		breaker.State, breaker.ConsecutiveFailures = CircuitClosed, 0
+		return false
+	}
+	breaker.ConsecutiveFailures++
+	if breaker.State == CircuitHalfOpen || breaker.ConsecutiveFailures >= c.failures {
+		breaker.State, breaker.OpenedAt = CircuitOpen, now
+		breaker.Trips++
+		return true
+	}
+	return false
+}
+
+// breaker returns the breaker of the flag, resetting it if the targeting of the flag changed. The caller must hold
+// c.mu.
+func (c *circuitBreakers) breaker(flagKey string, targeting string) *flagCircuitBreaker {
+	breaker, ok := c.flags[flagKey]
+	if !ok || breaker.targeting != targeting {
+		breaker = &flagCircuitBreaker{
+			targeting:           targeting,
+			CircuitBreakerState: CircuitBreakerState{State: CircuitClosed},
+		}
+		c.flags[flagKey] = breaker
+	}
+	return breaker
+}
+
+func (c *circuitBreakers) snapshot(now time.Time) map[string]CircuitBreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	states := make(map[string]CircuitBreakerState, len(c.flags))
+	for flagKey, breaker := range c.flags {
+		state := breaker.CircuitBreakerState
+		if state.State == CircuitOpen && now.Sub(state.OpenedAt) >= c.cooldown {
+			// the next evaluation is a trial
+			state.State = CircuitHalfOpen
+		}
+		states[flagKey] = state
+	}
+	return states
+}
+
+// evaluateTargetingWithBreaker evaluates the targeting of the flag unless its breaker is open, short-circuiting it
+// to its default variant with the ERROR reason then. Each ruleset of a flag has its own breaker, keyed by ruleKey.
+func (je *JSONEvaluator) evaluateTargetingWithBreaker(
+	reqID string, flagKey string, ruleKey string, flag model.Flag, context *structpb.Struct,
+) (string, string, map[string]interface{}, error) {
+	targeting := string(flag.Targeting)
+	if !je.circuitBreakers.allow(ruleKey, targeting, je.clock.Now()) {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("circuit breaker of flag: %s is open, returning its default variant",
+			flagKey))
+		return je.defaultVariant(flag, context), model.ErrorReason, resolutionMetadata(flag, nil), nil
+	}
+
+	start := time.Now()
+	variant, reason, metadata, err := je.evaluateTargeting(reqID, flagKey, ruleKey, flag, context)
+	elapsed := time.Since(start)
+	if je.circuitBreakers.record(ruleKey, targeting, err != nil, elapsed, je.clock.Now()) {
+		je.Logger.Warn(fmt.Sprintf("opened the circuit breaker of flag: %s after %d failed or slow evaluations, "+
+			"returning its default variant for %s", flagKey, je.circuitBreakers.failures, je.circuitBreakers.cooldown))
+	}
+	return variant, reason, metadata, err
+}
+
+// circuitBreakersOf returns the circuit breakers of the evaluator, nil if it doesn't have any
+func circuitBreakersOf(evaluator IEvaluator) map[string]CircuitBreakerState {
+	breakers, ok := evaluator.(CircuitBreakers)
+	if !ok {
+		return nil
+	}
+	return breakers.CircuitBreakers()
+}
@@ -0,0 +1,153 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the targeting of fragileFlag fails when the user of the context isn't a string
+const circuitBreakerFlagConfig = `{
+  "flags": {
+    "fragileFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "user" }] }, "on", "off"] }
+    },
+    "sturdyFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "pro"] }, "on", "off"] }
+    }
+  }
+}`
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	core, logs := observer.New(zap.WarnLevel)
+	je := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), store.NewFlags(),
+		eval.WithClock(clock), eval.WithCircuitBreaker(3, 0, time.Minute))
+	_, _, err := je.SetState(sync.DataSync{FlagData: circuitBreakerFlagConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	resolve := func(flagKey string, user interface{}) (bool, string, error) {
+		t.Helper()
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"user": user, "plan": "pro"})
+		require.Nil(t, err)
+		value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", flagKey, evalCtx)
+		return value, reason, err
+	}
+	failing := map[string]interface{}{"email": "user@faas.com"}
+
+	for i := 0; i < 2; i++ {
+		_, _, err := resolve("fragileFlag", failing)
+		require.NotNil(t, err, "failing evaluations must fail until the breaker opens")
+	}
+	value, reason, err := resolve("fragileFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason)
+	require.True(t, value)
+	require.Equal(t, eval.CircuitClosed, je.CircuitBreakers()["fragileFlag"].State,
+		"a successful evaluation must reset the consecutive failures")
+
+	for i := 0; i < 3; i++ {
+		_, _, err = resolve("fragileFlag", failing)
+		require.NotNil(t, err)
+	}
+	require.Equal(t, 1, logs.FilterMessage("opened the circuit breaker of flag: fragileFlag after 3 failed or slow "+
+		"evaluations, returning its default variant for 1m0s").Len())
+	value, reason, err = resolve("fragileFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.ErrorReason, reason, "an open breaker must short-circuit the flag")
+	require.False(t, value, "an open breaker must return the default variant")
+	require.Equal(t, eval.CircuitBreakerState{
+		State:               eval.CircuitOpen,
+		ConsecutiveFailures: 3,
+		Trips:               1,
+		OpenedAt:            clock.Now(),
+	}, je.CircuitBreakers()["fragileFlag"])
+
+	value, reason, err = resolve("sturdyFlag", failing)
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason, "breakers must isolate the failing flag")
+	require.True(t, value)
+
+	clock.Advance(time.Minute)
+	require.Equal(t, eval.CircuitHalfOpen, je.CircuitBreakers()["fragileFlag"].State)
+	_, _, err = resolve("fragileFlag", failing)
+	require.NotNil(t, err, "the cooldown must let a trial evaluation through")
+	_, reason, err = resolve("fragileFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.ErrorReason, reason, "a failed trial must reopen the breaker")
+	require.Equal(t, int64(2), je.CircuitBreakers()["fragileFlag"].Trips)
+
+	clock.Advance(time.Minute)
+	value, reason, err = resolve("fragileFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason, "a successful trial must close the breaker")
+	require.True(t, value)
+	require.Equal(t, eval.CircuitClosed, je.CircuitBreakers()["fragileFlag"].State)
+}
+
+func TestCircuitBreaker_SlowEvaluations(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithClock(clock), eval.WithCircuitBreaker(2, time.Nanosecond, time.Minute))
+	_, _, err := je.SetState(sync.DataSync{FlagData: circuitBreakerFlagConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "pro"})
+	require.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", "sturdyFlag", evalCtx)
+		require.Nil(t, err)
+		require.Equal(t, model.TargetingMatchReason, reason, "slow evaluations must be served until the breaker opens")
+		require.True(t, value)
+	}
+	value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", "sturdyFlag", evalCtx)
+	require.Nil(t, err)
+	require.Equal(t, model.ErrorReason, reason)
+	require.False(t, value)
+}
+
+func TestCircuitBreaker_TargetingChange(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithCircuitBreaker(1, time.Nanosecond, time.Hour))
+	_, _, err := je.SetState(sync.DataSync{FlagData: circuitBreakerFlagConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	_, _, _, _, err = je.ResolveBooleanValue(context.Background(), "", "sturdyFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.Equal(t, eval.CircuitOpen, je.CircuitBreakers()["sturdyFlag"].State)
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "sturdyFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "free"] }, "off", "on"] }
+    }
+  }
+}`, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", "sturdyFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason, "a new targeting rule must reset the breaker")
+	require.True(t, value)
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(), eval.WithCircuitBreaker(0, 0, 0))
+	require.Nil(t, je.CircuitBreakers())
+}
@@ -0,0 +1,49 @@
+package eval
+
+import (
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	// flagdPropertiesKey holds the properties flagd adds to the data of targeting rules, overriding any context key
+	// of the same name, e.g. {"var": "$flagd.timestamp"}
+	flagdPropertiesKey = "$flagd"
+	timestampProperty  = "timestamp"
+	// fractionalRandomizationProperty holds the fractional randomization of the flag being evaluated, if set
+	fractionalRandomizationProperty = "fractionalRandomization"
+)
+
+// Clock provides the current time to time dependent evaluations
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock sets the clock of time dependent evaluations, such as targeting on $flagd.timestamp. The real clock is
+// used by default, tests may provide a fixed or advanceable one.
+func WithClock(clock Clock) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.clock = clock
+	}
+}
+
+// targetingData returns the data targeting rules are applied to, the evaluation context along with the flagd
+// properties
+func (je *JSONEvaluator) targetingData(flag model.Flag, context map[string]interface{}) map[string]interface{} {
+	context = je.normalizeContext(context)
+	properties := map[string]interface{}{
+		timestampProperty: float64(je.clock.Now().Unix()),
+	}
+	if flag.FractionalRandomization != "" {
+		properties[fractionalRandomizationProperty] = flag.FractionalRandomization
+	}
+	context[flagdPropertiesKey] = properties
+	return context
+}
@@ -0,0 +1,80 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeClock is an advanceable clock
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestClock_ScheduledFlag(t *testing.T) {
+	launch := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: launch.Add(-time.Second)}
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(`{
+  "flags": {
+    "launchFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ ">=": [{ "var": "$flagd.timestamp" }, %d] }, "on", null] }
+    }
+  }
+}`, launch.Unix()), eval.WithClock(clock))
+	require.Nil(t, err)
+
+	value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "launchFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.False(t, value)
+	require.Equal(t, model.DefaultReason, reason)
+
+	clock.Advance(time.Second)
+	value, _, reason, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "launchFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.True(t, value)
+	require.Equal(t, model.TargetingMatchReason, reason)
+}
+
+func TestClock_OverridesContext(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(100, 0)}
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "launchFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "between": [{ "var": "$flagd.timestamp" }, 100, 200, "[)"] }, "on", null] }
+    }
+  }
+}`, eval.WithClock(clock))
+	require.Nil(t, err)
+
+	// the timestamp can't be provided by clients
+	ctx, err := structpb.NewStruct(map[string]interface{}{"$flagd": map[string]interface{}{"timestamp": 500}})
+	require.Nil(t, err)
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "launchFlag", ctx)
+	require.Nil(t, err)
+	require.True(t, value)
+
+	clock.Advance(100 * time.Second)
+	value, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "launchFlag", ctx)
+	require.Nil(t, err)
+	require.False(t, value)
+}
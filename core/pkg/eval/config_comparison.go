@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ConfigComparison is implemented by evaluators able to compare the evaluations of their configuration with the
+// evaluations of a candidate configuration
+type ConfigComparison interface {
+	CompareConfig(reqID string, config string, contexts []*structpb.Struct) (ConfigDivergence, error)
+}
+
+// ConfigDivergence counts the evaluations of each flag which differ between a configuration and a candidate
+type ConfigDivergence struct {
+	// Contexts is the number of evaluation contexts evaluated against both configurations
+	Contexts int
+	// Flags are keyed by flag key, holding the flags resolved by either configuration
+	Flags map[string]FlagDivergence
+}
+
+// FlagDivergence counts the evaluations of a flag against both configurations
+type FlagDivergence struct {
+	// Evaluations counts the contexts the flag resolved for in either configuration
+	Evaluations int
+	// Divergent counts the evaluations resolving another value or variant, or resolving in a single configuration
+	Divergent int
+}
+
+// CompareConfig evaluates every flag against each context with both the stored configuration and the candidate
+// configuration, counting the evaluations which differ. Neither the store nor the statistics of the evaluator are
+// changed. Invalid candidate configurations return an error.
+func (je *JSONEvaluator) CompareConfig(
+	reqID string, config string, contexts []*structpb.Struct,
+) (ConfigDivergence, error) {
+	candidate := je.comparisonEvaluator(store.NewFlags())
+	if _, _, err := candidate.SetState(sync.DataSync{FlagData: config, Type: sync.ALL}); err != nil {
+		return ConfigDivergence{}, fmt.Errorf("candidate configuration: %w", err)
+	}
+	live := je.comparisonEvaluator(je.store)
+	live.loaded.Store(je.loaded.Load())
+
+	divergence := ConfigDivergence{Contexts: len(contexts), Flags: map[string]FlagDivergence{}}
+	ctx := context.Background()
+	for _, evalCtx := range contexts {
+		resolved := resolvedValues(live.ResolveAllValues(ctx, reqID, evalCtx))
+		for flagKey, candidateValue := range resolvedValues(candidate.ResolveAllValues(ctx, reqID, evalCtx)) {
+			flag := divergence.Flags[flagKey]
+			flag.Evaluations++
+			if value, ok := resolved[flagKey]; !ok || !sameResolution(value, candidateValue) {
+				flag.Divergent++
+			}
+			divergence.Flags[flagKey] = flag
+			delete(resolved, flagKey)
+		}
+		// the flags left only resolved with the stored configuration
+		for flagKey := range resolved {
+			flag := divergence.Flags[flagKey]
+			flag.Evaluations++
+			flag.Divergent++
+			divergence.Flags[flagKey] = flag
+		}
+	}
+	return divergence, nil
+}
+
+// comparisonEvaluator returns an evaluator of the store with the options of the evaluator, which neither shares its
+// caches nor its statistics. The operators are registered globally, so they aren't registered again.
+func (je *JSONEvaluator) comparisonEvaluator(s *store.Flags) *JSONEvaluator {
+	ev := JSONEvaluator{Logger: je.Logger, store: s, clock: je.clock, options: je.options}
+	for _, opt := range je.options {
+		opt(&ev)
+	}
+	return &ev
+}
+
+func resolvedValues(values []AnyValue) map[string]AnyValue {
+	resolved := make(map[string]AnyValue, len(values))
+	for _, value := range values {
+		resolved[value.FlagKey] = value
+	}
+	return resolved
+}
+
+func sameResolution(a AnyValue, b AnyValue) bool {
+	return a.Variant == b.Variant && reflect.DeepEqual(a.Value, b.Value)
+}
+
+// compareConfig compares the configuration of the evaluator with the candidate, if it's able to
+func compareConfig(
+	evaluator IEvaluator, reqID string, config string, contexts []*structpb.Struct,
+) (ConfigDivergence, error) {
+	comparison, ok := evaluator.(ConfigComparison)
+	if !ok {
+		return ConfigDivergence{}, errors.New("the evaluator can't compare configurations")
+	}
+	return comparison.CompareConfig(reqID, config, contexts)
+}
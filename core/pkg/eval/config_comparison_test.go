@@ -0,0 +1,105 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const comparisonStableConfig = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null] }
+    },
+    "color": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    },
+    "legacy": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on"
+    }
+  }
+}`
+
+// comparisonCandidateConfig enables beta for another domain, keeps color and replaces legacy with banner
+const comparisonCandidateConfig = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@example.com", { "var": "email" }] }, "on", null] }
+    },
+    "color": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    },
+    "banner": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on"
+    }
+  }
+}`
+
+func comparisonContexts(t *testing.T, emails ...string) []*structpb.Struct {
+	contexts := make([]*structpb.Struct, 0, len(emails))
+	for _, email := range emails {
+		ctx, err := structpb.NewStruct(map[string]interface{}{"email": email})
+		require.Nil(t, err)
+		contexts = append(contexts, ctx)
+	}
+	return contexts
+}
+
+func TestJSONEvaluator_CompareConfig(t *testing.T) {
+	je, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonStableConfig, eval.WithRuleStatistics(true))
+	require.Nil(t, err)
+	stable, err := je.GetState()
+	require.Nil(t, err)
+	contexts := comparisonContexts(t, "user@faas.com", "user@example.com", "user@other.com")
+
+	divergence, err := je.CompareConfig("", comparisonCandidateConfig, contexts)
+	require.Nil(t, err)
+	require.Equal(t, eval.ConfigDivergence{
+		Contexts: 3,
+		Flags: map[string]eval.FlagDivergence{
+			"beta":   {Evaluations: 3, Divergent: 2},
+			"color":  {Evaluations: 3, Divergent: 0},
+			"legacy": {Evaluations: 3, Divergent: 3},
+			"banner": {Evaluations: 3, Divergent: 3},
+		},
+	}, divergence)
+
+	state, err := je.GetState()
+	require.Nil(t, err)
+	require.Equal(t, stable, state, "the store is left unchanged")
+	require.Empty(t, je.RuleStatistics(), "comparisons aren't recorded")
+
+	divergence, err = je.CompareConfig("", comparisonStableConfig, contexts)
+	require.Nil(t, err)
+	for flagKey, flag := range divergence.Flags {
+		require.Equal(t, 0, flag.Divergent, "flag: %s doesn't diverge from its own configuration", flagKey)
+	}
+
+	_, err = je.CompareConfig("", `{"flags":{"broken":{"state":"ENABLED"}}}`, contexts)
+	require.Error(t, err)
+}
+
+func TestCompareConfig_Delegation(t *testing.T) {
+	stable, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonStableConfig)
+	require.Nil(t, err)
+	contexts := comparisonContexts(t, "user@example.com")
+
+	evaluators := map[string]eval.IEvaluator{
+		"canary": eval.NewCanaryEvaluator(nil, stable, eval.NewJSONEvaluator(nil, nil), 50, nil),
+		"tenant": eval.NewTenantEvaluator(logger.NewLogger(nil, false), "tenant", stable, nil),
+	}
+	for name, evaluator := range evaluators {
+		t.Run(name, func(t *testing.T) {
+			comparison, ok := evaluator.(eval.ConfigComparison)
+			require.True(t, ok)
+			divergence, err := comparison.CompareConfig("", comparisonCandidateConfig, contexts)
+			require.Nil(t, err)
+			require.Equal(t, eval.FlagDivergence{Evaluations: 1, Divergent: 1}, divergence.Flags["beta"])
+		})
+	}
+}
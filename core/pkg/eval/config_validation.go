@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// ConfigValidation is implemented by evaluators able to validate a candidate configuration without loading it
+type ConfigValidation interface {
+	ValidateConfig(config string) ([]ConfigIssue, error)
+}
+
+// ConfigIssue is a reason a candidate configuration would be rejected
+type ConfigIssue struct {
+	// FlagKey is the flag at fault, empty for issues of the configuration as a whole, e.g. parse errors or cycles
+	FlagKey string
+	Message string
+}
+
+// ValidateConfig validates a candidate configuration as it would be when loaded, reporting the issues of every flag
+// rather than the first one: parse errors, schema violations, unknown operators, references to undefined variants
+// and cycles of derived flags. References to undefined variants are issues whatever the undefined variant policy, as
+// they'd otherwise fall back to the default variant unnoticed. The store is left unchanged and targeting rules aren't
+// cached. Conflicts with the flags of other sources aren't checked.
+func (je *JSONEvaluator) ValidateConfig(config string) ([]ConfigIssue, error) {
+	_, issues, err := je.validateCandidate(config)
+	return issues, err
+}
+
+// validateCandidate validates the candidate configuration, returning its valid flags along with the issues
+func (je *JSONEvaluator) validateCandidate(config string) (map[string]model.Flag, []ConfigIssue, error) {
+	flagSchema, err := compiledFlagSchema()
+	if err != nil {
+		return nil, nil, fmt.Errorf("compiling flag schema: %w", err)
+	}
+	candidateStore := store.NewFlags()
+	candidateStore.DuplicateKeys = je.store.DuplicateKeys
+	candidate := je.comparisonEvaluator(candidateStore)
+	candidate.undefinedVariants = UndefinedVariantsError
+
+	transposedConfig, err := candidate.transposeEvaluators(config)
+	if err != nil {
+		return nil, []ConfigIssue{{Message: fmt.Sprintf("transposing evaluators: %v", err)}}, nil
+	}
+	var raw rawFlags
+	if err := json.Unmarshal([]byte(transposedConfig), &raw); err != nil {
+		return nil, []ConfigIssue{{Message: fmt.Sprintf("unmarshalling provided configurations: %v", err)}}, nil
+	}
+	definitions, err := candidate.resolveDuplicateKeys("", raw.Flags)
+	if err != nil {
+		return nil, []ConfigIssue{{Message: err.Error()}}, nil
+	}
+	if definitions, err = candidate.checkFlagKeys(definitions); err != nil {
+		return nil, []ConfigIssue{{Message: err.Error()}}, nil
+	}
+
+	var issues []ConfigIssue
+	flags := make(map[string]model.Flag, len(definitions))
+	for key, definition := range definitions {
+		flag, err := candidate.validateFlagDefinition(flagSchema, key, definition, candidate.parseRule)
+		if err != nil {
+			issues = append(issues, ConfigIssue{FlagKey: key, Message: err.Error()})
+			continue
+		}
+		flags[key] = flag
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].FlagKey < issues[j].FlagKey
+	})
+	if err := candidate.checkDerivedCycles("", flags, true); err != nil {
+		issues = append(issues, ConfigIssue{Message: err.Error()})
+	}
+	return flags, issues, nil
+}
+
+// validateConfig validates the candidate configuration with the evaluator, if it's able to
+func validateConfig(evaluator IEvaluator, config string) ([]ConfigIssue, error) {
+	validation, ok := evaluator.(ConfigValidation)
+	if !ok {
+		return nil, errors.New("the evaluator can't validate configurations")
+	}
+	return validation.ValidateConfig(config)
+}
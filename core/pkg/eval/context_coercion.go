@@ -0,0 +1,177 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// ContextCoercion defines how evaluation context values compared by targeting rules are converted to the type of the
+// comparison, e.g. numbers sent as strings by clients
+type ContextCoercion string
+
+const (
+	// ContextCoercionOff compares evaluation context values as they are, the default
+	ContextCoercionOff ContextCoercion = "off"
+	// ContextCoercionCoerce converts numeric and boolean strings to the type of the comparison, other values are
+	// compared as they are
+	ContextCoercionCoerce ContextCoercion = "coerce"
+	// ContextCoercionStrict fails evaluations comparing evaluation context values of another type than the comparison
+	// with an invalid context error
+	ContextCoercionStrict ContextCoercion = "strict"
+)
+
+const (
+	// coerceOperator converts the operands of comparisons, it's added to parsed targeting rules and isn't listed by
+	// SupportedOperators
+	coerceOperator = "$coerce"
+
+	numberType  = "number"
+	booleanType = "boolean"
+)
+
+// comparisonOperators are the json-logic operators comparing their operands with the type of their literal operands
+var comparisonOperators = map[string]struct{}{
+	"==": {}, "===": {}, "!=": {}, "!==": {}, "<": {}, "<=": {}, ">": {}, ">=": {},
+}
+
+// ParseContextCoercion returns the context coercion of its name, an empty name defaults to off
+func ParseContextCoercion(mode string) (ContextCoercion, error) {
+	switch ContextCoercion(mode) {
+	case "":
+		return ContextCoercionOff, nil
+	case ContextCoercionOff, ContextCoercionCoerce, ContextCoercionStrict:
+		return ContextCoercion(mode), nil
+	default:
+		return "", fmt.Errorf("unknown context coercion: '%s', expected one of '%s', '%s' or '%s'",
+			mode, ContextCoercionOff, ContextCoercionCoerce, ContextCoercionStrict)
+	}
+}
+
+// WithContextCoercion sets how evaluation context values compared by targeting rules are converted to the type of
+// the comparison
+func WithContextCoercion(mode ContextCoercion) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.contextCoercion = mode
+	}
+}
+
+// coerceRule wraps the operations compared by the comparisons of a parsed targeting rule, e.g. the var references,
+// with the coercion to the type of the comparison, unless coercion is off
+func (je *JSONEvaluator) coerceRule(rule interface{}) {
+	if je.contextCoercion == "" || je.contextCoercion == ContextCoercionOff {
+		return
+	}
+	coerceComparisons(rule, je.contextCoercion)
+}
+
+func coerceComparisons(rule interface{}, mode ContextCoercion) {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for operator, args := range r {
+			coerceComparisons(args, mode)
+			operands, ok := args.([]interface{})
+			if !ok {
+				continue
+			}
+			target, ok := comparisonType(operator, operands)
+			if !ok {
+				continue
+			}
+			for i, operand := range operands {
+				if _, ok := operand.(map[string]interface{}); ok {
+					operands[i] = map[string]interface{}{
+						coerceOperator: []interface{}{operand, target, string(mode)},
+					}
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range r {
+			coerceComparisons(item, mode)
+		}
+	}
+}
+
+// comparisonType returns the type the operands of a comparison are compared as: numbers for the numeric operators,
+// otherwise the type of the first literal number or boolean operand. Other operations aren't comparisons.
+func comparisonType(operator string, operands []interface{}) (string, bool) {
+	switch operator {
+	case greaterThanOperator, lessThanOperator, betweenOperator:
+		return numberType, true
+	}
+	if _, ok := comparisonOperators[operator]; !ok {
+		return "", false
+	}
+	for _, operand := range operands {
+		switch operand.(type) {
+		case float64:
+			return numberType, true
+		case bool:
+			return booleanType, true
+		}
+	}
+	return "", false
+}
+
+// coerce converts a compared value to the type of the comparison, e.g. {"$coerce": [{"var": "age"}, "number",
+// "coerce"]}. Strict coercions of values of another type fail the evaluation, as json-logic operations don't return
+// errors the failure is raised as a panic, which ApplyInterface recovers.
+func (je *JSONEvaluator) coerce(values, _ interface{}) interface{} {
+	args, ok := values.([]interface{})
+	if !ok || len(args) != 3 {
+		je.Logger.Error(fmt.Sprintf("parse %s data: data isn't length 3", coerceOperator))
+		return nil
+	}
+	target, _ := args[1].(string)
+	if ContextCoercion(fmt.Sprint(args[2])) == ContextCoercionStrict {
+		if !isType(args[0], target) {
+			// the value is omitted as it may come from the context
+			je.Logger.Warn(fmt.Sprintf("compared value of type %T isn't a %s", args[0], target))
+			panic(errors.New(model.InvalidContextErrorCode))
+		}
+		return args[0]
+	}
+	return coerceValue(args[0], target)
+}
+
+// isType reports whether the value is of the type, missing values being of any type
+func isType(value interface{}, target string) bool {
+	switch value.(type) {
+	case nil:
+		return true
+	case float64:
+		return target == numberType
+	case bool:
+		return target == booleanType
+	default:
+		return false
+	}
+}
+
+// coerceValue converts numeric strings to finite numbers, and the strings true and false to booleans whatever their
+// case, surrounding whitespace aside. Other values are left as they are.
+func coerceValue(value interface{}, target string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	s = strings.TrimSpace(s)
+	if target == numberType {
+		if n, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return n
+		}
+		return value
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
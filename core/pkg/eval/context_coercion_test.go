@@ -0,0 +1,131 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const coercionFlagConfig = `{
+  "flags": {
+    "adult": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ ">=": [{ "var": "age" }, 18] }, "on", "off"] }
+    },
+    "exactAge": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "===": [{ "var": "age" }, 30] }, "on", "off"] }
+    },
+    "spender": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "between": [{ "var": "spend" }, 100, 500] }, "on", "off"] }
+    },
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "===": [true, { "var": "beta" }] }, "on", "off"] }
+    }
+  }
+}`
+
+func TestContextCoercion(t *testing.T) {
+	off, coerce, strict := eval.ContextCoercionOff, eval.ContextCoercionCoerce, eval.ContextCoercionStrict
+	invalid := model.InvalidContextErrorCode
+	tests := map[string]struct {
+		mode    eval.ContextCoercion
+		flagKey string
+		context map[string]interface{}
+		want    bool
+		errCode string
+	}{
+		"off keeps strings":           {mode: off, flagKey: "exactAge", context: age("30")},
+		"off numeric operator":        {mode: off, flagKey: "spender", context: spend("250"), want: true},
+		"string to number":            {mode: coerce, flagKey: "exactAge", context: age("30"), want: true},
+		"padded string to number":     {mode: coerce, flagKey: "adult", context: age(" 42 "), want: true},
+		"decimal string to number":    {mode: coerce, flagKey: "exactAge", context: age("30.0"), want: true},
+		"numbers are left as is":      {mode: coerce, flagKey: "exactAge", context: age(30.0), want: true},
+		"non numeric string":          {mode: coerce, flagKey: "exactAge", context: age("thirty")},
+		"string to boolean":           {mode: coerce, flagKey: "beta", context: beta("true"), want: true},
+		"uppercase string to boolean": {mode: coerce, flagKey: "beta", context: beta("TRUE"), want: true},
+		"non boolean string":          {mode: coerce, flagKey: "beta", context: beta("yes")},
+		"number isn't a boolean":      {mode: coerce, flagKey: "beta", context: beta(1.0)},
+		"missing value":               {mode: coerce, flagKey: "adult", context: map[string]interface{}{}},
+		"strict numbers":              {mode: strict, flagKey: "adult", context: age(42.0), want: true},
+		"strict booleans":             {mode: strict, flagKey: "beta", context: beta(true), want: true},
+		"strict missing value":        {mode: strict, flagKey: "adult", context: map[string]interface{}{}},
+		"strict numeric string":       {mode: strict, flagKey: "adult", context: age("42"), errCode: invalid},
+		"strict numeric operator":     {mode: strict, flagKey: "spender", context: spend("250"), errCode: invalid},
+		"strict boolean string":       {mode: strict, flagKey: "beta", context: beta("true"), errCode: invalid},
+		"strict number isn't boolean": {mode: strict, flagKey: "beta", context: beta(1.0), errCode: invalid},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, coercionFlagConfig, eval.WithContextCoercion(tt.mode))
+			require.Nil(t, err)
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", tt.flagKey, ctx)
+			if tt.errCode != "" {
+				require.EqualError(t, err, tt.errCode)
+				require.Equal(t, model.ErrorReason, reason)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestContextCoercion_ComparedOperations(t *testing.T) {
+	// comparisons without literal numbers or booleans aren't coerced, so string comparisons are left as is
+	config := `{
+  "flags": {
+    "sameTier": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "tier" }, { "var": "plan" }] }, "on", "off"] }
+    },
+    "namedTier": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "tier" }, "007"] }, "on", "off"] }
+    }
+  }
+}`
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, config, eval.WithContextCoercion(eval.ContextCoercionStrict))
+	require.Nil(t, err)
+	ctx, err := structpb.NewStruct(map[string]interface{}{"tier": "007", "plan": "007"})
+	require.Nil(t, err)
+	for _, flagKey := range []string{"sameTier", "namedTier"} {
+		value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", flagKey, ctx)
+		require.Nil(t, err)
+		require.True(t, value, flagKey)
+	}
+}
+
+func TestParseContextCoercion(t *testing.T) {
+	mode, err := eval.ParseContextCoercion("")
+	require.Nil(t, err)
+	require.Equal(t, eval.ContextCoercionOff, mode)
+
+	mode, err = eval.ParseContextCoercion("strict")
+	require.Nil(t, err)
+	require.Equal(t, eval.ContextCoercionStrict, mode)
+
+	_, err = eval.ParseContextCoercion("loose")
+	require.EqualError(t, err, "unknown context coercion: 'loose', expected one of 'off', 'coerce' or 'strict'")
+}
+
+func age(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"age": value}
+}
+
+func spend(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"spend": value}
+}
+
+func beta(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"beta": value}
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+)
+
+// ContextKeysMetadataKey is the metadata key holding the comma separated context keys the evaluation of a flag reads,
+// so clients can prune the context they send for the flag
+const ContextKeysMetadataKey = "contextKeys"
+
+// ContextKeys is implemented by evaluators able to analyse the context keys the evaluation of flags reads
+type ContextKeys interface {
+	// FlagContextKeys returns the context keys the evaluation of the flag reads, false if the flag doesn't exist
+	FlagContextKeys(flagKey string) (FlagContextKeys, bool)
+}
+
+// FlagContextKeys are the evaluation context keys read by the evaluation of a flag
+type FlagContextKeys struct {
+	// Keys are the sorted dot separated context paths referenced by the targeting rules and rulesets of the flag, its
+	// default variant by context, required context keys and template placeholders, along with the keys of the flags it
+	// is derived from
+	Keys []string
+	// Unbounded is whether a var operation of the rules has no literal path, computed or referencing the whole
+	// context, in which case the evaluation may read keys other than Keys
+	Unbounded bool
+}
+
+// WithContextKeysMetadata adds the context keys each flag reads to the metadata of its resolutions, unless its rules
+// may read any key of the context
+func WithContextKeysMetadata(enabled bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.contextKeysMetadata = enabled
+	}
+}
+
+// FlagContextKeys returns the context keys read by the evaluation of the flag. The keys are computed from the
+// current definition of the flag, their parsed rules are cached along with the rules evaluated.
+func (je *JSONEvaluator) FlagContextKeys(flagKey string) (FlagContextKeys, bool) {
+	flagKey = je.namespaceKey("", flagKey)
+	if _, ok := je.store.Get(flagKey); !ok {
+		return FlagContextKeys{}, false
+	}
+	referenced := map[string]struct{}{}
+	unbounded := je.collectFlagContextKeys(flagKey, referenced, map[string]struct{}{})
+	if je.overrideSecret != nil && je.fallsBackTo(FallbackOverride) {
+		referenced[OverrideTokenContextKey] = struct{}{}
+	}
+	if je.fallsBackTo(FallbackClientDefault) {
+		referenced[ClientDefaultContextKey] = struct{}{}
+	}
+	keys := make([]string, 0, len(referenced))
+	for key := range referenced {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return FlagContextKeys{Keys: keys, Unbounded: unbounded}, true
+}
+
+// collectFlagContextKeys adds the context keys read by the evaluation of the flag to the referenced keys, and returns
+// whether its rules may read any key. Visited flags guard against cycles of derived flags.
+func (je *JSONEvaluator) collectFlagContextKeys(
+	flagKey string, referenced map[string]struct{}, visited map[string]struct{},
+) bool {
+	if _, ok := visited[flagKey]; ok {
+		return false
+	}
+	visited[flagKey] = struct{}{}
+	flag, ok := je.store.Get(flagKey)
+	if !ok {
+		return false
+	}
+
+	unbounded := false
+	if len(flag.Targeting) != 0 {
+		if rule, err := je.targetingRule(flagKey, flag.Targeting); err == nil {
+			unbounded = collectGovernedContextKeys(rule, referenced, false)
+		}
+	}
+	if flag.Rulesets != nil {
+		referenced[flag.Rulesets.ContextKey] = struct{}{}
+		for name, targeting := range flag.Rulesets.Rules {
+			if rule, err := je.targetingRule(rulesetRuleKey(flagKey, name), targeting); err == nil {
+				unbounded = collectGovernedContextKeys(rule, referenced, false) || unbounded
+			}
+		}
+	}
+	if flag.DefaultVariantByContext != nil {
+		referenced[flag.DefaultVariantByContext.ContextKey] = struct{}{}
+	}
+	for _, key := range flag.RequireContext {
+		referenced[key] = struct{}{}
+	}
+	if flag.Template {
+		for _, variant := range flag.Variants {
+			template, ok := variant.(string)
+			if !ok {
+				continue
+			}
+			parts, err := parseTemplate(template)
+			if err != nil {
+				continue
+			}
+			for _, part := range parts {
+				if part.key != "" {
+					referenced[part.key] = struct{}{}
+				}
+			}
+		}
+	}
+	if flag.Derived != nil {
+		if expr, err := je.derivedExpression(flagKey, flag.Derived); err == nil {
+			for _, prerequisite := range expr.references() {
+				unbounded = je.collectFlagContextKeys(prerequisite, referenced, visited) || unbounded
+			}
+		}
+	}
+	return unbounded
+}
+
+// withContextKeys adds the context keys read by the flag to the metadata of a resolution, unless they're unbounded
+func (je *JSONEvaluator) withContextKeys(flagKey string, metadata map[string]interface{}) map[string]interface{} {
+	keys, ok := je.FlagContextKeys(flagKey)
+	if !ok || keys.Unbounded {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata[ContextKeysMetadataKey] = strings.Join(keys.Keys, ",")
+	return metadata
+}
+
+// FlagContextKeys returns the context keys read by the flag in the stable configuration, or in the candidate one for
+// new flags
+func (ce *CanaryEvaluator) FlagContextKeys(flagKey string) (FlagContextKeys, bool) {
+	if keys, ok := flagContextKeysOf(ce.stable, flagKey); ok {
+		return keys, true
+	}
+	return flagContextKeysOf(ce.candidate, flagKey)
+}
+
+// FlagContextKeys returns the context keys read by the flag in the shared configuration or the configuration of any
+// tenant, along with the tenant key routing evaluations to the configuration of their tenant
+func (te *TenantEvaluator) FlagContextKeys(flagKey string) (FlagContextKeys, bool) {
+	var merged FlagContextKeys
+	found := false
+	for _, evaluator := range te.evaluators() {
+		keys, ok := flagContextKeysOf(evaluator, flagKey)
+		if !ok {
+			continue
+		}
+		found = true
+		for _, key := range keys.Keys {
+			merged.Keys = appendContextKey(merged.Keys, key)
+		}
+		merged.Unbounded = merged.Unbounded || keys.Unbounded
+	}
+	if !found {
+		return FlagContextKeys{}, false
+	}
+	merged.Keys = appendContextKey(merged.Keys, te.contextKey)
+	return merged, true
+}
+
+// flagContextKeysOf returns the context keys read by the flag in the evaluator, false if it can't analyse them or the
+// flag doesn't exist
+func flagContextKeysOf(evaluator IEvaluator, flagKey string) (FlagContextKeys, bool) {
+	analysis, ok := evaluator.(ContextKeys)
+	if !ok {
+		return FlagContextKeys{}, false
+	}
+	return analysis.FlagContextKeys(flagKey)
+}
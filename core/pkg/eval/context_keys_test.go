@@ -0,0 +1,155 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const contextKeysFlagConfig = `{
+  "flags": {
+    "nested": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "and": [
+            { "in": ["@faas.com", { "var": "user.email" }] },
+            { "or": [{ ">": [{ "var": ["orders", 0] }, 10] }, { "missing_some": [1, ["plan", "tier"]] }] }
+          ] },
+          "blue",
+          { "some": [{ "var": "groups" }, { "==": [{ "var": "name" }, { "var": "$flagd.flagKey" }] }] },
+          "green",
+          { "fractionalEvaluation": [["targetingKey", "company.id"], ["red", 50], ["blue", 50]] }
+        ]
+      }
+    },
+    "unbounded": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": { "cat": ["e", "mail"] } }, "x"] }, "on", null] }
+    },
+    "static": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "greeting": {
+      "state": "ENABLED",
+      "variants": { "plain": "Hello {user.name}, welcome to {site}" },
+      "defaultVariant": "plain",
+      "template": true,
+      "requireContext": ["locale"],
+      "defaultVariantByContext": { "contextKey": "region", "variants": { "eu": "plain" } }
+    },
+    "perEnvironment": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "rulesets": {
+        "contextKey": "environment",
+        "rules": {
+          "staging": { "if": [{ "missing": "beta" }, "off", "on"] },
+          "production": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "on", "off"] }
+        }
+      }
+    },
+    "derived": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "and": ["perEnvironment", { "not": "static" }] }
+    }
+  }
+}`
+
+func TestFlagContextKeys(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextKeysFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]eval.FlagContextKeys{
+		"nested": {
+			Keys: []string{"company.id", "groups", "orders", "plan", "targetingKey", "tier", "user.email"},
+		},
+		"unbounded": {Keys: []string{}, Unbounded: true},
+		"static":    {Keys: []string{}},
+		"greeting":  {Keys: []string{"locale", "region", "site", "user.name"}},
+		"perEnvironment": {
+			Keys: []string{"beta", "environment", "plan"},
+		},
+		"derived": {Keys: []string{"beta", "environment", "plan"}},
+	}
+	for flagKey, want := range tests {
+		t.Run(flagKey, func(t *testing.T) {
+			keys, ok := evaluator.FlagContextKeys(flagKey)
+			require.True(t, ok)
+			require.Equal(t, want, keys)
+		})
+	}
+
+	_, ok := evaluator.FlagContextKeys("missing")
+	require.False(t, ok)
+}
+
+func TestFlagContextKeys_ConfigChange(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "blue", null] }
+    }
+  }
+}`, Type: sync.ALL})
+	require.Nil(t, err)
+	keys, ok := evaluator.FlagContextKeys("headerColor")
+	require.True(t, ok)
+	require.Equal(t, []string{"plan"}, keys.Keys)
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "country" }, "fr"] }, "blue", null] }
+    }
+  }
+}`, Type: sync.ALL})
+	require.Nil(t, err)
+	keys, ok = evaluator.FlagContextKeys("headerColor")
+	require.True(t, ok)
+	require.Equal(t, []string{"country"}, keys.Keys, "the keys should follow the definition of the flag")
+}
+
+func TestContextKeysMetadata(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextKeysFlagConfig, eval.WithContextKeysMetadata(true))
+	require.Nil(t, err)
+
+	_, _, _, metadata, err := evaluator.ResolveBooleanValue(context.Background(), "", "perEnvironment", nil)
+	require.Nil(t, err)
+	require.Equal(t, "beta,environment,plan", metadata[eval.ContextKeysMetadataKey])
+
+	_, _, _, metadata, err = evaluator.ResolveBooleanValue(context.Background(), "", "static", nil)
+	require.Nil(t, err)
+	require.Equal(t, "", metadata[eval.ContextKeysMetadataKey], "flags reading no key should hold an empty list")
+
+	_, _, _, metadata, err = evaluator.ResolveBooleanValue(context.Background(), "", "unbounded", nil)
+	require.Nil(t, err)
+	require.NotContains(t, metadata, eval.ContextKeysMetadataKey, "flags which may read any key can't be pruned")
+
+	evaluator, err = eval.NewJSONEvaluatorFromConfig(nil, contextKeysFlagConfig)
+	require.Nil(t, err)
+	_, _, _, metadata, err = evaluator.ResolveBooleanValue(context.Background(), "", "perEnvironment", nil)
+	require.Nil(t, err)
+	require.NotContains(t, metadata, eval.ContextKeysMetadataKey)
+}
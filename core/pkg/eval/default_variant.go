@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// WithDefaultVariantFallback makes flags lacking a valid default variant fall back to their first variant, with a
+// warning, instead of rejecting the configuration
+func WithDefaultVariantFallback(fallback bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.defaultVariantFallback = fallback
+	}
+}
+
+// applyDefaultVariantFallback sets the default variant of a flag to its first variant, in declaration order, if it
+// is missing or isn't one of the variants. Flags which can't be fixed are returned unchanged to fail validation.
+func (je *JSONEvaluator) applyDefaultVariantFallback(key string, raw json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw
+	}
+	var variants map[string]json.RawMessage
+	if err := json.Unmarshal(fields["variants"], &variants); err != nil || len(variants) == 0 {
+		return raw
+	}
+	var defaultVariant string
+	if err := json.Unmarshal(fields["defaultVariant"], &defaultVariant); err == nil {
+		if _, ok := variants[defaultVariant]; ok {
+			return raw
+		}
+	}
+
+	first, err := firstKey(fields["variants"])
+	if err != nil {
+		return raw
+	}
+	fields["defaultVariant"], _ = json.Marshal(first)
+	fixed, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	je.Logger.Warn(fmt.Sprintf(
+		"flag: '%s' has no valid default variant, falling back to its first variant: '%s'", key, first,
+	))
+	return fixed
+}
+
+// firstKey returns the first key of a JSON object, in declaration order
+func firstKey(object json.RawMessage) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return "", err
+	}
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v", token)
+	}
+	return key, nil
+}
+
+// validateDefaultVariantByContext checks the default variants a flag maps context values to are variants of the flag
+func validateDefaultVariantByContext(key string, flag model.Flag) error {
+	byContext := flag.DefaultVariantByContext
+	if byContext == nil {
+		return nil
+	}
+	if byContext.ContextKey == "" {
+		return fmt.Errorf("default variant by context of flag: '%s' has no context key", key)
+	}
+	for value, variant := range byContext.Variants {
+		if _, ok := flag.Variants[variant]; !ok {
+			return fmt.Errorf("default variant: '%s' of context value: '%s' isn't a valid variant of flag: '%s'",
+				variant, value, key)
+		}
+	}
+	return nil
+}
+
+// defaultVariant returns the default variant of the flag for the context, the variant mapped to the value of the
+// context key of its default variant by context if any, its default variant otherwise
+func (je *JSONEvaluator) defaultVariant(flag model.Flag, context *structpb.Struct) string {
+	byContext := flag.DefaultVariantByContext
+	if byContext == nil {
+		return flag.DefaultVariant
+	}
+	value, ok := contextValueKey(contextPathValue(je.normalizeContext(context.AsMap()), byContext.ContextKey))
+	if !ok {
+		return flag.DefaultVariant
+	}
+	if variant, ok := byContext.Variants[value]; ok {
+		return variant
+	}
+	return flag.DefaultVariant
+}
+
+// defaultReason is the reason of resolving the default variant of a flag without targeting
+func defaultReason(flag model.Flag) string {
+	if flag.DefaultVariantByContext != nil || flag.Rulesets != nil {
+		// the default variant or the targeting depends on the context, it isn't static
+		return model.DefaultReason
+	}
+	return model.StaticReason
+}
+
+// contextValueKey returns the key of a context value in a default variant by context mapping, strings are their own
+// key and numbers and booleans are keyed by their json. Missing values and objects aren't mapped.
+func contextValueKey(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, float64:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(raw), true
+	default:
+		return "", false
+	}
+}
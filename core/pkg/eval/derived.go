@@ -0,0 +1,262 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	derivedAnd = "and"
+	derivedOr  = "or"
+	derivedNot = "not"
+)
+
+// derivedExpression is the parsed derived expression of a flag, either a reference to a boolean flag or an operator
+// over nested expressions
+type derivedExpression struct {
+	flagKey  string
+	operator string
+	args     []derivedExpression
+}
+
+func parseDerivedExpression(raw interface{}) (derivedExpression, error) {
+	switch expr := raw.(type) {
+	case string:
+		if expr == "" {
+			return derivedExpression{}, errors.New("empty flag key")
+		}
+		return derivedExpression{flagKey: expr}, nil
+	case map[string]interface{}:
+		if len(expr) != 1 {
+			return derivedExpression{}, fmt.Errorf("expected a single operator, got %d", len(expr))
+		}
+		for operator, value := range expr {
+			args, ok := value.([]interface{})
+			if !ok {
+				// a single argument may be passed without an array, e.g. {"not": "flagKey"}
+				args = []interface{}{value}
+			}
+			switch {
+			case operator == derivedNot && len(args) != 1:
+				return derivedExpression{}, fmt.Errorf("%s expects a single argument, got %d", operator, len(args))
+			case (operator == derivedAnd || operator == derivedOr) && len(args) == 0:
+				return derivedExpression{}, fmt.Errorf("%s expects at least one argument", operator)
+			case operator != derivedNot && operator != derivedAnd && operator != derivedOr:
+				return derivedExpression{}, fmt.Errorf("unsupported operator: %s", operator)
+			}
+			parsed := derivedExpression{operator: operator, args: make([]derivedExpression, 0, len(args))}
+			for _, arg := range args {
+				argExpr, err := parseDerivedExpression(arg)
+				if err != nil {
+					return derivedExpression{}, err
+				}
+				parsed.args = append(parsed.args, argExpr)
+			}
+			return parsed, nil
+		}
+	}
+	return derivedExpression{}, fmt.Errorf("expected a flag key or an operator, got %v", raw)
+}
+
+// references returns the flag keys referenced by the expression
+func (d derivedExpression) references() []string {
+	if d.flagKey != "" {
+		return []string{d.flagKey}
+	}
+	var keys []string
+	for _, arg := range d.args {
+		keys = append(keys, arg.references()...)
+	}
+	return keys
+}
+
+func unmarshalDerivedExpression(raw json.RawMessage) (derivedExpression, error) {
+	var expr interface{}
+	if err := json.Unmarshal(raw, &expr); err != nil {
+		return derivedExpression{}, err
+	}
+	return parseDerivedExpression(expr)
+}
+
+// validateDerived checks the derived expression of a flag, which may only be set on boolean flags without targeting
+func validateDerived(key string, flag model.Flag) error {
+	if flag.Targeting != nil && string(flag.Targeting) != "{}" {
+		return fmt.Errorf("flag: '%s' can't be derived and have a targeting", key)
+	}
+	for variant, value := range flag.Variants {
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("derived flag: '%s' has a non boolean variant: '%s'", key, variant)
+		}
+	}
+	if _, err := unmarshalDerivedExpression(flag.Derived); err != nil {
+		return fmt.Errorf("derived expression of flag: '%s': %w", key, err)
+	}
+	return nil
+}
+
+// checkDerivedCycles rejects configurations whose derived flags depend on themselves, along with the flags of the
+// store. The flags of the source are replaced rather than added to when replace is set.
+func (je *JSONEvaluator) checkDerivedCycles(source string, flags map[string]model.Flag, replace bool) error {
+	all := map[string]model.Flag{}
+	for key, flag := range je.store.GetAll() {
+		if !replace || flag.Source != source {
+			all[key] = flag
+		}
+	}
+	for key, flag := range flags {
+		all[key] = flag
+	}
+
+	dependencies := map[string][]string{}
+	for key, flag := range all {
+		if flag.Derived == nil {
+			continue
+		}
+		expr, err := unmarshalDerivedExpression(flag.Derived)
+		if err != nil {
+			return fmt.Errorf("derived expression of flag: '%s': %w", key, err)
+		}
+		dependencies[key] = expr.references()
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := map[string]int{}
+	var path []string
+	var visit func(key string) error
+	visit = func(key string) error {
+		switch states[key] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("derived flags form a cycle: %s -> %s", strings.Join(path, " -> "), key)
+		}
+		states[key] = visiting
+		path = append(path, key)
+		for _, dependency := range dependencies[key] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[key] = visited
+		return nil
+	}
+	keys := make([]string, 0, len(dependencies))
+	for key := range dependencies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := visit(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateDerived resolves the derived expression of a flag over the values of its prerequisite flags, returning
+// the variant holding the result
+func (je *JSONEvaluator) evaluateDerived(
+	ctx context.Context,
+	reqID string,
+	flagKey string,
+	flag model.Flag,
+	context *structpb.Struct,
+	path []string,
+	chain *resolutionChain,
+) (string, string, map[string]interface{}, error) {
+	expr, err := je.derivedExpression(flagKey, flag.Derived)
+	if err != nil {
+		je.Logger.ErrorWithID(reqID, fmt.Sprintf("error parsing derived expression of flag: %s, %s", flagKey, err))
+		return "", model.ErrorReason, nil, errors.New(model.ParseErrorCode)
+	}
+	result, err := je.evaluateDerivedExpression(ctx, reqID, expr, context, append(path, flagKey), chain)
+	if ctx.Err() != nil {
+		return "", model.ErrorReason, nil, ctx.Err()
+	}
+	if err != nil {
+		je.Logger.ErrorWithID(reqID, fmt.Sprintf("error evaluating derived flag: %s, %s", flagKey, err))
+		return "", model.ErrorReason, nil, errors.New(model.GeneralErrorCode)
+	}
+
+	variants := make([]string, 0, len(flag.Variants))
+	for variant := range flag.Variants {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	for _, variant := range variants {
+		if flag.Variants[variant] == result {
+			return variant, model.DerivedReason, resolutionMetadata(flag, nil), nil
+		}
+	}
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("returning default variant for flagKey: %s, no variant is %t",
+		flagKey, result))
+	return flag.DefaultVariant, model.DefaultReason, resolutionMetadata(flag, nil), nil
+}
+
+func (je *JSONEvaluator) derivedExpression(flagKey string, raw json.RawMessage) (derivedExpression, error) {
+	if cached, ok := je.derived.get(flagKey, raw); ok {
+		return cached.rule.(derivedExpression), nil
+	}
+	expr, err := unmarshalDerivedExpression(raw)
+	if err != nil {
+		return expr, err
+	}
+	je.derived.set(flagKey, cachedRule{raw: string(raw), rule: expr})
+	return expr, nil
+}
+
+// evaluateDerivedExpression evaluates the expression, and and or short-circuit. The path of derived flags being
+// evaluated guards against cycles of flags stored without being checked, the evaluated prerequisites are recorded to
+// chain.
+func (je *JSONEvaluator) evaluateDerivedExpression(
+	ctx context.Context,
+	reqID string,
+	expr derivedExpression,
+	context *structpb.Struct,
+	path []string,
+	chain *resolutionChain,
+) (bool, error) {
+	switch expr.operator {
+	case derivedNot:
+		result, err := je.evaluateDerivedExpression(ctx, reqID, expr.args[0], context, path, chain)
+		return !result, err
+	case derivedAnd, derivedOr:
+		// and stops at the first false argument and or at the first true one
+		stop := expr.operator == derivedOr
+		for _, arg := range expr.args {
+			result, err := je.evaluateDerivedExpression(ctx, reqID, arg, context, path, chain)
+			if err != nil || result == stop {
+				return result, err
+			}
+		}
+		return !stop, nil
+	}
+
+	for _, key := range path {
+		if key == expr.flagKey {
+			return false, fmt.Errorf("derived flags form a cycle: %s -> %s", strings.Join(path, " -> "), key)
+		}
+	}
+	variant, reason, _, err := je.evaluateFlag(ctx, reqID, expr.flagKey, context, path, chain)
+	chain.record(expr.flagKey, len(path), variant, reason, err)
+	if err != nil {
+		return false, fmt.Errorf("prerequisite flag: %s: %w", expr.flagKey, err)
+	}
+	prerequisite, _ := je.store.Get(expr.flagKey)
+	result, ok := prerequisite.Variants[variant].(bool)
+	if !ok {
+		return false, fmt.Errorf("prerequisite flag: %s isn't a boolean flag", expr.flagKey)
+	}
+	return result, nil
+}
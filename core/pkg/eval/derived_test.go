@@ -0,0 +1,237 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const derivedFlagConfig = `{
+  "flags": {
+    "betaUser": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "beta" }, true] }, "on", null] }
+    },
+    "internalUser": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "on", null] }
+    },
+    "killSwitch": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    },
+    "legacyCheckout": {
+      "state": "ENABLED",
+      "variants": { "enabled": true, "disabled": false },
+      "defaultVariant": "disabled",
+      "derived": { "not": "newCheckout" }
+    },
+    "newCheckout": {
+      "state": "ENABLED",
+      "variants": { "enabled": true, "disabled": false },
+      "defaultVariant": "disabled",
+      "derived": { "and": [{ "or": ["betaUser", "internalUser"] }, { "not": "killSwitch" }] }
+    }
+  }
+}`
+
+func TestDerivedFlags(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, derivedFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		context     map[string]interface{}
+		flagKey     string
+		wantValue   bool
+		wantVariant string
+	}{
+		"and of or": {
+			context:     map[string]interface{}{"beta": true},
+			flagKey:     "newCheckout",
+			wantValue:   true,
+			wantVariant: "enabled",
+		},
+		"and of or, other argument": {
+			context:     map[string]interface{}{"email": "user@faas.com"},
+			flagKey:     "newCheckout",
+			wantValue:   true,
+			wantVariant: "enabled",
+		},
+		"and of or, no argument": {
+			context:     map[string]interface{}{"email": "user@example.com"},
+			flagKey:     "newCheckout",
+			wantValue:   false,
+			wantVariant: "disabled",
+		},
+		"not of derived flag": {
+			context:     map[string]interface{}{"beta": true},
+			flagKey:     "legacyCheckout",
+			wantValue:   false,
+			wantVariant: "disabled",
+		},
+		"not of derived flag, false": {
+			context:     map[string]interface{}{},
+			flagKey:     "legacyCheckout",
+			wantValue:   true,
+			wantVariant: "enabled",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, variant, reason, _, err := je.ResolveBooleanValue(context.Background(), "", tt.flagKey, evalCtx)
+			require.Nil(t, err)
+			require.Equal(t, tt.wantValue, value)
+			require.Equal(t, tt.wantVariant, variant)
+			require.Equal(t, model.DerivedReason, reason)
+		})
+	}
+
+	t.Run("kill switch", func(t *testing.T) {
+		_, _, err := je.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "killSwitch": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`, Source: "killSwitch.json", Type: sync.ADD})
+		require.Nil(t, err)
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"beta": true})
+		require.Nil(t, err)
+		value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", "newCheckout", evalCtx)
+		require.Nil(t, err)
+		require.False(t, value)
+		require.Equal(t, model.DerivedReason, reason)
+	})
+}
+
+func TestDerivedFlags_PrerequisiteErrors(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "disabledFlag": {
+      "state": "DISABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "stringFlag": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red"
+    },
+    "missingPrerequisite": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": "missingFlag"
+    },
+    "disabledPrerequisite": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "not": "disabledFlag" }
+    },
+    "stringPrerequisite": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "or": ["stringFlag"] }
+    }
+  }
+}`)
+	require.Nil(t, err)
+	for _, flagKey := range []string{"missingPrerequisite", "disabledPrerequisite", "stringPrerequisite"} {
+		t.Run(flagKey, func(t *testing.T) {
+			_, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", flagKey, &structpb.Struct{})
+			require.EqualError(t, err, model.GeneralErrorCode)
+			require.Equal(t, model.ErrorReason, reason)
+		})
+	}
+}
+
+func TestDerivedFlags_InvalidConfig(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		wantErr string
+	}{
+		"self reference": {
+			config: `{"flags": {"a": {"state": "ENABLED", "variants": {"on": true, "off": false},
+				"defaultVariant": "on", "derived": {"not": "a"}}}}`,
+			wantErr: "derived flags form a cycle: a -> a",
+		},
+		"cycle": {
+			config: `{"flags": {
+				"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on",
+					"derived": {"and": ["c", "b"]}},
+				"b": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on",
+					"derived": {"or": ["a"]}},
+				"c": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+			}}`,
+			wantErr: "derived flags form a cycle: a -> b -> a",
+		},
+		"non boolean variant": {
+			config: `{"flags": {"a": {"state": "ENABLED", "variants": {"on": "yes", "off": "no"},
+				"defaultVariant": "on", "derived": "b"}}}`,
+			wantErr: "derived flag: 'a' has a non boolean variant: '",
+		},
+		"targeting": {
+			config: `{"flags": {"a": {"state": "ENABLED", "variants": {"on": true, "off": false},
+				"defaultVariant": "on", "derived": "b", "targeting": {"if": [true, "on", "off"]}}}}`,
+			wantErr: "flag: 'a' can't be derived and have a targeting",
+		},
+		"unsupported operator": {
+			config: `{"flags": {"a": {"state": "ENABLED", "variants": {"on": true, "off": false},
+				"defaultVariant": "on", "derived": {"xor": ["b", "c"]}}}}`,
+			wantErr: "derived expression of flag: 'a': unsupported operator: xor",
+		},
+		"not arguments": {
+			config: `{"flags": {"a": {"state": "ENABLED", "variants": {"on": true, "off": false},
+				"defaultVariant": "on", "derived": {"not": ["b", "c"]}}}}`,
+			wantErr: "derived expression of flag: 'a': not expects a single argument, got 2",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJSONEvaluatorFromConfig(nil, tt.config)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDerivedFlags_CycleAcrossSources(t *testing.T) {
+	je := NewJSONEvaluator(nil, nil)
+	_, _, err := je.SetState(sync.DataSync{FlagData: `{"flags": {"a": {"state": "ENABLED",
+		"variants": {"on": true, "off": false}, "defaultVariant": "on", "derived": "b"}}}`,
+		Source: "a.json", Type: sync.ALL})
+	require.Nil(t, err, "prerequisites may be defined by other sources")
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{"flags": {"b": {"state": "ENABLED",
+		"variants": {"on": true, "off": false}, "defaultVariant": "on", "derived": {"not": "a"}}}}`,
+		Source: "b.json", Type: sync.ALL})
+	require.EqualError(t, err, "derived flags form a cycle: a -> b -> a")
+
+	// replacing the configuration of a source drops its previous dependencies
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{"flags": {"c": {"state": "ENABLED",
+		"variants": {"on": true, "off": false}, "defaultVariant": "on"}}}`, Source: "a.json", Type: sync.ALL})
+	require.Nil(t, err)
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{"flags": {"b": {"state": "ENABLED",
+		"variants": {"on": true, "off": false}, "defaultVariant": "on", "derived": {"not": "c"}}}}`,
+		Source: "b.json", Type: sync.ALL})
+	require.Nil(t, err)
+	value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", "b", &structpb.Struct{})
+	require.Nil(t, err)
+	require.False(t, value)
+	require.Equal(t, model.DerivedReason, reason)
+}
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// flagDefinitions holds every definition of each flag key of a configuration, in the order of the configuration,
+// as json objects may repeat keys
+type flagDefinitions map[string][]json.RawMessage
+
+func (d *flagDefinitions) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("flags isn't an object")
+	}
+	definitions := flagDefinitions{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return errors.New("flag key isn't a string")
+		}
+		var definition json.RawMessage
+		if err := dec.Decode(&definition); err != nil {
+			return err
+		}
+		definitions[key] = append(definitions[key], definition)
+	}
+	*d = definitions
+	return nil
+}
+
+// resolveDuplicateKeys returns a single definition for each flag key, applying the duplicate key policy of the store
+// to keys defined more than once by the source
+func (je *JSONEvaluator) resolveDuplicateKeys(
+	source string, definitions flagDefinitions,
+) (map[string]json.RawMessage, error) {
+	flags := make(map[string]json.RawMessage, len(definitions))
+	for key, defs := range definitions {
+		if len(defs) == 1 {
+			flags[key] = defs[0]
+			continue
+		}
+		kept := "last"
+		switch je.store.DuplicateKeys {
+		case store.DuplicateKeysError:
+			return nil, fmt.Errorf("duplicate flag key: '%s' is defined %d times by source: '%s'",
+				key, len(defs), source)
+		case store.DuplicateKeysFirstWins:
+			flags[key], kept = defs[0], "first"
+		default:
+			flags[key] = defs[len(defs)-1]
+		}
+		je.Logger.Warn(fmt.Sprintf("duplicate flag key: '%s' is defined %d times by source: '%s', keeping the %s",
+			key, len(defs), source, kept))
+	}
+	return flags, nil
+}
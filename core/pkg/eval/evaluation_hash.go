@@ -0,0 +1,144 @@
+package eval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// EvaluationHashMetadataKey is the metadata key holding the hash of an evaluation, identical decisions for the same
+// relevant evaluation context share the hash
+const EvaluationHashMetadataKey = "evaluationHash"
+
+// WithEvaluationHash adds the hash of the flag key, variant, reason and relevant evaluation context of each
+// evaluation to its resolution metadata, so clients can dedupe identical decisions
+func WithEvaluationHash(enabled bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.evaluationHash = enabled
+	}
+}
+
+// withEvaluationHash adds the evaluation hash to the metadata of a resolution of the flag
+func (je *JSONEvaluator) withEvaluationHash(
+	flagKey string, variant string, reason string, metadata map[string]interface{}, context *structpb.Struct,
+) map[string]interface{} {
+	flag, ok := je.store.Get(flagKey)
+	if !ok {
+		return metadata
+	}
+	keys := flag.HashContextKeys
+	if targeting, ruleKey := je.selectTargeting(flagKey, flag, context); keys == nil && targeting != nil {
+		if rule, err := je.targetingRule(ruleKey, targeting); err == nil {
+			keys = referencedContextKeys(rule)
+		}
+	}
+	if flag.HashContextKeys == nil && flag.DefaultVariantByContext != nil {
+		keys = appendContextKey(keys, flag.DefaultVariantByContext.ContextKey)
+	}
+	if flag.HashContextKeys == nil && flag.Rulesets != nil {
+		keys = appendContextKey(keys, flag.Rulesets.ContextKey)
+	}
+	hash, err := evaluationHash(flagKey, variant, reason, keys, je.normalizeContext(context.AsMap()))
+	if err != nil {
+		je.Logger.Warn(fmt.Sprintf("hashing the evaluation of flag: %s: %v", flagKey, err))
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata[EvaluationHashMetadataKey] = hash
+	return metadata
+}
+
+// evaluationHash returns the hex encoded SHA-256 of the canonical json of the decision and the values of the keys of
+// the context. Keys missing from the context hash as null values.
+func evaluationHash(
+	flagKey string, variant string, reason string, keys []string, context map[string]interface{},
+) (string, error) {
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		values[key] = contextPathValue(context, key)
+	}
+	// maps are marshalled with sorted keys, the json is canonical
+	raw, err := json.Marshal(struct {
+		FlagKey string                 `json:"flagKey"`
+		Variant string                 `json:"variant"`
+		Reason  string                 `json:"reason"`
+		Context map[string]interface{} `json:"context"`
+	}{FlagKey: flagKey, Variant: variant, Reason: reason, Context: values})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// contextPathValue returns the value of the dot separated path in the context, as var operations resolve it
+func contextPathValue(context map[string]interface{}, path string) interface{} {
+	var value interface{} = context
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = object[key]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+// referencedContextKeys returns the sorted context paths referenced by the var operations and fractional evaluations
+// of a targeting rule, flagd properties such as $flagd.timestamp aren't part of the context
+func referencedContextKeys(rule interface{}) []string {
+	referenced := map[string]struct{}{}
+	collectContextKeys(rule, referenced)
+	keys := make([]string, 0, len(referenced))
+	for key := range referenced {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendContextKey adds the context path to the sorted paths, unless it's already part of them
+func appendContextKey(keys []string, key string) []string {
+	i := sort.SearchStrings(keys, key)
+	if i < len(keys) && keys[i] == key {
+		return keys
+	}
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+	return keys
+}
+
+func collectContextKeys(rule interface{}, referenced map[string]struct{}) {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for operator, args := range r {
+			switch operator {
+			case varOperator:
+				if path, ok := varPath(args); ok && path != "" && !strings.HasPrefix(path, flagdPropertiesKey) {
+					referenced[path] = struct{}{}
+				}
+			case fractionalEvaluationOperator:
+				if list, ok := args.([]interface{}); ok && len(list) > 0 {
+					for _, bucketBy := range bucketingKeys(list[0]) {
+						referenced[bucketBy] = struct{}{}
+					}
+				}
+			}
+			collectContextKeys(args, referenced)
+		}
+	case []interface{}:
+		for _, item := range r {
+			collectContextKeys(item, referenced)
+		}
+	}
+}
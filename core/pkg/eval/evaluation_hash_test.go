@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const evaluationHashFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "user.email" }, "user@faas.com"] }, "blue", null] }
+    },
+    "explicitKeys": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "user.email" }, "user@faas.com"] }, "blue", null] },
+      "hashContextKeys": ["targetingKey"]
+    },
+    "split": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }
+    },
+    "static": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red"
+    }
+  }
+}`
+
+func TestEvaluationHash(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, evaluationHashFlagConfig, WithEvaluationHash(true))
+	require.Nil(t, err)
+	hash := func(flagKey string, values map[string]interface{}) string {
+		t.Helper()
+		evalCtx, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		_, _, _, metadata, err := je.ResolveStringValue(context.Background(), "", flagKey, evalCtx)
+		require.Nil(t, err)
+		hash, ok := metadata[EvaluationHashMetadataKey].(string)
+		require.True(t, ok, "metadata: %v", metadata)
+		require.Len(t, hash, 64)
+		return hash
+	}
+	user := func(email string, extra map[string]interface{}) map[string]interface{} {
+		context := map[string]interface{}{"user": map[string]interface{}{"email": email}}
+		for k, v := range extra {
+			context[k] = v
+		}
+		return context
+	}
+
+	matched := hash("headerColor", user("user@faas.com", nil))
+	require.Equal(t, matched, hash("headerColor", user("user@faas.com", nil)), "hashes must be stable")
+	require.Equal(t, matched, hash("headerColor", user("user@faas.com", map[string]interface{}{"plan": "pro"})),
+		"context keys the targeting doesn't reference mustn't change the hash")
+	require.NotEqual(t, matched, hash("headerColor", user("other@faas.com", nil)),
+		"a referenced context key changing the decision must change the hash")
+	require.NotEqual(t, hash("headerColor", user("other@faas.com", nil)),
+		hash("headerColor", user("another@faas.com", nil)),
+		"a referenced context key must change the hash, even for the same variant")
+
+	explicit := hash("explicitKeys", map[string]interface{}{"targetingKey": "user-1"})
+	require.Equal(t, explicit, hash("explicitKeys", user("other@faas.com", map[string]interface{}{
+		"targetingKey": "user-1",
+	})), "only the configured context keys must be hashed")
+	require.NotEqual(t, explicit, hash("explicitKeys", map[string]interface{}{"targetingKey": "user-2"}))
+
+	require.NotEqual(t, hash("split", map[string]interface{}{"email": "a@faas.com"}),
+		hash("split", map[string]interface{}{"email": "b@faas.com"}),
+		"the bucketing key of fractional evaluations must be hashed")
+
+	require.Equal(t, hash("static", nil), hash("static", map[string]interface{}{"email": "a@faas.com"}))
+	require.NotEqual(t, hash("static", nil), hash("headerColor", nil), "the flag key must be hashed")
+}
+
+func TestEvaluationHash_Disabled(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, evaluationHashFlagConfig)
+	require.Nil(t, err)
+	_, _, _, metadata, err := je.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+	require.Nil(t, err)
+	require.NotContains(t, metadata, EvaluationHashMetadataKey)
+}
+
+func TestReferencedContextKeys(t *testing.T) {
+	var rule interface{} = map[string]interface{}{
+		"if": []interface{}{
+			map[string]interface{}{"in": []interface{}{"@faas.com", map[string]interface{}{"var": []interface{}{"email"}}}},
+			map[string]interface{}{"fractionalEvaluation": []interface{}{"targetingKey", []interface{}{"a", 100.0}}},
+			map[string]interface{}{">": []interface{}{map[string]interface{}{"var": "$flagd.timestamp"}, 0.0}},
+			map[string]interface{}{"var": "user.plan"},
+		},
+	}
+	require.Equal(t, []string{"email", "targetingKey", "user.plan"}, referencedContextKeys(rule))
+}
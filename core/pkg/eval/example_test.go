@@ -0,0 +1,43 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Embedding the evaluation engine directly, without starting the flagd runtime
+func ExampleNewJSONEvaluatorFromConfig() {
+	const config = `{
+  "flags": {
+    "new-welcome-banner": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": {
+        "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null]
+      }
+    }
+  }
+}`
+	var evaluator eval.IEvaluator
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, config)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, _ := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "new-welcome-banner", ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(value, variant, reason)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "missing-flag", ctx)
+	fmt.Println(err)
+	// Output:
+	// true on TARGETING_MATCH
+	// FLAG_NOT_FOUND
+}
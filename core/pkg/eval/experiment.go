@@ -0,0 +1,25 @@
+package eval
+
+import "github.com/open-feature/flagd/core/pkg/model"
+
+const (
+	// ExperimentMetadataKey is the metadata key holding the experiment of a flag evaluated through a split
+	ExperimentMetadataKey = "experiment"
+	// ExperimentVariantMetadataKey is the metadata key holding the variant the split assigned in the experiment
+	ExperimentVariantMetadataKey = "experimentVariant"
+)
+
+// withExperiment adds the experiment of the flag and the assigned variant to the evaluation metadata of a targeting
+// match, if the variant was assigned by a split, i.e. a fractional evaluation bucketed the evaluation, so exposures
+// can be logged from the resolution
+func withExperiment(flag model.Flag, variant string, metadata map[string]interface{}) map[string]interface{} {
+	if flag.Experiment == "" {
+		return metadata
+	}
+	if _, split := metadata[BucketMetadataKey]; !split {
+		return metadata
+	}
+	metadata[ExperimentMetadataKey] = flag.Experiment
+	metadata[ExperimentVariantMetadataKey] = variant
+	return metadata
+}
@@ -0,0 +1,80 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const experimentFlagConfig = `{
+  "flags": {
+    "checkoutButton": {
+      "state": "ENABLED",
+      "variants": { "control": "Buy", "treatment": "Buy now" },
+      "defaultVariant": "control",
+      "experiment": "checkout-copy-2026",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "country" }, "CA"] },
+          "treatment",
+          { "fractionalEvaluation": ["email", ["control", 50], ["treatment", 50]] }
+        ]
+      }
+    },
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }
+    }
+  }
+}`
+
+func TestExperimentExposure(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, experimentFlagConfig)
+	require.Nil(t, err)
+	evalCtx := func(values map[string]interface{}) *structpb.Struct {
+		s, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		return s
+	}
+
+	_, variant, reason, metadata, err := evaluator.ResolveStringValue(context.Background(), "", "checkoutButton",
+		evalCtx(map[string]interface{}{"email": "jane@faas.com"}))
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason)
+	require.Equal(t, "checkout-copy-2026", metadata[eval.ExperimentMetadataKey])
+	require.Equal(t, variant, metadata[eval.ExperimentVariantMetadataKey])
+	require.Contains(t, metadata, eval.BucketMetadataKey)
+
+	values := evaluator.ResolveAllValues(context.Background(), "", evalCtx(map[string]interface{}{"email": "jane@faas.com"}))
+	exposures := map[string]interface{}{}
+	for _, value := range values {
+		exposures[value.FlagKey] = value.Metadata[eval.ExperimentMetadataKey]
+	}
+	require.Equal(t, map[string]interface{}{"checkoutButton": "checkout-copy-2026", "headerColor": nil}, exposures)
+
+	// evaluations which aren't assigned by the split aren't exposures
+	_, _, _, metadata, err = evaluator.ResolveStringValue(context.Background(), "", "checkoutButton",
+		evalCtx(map[string]interface{}{"email": "jane@faas.com", "country": "CA"}))
+	require.Nil(t, err)
+	require.NotContains(t, metadata, eval.ExperimentMetadataKey, "targeting matches outside the split aren't exposures")
+
+	_, _, reason, metadata, err = evaluator.ResolveStringValue(context.Background(), "", "checkoutButton",
+		evalCtx(map[string]interface{}{}))
+	require.Nil(t, err)
+	require.Equal(t, model.DefaultReason, reason)
+	require.NotContains(t, metadata, eval.ExperimentMetadataKey, "evaluations missing the split context aren't exposures")
+
+	// flags which aren't associated with an experiment have no exposure metadata
+	_, _, _, metadata, err = evaluator.ResolveStringValue(context.Background(), "", "headerColor",
+		evalCtx(map[string]interface{}{"email": "jane@faas.com"}))
+	require.Nil(t, err)
+	require.Contains(t, metadata, eval.BucketMetadataKey)
+	require.NotContains(t, metadata, eval.ExperimentMetadataKey)
+	require.NotContains(t, metadata, eval.ExperimentVariantMetadataKey)
+}
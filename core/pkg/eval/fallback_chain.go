@@ -0,0 +1,155 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ClientDefaultContextKey is the evaluation context key holding the default value of the client, resolved by the
+// client default level of the fallback chain
+const ClientDefaultContextKey = "defaultValue"
+
+// FallbackLevel is a level of the chain resolving the value of a flag, in order of precedence: override, targeting,
+// flag default, client default and zero value. Each enabled level resolves the flag when the levels before it don't.
+type FallbackLevel string
+
+const (
+	// FallbackOverride resolves the variant pinned by the override token of the context, with the OVERRIDE reason
+	FallbackOverride FallbackLevel = "override"
+	// FallbackTargeting resolves the variant of the targeting of the flag, with the TARGETING_MATCH or SPLIT reason
+	FallbackTargeting FallbackLevel = "targeting"
+	// FallbackFlagDefault resolves the default variant of the flag, with the DEFAULT or STATIC reason
+	FallbackFlagDefault FallbackLevel = "flag-default"
+	// FallbackClientDefault resolves the value of the ClientDefaultContextKey of the context, if it's of the type of
+	// the flag, with the CLIENT_DEFAULT reason
+	FallbackClientDefault FallbackLevel = "client-default"
+	// FallbackZeroValue resolves the zero value of the type of the flag, with the ZERO_VALUE reason
+	FallbackZeroValue FallbackLevel = "zero-value"
+)
+
+// DefaultFallbackChain are the levels resolving flags unless configured otherwise, flags which none of them resolves
+// return an error
+var DefaultFallbackChain = []FallbackLevel{FallbackOverride, FallbackTargeting, FallbackFlagDefault}
+
+// ParseFallbackChain returns the fallback levels of their names, no names default to the DefaultFallbackChain. The
+// order of the names doesn't matter, the levels always apply in order of precedence.
+func ParseFallbackChain(names []string) ([]FallbackLevel, error) {
+	if len(names) == 0 {
+		return DefaultFallbackChain, nil
+	}
+	levels := make([]FallbackLevel, 0, len(names))
+	for _, name := range names {
+		switch level := FallbackLevel(strings.TrimSpace(name)); level {
+		case FallbackOverride, FallbackTargeting, FallbackFlagDefault, FallbackClientDefault, FallbackZeroValue:
+			levels = append(levels, level)
+		default:
+			return nil, fmt.Errorf("unknown fallback level: '%s', expected '%s', '%s', '%s', '%s' or '%s'",
+				name, FallbackOverride, FallbackTargeting, FallbackFlagDefault, FallbackClientDefault, FallbackZeroValue)
+		}
+	}
+	return levels, nil
+}
+
+// WithFallbackChain enables the levels of the fallback chain resolving flags, the DefaultFallbackChain applies when
+// none is given. The client default and zero value levels replace the error of the resolution of a flag, e.g. a flag
+// which isn't found or isn't of the requested type, so clients always get a value along with a reason telling where
+// it came from. They apply to the resolution of a single flag, bulk evaluations report the errors of their flags.
+func WithFallbackChain(levels ...FallbackLevel) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if len(levels) == 0 {
+			je.fallbackLevels = nil
+			return
+		}
+		je.fallbackLevels = make(map[FallbackLevel]struct{}, len(levels))
+		for _, level := range levels {
+			je.fallbackLevels[level] = struct{}{}
+		}
+	}
+}
+
+// fallsBackTo tells whether the level of the fallback chain is enabled
+func (je *JSONEvaluator) fallsBackTo(level FallbackLevel) bool {
+	if je.fallbackLevels == nil {
+		return level != FallbackClientDefault && level != FallbackZeroValue
+	}
+	_, ok := je.fallbackLevels[level]
+	return ok
+}
+
+// skipFlagDefault returns an error in place of the resolution of the default variant of a flag if the flag default
+// level is disabled, for the levels below it to resolve the flag
+func (je *JSONEvaluator) skipFlagDefault(reqID string, flagKey string, reason string) error {
+	if je.fallsBackTo(FallbackFlagDefault) || (reason != model.DefaultReason && reason != model.StaticReason) {
+		return nil
+	}
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("flag: %s resolved its default variant, which the fallback chain skips",
+		flagKey))
+	return errors.New(model.GeneralErrorCode)
+}
+
+// fallback resolves a flag which failed to resolve by the client default or the zero value levels of the fallback
+// chain, if enabled, the error is returned otherwise. Evaluations whose context is done aren't resolved, nobody waits
+// for them.
+func fallback[T constraints](
+	je *JSONEvaluator,
+	reqID string,
+	flagKey string,
+	context *structpb.Struct,
+	value T,
+	variant string,
+	reason string,
+	metadata map[string]interface{},
+	err error,
+) (T, string, string, map[string]interface{}, error) {
+	if err == nil || isDone(err) {
+		return value, variant, reason, metadata, err
+	}
+	if je.fallsBackTo(FallbackClientDefault) {
+		if clientDefault, ok := clientDefaultOf[T](context); ok {
+			je.Logger.DebugWithID(reqID, fmt.Sprintf("flag: %s resolved the client default value in place of: %v",
+				flagKey, err))
+			return clientDefault, "", model.ClientDefaultReason, metadata, nil
+		}
+	}
+	if je.fallsBackTo(FallbackZeroValue) {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("flag: %s resolved the zero value in place of: %v", flagKey, err))
+		var zero T
+		return zero, "", model.ZeroValueReason, metadata, nil
+	}
+	return value, variant, reason, metadata, err
+}
+
+// clientDefaultOf returns the client default value of the context, false if it's missing or isn't of type T. Objects
+// are resolved as maps, integer flags truncate the number like their variants.
+func clientDefaultOf[T constraints](context *structpb.Struct) (T, bool) {
+	var value T
+	field, ok := context.GetFields()[ClientDefaultContextKey]
+	if !ok {
+		return value, false
+	}
+	var clientDefault interface{}
+	switch kind := field.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		clientDefault = kind.BoolValue
+	case *structpb.Value_StringValue:
+		clientDefault = kind.StringValue
+	case *structpb.Value_NumberValue:
+		clientDefault = kind.NumberValue
+	case *structpb.Value_StructValue:
+		clientDefault = kind.StructValue.AsMap()
+	default:
+		return value, false
+	}
+	value, ok = clientDefault.(T)
+	return value, ok
+}
+
+// isDone tells whether an evaluation failed with the error of its context
+func isDone(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
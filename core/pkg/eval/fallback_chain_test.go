@@ -0,0 +1,246 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var fullFallbackChain = []eval.FallbackLevel{
+	eval.FallbackOverride, eval.FallbackTargeting, eval.FallbackFlagDefault, eval.FallbackClientDefault,
+	eval.FallbackZeroValue,
+}
+
+// fallbackEvaluator returns an evaluator of the override flag configuration whose fallback chain holds the levels,
+// along with a valid override token pinning headerColor to blue
+func fallbackEvaluator(t *testing.T, levels ...eval.FallbackLevel) (*eval.JSONEvaluator, string) {
+	t.Helper()
+	now := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, overrideFlagConfig, eval.WithClock(&fakeClock{now: now}),
+		eval.WithOverrideTokenSecret(overrideSecret), eval.WithFallbackChain(levels...))
+	require.Nil(t, err)
+	token := signOverrideToken(t, overrideSecret, map[string]interface{}{
+		"flags": map[string]interface{}{"headerColor": "blue"},
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	return evaluator, token
+}
+
+func TestFallbackChain(t *testing.T) {
+	evaluator, token := fallbackEvaluator(t, fullFallbackChain...)
+	tests := map[string]struct {
+		flagKey string
+		context map[string]interface{}
+		value   string
+		variant string
+		reason  string
+	}{
+		"override": {
+			flagKey: "headerColor",
+			context: map[string]interface{}{eval.OverrideTokenContextKey: token, "plan": "premium"},
+			value:   "#0000FF",
+			variant: "blue",
+			reason:  model.OverrideReason,
+		},
+		"targeting": {
+			flagKey: "headerColor",
+			context: map[string]interface{}{"plan": "premium"},
+			value:   "#00FF00",
+			variant: "green",
+			reason:  model.TargetingMatchReason,
+		},
+		"flag default": {
+			flagKey: "headerColor",
+			context: map[string]interface{}{"plan": "free", eval.ClientDefaultContextKey: "#FFFFFF"},
+			value:   "#FF0000",
+			variant: "red",
+			reason:  model.DefaultReason,
+		},
+		"client default": {
+			flagKey: "footerColor",
+			context: map[string]interface{}{eval.ClientDefaultContextKey: "#FFFFFF"},
+			value:   "#FFFFFF",
+			reason:  model.ClientDefaultReason,
+		},
+		"client default of a type mismatch": {
+			flagKey: "newCheckout",
+			context: map[string]interface{}{eval.ClientDefaultContextKey: "#FFFFFF"},
+			value:   "#FFFFFF",
+			reason:  model.ClientDefaultReason,
+		},
+		"zero value": {
+			flagKey: "footerColor",
+			reason:  model.ZeroValueReason,
+		},
+		"zero value in place of a client default of another type": {
+			flagKey: "footerColor",
+			context: map[string]interface{}{eval.ClientDefaultContextKey: 42},
+			reason:  model.ZeroValueReason,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", tt.flagKey, evalCtx)
+			require.Nil(t, err)
+			require.Equal(t, tt.value, value)
+			require.Equal(t, tt.variant, variant)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestFallbackChain_Types(t *testing.T) {
+	evaluator, _ := fallbackEvaluator(t, fullFallbackChain...)
+	clientDefault := func(value interface{}) *structpb.Struct {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{eval.ClientDefaultContextKey: value})
+		require.Nil(t, err)
+		return evalCtx
+	}
+	ctx := context.Background()
+
+	boolValue, _, reason, _, err := evaluator.ResolveBooleanValue(ctx, "", "missing", clientDefault(true))
+	require.Nil(t, err)
+	require.True(t, boolValue)
+	require.Equal(t, model.ClientDefaultReason, reason)
+	intValue, _, reason, _, err := evaluator.ResolveIntValue(ctx, "", "missing", clientDefault(7))
+	require.Nil(t, err)
+	require.Equal(t, int64(7), intValue)
+	require.Equal(t, model.ClientDefaultReason, reason)
+	floatValue, _, reason, _, err := evaluator.ResolveFloatValue(ctx, "", "missing", clientDefault(0.5))
+	require.Nil(t, err)
+	require.Equal(t, 0.5, floatValue)
+	require.Equal(t, model.ClientDefaultReason, reason)
+	objectValue, _, reason, _, err := evaluator.ResolveObjectValue(ctx, "", "missing",
+		clientDefault(map[string]interface{}{"theme": "dark"}))
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"theme": "dark"}, objectValue)
+	require.Equal(t, model.ClientDefaultReason, reason)
+
+	boolValue, _, reason, _, err = evaluator.ResolveBooleanValue(ctx, "", "missing", nil)
+	require.Nil(t, err)
+	require.False(t, boolValue)
+	require.Equal(t, model.ZeroValueReason, reason)
+	intValue, _, reason, _, err = evaluator.ResolveIntValue(ctx, "", "missing", clientDefault("7"))
+	require.Nil(t, err)
+	require.Zero(t, intValue)
+	require.Equal(t, model.ZeroValueReason, reason)
+	objectValue, _, reason, _, err = evaluator.ResolveObjectValue(ctx, "", "missing", nil)
+	require.Nil(t, err)
+	require.Nil(t, objectValue)
+	require.Equal(t, model.ZeroValueReason, reason)
+}
+
+func TestFallbackChain_Levels(t *testing.T) {
+	tests := map[string]struct {
+		levels  []eval.FallbackLevel
+		flagKey string
+		context map[string]interface{}
+		value   string
+		reason  string
+		err     string
+	}{
+		"without override": {
+			levels:  []eval.FallbackLevel{eval.FallbackTargeting, eval.FallbackFlagDefault},
+			flagKey: "headerColor",
+			context: map[string]interface{}{"plan": "premium"},
+			value:   "#00FF00",
+			reason:  model.TargetingMatchReason,
+		},
+		"without targeting": {
+			levels:  []eval.FallbackLevel{eval.FallbackFlagDefault},
+			flagKey: "headerColor",
+			context: map[string]interface{}{"plan": "premium"},
+			value:   "#FF0000",
+			reason:  model.DefaultReason,
+		},
+		"without flag default": {
+			levels:  []eval.FallbackLevel{eval.FallbackTargeting, eval.FallbackClientDefault},
+			flagKey: "headerColor",
+			context: map[string]interface{}{"plan": "free", eval.ClientDefaultContextKey: "#FFFFFF"},
+			value:   "#FFFFFF",
+			reason:  model.ClientDefaultReason,
+		},
+		"without client default": {
+			levels:  []eval.FallbackLevel{eval.FallbackFlagDefault, eval.FallbackZeroValue},
+			flagKey: "footerColor",
+			context: map[string]interface{}{eval.ClientDefaultContextKey: "#FFFFFF"},
+			reason:  model.ZeroValueReason,
+		},
+		"without zero value": {
+			levels:  []eval.FallbackLevel{eval.FallbackFlagDefault, eval.FallbackClientDefault},
+			flagKey: "footerColor",
+			reason:  model.ErrorReason,
+			err:     model.FlagNotFoundErrorCode,
+		},
+		"without any level resolving the flag": {
+			levels:  []eval.FallbackLevel{eval.FallbackTargeting},
+			flagKey: "headerColor",
+			reason:  model.ErrorReason,
+			err:     model.GeneralErrorCode,
+		},
+		"default chain": {
+			flagKey: "footerColor",
+			context: map[string]interface{}{eval.ClientDefaultContextKey: "#FFFFFF"},
+			reason:  model.ErrorReason,
+			err:     model.FlagNotFoundErrorCode,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, token := fallbackEvaluator(t, tt.levels...)
+			if tt.context != nil {
+				tt.context[eval.OverrideTokenContextKey] = token
+			}
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", tt.flagKey, evalCtx)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Equal(t, tt.value, value)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestFallbackChain_ContextKeys(t *testing.T) {
+	evaluator, _ := fallbackEvaluator(t, fullFallbackChain...)
+	keys, ok := evaluator.FlagContextKeys("newCheckout")
+	require.True(t, ok)
+	require.Equal(t, []string{eval.ClientDefaultContextKey, eval.OverrideTokenContextKey}, keys.Keys,
+		"the client default should be kept by contexts pruned to the keys of the flag")
+
+	evaluator, _ = fallbackEvaluator(t, eval.FallbackTargeting, eval.FallbackFlagDefault)
+	keys, ok = evaluator.FlagContextKeys("newCheckout")
+	require.True(t, ok)
+	require.Empty(t, keys.Keys, "the keys of disabled levels aren't read")
+}
+
+func TestFallbackChain_Canceled(t *testing.T) {
+	evaluator, _ := fallbackEvaluator(t, fullFallbackChain...)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, _, _, err := evaluator.ResolveStringValue(ctx, "", "headerColor", nil)
+	require.ErrorIs(t, err, context.Canceled, "evaluations nobody waits for shouldn't fall back")
+}
+
+func TestParseFallbackChain(t *testing.T) {
+	levels, err := eval.ParseFallbackChain(nil)
+	require.Nil(t, err)
+	require.Equal(t, eval.DefaultFallbackChain, levels)
+	levels, err = eval.ParseFallbackChain([]string{"zero-value", " targeting"})
+	require.Nil(t, err)
+	require.Equal(t, []eval.FallbackLevel{eval.FallbackZeroValue, eval.FallbackTargeting}, levels)
+	_, err = eval.ParseFallbackChain([]string{"server-default"})
+	require.EqualError(t, err, "unknown fallback level: 'server-default', expected 'override', 'targeting', "+
+		"'flag-default', 'client-default' or 'zero-value'")
+}
@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InvalidFlagKeys defines how flag keys with characters outside of the allowed characters are handled, as they may
+// break the metric labels and logs they end up in
+type InvalidFlagKeys string
+
+const (
+	// InvalidFlagKeysError rejects configurations with invalid flag keys, the default
+	InvalidFlagKeysError InvalidFlagKeys = "error"
+	// InvalidFlagKeysSanitize replaces the invalid characters of flag keys with sanitizedFlagKeyCharacter, with a
+	// warning
+	InvalidFlagKeysSanitize InvalidFlagKeys = "sanitize"
+
+	sanitizedFlagKeyCharacter = "_"
+)
+
+// ParseInvalidFlagKeys returns the invalid flag keys policy of its name, an empty name defaults to error
+func ParseInvalidFlagKeys(policy string) (InvalidFlagKeys, error) {
+	switch InvalidFlagKeys(policy) {
+	case "":
+		return InvalidFlagKeysError, nil
+	case InvalidFlagKeysError, InvalidFlagKeysSanitize:
+		return InvalidFlagKeys(policy), nil
+	default:
+		return "", fmt.Errorf("unknown invalid flag keys policy: '%s', expected '%s' or '%s'",
+			policy, InvalidFlagKeysError, InvalidFlagKeysSanitize)
+	}
+}
+
+// ParseFlagKeyCharacters compiles the pattern matching each allowed character of flag keys, e.g. `[A-Za-z0-9_.-]`.
+// Flag keys aren't checked when the pattern is empty.
+func ParseFlagKeyCharacters(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	allowed, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("flag key characters: %w", err)
+	}
+	return allowed, nil
+}
+
+// WithFlagKeyCharacters checks each character of the flag keys of loaded configurations matches the allowed
+// pattern, applying the policy to the keys which don't. Flag keys aren't checked when the pattern is nil.
+func WithFlagKeyCharacters(allowed *regexp.Regexp, policy InvalidFlagKeys) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.flagKeyCharacters = allowed
+		je.invalidFlagKeys = policy
+	}
+}
+
+// invalidKeyCharacters returns the distinct characters of the key which the allowed pattern doesn't match, in the
+// order of the key
+func invalidKeyCharacters(allowed *regexp.Regexp, key string) []string {
+	var invalid []string
+	seen := map[rune]struct{}{}
+	for _, r := range key {
+		if _, ok := seen[r]; ok || allowed.MatchString(string(r)) {
+			continue
+		}
+		seen[r] = struct{}{}
+		invalid = append(invalid, fmt.Sprintf("%q", r))
+	}
+	return invalid
+}
+
+// checkFlagKeys checks the characters of the flag keys of a configuration, either rejecting the configuration with
+// a report of every invalid key or replacing their invalid characters. Keys are returned as is without an allowed
+// pattern.
+func (je *JSONEvaluator) checkFlagKeys(flags map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	if je.flagKeyCharacters == nil {
+		return flags, nil
+	}
+	var invalid []string
+	for key := range flags {
+		if chars := invalidKeyCharacters(je.flagKeyCharacters, key); len(chars) != 0 {
+			invalid = append(invalid, key)
+		}
+	}
+	if len(invalid) == 0 {
+		return flags, nil
+	}
+	sort.Strings(invalid)
+
+	var errs []string
+	if je.invalidFlagKeys != InvalidFlagKeysSanitize {
+		for _, key := range invalid {
+			errs = append(errs, fmt.Sprintf("flag key: '%s' has invalid characters: %s",
+				key, strings.Join(invalidKeyCharacters(je.flagKeyCharacters, key), ", ")))
+		}
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	sanitized := make(map[string]json.RawMessage, len(flags))
+	for key, flag := range flags {
+		sanitized[key] = flag
+	}
+	for _, key := range invalid {
+		fixed := sanitizeFlagKey(je.flagKeyCharacters, key)
+		if _, ok := sanitized[fixed]; ok {
+			errs = append(errs, fmt.Sprintf("flag key: '%s' is sanitized to: '%s', which is already defined",
+				key, fixed))
+			continue
+		}
+		if len(invalidKeyCharacters(je.flagKeyCharacters, fixed)) != 0 {
+			errs = append(errs, fmt.Sprintf("flag key: '%s' can't be sanitized, '%s' isn't an allowed character",
+				key, sanitizedFlagKeyCharacter))
+			continue
+		}
+		je.Logger.Warn(fmt.Sprintf("sanitized flag key: '%s' to: '%s'", key, fixed))
+		sanitized[fixed] = sanitized[key]
+		delete(sanitized, key)
+	}
+	if len(errs) != 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return sanitized, nil
+}
+
+// sanitizeFlagKey replaces each character of the key which the allowed pattern doesn't match
+func sanitizeFlagKey(allowed *regexp.Regexp, key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if allowed.MatchString(string(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(sanitizedFlagKeyCharacter)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const flagKeysAllowedCharacters = `[A-Za-z0-9_.-]`
+
+func flagKeysConfig(keys ...string) string {
+	config := `{ "flags": {`
+	for i, key := range keys {
+		if i > 0 {
+			config += ","
+		}
+		config += `"` + key + `": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }`
+	}
+	return config + `} }`
+}
+
+func TestFlagKeyCharacters(t *testing.T) {
+	allowed, err := ParseFlagKeyCharacters(flagKeysAllowedCharacters)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		keys     []string
+		policy   InvalidFlagKeys
+		wantKeys []string
+		wantErr  string
+	}{
+		"valid keys": {
+			keys:     []string{"new-checkout", "team.flag_1"},
+			wantKeys: []string{"new-checkout", "team.flag_1"},
+		},
+		"rejected keys": {
+			keys: []string{"valid", "new checkout", "team:flag{1}"},
+			wantErr: "flag key: 'new checkout' has invalid characters: ' '; " +
+				"flag key: 'team:flag{1}' has invalid characters: ':', '{', '}'",
+		},
+		"sanitized keys": {
+			keys:     []string{"valid", "new checkout", "team:flag"},
+			policy:   InvalidFlagKeysSanitize,
+			wantKeys: []string{"valid", "new_checkout", "team_flag"},
+		},
+		"sanitized key already defined": {
+			keys:    []string{"new_checkout", "new checkout"},
+			policy:  InvalidFlagKeysSanitize,
+			wantErr: "flag key: 'new checkout' is sanitized to: 'new_checkout', which is already defined",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, flagKeysConfig(tt.keys...), WithFlagKeyCharacters(allowed, tt.policy))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			flags := je.store.GetAll()
+			require.Len(t, flags, len(tt.wantKeys))
+			for _, key := range tt.wantKeys {
+				value, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", key, &structpb.Struct{})
+				require.Nil(t, err, key)
+				require.True(t, value)
+			}
+		})
+	}
+}
+
+func TestFlagKeyCharacters_SanitizeWarning(t *testing.T) {
+	allowed, err := ParseFlagKeyCharacters(flagKeysAllowedCharacters)
+	require.Nil(t, err)
+	core, logs := observer.New(zapcore.WarnLevel)
+	log := logger.NewLogger(zap.New(core), false)
+	_, err = NewJSONEvaluatorFromConfig(log, flagKeysConfig("new checkout"),
+		WithFlagKeyCharacters(allowed, InvalidFlagKeysSanitize))
+	require.Nil(t, err)
+	require.Equal(t, 1, logs.FilterMessage("sanitized flag key: 'new checkout' to: 'new_checkout'").Len())
+}
+
+func TestFlagKeyCharacters_Unsanitizable(t *testing.T) {
+	allowed, err := ParseFlagKeyCharacters(`[a-z]`)
+	require.Nil(t, err)
+	_, err = NewJSONEvaluatorFromConfig(nil, flagKeysConfig("new checkout"),
+		WithFlagKeyCharacters(allowed, InvalidFlagKeysSanitize))
+	require.EqualError(t, err, "flag key: 'new checkout' can't be sanitized, '_' isn't an allowed character")
+}
+
+func TestFlagKeyCharacters_Disabled(t *testing.T) {
+	allowed, err := ParseFlagKeyCharacters("")
+	require.Nil(t, err)
+	require.Nil(t, allowed)
+	_, err = NewJSONEvaluatorFromConfig(nil, flagKeysConfig("new checkout"), WithFlagKeyCharacters(allowed, ""))
+	require.Nil(t, err, "flag keys shouldn't be checked without a pattern")
+}
+
+func TestParseFlagKeyCharacters(t *testing.T) {
+	_, err := ParseFlagKeyCharacters(`[a-z`)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "flag key characters: ")
+}
+
+func TestParseInvalidFlagKeys(t *testing.T) {
+	policy, err := ParseInvalidFlagKeys("")
+	require.Nil(t, err)
+	require.Equal(t, InvalidFlagKeysError, policy)
+
+	policy, err = ParseInvalidFlagKeys("sanitize")
+	require.Nil(t, err)
+	require.Equal(t, InvalidFlagKeysSanitize, policy)
+
+	_, err = ParseInvalidFlagKeys("drop")
+	require.EqualError(t, err, "unknown invalid flag keys policy: 'drop', expected 'error' or 'sanitize'")
+}
@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validateFlagMetadata checks that flag metadata only holds boolean, number and string values, which are carried
+// with their json type through resolution metadata
+func validateFlagMetadata(metadata map[string]interface{}) error {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch metadata[key].(type) {
+		case bool, float64, string:
+		default:
+			return fmt.Errorf(
+				"metadata key: '%s' has a value of unsupported type %s, expected a boolean, number or string",
+				key, jsonTypeName(metadata[key]),
+			)
+		}
+	}
+	return nil
+}
+
+// withFlagMetadata merges the metadata of a flag with the metadata of its evaluation, evaluation metadata takes
+// precedence. The flag metadata is shared across evaluations, as such it is copied.
+func withFlagMetadata(flagMetadata, metadata map[string]interface{}) map[string]interface{} {
+	if len(flagMetadata) == 0 {
+		return metadata
+	}
+	merged := make(map[string]interface{}, len(flagMetadata)+len(metadata))
+	for k, v := range flagMetadata {
+		merged[k] = v
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	return merged
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
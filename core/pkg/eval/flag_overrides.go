@@ -0,0 +1,95 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// FlagOverrides is implemented by evaluators whose stored flags may be edited at runtime, e.g. flipping a kill switch
+// without editing the configuration of its source
+type FlagOverrides interface {
+	// OverrideFlag applies the override to the stored flag, returning its previous default variant and state along
+	// with the notifications of the change. Flags which aren't stored return a FlagNotFoundErrorCode error.
+	OverrideFlag(flagKey string, override FlagOverride) (FlagOverride, map[string]interface{}, error)
+}
+
+// FlagOverride sets the default variant or the state of a stored flag, empty fields are left unchanged
+type FlagOverride struct {
+	DefaultVariant string `json:"defaultVariant,omitempty"`
+	State          string `json:"state,omitempty"`
+}
+
+// validate checks the override changes the flag to a variant it defines or to a known state
+func (o FlagOverride) validate(flagKey string, flag model.Flag) error {
+	if o.DefaultVariant == "" && o.State == "" {
+		return errors.New("an override sets the defaultVariant or the state of the flag")
+	}
+	if _, ok := flag.Variants[o.DefaultVariant]; o.DefaultVariant != "" && !ok {
+		return fmt.Errorf("'%s' isn't a variant of flag: %s", o.DefaultVariant, flagKey)
+	}
+	if o.State != "" && o.State != Enabled && o.State != Disabled {
+		return fmt.Errorf("unknown state: '%s', expected '%s' or '%s'", o.State, Enabled, Disabled)
+	}
+	return nil
+}
+
+// OverrideFlag changes the default variant or the state of the stored flag. The override holds until the next reload
+// of the source of the flag changes it, or until its pending definition is applied if the flag is pinned.
+func (je *JSONEvaluator) OverrideFlag(flagKey string, override FlagOverride) (
+	FlagOverride, map[string]interface{}, error,
+) {
+	je.reloads.Lock()
+	defer je.reloads.Unlock()
+	flag, ok := je.store.Get(flagKey)
+	if !ok {
+		return FlagOverride{}, nil, errors.New(model.FlagNotFoundErrorCode)
+	}
+	if err := override.validate(flagKey, flag); err != nil {
+		return FlagOverride{}, nil, err
+	}
+
+	previous := FlagOverride{DefaultVariant: flag.DefaultVariant, State: flag.State}
+	if override.DefaultVariant != "" {
+		flag.DefaultVariant = override.DefaultVariant
+	}
+	if override.State != "" {
+		flag.State = override.State
+	}
+	je.Logger.Info(fmt.Sprintf("overriding flag: %s of source: %s, default variant: %s, state: %s",
+		flagKey, flag.Source, flag.DefaultVariant, flag.State))
+	return previous, je.store.Update(je.Logger, flag.Source, map[string]model.Flag{flagKey: flag}), nil
+}
+
+// overrideFlag applies the override to each of the evaluators storing the flag, merging their notifications. The
+// previous values of the first evaluator storing the flag are returned.
+func overrideFlag(flagKey string, override FlagOverride, evaluators ...IEvaluator) (
+	FlagOverride, map[string]interface{}, error,
+) {
+	var previous *FlagOverride
+	notifications := map[string]interface{}{}
+	for _, evaluator := range evaluators {
+		overrides, ok := evaluator.(FlagOverrides)
+		if !ok {
+			continue
+		}
+		prev, changes, err := overrides.OverrideFlag(flagKey, override)
+		if err != nil && err.Error() == model.FlagNotFoundErrorCode {
+			continue
+		}
+		if err != nil {
+			return FlagOverride{}, nil, err
+		}
+		if previous == nil {
+			previous = &prev
+		}
+		for key, notification := range changes {
+			notifications[key] = notification
+		}
+	}
+	if previous == nil {
+		return FlagOverride{}, nil, errors.New(model.FlagNotFoundErrorCode)
+	}
+	return *previous, notifications, nil
+}
@@ -0,0 +1,103 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestOverrideFlag(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	setState := func() {
+		t.Helper()
+		_, _, err := je.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(pinnedFlagConfig, "on", "red"), Source: "flags.json", Type: sync.ALL,
+		})
+		require.Nil(t, err)
+	}
+	resolve := func() (bool, string) {
+		t.Helper()
+		value, _, reason, _, _ := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+		return value, reason
+	}
+	setState()
+
+	previous, notifications, err := je.OverrideFlag("killSwitch", eval.FlagOverride{DefaultVariant: "off"})
+	require.Nil(t, err)
+	require.Equal(t, eval.FlagOverride{DefaultVariant: "on", State: eval.Enabled}, previous)
+	require.Contains(t, notifications, "killSwitch")
+	value, reason := resolve()
+	require.False(t, value, "the overridden default variant should be served")
+	require.Equal(t, model.StaticReason, reason)
+
+	_, _, err = je.OverrideFlag("killSwitch", eval.FlagOverride{State: eval.Disabled})
+	require.Nil(t, err)
+	_, reason = resolve()
+	require.Equal(t, model.ErrorReason, reason, "the overridden flag should be disabled")
+
+	setState()
+	value, reason = resolve()
+	require.True(t, value, "a reload should replace the override")
+	require.Equal(t, model.StaticReason, reason)
+
+	tests := map[string]struct {
+		flagKey  string
+		override eval.FlagOverride
+		wantErr  string
+	}{
+		"unknown flag": {
+			flagKey:  "aMissingFlag",
+			override: eval.FlagOverride{State: eval.Disabled},
+			wantErr:  model.FlagNotFoundErrorCode,
+		},
+		"unknown variant": {
+			flagKey:  "killSwitch",
+			override: eval.FlagOverride{DefaultVariant: "maybe"},
+			wantErr:  "'maybe' isn't a variant of flag: killSwitch",
+		},
+		"unknown state": {
+			flagKey:  "killSwitch",
+			override: eval.FlagOverride{State: "PAUSED"},
+			wantErr:  "unknown state: 'PAUSED', expected 'ENABLED' or 'DISABLED'",
+		},
+		"empty override": {
+			flagKey: "killSwitch",
+			wantErr: "an override sets the defaultVariant or the state of the flag",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := je.OverrideFlag(tt.flagKey, tt.override)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestOverrideFlag_Pinned(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithPinnedFlags([]string{"killSwitch"}))
+	_, _, err := je.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(pinnedFlagConfig, "on", "red"), Source: "flags.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+
+	_, _, err = je.OverrideFlag("killSwitch", eval.FlagOverride{DefaultVariant: "off"})
+	require.Nil(t, err)
+	_, _, err = je.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(pinnedFlagConfig, "on", "blue"), Source: "flags.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+
+	value, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+	require.Nil(t, err)
+	require.False(t, value, "the override of a pinned flag should survive reloads")
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch", Pending: true}}, je.PinnedFlags())
+}
@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Flag types inferred from the variants of flags
+const (
+	BooleanFlagType = "boolean"
+	StringFlagType  = "string"
+	IntFlagType     = "int"
+	FloatFlagType   = "float"
+	ObjectFlagType  = "object"
+)
+
+// FlagTypes is implemented by evaluators inferring the type of flags from their configuration, so clients can resolve
+// flags without knowing their type
+type FlagTypes interface {
+	FlagType(flagKey string, context *structpb.Struct) (string, bool)
+}
+
+// FlagType returns the type of the flag inferred from its variants, false if the flag doesn't exist. Numeric flags
+// are int flags if every variant is an integer, float flags otherwise.
+func (je *JSONEvaluator) FlagType(flagKey string, _ *structpb.Struct) (string, bool) {
+	flag, ok := je.store.Get(je.namespaceKey("", flagKey))
+	if !ok {
+		return "", false
+	}
+	return flagType(flag)
+}
+
+func flagType(flag model.Flag) (string, bool) {
+	if flag.Derived != nil {
+		return BooleanFlagType, true
+	}
+	value, ok := flag.Variants[flag.DefaultVariant]
+	if !ok {
+		return "", false
+	}
+	switch value.(type) {
+	case bool:
+		return BooleanFlagType, true
+	case string:
+		return StringFlagType, true
+	case map[string]any:
+		return ObjectFlagType, true
+	case float64:
+		for _, variant := range flag.Variants {
+			if number, ok := variant.(float64); ok && !isExactInteger(number) {
+				return FloatFlagType, true
+			}
+		}
+		return IntFlagType, true
+	default:
+		return "", false
+	}
+}
+
+func isExactInteger(number float64) bool {
+	return number == math.Trunc(number) && math.Abs(number) <= maxExactInteger
+}
+
+// flagTypeOf returns the type of the flag inferred by the evaluator, false if it doesn't infer flag types or the flag
+// doesn't exist
+func flagTypeOf(evaluator IEvaluator, flagKey string, context *structpb.Struct) (string, bool) {
+	types, ok := evaluator.(FlagTypes)
+	if !ok {
+		return "", false
+	}
+	return types.FlagType(flagKey, context)
+}
@@ -0,0 +1,38 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagType(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "boolFlag": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" },
+    "stringFlag": { "state": "ENABLED", "variants": { "red": "#FF0000" }, "defaultVariant": "red" },
+    "intFlag": { "state": "ENABLED", "variants": { "one": 1, "minus": -2 }, "defaultVariant": "one" },
+    "floatFlag": { "state": "ENABLED", "variants": { "one": 1, "half": 0.5 }, "defaultVariant": "one" },
+    "hugeFlag": { "state": "ENABLED", "variants": { "huge": 1e300 }, "defaultVariant": "huge" },
+    "objectFlag": { "state": "ENABLED", "variants": { "dark": { "theme": "dark" } }, "defaultVariant": "dark" }
+  }
+}`)
+	require.Nil(t, err)
+
+	tests := map[string]string{
+		"boolFlag":   eval.BooleanFlagType,
+		"stringFlag": eval.StringFlagType,
+		"intFlag":    eval.IntFlagType,
+		"floatFlag":  eval.FloatFlagType,
+		"hugeFlag":   eval.FloatFlagType,
+		"objectFlag": eval.ObjectFlagType,
+	}
+	for flagKey, want := range tests {
+		flagType, ok := evaluator.FlagType(flagKey, nil)
+		require.True(t, ok, flagKey)
+		require.Equal(t, want, flagType, flagKey)
+	}
+	_, ok := evaluator.FlagType("unknown", nil)
+	require.False(t, ok)
+}
@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// FlapDetection is implemented by evaluators holding the value of flags whose definition changes too often
+type FlapDetection interface {
+	// HeldFlags returns the sorted keys of the flags whose value is held until their definition stabilizes
+	HeldFlags() []string
+}
+
+// WithFlapDetection holds the value of flags whose definition changes more than maxChanges times within the window,
+// dampening unstable sources. The latest definition of a held flag is applied by the first update of its source
+// once it hasn't changed for a whole window. Flap detection is disabled when either is 0.
+func WithFlapDetection(maxChanges int, window time.Duration) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if maxChanges > 0 && window > 0 {
+			je.flaps = &flapDetector{maxChanges: maxChanges, window: window, flags: map[string]*flagChanges{}}
+		}
+	}
+}
+
+// flagChanges tracks the definition of a flag proposed by its source, which differs from the stored one while it's
+// held
+type flagChanges struct {
+	proposed model.Flag
+	present  bool
+	changes  []time.Time
+	held     bool
+}
+
+// flapDetector tracks the changes of the definition of each flag
+type flapDetector struct {
+	maxChanges int
+	window     time.Duration
+	mu         msync.Mutex
+	flags      map[string]*flagChanges
+}
+
+// HeldFlags returns the sorted keys of the flags whose value is held, nil if flap detection is disabled
+func (je *JSONEvaluator) HeldFlags() []string {
+	if je.flaps == nil {
+		return nil
+	}
+	je.flaps.mu.Lock()
+	defer je.flaps.mu.Unlock()
+	var held []string
+	for flagKey, flag := range je.flaps.flags {
+		if flag.held {
+			held = append(held, flagKey)
+		}
+	}
+	sort.Strings(held)
+	return held
+}
+
+// dampFlaps records the changes of the flags of the update and replaces the definitions of flapping flags by their
+// stored definition. Flags missing from an update of the whole configuration of the source are changes too, as they
+// would be deleted.
+func (je *JSONEvaluator) dampFlaps(source string, syncType sync.Type, flags map[string]model.Flag) {
+	d := je.flaps
+	now := je.clock.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	proposed := make(map[string]bool, len(flags))
+	for flagKey := range flags {
+		proposed[flagKey] = true
+	}
+	if syncType == sync.ALL {
+		for flagKey, flag := range je.store.GetAll() {
+			if _, ok := flags[flagKey]; !ok && flag.Source == source {
+				proposed[flagKey] = false
+			}
+		}
+	}
+
+	for flagKey, present := range proposed {
+		stored, isStored := je.store.Get(flagKey)
+		if isStored && stored.Source != source {
+			// the flag is owned by another source, the duplicate key policy decides which definition is served
+			continue
+		}
+		flag := flags[flagKey]
+		flag.Source = source
+		changes, ok := d.flags[flagKey]
+		if !ok {
+			changes = &flagChanges{proposed: stored, present: isStored}
+			d.flags[flagKey] = changes
+		}
+		if present != changes.present || (present && !reflect.DeepEqual(flag, changes.proposed)) {
+			changes.proposed, changes.present = flag, present
+			changes.changes = append(changes.changes, now)
+		}
+		changes.changes = recentChanges(changes.changes, now, d.window)
+
+		switch {
+		case len(changes.changes) > d.maxChanges || (changes.held && len(changes.changes) > 0):
+			if !changes.held {
+				changes.held = true
+				je.Logger.Warn(fmt.Sprintf("flag: %s changed more than %d times within %s, holding its value until "+
+					"it stabilizes", flagKey, d.maxChanges, d.window))
+			}
+			if isStored {
+				flags[flagKey] = stored
+			} else {
+				delete(flags, flagKey)
+			}
+		case changes.held:
+			changes.held = false
+			je.Logger.Warn(fmt.Sprintf("flag: %s stabilized, applying its latest definition", flagKey))
+		}
+		if !changes.held && len(changes.changes) == 0 {
+			// the proposed definition is the stored one, it's tracked again from the store on its next change
+			delete(d.flags, flagKey)
+		}
+	}
+}
+
+// recentChanges drops the changes which aren't within the window
+func recentChanges(changes []time.Time, now time.Time, window time.Duration) []time.Time {
+	recent := changes[:0]
+	for _, changed := range changes {
+		if now.Sub(changed) < window {
+			recent = append(recent, changed)
+		}
+	}
+	return recent
+}
+
+// heldFlagsOf returns the held flags of the evaluator, nil if it doesn't detect flaps
+func heldFlagsOf(evaluator IEvaluator) []string {
+	detector, ok := evaluator.(FlapDetection)
+	if !ok {
+		return nil
+	}
+	return detector.HeldFlags()
+}
+
+// mergeHeldFlags returns the sorted keys of the flags held by any of the evaluators
+func mergeHeldFlags(evaluators ...IEvaluator) []string {
+	keys := map[string]struct{}{}
+	for _, evaluator := range evaluators {
+		for _, flagKey := range heldFlagsOf(evaluator) {
+			keys[flagKey] = struct{}{}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	held := make([]string, 0, len(keys))
+	for flagKey := range keys {
+		held = append(held, flagKey)
+	}
+	sort.Strings(held)
+	return held
+}
@@ -0,0 +1,117 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const flappingFlagConfig = `{
+  "flags": {
+    "killSwitch": {
+      "state": "%s",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red"
+    }
+  }
+}`
+
+func TestFlapDetection(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	core, logs := observer.New(zap.WarnLevel)
+	je := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), store.NewFlags(),
+		eval.WithClock(clock), eval.WithFlapDetection(2, time.Minute))
+	setState := func(state string) {
+		t.Helper()
+		_, _, err := je.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(flappingFlagConfig, state), Source: "flags.json", Type: sync.ALL,
+		})
+		require.Nil(t, err)
+	}
+	enabled := func() bool {
+		t.Helper()
+		_, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+		return err == nil
+	}
+
+	setState("ENABLED")
+	require.True(t, enabled())
+	setState("DISABLED")
+	clock.Advance(time.Second)
+	setState("ENABLED")
+	require.True(t, enabled(), "flags changing up to the threshold must be applied")
+	require.Empty(t, je.HeldFlags())
+
+	clock.Advance(time.Second)
+	setState("DISABLED")
+	require.True(t, enabled(), "a flapping flag must hold its last value")
+	require.Equal(t, []string{"killSwitch"}, je.HeldFlags())
+	require.Equal(t, 1, logs.FilterMessage(
+		"flag: killSwitch changed more than 2 times within 1m0s, holding its value until it stabilizes").Len())
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(10 * time.Second)
+		setState([]string{"ENABLED", "DISABLED"}[i%2])
+		require.True(t, enabled(), "a flapping flag must hold its value while it keeps changing")
+	}
+	require.Equal(t, 1, logs.Len(), "the hold must only be logged once")
+
+	clock.Advance(30 * time.Second)
+	setState("DISABLED")
+	require.True(t, enabled(), "a held flag changed within the window isn't stable")
+
+	clock.Advance(time.Minute)
+	setState("DISABLED")
+	require.False(t, enabled(), "a held flag must apply its latest definition once stable")
+	require.Empty(t, je.HeldFlags())
+	require.Equal(t, 1, logs.FilterMessage("flag: killSwitch stabilized, applying its latest definition").Len())
+}
+
+func TestFlapDetection_Deletion(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	je := eval.NewJSONEvaluator(nil, store.NewFlags(), eval.WithClock(clock), eval.WithFlapDetection(1, time.Minute))
+	withFlag := fmt.Sprintf(flappingFlagConfig, "ENABLED")
+	withoutFlag := `{"flags": {"headerColor": {"state": "ENABLED", "variants": {"red": "#FF0000", "blue": "#0000FF"},
+	  "defaultVariant": "red"}}}`
+	for _, config := range []string{withFlag, withoutFlag, withFlag, withoutFlag} {
+		_, _, err := je.SetState(sync.DataSync{FlagData: config, Source: "flags.json", Type: sync.ALL})
+		require.Nil(t, err)
+	}
+	_, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+	require.NotNil(t, err)
+	require.Equal(t, model.FlagNotFoundErrorCode, err.Error(),
+		"a flag deleted and added back repeatedly must hold its deletion")
+	require.Equal(t, []string{"killSwitch"}, je.HeldFlags())
+
+	_, _, _, _, err = je.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+	require.Nil(t, err, "stable flags mustn't be held")
+}
+
+func TestFlapDetection_Disabled(t *testing.T) {
+	je := eval.NewJSONEvaluator(nil, store.NewFlags())
+	for _, state := range []string{"ENABLED", "DISABLED", "ENABLED", "DISABLED"} {
+		_, _, err := je.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(flappingFlagConfig, state), Source: "flags.json", Type: sync.ALL,
+		})
+		require.Nil(t, err)
+	}
+	_, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+	require.NotNil(t, err)
+	require.Nil(t, je.HeldFlags())
+}
@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const compositeFractionalFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00", "yellow": "#FFFF00" },
+      "defaultVariant": "red",
+      "targeting": {
+        "fractionalEvaluation": [
+          ["org", "user"],
+          ["red", 25], ["blue", 25], ["green", 25], ["yellow", 25]
+        ]
+      }
+    }
+  }
+}`
+
+func TestCompositeValue(t *testing.T) {
+	tests := map[string]struct {
+		keys      []interface{}
+		data      map[string]interface{}
+		want      string
+		wantFound bool
+		wantErr   bool
+	}{
+		"all keys": {
+			keys:      []interface{}{"org", "user"},
+			data:      map[string]interface{}{"org": "acme", "user": "jane"},
+			want:      `["acme","jane"]`,
+			wantFound: true,
+		},
+		"order of the keys": {
+			keys:      []interface{}{"user", "org"},
+			data:      map[string]interface{}{"org": "acme", "user": "jane"},
+			want:      `["jane","acme"]`,
+			wantFound: true,
+		},
+		"single key": {
+			keys:      []interface{}{"user"},
+			data:      map[string]interface{}{"org": "acme", "user": "jane"},
+			want:      "jane",
+			wantFound: true,
+		},
+		"missing key": {
+			keys:      []interface{}{"org", "user"},
+			data:      map[string]interface{}{"user": "jane"},
+			want:      `[null,"jane"]`,
+			wantFound: true,
+		},
+		"no key": {
+			keys: []interface{}{"org", "user"},
+			data: map[string]interface{}{"email": "jane@faas.com"},
+		},
+		"non-string value": {
+			keys:    []interface{}{"org", "user"},
+			data:    map[string]interface{}{"org": 42.0},
+			wantErr: true,
+		},
+		"non-string key": {
+			keys:    []interface{}{"org", 1.0},
+			data:    map[string]interface{}{"org": "acme"},
+			wantErr: true,
+		},
+		"empty keys": {
+			keys:    []interface{}{},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, found, err := compositeValue(tt.keys, tt.data)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.wantFound, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFractionalEvaluation_Composite(t *testing.T) {
+	evaluator, err := NewJSONEvaluatorFromConfig(nil, compositeFractionalFlagConfig)
+	require.Nil(t, err)
+	resolve := func(values map[string]interface{}) (string, string) {
+		evalCtx, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		return variant, reason
+	}
+
+	// the assignment of a composite is stable, and the composite of an org and user buckets as its values do
+	for _, user := range []string{"jane", "john", "alice", "bob"} {
+		values := map[string]interface{}{"org": "acme", "user": user}
+		composite, _, err := compositeValue([]interface{}{"org", "user"}, values)
+		require.Nil(t, err)
+		variant, _ := resolve(values)
+		require.Equal(t, distributedVariant(t, composite), variant)
+		for i := 0; i < 10; i++ {
+			again, _ := resolve(map[string]interface{}{"user": user, "org": "acme", "email": user + "@faas.com"})
+			require.Equal(t, variant, again, "the composite should be assigned the same variant")
+		}
+	}
+
+	// a missing key buckets the keys present
+	variant, _ := resolve(map[string]interface{}{"user": "jane"})
+	require.Equal(t, distributedVariant(t, `[null,"jane"]`), variant)
+
+	// a context holding none of the keys doesn't match, resolving the default variant
+	variant, reason := resolve(map[string]interface{}{"email": "jane@faas.com"})
+	require.Equal(t, "red", variant)
+	require.Equal(t, model.DefaultReason, reason)
+}
+
+// distributedVariant returns the variant the headerColor flag distributes the value to
+func distributedVariant(t *testing.T, value string) string {
+	t.Helper()
+	variant, _ := distributeValue(BucketingHashXXH3, value, []fractionalEvaluationDistribution{
+		{variant: "red", percentage: 25}, {variant: "blue", percentage: 25},
+		{variant: "green", percentage: 25}, {variant: "yellow", percentage: 25},
+	})
+	return variant
+}
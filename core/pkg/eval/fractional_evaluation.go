@@ -1,29 +1,52 @@
 package eval
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
-
-	"github.com/zeebo/xxh3"
 )
 
+const fractionalEvaluationOperator = "fractionalEvaluation"
+
 type fractionalEvaluationDistribution struct {
 	variant    string
 	percentage int
 }
 
 func (je *JSONEvaluator) fractionalEvaluation(values, data interface{}) interface{} {
-	valueToDistribute, feDistributions, err := parseFractionalEvaluationData(values, data)
+	randomization := fractionalRandomizationOf(data)
+	if randomization == FractionalRandomizationAlways {
+		return je.sampleFractionalEvaluation(values)
+	}
+	valueToDistribute, feDistributions, err := parseFractionalEvaluationData(values, data, je.fractionalRemainder)
 	if err != nil {
 		je.Logger.Error(fmt.Sprintf("parse fractional evaluation data: %v", err))
 		return nil
 	}
+	if feDistributions == nil && randomization == FractionalRandomizationMissing {
+		// the value to distribute is missing
+		return je.sampleFractionalEvaluation(values)
+	}
+
+	variant, bucket := distributeValue(je.bucketingHash, valueToDistribute, feDistributions)
+	return fractionalResult(variant, bucket)
+}
+
+// fractionalResult annotates the variant of a fractional evaluation with its bucket
+func fractionalResult(variant string, bucket int) interface{} {
+	if variant == "" {
+		return variant
+	}
 
-	return distributeValue(valueToDistribute, feDistributions)
+	return map[string]interface{}{
+		targetingVariantKey: variant,
+		BucketMetadataKey:   bucket,
+	}
 }
 
-func parseFractionalEvaluationData(values, data interface{}) (string, []fractionalEvaluationDistribution, error) {
+func parseFractionalEvaluationData(
+	values, data interface{}, remainder FractionalRemainder,
+) (string, []fractionalEvaluationDistribution, error) {
 	valuesArray, ok := values.([]interface{})
 	if !ok {
 		return "", nil, errors.New("fractional evaluation data is not an array")
@@ -32,27 +55,31 @@ func parseFractionalEvaluationData(values, data interface{}) (string, []fraction
 		return "", nil, errors.New("fractional evaluation data has length under 2")
 	}
 
-	bucketBy, ok := valuesArray[0].(string)
-	if !ok {
-		return "", nil, errors.New("first element of fractional evaluation data isn't of type string")
-	}
-
 	dataMap, ok := data.(map[string]interface{})
 	if !ok {
 		return "", nil, errors.New("data isn't of type map[string]interface{}")
 	}
 
-	v, ok := dataMap[bucketBy]
-	if !ok {
-		return "", nil, nil
-	}
-
-	valueToDistribute, ok := v.(string)
-	if !ok {
-		return "", nil, fmt.Errorf("var: %s isn't of type string", bucketBy)
+	var valueToDistribute string
+	switch bucketBy := valuesArray[0].(type) {
+	case string:
+		v, ok := dataMap[bucketBy]
+		if !ok {
+			return "", nil, nil
+		}
+		if valueToDistribute, ok = v.(string); !ok {
+			return "", nil, fmt.Errorf("var: %s isn't of type string", bucketBy)
+		}
+	case []interface{}:
+		var err error
+		if valueToDistribute, ok, err = compositeValue(bucketBy, dataMap); err != nil || !ok {
+			return "", nil, err
+		}
+	default:
+		return "", nil, errors.New("first element of fractional evaluation data isn't of type string or array")
 	}
 
-	feDistributions, err := parseFractionalEvaluationDistributions(valuesArray)
+	feDistributions, err := parseFractionalEvaluationDistributions(valuesArray, remainder)
 	if err != nil {
 		return "", nil, err
 	}
@@ -60,7 +87,65 @@ func parseFractionalEvaluationData(values, data interface{}) (string, []fraction
 	return valueToDistribute, feDistributions, nil
 }
 
-func parseFractionalEvaluationDistributions(values []interface{}) ([]fractionalEvaluationDistribution, error) {
+// compositeValue returns the value distributing the composite of the context keys, the json array of their values in
+// the order of the keys. Missing keys are null values of the composite, so evaluations sharing the keys they hold
+// share their bucket, and the value is missing when the context holds none of the keys. A single key distributes its
+// value as it is.
+func compositeValue(keys []interface{}, data map[string]interface{}) (string, bool, error) {
+	if len(keys) == 0 {
+		return "", false, errors.New("fractional evaluation data has no bucketing keys")
+	}
+	values := make([]interface{}, len(keys))
+	found := false
+	for i, k := range keys {
+		key, ok := k.(string)
+		if !ok {
+			return "", false, errors.New("bucketing keys of fractional evaluation data aren't of type string")
+		}
+		v, ok := data[key]
+		if !ok {
+			continue
+		}
+		if values[i], ok = v.(string); !ok {
+			return "", false, fmt.Errorf("var: %s isn't of type string", key)
+		}
+		found = true
+	}
+	if !found {
+		return "", false, nil
+	}
+	if len(values) == 1 {
+		return values[0].(string), true, nil
+	}
+	composite, err := json.Marshal(values)
+	if err != nil {
+		return "", false, fmt.Errorf("composite bucketing value: %w", err)
+	}
+	return string(composite), true, nil
+}
+
+// bucketingKeys returns the context keys bucketing a fractional evaluation, of a single key or composite
+func bucketingKeys(bucketBy interface{}) []string {
+	switch b := bucketBy.(type) {
+	case string:
+		return []string{b}
+	case []interface{}:
+		keys := make([]string, 0, len(b))
+		for _, k := range b {
+			if key, ok := k.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+// parseFractionalEvaluationDistributions parses the buckets of a fractional evaluation, whose percentages are checked
+// against the remainder policy
+func parseFractionalEvaluationDistributions(
+	values []interface{}, remainder FractionalRemainder,
+) ([]fractionalEvaluationDistribution, error) {
 	sumOfPercentages := 0
 	var feDistributions []fractionalEvaluationDistribution
 	for i := 1; i < len(values); i++ {
@@ -91,20 +176,24 @@ func parseFractionalEvaluationDistributions(values []interface{}) ([]fractionalE
 		})
 	}
 
-	if sumOfPercentages != 100 {
-		return nil, fmt.Errorf("percentages must sum to 100, got: %d", sumOfPercentages)
+	if err := checkPercentages(sumOfPercentages, remainder); err != nil {
+		return nil, err
 	}
 
 	return feDistributions, nil
 }
 
-func distributeValue(value string, feDistribution []fractionalEvaluationDistribution) string {
-	hashValue := xxh3.HashString(value)
+func distributeValue(
+	hash BucketingHash, value string, feDistribution []fractionalEvaluationDistribution,
+) (string, int) {
+	bucket := int(hash.ratio(value) * 100) // integer in range [0, 99]
 
-	hashRatio := float64(hashValue) / math.Pow(2, 64) // divide the hash value by the largest possible value, integer 2^64
-
-	bucket := int(hashRatio * 100) // integer in range [0, 99]
+	return bucketVariant(bucket, feDistribution), bucket
+}
 
+// bucketVariant returns the variant of the distribution whose range holds the bucket, none for the remaining buckets
+// of distributions summing to under 100
+func bucketVariant(bucket int, feDistribution []fractionalEvaluationDistribution) string {
 	rangeEnd := 0
 	for _, dist := range feDistribution {
 		rangeEnd += dist.percentage
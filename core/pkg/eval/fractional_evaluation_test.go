@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"context"
 	"testing"
 
 	"github.com/open-feature/flagd/core/pkg/store"
@@ -283,10 +284,12 @@ func TestFractionalEvaluation(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			je := NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
-			je.store.Flags = tt.flags.Flags
+			for key, flag := range tt.flags.Flags {
+				je.store.Set(key, flag)
+			}
 
-			value, variant, reason, err := resolve[string](
-				reqID, tt.flagKey, tt.context, je.evaluateVariant, je.store.Flags[tt.flagKey].Variants,
+			value, variant, reason, _, err := resolve[string](
+				context.Background(), reqID, tt.flagKey, tt.context, je.evaluateVariant, tt.flags.Flags[tt.flagKey].Variants,
 			)
 
 			if value != tt.expectedValue {
@@ -415,10 +418,13 @@ func BenchmarkFractionalEvaluation(b *testing.B) {
 	reqID := "test"
 	for name, tt := range tests {
 		b.Run(name, func(b *testing.B) {
-			je := JSONEvaluator{store: &store.Flags{Flags: tt.flags.Flags}}
+			je := NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+			for key, flag := range tt.flags.Flags {
+				je.store.Set(key, flag)
+			}
 			for i := 0; i < b.N; i++ {
-				value, variant, reason, err := resolve[string](
-					reqID, tt.flagKey, tt.context, je.evaluateVariant, je.store.Flags[tt.flagKey].Variants,
+				value, variant, reason, _, err := resolve[string](
+					context.Background(), reqID, tt.flagKey, tt.context, je.evaluateVariant, tt.flags.Flags[tt.flagKey].Variants,
 				)
 
 				if value != tt.expectedValue {
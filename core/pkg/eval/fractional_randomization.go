@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	// FractionalRandomizationOff buckets the context value of fractional evaluations, so evaluations of the same value
+	// resolve the same variant, and evaluations missing it don't match. It's the default.
+	FractionalRandomizationOff = "off"
+	// FractionalRandomizationMissing samples the distribution of fractional evaluations per evaluation when their
+	// context value is missing, evaluations holding it are bucketed
+	FractionalRandomizationMissing = "missing"
+	// FractionalRandomizationAlways samples the distribution of fractional evaluations per evaluation, whatever the
+	// context, so evaluations aren't sticky
+	FractionalRandomizationAlways = "always"
+)
+
+// validateFractionalRandomization checks the fractional randomization of a flag is one of the known modes
+func validateFractionalRandomization(key string, flag model.Flag) error {
+	switch flag.FractionalRandomization {
+	case "", FractionalRandomizationOff, FractionalRandomizationMissing, FractionalRandomizationAlways:
+		return nil
+	default:
+		return fmt.Errorf("unknown fractional randomization: '%s' of flag: '%s', expected one of '%s', '%s' or '%s'",
+			flag.FractionalRandomization, key, FractionalRandomizationOff, FractionalRandomizationMissing,
+			FractionalRandomizationAlways)
+	}
+}
+
+// fractionalRandomizationOf returns the fractional randomization of the flag whose targeting data is evaluated
+func fractionalRandomizationOf(data interface{}) string {
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	properties, ok := dataMap[flagdPropertiesKey].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	randomization, _ := properties[fractionalRandomizationProperty].(string)
+	return randomization
+}
+
+// sampleFractionalEvaluation picks a random bucket of the distribution of a fractional evaluation, ignoring the
+// context value it names
+func (je *JSONEvaluator) sampleFractionalEvaluation(values interface{}) interface{} {
+	valuesArray, ok := values.([]interface{})
+	if !ok || len(valuesArray) < 2 {
+		je.Logger.Error("parse fractional evaluation data: data isn't an array of length 2 or more")
+		return nil
+	}
+	feDistributions, err := parseFractionalEvaluationDistributions(valuesArray, je.fractionalRemainder)
+	if err != nil {
+		je.Logger.Error(fmt.Sprintf("parse fractional evaluation data: %v", err))
+		return nil
+	}
+	bucket := rand.Intn(100) //nolint:gosec // assignments don't need a cryptographic source
+	return fractionalResult(bucketVariant(bucket, feDistributions), bucket)
+}
@@ -0,0 +1,79 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func randomizedFlagConfig(randomization string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "experiment": {
+      "state": "ENABLED",
+      "variants": { "control": "control", "treatment": "treatment", "none": "none" },
+      "defaultVariant": "none",
+      "fractionalRandomization": %q,
+      "targeting": { "fractionalEvaluation": ["targetingKey", ["control", 75], ["treatment", 25]] }
+    }
+  }
+}`, randomization)
+}
+
+// evaluateExperiment returns the number of evaluations resolving each variant
+func evaluateExperiment(t *testing.T, evaluator eval.IEvaluator, values map[string]interface{}, n int) map[string]int {
+	t.Helper()
+	evalCtx, err := structpb.NewStruct(values)
+	require.Nil(t, err)
+	variants := map[string]int{}
+	for i := 0; i < n; i++ {
+		_, variant, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "experiment", evalCtx)
+		require.Nil(t, err)
+		variants[variant]++
+	}
+	return variants
+}
+
+func TestFractionalRandomization(t *testing.T) {
+	const evaluations = 10000
+	keyed := map[string]interface{}{"targetingKey": "user-1"}
+	anonymous := map[string]interface{}{}
+
+	always, err := eval.NewJSONEvaluatorFromConfig(nil, randomizedFlagConfig(eval.FractionalRandomizationAlways))
+	require.Nil(t, err)
+	variants := evaluateExperiment(t, always, keyed, evaluations)
+	require.InDelta(t, 0.75*evaluations, variants["control"], 0.05*evaluations, "the distribution must be sampled")
+	require.InDelta(t, 0.25*evaluations, variants["treatment"], 0.05*evaluations, "the distribution must be sampled")
+	require.Zero(t, variants["none"])
+
+	missing, err := eval.NewJSONEvaluatorFromConfig(nil, randomizedFlagConfig(eval.FractionalRandomizationMissing))
+	require.Nil(t, err)
+	variants = evaluateExperiment(t, missing, anonymous, evaluations)
+	require.InDelta(t, 0.75*evaluations, variants["control"], 0.05*evaluations)
+	require.InDelta(t, 0.25*evaluations, variants["treatment"], 0.05*evaluations)
+	require.Len(t, evaluateExperiment(t, missing, keyed, 100), 1, "evaluations with a key must stay sticky")
+
+	off, err := eval.NewJSONEvaluatorFromConfig(nil, randomizedFlagConfig(eval.FractionalRandomizationOff))
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"none": 100}, evaluateExperiment(t, off, anonymous, 100),
+		"evaluations without a key resolve the default variant")
+	require.Len(t, evaluateExperiment(t, off, keyed, 100), 1)
+}
+
+func TestFractionalRandomization_NotSticky(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, randomizedFlagConfig(eval.FractionalRandomizationAlways))
+	require.Nil(t, err)
+	// the chance of 100 evaluations of the same key resolving the same variant is below 0.75^100
+	variants := evaluateExperiment(t, evaluator, map[string]interface{}{"targetingKey": "user-1"}, 100)
+	require.Len(t, variants, 2, "evaluations of the same key must be independent")
+}
+
+func TestFractionalRandomization_Invalid(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, randomizedFlagConfig("sometimes"))
+	require.EqualError(t, err, "unknown fractional randomization: 'sometimes' of flag: 'experiment', "+
+		"expected one of 'off', 'missing' or 'always'")
+}
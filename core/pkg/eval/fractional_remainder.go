@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"fmt"
+)
+
+// FractionalRemainder defines how the buckets of fractional evaluations whose percentages sum to under 100 are
+// handled, e.g. a rollout of 25% blue and 25% green. Percentages summing to over 100 are always rejected, as the
+// buckets of the last variants would be truncated.
+type FractionalRemainder string
+
+const (
+	// FractionalRemainderError rejects flags whose fractional evaluations don't sum to 100, the default
+	FractionalRemainderError FractionalRemainder = "error"
+	// FractionalRemainderDefault loads flags whose fractional evaluations sum to under 100, the remaining buckets
+	// resolving the default variant of the flag
+	FractionalRemainderDefault FractionalRemainder = "default"
+)
+
+// ParseFractionalRemainder returns the fractional remainder policy of its name, an empty name defaults to error
+func ParseFractionalRemainder(policy string) (FractionalRemainder, error) {
+	switch FractionalRemainder(policy) {
+	case "":
+		return FractionalRemainderError, nil
+	case FractionalRemainderError, FractionalRemainderDefault:
+		return FractionalRemainder(policy), nil
+	default:
+		return "", fmt.Errorf("unknown fractional remainder policy: '%s', expected '%s' or '%s'",
+			policy, FractionalRemainderError, FractionalRemainderDefault)
+	}
+}
+
+// WithFractionalRemainder sets the policy of fractional evaluations whose percentages sum to under 100
+func WithFractionalRemainder(policy FractionalRemainder) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.fractionalRemainder = policy
+	}
+}
+
+// checkPercentages checks the sum of the percentages of a fractional evaluation against the remainder policy
+func checkPercentages(sum int, remainder FractionalRemainder) error {
+	switch {
+	case sum > 100:
+		return fmt.Errorf("percentages must sum to 100, got: %d", sum)
+	case sum < 100 && remainder != FractionalRemainderDefault:
+		return fmt.Errorf("percentages must sum to 100, got: %d, or the remaining buckets resolve the default "+
+			"variant with the '%s' fractional remainder", sum, FractionalRemainderDefault)
+	}
+	return nil
+}
+
+// validateFractionalPercentages checks the percentages of the fractional evaluations of the targeting rule, as
+// they'll be evaluated. Fractional evaluations whose percentages are computed from the evaluation context are checked
+// when evaluating.
+func (je *JSONEvaluator) validateFractionalPercentages(node interface{}, path string) error {
+	switch n := node.(type) {
+	case []interface{}:
+		for i, element := range n {
+			if err := je.validateFractionalPercentages(element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for operator, values := range n {
+			operatorPath := fmt.Sprintf("%s.%s", path, operator)
+			if args, ok := values.([]interface{}); ok && operator == fractionalEvaluationOperator {
+				if sum, ok := literalPercentages(args); ok {
+					if err := checkPercentages(sum, je.fractionalRemainder); err != nil {
+						return fmt.Errorf("fractional evaluation at %s: %w", operatorPath, err)
+					}
+				}
+			}
+			if err := je.validateFractionalPercentages(values, operatorPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// literalPercentages returns the sum of the percentages of a fractional evaluation, reporting false when they aren't
+// all literals
+func literalPercentages(args []interface{}) (int, bool) {
+	if len(args) < 2 {
+		return 0, false
+	}
+	sum := 0
+	for _, arg := range args[1:] {
+		bucket, ok := arg.([]interface{})
+		if !ok || len(bucket) != 2 {
+			return 0, false
+		}
+		percentage, ok := bucket[1].(float64)
+		if !ok {
+			return 0, false
+		}
+		sum += int(percentage)
+	}
+	return sum, true
+}
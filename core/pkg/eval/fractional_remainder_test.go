@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const fractionalRemainderFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "green",
+      "targeting": {
+        "if": [{ "var": "beta" }, "blue", { "fractionalEvaluation": ["email", ["red", 25], ["blue", %d]] }]
+      }
+    }
+  }
+}`
+
+func TestFractionalRemainder_Load(t *testing.T) {
+	tests := map[string]struct {
+		remainder FractionalRemainder
+		blue      int
+		err       string
+	}{
+		"sum of 100": {
+			remainder: FractionalRemainderError,
+			blue:      75,
+		},
+		"under 100 rejected": {
+			remainder: FractionalRemainderError,
+			blue:      25,
+			err: "targeting of flag: 'headerColor': fractional evaluation at targeting.if[2].fractionalEvaluation: " +
+				"percentages must sum to 100, got: 50, or the remaining buckets resolve the default variant with the " +
+				"'default' fractional remainder",
+		},
+		"under 100 resolving the default variant": {
+			remainder: FractionalRemainderDefault,
+			blue:      25,
+		},
+		"over 100 rejected": {
+			remainder: FractionalRemainderError,
+			blue:      80,
+			err: "targeting of flag: 'headerColor': fractional evaluation at targeting.if[2].fractionalEvaluation: " +
+				"percentages must sum to 100, got: 105",
+		},
+		"over 100 rejected whatever the remainder": {
+			remainder: FractionalRemainderDefault,
+			blue:      80,
+			err: "targeting of flag: 'headerColor': fractional evaluation at targeting.if[2].fractionalEvaluation: " +
+				"percentages must sum to 100, got: 105",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(fractionalRemainderFlagConfig, tt.blue),
+				WithFractionalRemainder(tt.remainder))
+			if tt.err == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFractionalRemainder_Evaluation(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(fractionalRemainderFlagConfig, 25),
+		WithFractionalRemainder(FractionalRemainderDefault))
+	require.Nil(t, err)
+
+	remainders := 0
+	for i := 0; i < 200; i++ {
+		email := fmt.Sprintf("user%d@faas.com", i)
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": email})
+		require.Nil(t, err)
+		_, variant, reason, _, err := je.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+
+		bucket := int(je.bucketingHash.ratio(email) * 100)
+		switch {
+		case bucket < 25:
+			require.Equal(t, "red", variant, email)
+			require.Equal(t, model.TargetingMatchReason, reason, email)
+		case bucket < 50:
+			require.Equal(t, "blue", variant, email)
+			require.Equal(t, model.TargetingMatchReason, reason, email)
+		default:
+			remainders++
+			require.Equal(t, "green", variant, "the remaining buckets should resolve the default variant")
+			require.Equal(t, model.DefaultReason, reason, email)
+		}
+	}
+	require.Positive(t, remainders)
+}
+
+func TestFractionalRemainder_ComputedPercentages(t *testing.T) {
+	// percentages computed from the context can't be checked when loading, they're checked when evaluating
+	config := `{
+	  "flags": {
+	    "headerColor": {
+	      "state": "ENABLED",
+	      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+	      "defaultVariant": "red",
+	      "targeting": { "fractionalEvaluation": ["email", ["red", 25], ["blue", { "var": "blueShare" }]] }
+	    }
+	  }
+	}`
+	je, err := NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com", "blueShare": 25})
+	require.Nil(t, err)
+	_, variant, reason, _, err := je.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	require.Nil(t, err)
+	require.Equal(t, "red", variant)
+	require.Equal(t, model.DefaultReason, reason)
+}
+
+func TestParseFractionalRemainder(t *testing.T) {
+	policy, err := ParseFractionalRemainder("")
+	require.Nil(t, err)
+	require.Equal(t, FractionalRemainderError, policy)
+	policy, err = ParseFractionalRemainder("default")
+	require.Nil(t, err)
+	require.Equal(t, FractionalRemainderDefault, policy)
+	_, err = ParseFractionalRemainder("variant")
+	require.EqualError(t, err, "unknown fractional remainder policy: 'variant', expected 'error' or 'default'")
+}
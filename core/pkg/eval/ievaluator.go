@@ -1,55 +1,74 @@
 package eval
 
 import (
+	"context"
+
 	"github.com/open-feature/flagd/core/pkg/sync"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type AnyValue struct {
-	Value   interface{}
-	Variant string
-	Reason  string
-	FlagKey string
+	Value    interface{}
+	Variant  string
+	Reason   string
+	FlagKey  string
+	Metadata map[string]interface{}
 }
 
-func NewAnyValue(value interface{}, variant string, reason string, flagKey string) AnyValue {
+func NewAnyValue(
+	value interface{}, variant string, reason string, flagKey string, metadata map[string]interface{},
+) AnyValue {
 	return AnyValue{
-		Value:   value,
-		Variant: variant,
-		Reason:  reason,
-		FlagKey: flagKey,
+		Value:    value,
+		Variant:  variant,
+		Reason:   reason,
+		FlagKey:  flagKey,
+		Metadata: metadata,
 	}
 }
 
 /*
 IEvaluator implementations store the state of the flags,
 do parsing and validation of the flag state and evaluate flags in response to handlers.
+Evaluations abort with the error of ctx once it's done, e.g. when the client of the request disconnects.
 */
 type IEvaluator interface {
 	GetState() (string, error)
 	SetState(payload sync.DataSync) (map[string]interface{}, bool, error)
 
 	ResolveBooleanValue(
+		ctx context.Context,
 		reqID string,
 		flagKey string,
-		context *structpb.Struct) (value bool, variant string, reason string, err error)
+		context *structpb.Struct,
+	) (value bool, variant string, reason string, metadata map[string]interface{}, err error)
 	ResolveStringValue(
+		ctx context.Context,
 		reqID string,
 		flagKey string,
-		context *structpb.Struct) (value string, variant string, reason string, err error)
+		context *structpb.Struct,
+	) (value string, variant string, reason string, metadata map[string]interface{}, err error)
 	ResolveIntValue(
+		ctx context.Context,
 		reqID string,
 		flagKey string,
-		context *structpb.Struct) (value int64, variant string, reason string, err error)
+		context *structpb.Struct,
+	) (value int64, variant string, reason string, metadata map[string]interface{}, err error)
 	ResolveFloatValue(
+		ctx context.Context,
 		reqID string,
 		flagKey string,
-		context *structpb.Struct) (value float64, variant string, reason string, err error)
+		context *structpb.Struct,
+	) (value float64, variant string, reason string, metadata map[string]interface{}, err error)
 	ResolveObjectValue(
+		ctx context.Context,
 		reqID string,
 		flagKey string,
-		context *structpb.Struct) (value map[string]any, variant string, reason string, err error)
+		context *structpb.Struct,
+	) (value map[string]any, variant string, reason string, metadata map[string]interface{}, err error)
 	ResolveAllValues(
+		ctx context.Context,
 		reqID string,
-		context *structpb.Struct) (values []AnyValue)
+		context *structpb.Struct,
+	) (values []AnyValue)
 }
@@ -1,13 +1,18 @@
 package eval
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	goruntime "runtime"
+	"sort"
 	"strconv"
 	"strings"
+	gosync "sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
@@ -21,15 +26,96 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-var regBrace *regexp.Regexp
+var (
+	regBrace *regexp.Regexp
+
+	flagSchemaOnce gosync.Once
+	flagSchema     *gojsonschema.Schema
+	flagSchemaErr  error
+)
 
 func init() {
 	regBrace = regexp.MustCompile("^[^{]*{|}[^}]*$")
 }
 
 type JSONEvaluator struct {
-	store  *store.Flags
-	Logger *logger.Logger
+	store             *store.Flags
+	Logger            *logger.Logger
+	rules             ruleCache
+	derived           ruleCache
+	patterns          regexCache
+	validationWorkers int
+	// defaultVariantFallback replaces missing or invalid default variants with the first variant of the flag
+	defaultVariantFallback bool
+	clock                  Clock
+	keyNormalization       KeyNormalization
+	ruleWarmup             bool
+	largeIntegers          LargeIntegers
+	bucketingHash          BucketingHash
+	schemaMismatch         SchemaMismatch
+	templateMissingKeys    TemplateMissingKeys
+	variantTypeMismatch    VariantTypeMismatch
+	// flagKeyCharacters matches each allowed character of flag keys, keys aren't checked when nil
+	flagKeyCharacters *regexp.Regexp
+	invalidFlagKeys   InvalidFlagKeys
+	// sourcePrefixes are the prefixes of the flag keys of sources, keyed by source URI
+	sourcePrefixes map[string]string
+	// overrideSecret verifies the override tokens of evaluation contexts, tokens are ignored when nil
+	overrideSecret []byte
+	// evaluationHash adds the hash of each evaluation to its resolution metadata
+	evaluationHash bool
+	// contextKeysMetadata adds the context keys read by each flag to its resolution metadata
+	contextKeysMetadata bool
+	// ruleStatistics records the matched branches of targeting rules, nil unless enabled
+	ruleStatistics *ruleStatistics
+	// flaps holds the value of flags whose definition changes too often, nil unless enabled
+	flaps *flapDetector
+	// circuitBreakers short-circuits flags whose targeting repeatedly fails, nil unless enabled
+	circuitBreakers *circuitBreakers
+	// lastGood holds the last successful targeting results of the flags serving them when their targeting fails
+	lastGood lastGoodResults
+	// namespaceSeparator separates the namespaces of flag keys, undefined flags resolve through their namespace
+	// unless empty
+	namespaceSeparator string
+	// maxVariants rejects flags with more variants when loading their configuration, unbounded when 0
+	maxVariants int
+	// undefinedVariants is the policy of targeting rules resolving variants which the flag doesn't define
+	undefinedVariants UndefinedVariants
+	// fractionalRemainder is the policy of fractional evaluations whose percentages sum to under 100
+	fractionalRemainder FractionalRemainder
+	// targetingReordering orders the exclusive conditions of targeting rules by their observed matches
+	targetingReordering bool
+	// targetingOrders holds the evaluation order of the targeting of the flags of the loaded configuration, by flag
+	// key, flags which aren't in it evaluate their targeting as declared
+	targetingOrders atomic.Pointer[map[string]*orderedTargeting]
+	// loaded is set by the first successful SetState, missing flags are reported as not ready until then
+	loaded atomic.Bool
+	// contextCoercion converts the evaluation context values compared by targeting rules to the type of the comparison
+	contextCoercion ContextCoercion
+	// notFoundGrace reports undefined flags as not ready for a grace period, nil unless enabled
+	notFoundGrace *notFoundGrace
+	// pinned keeps the stored definition of the pinned flags across reloads, nil unless flags are pinned
+	pinned *pinnedFlags
+	// allowedContextKeys are the context keys targeting rules may reference, any key may be referenced when nil
+	allowedContextKeys map[string]struct{}
+	// fallbackLevels are the enabled levels of the fallback chain, the DefaultFallbackChain when nil
+	fallbackLevels map[FallbackLevel]struct{}
+	// options configured the evaluator, they configure the evaluators comparing its configuration with a candidate
+	options []JSONEvaluatorOption
+	// reloads serializes the updates of the configuration, concurrent reloads are queued and each is built and
+	// applied as a whole before the next one starts
+	reloads gosync.Mutex
+}
+
+// JSONEvaluatorOption configures optional behaviour of the JSONEvaluator
+type JSONEvaluatorOption func(je *JSONEvaluator)
+
+// WithValidationWorkers sets the number of workers validating flag configurations concurrently,
+// values below 1 default to GOMAXPROCS
+func WithValidationWorkers(workers int) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.validationWorkers = workers
+	}
 }
 
 type constraints interface {
@@ -37,81 +123,169 @@ type constraints interface {
 }
 
 const (
+	Enabled  = "ENABLED"
 	Disabled = "DISABLED"
+
+	// RuleIDMetadataKey is the metadata key holding the id of the matched targeting rule
+	RuleIDMetadataKey = "ruleId"
+	// BucketMetadataKey is the metadata key holding the bucket [0, 99] selected by a fractional evaluation
+	BucketMetadataKey = "bucket"
+	// CacheTTLMetadataKey is the metadata key holding the number of seconds a resolution may be cached for
+	CacheTTLMetadataKey = "cacheTtl"
+
+	targetingVariantKey = "variant"
 )
 
-func NewJSONEvaluator(logger *logger.Logger, s *store.Flags) *JSONEvaluator {
+// NewJSONEvaluator returns a JSONEvaluator backed by the provided store. A nil logger disables logging
+// and a nil store defaults to an empty store.
+func NewJSONEvaluator(log *logger.Logger, s *store.Flags, opts ...JSONEvaluatorOption) *JSONEvaluator {
+	if log == nil {
+		log = logger.NewLogger(nil, false)
+	}
+	if s == nil {
+		s = store.NewFlags()
+	}
 	ev := JSONEvaluator{
-		Logger: logger.WithFields(
+		Logger: log.WithFields(
 			zap.String("component", "evaluator"),
 			zap.String("evaluator", "json"),
 		),
-		store: s,
+		store:   s,
+		clock:   realClock{},
+		options: opts,
+	}
+	for _, opt := range opts {
+		opt(&ev)
 	}
-	jsonlogic.AddOperator("fractionalEvaluation", ev.fractionalEvaluation)
 	return &ev
 }
 
+// NewJSONEvaluatorFromConfig returns a JSONEvaluator holding the flags of the provided configuration.
+// This allows the evaluation engine to be embedded without the flagd runtime, no sync providers or services are
+// started and the flags can be replaced at any time through SetState.
+func NewJSONEvaluatorFromConfig(
+	log *logger.Logger, config string, opts ...JSONEvaluatorOption,
+) (*JSONEvaluator, error) {
+	ev := NewJSONEvaluator(log, store.NewFlags(), opts...)
+	if _, _, err := ev.SetState(sync.DataSync{FlagData: config, Type: sync.ALL}); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
 func (je *JSONEvaluator) GetState() (string, error) {
 	return je.store.String()
 }
 
+// SetState updates the configuration with the flags of the payload, updates are applied one at a time
 func (je *JSONEvaluator) SetState(payload sync.DataSync) (map[string]interface{}, bool, error) {
+	je.reloads.Lock()
+	defer je.reloads.Unlock()
+	started := time.Now()
 	var newFlags Flags
-	err := je.configToFlags(payload.FlagData, &newFlags)
+	err := je.configToFlags(payload.Source, payload.FlagData, &newFlags)
+	if err == nil && payload.Type != sync.DELETE {
+		err = je.store.CheckDuplicates(payload.Source, newFlags.Flags)
+	}
+	if err == nil && payload.Type != sync.DELETE {
+		err = je.checkDerivedCycles(payload.Source, newFlags.Flags, payload.Type == sync.ALL)
+	}
 	if err != nil {
+		je.rules.discard()
 		return nil, false, err
 	}
+	if je.flaps != nil && payload.Type != sync.DELETE && je.loaded.Load() {
+		je.dampFlaps(payload.Source, payload.Type, newFlags.Flags)
+	}
+	if je.pinned != nil && je.loaded.Load() {
+		je.pinned.mu.Lock()
+		defer je.pinned.mu.Unlock()
+		var ok bool
+		if newFlags.Flags, ok = je.pinFlags(payload.Source, payload.Type, newFlags.Flags); !ok {
+			// every flag the update deletes is pinned
+			je.rules.discard()
+			return map[string]interface{}{}, false, nil
+		}
+	}
+	warmup := time.Since(started)
 
+	var notifications map[string]interface{}
+	var resync bool
 	switch payload.Type {
 	case sync.ALL:
-		n, resync := je.store.Merge(je.Logger, payload.Source, newFlags.Flags)
-		return n, resync, nil
+		notifications, resync = je.store.Merge(je.Logger, payload.Source, newFlags.Flags)
 	case sync.ADD:
-		return je.store.Add(je.Logger, payload.Source, newFlags.Flags), false, nil
+		notifications = je.store.Add(je.Logger, payload.Source, newFlags.Flags)
 	case sync.UPDATE:
-		return je.store.Update(je.Logger, payload.Source, newFlags.Flags), false, nil
+		notifications = je.store.Update(je.Logger, payload.Source, newFlags.Flags)
 	case sync.DELETE:
-		return je.store.DeleteFlags(je.Logger, payload.Source, newFlags.Flags), true, nil
+		notifications, resync = je.store.DeleteFlags(je.Logger, payload.Source, newFlags.Flags), true
 	default:
+		je.rules.discard()
 		return nil, false, fmt.Errorf("unsupported sync type: %d", payload.Type)
 	}
+	je.swapWarmedRules(payload.Source, warmup)
+	je.orderTargeting()
+	je.loaded.Store(true)
+	return notifications, resync, nil
 }
 
-func resolve[T constraints](reqID string, key string, context *structpb.Struct,
-	variantEval func(string, string, *structpb.Struct) (string, string, error),
+func resolve[T constraints](ctx context.Context, reqID string, key string, context *structpb.Struct,
+	variantEval func(context.Context, string, string, *structpb.Struct) (string, string, map[string]interface{}, error),
 	variants map[string]any) (
 	value T,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
-	variant, reason, err = variantEval(reqID, key, context)
+	variant, reason, metadata, err = variantEval(ctx, reqID, key, context)
 	if err != nil {
-		return value, variant, reason, err
+		return value, variant, reason, metadata, err
 	}
 
 	var ok bool
 	value, ok = variants[variant].(T)
 	if !ok {
-		return value, variant, model.ErrorReason, errors.New(model.TypeMismatchErrorCode)
+		return value, variant, model.ErrorReason, metadata, &TypeMismatchError{
+			FlagKey:  key,
+			Variant:  variant,
+			Expected: valueKind(value),
+			Actual:   valueKind(variants[variant]),
+		}
 	}
 
-	return value, variant, reason, nil
+	return value, variant, reason, metadata, nil
 }
 
-func (je *JSONEvaluator) ResolveAllValues(reqID string, context *structpb.Struct) []AnyValue {
+func (je *JSONEvaluator) ResolveAllValues(ctx context.Context, reqID string, context *structpb.Struct) []AnyValue {
+	values, _ := je.ResolveAllValuesWithErrors(ctx, reqID, context)
+	return values
+}
+
+// ResolveAllValuesWithErrors resolves every flag, the flags which fail to resolve are reported by their error code.
+// The flags left once ctx is done aren't resolved.
+func (je *JSONEvaluator) ResolveAllValuesWithErrors(ctx context.Context, reqID string, context *structpb.Struct) (
+	[]AnyValue, []FlagError,
+) {
 	values := []AnyValue{}
+	var errs []FlagError
 	var value interface{}
 	var variant string
 	var reason string
+	var metadata map[string]interface{}
 	var err error
 	allFlags := je.store.GetAll()
 	for flagKey, flag := range allFlags {
+		if ctx.Err() != nil {
+			je.Logger.DebugWithID(reqID, fmt.Sprintf("bulk evaluation aborted: %v", ctx.Err()))
+			break
+		}
 		defaultValue := flag.Variants[flag.DefaultVariant]
 		switch defaultValue.(type) {
 		case bool:
-			value, variant, reason, err = resolve[bool](
+			value, variant, reason, metadata, err = resolve[bool](
+				ctx,
 				reqID,
 				flagKey,
 				context,
@@ -119,15 +293,22 @@ func (je *JSONEvaluator) ResolveAllValues(reqID string, context *structpb.Struct
 				allFlags[flagKey].Variants,
 			)
 		case string:
-			value, variant, reason, err = resolve[string](
+			var s string
+			s, variant, reason, metadata, err = resolve[string](
+				ctx,
 				reqID,
 				flagKey,
 				context,
 				je.evaluateVariant,
 				allFlags[flagKey].Variants,
 			)
+			if err == nil {
+				s, err = je.resolveTemplate(reqID, flagKey, flag, s, context)
+			}
+			value = s
 		case float64:
-			value, variant, reason, err = resolve[float64](
+			value, variant, reason, metadata, err = resolve[float64](
+				ctx,
 				reqID,
 				flagKey,
 				context,
@@ -135,181 +316,537 @@ func (je *JSONEvaluator) ResolveAllValues(reqID string, context *structpb.Struct
 				allFlags[flagKey].Variants,
 			)
 		case map[string]any:
-			value, variant, reason, err = resolve[map[string]any](
+			var object map[string]any
+			object, variant, reason, metadata, err = resolve[map[string]any](
+				ctx,
 				reqID,
 				flagKey,
 				context,
 				je.evaluateVariant,
 				allFlags[flagKey].Variants,
 			)
+			value = maskFields(flag, object)
 		}
 		if err != nil {
 			je.Logger.ErrorWithID(reqID, fmt.Sprintf("bulk evaluation: key: %s returned error: %s", flagKey, err.Error()))
+			errs = append(errs, FlagError{FlagKey: flagKey, ErrorCode: err.Error()})
 			continue
 		}
-		values = append(values, NewAnyValue(value, variant, reason, flagKey))
+		values = append(values, NewAnyValue(value, variant, reason, flagKey, metadata))
 	}
-	return values
+	sortFlagErrors(errs)
+	return values, errs
 }
 
-func (je *JSONEvaluator) ResolveBooleanValue(reqID string, flagKey string, context *structpb.Struct) (
+func (je *JSONEvaluator) ResolveBooleanValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (
 	value bool,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating boolean flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
 	flag, _ := je.store.Get(flagKey)
-	return resolve[bool](reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	value, variant, reason, metadata, err = resolve[bool](ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	return fallback(je, reqID, flagKey, context, value, variant, reason, metadata, err)
 }
 
-func (je *JSONEvaluator) ResolveStringValue(reqID string, flagKey string, context *structpb.Struct) (
+func (je *JSONEvaluator) ResolveStringValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (
 	value string,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating string flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
 	flag, _ := je.store.Get(flagKey)
-	return resolve[string](reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	value, variant, reason, metadata, err = resolve[string](
+		ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	if err != nil {
+		return fallback(je, reqID, flagKey, context, value, variant, reason, metadata, err)
+	}
+	if value, err = je.resolveTemplate(reqID, flagKey, flag, value, context); err != nil {
+		return fallback(je, reqID, flagKey, context, "", variant, model.ErrorReason, metadata, err)
+	}
+	return value, variant, reason, metadata, nil
 }
 
-func (je *JSONEvaluator) ResolveFloatValue(reqID string, flagKey string, context *structpb.Struct) (
+func (je *JSONEvaluator) ResolveFloatValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (
 	value float64,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating float flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
 	flag, _ := je.store.Get(flagKey)
-	value, variant, reason, err = resolve[float64](
-		reqID, flagKey, context, je.evaluateVariant, flag.Variants)
-	return
+	value, variant, reason, metadata, err = resolve[float64](
+		ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	return fallback(je, reqID, flagKey, context, value, variant, reason, metadata, err)
 }
 
-func (je *JSONEvaluator) ResolveIntValue(reqID string, flagKey string, context *structpb.Struct) (
+func (je *JSONEvaluator) ResolveIntValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (
 	value int64,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating int flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
 	flag, _ := je.store.Get(flagKey)
 	var val float64
-	val, variant, reason, err = resolve[float64](
-		reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	val, variant, reason, metadata, err = resolve[float64](
+		ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	val, variant, reason, metadata, err = fallback(je, reqID, flagKey, context, val, variant, reason, metadata, err)
 	value = int64(val)
 	return
 }
 
-func (je *JSONEvaluator) ResolveObjectValue(reqID string, flagKey string, context *structpb.Struct) (
+func (je *JSONEvaluator) ResolveObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (
 	value map[string]any,
 	variant string,
 	reason string,
+	metadata map[string]interface{},
 	err error,
 ) {
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating object flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
 	flag, _ := je.store.Get(flagKey)
-	return resolve[map[string]any](reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	value, variant, reason, metadata, err = resolve[map[string]any](
+		ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	value, variant, reason, metadata, err = fallback(je, reqID, flagKey, context, value, variant, reason, metadata, err)
+	return maskFields(flag, value), variant, reason, metadata, err
 }
 
 // runs the rules (if defined) to determine the variant, otherwise falling through to the default
 func (je *JSONEvaluator) evaluateVariant(
+	ctx context.Context,
 	reqID string,
 	flagKey string,
 	context *structpb.Struct,
-) (variant string, reason string, err error) {
+) (variant string, reason string, metadata map[string]interface{}, err error) {
+	variant, reason, metadata, err = je.evaluateFlag(ctx, reqID, flagKey, context, nil, nil)
+	if err != nil {
+		return variant, reason, metadata, err
+	}
+	if err := je.skipFlagDefault(reqID, flagKey, reason); err != nil {
+		return "", model.ErrorReason, nil, err
+	}
+	if je.contextKeysMetadata {
+		metadata = je.withContextKeys(flagKey, metadata)
+	}
+	if je.evaluationHash {
+		metadata = je.withEvaluationHash(flagKey, variant, reason, metadata, context)
+	}
+	return variant, reason, metadata, nil
+}
+
+// evaluateFlag determines the variant of a flag, path holds the derived flags depending on it being evaluated. The
+// error of ctx is returned once it's done, as nobody waits for the variant anymore. The resolutions of prerequisite
+// flags are recorded to chain, if set.
+func (je *JSONEvaluator) evaluateFlag(
+	ctx context.Context,
+	reqID string,
+	flagKey string,
+	context *structpb.Struct,
+	path []string,
+	chain *resolutionChain,
+) (variant string, reason string, metadata map[string]interface{}, err error) {
+	if err := ctx.Err(); err != nil {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluation of flag: %s aborted: %v", flagKey, err))
+		return "", model.ErrorReason, nil, err
+	}
 	flag, ok := je.store.Get(flagKey)
 	if !ok {
+		if !je.loaded.Load() {
+			// the flag may exist in the sources, which haven't been synced yet
+			je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag before the initial sync: %s", flagKey))
+			return "", model.ErrorReason, nil, errors.New(model.ProviderNotReadyErrorCode)
+		}
+		if je.notFoundGrace != nil && je.notFoundGrace.pending(flagKey, je.clock.Now()) {
+			// the flag may be defined by a sync catching up
+			je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag within its not found grace period: %s", flagKey))
+			return "", model.ErrorReason, nil, errors.New(model.ProviderNotReadyErrorCode)
+		}
 		// flag not found
 		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag could not be found: %s", flagKey))
-		return "", model.ErrorReason, errors.New(model.FlagNotFoundErrorCode)
+		return "", model.ErrorReason, nil, errors.New(model.FlagNotFoundErrorCode)
 	}
 
 	if flag.State == Disabled {
 		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag is disabled: %s", flagKey))
-		return "", model.ErrorReason, errors.New(model.FlagDisabledErrorCode)
+		return "", model.ErrorReason, nil, errors.New(model.FlagDisabledErrorCode)
 	}
 
-	// get the targeting logic, if any
-	targeting := flag.Targeting
+	if variant, ok := je.pinnedVariant(reqID, flagKey, flag, context); ok {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("flag: %s is pinned to variant: %s by the override token",
+			flagKey, variant))
+		return variant, model.OverrideReason, resolutionMetadata(flag, nil), nil
+	}
 
-	if targeting != nil && string(targeting) != "{}" {
-		targetingBytes, err := targeting.MarshalJSON()
-		if err != nil {
-			je.Logger.ErrorWithID(reqID, fmt.Sprintf("Error parsing rules for flag: %s, %s", flagKey, err))
-			return "", model.ErrorReason, err
-		}
+	// prerequisites are evaluated against the request context, with their own base context
+	request := context
+	context = withBaseContext(flag, context)
+	if missing := je.missingContextKeys(flag, context); len(missing) > 0 {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag: %s is missing required context keys: %s",
+			flagKey, strings.Join(missing, ", ")))
+		return "", model.ErrorReason, nil, &MissingContextError{Keys: missing}
+	}
 
-		b, err := json.Marshal(context)
-		if err != nil {
-			je.Logger.ErrorWithID(reqID, fmt.Sprintf("error parsing context for flag: %s, %s, %v", flagKey, err, context))
+	if flag.Derived != nil {
+		return je.evaluateDerived(ctx, reqID, flagKey, flag, request, path, chain)
+	}
+
+	// get the targeting logic, if any, selected by the context for flags with rulesets
+	targeting, ruleKey := je.selectTargeting(flagKey, flag, context)
 
-			return "", model.ErrorReason, errors.New(model.ErrorReason)
+	if targeting != nil && string(targeting) != "{}" {
+		if !je.fallsBackTo(FallbackTargeting) {
+			// the targeting is skipped by the fallback chain, the default variant still depends on the context
+			return je.defaultVariant(flag, context), model.DefaultReason, resolutionMetadata(flag, nil), nil
 		}
-		var result bytes.Buffer
-		// evaluate json-logic rules to determine the variant
-		err = jsonlogic.Apply(bytes.NewReader(targetingBytes), bytes.NewReader(b), &result)
-		if err != nil {
-			je.Logger.ErrorWithID(reqID, fmt.Sprintf("error applying rules: %s", err))
-			return "", model.ErrorReason, err
+		flag.Targeting = targeting
+		evaluate := je.evaluateTargeting
+		if je.circuitBreakers != nil {
+			evaluate = je.evaluateTargetingWithBreaker
 		}
-		// strip whitespace and quotes from the variant
-		variant = strings.ReplaceAll(strings.TrimSpace(result.String()), "\"", "")
-
-		// if this is a valid variant, return it
-		if _, ok := flag.Variants[variant]; ok {
-			return variant, model.TargetingMatchReason, nil
+		if flag.LastGoodStaleness != nil {
+			return je.evaluateTargetingWithLastGood(reqID, flagKey, ruleKey, flag, context, evaluate)
 		}
-
-		je.Logger.DebugWithID(reqID, fmt.Sprintf("returning default variant for flagKey: %s, variant is not valid", flagKey))
-		reason = model.DefaultReason
-	} else {
-		reason = model.StaticReason
+		return evaluate(reqID, flagKey, ruleKey, flag, context)
 	}
 
-	return flag.DefaultVariant, reason, nil
+	return je.defaultVariant(flag, context), defaultReason(flag), resolutionMetadata(flag, nil), nil
 }
 
-// configToFlags convert string configurations to flags and store them to pointer newFlags
-func (je *JSONEvaluator) configToFlags(config string, newFlags *Flags) error {
-	schemaLoader := gojsonschema.NewStringLoader(schema.FlagdDefinitions)
-	flagStringLoader := gojsonschema.NewStringLoader(config)
+// evaluateTargeting determines the variant of a flag by its targeting rule, cached under ruleKey, falling back to its
+// default variant if the rule doesn't return a valid variant
+func (je *JSONEvaluator) evaluateTargeting(
+	reqID string,
+	flagKey string,
+	ruleKey string,
+	flag model.Flag,
+	context *structpb.Struct,
+) (string, string, map[string]interface{}, error) {
+	targeting := flag.Targeting
+	rule, err := je.boundTargetingRule(ruleKey, targeting)
+	if err != nil {
+		je.Logger.ErrorWithID(reqID, fmt.Sprintf("Error parsing rules for flag: %s, %s", flagKey, err))
+		return "", model.ErrorReason, nil, err
+	}
 
-	result, err := gojsonschema.Validate(schemaLoader, flagStringLoader)
+	// evaluate json-logic rules to determine the variant
+	data := je.targetingData(flag, context.AsMap())
+	var result interface{}
+	if ordered := je.orderedTargetingOf(ruleKey, targeting); ordered != nil {
+		result, err = ordered.apply(data)
+	} else {
+		result, err = jsonlogic.ApplyInterface(rule, data)
+	}
 	if err != nil {
-		return err
-	} else if !result.Valid() {
-		return fmt.Errorf("JSON schema validation failed: %s", buildErrorString(result.Errors()))
+		je.Logger.ErrorWithID(reqID, fmt.Sprintf("error applying rules: %s", err))
+		return "", model.ErrorReason, nil, err
+	}
+	if je.ruleStatistics != nil {
+		je.recordRuleStatistics(ruleKey, targeting, rule, data)
+	}
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("targeting of flag: %s resolved: %v", flagKey, result),
+		zap.ByteString("targeting", targeting))
+	variant, metadata := parseTargetingResult(result)
+
+	// if this is a valid variant, return it
+	if _, ok := flag.Variants[variant]; ok {
+		metadata = withExperiment(flag, variant, metadata)
+		return variant, model.TargetingMatchReason, resolutionMetadata(flag, metadata), nil
+	}
+
+	if err := je.resolvedUndefinedVariant(flagKey, variant, metadata); err != nil {
+		je.Logger.ErrorWithID(reqID, err.Error())
+		return "", model.ErrorReason, nil, err
+	}
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("returning default variant for flagKey: %s, variant: '%s' is not valid",
+		flagKey, variant))
+	return je.defaultVariant(flag, context), model.DefaultReason, resolutionMetadata(flag, nil), nil
+}
+
+// parseTargetingResult extracts the variant from the json-logic result. Operators which annotate their result
+// (e.g. rule, fractionalEvaluation) produce an object holding the variant alongside the evaluation metadata.
+func parseTargetingResult(result interface{}) (string, map[string]interface{}) {
+	switch r := result.(type) {
+	case string:
+		return r, nil
+	case map[string]interface{}:
+		variant, _ := r[targetingVariantKey].(string)
+		var metadata map[string]interface{}
+		for k, v := range r {
+			if k == targetingVariantKey {
+				continue
+			}
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+			metadata[k] = v
+		}
+		return variant, metadata
+	default:
+		// non string results are matched against the variants by their json representation
+		b, err := json.Marshal(r)
+		if err != nil {
+			return "", nil
+		}
+		return strings.ReplaceAll(strings.TrimSpace(string(b)), "\"", ""), nil
 	}
+}
 
+// configToFlags convert string configurations to flags and store them to pointer newFlags
+func (je *JSONEvaluator) configToFlags(source string, config string, newFlags *Flags) error {
 	transposedConfig, err := je.transposeEvaluators(config)
 	if err != nil {
 		return fmt.Errorf("transposing evaluators: %w", err)
 	}
 
-	err = json.Unmarshal([]byte(transposedConfig), &newFlags)
+	var raw rawFlags
+	err = json.Unmarshal([]byte(transposedConfig), &raw)
 	if err != nil {
 		return fmt.Errorf("unmarshalling provided configurations: %w", err)
 	}
-	if err := validateDefaultVariants(newFlags); err != nil {
+
+	definitions, err := je.resolveDuplicateKeys(source, raw.Flags)
+	if err != nil {
+		return err
+	}
+	if definitions, err = je.checkFlagKeys(definitions); err != nil {
+		return err
+	}
+	definitions = je.prefixFlagKeys(source, definitions)
+	if definitions, err = resolveBaseContexts(raw.BaseContexts, definitions); err != nil {
 		return err
 	}
+	flags, err := je.validateFlags(definitions)
+	if err != nil {
+		return err
+	}
+	newFlags.Flags = flags
 
 	return nil
 }
 
-// validateDefaultVariants returns an error if any of the default variants aren't valid
-func validateDefaultVariants(flags *Flags) error {
-	for name, flag := range flags.Flags {
-		if _, ok := flag.Variants[flag.DefaultVariant]; !ok {
-			return fmt.Errorf(
-				"default variant: '%s' isn't a valid variant of flag: '%s'", flag.DefaultVariant, name,
-			)
+// validateFlags validates each flag against the flag schema, checks its default variant and warms the targeting rule
+// cache. Flags are processed concurrently by the configured number of workers, errors are aggregated.
+func (je *JSONEvaluator) validateFlags(rawFlags map[string]json.RawMessage) (map[string]model.Flag, error) {
+	flagSchema, err := compiledFlagSchema()
+	if err != nil {
+		return nil, fmt.Errorf("compiling flag schema: %w", err)
+	}
+
+	type validationResult struct {
+		key  string
+		flag model.Flag
+		err  error
+	}
+	keys := make(chan string)
+	results := make(chan validationResult)
+
+	workers := je.validationWorkers
+	if workers <= 0 {
+		workers = goruntime.GOMAXPROCS(0)
+	}
+	if workers > len(rawFlags) {
+		workers = len(rawFlags)
+	}
+	var wg gosync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				flag, err := je.validateFlag(flagSchema, key, rawFlags[key])
+				results <- validationResult{key: key, flag: flag, err: err}
+			}
+		}()
+	}
+	go func() {
+		for key := range rawFlags {
+			keys <- key
+		}
+		close(keys)
+		wg.Wait()
+		close(results)
+	}()
+
+	flags := make(map[string]model.Flag, len(rawFlags))
+	var errs []string
+	for result := range results {
+		var tooManyVariants *tooManyVariantsError
+		if errors.As(result.err, &tooManyVariants) {
+			je.Logger.Error(fmt.Sprintf("rejecting %s, the other flags are loaded", tooManyVariants.Error()))
+			continue
+		}
+		var noVariants *noVariantsError
+		if errors.As(result.err, &noVariants) {
+			je.Logger.Error(fmt.Sprintf("rejecting %s, the other flags are loaded", noVariants.Error()))
+			continue
+		}
+		if result.err != nil {
+			errs = append(errs, result.err.Error())
+			continue
 		}
+		flags[result.key] = result.flag
+	}
+	if len(errs) != 0 {
+		sort.Strings(errs)
+		return nil, errors.New(strings.Join(errs, "; "))
 	}
 
-	return nil
+	return flags, nil
+}
+
+func (je *JSONEvaluator) validateFlag(flagSchema *gojsonschema.Schema, key string, raw json.RawMessage) (
+	model.Flag, error,
+) {
+	return je.validateFlagDefinition(flagSchema, key, raw, je.warmRule)
+}
+
+// validateFlagDefinition validates a flag, parsing its targeting rule through parseRule
+func (je *JSONEvaluator) validateFlagDefinition(
+	flagSchema *gojsonschema.Schema,
+	key string,
+	raw json.RawMessage,
+	parseRule func(flagKey string, targeting json.RawMessage) (interface{}, error),
+) (model.Flag, error) {
+	var flag model.Flag
+	if err := je.validateVariantCount(key, raw); err != nil {
+		return flag, err
+	}
+	if je.defaultVariantFallback {
+		raw = je.applyDefaultVariantFallback(key, raw)
+	}
+	raw, err := je.checkVariantTypes(key, raw)
+	if err != nil {
+		return flag, err
+	}
+	if err := checkArrayVariants(key, raw); err != nil {
+		return flag, err
+	}
+	result, err := flagSchema.Validate(gojsonschema.NewGoLoader(map[string]interface{}{
+		"flags": map[string]json.RawMessage{key: raw},
+	}))
+	if err != nil {
+		return flag, fmt.Errorf("flag: '%s': %w", key, err)
+	} else if !result.Valid() {
+		return flag, fmt.Errorf("JSON schema validation failed: %s", buildErrorString(result.Errors()))
+	}
+
+	if err := json.Unmarshal(raw, &flag); err != nil {
+		return flag, fmt.Errorf("unmarshalling flag: '%s': %w", key, err)
+	}
+	if err := je.convertLargeIntegers(key, raw, &flag); err != nil {
+		return flag, err
+	}
+	if _, ok := flag.Variants[flag.DefaultVariant]; !ok {
+		return flag, fmt.Errorf(
+			"default variant: '%s' isn't a valid variant of flag: '%s'", flag.DefaultVariant, key,
+		)
+	}
+	if err := validateDefaultVariantByContext(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateFractionalRandomization(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateRequireContext(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateMaskedFields(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateTraceSampling(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateLastGoodStaleness(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateTemplate(key, flag); err != nil {
+		return flag, err
+	}
+	if err := validateFlagMetadata(flag.Metadata); err != nil {
+		return flag, fmt.Errorf("flag: '%s': %w", key, err)
+	}
+	if err := je.validateVariantSchema(key, flag); err != nil {
+		return flag, err
+	}
+	je.warnAmbiguousVariants(key, flag)
+	if flag.Derived != nil {
+		if err := validateDerived(key, flag); err != nil {
+			return flag, err
+		}
+	}
+	var rule interface{}
+	if flag.Targeting != nil && string(flag.Targeting) != "{}" {
+		if rule, err = je.validateTargeting(key, key, flag, flag.Targeting, parseRule); err != nil {
+			return flag, err
+		}
+	}
+	if err := validateTargetingOrder(key, flag, rule); err != nil {
+		return flag, err
+	}
+	rulesets, err := je.validateRulesets(key, flag, parseRule)
+	if err != nil {
+		return flag, err
+	}
+	if len(rulesets) != 0 {
+		rule = append(rulesets, rule)
+	}
+	if flag.CacheTTL, err = je.validateCacheTTL(key, flag.CacheTTL, rule); err != nil {
+		return flag, err
+	}
+
+	return flag, nil
+}
+
+// validateTargeting parses a targeting rule of a flag through parseRule, caching it under ruleKey, and checks its
+// operators, variants and patterns
+func (je *JSONEvaluator) validateTargeting(
+	key string,
+	ruleKey string,
+	flag model.Flag,
+	targeting json.RawMessage,
+	parseRule func(flagKey string, targeting json.RawMessage) (interface{}, error),
+) (interface{}, error) {
+	rule, err := parseRule(ruleKey, targeting)
+	if err != nil {
+		return nil, fmt.Errorf("parsing targeting of flag: '%s': %w", key, err)
+	}
+	if err := validateOperators(rule); err != nil {
+		return nil, fmt.Errorf("targeting of flag: '%s': %w", key, err)
+	}
+	if err := je.validateContextKeys(rule); err != nil {
+		return nil, fmt.Errorf("targeting of flag: '%s': %w", key, err)
+	}
+	if err := je.validateVariantReferences(key, flag, rule); err != nil {
+		return nil, err
+	}
+	if err := je.validateRegexPatterns(rule); err != nil {
+		return nil, fmt.Errorf("targeting of flag: '%s': %w", key, err)
+	}
+	if err := je.validateFractionalPercentages(rule, "targeting"); err != nil {
+		return nil, fmt.Errorf("targeting of flag: '%s': %w", key, err)
+	}
+	return rule, nil
 }
 
 func (je *JSONEvaluator) transposeEvaluators(state string) (string, error) {
@@ -355,3 +892,11 @@ func buildErrorString(errors []gojsonschema.ResultError) string {
 
 	return builder.String()
 }
+
+// compiledFlagSchema compiles the flagd flag definitions schema once, the compiled schema is safe for concurrent use
+func compiledFlagSchema() (*gojsonschema.Schema, error) {
+	flagSchemaOnce.Do(func() {
+		flagSchema, flagSchemaErr = gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema.FlagdDefinitions))
+	})
+	return flagSchema, flagSchemaErr
+}
@@ -13,3 +13,10 @@ type Evaluators struct {
 type Flags struct {
 	Flags map[string]model.Flag `json:"flags"`
 }
+
+// rawFlags holds the flag configurations prior to validation
+type rawFlags struct {
+	Flags flagDefinitions `json:"flags"`
+	// BaseContexts are the base contexts flags may reference by name, keyed by name
+	BaseContexts map[string]json.RawMessage `json:"$baseContexts"`
+}
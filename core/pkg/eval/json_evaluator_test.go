@@ -1,10 +1,12 @@
 package eval_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
+	gosync "sync"
 	"testing"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -338,23 +342,23 @@ func TestResolveAllValues(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		vals := evaluator.ResolveAllValues(reqID, apStruct)
+		vals := evaluator.ResolveAllValues(context.Background(), reqID, apStruct)
 		for _, val := range vals {
 			switch vT := val.Value.(type) {
 			case bool:
-				v, _, reason, _ := evaluator.ResolveBooleanValue(reqID, val.FlagKey, apStruct)
+				v, _, reason, _, _ := evaluator.ResolveBooleanValue(context.Background(), reqID, val.FlagKey, apStruct)
 				assert.Equal(t, v, vT)
 				assert.Equal(t, val.Reason, reason)
 			case string:
-				v, _, reason, _ := evaluator.ResolveStringValue(reqID, val.FlagKey, apStruct)
+				v, _, reason, _, _ := evaluator.ResolveStringValue(context.Background(), reqID, val.FlagKey, apStruct)
 				assert.Equal(t, v, vT)
 				assert.Equal(t, val.Reason, reason)
 			case float64:
-				v, _, reason, _ := evaluator.ResolveFloatValue(reqID, val.FlagKey, apStruct)
+				v, _, reason, _, _ := evaluator.ResolveFloatValue(context.Background(), reqID, val.FlagKey, apStruct)
 				assert.Equal(t, v, vT)
 				assert.Equal(t, val.Reason, reason)
 			case interface{}:
-				v, _, reason, _ := evaluator.ResolveObjectValue(reqID, val.FlagKey, apStruct)
+				v, _, reason, _, _ := evaluator.ResolveObjectValue(context.Background(), reqID, val.FlagKey, apStruct)
 				assert.Equal(t, v, vT)
 				assert.Equal(t, val.Reason, reason)
 			}
@@ -388,7 +392,7 @@ func TestResolveBooleanValue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		val, _, reason, err := evaluator.ResolveBooleanValue(reqID, test.flagKey, apStruct)
+		val, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), reqID, test.flagKey, apStruct)
 		if test.errorCode == "" {
 			if assert.NoError(t, err) {
 				assert.Equal(t, test.val, val)
@@ -429,7 +433,7 @@ func BenchmarkResolveBooleanValue(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("test %s", test.flagKey), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				val, _, reason, err := evaluator.ResolveBooleanValue(reqID, test.flagKey, apStruct)
+				val, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), reqID, test.flagKey, apStruct)
 
 				if test.errorCode == "" {
 					if assert.NoError(b, err) {
@@ -471,7 +475,7 @@ func TestResolveStringValue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		val, _, reason, err := evaluator.ResolveStringValue(reqID, test.flagKey, apStruct)
+		val, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), reqID, test.flagKey, apStruct)
 
 		if test.errorCode == "" {
 			if assert.NoError(t, err) {
@@ -513,7 +517,7 @@ func BenchmarkResolveStringValue(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("test %s", test.flagKey), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				val, _, reason, err := evaluator.ResolveStringValue(reqID, test.flagKey, apStruct)
+				val, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), reqID, test.flagKey, apStruct)
 
 				if test.errorCode == "" {
 					if assert.NoError(b, err) {
@@ -555,7 +559,7 @@ func TestResolveFloatValue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		val, _, reason, err := evaluator.ResolveFloatValue(reqID, test.flagKey, apStruct)
+		val, _, reason, _, err := evaluator.ResolveFloatValue(context.Background(), reqID, test.flagKey, apStruct)
 
 		if test.errorCode == "" {
 			if assert.NoError(t, err) {
@@ -597,7 +601,7 @@ func BenchmarkResolveFloatValue(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("test: %s", test.flagKey), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				val, _, reason, err := evaluator.ResolveFloatValue(reqID, test.flagKey, apStruct)
+				val, _, reason, _, err := evaluator.ResolveFloatValue(context.Background(), reqID, test.flagKey, apStruct)
 
 				if test.errorCode == "" {
 					if assert.NoError(b, err) {
@@ -639,7 +643,7 @@ func TestResolveIntValue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		val, _, reason, err := evaluator.ResolveIntValue(reqID, test.flagKey, apStruct)
+		val, _, reason, _, err := evaluator.ResolveIntValue(context.Background(), reqID, test.flagKey, apStruct)
 
 		if test.errorCode == "" {
 			if assert.NoError(t, err) {
@@ -681,7 +685,7 @@ func BenchmarkResolveIntValue(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("test %s", test.flagKey), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				val, _, reason, err := evaluator.ResolveIntValue(reqID, test.flagKey, apStruct)
+				val, _, reason, _, err := evaluator.ResolveIntValue(context.Background(), reqID, test.flagKey, apStruct)
 
 				if test.errorCode == "" {
 					if assert.NoError(b, err) {
@@ -723,7 +727,7 @@ func TestResolveObjectValue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		val, _, reason, err := evaluator.ResolveObjectValue(reqID, test.flagKey, apStruct)
+		val, _, reason, _, err := evaluator.ResolveObjectValue(context.Background(), reqID, test.flagKey, apStruct)
 
 		if test.errorCode == "" {
 			if assert.NoError(t, err) {
@@ -768,7 +772,7 @@ func BenchmarkResolveObjectValue(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("test %s", test.flagKey), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				val, _, reason, err := evaluator.ResolveObjectValue(reqID, test.flagKey, apStruct)
+				val, _, reason, _, err := evaluator.ResolveObjectValue(context.Background(), reqID, test.flagKey, apStruct)
 
 				if test.errorCode == "" {
 					if assert.NoError(b, err) {
@@ -1122,56 +1126,56 @@ func TestFlagStateSafeForConcurrentReadWrites(t *testing.T) {
 		"Add_ResolveAllValues": {
 			dataSyncType: sync.ADD,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				evaluator.ResolveAllValues("", nil)
+				evaluator.ResolveAllValues(context.Background(), "", nil)
 				return nil
 			},
 		},
 		"Update_ResolveAllValues": {
 			dataSyncType: sync.UPDATE,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				evaluator.ResolveAllValues("", nil)
+				evaluator.ResolveAllValues(context.Background(), "", nil)
 				return nil
 			},
 		},
 		"Delete_ResolveAllValues": {
 			dataSyncType: sync.DELETE,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				evaluator.ResolveAllValues("", nil)
+				evaluator.ResolveAllValues(context.Background(), "", nil)
 				return nil
 			},
 		},
 		"Add_ResolveBooleanValue": {
 			dataSyncType: sync.ADD,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				_, _, _, err := evaluator.ResolveBooleanValue("", StaticBoolFlag, nil)
+				_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", StaticBoolFlag, nil)
 				return err
 			},
 		},
 		"Update_ResolveStringValue": {
 			dataSyncType: sync.UPDATE,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				_, _, _, err := evaluator.ResolveBooleanValue("", StaticStringValue, nil)
+				_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", StaticStringValue, nil)
 				return err
 			},
 		},
 		"Delete_ResolveIntValue": {
 			dataSyncType: sync.DELETE,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				_, _, _, err := evaluator.ResolveIntValue("", StaticIntFlag, nil)
+				_, _, _, _, err := evaluator.ResolveIntValue(context.Background(), "", StaticIntFlag, nil)
 				return err
 			},
 		},
 		"Add_ResolveFloatValue": {
 			dataSyncType: sync.ADD,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				_, _, _, err := evaluator.ResolveFloatValue("", StaticFloatFlag, nil)
+				_, _, _, _, err := evaluator.ResolveFloatValue(context.Background(), "", StaticFloatFlag, nil)
 				return err
 			},
 		},
 		"Update_ResolveObjectValue": {
 			dataSyncType: sync.UPDATE,
 			flagResolution: func(evaluator *eval.JSONEvaluator) error {
-				_, _, _, err := evaluator.ResolveObjectValue("", StaticObjectFlag, nil)
+				_, _, _, _, err := evaluator.ResolveObjectValue(context.Background(), "", StaticObjectFlag, nil)
 				return err
 			},
 		},
@@ -1241,3 +1245,857 @@ func TestFlagStateSafeForConcurrentReadWrites(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleIDMetadata(t *testing.T) {
+	const flagConfig = `{
+  "flags": {
+    "tierFlag": {
+      "state": "ENABLED",
+      "variants": {
+        "gold": "gold",
+        "silver": "silver",
+        "bronze": "bronze"
+      },
+      "defaultVariant": "bronze",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "tier" }, "premium"] },
+          { "rule": ["premium-users", "gold"] },
+          { "==": [{ "var": "tier" }, "beta"] },
+          { "rule": ["beta-users", { "fractionalEvaluation": ["email", ["gold", 50], ["silver", 50]] }] },
+          { "in": ["@faas.com", { "var": "email" }] },
+          "silver",
+          null
+        ]
+      }
+    }
+  }
+}`
+	tests := map[string]struct {
+		context          map[string]interface{}
+		expectedVariant  string
+		expectedReason   string
+		expectedMetadata map[string]interface{}
+	}{
+		"rule id of a matched branch": {
+			context:         map[string]interface{}{"tier": "premium"},
+			expectedVariant: "gold",
+			expectedReason:  model.TargetingMatchReason,
+			expectedMetadata: map[string]interface{}{
+				eval.RuleIDMetadataKey: "premium-users",
+			},
+		},
+		"rule id and bucket of a matched fractional branch": {
+			context:         map[string]interface{}{"tier": "beta", "email": "test@faas.com"},
+			expectedVariant: "gold",
+			expectedReason:  model.TargetingMatchReason,
+			expectedMetadata: map[string]interface{}{
+				eval.RuleIDMetadataKey: "beta-users",
+				eval.BucketMetadataKey: 16,
+			},
+		},
+		"branch without a rule id": {
+			context:         map[string]interface{}{"email": "test@faas.com"},
+			expectedVariant: "silver",
+			expectedReason:  model.TargetingMatchReason,
+		},
+		"no matching branch": {
+			context:         map[string]interface{}{},
+			expectedVariant: "bronze",
+			expectedReason:  model.DefaultReason,
+		},
+	}
+
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: flagConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := structpb.NewStruct(tt.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, variant, reason, metadata, err := evaluator.ResolveStringValue(context.Background(), "", "tierFlag", ctx)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedVariant, variant)
+				assert.Equal(t, tt.expectedReason, reason)
+				assert.Equal(t, tt.expectedMetadata, metadata)
+			}
+		})
+	}
+}
+
+func TestSetState_ValidationErrorsAggregated(t *testing.T) {
+	const flagConfig = `{
+  "flags": {
+    "validFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "invalidDefaultVariant": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "unknown"
+    },
+    "invalidState": {
+      "state": "UNKNOWN",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			evaluator := eval.NewJSONEvaluator(
+				logger.NewLogger(nil, false), store.NewFlags(), eval.WithValidationWorkers(workers),
+			)
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: flagConfig})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			assert.Contains(t, err.Error(), "default variant: 'unknown' isn't a valid variant of flag: 'invalidDefaultVariant'")
+			assert.Contains(t, err.Error(), "JSON schema validation failed")
+
+			_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "validFlag", nil)
+			assert.EqualError(t, err, model.ProviderNotReadyErrorCode, "no flags should be stored from an invalid config")
+		})
+	}
+}
+
+func largeFlagConfig(n int) string {
+	flags := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		flags[fmt.Sprintf("flag-%d", i)] = map[string]interface{}{
+			"state":          "ENABLED",
+			"defaultVariant": "off",
+			"variants":       map[string]interface{}{"on": true, "off": false},
+			"targeting": map[string]interface{}{
+				"if": []interface{}{
+					map[string]interface{}{"in": []interface{}{"@faas.com", map[string]interface{}{"var": "email"}}},
+					"on",
+					nil,
+				},
+			},
+		}
+	}
+	b, _ := json.Marshal(map[string]interface{}{"flags": flags})
+	return string(b)
+}
+
+func TestSetState_LargeConfigConcurrentWarmup(t *testing.T) {
+	config := largeFlagConfig(500)
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(), eval.WithValidationWorkers(8))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := structpb.NewStruct(map[string]interface{}{"email": "test@faas.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		val, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", fmt.Sprintf("flag-%d", i), ctx)
+		if assert.NoError(t, err) {
+			assert.True(t, val)
+			assert.Equal(t, model.TargetingMatchReason, reason)
+		}
+	}
+}
+
+func BenchmarkSetState_LargeConfig(b *testing.B) {
+	config := largeFlagConfig(2000)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				evaluator := eval.NewJSONEvaluator(
+					logger.NewLogger(nil, false), store.NewFlags(), eval.WithValidationWorkers(workers),
+				)
+				if _, _, err := evaluator.SetState(sync.DataSync{FlagData: config}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve_NilContext(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "bool": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null] }
+    },
+    "fractional": {
+      "state": "ENABLED", "variants": { "a": "a", "b": "b" }, "defaultVariant": "a",
+      "targeting": { "fractionalEvaluation": ["email", ["a", 50], ["b", 50]] }
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ctx := range []*structpb.Struct{nil, {}} {
+		value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "bool", ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, false, value)
+		assert.Equal(t, "off", variant)
+		assert.Equal(t, model.DefaultReason, reason)
+
+		str, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "fractional", ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "a", str)
+		assert.Equal(t, model.DefaultReason, reason)
+
+		for _, v := range evaluator.ResolveAllValues(context.Background(), "", ctx) {
+			assert.Equal(t, model.DefaultReason, v.Reason, v.FlagKey)
+		}
+	}
+}
+
+func TestDefaultVariantFallback(t *testing.T) {
+	tests := map[string]string{
+		"missing default variant": `{
+  "flags": {
+    "colorFlag": {
+      "state": "ENABLED",
+      "variants": { "red": "c05543", "green": "2f5230" },
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "green", null] }
+    }
+  }
+}`,
+		"invalid default variant": `{
+  "flags": {
+    "colorFlag": {
+      "state": "ENABLED",
+      "variants": { "red": "c05543", "green": "2f5230" },
+      "defaultVariant": "purple",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "green", null] }
+    }
+  }
+}`,
+	}
+	for name, config := range tests {
+		t.Run(name+" strict", func(t *testing.T) {
+			_, err := eval.NewJSONEvaluatorFromConfig(nil, config)
+			assert.NotNil(t, err)
+		})
+
+		t.Run(name+" fallback", func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(
+				logger.NewLogger(zap.New(core), false), config, eval.WithDefaultVariantFallback(true),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 1, logs.FilterMessage(
+				"flag: 'colorFlag' has no valid default variant, falling back to its first variant: 'red'",
+			).Len())
+
+			value, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "colorFlag", &structpb.Struct{})
+			assert.Nil(t, err)
+			assert.Equal(t, "c05543", value)
+			assert.Equal(t, "red", variant)
+			assert.Equal(t, model.DefaultReason, reason)
+		})
+	}
+
+	t.Run("fallback without variants", func(t *testing.T) {
+		evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": { "emptyFlag": { "state": "ENABLED", "variants": {} } }
+}`, eval.WithDefaultVariantFallback(true))
+		assert.Nil(t, err)
+		_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "", "emptyFlag", &structpb.Struct{})
+		assert.EqualError(t, err, model.FlagNotFoundErrorCode, "flags without variants are rejected")
+	})
+}
+
+func TestFlagMetadata(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "metadataFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "metadata": { "owner": "checkout", "version": 3, "experimental": true },
+      "targeting": {
+        "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, { "rule": ["beta", "on"] }, null]
+      }
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, metadata, err := evaluator.ResolveBooleanValue(context.Background(), "", "metadataFlag", &structpb.Struct{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"owner": "checkout", "version": float64(3), "experimental": true}, metadata)
+
+	ctx, err := structpb.NewStruct(map[string]interface{}{"email": "x@faas.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, metadata, err = evaluator.ResolveBooleanValue(context.Background(), "", "metadataFlag", ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "beta", metadata[eval.RuleIDMetadataKey])
+	assert.Equal(t, float64(3), metadata["version"])
+
+	// metadata values keep their type through structpb
+	proto, err := structpb.NewStruct(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, float64(3), proto.Fields["version"].GetNumberValue())
+	assert.Equal(t, true, proto.Fields["experimental"].GetBoolValue())
+	assert.Equal(t, "checkout", proto.Fields["owner"].GetStringValue())
+}
+
+func TestFlagMetadata_Validation(t *testing.T) {
+	tests := map[string]string{
+		"object": `{ "owner": { "team": "checkout" } }`,
+		"array":  `{ "owners": ["checkout"] }`,
+		"null":   `{ "owner": null }`,
+	}
+	for name, metadata := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := eval.NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(`{
+  "flags": {
+    "metadataFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "metadata": %s
+    }
+  }
+}`, metadata))
+			if err == nil {
+				t.Fatal("expected metadata validation to fail")
+			}
+			assert.Contains(t, err.Error(), "has a value of unsupported type "+name)
+		})
+	}
+}
+
+func TestContextKeyNormalization(t *testing.T) {
+	const config = `{
+  "flags": {
+    "betaFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": {
+        "if": [
+          { "or": [
+            { "==": [{ "var": "userId" }, "42"] },
+            { "==": [{ "var": ["Account.Plan", "free"] }, "enterprise"] }
+          ] },
+          "on",
+          "off"
+        ]
+      }
+    }
+  }
+}`
+	tests := map[string]struct {
+		context    map[string]interface{}
+		strict     bool
+		normalized bool
+	}{
+		"exact key": {
+			context:    map[string]interface{}{"userId": "42"},
+			strict:     true,
+			normalized: true,
+		},
+		"lowercase key": {
+			context:    map[string]interface{}{"userid": "42"},
+			strict:     false,
+			normalized: true,
+		},
+		"uppercase key": {
+			context:    map[string]interface{}{"USERID": "42"},
+			strict:     false,
+			normalized: true,
+		},
+		"nested key": {
+			context:    map[string]interface{}{"account": map[string]interface{}{"PLAN": "enterprise"}},
+			strict:     false,
+			normalized: true,
+		},
+		"lowercase key wins a collision": {
+			context:    map[string]interface{}{"userid": "42", "UserId": "7", "USERID": "7"},
+			strict:     false,
+			normalized: true,
+		},
+		"no matching key": {
+			context:    map[string]interface{}{"user": "42"},
+			strict:     false,
+			normalized: false,
+		},
+	}
+	strict, err := eval.NewJSONEvaluatorFromConfig(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized, err := eval.NewJSONEvaluatorFromConfig(
+		nil, config, eval.WithContextKeyNormalization(eval.KeyNormalizationLowercase),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, _, _, _, err := strict.ResolveBooleanValue(context.Background(), "", "betaFlag", evalCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.strict, value, "strict matching")
+
+			value, _, _, _, err = normalized.ResolveBooleanValue(context.Background(), "", "betaFlag", evalCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.normalized, value, "case-insensitive matching")
+		})
+	}
+}
+
+func TestContextKeyNormalization_FractionalEvaluation(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "red", "blue": "blue" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["Email", ["red", 0], ["blue", 100]] }
+    }
+  }
+}`, eval.WithContextKeyNormalization(eval.KeyNormalizationLowercase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"EMAIL": "test@faas.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "blue", value)
+	assert.Equal(t, "blue", variant)
+	assert.Equal(t, model.TargetingMatchReason, reason)
+}
+
+func TestParseKeyNormalization(t *testing.T) {
+	mode, err := eval.ParseKeyNormalization("")
+	assert.Nil(t, err)
+	assert.Equal(t, eval.KeyNormalizationNone, mode)
+
+	mode, err = eval.ParseKeyNormalization("lowercase")
+	assert.Nil(t, err)
+	assert.Equal(t, eval.KeyNormalizationLowercase, mode)
+
+	_, err = eval.ParseKeyNormalization("uppercase")
+	assert.NotNil(t, err)
+}
+
+func TestResolveBeforeInitialSync(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(nil, store.NewFlags())
+
+	_, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "myBoolFlag", nil)
+	assert.EqualError(t, err, model.ProviderNotReadyErrorCode)
+	assert.Equal(t, model.ErrorReason, reason)
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: `{"flags": {}}`, Type: sync.ALL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "myBoolFlag", nil)
+	assert.EqualError(t, err, model.FlagNotFoundErrorCode, "missing flags are absent once synced")
+}
+
+func TestSetState_DuplicateKeys(t *testing.T) {
+	const config = `{
+  "flags": {
+    "hello": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" },
+    "hello": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" }
+  }
+}`
+	tests := map[string]struct {
+		policy      store.DuplicateKeys
+		wantErr     string
+		wantVariant string
+		wantLog     string
+	}{
+		"error": {
+			policy:  store.DuplicateKeysError,
+			wantErr: "duplicate flag key: 'hello' is defined 2 times by source: 'flags.json'",
+		},
+		"first wins": {
+			policy:      store.DuplicateKeysFirstWins,
+			wantVariant: "off",
+			wantLog:     "duplicate flag key: 'hello' is defined 2 times by source: 'flags.json', keeping the first",
+		},
+		"last wins": {
+			policy:      store.DuplicateKeysLastWins,
+			wantVariant: "on",
+			wantLog:     "duplicate flag key: 'hello' is defined 2 times by source: 'flags.json', keeping the last",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			flags := store.NewFlags()
+			flags.DuplicateKeys = tt.policy
+			evaluator := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), flags)
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: config, Source: "flags.json", Type: sync.ALL})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, variant, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "hello", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantVariant, variant)
+			assert.Equal(t, 1, logs.FilterMessage(tt.wantLog).Len())
+		})
+	}
+
+	t.Run("error across sources", func(t *testing.T) {
+		flags := store.NewFlags()
+		flags.FlagSources = []string{"a.json", "b.json"}
+		flags.DuplicateKeys = store.DuplicateKeysError
+		evaluator := eval.NewJSONEvaluator(nil, flags)
+		const flag = `{
+  "flags": {
+    "hello": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "%s" }
+  }
+}`
+		_, _, err := evaluator.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(flag, "off"), Source: "a.json", Type: sync.ALL,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = evaluator.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(flag, "on"), Source: "b.json", Type: sync.ALL,
+		})
+		assert.EqualError(t, err, "duplicate flag key: 'hello' of source: 'b.json' is already defined by source: 'a.json'")
+
+		_, variant, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "hello", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "off", variant, "the configuration of the rejected source shouldn't be stored")
+	})
+}
+
+func TestCacheTTL(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(logger.NewLogger(zap.New(core), false), `{
+  "flags": {
+    "staticFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "cacheTtl": 300
+    },
+    "targetedFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "on", null] },
+      "cacheTtl": 60,
+      "metadata": { "team": "growth" }
+    },
+    "scheduledFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ ">=": [{ "var": "$flagd.timestamp" }, 1685610000] }, "on", null] },
+      "cacheTtl": 60
+    },
+    "fractionalFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "fractionalEvaluation": ["email", ["on", 50], ["off", 50]] },
+      "cacheTtl": 60
+    },
+    "uncachedFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, flagKey := range []string{"scheduledFlag", "fractionalFlag"} {
+		assert.Equal(t, 1, logs.FilterMessage(fmt.Sprintf(
+			"flag: '%s' has time sensitive or fractional targeting, its cacheTtl is ignored", flagKey,
+		)).Len())
+	}
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		context *structpb.Struct
+		wantTTL interface{}
+	}{
+		"staticFlag":     {wantTTL: int64(300)},
+		"targetedFlag":   {context: evalCtx, wantTTL: int64(60)},
+		"scheduledFlag":  {},
+		"fractionalFlag": {context: evalCtx},
+		"uncachedFlag":   {},
+	}
+	for flagKey, tt := range tests {
+		t.Run(flagKey, func(t *testing.T) {
+			_, _, _, metadata, err := evaluator.ResolveBooleanValue(context.Background(), "", flagKey, tt.context)
+			assert.Nil(t, err)
+			ttl, ok := metadata[eval.CacheTTLMetadataKey]
+			assert.Equal(t, tt.wantTTL != nil, ok)
+			assert.Equal(t, tt.wantTTL, ttl)
+		})
+	}
+
+	t.Run("default variant of a targeted flag", func(t *testing.T) {
+		_, _, reason, metadata, err := evaluator.ResolveBooleanValue(context.Background(), "", "targetedFlag", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, model.DefaultReason, reason)
+		assert.Equal(t, map[string]interface{}{eval.CacheTTLMetadataKey: int64(60), "team": "growth"}, metadata)
+	})
+
+	t.Run("negative ttl", func(t *testing.T) {
+		_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "staticFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on", "cacheTtl": -1 }
+  }
+}`)
+		assert.ErrorContains(t, err, "cacheTtl: -1 of flag: 'staticFlag' is negative")
+	})
+}
+
+func TestDefaultVariantByContext(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00", "yellow": "#FFFF00" },
+      "defaultVariant": "red",
+      "defaultVariantByContext": {
+        "contextKey": "user.plan",
+        "variants": { "pro": "blue", "enterprise": "green", "3": "yellow" }
+      },
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "yellow", null] }
+    },
+    "staticColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "defaultVariantByContext": { "contextKey": "tier", "variants": { "pro": "blue" } }
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		flagKey     string
+		context     map[string]interface{}
+		wantVariant string
+		wantReason  string
+	}{
+		"mapped context value": {
+			flagKey:     "headerColor",
+			context:     map[string]interface{}{"user": map[string]interface{}{"plan": "pro"}},
+			wantVariant: "blue",
+			wantReason:  model.DefaultReason,
+		},
+		"other mapped context value": {
+			flagKey:     "headerColor",
+			context:     map[string]interface{}{"user": map[string]interface{}{"plan": "enterprise"}},
+			wantVariant: "green",
+			wantReason:  model.DefaultReason,
+		},
+		"number context value": {
+			flagKey:     "headerColor",
+			context:     map[string]interface{}{"user": map[string]interface{}{"plan": 3}},
+			wantVariant: "yellow",
+			wantReason:  model.DefaultReason,
+		},
+		"unmapped context value falls back": {
+			flagKey:     "headerColor",
+			context:     map[string]interface{}{"user": map[string]interface{}{"plan": "free"}},
+			wantVariant: "red",
+			wantReason:  model.DefaultReason,
+		},
+		"missing context value falls back": {
+			flagKey:     "headerColor",
+			wantVariant: "red",
+			wantReason:  model.DefaultReason,
+		},
+		"targeting match ignores the mapping": {
+			flagKey: "headerColor",
+			context: map[string]interface{}{
+				"email": "user@faas.com", "user": map[string]interface{}{"plan": "pro"},
+			},
+			wantVariant: "yellow",
+			wantReason:  model.TargetingMatchReason,
+		},
+		"flag without targeting": {
+			flagKey:     "staticColor",
+			context:     map[string]interface{}{"tier": "pro"},
+			wantVariant: "blue",
+			wantReason:  model.DefaultReason,
+		},
+		"flag without targeting falls back": {
+			flagKey:     "staticColor",
+			wantVariant: "red",
+			wantReason:  model.DefaultReason,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", tt.flagKey, evalCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantVariant, variant)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+
+	t.Run("unknown variant", func(t *testing.T) {
+		_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red",
+      "defaultVariantByContext": { "contextKey": "plan", "variants": { "pro": "blue" } }
+    }
+  }
+}`)
+		assert.ErrorContains(t, err,
+			"default variant: 'blue' of context value: 'pro' isn't a valid variant of flag: 'headerColor'")
+	})
+}
+
+// generationFlags is a configuration whose flags all resolve the generation, even generations define an extra flag
+func generationFlags(generation int) string {
+	flags := map[string]interface{}{}
+	keys := []string{"first"}
+	for i := 0; i < 20; i++ {
+		keys = append(keys, fmt.Sprintf("flag-%d", i))
+	}
+	if generation%2 == 0 {
+		keys = append(keys, "extra")
+	}
+	for _, key := range keys {
+		flags[key] = map[string]interface{}{
+			"state":          "ENABLED",
+			"variants":       map[string]interface{}{"generation": generation},
+			"defaultVariant": "generation",
+		}
+	}
+	config, _ := json.Marshal(map[string]interface{}{"flags": flags})
+	return string(config)
+}
+
+// stateGeneration returns the generation resolved by every flag of the state, failing if the state mixes the flags of
+// several generations
+func stateGeneration(state string) (float64, error) {
+	var flags struct {
+		Flags map[string]model.Flag `json:"flags"`
+	}
+	if err := json.Unmarshal([]byte(state), &flags); err != nil {
+		return 0, err
+	}
+	generations := map[float64]struct{}{}
+	var generation float64
+	for _, flag := range flags.Flags {
+		generation = flag.Variants["generation"].(float64)
+		generations[generation] = struct{}{}
+	}
+	if len(generations) > 1 {
+		return 0, fmt.Errorf("state mixes the flags of generations %v", generations)
+	}
+	if _, extra := flags.Flags["extra"]; len(flags.Flags) > 0 && extra != (int(generation)%2 == 0) {
+		return 0, fmt.Errorf("the extra flag doesn't match generation %v", generation)
+	}
+	return generation, nil
+}
+
+func TestSetState_ConcurrentReloads(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(), eval.WithRuleWarmup(true))
+	done := make(chan struct{})
+	readers := gosync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				state, err := evaluator.GetState()
+				if err == nil {
+					_, err = stateGeneration(state)
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	reloads := gosync.WaitGroup{}
+	for generation := 1; generation <= 100; generation++ {
+		reloads.Add(1)
+		go func(generation int) {
+			defer reloads.Done()
+			_, _, err := evaluator.SetState(sync.DataSync{
+				FlagData: generationFlags(generation), Source: "flags.json", Type: sync.ALL,
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(generation)
+	}
+	reloads.Wait()
+	close(done)
+	readers.Wait()
+
+	state, err := evaluator.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	generation, err := stateGeneration(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _, _, _, err := evaluator.ResolveIntValue(context.Background(), "", "first", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(generation), value, "evaluations should resolve the last applied reload")
+}
@@ -0,0 +1,140 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeyNormalization defines how evaluation context keys, and the references of targeting rules to them, are
+// normalized before evaluation
+type KeyNormalization string
+
+const (
+	// KeyNormalizationNone matches context keys exactly, the default
+	KeyNormalizationNone KeyNormalization = ""
+	// KeyNormalizationLowercase lowercases context keys and rule references, so they match case-insensitively
+	KeyNormalizationLowercase KeyNormalization = "lowercase"
+
+	varOperator = "var"
+)
+
+// ParseKeyNormalization returns the key normalization mode of its name, an empty name disables normalization
+func ParseKeyNormalization(mode string) (KeyNormalization, error) {
+	switch KeyNormalization(mode) {
+	case KeyNormalizationNone, KeyNormalizationLowercase:
+		return KeyNormalization(mode), nil
+	default:
+		return KeyNormalizationNone, fmt.Errorf("unknown context key normalization: '%s', expected '%s'",
+			mode, KeyNormalizationLowercase)
+	}
+}
+
+// WithContextKeyNormalization normalizes the keys of evaluation contexts, and the keys referenced by targeting
+// rules, e.g. so that a rule on {"var": "userId"} matches a context holding userid
+func WithContextKeyNormalization(mode KeyNormalization) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.keyNormalization = mode
+	}
+}
+
+// normalizeContext returns the context with its keys, including those of nested objects, normalized. Keys which
+// collide once normalized resolve to the already normalized key if present, otherwise to the smallest key.
+func (je *JSONEvaluator) normalizeContext(context map[string]interface{}) map[string]interface{} {
+	if je.keyNormalization != KeyNormalizationLowercase {
+		return context
+	}
+	return lowercaseKeys(context)
+}
+
+func lowercaseKeys(m map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	// keys are set in reverse order, already lowercase keys last, so that either wins a collision
+	sort.Slice(keys, func(i, j int) bool {
+		iLower, jLower := keys[i] == strings.ToLower(keys[i]), keys[j] == strings.ToLower(keys[j])
+		if iLower != jLower {
+			return jLower
+		}
+		return keys[i] > keys[j]
+	})
+
+	normalized := make(map[string]interface{}, len(m))
+	for _, key := range keys {
+		normalized[strings.ToLower(key)] = lowercaseValueKeys(m[key])
+	}
+	return normalized
+}
+
+func lowercaseValueKeys(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return lowercaseKeys(value)
+	case []interface{}:
+		normalized := make([]interface{}, len(value))
+		for i, item := range value {
+			normalized[i] = lowercaseValueKeys(item)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
+
+// normalizeRule normalizes the context keys referenced by a parsed targeting rule, i.e. the paths of var
+// operations and the bucketing key of fractional evaluations
+func (je *JSONEvaluator) normalizeRule(rule interface{}) {
+	if je.keyNormalization != KeyNormalizationLowercase {
+		return
+	}
+	lowercaseReferences(rule)
+}
+
+func lowercaseReferences(rule interface{}) {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for operator, args := range r {
+			switch operator {
+			case varOperator:
+				if path, ok := args.(string); ok {
+					r[operator] = strings.ToLower(path)
+					continue
+				}
+				lowercaseFirstString(args)
+			case fractionalEvaluationOperator:
+				lowercaseFirstString(args)
+				if list, ok := args.([]interface{}); ok && len(list) > 0 {
+					// the bucketing keys of composite fractional evaluations
+					lowercaseStrings(list[0])
+				}
+			}
+			lowercaseReferences(r[operator])
+		}
+	case []interface{}:
+		for _, item := range r {
+			lowercaseReferences(item)
+		}
+	}
+}
+
+// lowercaseStrings lowercases the string elements of a list
+func lowercaseStrings(list interface{}) {
+	if items, ok := list.([]interface{}); ok {
+		for i, item := range items {
+			if s, ok := item.(string); ok {
+				items[i] = strings.ToLower(s)
+			}
+		}
+	}
+}
+
+// lowercaseFirstString lowercases the first element of an argument list, if it's a string
+func lowercaseFirstString(args interface{}) {
+	if list, ok := args.([]interface{}); ok && len(list) > 0 {
+		if s, ok := list[0].(string); ok {
+			list[0] = strings.ToLower(s)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// maxExactInteger is the largest integer magnitude float64 numbers, and therefore structpb numbers, hold exactly
+const maxExactInteger = 1 << 53
+
+// LargeIntegers defines how integers of object variants which float64 numbers can't represent exactly are handled
+type LargeIntegers string
+
+const (
+	// LargeIntegersError rejects flags whose object variants hold large integers, the default
+	LargeIntegersError LargeIntegers = "error"
+	// LargeIntegersString encodes large integers of object variants as strings, preserving their digits
+	LargeIntegersString LargeIntegers = "string"
+)
+
+// ParseLargeIntegers returns the large integer policy of its name, an empty name defaults to error
+func ParseLargeIntegers(policy string) (LargeIntegers, error) {
+	switch LargeIntegers(policy) {
+	case "":
+		return LargeIntegersError, nil
+	case LargeIntegersError, LargeIntegersString:
+		return LargeIntegers(policy), nil
+	default:
+		return "", fmt.Errorf("unknown large integer policy: '%s', expected '%s' or '%s'",
+			policy, LargeIntegersError, LargeIntegersString)
+	}
+}
+
+// WithLargeIntegers sets the policy of integers of object variants beyond the exact integer range of float64
+func WithLargeIntegers(policy LargeIntegers) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.largeIntegers = policy
+	}
+}
+
+// convertLargeIntegers decodes the object variants of a flag again, preserving their numbers, and either rejects
+// integers beyond the exact range of float64 or replaces them with strings, rather than rounding them silently
+func (je *JSONEvaluator) convertLargeIntegers(key string, raw json.RawMessage, flag *model.Flag) error {
+	if !hasObjectVariant(*flag) {
+		return nil
+	}
+	var fields struct {
+		Variants map[string]json.RawMessage `json:"variants"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("unmarshalling flag: '%s': %w", key, err)
+	}
+	variants := make([]string, 0, len(fields.Variants))
+	for variant := range fields.Variants {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	for _, variant := range variants {
+		if _, ok := flag.Variants[variant].(map[string]interface{}); !ok {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(fields.Variants[variant]))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("unmarshalling variant: '%s' of flag: '%s': %w", variant, key, err)
+		}
+		converted, err := je.convertNumbers(value, "")
+		if err != nil {
+			return fmt.Errorf("variant: '%s' of flag: '%s': %w", variant, key, err)
+		}
+		flag.Variants[variant] = converted
+	}
+	return nil
+}
+
+// convertNumbers replaces the json numbers of a decoded value with float64 numbers, or strings for large integers
+func (je *JSONEvaluator) convertNumbers(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// keys are walked in order, so the same integer is reported for every load
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			converted, err := je.convertNumbers(v[key], joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := je.convertNumbers(item, joinPath(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	case json.Number:
+		if !isLargeInteger(v) {
+			return v.Float64()
+		}
+		if je.largeIntegers == LargeIntegersString {
+			return v.String(), nil
+		}
+		return nil, fmt.Errorf("integer %s at '%s' exceeds the exact integer range of numbers (2^53), "+
+			"quote it or encode large integers as strings", v, path)
+	default:
+		return v, nil
+	}
+}
+
+func hasObjectVariant(flag model.Flag) bool {
+	for _, value := range flag.Variants {
+		if _, ok := value.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isLargeInteger(number json.Number) bool {
+	if strings.ContainsAny(number.String(), ".eE") {
+		return false
+	}
+	i, err := number.Int64()
+	if err != nil {
+		// the integer exceeds the int64 range
+		return true
+	}
+	return i > maxExactInteger || i < -maxExactInteger
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,106 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func largeIntegerFlagConfig(value string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "accountFlag": {
+      "state": "ENABLED",
+      "variants": {
+        "default": { "account": { "id": %s, "shards": [1, %s] }, "ratio": 0.5 }
+      },
+      "defaultVariant": "default"
+    }
+  }
+}`, value, value)
+}
+
+func TestLargeIntegers(t *testing.T) {
+	tests := map[string]struct {
+		policy    LargeIntegers
+		value     string
+		wantID    interface{}
+		wantShard interface{}
+		wantErr   string
+	}{
+		"exact integer": {
+			value:     "9007199254740992",
+			wantID:    float64(9007199254740992),
+			wantShard: float64(9007199254740992),
+		},
+		"negative exact integer": {
+			value:     "-9007199254740992",
+			wantID:    float64(-9007199254740992),
+			wantShard: float64(-9007199254740992),
+		},
+		"large float": {
+			value:     "9.007199254740993e15",
+			wantID:    9.007199254740993e15,
+			wantShard: 9.007199254740993e15,
+		},
+		"large integer rejected by default": {
+			value:   "9007199254740993",
+			wantErr: "variant: 'default' of flag: 'accountFlag': integer 9007199254740993 at 'account.id' exceeds",
+		},
+		"large integer rejected": {
+			policy:  LargeIntegersError,
+			value:   "-9007199254740993",
+			wantErr: "integer -9007199254740993 at 'account.id' exceeds the exact integer range",
+		},
+		"large integer as string": {
+			policy:    LargeIntegersString,
+			value:     "9007199254740993",
+			wantID:    "9007199254740993",
+			wantShard: "9007199254740993",
+		},
+		"integer beyond int64 as string": {
+			policy:    LargeIntegersString,
+			value:     "92233720368547758070",
+			wantID:    "92233720368547758070",
+			wantShard: "92233720368547758070",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, largeIntegerFlagConfig(tt.value), WithLargeIntegers(tt.policy))
+			if tt.wantErr != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			value, _, _, _, err := je.ResolveObjectValue(context.Background(), "", "accountFlag", &structpb.Struct{})
+			require.Nil(t, err)
+			account := value["account"].(map[string]interface{})
+			require.Equal(t, tt.wantID, account["id"])
+			require.Equal(t, []interface{}{float64(1), tt.wantShard}, account["shards"])
+			require.Equal(t, 0.5, value["ratio"])
+
+			// the value survives the conversion of object responses
+			converted, err := structpb.NewStruct(value)
+			require.Nil(t, err)
+			require.Equal(t, tt.wantID, converted.AsMap()["account"].(map[string]interface{})["id"])
+		})
+	}
+}
+
+func TestParseLargeIntegers(t *testing.T) {
+	policy, err := ParseLargeIntegers("")
+	require.Nil(t, err)
+	require.Equal(t, LargeIntegersError, policy)
+
+	policy, err = ParseLargeIntegers("string")
+	require.Nil(t, err)
+	require.Equal(t, LargeIntegersString, policy)
+
+	_, err = ParseLargeIntegers("round")
+	require.EqualError(t, err, "unknown large integer policy: 'round', expected 'error' or 'string'")
+}
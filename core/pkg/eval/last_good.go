@@ -0,0 +1,137 @@
+package eval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// lastGoodCapacity is the number of evaluation contexts whose last good result is kept per targeting rule, the
+// result evaluated the longest ago is evicted first
+const lastGoodCapacity = 128
+
+// lastGoodResults holds the last successful targeting result of flags serving it on error, per evaluation context
+type lastGoodResults struct {
+	mu    sync.Mutex
+	rules map[string]*lastGoodRule
+}
+
+// lastGoodRule holds the results of a targeting rule, they're dropped once the targeting of the flag changes
+type lastGoodRule struct {
+	targeting string
+	results   map[string]lastGoodResult
+}
+
+type lastGoodResult struct {
+	variant   string
+	metadata  map[string]interface{}
+	evaluated time.Time
+}
+
+func (c *lastGoodResults) set(ruleKey string, targeting string, contextKey string, result lastGoodResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rule := c.rule(ruleKey, targeting)
+	if _, ok := rule.results[contextKey]; !ok && len(rule.results) >= lastGoodCapacity {
+		oldest := ""
+		for key, cached := range rule.results {
+			if oldest == "" || cached.evaluated.Before(rule.results[oldest].evaluated) {
+				oldest = key
+			}
+		}
+		delete(rule.results, oldest)
+	}
+	rule.results[contextKey] = result
+}
+
+// get returns the last good result of the context, unless it was evaluated longer than staleness ago
+func (c *lastGoodResults) get(
+	ruleKey string, targeting string, contextKey string, staleness time.Duration, now time.Time,
+) (lastGoodResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rule := c.rule(ruleKey, targeting)
+	result, ok := rule.results[contextKey]
+	if !ok {
+		return lastGoodResult{}, false
+	}
+	if now.Sub(result.evaluated) > staleness {
+		delete(rule.results, contextKey)
+		return lastGoodResult{}, false
+	}
+	return result, true
+}
+
+// rule returns the results of the targeting rule, resetting them if the targeting changed. The caller must hold c.mu.
+func (c *lastGoodResults) rule(ruleKey string, targeting string) *lastGoodRule {
+	if c.rules == nil {
+		c.rules = map[string]*lastGoodRule{}
+	}
+	rule, ok := c.rules[ruleKey]
+	if !ok || rule.targeting != targeting {
+		rule = &lastGoodRule{targeting: targeting, results: map[string]lastGoodResult{}}
+		c.rules[ruleKey] = rule
+	}
+	return rule
+}
+
+func validateLastGoodStaleness(key string, flag model.Flag) error {
+	if flag.LastGoodStaleness != nil && *flag.LastGoodStaleness <= 0 {
+		return fmt.Errorf("lastGoodStaleness: %d of flag: '%s' isn't a positive number of seconds",
+			*flag.LastGoodStaleness, key)
+	}
+	return nil
+}
+
+// targetingEvaluation evaluates the targeting of a flag, with or without its circuit breaker
+type targetingEvaluation func(
+	reqID string, flagKey string, ruleKey string, flag model.Flag, context *structpb.Struct,
+) (string, string, map[string]interface{}, error)
+
+// evaluateTargetingWithLastGood evaluates the targeting of a flag, falling back to the last successful result of the
+// same evaluation context with the STALE reason on error, for up to lastGoodStaleness seconds after it was evaluated
+func (je *JSONEvaluator) evaluateTargetingWithLastGood(
+	reqID string, flagKey string, ruleKey string, flag model.Flag, context *structpb.Struct, evaluate targetingEvaluation,
+) (string, string, map[string]interface{}, error) {
+	variant, reason, metadata, err := evaluate(reqID, flagKey, ruleKey, flag, context)
+	contextKey, keyErr := lastGoodContextKey(je.normalizeContext(context.AsMap()))
+	if keyErr != nil {
+		je.Logger.Warn(fmt.Sprintf("keying the last good result of flag: %s: %v", flagKey, keyErr))
+		return variant, reason, metadata, err
+	}
+	targeting := string(flag.Targeting)
+	if err == nil {
+		if reason != model.ErrorReason {
+			je.lastGood.set(ruleKey, targeting, contextKey, lastGoodResult{
+				variant: variant, metadata: metadata, evaluated: je.clock.Now(),
+			})
+		}
+		return variant, reason, metadata, nil
+	}
+
+	staleness := time.Duration(*flag.LastGoodStaleness) * time.Second
+	last, ok := je.lastGood.get(ruleKey, targeting, contextKey, staleness, je.clock.Now())
+	if _, defined := flag.Variants[last.variant]; !ok || !defined {
+		return variant, reason, metadata, err
+	}
+	je.Logger.WarnWithID(reqID, fmt.Sprintf("evaluating flag: %s failed: %v, serving its last good variant: %s "+
+		"evaluated %s ago", flagKey, err, last.variant, je.clock.Now().Sub(last.evaluated)))
+	return last.variant, model.StaleReason, last.metadata, nil
+}
+
+// lastGoodContextKey returns the hex encoded SHA-256 of the canonical json of the evaluation context
+func lastGoodContextKey(context map[string]interface{}) (string, error) {
+	// maps are marshalled with sorted keys, the json is canonical
+	raw, err := json.Marshal(context)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,119 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the targeting of the flags fails between 09:01 and 09:10, as it reads the user object as a string then
+func lastGoodFlagConfig(start time.Time) string {
+	targeting := fmt.Sprintf(`{ "if": [{ "in": ["@faas.com", { "var": { "if": [
+        { "and": [{ ">=": [{ "var": "$flagd.timestamp" }, %d] }, { "<": [{ "var": "$flagd.timestamp" }, %d] }] },
+        "user", "user.email"
+      ] } }] }, "on", "off"] }`, start.Add(time.Minute).Unix(), start.Add(10*time.Minute).Unix())
+	return fmt.Sprintf(`{
+  "flags": {
+    "resilientFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "lastGoodStaleness": 300,
+      "targeting": %s
+    },
+    "fragileFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": %s
+    }
+  }
+}`, targeting, targeting)
+}
+
+func TestLastGood(t *testing.T) {
+	start := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(), eval.WithClock(clock))
+	_, _, err := je.SetState(sync.DataSync{FlagData: lastGoodFlagConfig(start), Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	resolve := func(flagKey string, email string) (bool, string, error) {
+		t.Helper()
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"user": map[string]interface{}{"email": email}})
+		require.Nil(t, err)
+		value, _, reason, _, err := je.ResolveBooleanValue(context.Background(), "", flagKey, evalCtx)
+		return value, reason, err
+	}
+
+	value, reason, err := resolve("resilientFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason)
+	require.True(t, value)
+	_, _, err = resolve("fragileFlag", "user@faas.com")
+	require.Nil(t, err)
+
+	clock.Advance(2 * time.Minute)
+	value, reason, err = resolve("resilientFlag", "user@faas.com")
+	require.Nil(t, err, "the last good result must be served while the targeting fails")
+	require.Equal(t, model.StaleReason, reason)
+	require.True(t, value)
+	_, _, err = resolve("fragileFlag", "user@faas.com")
+	require.NotNil(t, err, "flags without lastGoodStaleness must fail")
+	_, _, err = resolve("resilientFlag", "user@example.com")
+	require.NotNil(t, err, "contexts without a last good result must fail")
+
+	clock.Advance(4 * time.Minute)
+	_, _, err = resolve("resilientFlag", "user@faas.com")
+	require.NotNil(t, err, "results older than lastGoodStaleness must not be served")
+
+	clock.Advance(4 * time.Minute)
+	value, reason, err = resolve("resilientFlag", "user@faas.com")
+	require.Nil(t, err)
+	require.Equal(t, model.TargetingMatchReason, reason, "recovered evaluations must be served")
+	require.True(t, value)
+}
+
+func TestLastGood_TargetingChange(t *testing.T) {
+	start := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(), eval.WithClock(clock))
+	_, _, err := je.SetState(sync.DataSync{FlagData: lastGoodFlagConfig(start), Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"user": map[string]interface{}{"email": "user@faas.com"}})
+	require.Nil(t, err)
+	_, _, _, _, err = je.ResolveBooleanValue(context.Background(), "", "resilientFlag", evalCtx)
+	require.Nil(t, err)
+
+	clock.Advance(2 * time.Minute)
+	_, _, err = je.SetState(sync.DataSync{
+		FlagData: lastGoodFlagConfig(start.Add(time.Minute)), Source: "flags.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+	_, _, _, _, err = je.ResolveBooleanValue(context.Background(), "", "resilientFlag", evalCtx)
+	require.NotNil(t, err, "results of a previous targeting must not be served")
+}
+
+func TestLastGood_Validation(t *testing.T) {
+	for _, staleness := range []string{"0", "-30"} {
+		_, err := eval.NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(`{
+  "flags": {
+    "resilientFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "lastGoodStaleness": %s
+    }
+  }
+}`, staleness))
+		require.NotNil(t, err, "lastGoodStaleness: %s must be rejected", staleness)
+	}
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// validateMaskedFields checks the masked fields of a flag are dot separated paths without empty segments, masking
+// fields of the values of object flags only
+func validateMaskedFields(key string, flag model.Flag) error {
+	if len(flag.MaskedFields) == 0 {
+		return nil
+	}
+	if _, ok := flag.Variants[flag.DefaultVariant].(map[string]interface{}); !ok {
+		return fmt.Errorf("flag: '%s' masks fields, but isn't an object flag", key)
+	}
+	for _, path := range flag.MaskedFields {
+		for _, segment := range strings.Split(path, ".") {
+			if segment == "" {
+				return fmt.Errorf("flag: '%s' masks the invalid field path: '%s'", key, path)
+			}
+		}
+	}
+	return nil
+}
+
+// maskFields returns the value of an object flag without its masked fields. The value is the variant of the flag,
+// so the objects holding masked fields are copied rather than changed.
+func maskFields(flag model.Flag, value map[string]interface{}) map[string]interface{} {
+	for _, path := range flag.MaskedFields {
+		value = maskPath(value, strings.Split(path, "."))
+	}
+	return value
+}
+
+// maskPath returns the object without the field at path, the object itself if it doesn't hold the field. Paths
+// going through arrays mask the field of each object of the array.
+func maskPath(object map[string]interface{}, path []string) map[string]interface{} {
+	field, ok := object[path[0]]
+	if !ok {
+		return object
+	}
+	masked := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		masked[k] = v
+	}
+	if len(path) == 1 {
+		delete(masked, path[0])
+		return masked
+	}
+	switch v := field.(type) {
+	case map[string]interface{}:
+		masked[path[0]] = maskPath(v, path[1:])
+	case []interface{}:
+		elements := make([]interface{}, len(v))
+		for i, element := range v {
+			if nested, ok := element.(map[string]interface{}); ok {
+				elements[i] = maskPath(nested, path[1:])
+			} else {
+				elements[i] = element
+			}
+		}
+		masked[path[0]] = elements
+	default:
+		return object
+	}
+	return masked
+}
@@ -0,0 +1,104 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const maskedFieldsFlagConfig = `{
+  "flags": {
+    "checkoutConfig": {
+      "state": "ENABLED",
+      "variants": {
+        "default": {
+          "title": "Checkout",
+          "tuning": { "retries": 3, "timeoutMs": 500 },
+          "internal": { "owner": "payments", "costCenter": 42 },
+          "steps": [
+            { "name": "cart", "weight": 0.2 },
+            { "name": "payment", "weight": 0.8 },
+            "confirmation"
+          ]
+        }
+      },
+      "defaultVariant": "default",
+      "maskedFields": ["internal", "tuning.timeoutMs", "steps.weight", "missing.field"]
+    }
+  }
+}`
+
+func TestMaskedFields(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, maskedFieldsFlagConfig)
+	require.Nil(t, err)
+	want := map[string]interface{}{
+		"title":  "Checkout",
+		"tuning": map[string]interface{}{"retries": float64(3)},
+		"steps": []interface{}{
+			map[string]interface{}{"name": "cart"},
+			map[string]interface{}{"name": "payment"},
+			"confirmation",
+		},
+	}
+
+	value, _, _, _, err := evaluator.ResolveObjectValue(context.Background(), "", "checkoutConfig", nil)
+	require.Nil(t, err)
+	require.Equal(t, want, value, "masked fields should be absent and the others remain")
+	_, err = structpb.NewStruct(value)
+	require.Nil(t, err)
+
+	values := evaluator.ResolveAllValues(context.Background(), "", &structpb.Struct{})
+	require.Len(t, values, 1)
+	require.Equal(t, want, values[0].Value)
+
+	// masking copies the variant rather than changing it
+	value, _, _, _, err = evaluator.ResolveObjectValue(context.Background(), "", "checkoutConfig", nil)
+	require.Nil(t, err)
+	require.Equal(t, want, value)
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Contains(t, state, "costCenter")
+}
+
+func TestMaskedFields_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		wantErr string
+	}{
+		"not an object flag": {
+			config: `{
+  "flags": {
+    "banner": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "maskedFields": ["internal"]
+    }
+  }
+}`,
+			wantErr: "flag: 'banner' masks fields, but isn't an object flag",
+		},
+		"empty segment": {
+			config: `{
+  "flags": {
+    "checkoutConfig": {
+      "state": "ENABLED",
+      "variants": { "default": { "tuning": { "retries": 3 } } },
+      "defaultVariant": "default",
+      "maskedFields": ["tuning..retries"]
+    }
+  }
+}`,
+			wantErr: "flag: 'checkoutConfig' masks the invalid field path: 'tuning..retries'",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := eval.NewJSONEvaluatorFromConfig(nil, tt.config)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
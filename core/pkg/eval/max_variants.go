@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// tooManyVariantsError rejects a flag with more variants than the maximum, without rejecting the rest of its
+// configuration
+type tooManyVariantsError struct {
+	key      string
+	variants int
+	max      int
+}
+
+func (e *tooManyVariantsError) Error() string {
+	return fmt.Sprintf("flag: '%s' has %d variants, exceeding the maximum of %d", e.key, e.variants, e.max)
+}
+
+// noVariantsError rejects a flag without any variant, as it can't resolve a value, without rejecting the rest of its
+// configuration
+type noVariantsError struct {
+	key string
+}
+
+func (e *noVariantsError) Error() string {
+	return fmt.Sprintf("flag: '%s' has no variants, a flag needs at least one variant and a default variant", e.key)
+}
+
+// WithMaxVariants rejects flags with more than max variants when their configuration is loaded, the other flags of
+// the configuration are loaded. The number of variants is unbounded when max is 0.
+func WithMaxVariants(max int) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.maxVariants = max
+	}
+}
+
+// validateVariantCount rejects the flag if it has no variants or more variants than the maximum. The variants are
+// counted before the flag is validated against the flag schema, so oversized flags don't cost a full validation.
+func (je *JSONEvaluator) validateVariantCount(key string, raw json.RawMessage) error {
+	var fields struct {
+		Variants *map[string]json.RawMessage `json:"variants"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil || fields.Variants == nil {
+		// the flag schema reports malformed flags
+		return nil
+	}
+	variants := *fields.Variants
+	if len(variants) == 0 {
+		return &noVariantsError{key: key}
+	}
+	if je.maxVariants > 0 && len(variants) > je.maxVariants {
+		return &tooManyVariantsError{key: key, variants: len(variants), max: je.maxVariants}
+	}
+	return nil
+}
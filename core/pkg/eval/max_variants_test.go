@@ -0,0 +1,105 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const maxVariantsFlagConfig = `{
+  "flags": {
+    "atLimit": {
+      "state": "ENABLED",
+      "variants": { "one": 1, "two": 2, "three": 3 },
+      "defaultVariant": "one"
+    },
+    "overLimit": {
+      "state": "ENABLED",
+      "variants": { "one": 1, "two": 2, "three": 3, "four": 4 },
+      "defaultVariant": "one"
+    }
+  }
+}`
+
+func TestMaxVariants(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), nil, eval.WithMaxVariants(3))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: maxVariantsFlagConfig, Type: sync.ALL})
+	require.Nil(t, err)
+
+	value, _, _, _, err := evaluator.ResolveIntValue(context.Background(), "", "atLimit", nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), value)
+
+	_, _, _, _, err = evaluator.ResolveIntValue(context.Background(), "", "overLimit", nil)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+
+	require.Equal(t, 1, logs.FilterMessage(
+		"rejecting flag: 'overLimit' has 4 variants, exceeding the maximum of 3, the other flags are loaded").Len())
+}
+
+func TestMaxVariants_Unbounded(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, maxVariantsFlagConfig, eval.WithMaxVariants(0))
+	require.Nil(t, err)
+
+	_, _, _, _, err = evaluator.ResolveIntValue(context.Background(), "", "overLimit", nil)
+	require.Nil(t, err)
+}
+
+func TestNoVariants(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), nil)
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "validFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "emptyFlag": {
+      "state": "ENABLED",
+      "variants": {},
+      "defaultVariant": "on"
+    },
+    "otherValidFlag": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red"
+    }
+  }
+}`, Type: sync.ALL})
+	require.Nil(t, err, "the other flags of the configuration should be loaded")
+
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "validFlag", nil)
+	require.Nil(t, err)
+	require.True(t, value)
+	color, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "otherValidFlag", nil)
+	require.Nil(t, err)
+	require.Equal(t, "#FF0000", color)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "emptyFlag", nil)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+
+	require.Equal(t, 1, logs.FilterMessage("rejecting flag: 'emptyFlag' has no variants, a flag needs at least one "+
+		"variant and a default variant, the other flags are loaded").Len())
+}
+
+func TestNoVariants_Validation(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, maxVariantsFlagConfig)
+	require.Nil(t, err)
+	issues, err := evaluator.ValidateConfig(`{
+  "flags": { "emptyFlag": { "state": "ENABLED", "variants": {}, "defaultVariant": "on" } }
+}`)
+	require.Nil(t, err)
+	require.Equal(t, []eval.ConfigIssue{{
+		FlagKey: "emptyFlag",
+		Message: "flag: 'emptyFlag' has no variants, a flag needs at least one variant and a default variant",
+	}}, issues, "validations should report flags without variants")
+}
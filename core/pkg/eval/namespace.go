@@ -0,0 +1,43 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxNamespaceDepth bounds the ancestors looked up for a flag which isn't defined
+const maxNamespaceDepth = 32
+
+// WithNamespaceFallthrough resolves flags which aren't defined through their closest defined ancestor, the
+// ancestors of a key being its prefixes ending before the separator, e.g. team.feature then team for team.feature.x.
+// Fallthrough is disabled when the separator is empty.
+func WithNamespaceFallthrough(separator string) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.namespaceSeparator = separator
+	}
+}
+
+// namespaceKey returns the key of the flag resolving flagKey, its closest defined ancestor if flagKey isn't defined
+// and namespace fallthrough is enabled, flagKey otherwise
+func (je *JSONEvaluator) namespaceKey(reqID string, flagKey string) string {
+	if je.namespaceSeparator == "" || !je.loaded.Load() {
+		return flagKey
+	}
+	if _, ok := je.store.Get(flagKey); ok {
+		return flagKey
+	}
+	ancestor := flagKey
+	for depth := 0; depth < maxNamespaceDepth; depth++ {
+		i := strings.LastIndex(ancestor, je.namespaceSeparator)
+		if i <= 0 {
+			break
+		}
+		ancestor = ancestor[:i]
+		if _, ok := je.store.Get(ancestor); ok {
+			je.Logger.DebugWithID(reqID, fmt.Sprintf("flag: %s isn't defined, resolving its namespace: %s",
+				flagKey, ancestor))
+			return ancestor
+		}
+	}
+	return flagKey
+}
@@ -0,0 +1,77 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const namespacedFlags = `{
+  "flags": {
+    "team": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" },
+    "team.feature": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" },
+    "team/color": { "state": "ENABLED", "variants": { "red": "red" }, "defaultVariant": "red" }
+  }
+}`
+
+func TestNamespaceFallthrough(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, namespacedFlags, eval.WithNamespaceFallthrough("."))
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		flagKey string
+		value   bool
+		variant string
+	}{
+		"defined flag":        {flagKey: "team.feature", value: true, variant: "on"},
+		"parent namespace":    {flagKey: "team.feature.x", value: true, variant: "on"},
+		"multi-level":         {flagKey: "team.feature.x.y.z", value: true, variant: "on"},
+		"root namespace":      {flagKey: "team.other.x", value: false, variant: "off"},
+		"empty trailing part": {flagKey: "team.", value: false, variant: "off"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", tt.flagKey, nil)
+			require.Nil(t, err)
+			require.Equal(t, tt.value, value)
+			require.Equal(t, tt.variant, variant)
+			require.Equal(t, model.StaticReason, reason)
+		})
+	}
+
+	t.Run("genuine not found", func(t *testing.T) {
+		for _, flagKey := range []string{"other.feature", "teams.feature", ".team", "", "team/feature"} {
+			_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", flagKey, nil)
+			require.EqualError(t, err, model.FlagNotFoundErrorCode, flagKey)
+		}
+	})
+
+	t.Run("flag type of the namespace", func(t *testing.T) {
+		flagType, ok := evaluator.FlagType("team.feature.x", nil)
+		require.True(t, ok)
+		require.Equal(t, eval.BooleanFlagType, flagType)
+	})
+}
+
+func TestNamespaceFallthroughSeparator(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, namespacedFlags, eval.WithNamespaceFallthrough("/"))
+	require.Nil(t, err)
+
+	value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "team/color/dark", nil)
+	require.Nil(t, err)
+	require.Equal(t, "red", value)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "team.feature.x", nil)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+}
+
+func TestNamespaceFallthroughDisabled(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, namespacedFlags)
+	require.Nil(t, err)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "team.feature.x", nil)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+}
@@ -0,0 +1,55 @@
+package eval
+
+import (
+	msync "sync"
+	"time"
+)
+
+// maxGraceFlags bounds the unknown flags tracked by the not found grace period, so requests for arbitrary keys can't
+// grow it unbounded. Unknown flags requested past it are reported as not found right away.
+const maxGraceFlags = 10000
+
+// WithNotFoundGracePeriod reports flags which aren't defined as not ready rather than not found for the period
+// following their first request, giving a source about to define them the chance to sync. Requests past the period
+// report the flag as not found. The grace period is disabled when 0.
+func WithNotFoundGracePeriod(period time.Duration) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if period > 0 {
+			je.notFoundGrace = &notFoundGrace{period: period, requested: map[string]time.Time{}}
+		}
+	}
+}
+
+// notFoundGrace tracks the first request of each flag which wasn't defined when requested
+type notFoundGrace struct {
+	period    time.Duration
+	mu        msync.Mutex
+	requested map[string]time.Time
+}
+
+// pending returns whether the undefined flag is still within the grace period following its first request
+func (g *notFoundGrace) pending(flagKey string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	first, ok := g.requested[flagKey]
+	if ok {
+		return now.Sub(first) < g.period
+	}
+	if len(g.requested) >= maxGraceFlags {
+		g.prune(now)
+		if len(g.requested) >= maxGraceFlags {
+			return false
+		}
+	}
+	g.requested[flagKey] = now
+	return true
+}
+
+// prune drops the flags whose grace period has expired, a pruned flag starts a new period on its next request
+func (g *notFoundGrace) prune(now time.Time) {
+	for flagKey, first := range g.requested {
+		if now.Sub(first) >= g.period {
+			delete(g.requested, flagKey)
+		}
+	}
+}
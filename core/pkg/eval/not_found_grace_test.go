@@ -0,0 +1,80 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const graceFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    }
+  }
+}`
+
+const graceNewFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    },
+    "newFlag": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on"
+    }
+  }
+}`
+
+func TestNotFoundGracePeriod(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, graceFlagConfig,
+		eval.WithClock(clock), eval.WithNotFoundGracePeriod(5*time.Second))
+	require.Nil(t, err)
+	resolve := func() error {
+		t.Helper()
+		_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "newFlag", &structpb.Struct{})
+		return err
+	}
+
+	require.EqualError(t, resolve(), model.ProviderNotReadyErrorCode, "the first request starts the grace period")
+	clock.Advance(5*time.Second - time.Millisecond)
+	require.EqualError(t, resolve(), model.ProviderNotReadyErrorCode, "the grace period isn't reset by requests")
+	clock.Advance(time.Millisecond)
+	require.EqualError(t, resolve(), model.FlagNotFoundErrorCode, "flags are not found past the grace period")
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "otherFlag", &structpb.Struct{})
+	require.EqualError(t, err, model.ProviderNotReadyErrorCode, "each flag has its own grace period")
+}
+
+func TestNotFoundGracePeriod_Synced(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithClock(clock), eval.WithNotFoundGracePeriod(5*time.Second))
+	_, _, err := je.SetState(sync.DataSync{FlagData: graceFlagConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+
+	_, _, _, _, err = je.ResolveBooleanValue(context.Background(), "", "newFlag", &structpb.Struct{})
+	require.EqualError(t, err, model.ProviderNotReadyErrorCode)
+
+	clock.Advance(time.Second)
+	_, _, err = je.SetState(sync.DataSync{FlagData: graceNewFlagConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	value, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "newFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.True(t, value, "flags synced within their grace period resolve")
+}
+
+func TestNotFoundGracePeriod_Disabled(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, graceFlagConfig, eval.WithNotFoundGracePeriod(0))
+	require.Nil(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "newFlag", &structpb.Struct{})
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+}
@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	greaterThanOperator = "greater_than"
+	lessThanOperator    = "less_than"
+	betweenOperator     = "between"
+
+	// bounds of the between operator, [ and ] are inclusive while ( and ) are exclusive
+	inclusiveBounds = "[]"
+)
+
+// greaterThan reports whether a value is greater than a threshold, e.g. {"greater_than": [{"var": "age"}, 30]}
+func (je *JSONEvaluator) greaterThan(values, _ interface{}) interface{} {
+	value, threshold, ok := je.parseComparisonData(greaterThanOperator, values)
+	if !ok {
+		return nil
+	}
+	return value > threshold
+}
+
+// lessThan reports whether a value is less than a threshold, e.g. {"less_than": [{"var": "spend"}, 100]}
+func (je *JSONEvaluator) lessThan(values, _ interface{}) interface{} {
+	value, threshold, ok := je.parseComparisonData(lessThanOperator, values)
+	if !ok {
+		return nil
+	}
+	return value < threshold
+}
+
+// between reports whether a value is within a range, bounds are inclusive unless specified otherwise,
+// e.g. {"between": [{"var": "age"}, 18, 65]} or {"between": [{"var": "age"}, 18, 65, "[)"]}
+func (je *JSONEvaluator) between(values, _ interface{}) interface{} {
+	value, low, high, bounds, err := parseBetweenData(values)
+	if err != nil {
+		if !errors.Is(err, errMissingValue) {
+			je.Logger.Error(fmt.Sprintf("parse %s data: %v", betweenOperator, err))
+		}
+		return nil
+	}
+
+	aboveLow := value > low || (bounds[0] == '[' && value == low)
+	belowHigh := value < high || (bounds[1] == ']' && value == high)
+	return aboveLow && belowHigh
+}
+
+// errMissingValue is returned when the compared value is absent from the evaluation context, which never matches
+var errMissingValue = errors.New("missing value")
+
+func (je *JSONEvaluator) parseComparisonData(operator string, values interface{}) (float64, float64, bool) {
+	valuesArray, ok := values.([]interface{})
+	if !ok {
+		je.Logger.Error(fmt.Sprintf("parse %s data: data is not an array", operator))
+		return 0, 0, false
+	}
+	if len(valuesArray) != 2 {
+		je.Logger.Error(fmt.Sprintf("parse %s data: data isn't length 2", operator))
+		return 0, 0, false
+	}
+
+	value, err := toNumber(valuesArray[0])
+	if err != nil {
+		if !errors.Is(err, errMissingValue) {
+			je.Logger.Error(fmt.Sprintf("parse %s data: value %v", operator, err))
+		}
+		return 0, 0, false
+	}
+	threshold, err := toNumber(valuesArray[1])
+	if err != nil {
+		je.Logger.Error(fmt.Sprintf("parse %s data: threshold %v", operator, err))
+		return 0, 0, false
+	}
+
+	return value, threshold, true
+}
+
+func parseBetweenData(values interface{}) (value, low, high float64, bounds string, err error) {
+	valuesArray, ok := values.([]interface{})
+	if !ok {
+		return 0, 0, 0, "", errors.New("data is not an array")
+	}
+	if len(valuesArray) != 3 && len(valuesArray) != 4 {
+		return 0, 0, 0, "", errors.New("data isn't length 3 or 4")
+	}
+
+	if value, err = toNumber(valuesArray[0]); err != nil {
+		return 0, 0, 0, "", err
+	}
+	if low, err = toNumber(valuesArray[1]); err != nil {
+		return 0, 0, 0, "", fmt.Errorf("lower bound %w", err)
+	}
+	if high, err = toNumber(valuesArray[2]); err != nil {
+		return 0, 0, 0, "", fmt.Errorf("upper bound %w", err)
+	}
+
+	bounds = inclusiveBounds
+	if len(valuesArray) == 4 {
+		bounds, ok = valuesArray[3].(string)
+		if !ok || len(bounds) != 2 || !strings.ContainsRune("[(", rune(bounds[0])) ||
+			!strings.ContainsRune("])", rune(bounds[1])) {
+			return 0, 0, 0, "", fmt.Errorf("bounds %v must be one of [], [), (] or ()", valuesArray[3])
+		}
+	}
+
+	return value, low, high, bounds, nil
+}
+
+// toNumber coerces numbers and numeric strings to float64, errors omit the value as it may come from the context
+func toNumber(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, errMissingValue
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, errors.New("of type string isn't numeric")
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("of type %T isn't numeric", v)
+	}
+}
@@ -0,0 +1,89 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func numericFlagConfig(targeting string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "numericFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [%s, "on", null] }
+    }
+  }
+}`, targeting)
+}
+
+func TestNumericEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		context   map[string]interface{}
+		expected  bool
+	}{
+		"greater than":                  {`{"greater_than": [{"var": "age"}, 30]}`, map[string]interface{}{"age": 31}, true},
+		"greater than boundary":         {`{"greater_than": [{"var": "age"}, 30]}`, map[string]interface{}{"age": 30}, false},
+		"greater than numeric string":   {`{"greater_than": [{"var": "age"}, 30]}`, map[string]interface{}{"age": "30.5"}, true},
+		"greater than string threshold": {`{"greater_than": [{"var": "age"}, "30"]}`, map[string]interface{}{"age": 31}, true},
+		"less than":                     {`{"less_than": [{"var": "spend"}, 100]}`, map[string]interface{}{"spend": 99.99}, true},
+		"less than boundary":            {`{"less_than": [{"var": "spend"}, 100]}`, map[string]interface{}{"spend": 100}, false},
+		"less than numeric string":      {`{"less_than": [{"var": "spend"}, 100]}`, map[string]interface{}{"spend": " 12 "}, true},
+		"between":                       {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{"age": 40}, true},
+		"between inclusive low":         {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{"age": 18}, true},
+		"between inclusive high":        {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{"age": 65}, true},
+		"between below":                 {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{"age": 17.9}, false},
+		"between exclusive high":        {`{"between": [{"var": "age"}, 18, 65, "[)"]}`, map[string]interface{}{"age": 65}, false},
+		"between exclusive low":         {`{"between": [{"var": "age"}, 18, 65, "(]"]}`, map[string]interface{}{"age": 18}, false},
+		"between exclusive":             {`{"between": [{"var": "age"}, 18, 65, "()"]}`, map[string]interface{}{"age": 18.5}, true},
+		"between numeric string":        {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{"age": "65"}, true},
+		"non numeric value":             {`{"greater_than": [{"var": "age"}, 30]}`, map[string]interface{}{"age": "old"}, false},
+		"boolean value":                 {`{"less_than": [{"var": "age"}, 30]}`, map[string]interface{}{"age": true}, false},
+		"missing value":                 {`{"between": [{"var": "age"}, 18, 65]}`, map[string]interface{}{}, false},
+		"invalid bounds":                {`{"between": [{"var": "age"}, 18, 65, "[["]}`, map[string]interface{}{"age": 40}, false},
+		"non numeric bound":             {`{"between": [{"var": "age"}, "young", 65]}`, map[string]interface{}{"age": 40}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, numericFlagConfig(tt.targeting))
+			require.Nil(t, err)
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "numericFlag", ctx)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, value)
+			if tt.expected {
+				assert.Equal(t, model.TargetingMatchReason, reason)
+			} else {
+				assert.Equal(t, model.DefaultReason, reason)
+			}
+		})
+	}
+}
+
+func TestNumericEvaluation_ErrorsOmitContextValues(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(
+		logger.NewLogger(zap.New(core), false), numericFlagConfig(`{"greater_than": [{"var": "email"}, 30]}`),
+	)
+	require.Nil(t, err)
+	ctx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "numericFlag", ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, logs.FilterMessage("parse greater_than data: value of type string isn't numeric").Len())
+}
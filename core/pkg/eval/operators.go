@@ -0,0 +1,179 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+)
+
+// TargetingDialect is the dialect of targeting rules, json-logic extended with the flagd operators
+const TargetingDialect = "json-logic"
+
+// EngineVersion is the version of the evaluation engine, incremented whenever operators are added or their semantics
+// change, so clients can tell whether a flagd evaluates a configuration
+const EngineVersion = 2
+
+const ruleOperator = "rule"
+
+// jsonLogicOperators are the operators of the json-logic implementation
+var jsonLogicOperators = []string{
+	"==", "===", "!=", "!==", ">", ">=", "<", "<=", "!", "!!", "or", "and", "?:", "if",
+	"in", "in_sorted", "cat", "substr", "%", "abs", "max", "min", "+", "-", "*", "/",
+	"merge", "missing", "missing_some", "some", "filter", "map", "reduce", "all", "none", "set", varOperator,
+}
+
+// flagdOperators are the flagd operators extending json-logic, keyed by name
+var flagdOperators = map[string]func(je *JSONEvaluator, values, data interface{}) interface{}{
+	fractionalEvaluationOperator: (*JSONEvaluator).fractionalEvaluation,
+	ruleOperator:                 (*JSONEvaluator).rule,
+	regexOperator:                (*JSONEvaluator).regex,
+	greaterThanOperator:          (*JSONEvaluator).greaterThan,
+	lessThanOperator:             (*JSONEvaluator).lessThan,
+	betweenOperator:              (*JSONEvaluator).between,
+	lowerOperator:                (*JSONEvaluator).lower,
+	upperOperator:                (*JSONEvaluator).upper,
+	trimOperator:                 (*JSONEvaluator).trim,
+	splitOperator:                (*JSONEvaluator).split,
+	substrOperator:               (*JSONEvaluator).substr,
+}
+
+// defaultEvaluator applies the operations of rules which aren't bound to an evaluator, e.g. the constant conditions
+// linted without evaluating a configuration, with the default options
+var defaultEvaluator = NewJSONEvaluator(nil, nil)
+
+// the operators are registered once, as json-logic holds them in a global map which isn't safe for concurrent use.
+// Each operation is applied by the evaluator its rule was parsed by, so evaluators with different options, e.g. the
+// canary or tenant evaluators, or an evaluator built while others are evaluating, don't share their state.
+func init() {
+	for name, operator := range flagdOperators {
+		operator := operator
+		jsonlogic.AddOperator(name, func(values, data interface{}) interface{} {
+			je, values := boundEvaluator(values)
+			return operator(je, values, data)
+		})
+	}
+	jsonlogic.AddOperator(coerceOperator, func(values, data interface{}) interface{} {
+		je, values := boundEvaluator(values)
+		return je.coerce(values, data)
+	})
+}
+
+// evaluatorBinding binds an operation of a parsed rule to the evaluator applying it, as the first of its values
+type evaluatorBinding struct {
+	je *JSONEvaluator
+	// spread tells whether the values of the operation follow the binding, rather than being its second value
+	spread bool
+}
+
+// bindRule returns a copy of the parsed rule whose flagd operations are bound to the evaluator, so wherever
+// json-logic applies them, e.g. to the items iterated by the some or map operations, they apply its options. The
+// parsed rule is left unbound for the walkers of its operations.
+func (je *JSONEvaluator) bindRule(rule interface{}) interface{} {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		// json-logic applies maps of several keys as values
+		if len(r) != 1 {
+			return r
+		}
+		bound := make(map[string]interface{}, 1)
+		for operator, values := range r {
+			values = je.bindRule(values)
+			if _, ok := flagdOperators[operator]; ok || operator == coerceOperator {
+				if args, ok := values.([]interface{}); ok {
+					if operator == regexOperator {
+						je.bindPattern(args)
+					}
+					values = append([]interface{}{evaluatorBinding{je: je, spread: true}}, args...)
+				} else {
+					values = []interface{}{evaluatorBinding{je: je}, values}
+				}
+			}
+			bound[operator] = values
+		}
+		return bound
+	case []interface{}:
+		bound := make([]interface{}, len(r))
+		for i, item := range r {
+			bound[i] = je.bindRule(item)
+		}
+		return bound
+	}
+	return rule
+}
+
+// boundEvaluator returns the evaluator an operation is bound to along with the values of the operation, the default
+// evaluator applies operations which aren't bound
+func boundEvaluator(values interface{}) (*JSONEvaluator, interface{}) {
+	args, ok := values.([]interface{})
+	if !ok || len(args) == 0 {
+		return defaultEvaluator, values
+	}
+	binding, ok := args[0].(evaluatorBinding)
+	if !ok {
+		return defaultEvaluator, values
+	}
+	if binding.spread {
+		return binding.je, args[1:]
+	}
+	return binding.je, args[1]
+}
+
+// SupportedOperators returns the sorted operators targeting rules may use
+func SupportedOperators() []string {
+	operators := append([]string{}, jsonLogicOperators...)
+	for operator := range flagdOperators {
+		// flagd operators may replace json-logic operators, e.g. substr
+		if !containsOperator(jsonLogicOperators, operator) {
+			operators = append(operators, operator)
+		}
+	}
+	sort.Strings(operators)
+	return operators
+}
+
+func containsOperator(operators []string, operator string) bool {
+	for _, o := range operators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOperators fails if the rule uses operators which aren't supported, naming every unsupported operator
+func validateOperators(rule interface{}) error {
+	// the coercion of compared values is added to the parsed rules of evaluators coercing context values
+	supported := map[string]struct{}{coerceOperator: {}}
+	for _, operator := range SupportedOperators() {
+		supported[operator] = struct{}{}
+	}
+	unsupported := map[string]struct{}{}
+	collectUnsupportedOperators(rule, supported, unsupported)
+	if len(unsupported) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(unsupported))
+	for operator := range unsupported {
+		names = append(names, fmt.Sprintf("'%s'", operator))
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unsupported operators: %s", strings.Join(names, ", "))
+}
+
+func collectUnsupportedOperators(rule interface{}, supported, unsupported map[string]struct{}) {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for op, args := range r {
+			if _, ok := supported[op]; !ok {
+				unsupported[op] = struct{}{}
+			}
+			collectUnsupportedOperators(args, supported, unsupported)
+		}
+	case []interface{}:
+		for _, v := range r {
+			collectUnsupportedOperators(v, supported, unsupported)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package eval_test
+
+import (
+	"context"
+	gosync "sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// bucketedFlagConfig buckets the email hello into blue by the xxh3 hash, the default, and into red by murmur3
+const bucketedFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 20], ["blue", 80]] }
+    }
+  }
+}`
+
+// bucketedVariant returns the variant the evaluator buckets the email hello into
+func bucketedVariant(t *testing.T, evaluator eval.IEvaluator) string {
+	t.Helper()
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "hello"})
+	require.Nil(t, err)
+	_, variant, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	require.Nil(t, err)
+	return variant
+}
+
+func TestSupportedOperators(t *testing.T) {
+	operators := eval.SupportedOperators()
+	require.IsIncreasing(t, operators)
+	for _, operator := range []string{"==", "if", "var", "fractionalEvaluation", "rule", "regex", "between"} {
+		require.Contains(t, operators, operator)
+	}
+}
+
+func TestUnsupportedOperators(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          {
+            "and": [
+              { "sem_ver": [{ "var": "version" }, ">=", "1.0.0"] },
+              { "starts_with": [{ "var": "email" }, "x"] }
+            ]
+          },
+          "blue",
+          { "sem_ver": [{ "var": "version" }, "<", "0.1.0"] }
+        ]
+      }
+    }
+  }
+}`)
+	require.EqualError(t, err, "targeting of flag: 'headerColor': unsupported operators: 'sem_ver', 'starts_with'")
+
+	_, err = eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "regex": [{ "var": "email" }, "@faas\\.com$"] }, "blue", "red"] }
+    }
+  }
+}`)
+	require.Nil(t, err)
+}
+
+func TestOperators_ConcurrentConstruction(t *testing.T) {
+	live, err := eval.NewJSONEvaluatorFromConfig(nil, bucketedFlagConfig)
+	require.Nil(t, err)
+	require.Equal(t, "blue", bucketedVariant(t, live))
+
+	var wg gosync.WaitGroup
+	mismatches := make(chan string, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if variant := bucketedVariant(t, live); variant != "blue" {
+					mismatches <- variant
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		next, err := eval.NewJSONEvaluatorFromConfig(nil, bucketedFlagConfig,
+			eval.WithBucketingHash(eval.BucketingHashMurmur3))
+		require.Nil(t, err)
+		require.Equal(t, "red", bucketedVariant(t, next))
+	}
+	wg.Wait()
+	close(mismatches)
+	for variant := range mismatches {
+		t.Errorf("the live evaluator resolved: %s with the options of an evaluator built while it was evaluating", variant)
+	}
+	require.Equal(t, "blue", bucketedVariant(t, live))
+}
@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// OverrideTokenContextKey is the evaluation context key holding the signed token pinning flags to variants
+const OverrideTokenContextKey = "overrideToken"
+
+// WithOverrideTokenSecret verifies the override tokens of evaluation contexts with the secret, evaluations holding a
+// valid token resolve the variants it pins with the OVERRIDE reason, e.g. for testers to pin themselves into
+// variants. Tokens are HS256 JWTs whose flags claim maps flag keys to variants and whose exp claim is required.
+// Override tokens are ignored when the secret is empty.
+func WithOverrideTokenSecret(secret string) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if secret != "" {
+			je.overrideSecret = []byte(secret)
+		}
+	}
+}
+
+// overrideClaims are the claims of an override token
+type overrideClaims struct {
+	// Flags maps the pinned flag keys to their variant
+	Flags map[string]string `json:"flags"`
+	// Exp is the unix time the token expires at
+	Exp *int64 `json:"exp"`
+}
+
+// pinnedVariant returns the variant the override token of the context pins the flag to, false if the token is
+// missing, invalid, expired, or doesn't pin the flag to one of its variants. Invalid tokens are ignored, the
+// evaluation proceeds as without token. Tokens are ignored unless the override level of the fallback chain is enabled.
+func (je *JSONEvaluator) pinnedVariant(
+	reqID string, flagKey string, flag model.Flag, context *structpb.Struct,
+) (string, bool) {
+	if je.overrideSecret == nil || !je.fallsBackTo(FallbackOverride) {
+		return "", false
+	}
+	token := context.GetFields()[OverrideTokenContextKey].GetStringValue()
+	if token == "" {
+		return "", false
+	}
+	claims, err := verifyOverrideToken(token, je.overrideSecret, je.clock.Now())
+	if err != nil {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("ignoring override token: %v", err))
+		return "", false
+	}
+	variant, ok := claims.Flags[flagKey]
+	if !ok {
+		return "", false
+	}
+	if _, ok := flag.Variants[variant]; !ok {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("ignoring override of flag: %s, variant: '%s' is not defined",
+			flagKey, variant))
+		return "", false
+	}
+	return variant, true
+}
+
+// verifyOverrideToken returns the claims of the HS256 JWT signed with the secret, an error if its signature doesn't
+// match or it expired
+func verifyOverrideToken(token string, secret []byte, now time.Time) (overrideClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return overrideClaims{}, errors.New("token isn't a signed JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return overrideClaims{}, fmt.Errorf("header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return overrideClaims{}, fmt.Errorf("unsupported signing algorithm: '%s'", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return overrideClaims{}, fmt.Errorf("signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return overrideClaims{}, errors.New("signature doesn't match")
+	}
+	var claims overrideClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return overrideClaims{}, fmt.Errorf("claims: %w", err)
+	}
+	if claims.Exp == nil {
+		return overrideClaims{}, errors.New("token has no expiry")
+	}
+	if !now.Before(time.Unix(*claims.Exp, 0)) {
+		return overrideClaims{}, errors.New("token expired")
+	}
+	return claims, nil
+}
+
+// decodeSegment decodes a base64url encoded json segment of a JWT
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
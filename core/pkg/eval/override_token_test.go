@@ -0,0 +1,126 @@
+package eval_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const overrideSecret = "qa-signing-secret"
+
+const overrideFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "green", null] }
+    },
+    "newCheckout": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    }
+  }
+}`
+
+// signOverrideToken returns the HS256 JWT of the claims signed with the secret
+func signOverrideToken(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	require.Nil(t, err)
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestOverrideToken(t *testing.T) {
+	now := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, overrideFlagConfig,
+		eval.WithClock(&fakeClock{now: now}), eval.WithOverrideTokenSecret(overrideSecret))
+	require.Nil(t, err)
+	pins := map[string]interface{}{"headerColor": "blue", "newCheckout": "on"}
+	valid := signOverrideToken(t, overrideSecret, map[string]interface{}{"flags": pins, "exp": now.Add(time.Hour).Unix()})
+
+	resolve := func(token string) (string, string) {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "premium", eval.OverrideTokenContextKey: token})
+		require.Nil(t, err)
+		_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		return variant, reason
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		variant, reason := resolve(valid)
+		require.Equal(t, "blue", variant)
+		require.Equal(t, model.OverrideReason, reason)
+
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{eval.OverrideTokenContextKey: valid})
+		require.Nil(t, err)
+		value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "newCheckout", evalCtx)
+		require.Nil(t, err)
+		require.True(t, value)
+		require.Equal(t, model.OverrideReason, reason)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		parts := strings.Split(valid, ".")
+		payload, err := json.Marshal(map[string]interface{}{
+			"flags": map[string]interface{}{"headerColor": "red"}, "exp": now.Add(time.Hour).Unix(),
+		})
+		require.Nil(t, err)
+		tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+		variant, reason := resolve(tampered)
+		require.Equal(t, "green", variant, "tampered tokens should be ignored")
+		require.Equal(t, model.TargetingMatchReason, reason)
+
+		variant, _ = resolve(signOverrideToken(t, "another-secret", map[string]interface{}{
+			"flags": pins, "exp": now.Add(time.Hour).Unix(),
+		}))
+		require.Equal(t, "green", variant, "tokens signed with another secret should be ignored")
+
+		unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+		variant, _ = resolve(unsigned)
+		require.Equal(t, "green", variant, "unsigned tokens should be ignored")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		variant, reason := resolve(signOverrideToken(t, overrideSecret, map[string]interface{}{
+			"flags": pins, "exp": now.Add(-time.Minute).Unix(),
+		}))
+		require.Equal(t, "green", variant, "expired tokens should be ignored")
+		require.Equal(t, model.TargetingMatchReason, reason)
+
+		variant, _ = resolve(signOverrideToken(t, overrideSecret, map[string]interface{}{"flags": pins}))
+		require.Equal(t, "green", variant, "tokens without expiry should be ignored")
+	})
+
+	t.Run("undefined variant", func(t *testing.T) {
+		variant, _ := resolve(signOverrideToken(t, overrideSecret, map[string]interface{}{
+			"flags": map[string]interface{}{"headerColor": "purple"}, "exp": now.Add(time.Hour).Unix(),
+		}))
+		require.Equal(t, "green", variant)
+	})
+
+	t.Run("without secret", func(t *testing.T) {
+		evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, overrideFlagConfig, eval.WithClock(&fakeClock{now: now}))
+		require.Nil(t, err)
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "premium", eval.OverrideTokenContextKey: valid})
+		require.Nil(t, err)
+		_, variant, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		require.Equal(t, "green", variant, "override tokens shouldn't be verified without a secret")
+	})
+}
@@ -0,0 +1,197 @@
+package eval
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	msync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// PinnedFlags is implemented by evaluators keeping the definition of pinned flags across configuration reloads
+type PinnedFlags interface {
+	// PinnedFlags returns the pinned flags sorted by key
+	PinnedFlags() []PinnedFlag
+	// ApplyPinnedFlag applies the definition of a pinned flag proposed by the latest reload of its source, returning
+	// the notifications of the change and whether a definition was pending
+	ApplyPinnedFlag(flagKey string) (map[string]interface{}, bool)
+}
+
+// PinnedFlag is a flag whose definition is only changed by an explicit action
+type PinnedFlag struct {
+	FlagKey string `json:"flagKey"`
+	// Pending is set when a reload proposed another definition of the flag, including its removal
+	Pending bool `json:"pending"`
+}
+
+// WithPinnedFlags keeps the stored definition of the provided flags when a reload of their source changes or removes
+// them, e.g. kill switches which may only change through a controlled path. The proposed definition is applied by
+// ApplyPinnedFlag. Pinned flags which aren't stored yet are loaded from their first definition.
+func WithPinnedFlags(keys []string) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if len(keys) == 0 {
+			return
+		}
+		je.pinned = &pinnedFlags{flags: make(map[string]*pinnedFlag, len(keys))}
+		for _, key := range keys {
+			je.pinned.flags[key] = &pinnedFlag{}
+		}
+	}
+}
+
+// pinnedFlag holds the definition of a pinned flag proposed by the latest reload of its source, while pending
+type pinnedFlag struct {
+	proposed model.Flag
+	// present is unset when the proposed change removes the flag
+	present bool
+	pending bool
+	source  string
+}
+
+// pinnedFlags tracks the proposed definitions of the pinned flags, its lock is held while the store is updated
+type pinnedFlags struct {
+	mu    msync.Mutex
+	flags map[string]*pinnedFlag
+}
+
+// PinnedFlags returns the pinned flags sorted by key, nil if no flag is pinned
+func (je *JSONEvaluator) PinnedFlags() []PinnedFlag {
+	if je.pinned == nil {
+		return nil
+	}
+	je.pinned.mu.Lock()
+	defer je.pinned.mu.Unlock()
+	pinned := make([]PinnedFlag, 0, len(je.pinned.flags))
+	for flagKey, flag := range je.pinned.flags {
+		pinned = append(pinned, PinnedFlag{FlagKey: flagKey, Pending: flag.pending})
+	}
+	sort.Slice(pinned, func(i, j int) bool { return pinned[i].FlagKey < pinned[j].FlagKey })
+	return pinned
+}
+
+// ApplyPinnedFlag stores the definition of the pinned flag proposed by the latest reload of its source, or removes
+// the flag if the reload did
+func (je *JSONEvaluator) ApplyPinnedFlag(flagKey string) (map[string]interface{}, bool) {
+	if je.pinned == nil {
+		return nil, false
+	}
+	je.reloads.Lock()
+	defer je.reloads.Unlock()
+	je.pinned.mu.Lock()
+	defer je.pinned.mu.Unlock()
+	flag, ok := je.pinned.flags[flagKey]
+	if !ok || !flag.pending {
+		return nil, false
+	}
+	flag.pending = false
+	je.Logger.Info(fmt.Sprintf("applying the pending definition of pinned flag: %s", flagKey))
+	if !flag.present {
+		return je.store.DeleteFlags(je.Logger, flag.source, map[string]model.Flag{flagKey: {}}), true
+	}
+	update := map[string]model.Flag{flagKey: flag.proposed}
+	if _, ok := je.store.Get(flagKey); ok {
+		return je.store.Update(je.Logger, flag.source, update), true
+	}
+	return je.store.Add(je.Logger, flag.source, update), true
+}
+
+// pinFlags replaces the changes of the update to the pinned flags owned by the source with their stored definition,
+// recording the proposed definition until it's applied. Flags missing from an update of the whole configuration of
+// the source are proposed removals. The flags left to delete by a delete update are returned, along with whether
+// any is left, as deleting no flag in particular deletes every flag of the source.
+func (je *JSONEvaluator) pinFlags(
+	source string, syncType sync.Type, flags map[string]model.Flag,
+) (map[string]model.Flag, bool) {
+	if syncType == sync.DELETE && len(flags) == 0 {
+		flags = map[string]model.Flag{}
+		for flagKey, flag := range je.store.GetAll() {
+			if flag.Source == source {
+				flags[flagKey] = model.Flag{}
+			}
+		}
+	}
+
+	for flagKey, pinned := range je.pinned.flags {
+		stored, isStored := je.store.Get(flagKey)
+		if !isStored || stored.Source != source {
+			// pinned flags are loaded from their first definition, flags of other sources aren't changed by the update
+			continue
+		}
+		proposed, present := flags[flagKey]
+		switch syncType {
+		case sync.ALL:
+		case sync.DELETE:
+			if !present {
+				continue
+			}
+			present = false
+		default:
+			if !present {
+				continue
+			}
+		}
+
+		proposed.Source = source
+		if present && reflect.DeepEqual(proposed, stored) {
+			// the source proposes the stored definition again, discarding any pending change
+			pinned.pending = false
+			continue
+		}
+		pinned.proposed, pinned.present, pinned.pending, pinned.source = proposed, present, true, source
+		je.Logger.Warn(fmt.Sprintf("reload of source: %s attempted to change pinned flag: %s, keeping its stored "+
+			"definition until the change is applied", source, flagKey))
+		if syncType == sync.DELETE {
+			delete(flags, flagKey)
+		} else {
+			flags[flagKey] = stored
+		}
+	}
+	return flags, syncType != sync.DELETE || len(flags) > 0
+}
+
+// pinnedFlagsOf returns the pinned flags of the evaluators, pending if any of them has a pending definition
+func pinnedFlagsOf(evaluators ...IEvaluator) []PinnedFlag {
+	pending := map[string]bool{}
+	for _, evaluator := range evaluators {
+		pinned, ok := evaluator.(PinnedFlags)
+		if !ok {
+			continue
+		}
+		for _, flag := range pinned.PinnedFlags() {
+			pending[flag.FlagKey] = pending[flag.FlagKey] || flag.Pending
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	flags := make([]PinnedFlag, 0, len(pending))
+	for flagKey, isPending := range pending {
+		flags = append(flags, PinnedFlag{FlagKey: flagKey, Pending: isPending})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].FlagKey < flags[j].FlagKey })
+	return flags
+}
+
+// applyPinnedFlag applies the pending definition of the pinned flag of each of the evaluators, merging their
+// notifications
+func applyPinnedFlag(flagKey string, evaluators ...IEvaluator) (map[string]interface{}, bool) {
+	notifications := map[string]interface{}{}
+	applied := false
+	for _, evaluator := range evaluators {
+		pinned, ok := evaluator.(PinnedFlags)
+		if !ok {
+			continue
+		}
+		changes, ok := pinned.ApplyPinnedFlag(flagKey)
+		if !ok {
+			continue
+		}
+		applied = true
+		for key, notification := range changes {
+			notifications[key] = notification
+		}
+	}
+	return notifications, applied
+}
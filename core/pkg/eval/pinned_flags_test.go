@@ -0,0 +1,130 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const pinnedFlagConfig = `{
+  "flags": {
+    "killSwitch": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "%s"
+    },
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "%s"
+    }
+  }
+}`
+
+const pinnedColorOnlyConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red"
+    }
+  }
+}`
+
+func TestPinnedFlags(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	je := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), store.NewFlags(),
+		eval.WithPinnedFlags([]string{"killSwitch"}))
+	setState := func(killSwitch, headerColor string) {
+		t.Helper()
+		_, _, err := je.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(pinnedFlagConfig, killSwitch, headerColor), Source: "flags.json", Type: sync.ALL,
+		})
+		require.Nil(t, err)
+	}
+	killSwitch := func() bool {
+		t.Helper()
+		value, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+		require.Nil(t, err)
+		return value
+	}
+	headerColor := func() string {
+		t.Helper()
+		value, _, _, _, err := je.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+		require.Nil(t, err)
+		return value
+	}
+
+	setState("off", "red")
+	require.False(t, killSwitch(), "pinned flags are loaded from their first definition")
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch"}}, je.PinnedFlags())
+
+	setState("on", "blue")
+	require.False(t, killSwitch(), "pinned flags must survive reloads changing them")
+	require.Equal(t, "#0000FF", headerColor(), "flags which aren't pinned must be reloaded")
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch", Pending: true}}, je.PinnedFlags())
+	require.Equal(t, 1, logs.FilterMessage("reload of source: flags.json attempted to change pinned flag: "+
+		"killSwitch, keeping its stored definition until the change is applied").Len())
+
+	notifications, applied := je.ApplyPinnedFlag("killSwitch")
+	require.True(t, applied)
+	require.Contains(t, notifications, "killSwitch")
+	require.True(t, killSwitch(), "the pending definition must be applied")
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch"}}, je.PinnedFlags())
+
+	_, applied = je.ApplyPinnedFlag("killSwitch")
+	require.False(t, applied, "no definition is pending once applied")
+	_, applied = je.ApplyPinnedFlag("headerColor")
+	require.False(t, applied, "flags which aren't pinned can't be applied")
+
+	setState("off", "blue")
+	setState("on", "blue")
+	require.True(t, killSwitch())
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch"}}, je.PinnedFlags(),
+		"reloading the stored definition discards the pending change")
+}
+
+func TestPinnedFlags_Removal(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithPinnedFlags([]string{"killSwitch"}))
+	_, _, err := je.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(pinnedFlagConfig, "on", "red"), Source: "flags.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+	resolve := func() error {
+		t.Helper()
+		_, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "killSwitch", &structpb.Struct{})
+		return err
+	}
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: pinnedColorOnlyConfig, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	require.Nil(t, resolve(), "pinned flags missing from a reload must be kept")
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{"flags": {}}`, Source: "flags.json", Type: sync.DELETE})
+	require.Nil(t, err)
+	require.Nil(t, resolve(), "pinned flags must survive the deletion of their source")
+	_, _, _, _, err = je.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+	require.NotNil(t, err, "flags which aren't pinned must be deleted")
+	require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch", Pending: true}}, je.PinnedFlags())
+
+	_, applied := je.ApplyPinnedFlag("killSwitch")
+	require.True(t, applied)
+	require.NotNil(t, resolve(), "the pending removal must be applied")
+}
+
+func TestPinnedFlags_Disabled(t *testing.T) {
+	je := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	require.Nil(t, je.PinnedFlags())
+	_, applied := je.ApplyPinnedFlag("killSwitch")
+	require.False(t, applied)
+}
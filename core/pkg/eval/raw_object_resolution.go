@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// RawObjectResolver is implemented by evaluators able to resolve object flags along with the raw value of their
+// resolved variant, before the transforms of the resolved value
+type RawObjectResolver interface {
+	ResolveRawObjectValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+	) (RawObjectResolution, error)
+}
+
+// RawObjectResolution is the resolution of an object flag, Value being the resolved value and RawValue the value of
+// the variant as configured, e.g. with the fields masked from Value
+type RawObjectResolution struct {
+	RawValue map[string]any
+	Value    map[string]any
+	Variant  string
+	Reason   string
+	Metadata map[string]interface{}
+}
+
+// ResolveRawObjectValue resolves an object flag as ResolveObjectValue does, returning the configured value of the
+// resolved variant alongside the resolved value
+func (je *JSONEvaluator) ResolveRawObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (RawObjectResolution, error) {
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating the raw value of object flag: %s", flagKey))
+	flagKey = je.namespaceKey(reqID, flagKey)
+	flag, _ := je.store.Get(flagKey)
+	raw, variant, reason, metadata, err := resolve[map[string]any](
+		ctx, reqID, flagKey, context, je.evaluateVariant, flag.Variants)
+	return RawObjectResolution{
+		RawValue: raw,
+		Value:    maskFields(flag, raw),
+		Variant:  variant,
+		Reason:   reason,
+		Metadata: metadata,
+	}, err
+}
+
+// resolveRawObjectValue resolves the raw value of an object flag with the evaluator, if it's able to
+func resolveRawObjectValue(
+	ctx context.Context, evaluator IEvaluator, reqID string, flagKey string, context *structpb.Struct,
+) (RawObjectResolution, error) {
+	resolver, ok := evaluator.(RawObjectResolver)
+	if !ok {
+		return RawObjectResolution{}, errors.New("the evaluator can't resolve raw object values")
+	}
+	return resolver.ResolveRawObjectValue(ctx, reqID, flagKey, context)
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	gosync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ReadReplicas is implemented by evaluators able to serve evaluations from a read replica of their flags
+type ReadReplicas interface {
+	ReadReplica(maxStaleness time.Duration) *ReadReplica
+}
+
+// ReadReplica serves evaluations from a snapshot of the flags of its evaluator rather than its live store, so heavy
+// bulk and analysis queries don't contend with the evaluations of the hot path. The snapshot is refreshed lazily, by
+// the first evaluation once it's older than the maximum staleness, so the replica lags the live flags by up to the
+// maximum staleness after an update, plus the time until its next evaluation. The replica is read-only.
+type ReadReplica struct {
+	live         *JSONEvaluator
+	maxStaleness time.Duration
+
+	mx        gosync.Mutex
+	snapshot  *JSONEvaluator
+	refreshed time.Time
+}
+
+// ReadReplica returns a read replica of the flags of the evaluator, refreshed once older than the maximum staleness.
+// Evaluations of the replica are evaluated with the options of the evaluator, by their own rule cache, rule
+// statistics and circuit breakers.
+func (je *JSONEvaluator) ReadReplica(maxStaleness time.Duration) *ReadReplica {
+	return &ReadReplica{live: je, maxStaleness: maxStaleness}
+}
+
+// current returns the snapshot of the flags, refreshing it first if it's older than the maximum staleness. Snapshots
+// aren't changed once taken, evaluations in flight keep evaluating the snapshot they started with.
+func (r *ReadReplica) current() *JSONEvaluator {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	now := r.live.clock.Now()
+	if r.snapshot != nil && now.Sub(r.refreshed) < r.maxStaleness {
+		return r.snapshot
+	}
+	flags := store.NewFlags()
+	flags.DuplicateKeys = r.live.store.DuplicateKeys
+	for key, flag := range r.live.store.GetAll() {
+		flags.Set(key, flag)
+	}
+	snapshot := r.live.comparisonEvaluator(flags)
+	snapshot.loaded.Store(r.live.loaded.Load())
+	r.snapshot = snapshot
+	r.refreshed = now
+	return snapshot
+}
+
+// Refreshed returns the time the snapshot of the flags was taken, zero until the replica's first evaluation
+func (r *ReadReplica) Refreshed() time.Time {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.refreshed
+}
+
+// GetState returns the configuration of the snapshot of the flags
+func (r *ReadReplica) GetState() (string, error) {
+	return r.current().GetState()
+}
+
+// SetState fails, the replica is updated from the flags of its evaluator
+func (r *ReadReplica) SetState(sync.DataSync) (map[string]interface{}, bool, error) {
+	return nil, false, errors.New("read replicas are read-only, the configuration is set on their evaluator")
+}
+
+func (r *ReadReplica) ResolveBooleanValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (bool, string, string, map[string]interface{}, error) {
+	return r.current().ResolveBooleanValue(ctx, reqID, flagKey, context)
+}
+
+func (r *ReadReplica) ResolveStringValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (string, string, string, map[string]interface{}, error) {
+	return r.current().ResolveStringValue(ctx, reqID, flagKey, context)
+}
+
+func (r *ReadReplica) ResolveIntValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (int64, string, string, map[string]interface{}, error) {
+	return r.current().ResolveIntValue(ctx, reqID, flagKey, context)
+}
+
+func (r *ReadReplica) ResolveFloatValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (float64, string, string, map[string]interface{}, error) {
+	return r.current().ResolveFloatValue(ctx, reqID, flagKey, context)
+}
+
+func (r *ReadReplica) ResolveObjectValue(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (map[string]any, string, string, map[string]interface{}, error) {
+	return r.current().ResolveObjectValue(ctx, reqID, flagKey, context)
+}
+
+func (r *ReadReplica) ResolveAllValues(ctx context.Context, reqID string, context *structpb.Struct) []AnyValue {
+	return r.current().ResolveAllValues(ctx, reqID, context)
+}
+
+// ResolveAllValuesWithErrors resolves every flag of the snapshot, along with the errors of the flags which failed
+func (r *ReadReplica) ResolveAllValuesWithErrors(ctx context.Context, reqID string, context *structpb.Struct) (
+	[]AnyValue, []FlagError,
+) {
+	return r.current().ResolveAllValuesWithErrors(ctx, reqID, context)
+}
+
+// FlagType returns the type of the flag of the snapshot
+func (r *ReadReplica) FlagType(flagKey string, context *structpb.Struct) (string, bool) {
+	return r.current().FlagType(flagKey, context)
+}
+
+// EvaluateWhatIf evaluates the flag of the snapshot as if the values of the overridden variants were live
+func (r *ReadReplica) EvaluateWhatIf(
+	reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	return r.current().EvaluateWhatIf(reqID, flagKey, overrides, context)
+}
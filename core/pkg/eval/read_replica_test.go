@@ -0,0 +1,74 @@
+package eval_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	replicaFlagsV1 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "off"}}}`
+	replicaFlagsV2 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "on"}, "newFlag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`
+)
+
+func TestReadReplica_RefreshedOnceStale(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)}
+	live, err := eval.NewJSONEvaluatorFromConfig(nil, replicaFlagsV1, eval.WithClock(clock))
+	require.Nil(t, err)
+	replica := live.ReadReplica(5 * time.Second)
+	resolve := func(evaluator eval.IEvaluator) bool {
+		t.Helper()
+		value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "checkout", &structpb.Struct{})
+		require.Nil(t, err)
+		return value
+	}
+
+	require.True(t, replica.Refreshed().IsZero(), "the snapshot should be taken by the first evaluation")
+	require.False(t, resolve(replica))
+	require.Equal(t, clock.now, replica.Refreshed())
+
+	_, _, err = live.SetState(sync.DataSync{FlagData: replicaFlagsV2, Source: "file:flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	require.True(t, resolve(live))
+	clock.Advance(4 * time.Second)
+	require.False(t, resolve(replica), "the replica shouldn't reflect the update within its staleness")
+	require.Len(t, replica.ResolveAllValues(context.Background(), "", &structpb.Struct{}), 1)
+	_, _, _, _, err = replica.ResolveBooleanValue(context.Background(), "", "newFlag", &structpb.Struct{})
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+
+	clock.Advance(time.Second)
+	require.True(t, resolve(replica), "the replica should be refreshed once stale")
+	require.Len(t, replica.ResolveAllValues(context.Background(), "", &structpb.Struct{}), 2)
+	require.Equal(t, clock.now, replica.Refreshed())
+}
+
+func TestReadReplica_ReadOnly(t *testing.T) {
+	live, err := eval.NewJSONEvaluatorFromConfig(nil, replicaFlagsV1)
+	require.Nil(t, err)
+	replica := live.ReadReplica(time.Minute)
+
+	_, _, err = replica.SetState(sync.DataSync{FlagData: replicaFlagsV2, Source: "file:flags.json", Type: sync.ALL})
+	require.NotNil(t, err)
+	state, err := live.GetState()
+	require.Nil(t, err)
+	replicaState, err := replica.GetState()
+	require.Nil(t, err)
+	require.JSONEq(t, state, replicaState)
+
+	flagType, ok := replica.FlagType("checkout", nil)
+	require.True(t, ok)
+	require.Equal(t, eval.BooleanFlagType, flagType)
+	whatIf, err := replica.EvaluateWhatIf("", "checkout", map[string]json.RawMessage{"off": json.RawMessage("true")},
+		&structpb.Struct{})
+	require.Nil(t, err)
+	require.Equal(t, true, whatIf.Value)
+}
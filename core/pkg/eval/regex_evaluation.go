@@ -0,0 +1,149 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+)
+
+const (
+	regexOperator = "regex"
+	// maxRegexPatternLength and maxRegexProgramSize bound the cost of compiling and matching targeting patterns
+	maxRegexPatternLength = 1024
+	maxRegexProgramSize   = 10000
+)
+
+// regexCache holds the compiled literal patterns of targeting rules, keyed by pattern
+type regexCache struct {
+	mx       sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}
+
+func (c *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mx.RLock()
+	re, ok := c.patterns[pattern]
+	c.mx.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.patterns == nil {
+		c.patterns = map[string]*regexp.Regexp{}
+	}
+	c.patterns[pattern] = re
+	return re, nil
+}
+
+// regex reports whether a string matches a regular expression, e.g. {"regex": [{"var": "userAgent"}, "Firefox/\\d+"]}
+func (je *JSONEvaluator) regex(values, _ interface{}) interface{} {
+	value, pattern, err := parseRegexData(values)
+	if err != nil {
+		je.Logger.Error(fmt.Sprintf("parse regex data: %v", err))
+		return nil
+	}
+
+	re, ok := pattern.(*regexp.Regexp)
+	if !ok {
+		// patterns computed by the evaluation, e.g. read from the context, aren't cached as their number isn't bounded
+		if re, err = compileRegex(pattern.(string)); err != nil {
+			je.Logger.Error(fmt.Sprintf("compile regex: %v", err))
+			return nil
+		}
+	}
+
+	return re.MatchString(value)
+}
+
+// parseRegexData returns the value and the pattern of the regex data, the pattern being either a string or the
+// compiled literal pattern of the rule
+func parseRegexData(values interface{}) (string, interface{}, error) {
+	valuesArray, ok := values.([]interface{})
+	if !ok {
+		return "", nil, errors.New("regex data is not an array")
+	}
+	if len(valuesArray) != 2 {
+		return "", nil, errors.New("regex data isn't length 2")
+	}
+
+	// a missing or non string context value never matches
+	value, _ := valuesArray[0].(string)
+
+	switch pattern := valuesArray[1].(type) {
+	case string, *regexp.Regexp:
+		return value, pattern, nil
+	default:
+		return "", nil, errors.New("second element of regex data isn't of type string")
+	}
+}
+
+// bindPattern replaces the literal pattern of the values of a regex operation of a rule being bound by the compiled
+// pattern, so each rule holds its patterns compiled. Invalid patterns are left to fail the evaluation.
+func (je *JSONEvaluator) bindPattern(values []interface{}) {
+	if len(values) != 2 {
+		return
+	}
+	pattern, ok := values[1].(string)
+	if !ok {
+		return
+	}
+	if re, err := je.patterns.compile(pattern); err == nil {
+		values[1] = re
+	}
+}
+
+// compileRegex compiles a pattern, rejecting patterns exceeding the length or program size limits. Patterns are
+// matched by the linear time RE2 engine, the limits prevent patterns such as nested repetitions exhausting memory.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxRegexPatternLength {
+		return nil, fmt.Errorf("pattern exceeds the maximum length of %d", maxRegexPatternLength)
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+	if len(prog.Inst) > maxRegexProgramSize {
+		return nil, fmt.Errorf("pattern '%s' exceeds the maximum complexity", pattern)
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// validateRegexPatterns compiles the literal patterns of the regex operators within a parsed targeting rule
+func (je *JSONEvaluator) validateRegexPatterns(rule interface{}) error {
+	switch r := rule.(type) {
+	case map[string]interface{}:
+		for op, args := range r {
+			if op == regexOperator {
+				if argsArray, ok := args.([]interface{}); ok && len(argsArray) == 2 {
+					if pattern, ok := argsArray[1].(string); ok {
+						if _, err := je.patterns.compile(pattern); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			if err := je.validateRegexPatterns(args); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, v := range r {
+			if err := je.validateRegexPatterns(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
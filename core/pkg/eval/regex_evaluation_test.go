@@ -0,0 +1,143 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func regexFlagConfig(pattern string) string {
+	return fmt.Sprintf(`{
+  "flags": {
+    "browserFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": {
+        "if": [{ "regex": [{ "var": "userAgent" }, %q] }, "on", "off"]
+      }
+    }
+  }
+}`, pattern)
+}
+
+func TestRegexEvaluation(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: regexFlagConfig(`Firefox/\d+`)})
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		context         map[string]interface{}
+		expectedValue   bool
+		expectedVariant string
+	}{
+		"match": {
+			context:         map[string]interface{}{"userAgent": "Mozilla/5.0 Gecko/20100101 Firefox/112.0"},
+			expectedValue:   true,
+			expectedVariant: "on",
+		},
+		"no match": {
+			context:         map[string]interface{}{"userAgent": "Mozilla/5.0 Chrome/112.0.0.0 Safari/537.36"},
+			expectedValue:   false,
+			expectedVariant: "off",
+		},
+		"non string value": {
+			context:         map[string]interface{}{"userAgent": 12},
+			expectedValue:   false,
+			expectedVariant: "off",
+		},
+		"missing value": {
+			context:         map[string]interface{}{},
+			expectedValue:   false,
+			expectedVariant: "off",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "browserFlag", ctx)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedVariant, variant)
+			assert.Equal(t, model.TargetingMatchReason, reason)
+		})
+	}
+}
+
+func TestRegexEvaluation_InvalidPatterns(t *testing.T) {
+	tests := map[string]struct {
+		pattern     string
+		expectedErr string
+	}{
+		"syntax error": {
+			pattern:     `Firefox/(\d+`,
+			expectedErr: "invalid pattern",
+		},
+		"too long": {
+			pattern:     strings.Repeat("a", 1025),
+			expectedErr: "exceeds the maximum length",
+		},
+		"too complex": {
+			pattern:     strings.Repeat(`[a-z]{1000}`, 11),
+			expectedErr: "exceeds the maximum complexity",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: regexFlagConfig(tt.pattern)})
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+			assert.Contains(t, err.Error(), "browserFlag")
+		})
+	}
+}
+
+func TestRegexEvaluation_DynamicPatterns(t *testing.T) {
+	config := `{
+  "flags": {
+    "browserFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": {
+        "if": [
+          {
+            "or": [
+              { "regex": [{ "var": "userAgent" }, "^Firefox/"] },
+              { "regex": [{ "var": "userAgent" }, { "var": "pattern" }] }
+            ]
+          },
+          "on",
+          "off"
+        ]
+      }
+    }
+  }
+}`
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		ctx, err := structpb.NewStruct(map[string]interface{}{
+			"userAgent": fmt.Sprintf("Chrome/%d", i), "pattern": fmt.Sprintf("^Chrome/%d$", i),
+		})
+		require.Nil(t, err)
+		value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "browserFlag", ctx)
+		require.Nil(t, err)
+		require.True(t, value)
+	}
+	require.Equal(t, 1, evaluator.FlushCaches().Patterns, "only the literal pattern of the rule should be cached")
+}
@@ -0,0 +1,519 @@
+package eval
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// RegoExport is implemented by evaluators translating the targeting of their flags into Rego policies
+type RegoExport interface {
+	// ExportRego returns the bundle of the Rego policies of the flags whose targeting can be translated
+	ExportRego() RegoBundle
+}
+
+// RegoBundle holds the Rego policy of each translated flag, along with the library of the helpers they use
+type RegoBundle struct {
+	// Policies are the Rego modules of the bundle keyed by path
+	Policies map[string]string
+	// Untranslated are the reasons the flags left out of the bundle can't be translated, keyed by flag key
+	Untranslated map[string]string
+}
+
+const (
+	// regoRoot is the root of the packages and data of the bundle
+	regoRoot        = "flagd"
+	regoLibPath     = regoRoot + "/lib/lib.rego"
+	regoFlagsPrefix = regoRoot + "/flags/"
+)
+
+// regoIdentifier matches the flag keys usable as is in the package path of their policy, others are quoted
+var regoIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// regoKeywords can't be used as identifiers of package paths
+var regoKeywords = map[string]struct{}{
+	"as": {}, "contains": {}, "default": {}, "else": {}, "every": {}, "false": {}, "if": {}, "import": {}, "in": {},
+	"not": {}, "null": {}, "package": {}, "some": {}, "true": {}, "with": {},
+}
+
+// regoLib implements the json-logic semantics the translated targeting rules rely on, such as truthiness and the
+// flagd operators
+const regoLib = `package flagd.lib
+
+# truthy reports whether a value is truthy by the rules of json-logic
+truthy(x) = false {
+	x == null
+} else = false {
+	x == false
+} else = false {
+	x == 0
+} else = false {
+	x == ""
+} else = false {
+	x == []
+} else = true
+
+falsy(x) = false {
+	truthy(x)
+} else = true
+
+all_truthy(xs) = r {
+	r := equal(count([x | x := xs[_]; not truthy(x)]), 0)
+}
+
+any_truthy(xs) = r {
+	r := gt(count([x | x := xs[_]; truthy(x)]), 0)
+}
+
+# contains_value implements the in operator, on substrings of strings and on the elements of arrays
+contains_value(haystack, needle) = r {
+	is_string(haystack)
+	is_string(needle)
+	r := contains(haystack, needle)
+} else = r {
+	is_array(haystack)
+	r := gt(count([x | x := haystack[_]; x == needle]), 0)
+} else = false
+
+str(x) = x {
+	is_string(x)
+} else = "" {
+	x == null
+} else = s {
+	s := sprintf("%v", [x])
+}
+
+# num converts numeric strings to numbers, as the arithmetic and numeric operators do
+num(x) = x {
+	is_number(x)
+} else = n {
+	is_string(x)
+	n := to_number(x)
+}
+
+missing(keys) = m {
+	m := [k | k := keys[_]; object.get(input, split(k, "."), null) == null]
+}
+
+# regex_match matches missing and non string values as an empty string, as the regex operator does
+regex_match(value, pattern) = r {
+	is_string(value)
+	r := regex.match(pattern, value)
+} else = r {
+	r := regex.match(pattern, "")
+}
+
+greater_than(value, threshold) = r {
+	r := gt(num(value), num(threshold))
+} else = null
+
+less_than(value, threshold) = r {
+	r := lt(num(value), num(threshold))
+} else = null
+
+between(value, low, high, bounds) = r {
+	v := num(value)
+	r := all_truthy([above(v, num(low), substring(bounds, 0, 1)), below(v, num(high), substring(bounds, 1, 1))])
+} else = null
+
+above(v, low, bound) = r {
+	bound == "["
+	r := gte(v, low)
+} else = r {
+	r := gt(v, low)
+}
+
+below(v, high, bound) = r {
+	bound == "]"
+	r := lte(v, high)
+} else = r {
+	r := lt(v, high)
+}
+
+timestamp = t {
+	t := floor(div(time.now_ns(), 1000000000))
+}
+`
+
+// ExportRego translates the targeting of the stored flags into Rego policies, one package per flag
+func (je *JSONEvaluator) ExportRego() RegoBundle {
+	bundle := RegoBundle{Policies: map[string]string{regoLibPath: regoLib}, Untranslated: map[string]string{}}
+	for flagKey, flag := range je.store.GetAll() {
+		policy, err := regoPolicy(flagKey, flag)
+		if err != nil {
+			bundle.Untranslated[flagKey] = err.Error()
+			continue
+		}
+		bundle.Policies[regoFlagsPrefix+url.PathEscape(flagKey)+"/policy.rego"] = policy
+	}
+	return bundle
+}
+
+// WriteBundle writes the bundle as the gzipped tarball OPA loads, e.g. with opa run --bundle, the reasons of the
+// untranslated flags are its data at flagd.untranslated
+func (b RegoBundle) WriteBundle(w io.Writer) error {
+	manifest, err := json.Marshal(map[string]interface{}{"roots": []string{regoRoot}})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{regoRoot: map[string]interface{}{"untranslated": b.Untranslated}})
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{".manifest": manifest, "data.json": data}
+	for path, policy := range b.Policies {
+		files[path] = []byte(policy)
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, path := range paths {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: path, Mode: 0o644, Size: int64(len(files[path])), Typeflag: tar.TypeReg,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[path]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// regoPolicy returns the Rego module resolving the variant and value of the flag for the input context
+func regoPolicy(flagKey string, flag model.Flag) (string, error) {
+	switch {
+	case flag.State == Disabled:
+		return "", errors.New("the flag is disabled")
+	case flag.Derived != nil:
+		return "", errors.New("derived flags can't be translated")
+	case flag.Rulesets != nil:
+		return "", errors.New("rulesets can't be translated")
+	case flag.DefaultVariantByContext != nil:
+		return "", errors.New("default variants by context can't be translated")
+	case len(flag.RequireContext) > 0:
+		return "", errors.New("required context keys can't be translated")
+	}
+
+	t := &regoTranslator{}
+	targeting := "null"
+	if len(flag.Targeting) > 0 && string(flag.Targeting) != "{}" {
+		var rule interface{}
+		decoder := json.NewDecoder(bytes.NewReader(flag.Targeting))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rule); err != nil {
+			return "", fmt.Errorf("targeting isn't valid json: %w", err)
+		}
+		var err error
+		if targeting, err = t.term(rule); err != nil {
+			return "", err
+		}
+	}
+	defaultVariant, err := regoLiteral(flag.DefaultVariant)
+	if err != nil {
+		return "", err
+	}
+	variants, err := regoLiteral(flag.Variants)
+	if err != nil {
+		return "", err
+	}
+
+	var policy strings.Builder
+	fmt.Fprintf(&policy, "package %s\n\nimport data.flagd.lib\n\n", regoPackage(flagKey))
+	fmt.Fprintf(&policy, "default_variant := %s\n\nvariants := %s\n\n", defaultVariant, variants)
+	fmt.Fprintf(&policy, "targeting = x {\n\tx := %s\n}\n\n", targeting)
+	policy.WriteString("# variant is the variant the flag resolves for the input context, the default variant unless " +
+		"the targeting\n# resolves one of the variants of the flag\n")
+	policy.WriteString("variant = x {\n\tx := targeting\n\tis_string(x)\n\t_ = variants[x]\n} else = default_variant\n\n")
+	policy.WriteString("value := variants[variant]\n")
+	for _, rule := range t.rules {
+		policy.WriteString("\n" + rule)
+	}
+	return policy.String(), nil
+}
+
+// regoPackage returns the package of the policy of a flag, flag keys which aren't identifiers are quoted
+func regoPackage(flagKey string) string {
+	if _, keyword := regoKeywords[flagKey]; regoIdentifier.MatchString(flagKey) && !keyword {
+		return "flagd.flags." + flagKey
+	}
+	quoted, _ := regoLiteral(flagKey)
+	return "flagd.flags[" + quoted + "]"
+}
+
+// regoLiteral returns the Rego term of a json value, which are valid Rego terms
+func regoLiteral(value interface{}) (string, error) {
+	var literal bytes.Buffer
+	encoder := json.NewEncoder(&literal)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(literal.String(), "\n"), nil
+}
+
+// regoTranslator translates json-logic rules into Rego terms, the conditionals are translated into auxiliary rules
+type regoTranslator struct {
+	rules        []string
+	conditionals int
+}
+
+// term returns the Rego term evaluating to the result of the json-logic rule
+func (t *regoTranslator) term(rule interface{}) (string, error) {
+	switch rule := rule.(type) {
+	case []interface{}:
+		terms, err := t.terms(rule)
+		if err != nil {
+			return "", err
+		}
+		return "[" + strings.Join(terms, ", ") + "]", nil
+	case map[string]interface{}:
+		if len(rule) != 1 {
+			return "", errors.New("objects which aren't operations can't be translated")
+		}
+		for operator, values := range rule {
+			args, ok := values.([]interface{})
+			if !ok {
+				// unary operations may omit the array of their arguments
+				args = []interface{}{values}
+			}
+			return t.operation(operator, args)
+		}
+	}
+	return regoLiteral(rule)
+}
+
+func (t *regoTranslator) terms(rules []interface{}) ([]string, error) {
+	terms := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		term, err := t.term(rule)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// regoBuiltins are the Rego builtins of the json-logic operators translated as is
+var regoBuiltins = map[string]string{
+	"==": "equal", "===": "equal", "!=": "neq", "!==": "neq", ">": "gt", ">=": "gte", "<": "lt", "<=": "lte",
+}
+
+// operation returns the Rego term of a json-logic operation
+//
+//nolint:funlen,gocyclo
+func (t *regoTranslator) operation(operator string, args []interface{}) (string, error) {
+	arity := func(least, most int) error {
+		if len(args) < least || len(args) > most {
+			return fmt.Errorf("operator: '%s' has %d arguments", operator, len(args))
+		}
+		return nil
+	}
+	if operator == varOperator {
+		return t.variable(args)
+	}
+	if operator == ifOperator || operator == ternaryOperator {
+		return t.conditional(args)
+	}
+	if operator == ruleOperator {
+		// the translated rules resolve the annotated variant, without its rule id
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		return t.term(args[1])
+	}
+	terms, err := t.terms(args)
+	if err != nil {
+		return "", err
+	}
+	call := func(name string, terms ...string) string {
+		return name + "(" + strings.Join(terms, ", ") + ")"
+	}
+	numbers := func() []string {
+		converted := make([]string, 0, len(terms))
+		for _, term := range terms {
+			converted = append(converted, call("lib.num", term))
+		}
+		return converted
+	}
+	list := func(terms []string) string {
+		return "[" + strings.Join(terms, ", ") + "]"
+	}
+
+	switch operator {
+	case "==", "===", "!=", "!==", ">", ">=":
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		return call(regoBuiltins[operator], terms...), nil
+	case "<", "<=":
+		if err := arity(2, 3); err != nil {
+			return "", err
+		}
+		if len(terms) == 3 {
+			// between exclusive or inclusive
+			return call("lib.all_truthy", list([]string{
+				call(regoBuiltins[operator], terms[0], terms[1]), call(regoBuiltins[operator], terms[1], terms[2]),
+			})), nil
+		}
+		return call(regoBuiltins[operator], terms...), nil
+	case "!", "!!":
+		if err := arity(1, 1); err != nil {
+			return "", err
+		}
+		if operator == "!" {
+			return call("lib.falsy", terms[0]), nil
+		}
+		return call("lib.truthy", terms[0]), nil
+	case "and":
+		return call("lib.all_truthy", list(terms)), nil
+	case "or":
+		return call("lib.any_truthy", list(terms)), nil
+	case "in":
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		return call("lib.contains_value", terms[1], terms[0]), nil
+	case "cat":
+		strs := make([]string, 0, len(terms))
+		for _, term := range terms {
+			strs = append(strs, call("lib.str", term))
+		}
+		return call("concat", `""`, list(strs)), nil
+	case "+":
+		return call("sum", list(numbers())), nil
+	case "*":
+		return call("product", list(numbers())), nil
+	case "-":
+		if err := arity(1, 2); err != nil {
+			return "", err
+		}
+		if len(terms) == 1 {
+			return call("minus", "0", numbers()[0]), nil
+		}
+		return call("minus", numbers()...), nil
+	case "/", "%":
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		if operator == "/" {
+			return call("div", numbers()...), nil
+		}
+		return call("rem", numbers()...), nil
+	case "min", "max":
+		return call(operator, list(numbers())), nil
+	case "abs":
+		if err := arity(1, 1); err != nil {
+			return "", err
+		}
+		return call("abs", numbers()...), nil
+	case "missing":
+		return call("lib.missing", list(terms)), nil
+	case regexOperator:
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		return call("lib.regex_match", terms...), nil
+	case greaterThanOperator, lessThanOperator:
+		if err := arity(2, 2); err != nil {
+			return "", err
+		}
+		return call("lib."+operator, terms...), nil
+	case betweenOperator:
+		if err := arity(3, 4); err != nil {
+			return "", err
+		}
+		if len(terms) == 3 {
+			terms = append(terms, `"`+inclusiveBounds+`"`)
+		}
+		return call("lib.between", terms...), nil
+	default:
+		return "", fmt.Errorf("operator: '%s' can't be translated", operator)
+	}
+}
+
+// variable returns the Rego term of a var operation, reading the context from the input
+func (t *regoTranslator) variable(args []interface{}) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", fmt.Errorf("operator: '%s' has %d arguments", varOperator, len(args))
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("operator: '%s' can only be translated with a literal path", varOperator)
+	}
+	fallback := "null"
+	if len(args) == 2 {
+		var err error
+		if fallback, err = t.term(args[1]); err != nil {
+			return "", err
+		}
+	}
+	if path == "" {
+		return "input", nil
+	}
+	if path == flagdPropertiesKey+"."+timestampProperty {
+		return "lib.timestamp", nil
+	}
+	if strings.HasPrefix(path, flagdPropertiesKey+".") {
+		return "", fmt.Errorf("property: '%s' can't be translated", path)
+	}
+	keys := strings.Split(path, ".")
+	literals := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		literals = append(literals, key)
+	}
+	path, err := t.term(literals)
+	if err != nil {
+		return "", err
+	}
+	return "object.get(input, " + path + ", " + fallback + ")", nil
+}
+
+// conditional translates an if operation into an auxiliary rule whose else chain follows its branches, returning
+// the term of the rule
+func (t *regoTranslator) conditional(args []interface{}) (string, error) {
+	t.conditionals++
+	name := fmt.Sprintf("_if_%d", t.conditionals)
+	var rule strings.Builder
+	rule.WriteString(name + " = x {\n")
+	for i := 0; i+1 < len(args); i += 2 {
+		condition, err := t.term(args[i])
+		if err != nil {
+			return "", err
+		}
+		value, err := t.term(args[i+1])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&rule, "\tlib.truthy(%s)\n\tx := %s\n} else = x {\n", condition, value)
+	}
+	fallback := "null"
+	if len(args)%2 == 1 {
+		var err error
+		if fallback, err = t.term(args[len(args)-1]); err != nil {
+			return "", err
+		}
+	}
+	fmt.Fprintf(&rule, "\tx := %s\n}\n", fallback)
+	t.rules = append(t.rules, rule.String())
+	return name, nil
+}
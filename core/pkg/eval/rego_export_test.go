@@ -0,0 +1,206 @@
+package eval_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+)
+
+// regoPolicyOf exports the policy of a flag targeted by the rule
+func regoPolicyOf(t *testing.T, flagKey string, targeting string) (string, eval.RegoBundle) {
+	t.Helper()
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(`{
+  "flags": {
+    %q: {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "red",
+      "targeting": %s
+    }
+  }
+}`, flagKey, targeting))
+	require.Nil(t, err)
+	bundle := evaluator.ExportRego()
+	return bundle.Policies["flagd/flags/"+flagKey+"/policy.rego"], bundle
+}
+
+func TestExportRego(t *testing.T) {
+	policy, bundle := regoPolicyOf(t, "headerColor", `{
+  "if": [
+    { "in": ["@faas.com", { "var": "email" }] }, "blue",
+    { "and": [{ ">=": [{ "var": "user.age" }, 18] }, { "regex": [{ "var": "userAgent" }, "Firefox/\\d+"] }] }, "green",
+    null
+  ]
+}`)
+	require.Empty(t, bundle.Untranslated)
+	require.Contains(t, bundle.Policies, "flagd/lib/lib.rego")
+	require.Equal(t, `package flagd.flags.headerColor
+
+import data.flagd.lib
+
+default_variant := "red"
+
+variants := {"blue":"#0000FF","green":"#00FF00","red":"#FF0000"}
+
+targeting = x {
+	x := _if_1
+}
+
+# variant is the variant the flag resolves for the input context, the default variant unless the targeting
+# resolves one of the variants of the flag
+variant = x {
+	x := targeting
+	is_string(x)
+	_ = variants[x]
+} else = default_variant
+
+value := variants[variant]
+
+_if_1 = x {
+	lib.truthy(lib.contains_value(object.get(input, ["email"], null), "@faas.com"))
+	x := "blue"
+} else = x {
+	lib.truthy(lib.all_truthy([gte(object.get(input, ["user", "age"], null), 18), `+
+		`lib.regex_match(object.get(input, ["userAgent"], null), "Firefox/\\d+")]))
+	x := "green"
+} else = x {
+	x := null
+}
+`, policy)
+}
+
+func TestExportRego_Operators(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		term      string
+	}{
+		"var with default": {
+			targeting: `{ "var": ["plan", "free"] }`,
+			term:      `object.get(input, ["plan"], "free")`,
+		},
+		"timestamp": {
+			targeting: `{ "if": [{ ">": [{ "var": "$flagd.timestamp" }, 1700000000] }, "blue", "red"] }`,
+			term:      `gt(lib.timestamp, 1700000000)`,
+		},
+		"equality": {
+			targeting: `{ "if": [{ "==": [{ "var": "plan" }, "premium"] }, "blue", null] }`,
+			term:      `equal(object.get(input, ["plan"], null), "premium")`,
+		},
+		"double comparison": {
+			targeting: `{ "if": [{ "<": [1, { "var": "age" }, 10] }, "blue", null] }`,
+			term:      `lib.all_truthy([lt(1, object.get(input, ["age"], null)), lt(object.get(input, ["age"], null), 10)])`,
+		},
+		"negation": {
+			targeting: `{ "if": [{ "!": { "var": "beta" } }, "blue", null] }`,
+			term:      `lib.falsy(object.get(input, ["beta"], null))`,
+		},
+		"or": {
+			targeting: `{ "if": [{ "or": [{ "var": "beta" }, { "var": "alpha" }] }, "blue", null] }`,
+			term:      `lib.any_truthy([object.get(input, ["beta"], null), object.get(input, ["alpha"], null)])`,
+		},
+		"cat": {
+			targeting: `{ "cat": ["b", "lue"] }`,
+			term:      `concat("", [lib.str("b"), lib.str("lue")])`,
+		},
+		"arithmetic": {
+			targeting: `{ "if": [{ ">": [{ "+": [{ "var": "a" }, 1] }, { "*": [2, 3] }] }, "blue", null] }`,
+			term:      `gt(sum([lib.num(object.get(input, ["a"], null)), lib.num(1)]), product([lib.num(2), lib.num(3)]))`,
+		},
+		"numeric operators": {
+			targeting: `{ "if": [{ "between": [{ "var": "age" }, 18, 65, "[)"] }, "blue", null] }`,
+			term:      `lib.between(object.get(input, ["age"], null), 18, 65, "[)")`,
+		},
+		"default bounds": {
+			targeting: `{ "if": [{ "between": [{ "var": "age" }, 18, 65] }, "blue", null] }`,
+			term:      `lib.between(object.get(input, ["age"], null), 18, 65, "[]")`,
+		},
+		"missing": {
+			targeting: `{ "if": [{ "missing": ["email"] }, "red", "blue"] }`,
+			term:      `lib.missing(["email"])`,
+		},
+		"annotated rule": {
+			targeting: `{ "rule": ["beta-users", { "if": [{ "var": "beta" }, "blue", null] }] }`,
+			term:      `lib.truthy(object.get(input, ["beta"], null))`,
+		},
+		"nested conditionals": {
+			targeting: `{ "if": [{ "var": "beta" }, { "if": [{ "var": "alpha" }, "blue", "green"] }, null] }`,
+			term:      "\tx := _if_2\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, bundle := regoPolicyOf(t, "headerColor", tt.targeting)
+			require.Empty(t, bundle.Untranslated)
+			require.Contains(t, policy, tt.term)
+		})
+	}
+}
+
+func TestExportRego_Untranslated(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		reason    string
+	}{
+		"fractional evaluation": {
+			targeting: `{ "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }`,
+			reason:    "operator: 'fractionalEvaluation' can't be translated",
+		},
+		"array operation": {
+			targeting: `{ "if": [{ "some": [{ "var": "roles" }, { "==": [{ "var": "" }, "admin"] }] }, "blue", null] }`,
+			reason:    "operator: 'some' can't be translated",
+		},
+		"flagd property": {
+			targeting: `{ "if": [{ "var": "$flagd.fractionalRandomization" }, "blue", null] }`,
+			reason:    "property: '$flagd.fractionalRandomization' can't be translated",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, bundle := regoPolicyOf(t, "headerColor", tt.targeting)
+			require.Empty(t, policy)
+			require.Equal(t, map[string]string{"headerColor": tt.reason}, bundle.Untranslated)
+		})
+	}
+}
+
+func TestExportRego_QuotedPackage(t *testing.T) {
+	policy, _ := regoPolicyOf(t, "header-color", `{}`)
+	require.Contains(t, policy, `package flagd.flags["header-color"]`)
+	require.Contains(t, policy, "targeting = x {\n\tx := null\n}")
+
+	policy, _ = regoPolicyOf(t, "default", `{}`)
+	require.Contains(t, policy, `package flagd.flags["default"]`, "keywords aren't identifiers")
+}
+
+func TestRegoBundle_WriteBundle(t *testing.T) {
+	_, bundle := regoPolicyOf(t, "headerColor", `{ "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }`)
+	var buf bytes.Buffer
+	require.Nil(t, bundle.WriteBundle(&buf))
+
+	gz, err := gzip.NewReader(&buf)
+	require.Nil(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		content, err := io.ReadAll(tr)
+		require.Nil(t, err)
+		files[header.Name] = content
+	}
+	require.JSONEq(t, `{"roots": ["flagd"]}`, string(files[".manifest"]))
+	var data map[string]map[string]map[string]string
+	require.Nil(t, json.Unmarshal(files["data.json"], &data))
+	require.Contains(t, data["flagd"]["untranslated"], "headerColor")
+	require.Contains(t, files, "flagd/lib/lib.rego")
+}
@@ -0,0 +1,45 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MissingContextError is returned for the evaluations of a flag missing context keys the flag requires, it's
+// reported by its error code
+type MissingContextError struct {
+	// Keys are the missing context keys, in the order the flag requires them
+	Keys []string
+}
+
+func (e *MissingContextError) Error() string {
+	return model.MissingContextErrorCode
+}
+
+// validateRequireContext checks the context keys required by a flag aren't empty
+func validateRequireContext(key string, flag model.Flag) error {
+	for _, required := range flag.RequireContext {
+		if required == "" {
+			return fmt.Errorf("flag: '%s' requires an empty context key", key)
+		}
+	}
+	return nil
+}
+
+// missingContextKeys returns the context keys required by the flag which the context doesn't hold, null values
+// count as missing. Required keys may be dotted paths of nested values.
+func (je *JSONEvaluator) missingContextKeys(flag model.Flag, context *structpb.Struct) []string {
+	if len(flag.RequireContext) == 0 {
+		return nil
+	}
+	data := je.normalizeContext(context.AsMap())
+	var missing []string
+	for _, key := range flag.RequireContext {
+		if contextPathValue(data, key) == nil {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
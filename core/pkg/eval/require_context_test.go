@@ -0,0 +1,90 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const requireContextFlagConfig = `{
+  "flags": {
+    "entitlement": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "on", null] },
+      "requireContext": ["plan", "account.id"]
+    }
+  }
+}`
+
+func TestRequireContext(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, requireContextFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		context map[string]interface{}
+		value   bool
+		missing []string
+	}{
+		"required keys present": {
+			context: map[string]interface{}{"plan": "premium", "account": map[string]interface{}{"id": "42"}},
+			value:   true,
+		},
+		"required keys present without matching": {
+			context: map[string]interface{}{"plan": "free", "account": map[string]interface{}{"id": "42"}},
+			value:   false,
+		},
+		"nested key missing": {
+			context: map[string]interface{}{"plan": "premium", "account": map[string]interface{}{}},
+			missing: []string{"account.id"},
+		},
+		"null value counts as missing": {
+			context: map[string]interface{}{"plan": nil, "account": map[string]interface{}{"id": "42"}},
+			missing: []string{"plan"},
+		},
+		"every key missing": {
+			missing: []string{"plan", "account.id"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "entitlement", ctx)
+			if tt.missing == nil {
+				require.Nil(t, err)
+				require.Equal(t, tt.value, value)
+				return
+			}
+			require.Equal(t, model.ErrorReason, reason)
+			require.EqualError(t, err, model.MissingContextErrorCode)
+			var missingErr *eval.MissingContextError
+			require.ErrorAs(t, err, &missingErr)
+			require.Equal(t, tt.missing, missingErr.Keys)
+		})
+	}
+}
+
+func TestRequireContext_ResolveAll(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, requireContextFlagConfig)
+	require.Nil(t, err)
+	require.Empty(t, evaluator.ResolveAllValues(context.Background(), "", &structpb.Struct{}),
+		"flags missing required keys are left out of bulk evaluations")
+}
+
+func TestRequireContext_EmptyKey(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "entitlement": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "requireContext": [""]
+    }
+  }
+}`)
+	require.ErrorContains(t, err, "flag: 'entitlement' requires an empty context key")
+}
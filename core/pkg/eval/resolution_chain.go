@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ResolutionChains is implemented by evaluators able to report the resolutions of the prerequisite flags a resolution
+// depends on
+type ResolutionChains interface {
+	// ResolveChain resolves the flag, returning the resolutions of the prerequisite flags evaluated to resolve it in
+	// their evaluation order followed by the resolution of the flag, along with the error of the resolution of the flag
+	ResolveChain(ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+	) ([]ResolutionStep, error)
+}
+
+// ResolutionStep is the resolution of a flag of a resolution chain
+type ResolutionStep struct {
+	FlagKey string
+	// Depth is the number of derived flags between the flag and the resolved flag, 0 for the resolved flag
+	Depth   int
+	Variant string
+	Reason  string
+	// ErrorCode is the error code of a failed resolution
+	ErrorCode string
+}
+
+// resolutionChain records the resolutions of the flags evaluated by a resolution, nil unless the chain is requested
+type resolutionChain struct {
+	steps []ResolutionStep
+}
+
+func (c *resolutionChain) record(flagKey string, depth int, variant string, reason string, err error) {
+	if c == nil {
+		return
+	}
+	step := ResolutionStep{FlagKey: flagKey, Depth: depth, Variant: variant, Reason: reason}
+	if err != nil {
+		step.ErrorCode = err.Error()
+	}
+	c.steps = append(c.steps, step)
+}
+
+// ResolveChain resolves the flag as the Resolve methods do, recording the resolution of each prerequisite flag of its
+// derived expression, along with their own prerequisites. Prerequisites skipped as and and or short-circuit aren't
+// part of the chain.
+func (je *JSONEvaluator) ResolveChain(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) ([]ResolutionStep, error) {
+	flagKey = je.namespaceKey(reqID, flagKey)
+	chain := &resolutionChain{}
+	variant, reason, _, err := je.evaluateFlag(ctx, reqID, flagKey, context, nil, chain)
+	chain.record(flagKey, 0, variant, reason, err)
+	return chain.steps, err
+}
+
+// ResolveChain resolves the chain of the flag with the evaluator serving the evaluation context
+func (ce *CanaryEvaluator) ResolveChain(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) ([]ResolutionStep, error) {
+	evaluator, _ := ce.route(context)
+	return resolveChain(ctx, evaluator, reqID, flagKey, context)
+}
+
+// ResolveChain resolves the chain of the flag with the evaluator of the tenant of the evaluation context, falling back
+// to the shared evaluator as the Resolve methods do
+func (te *TenantEvaluator) ResolveChain(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) ([]ResolutionStep, error) {
+	evaluator, tenant := te.route(context)
+	steps, err := resolveChain(ctx, evaluator, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return resolveChain(ctx, te.shared, reqID, flagKey, context)
+	}
+	return steps, err
+}
+
+// resolveChain resolves the chain of the flag with the evaluator, if it's able to
+func resolveChain(
+	ctx context.Context, evaluator IEvaluator, reqID string, flagKey string, context *structpb.Struct,
+) ([]ResolutionStep, error) {
+	chains, ok := evaluator.(ResolutionChains)
+	if !ok {
+		return nil, errors.New("the evaluator can't resolve the chain of flags")
+	}
+	return chains.ResolveChain(ctx, reqID, flagKey, context)
+}
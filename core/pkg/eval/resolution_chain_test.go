@@ -0,0 +1,113 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const resolutionChainFlagConfig = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "beta"] }, "on", null] }
+    },
+    "employee": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null] }
+    },
+    "blocked": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    },
+    "eligible": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "or": ["beta", "employee"] }
+    },
+    "newCheckout": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "and": ["eligible", { "not": "blocked" }] }
+    },
+    "brokenCheckout": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "and": ["eligible", "aMissingFlag"] }
+    }
+  }
+}`
+
+func TestResolveChain(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolutionChainFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		flagKey string
+		context map[string]interface{}
+		want    []eval.ResolutionStep
+		wantErr string
+	}{
+		"two levels of prerequisites": {
+			flagKey: "newCheckout",
+			context: map[string]interface{}{"email": "user@faas.com"},
+			want: []eval.ResolutionStep{
+				{FlagKey: "beta", Depth: 2, Variant: "off", Reason: model.DefaultReason},
+				{FlagKey: "employee", Depth: 2, Variant: "on", Reason: model.TargetingMatchReason},
+				{FlagKey: "eligible", Depth: 1, Variant: "on", Reason: model.DerivedReason},
+				{FlagKey: "blocked", Depth: 1, Variant: "off", Reason: model.StaticReason},
+				{FlagKey: "newCheckout", Variant: "on", Reason: model.DerivedReason},
+			},
+		},
+		"short-circuited prerequisites": {
+			flagKey: "newCheckout",
+			context: map[string]interface{}{"plan": "beta"},
+			want: []eval.ResolutionStep{
+				{FlagKey: "beta", Depth: 2, Variant: "on", Reason: model.TargetingMatchReason},
+				{FlagKey: "eligible", Depth: 1, Variant: "on", Reason: model.DerivedReason},
+				{FlagKey: "blocked", Depth: 1, Variant: "off", Reason: model.StaticReason},
+				{FlagKey: "newCheckout", Variant: "on", Reason: model.DerivedReason},
+			},
+		},
+		"failed prerequisite": {
+			flagKey: "brokenCheckout",
+			context: map[string]interface{}{"plan": "beta"},
+			want: []eval.ResolutionStep{
+				{FlagKey: "beta", Depth: 2, Variant: "on", Reason: model.TargetingMatchReason},
+				{FlagKey: "eligible", Depth: 1, Variant: "on", Reason: model.DerivedReason},
+				{FlagKey: "aMissingFlag", Depth: 1, Reason: model.ErrorReason, ErrorCode: model.FlagNotFoundErrorCode},
+				{FlagKey: "brokenCheckout", Reason: model.ErrorReason, ErrorCode: model.GeneralErrorCode},
+			},
+			wantErr: model.GeneralErrorCode,
+		},
+		"flag without prerequisites": {
+			flagKey: "beta",
+			want:    []eval.ResolutionStep{{FlagKey: "beta", Variant: "off", Reason: model.DefaultReason}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			steps, err := evaluator.ResolveChain(context.Background(), "", tt.flagKey, evalCtx)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Equal(t, tt.want, steps)
+		})
+	}
+}
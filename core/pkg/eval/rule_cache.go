@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ruleCache holds the parsed targeting rules of flags, keyed by flag key. Entries are validated against the raw
+// targeting of the flag being evaluated, so rules of updated flags are re-parsed on first use.
+type ruleCache struct {
+	mx    sync.RWMutex
+	rules map[string]cachedRule
+	// staged holds the rules of a configuration warmed up before being swapped in, so evaluations of the current
+	// configuration don't evict them
+	staged map[string]cachedRule
+}
+
+// cachedRule is a parsed targeting rule along with its copy bound to the evaluator, which applies it
+type cachedRule struct {
+	raw   string
+	rule  interface{}
+	bound interface{}
+}
+
+func (c *ruleCache) get(flagKey string, raw []byte) (cachedRule, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	if cached, ok := c.rules[flagKey]; ok && cached.raw == string(raw) {
+		return cached, true
+	}
+	if cached, ok := c.staged[flagKey]; ok && cached.raw == string(raw) {
+		return cached, true
+	}
+	return cachedRule{}, false
+}
+
+func (c *ruleCache) set(flagKey string, cached cachedRule) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.rules == nil {
+		c.rules = map[string]cachedRule{}
+	}
+	c.rules[flagKey] = cached
+}
+
+func (c *ruleCache) stage(flagKey string, cached cachedRule) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.staged == nil {
+		c.staged = map[string]cachedRule{}
+	}
+	c.staged[flagKey] = cached
+}
+
+// promote moves the staged rules to the cache once their configuration is swapped in, returning their number
+func (c *ruleCache) promote() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.rules == nil {
+		c.rules = map[string]cachedRule{}
+	}
+	for flagKey, cached := range c.staged {
+		c.rules[flagKey] = cached
+	}
+	promoted := len(c.staged)
+	c.staged = nil
+	return promoted
+}
+
+// discard drops the staged rules of a configuration which isn't swapped in
+func (c *ruleCache) discard() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.staged = nil
+}
+
+// WithRuleWarmup parses the targeting rules of new configurations into a staging cache before the configuration is
+// swapped in, so the configuration being replaced keeps its cached rules until the swap and the first evaluations of
+// the new configuration don't parse rules. The warmup time is logged.
+func WithRuleWarmup(warmup bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.ruleWarmup = warmup
+	}
+}
+
+// targetingRule returns the parsed targeting rule of a flag, parsing and caching it on a cache miss
+func (je *JSONEvaluator) targetingRule(flagKey string, targeting json.RawMessage) (interface{}, error) {
+	cached, err := je.cachedTargetingRule(flagKey, targeting)
+	return cached.rule, err
+}
+
+// boundTargetingRule returns the parsed targeting rule of a flag bound to the evaluator, to be applied to the data
+// of an evaluation
+func (je *JSONEvaluator) boundTargetingRule(flagKey string, targeting json.RawMessage) (interface{}, error) {
+	cached, err := je.cachedTargetingRule(flagKey, targeting)
+	return cached.bound, err
+}
+
+func (je *JSONEvaluator) cachedTargetingRule(flagKey string, targeting json.RawMessage) (cachedRule, error) {
+	if cached, ok := je.rules.get(flagKey, targeting); ok {
+		return cached, nil
+	}
+	cached, err := je.parseCachedRule(flagKey, targeting)
+	if err != nil {
+		return cachedRule{}, err
+	}
+	je.rules.set(flagKey, cached)
+	return cached, nil
+}
+
+// warmRule returns the parsed targeting rule of a flag of a new configuration, staging it when warming up
+func (je *JSONEvaluator) warmRule(flagKey string, targeting json.RawMessage) (interface{}, error) {
+	if !je.ruleWarmup {
+		return je.targetingRule(flagKey, targeting)
+	}
+	if cached, ok := je.rules.get(flagKey, targeting); ok {
+		je.rules.stage(flagKey, cached)
+		return cached.rule, nil
+	}
+	cached, err := je.parseCachedRule(flagKey, targeting)
+	if err != nil {
+		return nil, err
+	}
+	je.rules.stage(flagKey, cached)
+	return cached.rule, nil
+}
+
+func (je *JSONEvaluator) parseCachedRule(flagKey string, targeting json.RawMessage) (cachedRule, error) {
+	rule, err := je.parseRule(flagKey, targeting)
+	if err != nil {
+		return cachedRule{}, err
+	}
+	return cachedRule{raw: string(targeting), rule: rule, bound: je.bindRule(rule)}, nil
+}
+
+func (je *JSONEvaluator) parseRule(flagKey string, targeting json.RawMessage) (interface{}, error) {
+	je.Logger.Debug(fmt.Sprintf("parsing targeting rule of flag: %s", flagKey))
+	var rule interface{}
+	if err := json.Unmarshal(targeting, &rule); err != nil {
+		return nil, err
+	}
+	je.normalizeRule(rule)
+	je.coerceRule(rule)
+	return rule, nil
+}
+
+// swapWarmedRules promotes the staged rules once the configuration of the source is swapped in, logging the time
+// spent warming them up
+func (je *JSONEvaluator) swapWarmedRules(source string, warmup time.Duration) {
+	if !je.ruleWarmup {
+		return
+	}
+	rules := je.rules.promote()
+	je.Logger.Info(fmt.Sprintf("warmed up %d targeting rules of source: %s in %s", rules, source, warmup))
+}
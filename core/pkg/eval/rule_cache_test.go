@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const warmupFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "regex": [{ "var": "email" }, "%s"] }, "blue", null] }
+    }
+  }
+}`
+
+func TestRuleWarmup(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	je := NewJSONEvaluator(logger.NewLogger(zap.New(core), true), store.NewFlags(), WithRuleWarmup(true))
+	parses := func() int {
+		return logs.FilterMessageSnippet("parsing targeting rule of flag").Len()
+	}
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+	resolveColor := func() string {
+		t.Helper()
+		value, _, _, _, err := je.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		return value
+	}
+
+	_, _, err = je.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(warmupFlagConfig, "@faas\\\\.com$"), Source: "flags.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, parses())
+	require.Equal(t, 1, logs.FilterMessageSnippet("warmed up 1 targeting rules of source: flags.json in").Len())
+	require.Equal(t, "#0000FF", resolveColor())
+	require.Equal(t, 1, parses(), "evaluations after the swap shouldn't parse the warmed up rule")
+
+	// the new configuration is warmed up while the current one is still evaluated
+	var newFlags Flags
+	config := fmt.Sprintf(warmupFlagConfig, "@example\\\\.com$")
+	require.Nil(t, je.configToFlags("flags.json", config, &newFlags))
+	require.Equal(t, 2, parses())
+	require.Equal(t, "#0000FF", resolveColor())
+	require.Equal(t, 2, parses(), "the warmup shouldn't evict the rules of the current configuration")
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: config, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	require.Equal(t, "#FF0000", resolveColor())
+	require.Equal(t, 2, parses(), "evaluations after the swap shouldn't parse the warmed up rule")
+}
+
+func TestRuleWarmup_InvalidConfig(t *testing.T) {
+	je := NewJSONEvaluator(nil, store.NewFlags(), WithRuleWarmup(true))
+	_, _, err := je.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(warmupFlagConfig, "(unclosed"), Source: "flags.json", Type: sync.ALL,
+	})
+	require.NotNil(t, err)
+	require.Empty(t, je.rules.staged, "rules of a rejected configuration shouldn't be staged")
+	require.Empty(t, je.rules.rules)
+}
@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+)
+
+// rule annotates a targeting branch with an id, which is returned in the resolution metadata when the branch matches.
+// e.g. {"rule": ["premium-users", "gold"]}
+func (je *JSONEvaluator) rule(values, _ interface{}) interface{} {
+	ruleID, result, err := parseRuleData(values)
+	if err != nil {
+		je.Logger.Error(fmt.Sprintf("parse rule data: %v", err))
+		return nil
+	}
+
+	annotated := map[string]interface{}{
+		RuleIDMetadataKey: ruleID,
+	}
+	switch r := result.(type) {
+	case string:
+		annotated[targetingVariantKey] = r
+	case map[string]interface{}:
+		// nested annotating operators (e.g. fractionalEvaluation) keep their metadata, the innermost rule id wins
+		for k, v := range r {
+			annotated[k] = v
+		}
+	default:
+		return result
+	}
+
+	return annotated
+}
+
+func parseRuleData(values interface{}) (string, interface{}, error) {
+	valuesArray, ok := values.([]interface{})
+	if !ok {
+		return "", nil, errors.New("rule data is not an array")
+	}
+	if len(valuesArray) != 2 {
+		return "", nil, errors.New("rule data isn't length 2")
+	}
+
+	ruleID, ok := valuesArray[0].(string)
+	if !ok {
+		return "", nil, errors.New("first element of rule data isn't of type string")
+	}
+
+	return ruleID, valuesArray[1], nil
+}
@@ -0,0 +1,261 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// LargeInListEntries is the number of entries from which the lists of in operations are reported, as they're scanned
+// on every evaluation
+const LargeInListEntries = 1000
+
+// Lint rules identify the checks reporting lint warnings
+const (
+	LintRuleLargeInList          = "large-in-list"
+	LintRuleAlwaysTrueCondition  = "always-true-condition"
+	LintRuleAlwaysFalseCondition = "always-false-condition"
+	LintRuleDuplicateCondition   = "duplicate-condition"
+	LintRuleUnreachableBranch    = "unreachable-branch"
+)
+
+// LintSeverity is the severity of a lint warning: info for inefficient rules, warning for suspicious ones
+type LintSeverity string
+
+const (
+	LintSeverityInfo    LintSeverity = "info"
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// contextOperators are the operators reading the evaluation context, conditions without them are constant
+var contextOperators = map[string]struct{}{
+	"var": {}, "missing": {}, "missing_some": {}, fractionalEvaluationOperator: {}, ruleOperator: {},
+}
+
+// RuleLinting is implemented by evaluators able to lint the targeting rules of flags
+type RuleLinting interface {
+	LintRules(config string, flagKey string) ([]LintWarning, error)
+}
+
+// LintWarning reports an inefficient or suspicious expression of the targeting rule of a flag
+type LintWarning struct {
+	FlagKey  string
+	Rule     string
+	Severity LintSeverity
+	// Path locates the expression in the flag, e.g. targeting.if[2] for the second condition of the top-level if
+	Path    string
+	Message string
+}
+
+// LintRules lints the targeting rules of the flags of the candidate configuration, of the stored flags if empty, only
+// linting the flag of the key if set. Rules are analyzed without being evaluated nor cached, the store is left
+// unchanged. Candidate configurations must be valid, unknown flags fail with the flag not found error code.
+func (je *JSONEvaluator) LintRules(config string, flagKey string) ([]LintWarning, error) {
+	flags := je.store.GetAll()
+	if config != "" {
+		var err error
+		if flags, err = je.candidateFlags(config); err != nil {
+			return nil, err
+		}
+	}
+	if flagKey != "" {
+		flag, ok := flags[flagKey]
+		if !ok {
+			return nil, errors.New(model.FlagNotFoundErrorCode)
+		}
+		flags = map[string]model.Flag{flagKey: flag}
+	}
+
+	var warnings []LintWarning
+	for key, flag := range flags {
+		warnings = append(warnings, lintFlag(key, flag)...)
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].FlagKey != warnings[j].FlagKey {
+			return warnings[i].FlagKey < warnings[j].FlagKey
+		}
+		return warnings[i].Path < warnings[j].Path
+	})
+	return warnings, nil
+}
+
+// ErrInvalidConfig is the error of the candidate configurations which fail validation
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// candidateFlags returns the flags of a candidate configuration, failing with ErrInvalidConfig on its first issue
+func (je *JSONEvaluator) candidateFlags(config string) (map[string]model.Flag, error) {
+	flags, issues, err := je.validateCandidate(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) > 0 {
+		if issues[0].FlagKey != "" {
+			return nil, fmt.Errorf("%w, flag: %s: %s", ErrInvalidConfig, issues[0].FlagKey, issues[0].Message)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidConfig, issues[0].Message)
+	}
+	return flags, nil
+}
+
+// lintFlag lints the targeting of the flag along with the rules of its rulesets
+func lintFlag(flagKey string, flag model.Flag) []LintWarning {
+	l := &ruleLinter{flagKey: flagKey}
+	l.lintTargeting("targeting", flag.Targeting)
+	if flag.Rulesets != nil {
+		values := make([]string, 0, len(flag.Rulesets.Rules))
+		for value := range flag.Rulesets.Rules {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			l.lintTargeting(fmt.Sprintf("rulesets.rules.%s", value), flag.Rulesets.Rules[value])
+		}
+	}
+	return l.warnings
+}
+
+type ruleLinter struct {
+	flagKey  string
+	warnings []LintWarning
+}
+
+func (l *ruleLinter) warn(rule string, severity LintSeverity, path string, format string, args ...interface{}) {
+	l.warnings = append(l.warnings, LintWarning{
+		FlagKey:  l.flagKey,
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *ruleLinter) lintTargeting(path string, targeting json.RawMessage) {
+	if len(targeting) == 0 {
+		return
+	}
+	var rule interface{}
+	if err := json.Unmarshal(targeting, &rule); err != nil {
+		return
+	}
+	l.lint(path, rule)
+}
+
+// lint walks the expression, linting the operations it nests
+func (l *ruleLinter) lint(path string, expression interface{}) {
+	switch e := expression.(type) {
+	case []interface{}:
+		for i, element := range e {
+			l.lint(fmt.Sprintf("%s[%d]", path, i), element)
+		}
+	case map[string]interface{}:
+		if len(e) != 1 {
+			return
+		}
+		for operator, values := range e {
+			args, _ := values.([]interface{})
+			switch operator {
+			case "in":
+				l.lintIn(path+".in", args)
+			case ifOperator, ternaryOperator:
+				l.lintIf(path+"."+operator, args)
+			}
+			l.lint(path+"."+operator, values)
+		}
+	}
+}
+
+// lintIn reports the in operations scanning a large list literal
+func (l *ruleLinter) lintIn(path string, args []interface{}) {
+	if len(args) != 2 {
+		return
+	}
+	if list, ok := args[1].([]interface{}); ok && len(list) >= LargeInListEntries {
+		l.warn(LintRuleLargeInList, LintSeverityInfo, path+"[1]",
+			"in scans its %d entries on every evaluation, consider a shorter list or a condition on a shared "+
+				"attribute of its entries", len(list))
+	}
+}
+
+// lintIf reports the constant and repeated conditions of a conditional, along with the branches they make
+// unreachable
+func (l *ruleLinter) lintIf(path string, args []interface{}) {
+	seen := map[string]int{}
+	for i := 0; i < len(args)-1; i += conditionalArgPairs {
+		condition := fmt.Sprintf("%s[%d]", path, i)
+		if encoded, err := json.Marshal(args[i]); err == nil {
+			if first, ok := seen[string(encoded)]; ok {
+				l.warn(LintRuleDuplicateCondition, LintSeverityWarning, condition,
+					"the condition repeats the condition at %s[%d], its branch is unreachable", path, first)
+				continue
+			}
+			seen[string(encoded)] = i
+		}
+		truthy, constant := constantCondition(args[i])
+		if !constant {
+			continue
+		}
+		if !truthy {
+			l.warn(LintRuleAlwaysFalseCondition, LintSeverityWarning, condition,
+				"the condition never reads the evaluation context and is always false, its branch is unreachable")
+			continue
+		}
+		l.warn(LintRuleAlwaysTrueCondition, LintSeverityWarning, condition,
+			"the condition never reads the evaluation context and is always true")
+		if next := i + conditionalArgPairs; next < len(args) {
+			l.warn(LintRuleUnreachableBranch, LintSeverityWarning, fmt.Sprintf("%s[%d]", path, next),
+				"the branches from here on are unreachable, the condition at %s is always true", condition)
+		}
+		return
+	}
+}
+
+// constantCondition returns the truthiness of a condition which doesn't read the evaluation context, reporting
+// false for conditions reading it
+func constantCondition(condition interface{}) (bool, bool) {
+	if readsContext(condition) {
+		return false, false
+	}
+	// the condition is evaluated by the if operation itself, so its truthiness matches the evaluation's
+	matched, err := jsonlogic.ApplyInterface(map[string]interface{}{
+		ifOperator: []interface{}{condition, true, false},
+	}, map[string]interface{}{})
+	if err != nil {
+		return false, false
+	}
+	return matched == true, true
+}
+
+// readsContext returns whether the expression holds an operation reading the evaluation context
+func readsContext(expression interface{}) bool {
+	switch e := expression.(type) {
+	case []interface{}:
+		for _, element := range e {
+			if readsContext(element) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for operator, values := range e {
+			if _, ok := contextOperators[operator]; ok {
+				return true
+			}
+			if readsContext(values) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintRules lints the rules with the evaluator, if it's able to
+func lintRules(evaluator IEvaluator, config string, flagKey string) ([]LintWarning, error) {
+	linting, ok := evaluator.(RuleLinting)
+	if !ok {
+		return nil, errors.New("the evaluator can't lint rules")
+	}
+	return linting.LintRules(config, flagKey)
+}
@@ -0,0 +1,155 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const ruleLintFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "plan" }, "enterprise"] }, "blue",
+          { "==": [{ "var": "plan" }, "enterprise"] }, "green",
+          { "==": [1, 1] }, "blue",
+          { "==": [{ "var": "plan" }, "pro"] }, "green",
+          "red"
+        ]
+      }
+    },
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["email", %s] }, "on", "off"] },
+      "rulesets": {
+        "contextKey": "environment",
+        "rules": {
+          "production": { "if": [{ "in": [{ "var": "email" }, %s] }, "on", "off"] },
+          "staging": { "if": [false, "on", "off"] }
+        }
+      }
+    },
+    "clean": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": [{ "var": "email" }, ["a@faas.com", "b@faas.com"]] }, "on", "off"] }
+    }
+  }
+}`
+
+func ruleLintConfig() string {
+	emails := make([]string, LargeInListEntries)
+	for i := range emails {
+		emails[i] = fmt.Sprintf(`"user%d@faas.com"`, i)
+	}
+	list := "[" + strings.Join(emails, ",") + "]"
+	return fmt.Sprintf(ruleLintFlagConfig, list, list)
+}
+
+func TestLintRules(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, ruleLintConfig())
+	require.Nil(t, err)
+
+	warnings, err := je.LintRules("", "")
+	require.Nil(t, err)
+	require.Equal(t, []LintWarning{
+		{
+			FlagKey:  "beta",
+			Rule:     LintRuleLargeInList,
+			Severity: LintSeverityInfo,
+			Path:     "rulesets.rules.production.if[0].in[1]",
+			Message: "in scans its 1000 entries on every evaluation, consider a shorter list or a condition on a " +
+				"shared attribute of its entries",
+		},
+		{
+			FlagKey:  "beta",
+			Rule:     LintRuleAlwaysFalseCondition,
+			Severity: LintSeverityWarning,
+			Path:     "rulesets.rules.staging.if[0]",
+			Message:  "the condition never reads the evaluation context and is always false, its branch is unreachable",
+		},
+		{
+			FlagKey:  "beta",
+			Rule:     LintRuleAlwaysFalseCondition,
+			Severity: LintSeverityWarning,
+			Path:     "targeting.if[0]",
+			Message:  "the condition never reads the evaluation context and is always false, its branch is unreachable",
+		},
+		{
+			FlagKey:  "beta",
+			Rule:     LintRuleLargeInList,
+			Severity: LintSeverityInfo,
+			Path:     "targeting.if[0].in[1]",
+			Message: "in scans its 1000 entries on every evaluation, consider a shorter list or a condition on a " +
+				"shared attribute of its entries",
+		},
+		{
+			FlagKey:  "headerColor",
+			Rule:     LintRuleDuplicateCondition,
+			Severity: LintSeverityWarning,
+			Path:     "targeting.if[2]",
+			Message:  "the condition repeats the condition at targeting.if[0], its branch is unreachable",
+		},
+		{
+			FlagKey:  "headerColor",
+			Rule:     LintRuleAlwaysTrueCondition,
+			Severity: LintSeverityWarning,
+			Path:     "targeting.if[4]",
+			Message:  "the condition never reads the evaluation context and is always true",
+		},
+		{
+			FlagKey:  "headerColor",
+			Rule:     LintRuleUnreachableBranch,
+			Severity: LintSeverityWarning,
+			Path:     "targeting.if[6]",
+			Message:  "the branches from here on are unreachable, the condition at targeting.if[4] is always true",
+		},
+	}, warnings)
+}
+
+func TestLintRules_Flag(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, ruleLintConfig())
+	require.Nil(t, err)
+
+	warnings, err := je.LintRules("", "clean")
+	require.Nil(t, err)
+	require.Empty(t, warnings)
+
+	_, err = je.LintRules("", "missing")
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+}
+
+func TestLintRules_Candidate(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, `{"flags": {}}`)
+	require.Nil(t, err)
+
+	warnings, err := je.LintRules(ruleLintConfig(), "headerColor")
+	require.Nil(t, err)
+	require.Len(t, warnings, 3)
+	require.Empty(t, je.store.GetAll(), "linting a candidate shouldn't load it")
+
+	_, err = je.LintRules(`{"flags": {"broken": {"state": "ENABLED"}}}`, "")
+	require.True(t, errors.Is(err, ErrInvalidConfig))
+	require.Contains(t, err.Error(), "invalid configuration, flag: broken: ")
+}
+
+func TestLintRules_Canary(t *testing.T) {
+	stable, err := NewJSONEvaluatorFromConfig(nil, ruleLintConfig())
+	require.Nil(t, err)
+	ce := NewCanaryEvaluator(nil, stable, nil, 0, nil)
+
+	warnings, err := ce.LintRules("", "headerColor")
+	require.Nil(t, err)
+	require.Len(t, warnings, 3)
+}
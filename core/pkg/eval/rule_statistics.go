@@ -0,0 +1,151 @@
+package eval
+
+import (
+	"strconv"
+	"sync"
+)
+
+const (
+	ifOperator          = "if"
+	ternaryOperator     = "?:"
+	elseBranch          = "else"
+	noBranch            = "none"
+	conditionalArgPairs = 2
+)
+
+// RuleStatistics is implemented by evaluators recording which branches of the targeting rules of flags match
+type RuleStatistics interface {
+	RuleStatistics() map[string]FlagRuleStatistics
+}
+
+// FlagRuleStatistics counts the evaluations of the top-level conditional of the targeting of a flag, by matched
+// branch. Branches are the index of the matched condition, else if no condition matched and the else value was
+// returned, none if no condition matched without else value.
+type FlagRuleStatistics struct {
+	Evaluations int64            `json:"evaluations"`
+	Branches    map[string]int64 `json:"branches"`
+	// ConditionsEvaluated is the number of conditions evaluated, conditions after the matching one are short
+	// circuited
+	ConditionsEvaluated int64 `json:"conditionsEvaluated"`
+}
+
+// AverageConditions is the average number of conditions evaluated per evaluation
+func (s FlagRuleStatistics) AverageConditions() float64 {
+	if s.Evaluations == 0 {
+		return 0
+	}
+	return float64(s.ConditionsEvaluated) / float64(s.Evaluations)
+}
+
+// WithRuleStatistics records the matched branch of the top-level if of the targeting of each evaluation, along with
+// the number of conditions evaluated. Recording evaluates the conditions of the rule a second time, nothing is
+// recorded when disabled.
+func WithRuleStatistics(enabled bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		if enabled {
+			je.ruleStatistics = &ruleStatistics{flags: map[string]*flagRuleStatistics{}}
+		}
+	}
+}
+
+// RuleStatistics returns the statistics of the flags whose targeting is a top-level if, nil if they aren't recorded
+func (je *JSONEvaluator) RuleStatistics() map[string]FlagRuleStatistics {
+	if je.ruleStatistics == nil {
+		return nil
+	}
+	return je.ruleStatistics.snapshot()
+}
+
+type flagRuleStatistics struct {
+	// targeting is the rule the statistics were recorded for, they're reset when the rule of the flag changes
+	targeting string
+	FlagRuleStatistics
+}
+
+type ruleStatistics struct {
+	mu    sync.Mutex
+	flags map[string]*flagRuleStatistics
+}
+
+func (s *ruleStatistics) record(flagKey string, targeting string, branch string, conditions int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.flags[flagKey]
+	if !ok || stats.targeting != targeting {
+		stats = &flagRuleStatistics{
+			targeting:          targeting,
+			FlagRuleStatistics: FlagRuleStatistics{Branches: map[string]int64{}},
+		}
+		s.flags[flagKey] = stats
+	}
+	stats.Evaluations++
+	stats.Branches[branch]++
+	stats.ConditionsEvaluated += int64(conditions)
+}
+
+func (s *ruleStatistics) snapshot() map[string]FlagRuleStatistics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[string]FlagRuleStatistics, len(s.flags))
+	for flagKey, stats := range s.flags {
+		branches := make(map[string]int64, len(stats.Branches))
+		for branch, n := range stats.Branches {
+			branches[branch] = n
+		}
+		snapshot[flagKey] = FlagRuleStatistics{
+			Evaluations:         stats.Evaluations,
+			Branches:            branches,
+			ConditionsEvaluated: stats.ConditionsEvaluated,
+		}
+	}
+	return snapshot
+}
+
+// recordRuleStatistics records the branch of the top-level conditional of the rule matching the data, rules of
+// other operators aren't recorded
+func (je *JSONEvaluator) recordRuleStatistics(flagKey string, targeting []byte, rule interface{}, data interface{}) {
+	branch, conditions, ok := matchedBranch(rule, data)
+	if !ok {
+		return
+	}
+	je.ruleStatistics.record(flagKey, string(targeting), branch, conditions)
+}
+
+// matchedBranch evaluates the conditions of a top-level if until one matches, as the if operation does, returning
+// the matched branch and the number of evaluated conditions
+func matchedBranch(rule interface{}, data interface{}) (string, int, bool) {
+	operation, ok := rule.(map[string]interface{})
+	if !ok || len(operation) != 1 {
+		return "", 0, false
+	}
+	var args []interface{}
+	for operator, values := range operation {
+		if operator != ifOperator && operator != ternaryOperator {
+			return "", 0, false
+		}
+		if args, ok = values.([]interface{}); !ok || len(args) == 0 {
+			return "", 0, false
+		}
+	}
+
+	conditions := 0
+	for i := 0; i < len(args)-1; i += conditionalArgPairs {
+		conditions++
+		if matched, err := conditionMatches(args[i], data); err == nil && matched {
+			return strconv.Itoa(i / conditionalArgPairs), conditions, true
+		}
+	}
+	if len(args)%conditionalArgPairs == 1 {
+		return elseBranch, conditions, true
+	}
+	return noBranch, conditions, true
+}
+
+// ruleStatisticsOf returns the rule statistics of the evaluator, nil if it doesn't record them
+func ruleStatisticsOf(evaluator IEvaluator) map[string]FlagRuleStatistics {
+	stats, ok := evaluator.(RuleStatistics)
+	if !ok {
+		return nil
+	}
+	return stats.RuleStatistics()
+}
@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const ruleStatisticsFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00", "yellow": "#FFFF00" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "plan" }, "enterprise"] }, "blue",
+          { "==": [{ "var": "plan" }, "pro"] }, "green",
+          { "in": ["@faas.com", { "var": "email" }] }, "yellow",
+          "red"
+        ]
+      }
+    },
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "var": "beta" }, "blue"] }
+    },
+    "split": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }
+    }
+  }
+}`
+
+func TestRuleStatistics(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig, WithRuleStatistics(true))
+	require.Nil(t, err)
+	evaluate := func(flagKey string, values map[string]interface{}) {
+		t.Helper()
+		evalCtx, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		_, _, _, _, err = je.ResolveStringValue(context.Background(), "", flagKey, evalCtx)
+		require.Nil(t, err)
+	}
+
+	evaluate("headerColor", map[string]interface{}{"plan": "enterprise"})
+	evaluate("headerColor", map[string]interface{}{"plan": "pro"})
+	evaluate("headerColor", map[string]interface{}{"plan": "pro"})
+	evaluate("headerColor", map[string]interface{}{"plan": "free", "email": "user@faas.com"})
+	evaluate("headerColor", map[string]interface{}{"plan": "free", "email": "user@example.com"})
+	evaluate("beta", map[string]interface{}{"beta": true})
+	evaluate("beta", map[string]interface{}{"beta": false})
+	evaluate("beta", map[string]interface{}{})
+	evaluate("split", map[string]interface{}{"email": "user@faas.com"})
+
+	stats := je.RuleStatistics()
+	require.Equal(t, map[string]FlagRuleStatistics{
+		"headerColor": {
+			Evaluations:         5,
+			Branches:            map[string]int64{"0": 1, "1": 2, "2": 1, elseBranch: 1},
+			ConditionsEvaluated: 1 + 2*2 + 3 + 3,
+		},
+		"beta": {
+			Evaluations:         3,
+			Branches:            map[string]int64{"0": 1, noBranch: 2},
+			ConditionsEvaluated: 3,
+		},
+	}, stats, "only top-level if rules must be recorded")
+	require.Equal(t, 11.0/5, stats["headerColor"].AverageConditions())
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "var": "beta" }, "blue", "red"] }
+    }
+  }
+}`, Source: "beta.json", Type: sync.ALL})
+	require.Nil(t, err)
+	evaluate("beta", map[string]interface{}{})
+	require.Equal(t, FlagRuleStatistics{
+		Evaluations:         1,
+		Branches:            map[string]int64{elseBranch: 1},
+		ConditionsEvaluated: 1,
+	}, je.RuleStatistics()["beta"], "the statistics of a changed rule must be reset")
+}
+
+func TestRuleStatisticsDisabled(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig)
+	require.Nil(t, err)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "pro"})
+	require.Nil(t, err)
+	_, _, _, _, err = je.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	require.Nil(t, err)
+	require.Nil(t, je.RuleStatistics())
+}
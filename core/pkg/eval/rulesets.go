@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// rulesetRuleKey is the key the targeting rule of a ruleset of a flag is cached under, apart from the targeting of
+// the flag so evaluations alternating rulesets don't re-parse their rules
+func rulesetRuleKey(flagKey string, ruleset string) string {
+	return flagKey + "[" + ruleset + "]"
+}
+
+// validateRulesets checks the rulesets of a flag, parsing and validating each of their rules as a targeting rule,
+// and returns the parsed rules
+func (je *JSONEvaluator) validateRulesets(
+	key string,
+	flag model.Flag,
+	parseRule func(flagKey string, targeting json.RawMessage) (interface{}, error),
+) ([]interface{}, error) {
+	rulesets := flag.Rulesets
+	if rulesets == nil {
+		return nil, nil
+	}
+	if rulesets.ContextKey == "" {
+		return nil, fmt.Errorf("rulesets of flag: '%s' have no context key", key)
+	}
+	if flag.Derived != nil {
+		return nil, fmt.Errorf("flag: '%s' can't be derived and have rulesets", key)
+	}
+	rules := make([]interface{}, 0, len(rulesets.Rules))
+	for name, targeting := range rulesets.Rules {
+		if string(targeting) == "{}" {
+			// an empty ruleset resolves the default variant
+			continue
+		}
+		rule, err := je.validateTargeting(key, rulesetRuleKey(key, name), flag, targeting, parseRule)
+		if err != nil {
+			return nil, fmt.Errorf("ruleset: '%s': %w", name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// selectTargeting returns the targeting rule of the flag for the context, along with the key its parsed rule is
+// cached under: the rule of the ruleset mapped to the value of the context key of its rulesets if any, its
+// targeting otherwise
+func (je *JSONEvaluator) selectTargeting(
+	flagKey string, flag model.Flag, context *structpb.Struct,
+) (json.RawMessage, string) {
+	rulesets := flag.Rulesets
+	if rulesets == nil {
+		return flag.Targeting, flagKey
+	}
+	value, ok := contextValueKey(contextPathValue(je.normalizeContext(context.AsMap()), rulesets.ContextKey))
+	if !ok {
+		return flag.Targeting, flagKey
+	}
+	if targeting, ok := rulesets.Rules[value]; ok {
+		return targeting, rulesetRuleKey(flagKey, value)
+	}
+	return flag.Targeting, flagKey
+}
@@ -0,0 +1,132 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const rulesetsFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00", "yellow": "#FFFF00" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "yellow", null] },
+      "rulesets": {
+        "contextKey": "environment",
+        "rules": {
+          "production": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "blue", null] },
+          "staging": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "green", "blue"] },
+          "development": {}
+        }
+      }
+    }
+  }
+}`
+
+func TestRulesets(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, rulesetsFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		context map[string]interface{}
+		variant string
+		reason  string
+	}{
+		"production ruleset": {
+			context: map[string]interface{}{"environment": "production", "email": "user@faas.com"},
+			variant: "blue", reason: model.TargetingMatchReason,
+		},
+		"production ruleset not matching": {
+			context: map[string]interface{}{"environment": "production", "email": "user@example.com"},
+			variant: "red", reason: model.DefaultReason,
+		},
+		"staging ruleset": {
+			context: map[string]interface{}{"environment": "staging", "email": "user@faas.com"},
+			variant: "green", reason: model.TargetingMatchReason,
+		},
+		"staging ruleset fallback branch": {
+			context: map[string]interface{}{"environment": "staging", "email": "user@example.com"},
+			variant: "blue", reason: model.TargetingMatchReason,
+		},
+		"empty ruleset": {
+			context: map[string]interface{}{"environment": "development", "email": "user@faas.com"},
+			variant: "red", reason: model.DefaultReason,
+		},
+		"unmapped context value falls back to the targeting": {
+			context: map[string]interface{}{"environment": "qa", "email": "user@faas.com"},
+			variant: "yellow", reason: model.TargetingMatchReason,
+		},
+		"missing context key falls back to the targeting": {
+			context: map[string]interface{}{"email": "user@faas.com"},
+			variant: "yellow", reason: model.TargetingMatchReason,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+			require.Nil(t, err)
+			require.Equal(t, tt.variant, variant)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestRulesets_WithoutTargeting(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "rulesets": { "contextKey": "environment", "rules": { "staging": { "if": [true, "on", "off"] } } }
+    }
+  }
+}`)
+	require.Nil(t, err)
+
+	value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "beta", &structpb.Struct{})
+	require.Nil(t, err)
+	require.False(t, value)
+	require.Equal(t, model.DefaultReason, reason, "resolutions of flags with rulesets depend on the context")
+
+	ctx, err := structpb.NewStruct(map[string]interface{}{"environment": "staging"})
+	require.Nil(t, err)
+	value, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "beta", ctx)
+	require.Nil(t, err)
+	require.True(t, value)
+}
+
+func TestRulesets_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		rulesets string
+		err      string
+	}{
+		"no context key": {
+			rulesets: `{ "rules": {} }`,
+			err:      "rulesets of flag: 'beta' have no context key",
+		},
+		"unsupported operator": {
+			rulesets: `{ "contextKey": "environment", "rules": { "staging": { "unknown": [] } } }`,
+			err:      "ruleset: 'staging': targeting of flag: 'beta'",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "rulesets": `+tt.rulesets+`
+    }
+  }
+}`)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Sandbox is implemented by evaluators able to evaluate flag definitions which aren't part of their configuration
+type Sandbox interface {
+	EvaluateSandbox(reqID string, flagKey string, definition json.RawMessage, context *structpb.Struct,
+	) (SandboxEvaluation, error)
+}
+
+// SandboxEvaluation is the resolution of an inline flag definition, along with the steps of its evaluation
+type SandboxEvaluation struct {
+	Value    interface{}
+	Variant  string
+	Reason   string
+	Metadata map[string]interface{}
+	// ErrorCode is set if the evaluation failed
+	ErrorCode string
+	Trace     []string
+}
+
+func (s *SandboxEvaluation) trace(format string, args ...interface{}) {
+	s.Trace = append(s.Trace, fmt.Sprintf(format, args...))
+}
+
+// EvaluateSandbox validates an inline flag definition and evaluates it against the context, without storing it or
+// caching its targeting rule. Derived definitions may reference the stored flags. Invalid definitions return an
+// error, failed evaluations return their error code.
+func (je *JSONEvaluator) EvaluateSandbox(
+	reqID string, flagKey string, definition json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	flagSchema, err := compiledFlagSchema()
+	if err != nil {
+		return SandboxEvaluation{}, fmt.Errorf("compiling flag schema: %w", err)
+	}
+	flag, err := je.validateFlagDefinition(flagSchema, flagKey, definition, je.parseRule)
+	if err != nil {
+		return SandboxEvaluation{}, err
+	}
+
+	var eval SandboxEvaluation
+	eval.trace("validated the definition of flag: %s", flagKey)
+	return je.evaluateDefinition(reqID, flagKey, flag, context, eval), nil
+}
+
+// evaluateDefinition evaluates the flag definition against the context without caching its targeting rule, adding
+// the steps of the evaluation to its trace
+func (je *JSONEvaluator) evaluateDefinition(
+	reqID string, flagKey string, flag model.Flag, evalCtx *structpb.Struct, eval SandboxEvaluation,
+) SandboxEvaluation {
+	var metadata map[string]interface{}
+	var err error
+	// prerequisites are evaluated against the request context, with their own base context
+	request := evalCtx
+	if len(flag.BaseContext) != 0 {
+		evalCtx = withBaseContext(flag, evalCtx)
+		eval.trace("merged the base context under the evaluation context")
+	}
+	if flag.Rulesets != nil {
+		var ruleKey string
+		if flag.Targeting, ruleKey = je.selectTargeting(flagKey, flag, evalCtx); ruleKey != flagKey {
+			eval.trace("selected the ruleset of context key: %s", flag.Rulesets.ContextKey)
+		}
+	}
+	switch {
+	case flag.State == Disabled:
+		eval.trace("flag is disabled")
+		return eval.failed(model.FlagDisabledErrorCode)
+	case flag.Derived != nil:
+		eval.trace("evaluating derived expression: %s", compact(flag.Derived))
+		eval.Variant, eval.Reason, metadata, err = je.evaluateDerived(
+			context.Background(), reqID, flagKey, flag, request, nil, nil)
+		if err != nil {
+			eval.trace("derived expression failed: %s", err)
+			return eval.failed(err.Error())
+		}
+		eval.trace("derived expression resolved variant: %s", eval.Variant)
+	case flag.Targeting != nil && string(flag.Targeting) != "{}":
+		rule, err := je.parseRule(flagKey, flag.Targeting)
+		if err != nil {
+			eval.trace("parsing targeting failed: %s", err)
+			return eval.failed(model.ParseErrorCode)
+		}
+		eval.trace("evaluating targeting: %s", compact(flag.Targeting))
+		result, err := jsonlogic.ApplyInterface(je.bindRule(rule), je.targetingData(flag, evalCtx.AsMap()))
+		if err != nil {
+			eval.trace("targeting failed: %s", err)
+			return eval.failed(model.GeneralErrorCode)
+		}
+		if raw, err := json.Marshal(result); err == nil {
+			eval.trace("targeting resolved: %s", raw)
+		}
+		eval.Variant, metadata = parseTargetingResult(result)
+		if _, ok := flag.Variants[eval.Variant]; ok {
+			eval.trace("variant: %s matched", eval.Variant)
+			eval.Reason = model.TargetingMatchReason
+			break
+		}
+		defaultVariant := je.defaultVariant(flag, evalCtx)
+		eval.trace("'%s' isn't a variant of the flag, resolving the default variant: %s", eval.Variant,
+			defaultVariant)
+		eval.Variant, eval.Reason, metadata = defaultVariant, model.DefaultReason, nil
+	default:
+		defaultVariant := je.defaultVariant(flag, evalCtx)
+		eval.trace("flag has no targeting, resolving the default variant: %s", defaultVariant)
+		eval.Variant, eval.Reason = defaultVariant, defaultReason(flag)
+	}
+	eval.Value = flag.Variants[eval.Variant]
+	eval.Metadata = resolutionMetadata(flag, metadata)
+	return eval
+}
+
+// evaluateSandbox evaluates the inline flag definition with the evaluator, if it's a sandbox
+func evaluateSandbox(
+	evaluator IEvaluator, reqID string, flagKey string, definition json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	sandbox, ok := evaluator.(Sandbox)
+	if !ok {
+		return SandboxEvaluation{}, errors.New("the evaluator can't evaluate inline flag definitions")
+	}
+	return sandbox.EvaluateSandbox(reqID, flagKey, definition, context)
+}
+
+// compact strips the insignificant whitespace of the json, keeping traces on a single line
+func compact(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+func (s SandboxEvaluation) failed(errorCode string) SandboxEvaluation {
+	s.Variant, s.Reason, s.ErrorCode = "", model.ErrorReason, errorCode
+	return s
+}
@@ -0,0 +1,27 @@
+package eval
+
+import "encoding/json"
+
+// WithSourcePrefixes prefixes the keys of the flags of each source with the prefix of its URI, e.g. teamA/ loads
+// flagX as teamA/flagX, so sources defining the same keys don't collide. Flags resolve through their prefixed key.
+func WithSourcePrefixes(prefixes map[string]string) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.sourcePrefixes = prefixes
+	}
+}
+
+// prefixFlagKeys returns the definitions of the flags of the source keyed by their prefixed key, the definitions as
+// they are if the source has no prefix
+func (je *JSONEvaluator) prefixFlagKeys(
+	source string, definitions map[string]json.RawMessage,
+) map[string]json.RawMessage {
+	prefix := je.sourcePrefixes[source]
+	if prefix == "" {
+		return definitions
+	}
+	prefixed := make(map[string]json.RawMessage, len(definitions))
+	for key, definition := range definitions {
+		prefixed[prefix+key] = definition
+	}
+	return prefixed
+}
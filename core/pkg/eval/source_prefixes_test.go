@@ -0,0 +1,77 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourcePrefixes(t *testing.T) {
+	const config = `{
+  "flags": {
+    "flagX": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "%s" }
+  }
+}`
+	flags := store.NewFlags()
+	flags.FlagSources = []string{"team-a.json", "team-b.json"}
+	flags.DuplicateKeys = store.DuplicateKeysError
+	evaluator := eval.NewJSONEvaluator(nil, flags, eval.WithSourcePrefixes(map[string]string{
+		"team-a.json": "teamA/",
+		"team-b.json": "teamB/",
+	}))
+
+	_, _, err := evaluator.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(config, "on"), Source: "team-a.json", Type: sync.ALL,
+	})
+	require.Nil(t, err)
+	_, _, err = evaluator.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(config, "off"), Source: "team-b.json", Type: sync.ALL,
+	})
+	require.Nil(t, err, "flags with the same base key shouldn't collide across prefixed sources")
+
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "teamA/flagX", nil)
+	require.Nil(t, err)
+	require.True(t, value)
+	value, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "teamB/flagX", nil)
+	require.Nil(t, err)
+	require.False(t, value)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "flagX", nil)
+	require.NotNil(t, err, "flags should only resolve through their prefixed key")
+
+	// removing a flag of a source removes its prefixed key only
+	_, _, err = evaluator.SetState(sync.DataSync{
+		FlagData: fmt.Sprintf(config, "on"), Source: "team-a.json", Type: sync.DELETE,
+	})
+	require.Nil(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "teamA/flagX", nil)
+	require.NotNil(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", "teamB/flagX", nil)
+	require.Nil(t, err)
+}
+
+func TestSourcePrefixes_Unprefixed(t *testing.T) {
+	flags := store.NewFlags()
+	flags.FlagSources = []string{"team-a.json", "shared.json"}
+	flags.DuplicateKeys = store.DuplicateKeysError
+	evaluator := eval.NewJSONEvaluator(nil, flags, eval.WithSourcePrefixes(map[string]string{
+		"team-a.json": "teamA/",
+	}))
+	const config = `{
+  "flags": {
+    "flagX": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config, Source: "team-a.json", Type: sync.ALL})
+	require.Nil(t, err)
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: config, Source: "shared.json", Type: sync.ALL})
+	require.Nil(t, err)
+	for _, key := range []string{"teamA/flagX", "flagX"} {
+		_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "", key, nil)
+		require.Nil(t, err, key)
+	}
+}
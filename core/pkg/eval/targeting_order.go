@@ -0,0 +1,313 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// WithTargetingReordering orders the conditions of the targeting of the flags whose conditions are exclusive by the
+// number of evaluations they matched while the previous configuration was served, so the most frequently matching
+// conditions are evaluated first. The order is computed when a configuration is loaded and fixed until the next
+// one, flags hinting the order of their conditions keep their order.
+func WithTargetingReordering(enabled bool) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.targetingReordering = enabled
+	}
+}
+
+// orderedTargeting evaluates the top-level if of the targeting of a flag one condition at a time in the evaluation
+// order, the library's if evaluating each of its conditions and values
+type orderedTargeting struct {
+	// targeting is the rule the order was computed for, other rules of the flag, e.g. of its rulesets, are evaluated
+	// as declared
+	targeting  string
+	conditions []interface{}
+	values     []interface{}
+	// elseValue is the value of the if when no condition matches, if hasElse
+	elseValue interface{}
+	hasElse   bool
+	order     []int
+	exclusive bool
+	// matches counts the evaluations matching each declared condition, nil unless the flag is reordered
+	matches []atomic.Int64
+}
+
+// apply evaluates the conditions in the evaluation order until one matches, returning its value. Unless the
+// conditions are exclusive, the conditions declared before the match which weren't evaluated yet are evaluated, so
+// the result is the one of the declared order.
+func (o *orderedTargeting) apply(data interface{}) (interface{}, error) {
+	evaluated := make([]bool, len(o.conditions))
+	for _, i := range o.order {
+		matched, err := conditionMatches(o.conditions[i], data)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			evaluated[i] = true
+			continue
+		}
+		first := i
+		for j := 0; j < i && !o.exclusive; j++ {
+			if evaluated[j] {
+				continue
+			}
+			if matched, err = conditionMatches(o.conditions[j], data); err != nil {
+				return nil, err
+			}
+			if matched {
+				first = j
+				break
+			}
+		}
+		if o.matches != nil {
+			o.matches[first].Add(1)
+		}
+		return jsonlogic.ApplyInterface(o.values[first], data)
+	}
+	if o.hasElse {
+		return jsonlogic.ApplyInterface(o.elseValue, data)
+	}
+	return nil, nil
+}
+
+// conditionMatches evaluates a condition of an if, its truthiness matching the evaluation of the if operation
+func conditionMatches(condition interface{}, data interface{}) (bool, error) {
+	matched, err := jsonlogic.ApplyInterface(map[string]interface{}{
+		ifOperator: []interface{}{condition, true, false},
+	}, data)
+	return matched == true, err
+}
+
+// topLevelIf returns the arguments of the top-level if of a rule
+func topLevelIf(rule interface{}) ([]interface{}, bool) {
+	operation, ok := rule.(map[string]interface{})
+	if !ok || len(operation) != 1 {
+		return nil, false
+	}
+	args, ok := operation[ifOperator].([]interface{})
+	return args, ok && len(args) >= conditionalArgPairs
+}
+
+// validateTargetingOrder checks the targeting order of a flag hints the order of the conditions of a top-level if
+func validateTargetingOrder(key string, flag model.Flag, rule interface{}) error {
+	if flag.TargetingOrder == nil {
+		return nil
+	}
+	args, ok := topLevelIf(rule)
+	if !ok {
+		return fmt.Errorf("targetingOrder of flag: '%s' requires a targeting whose top-level operator is if", key)
+	}
+	conditions := len(args) / conditionalArgPairs
+	seen := make(map[int]struct{}, len(flag.TargetingOrder.Conditions))
+	for _, i := range flag.TargetingOrder.Conditions {
+		if i < 0 || i >= conditions {
+			return fmt.Errorf("targetingOrder of flag: '%s' orders condition: %d, the targeting has %d conditions",
+				key, i, conditions)
+		}
+		if _, ok := seen[i]; ok {
+			return fmt.Errorf("targetingOrder of flag: '%s' orders condition: %d more than once", key, i)
+		}
+		seen[i] = struct{}{}
+	}
+	return nil
+}
+
+// orderTargeting computes the evaluation order of the targeting of the flags once a configuration is loaded. Flags
+// hinting an order are evaluated in that order, flags whose conditions are exclusive are reordered by the matches
+// counted by the previous order of their targeting when reordering, the order of other flags isn't changed.
+func (je *JSONEvaluator) orderTargeting() {
+	previous := je.targetingOrders.Load()
+	orders := map[string]*orderedTargeting{}
+	for key, flag := range je.store.GetAll() {
+		if flag.TargetingOrder == nil && !je.targetingReordering {
+			continue
+		}
+		if flag.Targeting == nil || flag.Derived != nil {
+			continue
+		}
+		rule, err := je.boundTargetingRule(key, flag.Targeting)
+		if err != nil {
+			continue
+		}
+		args, ok := topLevelIf(rule)
+		if !ok {
+			continue
+		}
+		ordered := newOrderedTargeting(flag, args)
+		hinted := flag.TargetingOrder != nil && len(flag.TargetingOrder.Conditions) != 0
+		switch {
+		case hinted:
+			ordered.order = hintedOrder(flag.TargetingOrder.Conditions, len(ordered.conditions))
+		case je.targetingReordering && ordered.exclusive:
+			var counted *orderedTargeting
+			if previous != nil {
+				counted = (*previous)[key]
+			}
+			ordered.order = reorderedConditions(ordered, counted)
+			ordered.matches = make([]atomic.Int64, len(ordered.conditions))
+			je.Logger.Debug(fmt.Sprintf("ordered the conditions of flag: %s as %v", key, ordered.order))
+		case flag.TargetingOrder == nil:
+			continue
+		}
+		orders[key] = ordered
+	}
+	je.targetingOrders.Store(&orders)
+}
+
+// orderedTargetingOf returns the evaluation order of the targeting of a flag, nil if it's evaluated as declared
+func (je *JSONEvaluator) orderedTargetingOf(flagKey string, targeting []byte) *orderedTargeting {
+	orders := je.targetingOrders.Load()
+	if orders == nil {
+		return nil
+	}
+	if ordered, ok := (*orders)[flagKey]; ok && ordered.targeting == string(targeting) {
+		return ordered
+	}
+	return nil
+}
+
+func newOrderedTargeting(flag model.Flag, args []interface{}) *orderedTargeting {
+	ordered := &orderedTargeting{targeting: string(flag.Targeting)}
+	for i := 0; i < len(args)-1; i += conditionalArgPairs {
+		ordered.conditions = append(ordered.conditions, args[i])
+		ordered.values = append(ordered.values, args[i+1])
+	}
+	if len(args)%conditionalArgPairs == 1 {
+		ordered.elseValue, ordered.hasElse = args[len(args)-1], true
+	}
+	ordered.order = hintedOrder(nil, len(ordered.conditions))
+	ordered.exclusive = (flag.TargetingOrder != nil && flag.TargetingOrder.Exclusive) ||
+		exclusiveConditions(ordered.conditions)
+	return ordered
+}
+
+// hintedOrder returns the hinted conditions followed by the conditions left out, in their declared order
+func hintedOrder(hint []int, conditions int) []int {
+	order := make([]int, 0, conditions)
+	hinted := make(map[int]struct{}, len(hint))
+	for _, i := range hint {
+		order = append(order, i)
+		hinted[i] = struct{}{}
+	}
+	for i := 0; i < conditions; i++ {
+		if _, ok := hinted[i]; !ok {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// reorderedConditions orders the conditions by the matches counted by their previous order, most matches first and
+// ties broken by the previous order, keeping the previous order if its targeting changed or it counted no match
+func reorderedConditions(ordered *orderedTargeting, previous *orderedTargeting) []int {
+	if previous == nil || previous.matches == nil || previous.targeting != ordered.targeting {
+		return ordered.order
+	}
+	order := append([]int(nil), previous.order...)
+	var total int64
+	for i := range previous.matches {
+		total += previous.matches[i].Load()
+	}
+	if total == 0 {
+		return order
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return previous.matches[order[a]].Load() > previous.matches[order[b]].Load()
+	})
+	return order
+}
+
+// exclusiveConditions reports whether the conditions compare the same context value with distinct string literals
+// by ==, === or in, e.g. {"==": [{"var": "plan"}, "pro"]} and {"in": [{"var": "plan"}, ["basic", "free"]]}, so no
+// evaluation context matches more than one of them. Numeric strings aren't distinct, == compares them as numbers.
+func exclusiveConditions(conditions []interface{}) bool {
+	var path string
+	seen := map[string]struct{}{}
+	for i, condition := range conditions {
+		conditionPath, literals, ok := comparedLiterals(condition)
+		if !ok || (i > 0 && conditionPath != path) {
+			return false
+		}
+		path = conditionPath
+		for _, literal := range literals {
+			if _, ok := seen[literal]; ok {
+				return false
+			}
+			seen[literal] = struct{}{}
+		}
+	}
+	return len(conditions) > 0
+}
+
+// comparedLiterals returns the context path and the string literals a condition compares it with
+func comparedLiterals(condition interface{}) (string, []string, bool) {
+	operation, ok := condition.(map[string]interface{})
+	if !ok || len(operation) != 1 {
+		return "", nil, false
+	}
+	for operator, values := range operation {
+		args, ok := values.([]interface{})
+		if !ok || len(args) != 2 {
+			return "", nil, false
+		}
+		switch operator {
+		case "==", "===":
+			if path, ok := contextPath(args[0]); ok {
+				literal, ok := distinctLiteral(args[1])
+				return path, []string{literal}, ok
+			}
+			path, ok := contextPath(args[1])
+			if !ok {
+				return "", nil, false
+			}
+			literal, ok := distinctLiteral(args[0])
+			return path, []string{literal}, ok
+		case "in":
+			path, ok := contextPath(args[0])
+			list, isList := args[1].([]interface{})
+			if !ok || !isList {
+				return "", nil, false
+			}
+			literals := make([]string, 0, len(list))
+			for _, value := range list {
+				literal, ok := distinctLiteral(value)
+				if !ok {
+					return "", nil, false
+				}
+				literals = append(literals, literal)
+			}
+			return path, literals, true
+		}
+	}
+	return "", nil, false
+}
+
+// contextPath returns the path of a var operation
+func contextPath(value interface{}) (string, bool) {
+	operation, ok := value.(map[string]interface{})
+	if !ok || len(operation) != 1 {
+		return "", false
+	}
+	args, ok := operation[varOperator]
+	if !ok {
+		return "", false
+	}
+	return varPath(args)
+}
+
+// distinctLiteral returns a string literal which equals no other string literal by ==
+func distinctLiteral(value interface{}) (string, bool) {
+	literal, ok := value.(string)
+	if !ok || literal == "" || literal == "true" || literal == "false" {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err == nil {
+		return "", false
+	}
+	return literal, true
+}
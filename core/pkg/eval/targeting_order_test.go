@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// orderFlagConfig holds the overlapping conditions of the tier flag and the exclusive conditions of the plan flag,
+// formatted with the targeting order of each
+const orderFlagConfig = `{
+  "flags": {
+    "tier": {
+      "state": "ENABLED",
+      "variants": { "adult": "adult", "pro": "pro", "staff": "staff", "other": "other" },
+      "defaultVariant": "other",
+      "targeting": { "if": [
+        { ">=": [{ "var": "age" }, 18] }, "adult",
+        { "==": [{ "var": "plan" }, "pro"] }, "pro",
+        { "in": ["@faas.com", { "var": "email" }] }, "staff",
+        "other"
+      ] }%s
+    },
+    "plan": {
+      "state": "ENABLED",
+      "variants": { "basic": "basic", "pro": "pro", "team": "team" },
+      "defaultVariant": "basic",
+      "targeting": { "if": [
+        { "==": [{ "var": "plan" }, "basic"] }, "basic",
+        { "===": ["pro", { "var": "plan" }] }, "pro",
+        { "in": [{ "var": "plan" }, ["team", "enterprise"]] }, "team"
+      ] }%s
+    }
+  }
+}`
+
+func orderConfig(tierOrder string, planOrder string) string {
+	order := func(targetingOrder string) string {
+		if targetingOrder == "" {
+			return ""
+		}
+		return `, "targetingOrder": ` + targetingOrder
+	}
+	return fmt.Sprintf(orderFlagConfig, order(tierOrder), order(planOrder))
+}
+
+// orderContexts covers the matches of each condition, of overlapping conditions and of no condition
+func orderContexts() []map[string]interface{} {
+	var contexts []map[string]interface{}
+	for _, age := range []interface{}{nil, 12, 30} {
+		for _, plan := range []interface{}{nil, "basic", "pro", "team", "enterprise"} {
+			for _, email := range []interface{}{nil, "user@faas.com", "user@example.com"} {
+				values := map[string]interface{}{}
+				for key, value := range map[string]interface{}{"age": age, "plan": plan, "email": email} {
+					if value != nil {
+						values[key] = value
+					}
+				}
+				contexts = append(contexts, values)
+			}
+		}
+	}
+	return contexts
+}
+
+func resolvedTargeting(t *testing.T, je *JSONEvaluator, flagKey string, contexts []map[string]interface{}) []string {
+	t.Helper()
+	results := make([]string, 0, len(contexts))
+	for _, values := range contexts {
+		evalCtx, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		variant, reason, _, err := je.evaluateVariant(context.Background(), "", flagKey, evalCtx)
+		require.Nil(t, err)
+		results = append(results, variant+"/"+reason)
+	}
+	return results
+}
+
+func TestTargetingOrder_Correctness(t *testing.T) {
+	contexts := orderContexts()
+	declared, err := NewJSONEvaluatorFromConfig(nil, orderConfig("", ""))
+	require.Nil(t, err)
+	wantTier := resolvedTargeting(t, declared, "tier", contexts)
+	wantPlan := resolvedTargeting(t, declared, "plan", contexts)
+
+	orders := []string{
+		`{}`,
+		`{"conditions": [2, 1, 0]}`,
+		`{"conditions": [1]}`,
+		`{"conditions": [2, 0]}`,
+	}
+	for _, order := range orders {
+		t.Run(order, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, orderConfig(order, order))
+			require.Nil(t, err)
+			require.NotNil(t, je.orderedTargetingOf("tier", []byte(mustFlag(t, je, "tier"))))
+			require.Equal(t, wantTier, resolvedTargeting(t, je, "tier", contexts),
+				"the order of overlapping conditions shouldn't change the results")
+			require.Equal(t, wantPlan, resolvedTargeting(t, je, "plan", contexts),
+				"the order of exclusive conditions shouldn't change the results")
+		})
+	}
+}
+
+func TestTargetingOrder_Reordering(t *testing.T) {
+	contexts := orderContexts()
+	config := orderConfig("", "")
+	declared, err := NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	je, err := NewJSONEvaluatorFromConfig(nil, config, WithTargetingReordering(true))
+	require.Nil(t, err)
+	order := func(flagKey string) []int {
+		t.Helper()
+		ordered := je.orderedTargetingOf(flagKey, []byte(mustFlag(t, je, flagKey)))
+		if ordered == nil {
+			return nil
+		}
+		return ordered.order
+	}
+	require.Nil(t, order("tier"), "flags whose conditions overlap shouldn't be reordered")
+	require.Equal(t, []int{0, 1, 2}, order("plan"))
+
+	enterprise := []map[string]interface{}{{"plan": "enterprise"}}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, []string{"team/TARGETING_MATCH"}, resolvedTargeting(t, je, "plan", enterprise))
+	}
+	resolvedTargeting(t, je, "plan", []map[string]interface{}{{"plan": "pro"}})
+	require.Equal(t, []int{0, 1, 2}, order("plan"), "the order should be fixed until the next configuration")
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: config, Source: "file:flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	require.Equal(t, []int{2, 1, 0}, order("plan"))
+	require.Equal(t, resolvedTargeting(t, declared, "plan", contexts), resolvedTargeting(t, je, "plan", contexts))
+
+	for i := 0; i < 10; i++ {
+		resolvedTargeting(t, je, "plan", []map[string]interface{}{{"plan": "pro"}})
+	}
+	_, _, err = je.SetState(sync.DataSync{FlagData: config, Source: "file:flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	require.Equal(t, []int{1, 2, 0}, order("plan"), "the matches of the previous configuration should order it")
+}
+
+func TestTargetingOrder_Hinted(t *testing.T) {
+	je, err := NewJSONEvaluatorFromConfig(nil, orderConfig("", `{"conditions": [1]}`), WithTargetingReordering(true))
+	require.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		resolvedTargeting(t, je, "plan", []map[string]interface{}{{"plan": "team"}})
+	}
+	_, _, err = je.SetState(sync.DataSync{FlagData: orderConfig("", `{"conditions": [1]}`), Type: sync.ALL})
+	require.Nil(t, err)
+	ordered := je.orderedTargetingOf("plan", []byte(mustFlag(t, je, "plan")))
+	require.NotNil(t, ordered)
+	require.Equal(t, []int{1, 0, 2}, ordered.order, "hinted orders shouldn't be reordered")
+}
+
+func TestTargetingOrder_Validation(t *testing.T) {
+	tests := map[string]struct {
+		order string
+		err   string
+	}{
+		"out of range": {
+			order: `{"conditions": [3]}`,
+			err:   "targetingOrder of flag: 'tier' orders condition: 3, the targeting has 3 conditions",
+		},
+		"negative": {
+			order: `{"conditions": [-1]}`,
+			err:   "targetingOrder of flag: 'tier' orders condition: -1, the targeting has 3 conditions",
+		},
+		"duplicate": {
+			order: `{"conditions": [1, 1]}`,
+			err:   "targetingOrder of flag: 'tier' orders condition: 1 more than once",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJSONEvaluatorFromConfig(nil, orderConfig(tt.order, ""))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+
+	_, err := NewJSONEvaluatorFromConfig(nil, `{"flags": {"static": {"state": "ENABLED", "variants": {"on": true}, `+
+		`"defaultVariant": "on", "targetingOrder": {"exclusive": true}}}}`)
+	require.EqualError(t, err, "targetingOrder of flag: 'static' requires a targeting whose top-level operator is if")
+}
+
+func TestExclusiveConditions(t *testing.T) {
+	tests := map[string]struct {
+		conditions string
+		exclusive  bool
+	}{
+		"distinct literals": {
+			conditions: `[{"==": [{"var": "plan"}, "basic"]}, {"in": [{"var": ["plan", "basic"]}, ["pro", "team"]]}]`,
+			exclusive:  true,
+		},
+		"repeated literal": {
+			conditions: `[{"==": [{"var": "plan"}, "pro"]}, {"in": [{"var": "plan"}, ["pro", "team"]]}]`,
+		},
+		"other context values": {
+			conditions: `[{"==": [{"var": "plan"}, "pro"]}, {"==": [{"var": "tier"}, "team"]}]`,
+		},
+		"numeric literals": {
+			conditions: `[{"==": [{"var": "version"}, "1"]}, {"==": [{"var": "version"}, "1.0"]}]`,
+		},
+		"other operators": {
+			conditions: `[{"==": [{"var": "plan"}, "pro"]}, {"!=": [{"var": "plan"}, "pro"]}]`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := NewJSONEvaluator(nil, nil).parseRule("flag", []byte(tt.conditions))
+			require.Nil(t, err)
+			require.Equal(t, tt.exclusive, exclusiveConditions(rule.([]interface{})))
+		})
+	}
+}
+
+func BenchmarkTargetingOrder(b *testing.B) {
+	conditions := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		conditions = append(conditions, fmt.Sprintf(`{"==": [{"var": "segment"}, "segment-%d"]}, "on"`, i))
+	}
+	config := `{"flags": {"segmented": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "off", "targeting": {"if": [` + strings.Join(conditions, ", ") + `, "off"]}%s}}}`
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"segment": "segment-19"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, order := range map[string]string{
+		"declared": "",
+		"hinted":   `, "targetingOrder": {"conditions": [19]}`,
+	} {
+		je, err := NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(config, order))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, _, _, err := je.ResolveBooleanValue(context.Background(), "", "segmented", evalCtx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func mustFlag(t *testing.T, je *JSONEvaluator, flagKey string) string {
+	t.Helper()
+	flag, ok := je.store.Get(flagKey)
+	require.True(t, ok)
+	return string(flag.Targeting)
+}
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TemplateMissingKeys defines how the placeholders of templated string flags whose context key is missing are handled
+type TemplateMissingKeys string
+
+const (
+	// TemplateMissingKeysKeep leaves the placeholders of missing keys in the value, the default
+	TemplateMissingKeysKeep TemplateMissingKeys = "keep"
+	// TemplateMissingKeysError fails evaluations missing a key of their template
+	TemplateMissingKeysError TemplateMissingKeys = "error"
+)
+
+// ParseTemplateMissingKeys returns the missing template key policy of its name, an empty name defaults to keep
+func ParseTemplateMissingKeys(policy string) (TemplateMissingKeys, error) {
+	switch TemplateMissingKeys(policy) {
+	case "":
+		return TemplateMissingKeysKeep, nil
+	case TemplateMissingKeysKeep, TemplateMissingKeysError:
+		return TemplateMissingKeys(policy), nil
+	default:
+		return "", fmt.Errorf("unknown missing template key policy: '%s', expected '%s' or '%s'",
+			policy, TemplateMissingKeysKeep, TemplateMissingKeysError)
+	}
+}
+
+// WithTemplateMissingKeys sets the policy of the placeholders of templated flags missing from the evaluation context
+func WithTemplateMissingKeys(policy TemplateMissingKeys) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.templateMissingKeys = policy
+	}
+}
+
+// templatePart is either a literal of a template, or the context path of a placeholder
+type templatePart struct {
+	literal string
+	key     string
+}
+
+// parseTemplate splits a template into literals and {path} placeholders, doubled braces are literal braces
+func parseTemplate(template string) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch c := template[i]; {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"), c == '}' && strings.HasPrefix(template[i:], "}}"):
+			literal.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexAny(template[i+1:], "{}")
+			if end < 0 || template[i+1+end] != '}' {
+				return nil, fmt.Errorf("unclosed placeholder at offset %d", i)
+			}
+			key := strings.TrimSpace(template[i+1 : i+1+end])
+			if key == "" {
+				return nil, fmt.Errorf("empty placeholder at offset %d", i)
+			}
+			if literal.Len() > 0 {
+				parts = append(parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			parts = append(parts, templatePart{key: key})
+			i += end + 1
+		case c == '}':
+			return nil, fmt.Errorf("unopened placeholder at offset %d, literal braces are doubled", i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String()})
+	}
+	return parts, nil
+}
+
+// validateTemplate checks the variants of a templated flag are valid string templates
+func validateTemplate(key string, flag model.Flag) error {
+	if !flag.Template {
+		return nil
+	}
+	for variant, value := range flag.Variants {
+		template, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("variant: '%s' of templated flag: '%s' isn't a string", variant, key)
+		}
+		if _, err := parseTemplate(template); err != nil {
+			return fmt.Errorf("template of variant: '%s' of flag: '%s': %w", variant, key, err)
+		}
+	}
+	return nil
+}
+
+// renderTemplate substitutes the values of the evaluation context into the placeholders of the template
+func (je *JSONEvaluator) renderTemplate(template string, context *structpb.Struct) (string, error) {
+	parts, err := parseTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	data := je.normalizeContext(context.AsMap())
+	var rendered strings.Builder
+	for _, part := range parts {
+		if part.key == "" {
+			rendered.WriteString(part.literal)
+			continue
+		}
+		value, ok := templateValue(contextPathValue(data, part.key))
+		if !ok {
+			if je.templateMissingKeys == TemplateMissingKeysError {
+				return "", fmt.Errorf("context key: %s is missing", part.key)
+			}
+			rendered.WriteString("{" + part.key + "}")
+			continue
+		}
+		rendered.WriteString(value)
+	}
+	return rendered.String(), nil
+}
+
+// templateValue formats a context value for a template, strings are substituted as is and other values by their
+// json. Missing and null values aren't substituted.
+func templateValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(raw), true
+	}
+}
+
+// resolveTemplate renders the resolved value of a templated flag, failing the evaluation if its template can't be
+// rendered
+func (je *JSONEvaluator) resolveTemplate(
+	reqID string, flagKey string, flag model.Flag, value string, context *structpb.Struct,
+) (string, error) {
+	if !flag.Template {
+		return value, nil
+	}
+	rendered, err := je.renderTemplate(value, context)
+	if err != nil {
+		je.Logger.ErrorWithID(reqID, fmt.Sprintf("rendering the template of flag: %s: %v", flagKey, err))
+		return "", errors.New(model.GeneralErrorCode)
+	}
+	return rendered, nil
+}
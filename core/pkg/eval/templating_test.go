@@ -0,0 +1,126 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const templateFlagConfig = `{
+  "flags": {
+    "greeting": {
+      "state": "ENABLED",
+      "variants": {
+        "plain": "Hi {name}, you have {count} items",
+        "nested": "Hi {user.name}, {{literal}} braces and {active}"
+      },
+      "defaultVariant": "plain",
+      "targeting": { "if": [{ "==": [{ "var": "active" }, true] }, "nested", null] },
+      "template": true
+    },
+    "untemplated": {
+      "state": "ENABLED",
+      "variants": { "plain": "Hi {name}" },
+      "defaultVariant": "plain"
+    }
+  }
+}`
+
+func TestTemplate(t *testing.T) {
+	tests := map[string]struct {
+		policy    TemplateMissingKeys
+		flagKey   string
+		context   map[string]interface{}
+		wantValue string
+		wantErr   bool
+	}{
+		"substitution": {
+			flagKey:   "greeting",
+			context:   map[string]interface{}{"name": "Ada", "count": 3},
+			wantValue: "Hi Ada, you have 3 items",
+		},
+		"nested keys and escaped braces": {
+			flagKey:   "greeting",
+			context:   map[string]interface{}{"user": map[string]interface{}{"name": "Ada"}, "active": true},
+			wantValue: "Hi Ada, {literal} braces and true",
+		},
+		"missing key kept": {
+			flagKey:   "greeting",
+			context:   map[string]interface{}{"name": "Ada"},
+			wantValue: "Hi Ada, you have {count} items",
+		},
+		"null value kept": {
+			policy:    TemplateMissingKeysKeep,
+			flagKey:   "greeting",
+			context:   map[string]interface{}{"name": "Ada", "count": nil},
+			wantValue: "Hi Ada, you have {count} items",
+		},
+		"missing key error": {
+			policy:  TemplateMissingKeysError,
+			flagKey: "greeting",
+			context: map[string]interface{}{"name": "Ada"},
+			wantErr: true,
+		},
+		"untemplated flag": {
+			policy:    TemplateMissingKeysError,
+			flagKey:   "untemplated",
+			context:   map[string]interface{}{"name": "Ada"},
+			wantValue: "Hi {name}",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, templateFlagConfig, WithTemplateMissingKeys(tt.policy))
+			require.Nil(t, err)
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, _, reason, _, err := je.ResolveStringValue(context.Background(), "", tt.flagKey, evalCtx)
+			if tt.wantErr {
+				require.EqualError(t, err, model.GeneralErrorCode)
+				require.Equal(t, model.ErrorReason, reason)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.wantValue, value)
+
+			for _, resolved := range je.ResolveAllValues(context.Background(), "", evalCtx) {
+				if resolved.FlagKey == tt.flagKey {
+					require.Equal(t, tt.wantValue, resolved.Value, "bulk evaluations must render templates")
+				}
+			}
+		})
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	parts, err := parseTemplate("{{a}} {b} }}{ c }")
+	require.Nil(t, err)
+	require.Equal(t, []templatePart{{literal: "{a} "}, {key: "b"}, {literal: " }"}, {key: "c"}}, parts)
+
+	for _, invalid := range []string{"{a", "a}", "{}", "{a{b}}"} {
+		_, err := parseTemplate(invalid)
+		require.NotNil(t, err, invalid)
+	}
+
+	_, err = NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "greeting": {
+      "state": "ENABLED",
+      "variants": { "plain": "Hi {name" },
+      "defaultVariant": "plain",
+      "template": true
+    }
+  }
+}`)
+	require.ErrorContains(t, err, "template of variant: 'plain' of flag: 'greeting': unclosed placeholder at offset 3")
+
+	_, err = NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "greeting": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on", "template": true }
+  }
+}`)
+	require.ErrorContains(t, err, "variant: 'on' of templated flag: 'greeting' isn't a string")
+}
@@ -0,0 +1,285 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TenantMetadataKey is the metadata key holding the tenant whose configuration served the evaluation
+const TenantMetadataKey = "tenant"
+
+// TenantEvaluator serves evaluations from the configuration of the tenant identified by a value of the evaluation
+// context. Evaluations of unknown tenants, or without a tenant, are served by the shared configuration, as are the
+// flags missing from the configuration of a tenant.
+type TenantEvaluator struct {
+	Logger     *logger.Logger
+	contextKey string
+	shared     IEvaluator
+	tenants    map[string]IEvaluator
+}
+
+func NewTenantEvaluator(
+	log *logger.Logger, contextKey string, shared IEvaluator, tenants map[string]IEvaluator,
+) *TenantEvaluator {
+	if log == nil {
+		log = logger.NewLogger(nil, false)
+	}
+	return &TenantEvaluator{
+		Logger:     log.WithFields(zap.String("component", "evaluator"), zap.String("evaluator", "tenant")),
+		contextKey: contextKey,
+		shared:     shared,
+		tenants:    tenants,
+	}
+}
+
+// GetState returns the state of the shared configuration
+func (te *TenantEvaluator) GetState() (string, error) {
+	return te.shared.GetState()
+}
+
+// SetState updates the shared configuration
+func (te *TenantEvaluator) SetState(payload sync.DataSync) (map[string]interface{}, bool, error) {
+	return te.shared.SetState(payload)
+}
+
+// EvaluateSandbox evaluates inline flag definitions with the shared evaluator
+func (te *TenantEvaluator) EvaluateSandbox(
+	reqID string, flagKey string, definition json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	return evaluateSandbox(te.shared, reqID, flagKey, definition, context)
+}
+
+// EvaluateWhatIf evaluates stored flags with overridden variants with the shared evaluator
+func (te *TenantEvaluator) EvaluateWhatIf(
+	reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	return evaluateWhatIf(te.shared, reqID, flagKey, overrides, context)
+}
+
+// CompareConfig compares the configuration of the shared evaluator with the candidate configuration
+func (te *TenantEvaluator) CompareConfig(
+	reqID string, config string, contexts []*structpb.Struct,
+) (ConfigDivergence, error) {
+	return compareConfig(te.shared, reqID, config, contexts)
+}
+
+// ValidateConfig validates the candidate configuration with the shared evaluator
+func (te *TenantEvaluator) ValidateConfig(config string) ([]ConfigIssue, error) {
+	return validateConfig(te.shared, config)
+}
+
+// LintRules lints the rules with the shared evaluator
+func (te *TenantEvaluator) LintRules(config string, flagKey string) ([]LintWarning, error) {
+	return lintRules(te.shared, config, flagKey)
+}
+
+// RuleStatistics returns the rule statistics of the shared evaluator
+func (te *TenantEvaluator) RuleStatistics() map[string]FlagRuleStatistics {
+	return ruleStatisticsOf(te.shared)
+}
+
+// CircuitBreakers returns the circuit breakers of the shared evaluator
+func (te *TenantEvaluator) CircuitBreakers() map[string]CircuitBreakerState {
+	return circuitBreakersOf(te.shared)
+}
+
+// FlagType returns the type of the flag in the configuration of the tenant of the context, falling back to the
+// shared configuration
+func (te *TenantEvaluator) FlagType(flagKey string, context *structpb.Struct) (string, bool) {
+	evaluator, tenant := te.route(context)
+	if flagType, ok := flagTypeOf(evaluator, flagKey, context); ok || tenant == "" {
+		return flagType, ok
+	}
+	return flagTypeOf(te.shared, flagKey, context)
+}
+
+// HeldFlags returns the flags held by the shared evaluator or the evaluator of any tenant
+func (te *TenantEvaluator) HeldFlags() []string {
+	return mergeHeldFlags(te.evaluators()...)
+}
+
+// PinnedFlags returns the flags pinned by the shared evaluator or the evaluator of any tenant
+func (te *TenantEvaluator) PinnedFlags() []PinnedFlag {
+	return pinnedFlagsOf(te.evaluators()...)
+}
+
+// ApplyPinnedFlag applies the pending definition of the pinned flag to the shared evaluator and the evaluator of each
+// tenant
+func (te *TenantEvaluator) ApplyPinnedFlag(flagKey string) (map[string]interface{}, bool) {
+	return applyPinnedFlag(flagKey, te.evaluators()...)
+}
+
+// OverrideFlag applies the override to the shared evaluator and the evaluator of each tenant storing the flag
+func (te *TenantEvaluator) OverrideFlag(flagKey string, override FlagOverride) (
+	FlagOverride, map[string]interface{}, error,
+) {
+	return overrideFlag(flagKey, override, te.evaluators()...)
+}
+
+// FlushCaches flushes the caches of the shared evaluator and the evaluator of each tenant
+func (te *TenantEvaluator) FlushCaches() FlushedCaches {
+	return flushCaches(te.evaluators()...)
+}
+
+// evaluators returns the shared evaluator followed by the evaluator of each tenant
+func (te *TenantEvaluator) evaluators() []IEvaluator {
+	evaluators := []IEvaluator{te.shared}
+	for _, tenant := range te.tenants {
+		evaluators = append(evaluators, tenant)
+	}
+	return evaluators
+}
+
+// SetTenantState updates the configuration of the tenant
+func (te *TenantEvaluator) SetTenantState(tenant string, payload sync.DataSync) (map[string]interface{}, bool, error) {
+	evaluator, ok := te.tenants[tenant]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown tenant: %s", tenant)
+	}
+	return evaluator.SetState(payload)
+}
+
+func (te *TenantEvaluator) ResolveAllValues(ctx context.Context, reqID string, context *structpb.Struct) []AnyValue {
+	values, _ := te.ResolveAllValuesWithErrors(ctx, reqID, context)
+	return values
+}
+
+// ResolveAllValuesWithErrors resolves every flag of the tenant of the context and the shared flags it doesn't
+// define, along with the errors of the flags which failed to resolve
+func (te *TenantEvaluator) ResolveAllValuesWithErrors(
+	ctx context.Context, reqID string, context *structpb.Struct,
+) ([]AnyValue, []FlagError) {
+	evaluator, tenant := te.route(context)
+	if tenant == "" {
+		return resolveAllWithErrors(ctx, evaluator, reqID, context)
+	}
+	values, errs := resolveAllWithErrors(ctx, evaluator, reqID, context)
+	served := make(map[string]struct{}, len(values)+len(errs))
+	for i := range values {
+		served[values[i].FlagKey] = struct{}{}
+		values[i].Metadata = withTenant(values[i].Metadata, tenant)
+	}
+	for _, err := range errs {
+		served[err.FlagKey] = struct{}{}
+	}
+	sharedValues, sharedErrs := resolveAllWithErrors(ctx, te.shared, reqID, context)
+	for _, value := range sharedValues {
+		if _, ok := served[value.FlagKey]; !ok {
+			values = append(values, value)
+		}
+	}
+	for _, err := range sharedErrs {
+		if _, ok := served[err.FlagKey]; !ok {
+			errs = append(errs, err)
+		}
+	}
+	sortFlagErrors(errs)
+	return values, errs
+}
+
+func (te *TenantEvaluator) ResolveBooleanValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value bool, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, tenant := te.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveBooleanValue(ctx, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return te.shared.ResolveBooleanValue(ctx, reqID, flagKey, context)
+	}
+	return value, variant, reason, withTenant(metadata, tenant), err
+}
+
+func (te *TenantEvaluator) ResolveStringValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value string, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, tenant := te.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveStringValue(ctx, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return te.shared.ResolveStringValue(ctx, reqID, flagKey, context)
+	}
+	return value, variant, reason, withTenant(metadata, tenant), err
+}
+
+func (te *TenantEvaluator) ResolveIntValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value int64, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, tenant := te.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveIntValue(ctx, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return te.shared.ResolveIntValue(ctx, reqID, flagKey, context)
+	}
+	return value, variant, reason, withTenant(metadata, tenant), err
+}
+
+func (te *TenantEvaluator) ResolveFloatValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value float64, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, tenant := te.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveFloatValue(ctx, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return te.shared.ResolveFloatValue(ctx, reqID, flagKey, context)
+	}
+	return value, variant, reason, withTenant(metadata, tenant), err
+}
+
+func (te *TenantEvaluator) ResolveObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (value map[string]any, variant string, reason string, metadata map[string]interface{}, err error) {
+	evaluator, tenant := te.route(context)
+	value, variant, reason, metadata, err = evaluator.ResolveObjectValue(ctx, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return te.shared.ResolveObjectValue(ctx, reqID, flagKey, context)
+	}
+	return value, variant, reason, withTenant(metadata, tenant), err
+}
+
+// ResolveRawObjectValue resolves the raw value of an object flag with the evaluator of the tenant of the evaluation
+// context, falling back to the shared evaluator as ResolveObjectValue does
+func (te *TenantEvaluator) ResolveRawObjectValue(
+	ctx context.Context, reqID string, flagKey string, context *structpb.Struct,
+) (RawObjectResolution, error) {
+	evaluator, tenant := te.route(context)
+	resolution, err := resolveRawObjectValue(ctx, evaluator, reqID, flagKey, context)
+	if fallsBack(tenant, err) {
+		return resolveRawObjectValue(ctx, te.shared, reqID, flagKey, context)
+	}
+	resolution.Metadata = withTenant(resolution.Metadata, tenant)
+	return resolution, err
+}
+
+// route returns the evaluator of the tenant of the evaluation context along with the tenant, or the shared evaluator
+// and no tenant for unknown tenants
+func (te *TenantEvaluator) route(evalCtx *structpb.Struct) (IEvaluator, string) {
+	tenant, ok := evalCtx.GetFields()[te.contextKey].GetKind().(*structpb.Value_StringValue)
+	if !ok {
+		return te.shared, ""
+	}
+	if evaluator, ok := te.tenants[tenant.StringValue]; ok {
+		return evaluator, tenant.StringValue
+	}
+	return te.shared, ""
+}
+
+// fallsBack reports whether the evaluation of a tenant lacked the flag, which is then served by the shared
+// configuration
+func fallsBack(tenant string, err error) bool {
+	return tenant != "" && err != nil && err.Error() == model.FlagNotFoundErrorCode
+}
+
+func withTenant(metadata map[string]interface{}, tenant string) map[string]interface{} {
+	if tenant == "" {
+		return metadata
+	}
+	annotated := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		annotated[k] = v
+	}
+	annotated[TenantMetadataKey] = tenant
+	return annotated
+}
@@ -0,0 +1,245 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	sharedTenantConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red"
+    },
+    "sharedFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`
+	tenantAConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "blue"
+    }
+  }
+}`
+	tenantBConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "green": "#00FF00" },
+      "defaultVariant": "green"
+    },
+    "tenantFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    }
+  }
+}`
+)
+
+func newTestTenantEvaluator(t *testing.T) *TenantEvaluator {
+	t.Helper()
+	shared, err := NewJSONEvaluatorFromConfig(nil, sharedTenantConfig)
+	require.Nil(t, err)
+	te := NewTenantEvaluator(nil, "tenantId", shared, map[string]IEvaluator{
+		"tenantA": NewJSONEvaluator(nil, nil),
+		"tenantB": NewJSONEvaluator(nil, nil),
+	})
+	for tenant, config := range map[string]string{"tenantA": tenantAConfig, "tenantB": tenantBConfig} {
+		_, _, err := te.SetTenantState(tenant, sync.DataSync{FlagData: config, Source: tenant, Type: sync.ALL})
+		require.Nil(t, err)
+	}
+	return te
+}
+
+func TestTenantEvaluator(t *testing.T) {
+	te := newTestTenantEvaluator(t)
+
+	tests := map[string]struct {
+		context    map[string]interface{}
+		wantValue  string
+		wantTenant interface{}
+	}{
+		"tenant A": {
+			context:    map[string]interface{}{"tenantId": "tenantA"},
+			wantValue:  "#0000FF",
+			wantTenant: "tenantA",
+		},
+		"tenant B": {
+			context:    map[string]interface{}{"tenantId": "tenantB"},
+			wantValue:  "#00FF00",
+			wantTenant: "tenantB",
+		},
+		"unknown tenant": {
+			context:   map[string]interface{}{"tenantId": "tenantC"},
+			wantValue: "#FF0000",
+		},
+		"non string tenant": {
+			context:   map[string]interface{}{"tenantId": 1},
+			wantValue: "#FF0000",
+		},
+		"missing tenant": {
+			context:   map[string]interface{}{},
+			wantValue: "#FF0000",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			value, _, reason, metadata, err := te.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+			require.Nil(t, err)
+			require.Equal(t, tt.wantValue, value)
+			require.Equal(t, model.StaticReason, reason)
+			require.Equal(t, tt.wantTenant, metadata[TenantMetadataKey])
+		})
+	}
+}
+
+func TestTenantEvaluator_SharedFallback(t *testing.T) {
+	te := newTestTenantEvaluator(t)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"tenantId": "tenantA"})
+	require.Nil(t, err)
+
+	value, _, _, metadata, err := te.ResolveBooleanValue(context.Background(), "", "sharedFlag", evalCtx)
+	require.Nil(t, err, "flags missing from the tenant configuration should be served by the shared configuration")
+	require.True(t, value)
+	require.NotContains(t, metadata, TenantMetadataKey)
+
+	_, _, _, _, err = te.ResolveBooleanValue(context.Background(), "", "tenantFlag", evalCtx)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode, "flags of other tenants shouldn't be served")
+
+	_, _, _, _, err = te.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+	require.Nil(t, err)
+
+	values := te.ResolveAllValues(context.Background(), "", evalCtx)
+	resolved := map[string]AnyValue{}
+	for _, value := range values {
+		resolved[value.FlagKey] = value
+	}
+	require.Len(t, resolved, 2)
+	require.Equal(t, "#0000FF", resolved["headerColor"].Value)
+	require.Equal(t, "tenantA", resolved["headerColor"].Metadata[TenantMetadataKey])
+	require.Equal(t, true, resolved["sharedFlag"].Value)
+	require.NotContains(t, resolved["sharedFlag"].Metadata, TenantMetadataKey)
+}
+
+func TestTenantEvaluator_TenantNotReady(t *testing.T) {
+	shared, err := NewJSONEvaluatorFromConfig(nil, sharedTenantConfig)
+	require.Nil(t, err)
+	te := NewTenantEvaluator(nil, "tenantId", shared, map[string]IEvaluator{"tenantA": NewJSONEvaluator(nil, nil)})
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"tenantId": "tenantA"})
+	require.Nil(t, err)
+
+	_, _, _, _, err = te.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	require.EqualError(t, err, model.ProviderNotReadyErrorCode,
+		"tenants shouldn't be served the shared configuration before their initial sync")
+
+	_, _, err = te.SetTenantState("tenantC", sync.DataSync{FlagData: tenantAConfig, Source: "tenantC", Type: sync.ALL})
+	require.EqualError(t, err, "unknown tenant: tenantC")
+}
+
+func TestTenantEvaluator_OwnOptions(t *testing.T) {
+	// the email hello is bucketed into blue by xxh3, the default hash, and into red by murmur3
+	config := `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "regex": [{ "var": "email" }, "^h"] },
+          { "fractionalEvaluation": ["email", ["red", 20], ["blue", 80]] },
+          "red"
+        ]
+      }
+    }
+  }
+}`
+	shared, err := NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	tenantA, err := NewJSONEvaluatorFromConfig(nil, config, WithBucketingHash(BucketingHashMurmur3))
+	require.Nil(t, err)
+	tenantB, err := NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	te := NewTenantEvaluator(nil, "tenantId", shared, map[string]IEvaluator{"tenantA": tenantA, "tenantB": tenantB})
+
+	for tenant, want := range map[string]string{"": "blue", "tenantA": "red", "tenantB": "blue"} {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"tenantId": tenant, "email": "hello"})
+		require.Nil(t, err)
+		_, variant, _, _, err := te.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+		require.Equal(t, want, variant, "variant of tenant: '%s'", tenant)
+	}
+	for name, evaluator := range map[string]*JSONEvaluator{"shared": shared, "tenantA": tenantA, "tenantB": tenantB} {
+		require.Equal(t, 1, evaluator.FlushCaches().Patterns,
+			"the pattern should be cached by the evaluator of %s, which evaluated it", name)
+	}
+}
+
+func TestTenantEvaluator_IteratedOperations(t *testing.T) {
+	config := `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          {
+            "and": [
+              { "some": [{ "var": "scores" }, { ">": [{ "var": "" }, 18] }] },
+              { "some": [{ "var": "emails" }, { "regex": [{ "var": "" }, "^h"] }] }
+            ]
+          },
+          "blue",
+          "red"
+        ]
+      }
+    }
+  }
+}`
+	shared, err := NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	strict, err := NewJSONEvaluatorFromConfig(nil, config, WithContextCoercion(ContextCoercionStrict))
+	require.Nil(t, err)
+	te := NewTenantEvaluator(nil, "tenantId", shared, map[string]IEvaluator{"strict": strict})
+	resolve := func(tenant string, score interface{}) (string, error) {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{
+			"tenantId": tenant, "scores": []interface{}{score}, "emails": []interface{}{"hello"},
+		})
+		require.Nil(t, err)
+		_, variant, _, _, err := te.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		return variant, err
+	}
+
+	variant, err := resolve("strict", 21)
+	require.Nil(t, err)
+	require.Equal(t, "blue", variant)
+	_, err = resolve("strict", "21")
+	require.EqualError(t, err, model.InvalidContextErrorCode,
+		"the comparisons of the iterated items should apply the strict coercion of the tenant")
+	variant, err = resolve("", "21")
+	require.Nil(t, err)
+	require.Equal(t, "blue", variant, "the shared evaluator doesn't coerce context values")
+
+	for name, evaluator := range map[string]*JSONEvaluator{"shared": shared, "strict": strict} {
+		require.Equal(t, 1, evaluator.FlushCaches().Patterns,
+			"the pattern of the iterated items should be cached by the evaluator of %s, which evaluated it", name)
+	}
+	require.Zero(t, defaultEvaluator.FlushCaches().Patterns)
+}
@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// TraceSampling is implemented by evaluators holding the trace sampling rates configured by flags
+type TraceSampling interface {
+	// TraceSamplingRate returns the fraction of the evaluations of the flag which are traced, false if the flag
+	// doesn't configure it or doesn't exist
+	TraceSamplingRate(flagKey string) (float64, bool)
+}
+
+// TraceSamplingRate returns the trace sampling rate configured by the flag
+func (je *JSONEvaluator) TraceSamplingRate(flagKey string) (float64, bool) {
+	flag, ok := je.store.Get(je.namespaceKey("", flagKey))
+	if !ok || flag.TraceSampling == nil {
+		return 0, false
+	}
+	return *flag.TraceSampling, true
+}
+
+// TraceSamplingRate returns the trace sampling rate configured by the flag in the stable configuration, or in the
+// candidate one for new flags
+func (ce *CanaryEvaluator) TraceSamplingRate(flagKey string) (float64, bool) {
+	if rate, ok := traceSamplingRateOf(ce.stable, flagKey); ok {
+		return rate, true
+	}
+	return traceSamplingRateOf(ce.candidate, flagKey)
+}
+
+// TraceSamplingRate returns the trace sampling rate configured by the flag in the shared configuration, spans are
+// sampled before the tenant of the evaluation is known
+func (te *TenantEvaluator) TraceSamplingRate(flagKey string) (float64, bool) {
+	return traceSamplingRateOf(te.shared, flagKey)
+}
+
+func validateTraceSampling(key string, flag model.Flag) error {
+	if flag.TraceSampling == nil {
+		return nil
+	}
+	if rate := *flag.TraceSampling; rate < 0 || rate > 1 {
+		return fmt.Errorf("traceSampling: %g of flag: '%s' isn't between 0 and 1", rate, key)
+	}
+	return nil
+}
+
+// traceSamplingRateOf returns the trace sampling rate configured by the flag in the evaluator, false if it doesn't
+// hold trace sampling rates or the flag doesn't configure one
+func traceSamplingRateOf(evaluator IEvaluator, flagKey string) (float64, bool) {
+	sampling, ok := evaluator.(TraceSampling)
+	if !ok {
+		return 0, false
+	}
+	return sampling.TraceSamplingRate(flagKey)
+}
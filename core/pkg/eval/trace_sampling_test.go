@@ -0,0 +1,69 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+)
+
+const traceSamplingFlagConfig = `{
+  "flags": {
+    "highVolumeFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "traceSampling": 0.01
+    },
+    "unsampledFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`
+
+func TestTraceSamplingRate(t *testing.T) {
+	je, err := eval.NewJSONEvaluatorFromConfig(nil, traceSamplingFlagConfig)
+	require.Nil(t, err)
+	rate, ok := je.TraceSamplingRate("highVolumeFlag")
+	require.True(t, ok)
+	require.Equal(t, 0.01, rate)
+	_, ok = je.TraceSamplingRate("unsampledFlag")
+	require.False(t, ok, "flags without a trace sampling rate should defer to the server")
+	_, ok = je.TraceSamplingRate("aMissingFlag")
+	require.False(t, ok)
+
+	candidate, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "newFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on", "traceSampling": 0.5 }
+  }
+}`)
+	require.Nil(t, err)
+	canary := eval.NewCanaryEvaluator(nil, je, candidate, 10, nil)
+	rate, ok = canary.TraceSamplingRate("highVolumeFlag")
+	require.True(t, ok)
+	require.Equal(t, 0.01, rate)
+	rate, ok = canary.TraceSamplingRate("newFlag")
+	require.True(t, ok, "new flags of the candidate configuration should be sampled by their rate")
+	require.Equal(t, 0.5, rate)
+
+	tenants := eval.NewTenantEvaluator(nil, "tenant", je, map[string]eval.IEvaluator{"acme": candidate})
+	rate, ok = tenants.TraceSamplingRate("highVolumeFlag")
+	require.True(t, ok)
+	require.Equal(t, 0.01, rate)
+}
+
+func TestTraceSamplingRate_Invalid(t *testing.T) {
+	_, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "highVolumeFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "traceSampling": 1.5
+    }
+  }
+}`)
+	require.ErrorContains(t, err, "traceSampling: 1.5 of flag: 'highVolumeFlag' isn't between 0 and 1")
+}
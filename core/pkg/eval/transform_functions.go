@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	lowerOperator  = "lower"
+	upperOperator  = "upper"
+	trimOperator   = "trim"
+	splitOperator  = "split"
+	substrOperator = "substr"
+
+	// maxTransformLength bounds the length of the strings transformed, in bytes, longer strings aren't transformed
+	maxTransformLength = 4096
+)
+
+// lower returns a string in lower case, e.g. {"lower": {"var": "email"}}
+func (je *JSONEvaluator) lower(values, _ interface{}) interface{} {
+	return je.transform(lowerOperator, values, strings.ToLower)
+}
+
+// upper returns a string in upper case, e.g. {"upper": {"var": "country"}}
+func (je *JSONEvaluator) upper(values, _ interface{}) interface{} {
+	return je.transform(upperOperator, values, strings.ToUpper)
+}
+
+// trim returns a string without its leading and trailing white space, e.g. {"trim": {"var": "plan"}}
+func (je *JSONEvaluator) trim(values, _ interface{}) interface{} {
+	return je.transform(trimOperator, values, strings.TrimSpace)
+}
+
+// transform applies a function to the single string argument of an operator, returning nil if the value isn't a
+// string, e.g. missing from the context
+func (je *JSONEvaluator) transform(operator string, values interface{}, fn func(string) string) interface{} {
+	if valuesArray, ok := values.([]interface{}); ok {
+		if len(valuesArray) != 1 {
+			je.Logger.Error(fmt.Sprintf("parse %s data: data isn't length 1", operator))
+			return nil
+		}
+		values = valuesArray[0]
+	}
+	value, ok := transformedString(values)
+	if !ok {
+		return nil
+	}
+	return fn(value)
+}
+
+// split returns the part of a string at an index once split around a separator, negative indices counting from the
+// end, e.g. {"split": [{"var": "email"}, "@", -1]} for the domain of an email. The parts are returned as a list
+// without an index, and an index out of range returns nil.
+func (je *JSONEvaluator) split(values, _ interface{}) interface{} {
+	value, separator, index, err := parseSplitData(values)
+	if err != nil {
+		if !errors.Is(err, errMissingValue) {
+			je.Logger.Error(fmt.Sprintf("parse %s data: %v", splitOperator, err))
+		}
+		return nil
+	}
+
+	if index == nil {
+		parts := strings.Split(value, separator)
+		list := make([]interface{}, len(parts))
+		for i, part := range parts {
+			list[i] = part
+		}
+		return list
+	}
+	i := *index
+	if i >= 0 {
+		// the string is only split up to the part at the index
+		parts := strings.SplitN(value, separator, i+2)
+		if i >= len(parts) {
+			return nil
+		}
+		return parts[i]
+	}
+	parts := strings.Split(value, separator)
+	if -i > len(parts) {
+		return nil
+	}
+	return parts[len(parts)+i]
+}
+
+func parseSplitData(values interface{}) (value, separator string, index *int, err error) {
+	valuesArray, ok := values.([]interface{})
+	if !ok {
+		return "", "", nil, errors.New("data is not an array")
+	}
+	if len(valuesArray) != 2 && len(valuesArray) != 3 {
+		return "", "", nil, errors.New("data isn't length 2 or 3")
+	}
+
+	if value, ok = transformedString(valuesArray[0]); !ok {
+		return "", "", nil, errMissingValue
+	}
+	if separator, ok = valuesArray[1].(string); !ok || separator == "" {
+		return "", "", nil, errors.New("separator isn't a non empty string")
+	}
+	if len(valuesArray) == 3 {
+		i, err := integerArgument(valuesArray[2])
+		if err != nil {
+			return "", "", nil, fmt.Errorf("index %w", err)
+		}
+		index = &i
+	}
+	return value, separator, index, nil
+}
+
+// substr returns the characters of a string from a start, for a length, e.g. {"substr": [{"var": "postcode"}, 0, 2]}.
+// It replaces the json-logic substr, with its semantics: a negative start counts from the end and a negative length
+// leaves as many characters off the end, while bounds out of range are clamped rather than panicking.
+func (je *JSONEvaluator) substr(values, _ interface{}) interface{} {
+	valuesArray, ok := values.([]interface{})
+	if !ok || len(valuesArray) != 2 && len(valuesArray) != 3 {
+		je.Logger.Error(fmt.Sprintf("parse %s data: data isn't an array of length 2 or 3", substrOperator))
+		return nil
+	}
+	value, ok := substrString(valuesArray[0])
+	if !ok {
+		return nil
+	}
+	runes := []rune(value)
+
+	from, err := integerArgument(valuesArray[1])
+	if err != nil {
+		je.Logger.Error(fmt.Sprintf("parse %s data: start %v", substrOperator, err))
+		return nil
+	}
+	if from < 0 {
+		from += len(runes)
+	}
+	if from < 0 || from > len(runes) {
+		// as the json-logic substr, the string is returned as it is
+		return value
+	}
+
+	to := len(runes)
+	if len(valuesArray) == 3 {
+		length, err := integerArgument(valuesArray[2])
+		if err != nil {
+			je.Logger.Error(fmt.Sprintf("parse %s data: length %v", substrOperator, err))
+			return nil
+		}
+		if length < 0 {
+			to = len(runes) + length
+		} else if length < len(runes)-from {
+			to = from + length
+		}
+	}
+	if to <= from {
+		return ""
+	}
+	return string(runes[from:to])
+}
+
+// transformedString returns the string transformed by an operator, false if the value isn't a string or is longer
+// than maxTransformLength
+func transformedString(value interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok || len(s) > maxTransformLength {
+		return "", false
+	}
+	return s, true
+}
+
+// substrString returns the string of a substr, numbers being formatted and nil being empty as with the json-logic
+// substr
+func substrString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return transformedString(value)
+	}
+}
+
+// integerArgument returns the integer of a numeric argument, fractions being truncated. Arguments are clamped beyond
+// the length of the longest string transformed, which they are out of range of either way.
+func integerArgument(value interface{}) (int, error) {
+	n, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("%v isn't a number", value)
+	}
+	switch {
+	case n > maxTransformLength:
+		return maxTransformLength + 1, nil
+	case n < -maxTransformLength:
+		return -maxTransformLength - 1, nil
+	}
+	return int(n), nil
+}
@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestTransformFunctions(t *testing.T) {
+	je := NewJSONEvaluator(nil, nil)
+	long := strings.Repeat("a", maxTransformLength+1)
+	tests := map[string]struct {
+		operator func(values, data interface{}) interface{}
+		values   interface{}
+		expected interface{}
+	}{
+		"lower":                  {je.lower, "Jane.Doe@FaaS.com", "jane.doe@faas.com"},
+		"lower argument list":    {je.lower, []interface{}{"ÉCOLE"}, "école"},
+		"lower empty":            {je.lower, "", ""},
+		"lower missing":          {je.lower, nil, nil},
+		"lower number":           {je.lower, float64(42), nil},
+		"lower too many args":    {je.lower, []interface{}{"a", "b"}, nil},
+		"lower too long":         {je.lower, long, nil},
+		"upper":                  {je.upper, "fr", "FR"},
+		"upper missing":          {je.upper, []interface{}{nil}, nil},
+		"trim":                   {je.trim, " \tpremium\n", "premium"},
+		"trim blank":             {je.trim, "   ", ""},
+		"trim object":            {je.trim, map[string]interface{}{"a": "b"}, nil},
+		"split index":            {je.split, []interface{}{"jane@faas.com", "@", float64(1)}, "faas.com"},
+		"split negative index":   {je.split, []interface{}{"a.b.c", ".", float64(-1)}, "c"},
+		"split first":            {je.split, []interface{}{"a.b.c", ".", float64(-3)}, "a"},
+		"split fractional index": {je.split, []interface{}{"a.b.c", ".", 1.9}, "b"},
+		"split out of range":     {je.split, []interface{}{"jane@faas.com", "@", float64(2)}, nil},
+		"split negative range":   {je.split, []interface{}{"a.b", ".", float64(-3)}, nil},
+		"split huge index":       {je.split, []interface{}{"a.b", ".", 1e18}, nil},
+		"split no separator":     {je.split, []interface{}{"jane", "@", float64(0)}, "jane"},
+		"split empty parts":      {je.split, []interface{}{"@@", "@", float64(1)}, ""},
+		"split list":             {je.split, []interface{}{"a,b", ","}, []interface{}{"a", "b"}},
+		"split empty string":     {je.split, []interface{}{"", ",", float64(0)}, ""},
+		"split missing":          {je.split, []interface{}{nil, "@", float64(1)}, nil},
+		"split empty separator":  {je.split, []interface{}{"abc", "", float64(0)}, nil},
+		"split index string":     {je.split, []interface{}{"a.b", ".", "1"}, nil},
+		"split too few args":     {je.split, []interface{}{"a.b"}, nil},
+		"split not an array":     {je.split, "a.b", nil},
+		"substr":                 {je.substr, []interface{}{"SW1A 1AA", float64(0), float64(2)}, "SW"},
+		"substr to the end":      {je.substr, []interface{}{"jsonlogic", float64(4)}, "logic"},
+		"substr negative start":  {je.substr, []interface{}{"jsonlogic", float64(-5)}, "logic"},
+		"substr negative length": {je.substr, []interface{}{"jsonlogic", float64(1), float64(-5)}, "son"},
+		"substr runes":           {je.substr, []interface{}{"héllo", float64(1), float64(2)}, "él"},
+		"substr length overflow": {je.substr, []interface{}{"abc", float64(1), 1e18}, "bc"},
+		"substr start past end":  {je.substr, []interface{}{"jsonlogic", float64(10)}, "jsonlogic"},
+		"substr start before":    {je.substr, []interface{}{"jsonlogic", float64(-10)}, "jsonlogic"},
+		"substr crossed bounds":  {je.substr, []interface{}{"abc", float64(2), float64(-2)}, ""},
+		"substr zero length":     {je.substr, []interface{}{"abc", float64(1), float64(0)}, ""},
+		"substr number":          {je.substr, []interface{}{float64(12345), float64(0), float64(2)}, "12"},
+		"substr missing":         {je.substr, []interface{}{nil, float64(0), float64(2)}, ""},
+		"substr boolean":         {je.substr, []interface{}{true, float64(0)}, nil},
+		"substr start string":    {je.substr, []interface{}{"abc", "1"}, nil},
+		"substr not an array":    {je.substr, "abc", nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.operator(tt.values, nil))
+		})
+	}
+}
+
+func TestTransformFunctions_Targeting(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		context   map[string]interface{}
+		expected  bool
+	}{
+		"email domain": {
+			`{"==": [{"lower": {"split": [{"var": "email"}, "@", -1]}}, "faas.com"]}`,
+			map[string]interface{}{"email": "Jane@FaaS.com"}, true,
+		},
+		"email domain mismatch": {
+			`{"==": [{"lower": {"split": [{"var": "email"}, "@", -1]}}, "faas.com"]}`,
+			map[string]interface{}{"email": "jane@example.com"}, false,
+		},
+		"missing email": {
+			`{"==": [{"lower": {"split": [{"var": "email"}, "@", -1]}}, "faas.com"]}`,
+			map[string]interface{}{}, false,
+		},
+		"postcode area": {
+			`{"in": [{"upper": {"substr": [{"trim": {"var": "postcode"}}, 0, 2]}}, ["SW", "SE"]]}`,
+			map[string]interface{}{"postcode": " sw1a 1aa"}, true,
+		},
+		"crossed substr bounds": {
+			`{"==": [{"substr": [{"var": "code"}, 2, -2]}, ""]}`,
+			map[string]interface{}{"code": "abc"}, true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := NewJSONEvaluatorFromConfig(nil, fmt.Sprintf(`{
+  "flags": {
+    "transformFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [%s, "on", null] }
+    }
+  }
+}`, tt.targeting))
+			require.Nil(t, err)
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "transformFlag", ctx)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, value)
+			if tt.expected {
+				assert.Equal(t, model.TargetingMatchReason, reason)
+			} else {
+				assert.Equal(t, model.DefaultReason, reason)
+			}
+		})
+	}
+}
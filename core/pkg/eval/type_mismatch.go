@@ -0,0 +1,66 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// TypeMismatchError is returned for the resolutions of a flag as another type than the type of its resolved
+// variant, it's reported by its error code
+type TypeMismatchError struct {
+	FlagKey string
+	Variant string
+	// Expected is the kind of value of the resolution type, Actual the kind of the value of the variant
+	Expected string
+	Actual   string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return model.TypeMismatchErrorCode
+}
+
+// Description names the flag and the kinds of value at fault, e.g. for error responses
+func (e *TypeMismatchError) Description() string {
+	return fmt.Sprintf("variant: '%s' of flag: '%s' is %s, not %s", e.Variant, e.FlagKey,
+		kindArticle(e.Actual), kindArticle(e.Expected))
+}
+
+// valueKind names the json kind of a resolved value
+func valueKind(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return BooleanFlagType
+	case string:
+		return StringFlagType
+	case float64:
+		return "number"
+	case map[string]interface{}:
+		return ObjectFlagType
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// checkArrayVariants rejects flags whose default variant is a json array with an error naming the flag, rather than
+// the schema violations of each variant type. Values of object flags are json objects, so arrays resolve with none of
+// the resolution types.
+func checkArrayVariants(key string, raw json.RawMessage) error {
+	var fields struct {
+		Variants       map[string]json.RawMessage `json:"variants"`
+		DefaultVariant string                     `json:"defaultVariant"`
+	}
+	if json.Unmarshal(raw, &fields) != nil {
+		return nil
+	}
+	if value, ok := fields.Variants[fields.DefaultVariant]; ok && variantKind(value) == "array" {
+		return fmt.Errorf("default variant: '%s' of flag: '%s' is an array, the values of object flags are json "+
+			"objects, e.g. { \"items\": [...] }", fields.DefaultVariant, key)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestResolveObjectValue_VariantKinds(t *testing.T) {
+	tests := map[string]struct {
+		defaultValue string
+		otherValue   string
+		want         map[string]interface{}
+		wantMismatch *TypeMismatchError
+		wantLoadErr  string
+	}{
+		"object": {
+			defaultValue: `{ "color": "red" }`,
+			otherValue:   `{ "color": "blue" }`,
+			want:         map[string]interface{}{"color": "red"},
+		},
+		"scalar": {
+			defaultValue: `"red"`,
+			otherValue:   `"blue"`,
+			wantMismatch: &TypeMismatchError{FlagKey: "myFlag", Variant: "default", Expected: "object", Actual: "string"},
+		},
+		"array": {
+			defaultValue: `["eu", "us"]`,
+			otherValue:   `["eu"]`,
+			wantLoadErr: "default variant: 'default' of flag: 'myFlag' is an array, the values of object flags are " +
+				"json objects, e.g. { \"items\": [...] }",
+		},
+		"array nested in an object": {
+			defaultValue: `{ "regions": ["eu", "us"] }`,
+			otherValue:   `{ "regions": ["eu"] }`,
+			want:         map[string]interface{}{"regions": []interface{}{"eu", "us"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, variantTypesFlagConfig(tt.defaultValue, tt.otherValue))
+			if tt.wantLoadErr != "" {
+				require.EqualError(t, err, tt.wantLoadErr)
+				return
+			}
+			require.Nil(t, err)
+
+			value, variant, reason, _, err := je.ResolveObjectValue(
+				context.Background(), "", "myFlag", &structpb.Struct{})
+			require.Equal(t, "default", variant)
+			if tt.wantMismatch == nil {
+				require.Nil(t, err)
+				require.Equal(t, tt.want, value)
+				return
+			}
+			require.EqualError(t, err, model.TypeMismatchErrorCode, "mismatches are reported by their error code")
+			require.Equal(t, model.ErrorReason, reason)
+			var mismatch *TypeMismatchError
+			require.True(t, errors.As(err, &mismatch))
+			require.Equal(t, tt.wantMismatch, mismatch)
+		})
+	}
+}
+
+func TestTypeMismatchError_Description(t *testing.T) {
+	err := &TypeMismatchError{FlagKey: "headerColor", Variant: "red", Expected: "object", Actual: "string"}
+	require.Equal(t, "variant: 'red' of flag: 'headerColor' is a string, not an object", err.Description())
+	err = &TypeMismatchError{FlagKey: "retries", Variant: "few", Expected: "boolean", Actual: "number"}
+	require.Equal(t, "variant: 'few' of flag: 'retries' is a number, not a boolean", err.Description())
+}
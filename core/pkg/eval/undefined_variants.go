@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// UndefinedVariants defines how targeting rules resolving variants which the flag doesn't define are handled
+type UndefinedVariants string
+
+const (
+	// UndefinedVariantsFallback loads flags whose targeting references undefined variants with a warning, evaluations
+	// resolving an undefined variant fall back to the default variant, the default
+	UndefinedVariantsFallback UndefinedVariants = "fallback"
+	// UndefinedVariantsError rejects flags whose targeting references undefined variants, evaluations resolving an
+	// undefined variant fail
+	UndefinedVariantsError UndefinedVariants = "error"
+)
+
+// ParseUndefinedVariants returns the undefined variant policy of its name, an empty name defaults to fallback
+func ParseUndefinedVariants(policy string) (UndefinedVariants, error) {
+	switch UndefinedVariants(policy) {
+	case "":
+		return UndefinedVariantsFallback, nil
+	case UndefinedVariantsFallback, UndefinedVariantsError:
+		return UndefinedVariants(policy), nil
+	default:
+		return "", fmt.Errorf("unknown undefined variant policy: '%s', expected '%s' or '%s'",
+			policy, UndefinedVariantsFallback, UndefinedVariantsError)
+	}
+}
+
+// WithUndefinedVariants sets the policy of targeting rules resolving variants which the flag doesn't define
+func WithUndefinedVariants(policy UndefinedVariants) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.undefinedVariants = policy
+	}
+}
+
+// variantReference is a variant a targeting rule resolves literally, along with its path in the rule and the id of
+// the annotated rule resolving it, if any
+type variantReference struct {
+	variant string
+	path    string
+	ruleID  string
+}
+
+func (r variantReference) String() string {
+	if r.ruleID == "" {
+		return fmt.Sprintf("variant: '%s' at %s", r.variant, r.path)
+	}
+	return fmt.Sprintf("variant: '%s' of rule: '%s' at %s", r.variant, r.ruleID, r.path)
+}
+
+// validateVariantReferences detects the variants the targeting rule of the flag resolves literally which the flag
+// doesn't define, rejecting the flag or logging a warning as per the undefined variant policy. Variants computed
+// from the evaluation context are checked when evaluating.
+func (je *JSONEvaluator) validateVariantReferences(key string, flag model.Flag, rule interface{}) error {
+	var references []variantReference
+	collectVariantReferences(rule, "targeting", "", &references)
+	var undefined []string
+	for _, reference := range references {
+		if _, ok := flag.Variants[reference.variant]; !ok {
+			undefined = append(undefined, reference.String())
+		}
+	}
+	if len(undefined) == 0 {
+		return nil
+	}
+	sort.Strings(undefined)
+	err := fmt.Errorf("targeting of flag: '%s' references undefined variants: %s", key, strings.Join(undefined, ", "))
+	if je.undefinedVariants == UndefinedVariantsError {
+		return err
+	}
+	je.Logger.Warn(fmt.Sprintf("%s, falling back to the default variant", err))
+	return nil
+}
+
+// collectVariantReferences collects the variants the rule resolves as string literals, through the branches of if,
+// the buckets of fractional evaluations and annotated rules
+func collectVariantReferences(node interface{}, path string, ruleID string, references *[]variantReference) {
+	switch n := node.(type) {
+	case string:
+		if n != "" {
+			*references = append(*references, variantReference{variant: n, path: path, ruleID: ruleID})
+		}
+	case map[string]interface{}:
+		if len(n) != 1 {
+			return
+		}
+		for operator, values := range n {
+			args, ok := values.([]interface{})
+			if !ok {
+				return
+			}
+			switch operator {
+			case "if":
+				for i, arg := range args {
+					// odd arguments are the branches of the preceding conditions, a trailing argument is the else branch
+					if i%2 == 1 || i == len(args)-1 {
+						collectVariantReferences(arg, fmt.Sprintf("%s.if[%d]", path, i), ruleID, references)
+					}
+				}
+			case fractionalEvaluationOperator:
+				for i, arg := range args {
+					if bucket, ok := arg.([]interface{}); ok && len(bucket) > 0 {
+						bucketPath := fmt.Sprintf("%s.%s[%d]", path, operator, i)
+						collectVariantReferences(bucket[0], bucketPath, ruleID, references)
+					}
+				}
+			case ruleOperator:
+				if len(args) == 2 {
+					if id, ok := args[0].(string); ok {
+						collectVariantReferences(args[1], fmt.Sprintf("%s.%s[1]", path, operator), id, references)
+					}
+				}
+			}
+		}
+	}
+}
+
+// resolvedUndefinedVariant returns the error of an evaluation whose targeting resolved a variant which the flag
+// doesn't define, nil if the evaluation falls back to the default variant. Rules resolving no variant, e.g. an if
+// without else branch, always fall back.
+func (je *JSONEvaluator) resolvedUndefinedVariant(
+	flagKey string, variant string, metadata map[string]interface{},
+) error {
+	if je.undefinedVariants != UndefinedVariantsError || variant == "" || variant == "null" {
+		return nil
+	}
+	if ruleID, ok := metadata[RuleIDMetadataKey].(string); ok {
+		return fmt.Errorf("targeting of flag: '%s' resolved the undefined variant: '%s' of rule: '%s'",
+			flagKey, variant, ruleID)
+	}
+	return fmt.Errorf("targeting of flag: '%s' resolved the undefined variant: '%s'", flagKey, variant)
+}
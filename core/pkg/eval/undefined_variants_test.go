@@ -0,0 +1,156 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// literalVariantFlagConfig references the undefined variants black and purple literally
+const literalVariantFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "tier" }, "beta"] },
+          { "rule": ["beta-users", { "fractionalEvaluation": ["email", ["blue", 50], ["black", 50]] }] },
+          { "==": [{ "var": "tier" }, "pro"] },
+          "purple",
+          "red"
+        ]
+      }
+    }
+  }
+}`
+
+// contextVariantFlagConfig resolves the variant named by the evaluation context
+const contextVariantFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "tier" }, "beta"] },
+          { "rule": ["beta-users", { "var": "color" }] },
+          { "var": "color" }
+        ]
+      }
+    }
+  }
+}`
+
+func TestUndefinedVariants_Load(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		_, err := eval.NewJSONEvaluatorFromConfig(nil, literalVariantFlagConfig,
+			eval.WithUndefinedVariants(eval.UndefinedVariantsError))
+		require.EqualError(t, err, "targeting of flag: 'headerColor' references undefined variants: "+
+			"variant: 'black' of rule: 'beta-users' at targeting.if[1].rule[1].fractionalEvaluation[2], "+
+			"variant: 'purple' at targeting.if[3]")
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		core, logs := observer.New(zap.WarnLevel)
+		evaluator := eval.NewJSONEvaluator(logger.NewLogger(zap.New(core), false), nil)
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: literalVariantFlagConfig, Type: sync.ALL})
+		require.Nil(t, err)
+		require.Equal(t, 1, logs.FilterMessage("targeting of flag: 'headerColor' references undefined variants: "+
+			"variant: 'black' of rule: 'beta-users' at targeting.if[1].rule[1].fractionalEvaluation[2], "+
+			"variant: 'purple' at targeting.if[3], falling back to the default variant").Len())
+
+		ctx, err := structpb.NewStruct(map[string]interface{}{"tier": "pro"})
+		require.Nil(t, err)
+		_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+		require.Nil(t, err)
+		require.Equal(t, "red", variant)
+		require.Equal(t, model.DefaultReason, reason)
+	})
+
+	t.Run("defined variants", func(t *testing.T) {
+		_, err := eval.NewJSONEvaluatorFromConfig(nil, contextVariantFlagConfig,
+			eval.WithUndefinedVariants(eval.UndefinedVariantsError))
+		require.Nil(t, err)
+	})
+}
+
+func TestUndefinedVariants_Evaluation(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextVariantFlagConfig,
+		eval.WithUndefinedVariants(eval.UndefinedVariantsError))
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		context     map[string]interface{}
+		wantVariant string
+		wantReason  string
+		wantErr     string
+	}{
+		"defined variant": {
+			context:     map[string]interface{}{"color": "blue"},
+			wantVariant: "blue",
+			wantReason:  model.TargetingMatchReason,
+		},
+		"undefined variant": {
+			context:    map[string]interface{}{"color": "black"},
+			wantReason: model.ErrorReason,
+			wantErr:    "targeting of flag: 'headerColor' resolved the undefined variant: 'black'",
+		},
+		"undefined variant of an annotated rule": {
+			context:    map[string]interface{}{"tier": "beta", "color": "black"},
+			wantReason: model.ErrorReason,
+			wantErr:    "targeting of flag: 'headerColor' resolved the undefined variant: 'black' of rule: 'beta-users'",
+		},
+		"no variant falls back": {
+			context:     map[string]interface{}{},
+			wantVariant: "red",
+			wantReason:  model.DefaultReason,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Equal(t, tt.wantVariant, variant)
+			require.Equal(t, tt.wantReason, reason)
+		})
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextVariantFlagConfig)
+		require.Nil(t, err)
+		ctx, err := structpb.NewStruct(map[string]interface{}{"color": "black"})
+		require.Nil(t, err)
+		_, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", "headerColor", ctx)
+		require.Nil(t, err)
+		require.Equal(t, "red", variant)
+		require.Equal(t, model.DefaultReason, reason)
+	})
+}
+
+func TestParseUndefinedVariants(t *testing.T) {
+	policy, err := eval.ParseUndefinedVariants("")
+	require.Nil(t, err)
+	require.Equal(t, eval.UndefinedVariantsFallback, policy)
+	policy, err = eval.ParseUndefinedVariants("error")
+	require.Nil(t, err)
+	require.Equal(t, eval.UndefinedVariantsError, policy)
+	_, err = eval.ParseUndefinedVariants("ignore")
+	require.EqualError(t, err, "unknown undefined variant policy: 'ignore', expected 'fallback' or 'error'")
+}
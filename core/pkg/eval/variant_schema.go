@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaMismatch defines how object variants which don't conform to the schema of their flag are handled
+type SchemaMismatch string
+
+const (
+	// SchemaMismatchError rejects flags whose variants don't conform to their schema, the default
+	SchemaMismatchError SchemaMismatch = "error"
+	// SchemaMismatchWarn loads flags whose variants don't conform to their schema, with a warning
+	SchemaMismatchWarn SchemaMismatch = "warn"
+)
+
+// ParseSchemaMismatch returns the schema mismatch policy of its name, an empty name defaults to error
+func ParseSchemaMismatch(policy string) (SchemaMismatch, error) {
+	switch SchemaMismatch(policy) {
+	case "":
+		return SchemaMismatchError, nil
+	case SchemaMismatchError, SchemaMismatchWarn:
+		return SchemaMismatch(policy), nil
+	default:
+		return "", fmt.Errorf("unknown schema mismatch policy: '%s', expected '%s' or '%s'",
+			policy, SchemaMismatchError, SchemaMismatchWarn)
+	}
+}
+
+// WithSchemaMismatch sets the policy of object variants which don't conform to the schema of their flag
+func WithSchemaMismatch(policy SchemaMismatch) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.schemaMismatch = policy
+	}
+}
+
+// validateVariantSchema validates the variants of an object flag against the JSON schema of the flag, if set
+func (je *JSONEvaluator) validateVariantSchema(key string, flag model.Flag) error {
+	if flag.Schema == nil {
+		return nil
+	}
+	variants := make([]string, 0, len(flag.Variants))
+	for variant, value := range flag.Variants {
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("flag: '%s' has a schema but variant: '%s' isn't an object", key, variant)
+		}
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(flag.Schema))
+	if err != nil {
+		return fmt.Errorf("compiling schema of flag: '%s': %w", key, err)
+	}
+	for _, variant := range variants {
+		result, err := schema.Validate(gojsonschema.NewGoLoader(flag.Variants[variant]))
+		if err != nil {
+			return fmt.Errorf("validating variant: '%s' of flag: '%s': %w", variant, key, err)
+		}
+		if result.Valid() {
+			continue
+		}
+		mismatch := strings.TrimSpace(fmt.Sprintf("variant: '%s' of flag: '%s' doesn't conform to its schema:%s",
+			variant, key, buildErrorString(result.Errors())))
+		if je.schemaMismatch != SchemaMismatchWarn {
+			return errors.New(mismatch)
+		}
+		je.Logger.Warn(mismatch)
+	}
+	return nil
+}
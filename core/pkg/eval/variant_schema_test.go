@@ -0,0 +1,131 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const variantSchema = `{
+  "type": "object",
+  "properties": {
+    "retries": { "type": "integer", "minimum": 0 },
+    "endpoint": { "type": "string" }
+  },
+  "required": ["retries", "endpoint"],
+  "additionalProperties": false
+}`
+
+func variantSchemaFlagConfig(variant string) string {
+	return `{
+  "flags": {
+    "clientConfig": {
+      "state": "ENABLED",
+      "variants": {
+        "default": { "retries": 3, "endpoint": "https://api.example.com" },
+        "other": ` + variant + `
+      },
+      "defaultVariant": "default",
+      "schema": ` + variantSchema + `
+    }
+  }
+}`
+}
+
+func TestVariantSchema(t *testing.T) {
+	tests := map[string]struct {
+		variant string
+		wantErr string
+	}{
+		"conforming variants": {
+			variant: `{ "retries": 0, "endpoint": "https://fallback.example.com" }`,
+		},
+		"missing property": {
+			variant: `{ "retries": 1 }`,
+			wantErr: "variant: 'other' of flag: 'clientConfig' doesn't conform to its schema: 1:(root): endpoint is required",
+		},
+		"mistyped property": {
+			variant: `{ "retries": "3", "endpoint": "https://api.example.com" }`,
+			wantErr: "variant: 'other' of flag: 'clientConfig' doesn't conform to its schema: 1:retries: " +
+				"Invalid type. Expected: integer, given: string",
+		},
+		"additional property": {
+			variant: `{ "retries": 3, "endpoint": "https://api.example.com", "timeout": 10 }`,
+			wantErr: "variant: 'other' of flag: 'clientConfig' doesn't conform to its schema: " +
+				"1:(root): Additional property timeout is not allowed",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je, err := NewJSONEvaluatorFromConfig(nil, variantSchemaFlagConfig(tt.variant))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			value, variant, _, _, err := je.ResolveObjectValue(context.Background(), "", "clientConfig", &structpb.Struct{})
+			require.Nil(t, err)
+			require.Equal(t, "default", variant)
+			require.Equal(t, map[string]interface{}{"retries": float64(3), "endpoint": "https://api.example.com"}, value)
+		})
+	}
+}
+
+func TestVariantSchema_Warn(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	log := logger.NewLogger(zap.New(core), false)
+	je, err := NewJSONEvaluatorFromConfig(log, variantSchemaFlagConfig(`{ "retries": -1, "endpoint": "x" }`),
+		WithSchemaMismatch(SchemaMismatchWarn))
+	require.Nil(t, err, "mismatching variants should be loaded")
+	require.Equal(t, 1, logs.FilterMessage("variant: 'other' of flag: 'clientConfig' doesn't conform to its schema: "+
+		"1:retries: Must be greater than or equal to 0").Len())
+
+	_, _, _, _, err = je.ResolveObjectValue(context.Background(), "", "clientConfig", &structpb.Struct{})
+	require.Nil(t, err)
+}
+
+func TestVariantSchema_InvalidSchema(t *testing.T) {
+	_, err := NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "clientConfig": {
+      "state": "ENABLED",
+      "variants": { "default": {} },
+      "defaultVariant": "default",
+      "schema": { "type": "unknown" }
+    }
+  }
+}`)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "compiling schema of flag: 'clientConfig'")
+
+	_, err = NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "endpoint": {
+      "state": "ENABLED",
+      "variants": { "default": "https://api.example.com" },
+      "defaultVariant": "default",
+      "schema": { "type": "string" }
+    }
+  }
+}`)
+	require.EqualError(t, err, "flag: 'endpoint' has a schema but variant: 'default' isn't an object")
+}
+
+func TestParseSchemaMismatch(t *testing.T) {
+	policy, err := ParseSchemaMismatch("")
+	require.Nil(t, err)
+	require.Equal(t, SchemaMismatchError, policy)
+
+	policy, err = ParseSchemaMismatch("warn")
+	require.Nil(t, err)
+	require.Equal(t, SchemaMismatchWarn, policy)
+
+	_, err = ParseSchemaMismatch("ignore")
+	require.EqualError(t, err, "unknown schema mismatch policy: 'ignore', expected 'error' or 'warn'")
+}
@@ -0,0 +1,166 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// VariantTypeMismatch defines how variants whose value isn't of the type of the default variant of their flag are
+// handled, as they fail with a type mismatch when resolved
+type VariantTypeMismatch string
+
+const (
+	// VariantTypeMismatchError rejects flags whose variants aren't of the type of their default variant, the default
+	VariantTypeMismatchError VariantTypeMismatch = "error"
+	// VariantTypeMismatchWarn loads flags without their variants of another type than their default variant, with a
+	// warning
+	VariantTypeMismatchWarn VariantTypeMismatch = "warn"
+)
+
+// ParseVariantTypeMismatch returns the variant type mismatch policy of its name, an empty name defaults to error
+func ParseVariantTypeMismatch(policy string) (VariantTypeMismatch, error) {
+	switch VariantTypeMismatch(policy) {
+	case "":
+		return VariantTypeMismatchError, nil
+	case VariantTypeMismatchError, VariantTypeMismatchWarn:
+		return VariantTypeMismatch(policy), nil
+	default:
+		return "", fmt.Errorf("unknown variant type mismatch policy: '%s', expected '%s' or '%s'",
+			policy, VariantTypeMismatchError, VariantTypeMismatchWarn)
+	}
+}
+
+// WithVariantTypeMismatch sets the policy of variants whose value isn't of the type of the default variant of their
+// flag
+func WithVariantTypeMismatch(policy VariantTypeMismatch) JSONEvaluatorOption {
+	return func(je *JSONEvaluator) {
+		je.variantTypeMismatch = policy
+	}
+}
+
+// variantKind names the json kind of a variant value
+func variantKind(value json.RawMessage) string {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return ""
+	}
+	switch value[0] {
+	case 't', 'f':
+		return BooleanFlagType
+	case '"':
+		return StringFlagType
+	case '{':
+		return ObjectFlagType
+	case '[':
+		return "array"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// checkVariantTypes checks the variants of a flag are of the type of its default variant, so a misconfigured
+// variant is reported when loading the configuration rather than by its first resolution. Mismatched variants
+// either reject the flag or are removed from it with a warning, the flag is returned unchanged without a default
+// variant to check against.
+func (je *JSONEvaluator) checkVariantTypes(key string, raw json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw, nil
+	}
+	var variants map[string]json.RawMessage
+	var defaultVariant string
+	if json.Unmarshal(fields["variants"], &variants) != nil || json.Unmarshal(fields["defaultVariant"],
+		&defaultVariant) != nil {
+		return raw, nil
+	}
+	defaultValue, ok := variants[defaultVariant]
+	if !ok {
+		return raw, nil
+	}
+
+	expected := variantKind(defaultValue)
+	names := make([]string, 0, len(variants))
+	for variant, value := range variants {
+		if variantKind(value) != expected {
+			names = append(names, variant)
+		}
+	}
+	if len(names) == 0 {
+		return raw, nil
+	}
+	sort.Strings(names)
+	for _, variant := range names {
+		mismatch := fmt.Sprintf("variant: '%s' of flag: '%s' is %s, expected %s as its default variant: '%s'",
+			variant, key, kindArticle(variantKind(variants[variant])), kindArticle(expected), defaultVariant)
+		if je.variantTypeMismatch != VariantTypeMismatchWarn {
+			return raw, errors.New(mismatch)
+		}
+		je.Logger.Warn(mismatch + ", loading the flag without it")
+		delete(variants, variant)
+	}
+	fields["variants"], _ = json.Marshal(variants)
+	fixed, err := json.Marshal(fields)
+	if err != nil {
+		return raw, nil
+	}
+	return fixed, nil
+}
+
+// kindArticle returns the kind of a variant value with its article, e.g. "a boolean"
+func kindArticle(kind string) string {
+	switch kind {
+	case "null":
+		return kind
+	case ObjectFlagType, "array":
+		return "an " + kind
+	default:
+		return "a " + kind
+	}
+}
+
+// warnAmbiguousVariants warns about flags whose variants are valid but likely resolved as another type than the
+// intended one: string flags whose variants are all booleans or numbers written as strings, and numeric flags mixing
+// integer and non integer variants, which int resolutions truncate
+func (je *JSONEvaluator) warnAmbiguousVariants(key string, flag model.Flag) {
+	switch flagType, _ := flagType(flag); flagType {
+	case StringFlagType:
+		booleans, numbers := true, true
+		for _, value := range flag.Variants {
+			s, _ := value.(string)
+			if s != "true" && s != "false" {
+				booleans = false
+			}
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				numbers = false
+			}
+		}
+		if booleans {
+			je.Logger.Warn(fmt.Sprintf("variants of flag: '%s' are booleans written as strings, it resolves as a "+
+				"string flag rather than a boolean flag", key))
+		} else if numbers {
+			je.Logger.Warn(fmt.Sprintf("variants of flag: '%s' are numbers written as strings, it resolves as a "+
+				"string flag rather than a numeric flag", key))
+		}
+	case FloatFlagType:
+		if number, ok := flag.Variants[flag.DefaultVariant].(float64); !ok || !isExactInteger(number) {
+			return
+		}
+		var fractional []string
+		for variant, value := range flag.Variants {
+			if number, ok := value.(float64); ok && !isExactInteger(number) {
+				fractional = append(fractional, variant)
+			}
+		}
+		sort.Strings(fractional)
+		je.Logger.Warn(fmt.Sprintf("flag: '%s' has an integer default variant but the non integer variants: %v, "+
+			"they're truncated when it's resolved as an int flag", key, fractional))
+	}
+}
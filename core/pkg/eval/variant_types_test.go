@@ -0,0 +1,175 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func variantTypesFlagConfig(defaultValue, otherValue string) string {
+	return `{
+  "flags": {
+    "myFlag": {
+      "state": "ENABLED",
+      "variants": { "default": ` + defaultValue + `, "other": ` + otherValue + ` },
+      "defaultVariant": "default",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "other", null] }
+    }
+  }
+}`
+}
+
+func TestVariantTypes(t *testing.T) {
+	tests := map[string]struct {
+		defaultValue string
+		otherValue   string
+		wantErr      string
+	}{
+		"boolean variants": {
+			defaultValue: `false`,
+			otherValue:   `true`,
+		},
+		"boolean flag with a string variant": {
+			defaultValue: `false`,
+			otherValue:   `"on"`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is a string, expected a boolean as its default " +
+				"variant: 'default'",
+		},
+		"string flag with a boolean variant": {
+			defaultValue: `"red"`,
+			otherValue:   `true`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is a boolean, expected a string as its default " +
+				"variant: 'default'",
+		},
+		"string flag with an integer variant": {
+			defaultValue: `"A"`,
+			otherValue:   `2`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is a number, expected a string as its default " +
+				"variant: 'default'",
+		},
+		"numeric flag with a string variant": {
+			defaultValue: `1`,
+			otherValue:   `"2"`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is a string, expected a number as its default " +
+				"variant: 'default'",
+		},
+		"float flag with a boolean variant": {
+			defaultValue: `1.5`,
+			otherValue:   `false`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is a boolean, expected a number as its default " +
+				"variant: 'default'",
+		},
+		"object flag with an array variant": {
+			defaultValue: `{ "color": "red" }`,
+			otherValue:   `["red"]`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is an array, expected an object as its default " +
+				"variant: 'default'",
+		},
+		"string flag with a null variant": {
+			defaultValue: `"red"`,
+			otherValue:   `null`,
+			wantErr: "variant: 'other' of flag: 'myFlag' is null, expected a string as its default " +
+				"variant: 'default'",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJSONEvaluatorFromConfig(nil, variantTypesFlagConfig(tt.defaultValue, tt.otherValue))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestVariantTypes_Warn(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	log := logger.NewLogger(zap.New(core), false)
+	je, err := NewJSONEvaluatorFromConfig(log, variantTypesFlagConfig(`false`, `"on"`),
+		WithVariantTypeMismatch(VariantTypeMismatchWarn))
+	require.Nil(t, err, "flags with mismatched variants should be loaded")
+	require.Equal(t, 1, logs.FilterMessage("variant: 'other' of flag: 'myFlag' is a string, expected a "+
+		"boolean as its default variant: 'default', loading the flag without it").Len())
+
+	value, variant, _, _, err := je.ResolveBooleanValue(context.Background(), "", "myFlag", &structpb.Struct{})
+	require.Nil(t, err)
+	require.Equal(t, "default", variant)
+	require.False(t, value)
+
+	flag, ok := je.store.Get("myFlag")
+	require.True(t, ok)
+	require.NotContains(t, flag.Variants, "other", "the mismatched variant should be removed")
+}
+
+func TestVariantTypes_AmbiguousVariants(t *testing.T) {
+	tests := map[string]struct {
+		defaultValue string
+		otherValue   string
+		warning      string
+	}{
+		"booleans written as strings": {
+			defaultValue: `"false"`,
+			otherValue:   `"true"`,
+			warning: "variants of flag: 'myFlag' are booleans written as strings, it resolves as a string flag " +
+				"rather than a boolean flag",
+		},
+		"numbers written as strings": {
+			defaultValue: `"1"`,
+			otherValue:   `"2.5"`,
+			warning: "variants of flag: 'myFlag' are numbers written as strings, it resolves as a string flag " +
+				"rather than a numeric flag",
+		},
+		"integer default with a non integer variant": {
+			defaultValue: `1`,
+			otherValue:   `2.5`,
+			warning: "flag: 'myFlag' has an integer default variant but the non integer variants: [other], " +
+				"they're truncated when it's resolved as an int flag",
+		},
+		"strings": {
+			defaultValue: `"red"`,
+			otherValue:   `"true"`,
+		},
+		"integers": {
+			defaultValue: `1`,
+			otherValue:   `2`,
+		},
+		"non integer default": {
+			defaultValue: `1.5`,
+			otherValue:   `2`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			log := logger.NewLogger(zap.New(core), false)
+			_, err := NewJSONEvaluatorFromConfig(log, variantTypesFlagConfig(tt.defaultValue, tt.otherValue))
+			require.Nil(t, err, "ambiguous variants should be loaded")
+			if tt.warning == "" {
+				require.Zero(t, logs.Len())
+				return
+			}
+			require.Equal(t, 1, logs.FilterMessage(tt.warning).Len())
+		})
+	}
+}
+
+func TestParseVariantTypeMismatch(t *testing.T) {
+	policy, err := ParseVariantTypeMismatch("")
+	require.Nil(t, err)
+	require.Equal(t, VariantTypeMismatchError, policy)
+
+	policy, err = ParseVariantTypeMismatch("warn")
+	require.Nil(t, err)
+	require.Equal(t, VariantTypeMismatchWarn, policy)
+
+	_, err = ParseVariantTypeMismatch("ignore")
+	require.EqualError(t, err, "unknown variant type mismatch policy: 'ignore', expected 'error' or 'warn'")
+}
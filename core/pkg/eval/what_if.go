@@ -0,0 +1,95 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// WhatIf is implemented by evaluators able to evaluate stored flags with the values of some variants overridden
+type WhatIf interface {
+	EvaluateWhatIf(reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+	) (SandboxEvaluation, error)
+}
+
+// EvaluateWhatIf evaluates the stored flag against the context as if the values of the overridden variants were
+// live, without changing the store. Overrides of unknown variants, or of a different type than the variant, return
+// an error, failed evaluations return their error code.
+func (je *JSONEvaluator) EvaluateWhatIf(
+	reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	var eval SandboxEvaluation
+	flag, ok := je.store.Get(flagKey)
+	if !ok {
+		eval.trace("flag: %s isn't stored", flagKey)
+		return eval.failed(model.FlagNotFoundErrorCode), nil
+	}
+
+	variants := make(map[string]any, len(flag.Variants))
+	for variant, value := range flag.Variants {
+		variants[variant] = value
+	}
+	names := make([]string, 0, len(overrides))
+	for variant := range overrides {
+		names = append(names, variant)
+	}
+	sort.Strings(names)
+	for _, variant := range names {
+		live, ok := flag.Variants[variant]
+		if !ok {
+			return SandboxEvaluation{}, fmt.Errorf("'%s' isn't a variant of flag: %s", variant, flagKey)
+		}
+		var value interface{}
+		if err := json.Unmarshal(overrides[variant], &value); err != nil {
+			return SandboxEvaluation{}, fmt.Errorf("value of variant: %s: %w", variant, err)
+		}
+		if err := sameValueType(live, value); err != nil {
+			return SandboxEvaluation{}, fmt.Errorf("value of variant: %s: %w", variant, err)
+		}
+		variants[variant] = value
+		eval.trace("overrode the value of variant: %s with %s", variant, compact(overrides[variant]))
+	}
+	flag.Variants = variants
+	return je.evaluateDefinition(reqID, flagKey, flag, context, eval), nil
+}
+
+// sameValueType checks the override is of the type of the live value, as the variants of a flag share their type
+func sameValueType(live interface{}, override interface{}) error {
+	switch live.(type) {
+	case bool:
+		if _, ok := override.(bool); ok {
+			return nil
+		}
+	case string:
+		if _, ok := override.(string); ok {
+			return nil
+		}
+	case float64:
+		if _, ok := override.(float64); ok {
+			return nil
+		}
+	case map[string]interface{}:
+		if _, ok := override.(map[string]interface{}); ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return errors.New("isn't of the type of the live value")
+}
+
+// evaluateWhatIf evaluates the stored flag with overridden variants with the evaluator, if it supports what-if
+// evaluations
+func evaluateWhatIf(
+	evaluator IEvaluator, reqID string, flagKey string, overrides map[string]json.RawMessage, context *structpb.Struct,
+) (SandboxEvaluation, error) {
+	whatIf, ok := evaluator.(WhatIf)
+	if !ok {
+		return SandboxEvaluation{}, errors.New("the evaluator can't evaluate overridden variants")
+	}
+	return whatIf.EvaluateWhatIf(reqID, flagKey, overrides, context)
+}
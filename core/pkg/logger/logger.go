@@ -41,13 +41,28 @@ type Logger struct {
 	Logger        *zap.Logger
 	fields        []zap.Field
 	reqIDLogging  bool
+	// verboseRequests holds the requestIDs logged at the debug level of verboseLogger, if set
+	verboseRequests *sync.Map
+	verboseLogger   *zap.Logger
+}
+
+// requestLogger returns the logger of a requestID, false if its logs are dropped as request logging is disabled and
+// the request isn't verbose
+func (l *Logger) requestLogger(reqID string) (*zap.Logger, bool) {
+	if l.verboseRequests != nil {
+		if _, ok := l.verboseRequests.Load(reqID); ok {
+			return l.verboseLogger, true
+		}
+	}
+	return l.Logger, l.reqIDLogging
 }
 
 func (l *Logger) DebugWithID(reqID string, msg string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	logger, ok := l.requestLogger(reqID)
+	if !ok {
 		return
 	}
-	if ce := l.Logger.Check(zap.DebugLevel, msg); ce != nil {
+	if ce := logger.Check(zap.DebugLevel, msg); ce != nil {
 		fields = append(fields, l.getFieldsForLog(reqID)...)
 		ce.Write(fields...)
 	}
@@ -61,10 +76,11 @@ func (l *Logger) Debug(msg string, fields ...zap.Field) {
 }
 
 func (l *Logger) InfoWithID(reqID string, msg string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	logger, ok := l.requestLogger(reqID)
+	if !ok {
 		return
 	}
-	if ce := l.Logger.Check(zap.InfoLevel, msg); ce != nil {
+	if ce := logger.Check(zap.InfoLevel, msg); ce != nil {
 		fields = append(fields, l.getFieldsForLog(reqID)...)
 		ce.Write(fields...)
 	}
@@ -78,10 +94,11 @@ func (l *Logger) Info(msg string, fields ...zap.Field) {
 }
 
 func (l *Logger) WarnWithID(reqID string, msg string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	logger, ok := l.requestLogger(reqID)
+	if !ok {
 		return
 	}
-	if ce := l.Logger.Check(zap.WarnLevel, msg); ce != nil {
+	if ce := logger.Check(zap.WarnLevel, msg); ce != nil {
 		fields = append(fields, l.getFieldsForLog(reqID)...)
 		ce.Write(fields...)
 	}
@@ -95,10 +112,11 @@ func (l *Logger) Warn(msg string, fields ...zap.Field) {
 }
 
 func (l *Logger) ErrorWithID(reqID string, msg string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	logger, ok := l.requestLogger(reqID)
+	if !ok {
 		return
 	}
-	if ce := l.Logger.Check(zap.ErrorLevel, msg); ce != nil {
+	if ce := logger.Check(zap.ErrorLevel, msg); ce != nil {
 		fields = append(fields, l.getFieldsForLog(reqID)...)
 		ce.Write(fields...)
 	}
@@ -112,10 +130,11 @@ func (l *Logger) Error(msg string, fields ...zap.Field) {
 }
 
 func (l *Logger) FatalWithID(reqID string, msg string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	logger, ok := l.requestLogger(reqID)
+	if !ok {
 		return
 	}
-	if ce := l.Logger.Check(zap.FatalLevel, msg); ce != nil {
+	if ce := logger.Check(zap.FatalLevel, msg); ce != nil {
 		fields = append(fields, l.getFieldsForLog(reqID)...)
 		ce.Write(fields...)
 	}
@@ -131,7 +150,7 @@ func (l *Logger) Fatal(msg string, fields ...zap.Field) {
 // WriteFields adds field key and value pairs to the highest level Logger, they will be applied to all
 // subsequent log calls using the matching requestID
 func (l *Logger) WriteFields(reqID string, fields ...zap.Field) {
-	if !l.reqIDLogging {
+	if _, ok := l.requestLogger(reqID); !ok {
 		return
 	}
 	res := append(l.getFields(reqID), fields...)
@@ -157,14 +176,28 @@ func (l *Logger) getFieldsForLog(reqID string) []zap.Field {
 	return fields
 }
 
-// ClearFields clears all stored fields for a given requestID, important for maintaining performance
+// ClearFields clears all stored fields for a given requestID, important for maintaining performance. The request is
+// no longer verbose.
 func (l *Logger) ClearFields(reqID string) {
+	if l.verboseRequests != nil {
+		l.verboseRequests.Delete(reqID)
+	}
 	if !l.reqIDLogging {
 		return
 	}
 	l.requestFields.Delete(reqID)
 }
 
+// SetVerbose logs the subsequent XxxWithID log calls of a requestID at the debug level, regardless of the level of
+// the logger, e.g. to debug the evaluations of a single flag. It has no effect unless the logger was created by
+// NewVerboseLogger. ClearFields must be called once the request is done.
+func (l *Logger) SetVerbose(reqID string) {
+	if l.verboseRequests == nil {
+		return
+	}
+	l.verboseRequests.Store(reqID, struct{}{})
+}
+
 // NewZapLogger creates a *zap.Logger using the base config
 func NewZapLogger(level zapcore.Level, logFormat string) (*zap.Logger, error) {
 	cfg := zap.Config{
@@ -207,14 +240,41 @@ func NewLogger(logger *zap.Logger, reqIDLogging bool) *Logger {
 	}
 }
 
+// NewVerboseLogger returns the logging wrapper of a *zap.Logger enabling the debug level, filtering its logs to the
+// provided level except for the requests set verbose through SetVerbose, which are logged at the debug level.
+// Request fields are only kept for verbose requests unless reqIDLogging is set.
+func NewVerboseLogger(logger *zap.Logger, level zapcore.Level, reqIDLogging bool) *Logger {
+	l := NewLogger(logger.WithOptions(zap.IncreaseLevel(level)), reqIDLogging)
+	l.verboseRequests = &sync.Map{}
+	l.verboseLogger = logger.WithOptions(zap.AddCallerSkip(1))
+	return l
+}
+
+// WithLevel creates a new logging wrapper filtering its logs to the level rather than to the level of the wrapper,
+// e.g. for the logs of a subsystem. Loggers created by NewVerboseLogger may lower the level down to the debug level,
+// other loggers can only raise it. Requests set verbose are still logged at the debug level.
+func (l *Logger) WithLevel(level zapcore.Level) *Logger {
+	base := l.Logger
+	if l.verboseLogger != nil {
+		base = l.verboseLogger
+	}
+	child := *l
+	if base.Core().Enabled(level) {
+		child.Logger = base.WithOptions(zap.IncreaseLevel(level))
+	}
+	return &child
+}
+
 // WithFields creates a new logging wrapper with a predefined base set of fields.
 // These fields will be added to each request, but the logger will still
 // read/write from the highest level logging wrappers field pool
 func (l *Logger) WithFields(fields ...zap.Field) *Logger {
 	return &Logger{
-		Logger:        l.Logger,
-		requestFields: l.requestFields,
-		fields:        fields,
-		reqIDLogging:  l.reqIDLogging,
+		Logger:          l.Logger,
+		requestFields:   l.requestFields,
+		fields:          fields,
+		reqIDLogging:    l.reqIDLogging,
+		verboseRequests: l.verboseRequests,
+		verboseLogger:   l.verboseLogger,
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestFieldStorageAndRetrieval(t *testing.T) {
@@ -83,3 +84,65 @@ func TestLoggerChildOperation(t *testing.T) {
 		t.Error("field 2 is present in the parent logger getFieldsForLog response")
 	}
 }
+
+func TestVerboseLogger(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	l := NewVerboseLogger(zap.New(core), zap.InfoLevel, false)
+	child := l.WithFields(zap.String("component", "child"))
+
+	l.Debug("not logged below the level")
+	l.DebugWithID("quiet", "not logged for quiet requests")
+	l.WriteFields("quiet", zap.String("field", "quiet"))
+	if len(l.getFields("quiet")) != 0 {
+		t.Error("fields are stored for quiet requests")
+	}
+
+	l.SetVerbose("verbose")
+	l.WriteFields("verbose", zap.String("field", "verbose"))
+	child.DebugWithID("verbose", "logged for verbose requests")
+	entries := logs.FilterMessage("logged for verbose requests").All()
+	if len(entries) != 1 {
+		t.Fatal("the verbose request wasn't logged by the child logger", logs.All())
+	}
+	fields := entries[0].ContextMap()
+	if fields["field"] != "verbose" || fields["component"] != "child" || fields[RequestIDFieldName] != "verbose" {
+		t.Error("the verbose request is logged without its fields", fields)
+	}
+
+	l.ClearFields("verbose")
+	l.DebugWithID("verbose", "not logged once cleared")
+	if logs.Len() != 1 {
+		t.Error("unexpected logs", logs.All())
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	l := NewVerboseLogger(zap.New(core), zap.InfoLevel, false)
+	verbose := l.WithLevel(zap.DebugLevel).WithFields(zap.String("component", "sync"))
+	quiet := l.WithLevel(zap.WarnLevel).WithFields(zap.String("component", "evaluator"))
+
+	verbose.Debug("logged below the level of the parent")
+	quiet.Info("not logged below the level of the logger")
+	quiet.Warn("logged at the level of the logger")
+	l.Debug("not logged below the level of the parent")
+	if logs.Len() != 2 ||
+		logs.FilterMessage("logged below the level of the parent").Len() != 1 ||
+		logs.FilterMessage("logged at the level of the logger").Len() != 1 {
+		t.Error("logs aren't filtered to the level of their logger", logs.All())
+	}
+
+	l.SetVerbose("verbose")
+	quiet.DebugWithID("verbose", "logged for verbose requests")
+	if logs.FilterMessage("logged for verbose requests").Len() != 1 {
+		t.Error("verbose requests aren't logged whatever the level", logs.All())
+	}
+
+	infoCore, infoLogs := observer.New(zap.InfoLevel)
+	raised := NewLogger(zap.New(infoCore), false).WithLevel(zap.DebugLevel)
+	raised.Debug("not logged below the level of the zap logger")
+	raised.Info("logged at the level of the zap logger")
+	if infoLogs.Len() != 1 {
+		t.Error("the level of loggers which aren't verbose can only be raised", infoLogs.All())
+	}
+}
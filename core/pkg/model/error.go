@@ -1,9 +1,14 @@
 package model
 
 const (
-	FlagNotFoundErrorCode = "FLAG_NOT_FOUND"
-	ParseErrorCode        = "PARSE_ERROR"
-	TypeMismatchErrorCode = "TYPE_MISMATCH"
-	GeneralErrorCode      = "GENERAL"
-	FlagDisabledErrorCode = "FLAG_DISABLED"
+	FlagNotFoundErrorCode   = "FLAG_NOT_FOUND"
+	ParseErrorCode          = "PARSE_ERROR"
+	TypeMismatchErrorCode   = "TYPE_MISMATCH"
+	GeneralErrorCode        = "GENERAL"
+	FlagDisabledErrorCode   = "FLAG_DISABLED"
+	InvalidContextErrorCode = "INVALID_CONTEXT"
+	// MissingContextErrorCode is returned for flags evaluated without a context key they require
+	MissingContextErrorCode = "MISSING_CONTEXT"
+	// ProviderNotReadyErrorCode is returned for flags requested before the initial sync of the flag configuration
+	ProviderNotReadyErrorCode = "PROVIDER_NOT_READY"
 )
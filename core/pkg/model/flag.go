@@ -8,6 +8,77 @@ type Flag struct {
 	Variants       map[string]any  `json:"variants"`
 	Targeting      json.RawMessage `json:"targeting,omitempty"`
 	Source         string          `json:"source"`
+	// Metadata holds boolean, number and string values returned alongside each resolution of the flag
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// CacheTTL is the number of seconds clients may cache resolutions of the flag for, if set
+	CacheTTL *int64 `json:"cacheTtl,omitempty"`
+	// Derived is an and, or and not expression over boolean flags resolving the value of a boolean flag, if set
+	Derived json.RawMessage `json:"derived,omitempty"`
+	// Schema is a JSON schema the variants of an object flag conform to, if set
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// HashContextKeys are the evaluation context paths the evaluation hash covers, the paths referenced by the
+	// targeting if unset
+	HashContextKeys []string `json:"hashContextKeys,omitempty"`
+	// Template substitutes evaluation context values into the {path} placeholders of the string variants of the flag
+	Template bool `json:"template,omitempty"`
+	// DefaultVariantByContext selects the default variant by the value of an evaluation context key, if set
+	DefaultVariantByContext *DefaultVariantByContext `json:"defaultVariantByContext,omitempty"`
+	// FractionalRandomization is when the fractional evaluations of the flag sample their distribution per
+	// evaluation rather than bucketing a context value: never if unset, when the value is missing, or always
+	FractionalRandomization string `json:"fractionalRandomization,omitempty"`
+	// Rulesets selects the targeting rule by the value of an evaluation context key, falling back to Targeting, if set
+	Rulesets *Rulesets `json:"rulesets,omitempty"`
+	// RequireContext lists the context keys the flag fails evaluating without, rather than falling through to its
+	// default variant
+	RequireContext []string `json:"requireContext,omitempty"`
+	// MaskedFields are the dot separated paths of the fields left out of the resolved values of an object flag, e.g.
+	// fields meant for the services reading the configuration rather than for clients
+	MaskedFields []string `json:"maskedFields,omitempty"`
+	// Experiment is the key of the A/B experiment the flag is associated with, returned along with the assigned
+	// variant in the metadata of the resolutions assigned by a split
+	Experiment string `json:"experiment,omitempty"`
+	// TraceSampling is the fraction of the evaluations of the flag which are traced, between 0 and 1, unless the
+	// server configures the rate of the flag or the caller propagates a sampling decision
+	TraceSampling *float64 `json:"traceSampling,omitempty"`
+	// LastGoodStaleness is the number of seconds the last successful targeting result of the flag for an evaluation
+	// context is served for when evaluating its targeting fails, errors are returned if unset
+	LastGoodStaleness *int64 `json:"lastGoodStaleness,omitempty"`
+	// BaseContext holds the evaluation context values merged under the context of each evaluation of the flag, the
+	// values of the evaluation context taking precedence. Configurations may reference a base context of their
+	// $baseContexts by name, it's stored inline.
+	BaseContext map[string]interface{} `json:"baseContext,omitempty"`
+	// TargetingOrder hints the order the conditions of the top-level if of the targeting are evaluated in, if set
+	TargetingOrder *TargetingOrder `json:"targetingOrder,omitempty"`
+}
+
+// TargetingOrder is the evaluation order of the conditions of the top-level if of the targeting of a flag. Conditions
+// are evaluated one at a time until one matches, the result is the one of the declared order unless the conditions
+// are exclusive.
+type TargetingOrder struct {
+	// Conditions are the indexes of the conditions in the order they're evaluated, the conditions left out are
+	// evaluated after them in their declared order
+	Conditions []int `json:"conditions,omitempty"`
+	// Exclusive asserts no evaluation context matches more than one condition, so the first condition matching in
+	// the evaluation order is the result without evaluating the conditions declared before it
+	Exclusive bool `json:"exclusive,omitempty"`
+}
+
+// DefaultVariantByContext maps the values of an evaluation context key to the default variant of a flag, values
+// which aren't mapped fall back to the DefaultVariant of the flag
+type DefaultVariantByContext struct {
+	// ContextKey is the dot separated path of the evaluation context value, e.g. user.plan
+	ContextKey string `json:"contextKey"`
+	// Variants are the default variants keyed by context value, numbers and booleans are keyed by their json
+	Variants map[string]string `json:"variants"`
+}
+
+// Rulesets maps the values of an evaluation context key to the targeting rules of a flag, e.g. per environment,
+// values which aren't mapped fall back to the Targeting of the flag
+type Rulesets struct {
+	// ContextKey is the dot separated path of the evaluation context value, e.g. environment
+	ContextKey string `json:"contextKey"`
+	// Rules are the targeting rules keyed by context value, numbers and booleans are keyed by their json
+	Rules map[string]json.RawMessage `json:"rules"`
 }
 
 type Evaluators struct {
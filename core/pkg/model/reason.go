@@ -10,4 +10,11 @@ const (
 	UnknownReason        = "UNKNOWN"
 	ErrorReason          = "ERROR"
 	StaticReason         = "STATIC"
+	DerivedReason        = "DERIVED"
+	OverrideReason       = "OVERRIDE"
+	StaleReason          = "STALE"
+	// ClientDefaultReason is the reason of flags resolving the default value sent by the client
+	ClientDefaultReason = "CLIENT_DEFAULT"
+	// ZeroValueReason is the reason of flags resolving the zero value of their type
+	ZeroValueReason = "ZERO_VALUE"
 )
@@ -0,0 +1,76 @@
+package otel
+
+import (
+	msync "sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OtherReason is the reason label of the evaluations beyond the distinct reasons cap
+const OtherReason = "other"
+
+// MetricLabels bounds the cardinality of the labels of the flag metrics
+type MetricLabels struct {
+	// DroppedFlagKeys are the flags whose metrics are recorded without the flag_key label, aggregated with the
+	// metrics of every other dropped flag, e.g. flags generated per customer
+	DroppedFlagKeys []string
+	// MaxReasons caps the distinct reason labels of the flag evaluations, further reasons being recorded as
+	// OtherReason. Reasons aren't capped when 0.
+	MaxReasons int
+}
+
+// RecorderOption configures the metrics recorder
+type RecorderOption func(*MetricsRecorder)
+
+// WithMetricLabels bounds the labels of the flag metrics
+func WithMetricLabels(labels MetricLabels) RecorderOption {
+	return func(r *MetricsRecorder) {
+		r.labels = newLabelPolicy(labels)
+	}
+}
+
+// labelPolicy applies the metric labels, remembering the reasons labelled so far
+type labelPolicy struct {
+	droppedFlagKeys map[string]struct{}
+	maxReasons      int
+
+	mx      msync.Mutex
+	reasons map[string]struct{}
+}
+
+func newLabelPolicy(labels MetricLabels) *labelPolicy {
+	p := &labelPolicy{
+		droppedFlagKeys: make(map[string]struct{}, len(labels.DroppedFlagKeys)),
+		maxReasons:      labels.MaxReasons,
+		reasons:         map[string]struct{}{},
+	}
+	for _, flagKey := range labels.DroppedFlagKeys {
+		p.droppedFlagKeys[flagKey] = struct{}{}
+	}
+	return p
+}
+
+// flagKey returns the flag_key label of the flag, reporting false when its label is dropped
+func (p *labelPolicy) flagKey(flagKey string) (attribute.KeyValue, bool) {
+	if _, ok := p.droppedFlagKeys[flagKey]; ok {
+		return attribute.KeyValue{}, false
+	}
+	return attribute.String("flag_key", flagKey), true
+}
+
+// reason returns the reason label, OtherReason once the cap of distinct reasons is reached by other reasons
+func (p *labelPolicy) reason(reason string) string {
+	if p.maxReasons <= 0 {
+		return reason
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if _, ok := p.reasons[reason]; ok {
+		return reason
+	}
+	if len(p.reasons) >= p.maxReasons {
+		return OtherReason
+	}
+	p.reasons[reason] = struct{}{}
+	return reason
+}
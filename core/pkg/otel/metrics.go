@@ -2,22 +2,40 @@ package otel
 
 import (
 	"context"
+	"errors"
+	goruntime "runtime"
 	"time"
 
+	"github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
+	metricapi "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/metric/unit"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/aggregation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDExemplarLabel is the exemplar label holding the trace id of an evaluation
+	TraceIDExemplarLabel = "trace_id"
+
+	evaluationDurationName = "evaluation_duration_seconds"
 )
 
 type MetricsRecorder struct {
+	meter                     metricapi.Meter
 	httpRequestDurHistogram   instrument.Float64Histogram
 	httpResponseSizeHistogram instrument.Float64Histogram
 	httpRequestsInflight      instrument.Int64UpDownCounter
+	configVersionEvaluations  instrument.Int64Counter
+	flagEvaluations           instrument.Int64Counter
+	labels                    *labelPolicy
+	// evaluationDuration is a prometheus histogram, as the OpenTelemetry SDK doesn't record exemplars
+	evaluationDuration *prometheus.HistogramVec
 }
 
 func (r MetricsRecorder) HTTPAttributes(svcName, url, method, code string) []attribute.KeyValue {
@@ -45,6 +63,282 @@ func (r MetricsRecorder) InFlightRequestEnd(ctx context.Context, attrs []attribu
 	r.httpRequestsInflight.Add(ctx, -1, attrs...)
 }
 
+// ConfigVersionEvaluation counts an evaluation served by a configuration version, e.g. the stable or candidate
+// configuration of a canary rollout
+func (r MetricsRecorder) ConfigVersionEvaluation(ctx context.Context, version string) {
+	r.configVersionEvaluations.Add(ctx, 1, attribute.String("config_version", version))
+}
+
+// FlagEvaluation counts an evaluation of a flag by reason, the labels being bounded by the metric labels
+func (r MetricsRecorder) FlagEvaluation(ctx context.Context, flagKey string, reason string) {
+	attrs := []attribute.KeyValue{attribute.String("reason", r.labels.reason(reason))}
+	if label, ok := r.labels.flagKey(flagKey); ok {
+		attrs = append(attrs, label)
+	}
+	r.flagEvaluations.Add(ctx, 1, attrs...)
+}
+
+// EvaluationDuration records the latency of an evaluation. Evaluations of a sampled trace carry its trace id as an
+// exemplar, linking latency spikes to the traces of the evaluations.
+func (r MetricsRecorder) EvaluationDuration(ctx context.Context, method string, duration time.Duration) {
+	observer := r.evaluationDuration.WithLabelValues(method)
+	spanContext := trace.SpanContextFromContext(ctx)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && spanContext.IsSampled() {
+		exemplarObserver.ObserveWithExemplar(
+			duration.Seconds(), prometheus.Labels{TraceIDExemplarLabel: spanContext.TraceID().String()},
+		)
+		return
+	}
+	observer.Observe(duration.Seconds())
+}
+
+// RegisterEvaluationDuration registers the evaluation latency histogram, exemplars are exposed by registries
+// served in the OpenMetrics format. Registering it again is a no-op, a recorder registering to a registerer which
+// already holds the histogram of another recorder records into that one.
+func (r *MetricsRecorder) RegisterEvaluationDuration(registerer prometheus.Registerer) error {
+	err := registerer.Register(r.evaluationDuration)
+	var registered prometheus.AlreadyRegisteredError
+	if !errors.As(err, &registered) {
+		return err
+	}
+	existing, ok := registered.ExistingCollector.(*prometheus.HistogramVec)
+	if !ok {
+		return err
+	}
+	r.evaluationDuration = existing
+	return nil
+}
+
+// RegisterSourceStatuses observes the status of the flag sources on every collection: the timestamp of the last
+// successful sync and last error, along with the number of updates and errors of each source
+func (r MetricsRecorder) RegisterSourceStatuses(statuses *sync.SourceStatuses) error {
+	lastSync, err := r.meter.Float64ObservableGauge(
+		"sync_last_success_timestamp_seconds",
+		instrument.WithDescription("The unix timestamp of the last successful sync of a source"),
+	)
+	if err != nil {
+		return err
+	}
+	lastError, err := r.meter.Float64ObservableGauge(
+		"sync_last_error_timestamp_seconds",
+		instrument.WithDescription("The unix timestamp of the last failed sync of a source"),
+	)
+	if err != nil {
+		return err
+	}
+	updates, err := r.meter.Int64ObservableCounter(
+		"sync_updates",
+		instrument.WithDescription("The number of successful syncs of a source"),
+	)
+	if err != nil {
+		return err
+	}
+	errs, err := r.meter.Int64ObservableCounter(
+		"sync_errors",
+		instrument.WithDescription("The number of failed syncs of a source"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		for _, status := range statuses.Snapshot() {
+			attrs := []attribute.KeyValue{attribute.String("source", status.Source)}
+			if !status.LastSync.IsZero() {
+				o.ObserveFloat64(lastSync, unixSeconds(status.LastSync), attrs...)
+			}
+			if !status.LastErrorTime.IsZero() {
+				o.ObserveFloat64(lastError, unixSeconds(status.LastErrorTime), attrs...)
+			}
+			o.ObserveInt64(updates, status.Updates, attrs...)
+			o.ObserveInt64(errs, status.Errors, attrs...)
+		}
+		return nil
+	}, lastSync, lastError, updates, errs)
+	return err
+}
+
+// RegisterStreamSubscribers observes the number of active event stream subscribers
+func (r MetricsRecorder) RegisterStreamSubscribers(count func() int64) error {
+	subscribers, err := r.meter.Int64ObservableGauge(
+		"stream_subscribers",
+		instrument.WithDescription("The number of active event stream subscribers"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		o.ObserveInt64(subscribers, count())
+		return nil
+	}, subscribers)
+	return err
+}
+
+// RegisterRuntimeDiagnostics observes the number of open connections of the evaluation service, along with the
+// goroutines and the heap of the process, e.g. to spot leaking streams
+func (r MetricsRecorder) RegisterRuntimeDiagnostics(connections func() int64) error {
+	connectionsGauge, err := r.meter.Int64ObservableGauge(
+		"connections_active",
+		instrument.WithDescription("The number of open connections of the evaluation service"),
+	)
+	if err != nil {
+		return err
+	}
+	goroutines, err := r.meter.Int64ObservableGauge(
+		"goroutines",
+		instrument.WithDescription("The number of goroutines of the process"),
+	)
+	if err != nil {
+		return err
+	}
+	heap, err := r.meter.Int64ObservableGauge(
+		"heap_alloc_bytes",
+		instrument.WithDescription("The bytes of allocated heap objects"),
+		instrument.WithUnit(unit.Bytes),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		var mem goruntime.MemStats
+		goruntime.ReadMemStats(&mem)
+		o.ObserveInt64(connectionsGauge, connections())
+		o.ObserveInt64(goroutines, int64(goruntime.NumGoroutine()))
+		o.ObserveInt64(heap, int64(mem.HeapAlloc))
+		return nil
+	}, connectionsGauge, goroutines, heap)
+	return err
+}
+
+// RegisterVariantDistribution observes the number of evaluations of each flag by returned variant within the
+// distribution window, e.g. to confirm the split of an experiment. The variants of the flags whose flag_key label is
+// dropped are observed summed over these flags.
+func (r MetricsRecorder) RegisterVariantDistribution(snapshot func() map[string]map[string]int64) error {
+	distribution, err := r.meter.Int64ObservableGauge(
+		"variant_distribution",
+		instrument.WithDescription("The number of evaluations of a flag returning a variant within the window"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		dropped := map[string]int64{}
+		for flagKey, variants := range snapshot() {
+			label, ok := r.labels.flagKey(flagKey)
+			for variant, n := range variants {
+				if !ok {
+					dropped[variant] += n
+					continue
+				}
+				o.ObserveInt64(distribution, n, label, attribute.String("variant", variant))
+			}
+		}
+		for variant, n := range dropped {
+			o.ObserveInt64(distribution, n, attribute.String("variant", variant))
+		}
+		return nil
+	}, distribution)
+	return err
+}
+
+// RegisterEvaluationAdmission observes the number of unary evaluations being served and queued waiting for a slot
+func (r MetricsRecorder) RegisterEvaluationAdmission(inFlight func() int64, queued func() int64) error {
+	inFlightGauge, err := r.meter.Int64ObservableGauge(
+		"evaluations_inflight",
+		instrument.WithDescription("The number of evaluations being served"),
+	)
+	if err != nil {
+		return err
+	}
+	queuedGauge, err := r.meter.Int64ObservableGauge(
+		"evaluations_queued",
+		instrument.WithDescription("The number of evaluations queued waiting for a slot"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		o.ObserveInt64(inFlightGauge, inFlight())
+		o.ObserveInt64(queuedGauge, queued())
+		return nil
+	}, inFlightGauge, queuedGauge)
+	return err
+}
+
+// RegisterDroppedEvaluationEvents observes the number of evaluation events the evaluation webhook dropped, by reason:
+// overload for events dropped as its queue was full, delivery for events of batches which failed to be posted
+func (r MetricsRecorder) RegisterDroppedEvaluationEvents(overloaded func() int64, undelivered func() int64) error {
+	dropped, err := r.meter.Int64ObservableCounter(
+		"evaluation_events_dropped",
+		instrument.WithDescription("The number of evaluation events dropped by the evaluation webhook"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		o.ObserveInt64(dropped, overloaded(), attribute.String("reason", "overload"))
+		o.ObserveInt64(dropped, undelivered(), attribute.String("reason", "delivery"))
+		return nil
+	}, dropped)
+	return err
+}
+
+// RuleStatistics counts the evaluations of the targeting rule of a flag, by matched branch
+type RuleStatistics struct {
+	ConditionsEvaluated int64
+	Branches            map[string]int64
+}
+
+// RegisterRuleStatistics observes the number of evaluations of the targeting rule of each flag by matched branch,
+// along with the number of conditions evaluated. The average number of conditions evaluated per evaluation is the
+// ratio of the conditions to the sum of the branches. The statistics of the flags whose flag_key label is dropped are
+// observed summed over these flags.
+func (r MetricsRecorder) RegisterRuleStatistics(snapshot func() map[string]RuleStatistics) error {
+	branches, err := r.meter.Int64ObservableCounter(
+		"targeting_rule_branches",
+		instrument.WithDescription("The number of evaluations of the targeting rule of a flag matching a branch"),
+	)
+	if err != nil {
+		return err
+	}
+	conditions, err := r.meter.Int64ObservableCounter(
+		"targeting_rule_conditions",
+		instrument.WithDescription("The number of conditions evaluated by the targeting rule of a flag"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.meter.RegisterCallback(func(_ context.Context, o metricapi.Observer) error {
+		dropped := RuleStatistics{Branches: map[string]int64{}}
+		for flagKey, stats := range snapshot() {
+			label, ok := r.labels.flagKey(flagKey)
+			if !ok {
+				dropped.ConditionsEvaluated += stats.ConditionsEvaluated
+				for branch, n := range stats.Branches {
+					dropped.Branches[branch] += n
+				}
+				continue
+			}
+			for branch, n := range stats.Branches {
+				o.ObserveInt64(branches, n, label, attribute.String("branch", branch))
+			}
+			o.ObserveInt64(conditions, stats.ConditionsEvaluated, label)
+		}
+		if len(dropped.Branches) > 0 || dropped.ConditionsEvaluated > 0 {
+			for branch, n := range dropped.Branches {
+				o.ObserveInt64(branches, n, attribute.String("branch", branch))
+			}
+			o.ObserveInt64(conditions, dropped.ConditionsEvaluated)
+		}
+		return nil
+	}, branches, conditions)
+	return err
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
 func getDurationView(svcName, viewName string, bucket []float64) metric.View {
 	return metric.NewView(
 		metric.Instrument{
@@ -60,7 +354,7 @@ func getDurationView(svcName, viewName string, bucket []float64) metric.View {
 	)
 }
 
-func NewOTelRecorder(exporter metric.Reader, serviceName string) *MetricsRecorder {
+func NewOTelRecorder(exporter metric.Reader, serviceName string, opts ...RecorderOption) *MetricsRecorder {
 	const requestDurationName = "http_request_duration_seconds"
 	const responseSizeName = "http_response_size_bytes"
 
@@ -87,9 +381,32 @@ func NewOTelRecorder(exporter metric.Reader, serviceName string) *MetricsRecorde
 		"http_requests_inflight",
 		instrument.WithDescription("The number of inflight requests being handled at the same time"),
 	)
-	return &MetricsRecorder{
+	versionCounter, _ := meter.Int64Counter(
+		"config_version_evaluations",
+		instrument.WithDescription("The number of evaluations served by each configuration version"),
+	)
+	flagEvaluations, _ := meter.Int64Counter(
+		"flag_evaluations",
+		instrument.WithDescription("The number of evaluations of a flag by reason"),
+	)
+	evaluationDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: evaluationDurationName,
+		Help: "The latency of the flag evaluations",
+		// evaluations usually take well under a millisecond, buckets range from 100µs to 1.6s
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+	}, []string{"method"})
+	r := &MetricsRecorder{
+		meter:                     meter,
 		httpRequestDurHistogram:   hduration,
 		httpResponseSizeHistogram: hsize,
 		httpRequestsInflight:      reqCounter,
+		configVersionEvaluations:  versionCounter,
+		flagEvaluations:           flagEvaluations,
+		labels:                    newLabelPolicy(MetricLabels{}),
+		evaluationDuration:        evaluationDuration,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
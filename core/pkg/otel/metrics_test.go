@@ -2,12 +2,19 @@ package otel
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	semconv "go.opentelemetry.io/otel/semconv/v1.13.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const svcName = "mySvc"
@@ -87,6 +94,7 @@ func TestNewOTelRecorder(t *testing.T) {
 	require.NotNil(t, rec.httpRequestDurHistogram, "Expected httpRequestDurHistogram to be created")
 	require.NotNil(t, rec.httpResponseSizeHistogram, "Expected httpResponseSizeHistogram to be created")
 	require.NotNil(t, rec.httpRequestsInflight, "Expected httpRequestsInflight to be created")
+	require.NotNil(t, rec.configVersionEvaluations, "Expected configVersionEvaluations to be created")
 }
 
 func TestMetrics(t *testing.T) {
@@ -127,6 +135,14 @@ func TestMetrics(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "ConfigVersionEvaluation",
+			metricFunc: func() {
+				for i := 0; i < n; i++ {
+					rec.ConfigVersionEvaluation(ctx, "candidate")
+				}
+			},
+		},
 	}
 	i := 0
 	for _, tt := range tests {
@@ -144,3 +160,308 @@ func TestMetrics(t *testing.T) {
 		require.Equal(t, i, len(scopeMetrics.Metrics))
 	}
 }
+
+func TestRegisterSourceStatuses(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	statuses := sync.NewSourceStatuses("a.json")
+	require.Nil(t, rec.RegisterSourceStatuses(statuses))
+
+	lastSync := time.Unix(1680000000, 0)
+	statuses.RecordSync("a.json", lastSync)
+	statuses.RecordSync("a.json", lastSync)
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	observed := map[string]interface{}{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch d := m.Data.(type) {
+		case metricdata.Gauge[float64]:
+			for _, p := range d.DataPoints {
+				observed[m.Name] = p.Value
+			}
+		case metricdata.Sum[int64]:
+			for _, p := range d.DataPoints {
+				source, _ := p.Attributes.Value("source")
+				require.Equal(t, "a.json", source.AsString())
+				observed[m.Name] = p.Value
+			}
+		}
+	}
+	require.Equal(t, map[string]interface{}{
+		"sync_last_success_timestamp_seconds": float64(1680000000),
+		"sync_updates":                        int64(2),
+		"sync_errors":                         int64(0),
+	}, observed)
+}
+
+func TestRegisterStreamSubscribers(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	subscribers := int64(3)
+	require.Nil(t, rec.RegisterStreamSubscribers(func() int64 { return subscribers }))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 1)
+	require.Equal(t, "stream_subscribers", data.ScopeMetrics[0].Metrics[0].Name)
+	gauge, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	require.Equal(t, int64(3), gauge.DataPoints[0].Value)
+}
+
+func TestRegisterRuntimeDiagnostics(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	require.Nil(t, rec.RegisterRuntimeDiagnostics(func() int64 { return 4 }))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	observed := map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		require.True(t, ok)
+		observed[m.Name] = gauge.DataPoints[0].Value
+	}
+	require.Equal(t, int64(4), observed["connections_active"])
+	require.Positive(t, observed["goroutines"])
+	require.Positive(t, observed["heap_alloc_bytes"])
+}
+
+func TestEvaluationDuration(t *testing.T) {
+	rec := NewOTelRecorder(metric.NewManualReader(), svcName)
+	registry := prometheus.NewRegistry()
+	require.Nil(t, rec.RegisterEvaluationDuration(registry))
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.Nil(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.Nil(t, err)
+	sampled := trace.ContextWithSpanContext(context.TODO(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	unsampled := trace.ContextWithSpanContext(context.TODO(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID,
+	}))
+	rec.EvaluationDuration(sampled, "ResolveBoolean", 2*time.Millisecond)
+	rec.EvaluationDuration(unsampled, "ResolveString", time.Millisecond)
+	rec.EvaluationDuration(context.TODO(), "ResolveString", time.Millisecond)
+
+	families, err := registry.Gather()
+	require.Nil(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, evaluationDurationName, families[0].GetName())
+	exemplars := map[string][]*dto.Exemplar{}
+	for _, m := range families[0].GetMetric() {
+		method := m.GetLabel()[0].GetValue()
+		for _, bucket := range m.GetHistogram().GetBucket() {
+			if bucket.GetExemplar() != nil {
+				exemplars[method] = append(exemplars[method], bucket.GetExemplar())
+			}
+		}
+	}
+	require.Len(t, exemplars["ResolveBoolean"], 1)
+	require.Equal(t, TraceIDExemplarLabel, exemplars["ResolveBoolean"][0].GetLabel()[0].GetName())
+	require.Equal(t, traceID.String(), exemplars["ResolveBoolean"][0].GetLabel()[0].GetValue())
+	require.Equal(t, 0.002, exemplars["ResolveBoolean"][0].GetValue())
+	require.Empty(t, exemplars["ResolveString"], "evaluations of unsampled or missing traces shouldn't carry exemplars")
+}
+
+func TestRegisterVariantDistribution(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	require.Nil(t, rec.RegisterVariantDistribution(func() map[string]map[string]int64 {
+		return map[string]map[string]int64{"headerColor": {"red": 3, "blue": 2}}
+	}))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 1)
+	require.Equal(t, "variant_distribution", data.ScopeMetrics[0].Metrics[0].Name)
+	gauge, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	observed := map[string]int64{}
+	for _, p := range gauge.DataPoints {
+		flagKey, _ := p.Attributes.Value("flag_key")
+		variant, _ := p.Attributes.Value("variant")
+		observed[flagKey.AsString()+"/"+variant.AsString()] = p.Value
+	}
+	require.Equal(t, map[string]int64{"headerColor/red": 3, "headerColor/blue": 2}, observed)
+}
+
+func TestRegisterRuleStatistics(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	require.Nil(t, rec.RegisterRuleStatistics(func() map[string]RuleStatistics {
+		return map[string]RuleStatistics{
+			"headerColor": {ConditionsEvaluated: 7, Branches: map[string]int64{"0": 3, "else": 2}},
+		}
+	}))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	observed := map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok, m.Name)
+		for _, p := range sum.DataPoints {
+			label := m.Name
+			if branch, ok := p.Attributes.Value("branch"); ok {
+				label += "/" + branch.AsString()
+			}
+			observed[label] = p.Value
+		}
+	}
+	require.Equal(t, map[string]int64{
+		"targeting_rule_branches/0":    3,
+		"targeting_rule_branches/else": 2,
+		"targeting_rule_conditions":    7,
+	}, observed)
+}
+
+func TestRegisterEvaluationAdmission(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	require.Nil(t, rec.RegisterEvaluationAdmission(func() int64 { return 3 }, func() int64 { return 2 }))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	observed := map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		require.True(t, ok, m.Name)
+		require.Len(t, gauge.DataPoints, 1)
+		observed[m.Name] = gauge.DataPoints[0].Value
+	}
+	require.Equal(t, map[string]int64{"evaluations_inflight": 3, "evaluations_queued": 2}, observed)
+}
+
+func TestRegisterDroppedEvaluationEvents(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName)
+	require.Nil(t, rec.RegisterDroppedEvaluationEvents(func() int64 { return 4 }, func() int64 { return 1 }))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 1)
+	sum, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	dropped := map[string]int64{}
+	for _, point := range sum.DataPoints {
+		reason, _ := point.Attributes.Value("reason")
+		dropped[reason.AsString()] = point.Value
+	}
+	require.Equal(t, map[string]int64{"overload": 4, "delivery": 1}, dropped)
+}
+
+// labelled renders the data points of a metric as their sorted labels, missing labels being omitted
+func labelled[N int64 | float64](points []metricdata.DataPoint[N]) map[string]N {
+	observed := map[string]N{}
+	for _, p := range points {
+		label := ""
+		for _, kv := range p.Attributes.ToSlice() {
+			label += string(kv.Key) + "=" + kv.Value.AsString() + ","
+		}
+		observed[label] = p.Value
+	}
+	return observed
+}
+
+func TestFlagEvaluation(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName, WithMetricLabels(MetricLabels{
+		DroppedFlagKeys: []string{"customer-1", "customer-2"},
+		MaxReasons:      2,
+	}))
+	rec.FlagEvaluation(context.TODO(), "headerColor", "TARGETING_MATCH")
+	rec.FlagEvaluation(context.TODO(), "headerColor", "DEFAULT")
+	rec.FlagEvaluation(context.TODO(), "headerColor", "ERROR")
+	rec.FlagEvaluation(context.TODO(), "customer-1", "DEFAULT")
+	rec.FlagEvaluation(context.TODO(), "customer-2", "DEFAULT")
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 1)
+	require.Equal(t, "flag_evaluations", data.ScopeMetrics[0].Metrics[0].Name)
+	sum, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Equal(t, map[string]int64{
+		"flag_key=headerColor,reason=TARGETING_MATCH,": 1,
+		"flag_key=headerColor,reason=DEFAULT,":         1,
+		// reasons beyond the cap are recorded as other
+		"flag_key=headerColor,reason=other,": 1,
+		// the dropped flags are aggregated without their flag key
+		"reason=DEFAULT,": 2,
+	}, labelled(sum.DataPoints))
+}
+
+func TestMetricLabels_DroppedFlagKeys(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, svcName, WithMetricLabels(MetricLabels{DroppedFlagKeys: []string{"a", "b"}}))
+	require.Nil(t, rec.RegisterVariantDistribution(func() map[string]map[string]int64 {
+		return map[string]map[string]int64{"headerColor": {"red": 3}, "a": {"on": 2, "off": 1}, "b": {"on": 4}}
+	}))
+	require.Nil(t, rec.RegisterRuleStatistics(func() map[string]RuleStatistics {
+		return map[string]RuleStatistics{
+			"headerColor": {ConditionsEvaluated: 7, Branches: map[string]int64{"0": 3}},
+			"a":           {ConditionsEvaluated: 2, Branches: map[string]int64{"0": 1, "else": 1}},
+			"b":           {ConditionsEvaluated: 1, Branches: map[string]int64{"0": 1}},
+		}
+	}))
+
+	data, err := exp.Collect(context.TODO())
+	require.Nil(t, err)
+	require.Len(t, data.ScopeMetrics, 1)
+	observed := map[string]map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch d := m.Data.(type) {
+		case metricdata.Gauge[int64]:
+			observed[m.Name] = labelled(d.DataPoints)
+		case metricdata.Sum[int64]:
+			observed[m.Name] = labelled(d.DataPoints)
+		}
+	}
+	require.Equal(t, map[string]map[string]int64{
+		"variant_distribution": {"flag_key=headerColor,variant=red,": 3, "variant=on,": 6, "variant=off,": 1},
+		"targeting_rule_branches": {
+			"branch=0,flag_key=headerColor,": 3, "branch=0,": 2, "branch=else,": 1,
+		},
+		"targeting_rule_conditions": {"flag_key=headerColor,": 7, "": 3},
+	}, observed)
+}
+
+func TestRegisterEvaluationDuration_Again(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	rec := NewOTelRecorder(metric.NewManualReader(), svcName)
+	require.Nil(t, rec.RegisterEvaluationDuration(registry))
+	require.Nil(t, rec.RegisterEvaluationDuration(registry), "registering again should be a no-op")
+	other := NewOTelRecorder(metric.NewManualReader(), svcName)
+	require.Nil(t, other.RegisterEvaluationDuration(registry),
+		"the histogram of another recorder should be reused")
+
+	rec.EvaluationDuration(context.TODO(), "ResolveBoolean", time.Millisecond)
+	other.EvaluationDuration(context.TODO(), "ResolveBoolean", time.Millisecond)
+	families, err := registry.Gather()
+	require.Nil(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, uint64(2), families[0].GetMetric()[0].GetHistogram().GetSampleCount())
+
+	require.NotNil(t, rec.RegisterEvaluationDuration(failingRegisterer{}))
+}
+
+// failingRegisterer is a prometheus.Registerer failing the registration of any collector
+type failingRegisterer struct {
+	prometheus.Registerer
+}
+
+func (failingRegisterer) Register(prometheus.Collector) error {
+	return errors.New("registration failed")
+}
@@ -0,0 +1,67 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+)
+
+// FlagSamplingRates returns the fraction of the evaluations of a flag which are traced, false if the flag has no rate
+type FlagSamplingRates func(flagKey string) (float64, bool)
+
+// NewEvaluationSampler samples the evaluations without a parent span by the rate of their flag, identified by the
+// feature_flag.key attribute of their span, and the other spans at the default rate. Spans with a parent, e.g.
+// propagated by the caller in the traceparent header, follow its sampling decision.
+func NewEvaluationSampler(rates FlagSamplingRates, defaultRate float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(evaluationSampler{rates: rates, defaultRate: defaultRate})
+}
+
+type evaluationSampler struct {
+	rates       FlagSamplingRates
+	defaultRate float64
+}
+
+func (s evaluationSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.TraceIDRatioBased(s.rate(p)).ShouldSample(p)
+}
+
+func (s evaluationSampler) Description() string {
+	return fmt.Sprintf("EvaluationSampler{default:%g}", s.defaultRate)
+}
+
+// rate is the sampling rate of the flag evaluated by the span, the default rate if the flag has none
+func (s evaluationSampler) rate(p sdktrace.SamplingParameters) float64 {
+	for _, attr := range p.Attributes {
+		if attr.Key != semconv.FeatureFlagKeyKey {
+			continue
+		}
+		if rate, ok := s.rates(attr.Value.AsString()); ok {
+			return rate
+		}
+		break
+	}
+	return s.defaultRate
+}
+
+// NewTracerProvider exports the sampled spans of the service to the OpenTelemetry collector listening at the gRPC
+// endpoint, e.g. localhost:4317
+func NewTracerProvider(
+	ctx context.Context, collectorURI string, svcName string, sampler sdktrace.Sampler,
+) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(collectorURI),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("trace exporter: %w", err)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(svcName))),
+	), nil
+}
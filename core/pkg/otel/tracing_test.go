@@ -0,0 +1,74 @@
+package otel
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEvaluationSampler_Rates(t *testing.T) {
+	rates := map[string]float64{"highVolumeFlag": 0.01, "rareFlag": 1, "quietFlag": 0}
+	sampler := NewEvaluationSampler(func(flagKey string) (float64, bool) {
+		rate, ok := rates[flagKey]
+		return rate, ok
+	}, 0.25)
+
+	const evaluations = 20000
+	tests := map[string]struct {
+		attrs []attribute.KeyValue
+		rate  float64
+	}{
+		"high volume flag":   {attrs: []attribute.KeyValue{semconv.FeatureFlagKey("highVolumeFlag")}, rate: 0.01},
+		"rare flag":          {attrs: []attribute.KeyValue{semconv.FeatureFlagKey("rareFlag")}, rate: 1},
+		"flag never sampled": {attrs: []attribute.KeyValue{semconv.FeatureFlagKey("quietFlag")}, rate: 0},
+		"flag without rate":  {attrs: []attribute.KeyValue{semconv.FeatureFlagKey("otherFlag")}, rate: 0.25},
+		"span without flag":  {rate: 0.25},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sampled := 0
+			for i := 0; i < evaluations; i++ {
+				// a Weyl sequence spreads the trace ids evenly, as the random ids of independent evaluations
+				var traceID trace.TraceID
+				binary.BigEndian.PutUint64(traceID[8:], uint64(i+1)*0x9e3779b97f4a7c15)
+				res := sampler.ShouldSample(sdktrace.SamplingParameters{
+					ParentContext: context.Background(), TraceID: traceID, Name: "ResolveBoolean", Attributes: tt.attrs,
+				})
+				if res.Decision == sdktrace.RecordAndSample {
+					sampled++
+				}
+			}
+			require.InDelta(t, tt.rate, float64(sampled)/evaluations, 0.005)
+		})
+	}
+}
+
+func TestEvaluationSampler_PropagatedDecision(t *testing.T) {
+	sampler := NewEvaluationSampler(func(flagKey string) (float64, bool) {
+		return map[string]float64{"quietFlag": 0, "rareFlag": 1}[flagKey], true
+	}, 1)
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35}
+	parent := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: trace.SpanID{0x00, 0xf0, 0x67}, TraceFlags: flags, Remote: true,
+		}))
+	}
+
+	res := sampler.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: parent(trace.FlagsSampled), TraceID: traceID,
+		Attributes: []attribute.KeyValue{semconv.FeatureFlagKey("quietFlag")},
+	})
+	require.Equal(t, sdktrace.RecordAndSample, res.Decision, "a sampled parent should sample the evaluation")
+
+	res = sampler.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: parent(0), TraceID: traceID,
+		Attributes: []attribute.KeyValue{semconv.FeatureFlagKey("rareFlag")},
+	})
+	require.Equal(t, sdktrace.Drop, res.Decision, "a parent which isn't sampled should drop the evaluation")
+}
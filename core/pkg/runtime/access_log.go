@@ -0,0 +1,50 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AccessLogFormat is the encoding of the lines of the access log
+type AccessLogFormat string
+
+const (
+	// AccessLogFormatJSON writes each line as a json object, the default
+	AccessLogFormatJSON AccessLogFormat = "json"
+	// AccessLogFormatConsole writes each line as tab separated values, followed by the fields as json
+	AccessLogFormatConsole AccessLogFormat = "console"
+)
+
+// ParseAccessLogFormat returns the access log format of its name, an empty name defaults to json
+func ParseAccessLogFormat(format string) (AccessLogFormat, error) {
+	switch AccessLogFormat(format) {
+	case "":
+		return AccessLogFormatJSON, nil
+	case AccessLogFormatJSON, AccessLogFormatConsole:
+		return AccessLogFormat(format), nil
+	default:
+		return "", fmt.Errorf("unknown access log format: '%s', expected '%s' or '%s'",
+			format, AccessLogFormatJSON, AccessLogFormatConsole)
+	}
+}
+
+// newAccessLogger returns the logger of the access log, nil unless the access log is enabled. It's built apart from
+// the logger of flagd, so its format and level don't depend on the other logs.
+func newAccessLogger(config Config) (*logger.Logger, error) {
+	if !config.AccessLog {
+		return nil, nil
+	}
+	format, err := ParseAccessLogFormat(config.AccessLogFormat)
+	if err != nil {
+		return nil, err
+	}
+	zapLogger, err := logger.NewZapLogger(zapcore.InfoLevel, string(format))
+	if err != nil {
+		return nil, fmt.Errorf("access logger: %w", err)
+	}
+	return logger.NewLogger(zapLogger.WithOptions(zap.WithCaller(false)), false).
+		WithFields(zap.String("component", "accesslog")), nil
+}
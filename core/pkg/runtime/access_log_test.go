@@ -0,0 +1,30 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccessLogFormat(t *testing.T) {
+	format, err := ParseAccessLogFormat("")
+	require.Nil(t, err)
+	require.Equal(t, AccessLogFormatJSON, format)
+	format, err = ParseAccessLogFormat("console")
+	require.Nil(t, err)
+	require.Equal(t, AccessLogFormatConsole, format)
+	_, err = ParseAccessLogFormat("clf")
+	require.EqualError(t, err, "unknown access log format: 'clf', expected 'json' or 'console'")
+}
+
+func TestNewAccessLogger(t *testing.T) {
+	accessLogger, err := newAccessLogger(Config{AccessLogFormat: "clf"})
+	require.Nil(t, err)
+	require.Nil(t, accessLogger, "requests shouldn't be logged unless the access log is enabled")
+
+	accessLogger, err = newAccessLogger(Config{AccessLog: true, AccessLogFormat: "console"})
+	require.Nil(t, err)
+	require.NotNil(t, accessLogger)
+	_, err = newAccessLogger(Config{AccessLog: true, AccessLogFormat: "clf"})
+	require.NotNil(t, err)
+}
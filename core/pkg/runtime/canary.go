@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// updateCandidateWithNotify updates the candidate configuration of the canary rollout and notifies listeners
+func (r *Runtime) updateCandidateWithNotify(payload sync.DataSync) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notifications, resyncRequired, err := r.Canary.SetCandidateState(payload)
+	if err != nil {
+		r.Logger.Error(fmt.Sprintf("candidate configuration: %v", err))
+		r.recordSync(payload.Source, err)
+		return false
+	}
+	r.recordSync(payload.Source, nil)
+	r.markStarted(payload.Source)
+	r.markConfigLoaded()
+
+	r.Service.Notify(service.Notification{
+		Type: service.ConfigurationChange,
+		Data: map[string]interface{}{
+			"flags": notifications,
+		},
+	})
+
+	return resyncRequired
+}
+
+// startCanaryPromotion promotes the candidate configuration once the soak period elapses, or on the promotion signal
+func (r *Runtime) startCanaryPromotion(ctx context.Context) {
+	var soak <-chan time.Time
+	if r.config.CanarySoakPeriod > 0 {
+		timer := time.NewTimer(r.config.CanarySoakPeriod)
+		soak = timer.C
+		go func() {
+			<-ctx.Done()
+			timer.Stop()
+		}()
+	}
+	promote := make(chan os.Signal, 1)
+	if len(promoteSignals) > 0 {
+		signal.Notify(promote, promoteSignals...)
+	}
+
+	go func() {
+		defer signal.Stop(promote)
+		select {
+		case <-soak:
+			r.Logger.Info("canary soak period elapsed")
+		case sig := <-promote:
+			r.Logger.Info(fmt.Sprintf("received %s", sig))
+		case <-ctx.Done():
+			return
+		}
+		r.Canary.Promote()
+	}()
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package runtime
+
+import (
+	"os"
+	"syscall"
+)
+
+// promoteSignals promote the candidate configuration of a canary rollout
+var promoteSignals = []os.Signal{syscall.SIGUSR1}
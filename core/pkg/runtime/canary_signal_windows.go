@@ -0,0 +1,8 @@
+//go:build windows
+
+package runtime
+
+import "os"
+
+// promoteSignals promote the candidate configuration of a canary rollout, windows has no user defined signals
+var promoteSignals = []os.Signal{}
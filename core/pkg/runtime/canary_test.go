@@ -0,0 +1,29 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryPromotedAfterSoakPeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := Runtime{
+		config: Config{CanarySoakPeriod: 10 * time.Millisecond},
+		Logger: logger.NewLogger(nil, false),
+		Canary: eval.NewCanaryEvaluator(
+			nil, eval.NewJSONEvaluator(nil, store.NewFlags()), eval.NewJSONEvaluator(nil, store.NewFlags()), 10, nil,
+		),
+	}
+
+	r.startCanaryPromotion(ctx)
+	require.Eventually(t, func() bool {
+		return r.Canary.Percentage() == 100
+	}, time.Second, 5*time.Millisecond)
+}
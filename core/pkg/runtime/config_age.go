@@ -0,0 +1,28 @@
+package runtime
+
+import (
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+)
+
+// markConfigLoaded records the reload of a configuration served, from any source, candidate and tenant sources
+// included
+func (r *Runtime) markConfigLoaded() {
+	r.configLoaded.Store(time.Now().UnixNano())
+}
+
+// configLoadedProbe returns the time the served configuration was last reloaded, nil unless the configuration age is
+// added to the resolutions
+func (r *Runtime) configLoadedProbe() service.ConfigLoadedProbe {
+	if !r.config.ConfigAgeMetadata {
+		return nil
+	}
+	return func() time.Time {
+		loaded := r.configLoaded.Load()
+		if loaded == 0 {
+			return time.Time{}
+		}
+		return time.Unix(0, loaded)
+	}
+}
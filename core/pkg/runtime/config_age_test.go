@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigLoadedProbe(t *testing.T) {
+	r := Runtime{
+		config:    Config{ConfigAgeMetadata: true},
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{&chanSync{}},
+	}
+	loaded := r.configLoadedProbe()
+	require.NotNil(t, loaded)
+	require.True(t, loaded().IsZero(), "no configuration was loaded yet")
+
+	before := time.Now()
+	r.updateWithNotify(sync.DataSync{
+		Source: "flags.json", Type: sync.ALL, FlagData: fmt.Sprintf(freezeFlagConfig, "red"),
+	})
+	first := loaded()
+	require.False(t, first.Before(before), "the load time should be the last reload")
+
+	r.updateWithNotify(sync.DataSync{Source: "flags.json", Type: sync.ALL, FlagData: `{"flags": `})
+	require.Equal(t, first, loaded(), "failed reloads shouldn't change the load time")
+
+	time.Sleep(time.Millisecond)
+	r.updateWithNotify(sync.DataSync{
+		Source: "flags.json", Type: sync.ALL, FlagData: fmt.Sprintf(freezeFlagConfig, "blue"),
+	})
+	require.True(t, loaded().After(first), "the load time should follow the last reload")
+
+	r.config.ConfigAgeMetadata = false
+	require.Nil(t, r.configLoadedProbe(), "the age shouldn't be added unless enabled")
+}
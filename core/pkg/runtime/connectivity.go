@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// disconnected reports whether a remote source has been unreachable for longer than the disconnect threshold, in
+// which case flagd isn't ready even though it keeps serving the last configuration of the source. Transitions are
+// logged.
+func (r *Runtime) disconnected(syncImpl []sync.ISync) bool {
+	threshold := r.config.SourceDisconnectThreshold
+	if threshold <= 0 {
+		return false
+	}
+	disconnected := disconnectedSources(syncImpl, threshold)
+	degraded := disconnected > 0
+	if r.degraded.Swap(degraded) != degraded {
+		if degraded {
+			r.Logger.Warn(fmt.Sprintf("%d remote flag source(s) unreachable for longer than %s, reporting not ready",
+				disconnected, threshold))
+		} else {
+			r.Logger.Info("remote flag sources reachable again, reporting ready")
+		}
+	}
+	return degraded
+}
+
+// staleProbe reports the configuration as stale while a remote source has been unreachable for longer than the
+// stale threshold, nil if the threshold isn't set
+func (r *Runtime) staleProbe() service.StaleProbe {
+	threshold := r.config.StaleThreshold
+	if threshold <= 0 {
+		return nil
+	}
+	return func() bool {
+		return disconnectedSources(r.syncImpls(), threshold) > 0
+	}
+}
+
+// disconnectedSources counts the remote sources which have been unreachable for longer than the threshold
+func disconnectedSources(syncImpl []sync.ISync, threshold time.Duration) int {
+	disconnected := 0
+	for _, p := range syncImpl {
+		c, ok := p.(sync.Connectivity)
+		if !ok {
+			continue
+		}
+		if since := c.DisconnectedSince(); !since.IsZero() && time.Since(since) > threshold {
+			disconnected++
+		}
+	}
+	return disconnected
+}
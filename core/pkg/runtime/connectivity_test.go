@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// remoteSync is a ready sync provider reporting the connectivity of a remote source
+type remoteSync struct {
+	chanSync
+	disconnectedSince time.Time
+}
+
+func (r *remoteSync) DisconnectedSince() time.Time { return r.disconnectedSince }
+
+func TestReadinessOnDisconnectedSources(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	remote := &remoteSync{}
+	r := Runtime{
+		config:   Config{SourceDisconnectThreshold: time.Minute},
+		Logger:   logger.NewLogger(zap.New(core), false),
+		SyncImpl: []sync.ISync{&chanSync{}, remote},
+	}
+	require.True(t, r.isReady(), "connected sources should be ready")
+
+	remote.disconnectedSince = time.Now().Add(-30 * time.Second)
+	require.True(t, r.isReady(), "sources disconnected within the threshold should be ready")
+	require.Zero(t, logs.Len())
+
+	remote.disconnectedSince = time.Now().Add(-2 * time.Minute)
+	require.False(t, r.isReady(), "sources disconnected beyond the threshold shouldn't be ready")
+	require.False(t, r.isReady())
+	require.Equal(t, 1, logs.FilterMessage(
+		"1 remote flag source(s) unreachable for longer than 1m0s, reporting not ready").Len(),
+		"the transition should be logged once")
+
+	remote.disconnectedSince = time.Time{}
+	require.True(t, r.isReady(), "reconnected sources should be ready")
+	require.Equal(t, 1, logs.FilterMessage("remote flag sources reachable again, reporting ready").Len())
+}
+
+func TestReadinessOnDisconnectedSources_Disabled(t *testing.T) {
+	r := Runtime{
+		Logger:   logger.NewLogger(nil, false),
+		SyncImpl: []sync.ISync{&remoteSync{disconnectedSince: time.Now().Add(-time.Hour)}},
+	}
+	require.True(t, r.isReady(), "stale configurations should be served while ready without threshold")
+}
+
+func TestStaleProbe(t *testing.T) {
+	remote := &remoteSync{}
+	r := Runtime{
+		config:   Config{StaleThreshold: time.Minute},
+		Logger:   logger.NewLogger(nil, false),
+		SyncImpl: []sync.ISync{&chanSync{}, remote},
+	}
+	stale := r.staleProbe()
+	require.NotNil(t, stale)
+	require.False(t, stale(), "connected sources should be fresh")
+
+	remote.disconnectedSince = time.Now().Add(-30 * time.Second)
+	require.False(t, stale(), "sources disconnected within the threshold should be fresh")
+
+	remote.disconnectedSince = time.Now().Add(-2 * time.Minute)
+	require.True(t, stale(), "sources disconnected beyond the threshold should be stale")
+	require.True(t, r.isReady(), "stale configurations should be served while ready")
+
+	remote.disconnectedSince = time.Time{}
+	require.False(t, stale(), "reconnected sources should be fresh")
+
+	r.config.StaleThreshold = 0
+	require.Nil(t, r.staleProbe(), "resolutions shouldn't be marked without threshold")
+}
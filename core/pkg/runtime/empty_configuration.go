@@ -0,0 +1,53 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// EmptyConfiguration defines how flagd starts when the initial configuration of its sources holds no flags
+type EmptyConfiguration string
+
+const (
+	// EmptyConfigurationServe reports flagd as ready without flags, so flags can be added once it runs, the default
+	EmptyConfigurationServe EmptyConfiguration = "serve"
+	// EmptyConfigurationNotReady reports flagd as not ready until a configuration holding a flag is loaded
+	EmptyConfigurationNotReady EmptyConfiguration = "not-ready"
+	// EmptyConfigurationFail stops flagd with an error once every source applied an initial configuration without a
+	// flag, as an empty configuration is a misconfiguration
+	EmptyConfigurationFail EmptyConfiguration = "fail"
+)
+
+// ParseEmptyConfiguration returns the empty configuration policy of its name, an empty name defaults to serve
+func ParseEmptyConfiguration(policy string) (EmptyConfiguration, error) {
+	switch EmptyConfiguration(policy) {
+	case "":
+		return EmptyConfigurationServe, nil
+	case EmptyConfigurationServe, EmptyConfigurationNotReady, EmptyConfigurationFail:
+		return EmptyConfiguration(policy), nil
+	default:
+		return "", fmt.Errorf("unknown empty configuration policy: '%s', expected '%s', '%s' or '%s'",
+			policy, EmptyConfigurationServe, EmptyConfigurationNotReady, EmptyConfigurationFail)
+	}
+}
+
+// markFlagsLoaded records whether the applied configuration holds a flag, flagd stays ready once a flag was loaded
+// even if the flags are removed later. The caller must hold r.mu.
+func (r *Runtime) markFlagsLoaded() {
+	if r.emptyConfiguration == EmptyConfigurationNotReady && !r.flagsLoaded.Load() && r.flagCount() > 0 {
+		r.flagsLoaded.Store(true)
+	}
+}
+
+// awaitFlags returns an error if every source applied its initial configuration without a flag being loaded
+func (r *Runtime) awaitFlags(ctx context.Context) error {
+	if !r.awaitStartup(ctx) {
+		return nil
+	}
+	if r.flagCount() == 0 {
+		return errors.New("the initial configuration of the flag sources holds no flag, " +
+			"start flagd with --empty-configuration serve to serve an empty configuration")
+	}
+	return nil
+}
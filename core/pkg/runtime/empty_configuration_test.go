@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmptyConfiguration(t *testing.T) {
+	policy, err := ParseEmptyConfiguration("")
+	require.Nil(t, err)
+	require.Equal(t, EmptyConfigurationServe, policy, "empty configurations should be served by default")
+
+	policy, err = ParseEmptyConfiguration("not-ready")
+	require.Nil(t, err)
+	require.Equal(t, EmptyConfigurationNotReady, policy)
+
+	_, err = ParseEmptyConfiguration("ignore")
+	require.NotNil(t, err)
+}
+
+func TestEmptyConfiguration_Readiness(t *testing.T) {
+	tests := map[string]struct {
+		policy    EmptyConfiguration
+		wantReady bool
+	}{
+		"unset":     {wantReady: true},
+		"serve":     {policy: EmptyConfigurationServe, wantReady: true},
+		"not-ready": {policy: EmptyConfigurationNotReady},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Runtime{
+				Logger:             logger.NewLogger(nil, false),
+				Evaluator:          eval.NewJSONEvaluator(nil, store.NewFlags()),
+				Service:            noopService{},
+				SyncImpl:           []sync.ISync{&chanSync{}},
+				emptyConfiguration: tt.policy,
+			}
+			r.updateWithNotify(sync.DataSync{Source: "flags.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+			require.Equal(t, tt.wantReady, r.isReady())
+
+			r.updateWithNotify(sync.DataSync{
+				Source: "flags.json", Type: sync.ALL, FlagData: fmt.Sprintf(freezeFlagConfig, "red"),
+			})
+			require.True(t, r.isReady(), "flagd should be ready once a flag is loaded")
+
+			r.updateWithNotify(sync.DataSync{Source: "flags.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+			require.True(t, r.isReady(), "flagd should stay ready once a flag was loaded")
+		})
+	}
+}
+
+func TestEmptyConfiguration_Fail(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		wantErr bool
+	}{
+		"without flags": {config: `{"flags": {}}`, wantErr: true},
+		"with flags":    {config: fmt.Sprintf(freezeFlagConfig, "red")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			source := &chanSync{updates: make(chan sync.DataSync)}
+			r := Runtime{
+				Logger:             logger.NewLogger(nil, false),
+				Evaluator:          eval.NewJSONEvaluator(nil, store.NewFlags()),
+				Service:            noopService{},
+				SyncImpl:           []sync.ISync{source},
+				emptyConfiguration: EmptyConfigurationFail,
+			}
+			stopped := make(chan error)
+			go func() { stopped <- r.run(ctx) }()
+
+			source.updates <- sync.DataSync{Source: "flags.json", Type: sync.ALL, FlagData: tt.config}
+			if tt.wantErr {
+				select {
+				case err := <-stopped:
+					require.NotNil(t, err, "flagd should fail without flags")
+				case <-time.After(time.Second):
+					t.Fatal("flagd should stop once the startup completes without flags")
+				}
+				return
+			}
+			require.Eventually(t, r.startupComplete, time.Second, 10*time.Millisecond)
+			select {
+			case err := <-stopped:
+				t.Fatalf("flagd shouldn't stop with flags: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			cancel()
+			require.Nil(t, <-stopped)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// evaluatorSwap is the evaluator swapped in once every source resynced its configuration to it
+type evaluatorSwap struct {
+	next eval.IEvaluator
+	// pending are the sources which haven't resynced their whole configuration to next yet
+	pending map[string]struct{}
+	// notifications are the changes of the flags of next, notified once it's swapped in
+	notifications map[string]interface{}
+}
+
+// evaluator returns the evaluator the sources are synced to, the evaluator swapped in by SwapEvaluator if any
+func (r *Runtime) evaluator() eval.IEvaluator {
+	if swapped := r.swapped.Load(); swapped != nil {
+		return *swapped
+	}
+	return r.Evaluator
+}
+
+// SwapEvaluator switches the evaluations to another evaluator instance, e.g. one with a different rule engine,
+// without restarting. Sync updates are applied to both evaluators while the sources resync their whole configuration
+// to the new evaluator, which serves the evaluations once every source resynced, evaluations in flight finishing on
+// the previous evaluator. The swap is pending until then, e.g. while the configuration is frozen.
+func (r *Runtime) SwapEvaluator(next eval.IEvaluator) error {
+	if next == nil {
+		return errors.New("no evaluator set")
+	}
+	if r.Canary != nil || r.Tenants != nil {
+		return errors.New("the evaluator can't be swapped while a canary configuration or tenants are configured")
+	}
+	if _, ok := r.Service.(service.EvaluatorSwapper); !ok {
+		return errors.New("the service doesn't support swapping its evaluator")
+	}
+
+	r.mu.Lock()
+	if r.swap != nil {
+		r.mu.Unlock()
+		return errors.New("an evaluator swap is pending")
+	}
+	if len(r.syncedSources) == 0 {
+		defer r.mu.Unlock()
+		r.completeSwap(next, nil)
+		return nil
+	}
+	r.swap = &evaluatorSwap{
+		next:          next,
+		pending:       make(map[string]struct{}, len(r.syncedSources)),
+		notifications: map[string]interface{}{},
+	}
+	for source := range r.syncedSources {
+		r.swap.pending[source] = struct{}{}
+	}
+	r.audit().Info(fmt.Sprintf("swapping the evaluator once %d sources resync", len(r.swap.pending)))
+	r.mu.Unlock()
+
+	r.resync()
+	return nil
+}
+
+// applyToSwap applies the sync update to the evaluator being swapped in, swapping it in once every source resynced
+// its whole configuration. The caller must hold r.mu.
+func (r *Runtime) applyToSwap(payload sync.DataSync) {
+	if r.swap == nil {
+		return
+	}
+	notifications, _, err := r.swap.next.SetState(payload)
+	if err != nil {
+		r.audit().Error(fmt.Sprintf("evaluator swap aborted, the update of %s failed: %v", payload.Source, err))
+		r.swap = nil
+		return
+	}
+	for key, notification := range notifications {
+		r.swap.notifications[key] = notification
+	}
+	if payload.Type == sync.ALL {
+		delete(r.swap.pending, payload.Source)
+	}
+	if len(r.swap.pending) == 0 {
+		r.completeSwap(r.swap.next, r.swap.notifications)
+		r.swap = nil
+	}
+}
+
+// completeSwap syncs the sources to the evaluator and serves the evaluations with it. The caller must hold r.mu.
+func (r *Runtime) completeSwap(next eval.IEvaluator, notifications map[string]interface{}) {
+	r.swapped.Store(&next)
+	if swapper, ok := r.Service.(service.EvaluatorSwapper); ok {
+		swapper.SwapEvaluator(next)
+	}
+	r.audit().Info("evaluator swapped")
+	if len(notifications) == 0 {
+		return
+	}
+	r.Service.Notify(service.Notification{
+		Type: service.ConfigurationChange,
+		Data: map[string]interface{}{
+			"flags": notifications,
+		},
+	})
+}
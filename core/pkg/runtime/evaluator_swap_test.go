@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"context"
+	msync "sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// swappingService records the evaluators swapped in and the notifications
+type swappingService struct {
+	noopService
+	mu            msync.Mutex
+	evaluator     eval.IEvaluator
+	notifications []service.Notification
+}
+
+func (s *swappingService) SwapEvaluator(next eval.IEvaluator) eval.IEvaluator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.evaluator
+	s.evaluator = next
+	return previous
+}
+
+func (s *swappingService) Notify(n service.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, n)
+}
+
+func (s *swappingService) current() eval.IEvaluator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evaluator
+}
+
+func resolvedColor(t *testing.T, evaluator eval.IEvaluator) string {
+	t.Helper()
+	value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "color", nil)
+	require.Nil(t, err)
+	return value
+}
+
+func TestSwapEvaluator(t *testing.T) {
+	svc := &swappingService{}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   svc,
+		SyncImpl:  []sync.ISync{&chanSync{}, &chanSync{}},
+	}
+	config := func(variant string) string {
+		return `{"flags": {"color": {"state": "ENABLED", "variants": {"red": "red", "blue": "blue"}, ` +
+			`"defaultVariant": "` + variant + `"}}}`
+	}
+	r.updateWithNotify(sync.DataSync{FlagData: config("red"), Source: "file:a.json", Type: sync.ALL})
+	r.updateWithNotify(sync.DataSync{FlagData: `{"flags": {}}`, Source: "file:b.json", Type: sync.ALL})
+
+	next := eval.NewJSONEvaluator(nil, store.NewFlags())
+	require.Nil(t, r.SwapEvaluator(next))
+	require.EqualError(t, r.SwapEvaluator(eval.NewJSONEvaluator(nil, store.NewFlags())),
+		"an evaluator swap is pending")
+
+	r.updateWithNotify(sync.DataSync{FlagData: config("blue"), Source: "file:a.json", Type: sync.ALL})
+	require.Nil(t, svc.current(), "the evaluator should be swapped once every source resynced")
+	require.Equal(t, "blue", resolvedColor(t, r.Evaluator), "the previous evaluator should be synced until swapped")
+
+	svc.notifications = nil
+	r.updateWithNotify(sync.DataSync{FlagData: `{"flags": {}}`, Source: "file:b.json", Type: sync.ALL})
+	require.Same(t, next, svc.current())
+	require.Same(t, next, r.evaluator())
+	require.Equal(t, "blue", resolvedColor(t, next))
+	require.Len(t, svc.notifications, 2, "the flags of the evaluator swapped in should be notified")
+
+	r.updateWithNotify(sync.DataSync{FlagData: config("red"), Source: "file:a.json", Type: sync.ALL})
+	require.Equal(t, "red", resolvedColor(t, next), "the sources should be synced to the evaluator swapped in")
+	require.Equal(t, "blue", resolvedColor(t, r.Evaluator))
+}
+
+func TestSwapEvaluator_BeforeSync(t *testing.T) {
+	svc := &swappingService{}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   svc,
+		SyncImpl:  []sync.ISync{&chanSync{}},
+	}
+	next := eval.NewJSONEvaluator(nil, store.NewFlags())
+	require.Nil(t, r.SwapEvaluator(next))
+	require.Same(t, next, svc.current(), "the evaluator should be swapped at once without configuration to resync")
+}
+
+func TestSwapEvaluator_Aborted(t *testing.T) {
+	svc := &swappingService{}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   svc,
+		SyncImpl:  []sync.ISync{&chanSync{}},
+	}
+	r.updateWithNotify(sync.DataSync{FlagData: `{"flags": {}}`, Source: "file:a.json", Type: sync.ALL})
+
+	allowed, err := eval.ParseFlagKeyCharacters(`[a-z]`)
+	require.Nil(t, err)
+	strict := eval.NewJSONEvaluator(nil, store.NewFlags(), eval.WithFlagKeyCharacters(allowed, eval.InvalidFlagKeysError))
+	require.Nil(t, r.SwapEvaluator(strict))
+	r.updateWithNotify(sync.DataSync{
+		FlagData: `{"flags": {"dark-mode": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`,
+		Source:   "file:a.json",
+		Type:     sync.ALL,
+	})
+	require.Nil(t, svc.current(), "the swap should be aborted once an update fails on the evaluator")
+	require.Same(t, r.Evaluator, r.evaluator())
+	require.Nil(t, r.SwapEvaluator(eval.NewJSONEvaluator(nil, store.NewFlags())))
+}
+
+func TestSwapEvaluator_Unsupported(t *testing.T) {
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+	}
+	require.EqualError(t, r.SwapEvaluator(nil), "no evaluator set")
+	require.EqualError(t, r.SwapEvaluator(eval.NewJSONEvaluator(nil, store.NewFlags())),
+		"the service doesn't support swapping its evaluator")
+
+	r.Service = &swappingService{}
+	r.Canary = eval.NewCanaryEvaluator(nil, r.Evaluator, eval.NewJSONEvaluator(nil, store.NewFlags()), 10, nil)
+	require.EqualError(t, r.SwapEvaluator(eval.NewJSONEvaluator(nil, store.NewFlags())),
+		"the evaluator can't be swapped while a canary configuration or tenants are configured")
+}
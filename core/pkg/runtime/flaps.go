@@ -0,0 +1,50 @@
+package runtime
+
+import (
+	"context"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// flapReleases replays the latest update of the sources while the evaluator holds flapping flags, so their latest
+// definition is applied once they stabilize even if their source doesn't send another update
+type flapReleases struct {
+	mu     msync.Mutex
+	timers map[flapSource]*time.Timer
+}
+
+type flapSource struct {
+	source   string
+	dataSync chan<- sync.DataSync
+}
+
+// scheduleFlapRelease replays the update of the source a flap window after it was applied, if the evaluator holds
+// flapping flags. Later updates of the source replace the scheduled replay.
+func (r *Runtime) scheduleFlapRelease(ctx context.Context, payload sync.DataSync, dataSync chan<- sync.DataSync) {
+	detector, ok := r.evaluator().(eval.FlapDetection)
+	if !ok || r.config.FlapWindow <= 0 || payload.Type == sync.DELETE {
+		return
+	}
+	key := flapSource{source: payload.Source, dataSync: dataSync}
+	r.flaps.mu.Lock()
+	defer r.flaps.mu.Unlock()
+	if timer, ok := r.flaps.timers[key]; ok {
+		timer.Stop()
+		delete(r.flaps.timers, key)
+	}
+	if len(detector.HeldFlags()) == 0 {
+		return
+	}
+	if r.flaps.timers == nil {
+		r.flaps.timers = map[flapSource]*time.Timer{}
+	}
+	r.flaps.timers[key] = time.AfterFunc(r.config.FlapWindow, func() {
+		select {
+		case dataSync <- payload:
+		case <-ctx.Done():
+		}
+	})
+}
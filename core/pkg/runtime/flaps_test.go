@@ -0,0 +1,56 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestFlapRelease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const window = 100 * time.Millisecond
+	source := &chanSync{updates: make(chan sync.DataSync)}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags(), eval.WithFlapDetection(1, window)),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{source},
+		config:    Config{FlapThreshold: 1, FlapWindow: window},
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	require.Nil(t, r.startSyncs(gCtx, g, r.SyncImpl, r.updateWithNotify))
+
+	send := func(variant string) {
+		source.updates <- sync.DataSync{
+			FlagData: fmt.Sprintf(freezeFlagConfig, variant), Source: "flags.json", Type: sync.ALL,
+		}
+	}
+	headerColor := func() string {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		value, _, _, _, _ := r.Evaluator.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+		return value
+	}
+
+	send("red")
+	send("blue")
+	send("green")
+	require.Eventually(t, func() bool {
+		return len(r.Evaluator.(eval.FlapDetection).HeldFlags()) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, "#0000FF", headerColor(), "the flapping flag must hold its last value")
+
+	require.Eventually(t, func() bool { return headerColor() == "#00FF00" }, time.Second, 5*time.Millisecond,
+		"the latest definition must be applied once stable, without another update of the source")
+	require.Empty(t, r.Evaluator.(eval.FlapDetection).HeldFlags())
+}
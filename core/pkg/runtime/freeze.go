@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	msync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// configFreeze holds the sync updates received while the flag configuration is frozen, they are applied once it's
+// unfrozen
+type configFreeze struct {
+	mu      msync.Mutex
+	frozen  bool
+	pending []heldUpdate
+	// replays are the held updates each sync loop applies before any other update once unfrozen, wakeups wake the
+	// loops to apply them
+	replays map[chan<- sync.DataSync][]sync.DataSync
+	wakeups map[chan<- sync.DataSync]chan struct{}
+}
+
+type heldUpdate struct {
+	payload  sync.DataSync
+	dataSync chan<- sync.DataSync
+}
+
+// Freeze freezes the flag configuration, evaluations are served by the current configuration and sync updates are
+// held until Unfreeze
+func (r *Runtime) Freeze() {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	if r.freeze.frozen {
+		return
+	}
+	r.freeze.frozen = true
+	r.audit().Warn("flag configuration frozen, sync updates are held until it's unfrozen")
+}
+
+// Unfreeze applies the sync updates held while the flag configuration was frozen, in the order they were received.
+// Each sync loop applies its held updates before any update it receives after unfreezing.
+func (r *Runtime) Unfreeze() {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	if !r.freeze.frozen {
+		return
+	}
+	r.freeze.frozen = false
+	pending := r.freeze.pending
+	r.freeze.pending = nil
+	r.audit().Warn(fmt.Sprintf("flag configuration unfrozen, applying %d held sync updates", len(pending)))
+	if r.freeze.replays == nil {
+		r.freeze.replays = map[chan<- sync.DataSync][]sync.DataSync{}
+	}
+	for _, held := range pending {
+		r.freeze.replays[held.dataSync] = append(r.freeze.replays[held.dataSync], held.payload)
+		select {
+		case r.freeze.wakeups[held.dataSync] <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Frozen reports whether the flag configuration is frozen
+func (r *Runtime) Frozen() bool {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	return r.freeze.frozen
+}
+
+// hold holds the sync update while the flag configuration is frozen, to be replayed by the sync loop of dataSync once
+// unfrozen. Updates of a source replaced by a later update of its whole configuration are dropped.
+func (r *Runtime) hold(payload sync.DataSync, dataSync chan<- sync.DataSync) bool {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	if !r.freeze.frozen {
+		return false
+	}
+	if payload.Type == sync.ALL {
+		pending := r.freeze.pending[:0]
+		for _, held := range r.freeze.pending {
+			if held.payload.Source != payload.Source || held.dataSync != dataSync {
+				pending = append(pending, held)
+			}
+		}
+		r.freeze.pending = pending
+	}
+	r.freeze.pending = append(r.freeze.pending, heldUpdate{payload: payload, dataSync: dataSync})
+	r.Logger.Info(fmt.Sprintf("flag configuration is frozen, holding the update of source: %s", payload.Source))
+	return true
+}
+
+// replayWakeup returns the channel waking the sync loop of dataSync when its held updates are to be replayed
+func (r *Runtime) replayWakeup(dataSync chan<- sync.DataSync) <-chan struct{} {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	if r.freeze.wakeups == nil {
+		r.freeze.wakeups = map[chan<- sync.DataSync]chan struct{}{}
+	}
+	wakeup := make(chan struct{}, 1)
+	r.freeze.wakeups[dataSync] = wakeup
+	return wakeup
+}
+
+// takeReplay returns the held updates the sync loop of dataSync is to replay since the configuration was unfrozen
+func (r *Runtime) takeReplay(dataSync chan<- sync.DataSync) []sync.DataSync {
+	r.freeze.mu.Lock()
+	defer r.freeze.mu.Unlock()
+	replay := r.freeze.replays[dataSync]
+	delete(r.freeze.replays, dataSync)
+	return replay
+}
+
+// startFreezeToggle freezes and unfreezes the flag configuration on the freeze signal
+func (r *Runtime) startFreezeToggle(ctx context.Context) {
+	if len(freezeSignals) == 0 {
+		return
+	}
+	toggle := make(chan os.Signal, 1)
+	signal.Notify(toggle, freezeSignals...)
+
+	go func() {
+		defer signal.Stop(toggle)
+		for {
+			select {
+			case sig := <-toggle:
+				r.Logger.Info(fmt.Sprintf("received %s", sig))
+				if r.Frozen() {
+					r.Unfreeze()
+				} else {
+					r.Freeze()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package runtime
+
+import (
+	"os"
+	"syscall"
+)
+
+// freezeSignals toggle the freeze of the flag configuration
+var freezeSignals = []os.Signal{syscall.SIGUSR2}
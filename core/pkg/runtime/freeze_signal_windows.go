@@ -0,0 +1,8 @@
+//go:build windows
+
+package runtime
+
+import "os"
+
+// freezeSignals toggle the freeze of the flag configuration, windows has no user defined signals
+var freezeSignals = []os.Signal{}
@@ -0,0 +1,157 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const freezeFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "%s"
+    }
+  }
+}`
+
+// chanSync forwards the updates sent to it to the runtime
+type chanSync struct {
+	updates chan sync.DataSync
+}
+
+func (c *chanSync) Init(context.Context) error { return nil }
+
+func (c *chanSync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	for {
+		select {
+		case update := <-c.updates:
+			dataSync <- update
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (c *chanSync) ReSync(context.Context, chan<- sync.DataSync) error { return nil }
+
+func (c *chanSync) IsReady() bool { return true }
+
+func TestFreeze(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	core, logs := observer.New(zap.InfoLevel)
+	source := &chanSync{updates: make(chan sync.DataSync)}
+	r := Runtime{
+		Logger:    logger.NewLogger(zap.New(core), false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{source},
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	require.Nil(t, r.startSyncs(gCtx, g, r.SyncImpl, r.updateWithNotify))
+
+	send := func(variant string) {
+		source.updates <- sync.DataSync{
+			FlagData: fmt.Sprintf(freezeFlagConfig, variant), Source: "flags.json", Type: sync.ALL,
+		}
+	}
+	headerColor := func() string {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		value, _, _, _, _ := r.Evaluator.ResolveStringValue(context.Background(), "", "headerColor", &structpb.Struct{})
+		return value
+	}
+	held := func() int {
+		return logs.FilterMessage("flag configuration is frozen, holding the update of source: flags.json").Len()
+	}
+
+	send("red")
+	require.Eventually(t, func() bool { return headerColor() == "#FF0000" }, time.Second, 5*time.Millisecond)
+
+	r.Freeze()
+	require.True(t, r.Frozen())
+	send("blue")
+	send("green")
+	require.Eventually(t, func() bool { return held() == 2 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, "#FF0000", headerColor(), "updates shouldn't be applied while frozen")
+
+	r.Unfreeze()
+	require.False(t, r.Frozen())
+	require.Equal(t, 1, logs.FilterMessage("flag configuration unfrozen, applying 1 held sync updates").Len(),
+		"updates superseded by a later update of the whole configuration shouldn't be held")
+	require.Eventually(t, func() bool { return headerColor() == "#00FF00" }, time.Second, 5*time.Millisecond)
+
+	send("blue")
+	require.Eventually(t, func() bool { return headerColor() == "#0000FF" }, time.Second, 5*time.Millisecond)
+	require.Equal(t, 2, held(), "updates should be applied once unfrozen")
+}
+
+func TestFreeze_HeldUpdatesOrder(t *testing.T) {
+	r := Runtime{Logger: logger.NewLogger(nil, false)}
+	dataSync := make(chan sync.DataSync, 4)
+	other := make(chan sync.DataSync, 4)
+
+	require.False(t, r.hold(sync.DataSync{Source: "a.json", Type: sync.ALL}, dataSync),
+		"updates shouldn't be held when the configuration isn't frozen")
+
+	r.Freeze()
+	for _, update := range []sync.DataSync{
+		{Source: "a.json", Type: sync.ADD, FlagData: "1"},
+		{Source: "b.json", Type: sync.ALL, FlagData: "2"},
+		{Source: "a.json", Type: sync.ALL, FlagData: "3"},
+		{Source: "a.json", Type: sync.UPDATE, FlagData: "4"},
+	} {
+		require.True(t, r.hold(update, dataSync))
+	}
+	require.True(t, r.hold(sync.DataSync{Source: "a.json", Type: sync.ALL, FlagData: "5"}, other),
+		"updates of other sync loops aren't superseded")
+	r.Unfreeze()
+
+	var replayed []string
+	for _, update := range r.takeReplay(dataSync) {
+		replayed = append(replayed, update.FlagData)
+	}
+	require.Equal(t, []string{"2", "3", "4"}, replayed)
+	require.Equal(t, []sync.DataSync{{Source: "a.json", Type: sync.ALL, FlagData: "5"}}, r.takeReplay(other))
+	require.Empty(t, r.takeReplay(dataSync), "held updates should be replayed once")
+}
+
+func TestFreeze_UpdateDuringUnfreeze(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &chanSync{updates: make(chan sync.DataSync)}
+	r := Runtime{Logger: logger.NewLogger(nil, false)}
+	applied := make(chan string, 4)
+	g, gCtx := errgroup.WithContext(ctx)
+	require.Nil(t, r.startSyncs(gCtx, g, []sync.ISync{source}, func(data sync.DataSync) bool {
+		applied <- data.FlagData
+		return false
+	}))
+
+	for i := 0; i < 100; i++ {
+		r.Freeze()
+		source.updates <- sync.DataSync{FlagData: "held", Source: "flags.json", Type: sync.ALL}
+		require.Eventually(t, func() bool {
+			r.freeze.mu.Lock()
+			defer r.freeze.mu.Unlock()
+			return len(r.freeze.pending) == 1
+		}, time.Second, time.Millisecond)
+		r.Unfreeze()
+		source.updates <- sync.DataSync{FlagData: "latest", Source: "flags.json", Type: sync.ALL}
+		require.Equal(t, "held", <-applied, "held updates should be applied before updates received after unfreezing")
+		require.Equal(t, "latest", <-applied)
+	}
+}
@@ -1,11 +1,14 @@
 package runtime
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"regexp"
+	"strings"
 	msync "sync"
 	"time"
 
@@ -15,117 +18,537 @@ import (
 	service "github.com/open-feature/flagd/core/pkg/service/flag-evaluation"
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/failover"
 	"github.com/open-feature/flagd/core/pkg/sync/file"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc"
 	httpSync "github.com/open-feature/flagd/core/pkg/sync/http"
 	"github.com/open-feature/flagd/core/pkg/sync/kubernetes"
+	"github.com/open-feature/flagd/core/pkg/sync/kv"
+	"github.com/open-feature/flagd/core/pkg/sync/oci"
+	"github.com/open-feature/flagd/core/pkg/sync/s3"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
+	"github.com/open-feature/flagd/core/pkg/sync/stdin"
 	"github.com/robfig/cron"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.uber.org/zap"
 )
 
 const (
+	syncProviderConsul     = "consul"
 	syncProviderFile       = "file"
 	syncProviderGrpc       = "grpc"
 	syncProviderKubernetes = "kubernetes"
 	syncProviderHTTP       = "http"
+	syncProviderOCI        = "oci"
+	syncProviderS3         = "s3"
+	syncProviderStdin      = "stdin"
 	svcName                = "openfeature/flagd"
+	// defaultSyncTimeout bounds the requests of remote sources, unless configured otherwise
+	defaultSyncTimeout = 10 * time.Second
 )
 
 var (
-	regCrd        *regexp.Regexp
-	regURL        *regexp.Regexp
-	regGRPC       *regexp.Regexp
-	regGRPCSecure *regexp.Regexp
-	regFile       *regexp.Regexp
+	regConsul       *regexp.Regexp
+	regConsulSecure *regexp.Regexp
+	regCrd          *regexp.Regexp
+	regURL          *regexp.Regexp
+	regOCI          *regexp.Regexp
+	regS3           *regexp.Regexp
+	regGRPC         *regexp.Regexp
+	regGRPCSecure   *regexp.Regexp
+	regFile         *regexp.Regexp
+	regStdin        *regexp.Regexp
 )
 
 func init() {
+	regConsul = regexp.MustCompile("^" + kv.ConsulPrefix)
+	regConsulSecure = regexp.MustCompile("^" + regexp.QuoteMeta(kv.ConsulSecurePrefix))
 	regCrd = regexp.MustCompile("^core.openfeature.dev/")
 	regURL = regexp.MustCompile("^https?://")
+	regOCI = regexp.MustCompile("^" + oci.Prefix)
+	regS3 = regexp.MustCompile("^" + s3.Prefix)
 	regGRPC = regexp.MustCompile("^" + grpc.Prefix)
 	regGRPCSecure = regexp.MustCompile("^" + grpc.PrefixSecure)
 	regFile = regexp.MustCompile("^file:")
+	regStdin = regexp.MustCompile("^" + stdin.URI + "$")
 }
 
 func FromConfig(logger *logger.Logger, config Config) (*Runtime, error) {
-	s := store.NewFlags()
+	if err := service.ValidateResolveTypes(config.DisabledResolveTypes); err != nil {
+		return nil, err
+	}
+	loggers, err := subsystemLoggers(logger, config.LogLevels)
+	if err != nil {
+		return nil, err
+	}
+	evalLogger := loggers[LogSubsystemEvaluation]
+	unknownReasons, err := service.ParseUnknownReasons(config.UnknownReasons)
+	if err != nil {
+		return nil, err
+	}
+	unsupportedContext, err := service.ParseUnsupportedContextValues(config.UnsupportedContextValues)
+	if err != nil {
+		return nil, err
+	}
+	oversizedContext, err := service.ParseOversizedContextValues(config.OversizedContextValues)
+	if err != nil {
+		return nil, err
+	}
+	emptyFlagKeys, err := service.ParseEmptyFlagKeys(config.EmptyFlagKeys)
+	if err != nil {
+		return nil, err
+	}
+	disabledFlags, err := service.ParseDisabledFlags(config.DisabledFlags)
+	if err != nil {
+		return nil, err
+	}
+	targetingKeyFallback, err := service.ParseTargetingKeyFallback(config.TargetingKeyFallback)
+	if err != nil {
+		return nil, err
+	}
+	readReplicaRPCs, err := service.ParseReadReplicaRPCs(config.ReadReplicaRPCs)
+	if err != nil {
+		return nil, err
+	}
+	traceSamplingRates, err := parseTraceSamplingRates(config.TraceSamplingRates)
+	if err != nil {
+		return nil, err
+	}
+	duplicateKeys, err := store.ParseDuplicateKeys(config.DuplicateFlagKeys)
+	if err != nil {
+		return nil, err
+	}
+	emptyConfiguration, err := ParseEmptyConfiguration(config.EmptyConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	accessLogger, err := newAccessLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	s := newFlagStore(config)
 	sources := []string{}
 	for _, sync := range config.SyncProviders {
 		sources = append(sources, sync.URI)
 	}
 	s.FlagSources = sources
+	s.DuplicateKeys = duplicateKeys
 	exporter, err := prometheus.New()
 	if err != nil {
 		return nil, err
 	}
+	keyNormalization, err := eval.ParseKeyNormalization(config.ContextKeyNormalization)
+	if err != nil {
+		return nil, err
+	}
+	largeIntegers, err := eval.ParseLargeIntegers(config.LargeIntegers)
+	if err != nil {
+		return nil, err
+	}
+	bucketingHash, err := eval.ParseBucketingHash(config.BucketingHash)
+	if err != nil {
+		return nil, err
+	}
+	schemaMismatch, err := eval.ParseSchemaMismatch(config.SchemaMismatch)
+	if err != nil {
+		return nil, err
+	}
+	variantTypeMismatch, err := eval.ParseVariantTypeMismatch(config.VariantTypeMismatch)
+	if err != nil {
+		return nil, err
+	}
+	flagKeyCharacters, err := eval.ParseFlagKeyCharacters(config.FlagKeyCharacters)
+	if err != nil {
+		return nil, err
+	}
+	invalidFlagKeys, err := eval.ParseInvalidFlagKeys(config.InvalidFlagKeys)
+	if err != nil {
+		return nil, err
+	}
+	templateMissingKeys, err := eval.ParseTemplateMissingKeys(config.TemplateMissingKeys)
+	if err != nil {
+		return nil, err
+	}
+	undefinedVariants, err := eval.ParseUndefinedVariants(config.UndefinedVariants)
+	if err != nil {
+		return nil, err
+	}
+	fractionalRemainder, err := eval.ParseFractionalRemainder(config.FractionalRemainder)
+	if err != nil {
+		return nil, err
+	}
+	contextCoercion, err := eval.ParseContextCoercion(config.ContextCoercion)
+	if err != nil {
+		return nil, err
+	}
+	fallbackChain, err := eval.ParseFallbackChain(config.FallbackChain)
+	if err != nil {
+		return nil, err
+	}
+	namespaceSeparator := ""
+	if config.NamespaceFallthrough {
+		if config.NamespaceSeparator == "" {
+			return nil, errors.New("namespace fallthrough requires a namespace separator")
+		}
+		namespaceSeparator = config.NamespaceSeparator
+	}
+	evalOpts := []eval.JSONEvaluatorOption{
+		eval.WithValidationWorkers(config.ValidationWorkers),
+		eval.WithDefaultVariantFallback(config.DefaultVariantFallback),
+		eval.WithContextKeyNormalization(keyNormalization),
+		eval.WithContextCoercion(contextCoercion),
+		eval.WithRuleWarmup(config.RuleWarmup),
+		eval.WithLargeIntegers(largeIntegers),
+		eval.WithBucketingHash(bucketingHash),
+		eval.WithSchemaMismatch(schemaMismatch),
+		eval.WithVariantTypeMismatch(variantTypeMismatch),
+		eval.WithFlagKeyCharacters(flagKeyCharacters, invalidFlagKeys),
+		eval.WithUndefinedVariants(undefinedVariants),
+		eval.WithFractionalRemainder(fractionalRemainder),
+		eval.WithTemplateMissingKeys(templateMissingKeys),
+		eval.WithEvaluationHash(config.EvaluationHash),
+		eval.WithContextKeysMetadata(config.ContextKeysMetadata),
+		eval.WithRuleStatistics(config.RuleStatistics),
+		eval.WithTargetingReordering(config.TargetingReordering),
+		eval.WithFlapDetection(config.FlapThreshold, config.FlapWindow),
+		eval.WithCircuitBreaker(config.CircuitBreakerFailures, config.CircuitBreakerSlow, config.CircuitBreakerCooldown),
+		eval.WithNamespaceFallthrough(namespaceSeparator),
+		eval.WithSourcePrefixes(sourcePrefixes(config)),
+		eval.WithMaxVariants(config.MaxVariants),
+		eval.WithPinnedFlags(config.PinnedFlags),
+		eval.WithAllowedContextKeys(config.AllowedContextKeys),
+		eval.WithOverrideTokenSecret(config.OverrideTokenSecret),
+		eval.WithNotFoundGracePeriod(config.NotFoundGracePeriod),
+		eval.WithFallbackChain(fallbackChain...),
+	}
+	metrics := otel.NewOTelRecorder(exporter, svcName, otel.WithMetricLabels(otel.MetricLabels{
+		DroppedFlagKeys: config.MetricsDroppedFlagKeys,
+		MaxReasons:      config.MetricsMaxReasons,
+	}))
 	rt := Runtime{
-		config:      config,
-		Logger:      logger.WithFields(zap.String("component", "runtime")),
-		Evaluator:   eval.NewJSONEvaluator(logger, s),
-		metrics:     otel.NewOTelRecorder(exporter, svcName),
-		serviceName: svcName,
+		config:             config,
+		Logger:             logger.WithFields(zap.String("component", "runtime")),
+		auditLogger:        loggers[LogSubsystemAudit].WithFields(zap.String("component", "runtime")),
+		Evaluator:          eval.NewJSONEvaluator(evalLogger, s, evalOpts...),
+		metrics:            metrics,
+		serviceName:        svcName,
+		emptyConfiguration: emptyConfiguration,
+	}
+	statusSources := append([]string{}, sources...)
+	for _, sync := range config.CanarySyncProviders {
+		statusSources = append(statusSources, sync.URI)
 	}
-	if err := rt.setSyncImplFromConfig(logger); err != nil {
+	for _, tenantSources := range config.TenantSyncProviders {
+		for _, sync := range tenantSources {
+			statusSources = append(statusSources, sync.URI)
+		}
+	}
+	rt.sourceStatuses = sync.NewSourceStatuses(statusSources...)
+	if err := rt.metrics.RegisterSourceStatuses(rt.sourceStatuses); err != nil {
 		return nil, err
 	}
-	rt.setService(logger)
+	if len(config.CanarySyncProviders) > 0 {
+		candidate := newFlagStore(config)
+		candidate.FlagSources = make([]string, 0, len(config.CanarySyncProviders))
+		for _, sync := range config.CanarySyncProviders {
+			candidate.FlagSources = append(candidate.FlagSources, sync.URI)
+		}
+		candidate.DuplicateKeys = duplicateKeys
+		rt.Canary = eval.NewCanaryEvaluator(
+			evalLogger,
+			rt.Evaluator,
+			eval.NewJSONEvaluator(evalLogger, candidate, evalOpts...),
+			config.CanaryPercentage,
+			rt.metrics,
+		)
+		rt.Evaluator = rt.Canary
+	}
+	if len(config.TenantSyncProviders) > 0 {
+		if config.TenantContextKey == "" {
+			return nil, errors.New("tenant configurations require a tenant context key")
+		}
+		tenants := make(map[string]eval.IEvaluator, len(config.TenantSyncProviders))
+		for tenant, tenantSources := range config.TenantSyncProviders {
+			tenantStore := newFlagStore(config)
+			tenantStore.FlagSources = make([]string, 0, len(tenantSources))
+			for _, sync := range tenantSources {
+				tenantStore.FlagSources = append(tenantStore.FlagSources, sync.URI)
+			}
+			tenantStore.DuplicateKeys = duplicateKeys
+			tenants[tenant] = eval.NewJSONEvaluator(evalLogger.WithFields(zap.String("tenant", tenant)), tenantStore,
+				evalOpts...)
+		}
+		rt.Tenants = eval.NewTenantEvaluator(evalLogger, config.TenantContextKey, rt.Evaluator, tenants)
+		rt.Evaluator = rt.Tenants
+	}
+	if err := rt.setSyncImplFromConfig(loggers[LogSubsystemSync]); err != nil {
+		return nil, err
+	}
+	if err := rt.setTracerProvider(traceSamplingRates); err != nil {
+		return nil, err
+	}
+	rt.setService(loggers[LogSubsystemServer], loggers[LogSubsystemAudit], accessLogger, unknownReasons,
+		unsupportedContext, oversizedContext, emptyFlagKeys, disabledFlags, targetingKeyFallback, readReplicaRPCs)
 	return &rt, nil
 }
 
-func (r *Runtime) setService(logger *logger.Logger) {
-	r.Service = &service.ConnectService{
+func (r *Runtime) setService(
+	logger *logger.Logger,
+	auditLogger *logger.Logger,
+	accessLogger *logger.Logger,
+	unknownReasons service.UnknownReasons,
+	unsupportedContext service.UnsupportedContextValues,
+	oversizedContext service.OversizedContextValues,
+	emptyFlagKeys service.EmptyFlagKeys,
+	disabledFlags service.DisabledFlags,
+	targetingKeyFallback service.TargetingKeyFallback,
+	readReplicaRPCs []service.ReadReplicaRPC,
+) {
+	svc := &service.ConnectService{
 		ConnectServiceConfiguration: &service.ConnectServiceConfiguration{
-			ServerKeyPath:    r.config.ServiceKeyPath,
-			ServerCertPath:   r.config.ServiceCertPath,
-			ServerSocketPath: r.config.ServiceSocketPath,
-			CORS:             r.config.CORS,
+			ServerKeyPath:              r.config.ServiceKeyPath,
+			ServerCertPath:             r.config.ServiceCertPath,
+			ServerSocketPath:           r.config.ServiceSocketPath,
+			ServerSocketWithPort:       r.config.ServiceSocketWithPort,
+			CORS:                       r.config.CORS,
+			DisabledResolveTypes:       r.config.DisabledResolveTypes,
+			LogContextKeys:             r.config.LogContextKeys,
+			ContextHeaders:             r.config.ContextHeaders,
+			TargetingKeySalt:           r.config.TargetingKeySalt,
+			MaxStreamSubscribers:       r.config.MaxStreamSubscribers,
+			DisableGRPCWeb:             r.config.DisableGRPCWeb,
+			EnableAdminAPI:             r.config.EnableAdminAPI,
+			AuthTokens:                 r.config.AuthTokens,
+			VariantDistributionWindow:  r.config.VariantDistributionWindow,
+			UnknownReasons:             unknownReasons,
+			UnsupportedContextValues:   unsupportedContext,
+			MaxContextValueBytes:       r.config.MaxContextValueBytes,
+			OversizedContextValues:     oversizedContext,
+			EmptyFlagKeys:              emptyFlagKeys,
+			StrictContextConversion:    r.config.StrictContextConversion,
+			ReadTimeout:                r.config.ServiceReadTimeout,
+			WriteTimeout:               r.config.ServiceWriteTimeout,
+			IdleTimeout:                r.config.ServiceIdleTimeout,
+			MaxHeaderBytes:             r.config.ServiceMaxHeaderBytes,
+			MaxRequestBytes:            r.config.ServiceMaxRequestBytes,
+			CompressMinBytes:           r.config.ServiceCompressMinBytes,
+			VerboseFlags:               r.config.VerboseFlags,
+			ContextSamples:             r.config.ContextSamples,
+			MetricsStreamInterval:      r.config.MetricsStreamInterval,
+			MaxConcurrentEvaluations:   r.config.MaxConcurrentEvaluations,
+			MaxQueuedEvaluations:       r.config.MaxQueuedEvaluations,
+			EvaluationWebhookURL:       r.config.EvaluationWebhookURL,
+			EvaluationWebhookBatchSize: r.config.EvaluationWebhookBatch,
+			EvaluationWebhookInterval:  r.config.EvaluationWebhookInterval,
+			DisabledFlags:              disabledFlags,
+			TargetingKeyFallback:       targetingKeyFallback,
+			ReadReplicaRPCs:            readReplicaRPCs,
+			ReadReplicaStaleness:       r.config.ReadReplicaStaleness,
 		},
 		Logger: logger.WithFields(
 			zap.String("component", "service"),
 		),
-		Metrics: r.metrics,
+		AuditLogger: auditLogger.WithFields(
+			zap.String("component", "service"),
+		),
+		AccessLogger: accessLogger,
+		Metrics:      r.metrics,
+	}
+	// a nil provider would be a non-nil tracer provider interface
+	if r.tracerProvider != nil {
+		svc.TracerProvider = r.tracerProvider
 	}
+	r.Service = svc
 }
 
 func (r *Runtime) setSyncImplFromConfig(logger *logger.Logger) error {
+	var verifier *signature.Verifier
+	var err error
+	if r.config.SignaturePublicKeyPath != "" {
+		if verifier, err = signature.LoadVerifier(r.config.SignaturePublicKeyPath); err != nil {
+			return err
+		}
+	}
+	if r.SyncImpl, err = r.syncImplFromSources(logger, r.config.SyncProviders, verifier); err != nil {
+		return err
+	}
+	if r.CanarySyncImpl, err = r.syncImplFromSources(logger, r.config.CanarySyncProviders, verifier); err != nil {
+		return err
+	}
+	r.TenantSyncImpl = make(map[string][]sync.ISync, len(r.config.TenantSyncProviders))
+	for tenant, sources := range r.config.TenantSyncProviders {
+		if r.TenantSyncImpl[tenant], err = r.syncImplFromSources(logger, sources, verifier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncImplFromSources creates the sync providers of the sources, verifying their configurations if verifier is set
+func (r *Runtime) syncImplFromSources(
+	logger *logger.Logger, sources []sync.SourceConfig, verifier *signature.Verifier,
+) ([]sync.ISync, error) {
 	rtLogger := logger.WithFields(zap.String("component", "runtime"))
-	r.SyncImpl = make([]sync.ISync, 0, len(r.config.SyncProviders))
-	for _, syncProvider := range r.config.SyncProviders {
+	syncImpl := make([]sync.ISync, 0, len(sources))
+	for _, syncProvider := range sources {
+		if len(syncProvider.Fallbacks) > 0 {
+			f, err := r.newFailover(syncProvider, verifier, logger)
+			if err != nil {
+				return nil, err
+			}
+			syncImpl = append(syncImpl, f)
+			rtLogger.Debug(fmt.Sprintf("using failover sync-provider for: %s", syncProvider.URI))
+			continue
+		}
 		switch syncProvider.Provider {
 		case syncProviderFile:
-			r.SyncImpl = append(
-				r.SyncImpl,
-				r.newFile(syncProvider, logger),
-			)
+			f := r.newFile(syncProvider, logger)
+			syncImpl = append(syncImpl, f)
 			rtLogger.Debug(fmt.Sprintf("using filepath sync-provider for: %q", syncProvider.URI))
+			if verifier != nil && file.IsBundle(f.URI) {
+				// bundles are verified before they're extracted, the merged configuration isn't signed
+				f.VerifyBundle = bundleVerifier(syncProvider, verifier)
+				continue
+			}
 		case syncProviderKubernetes:
 			k, err := r.newK8s(syncProvider.URI, logger)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			r.SyncImpl = append(
-				r.SyncImpl,
+			syncImpl = append(
+				syncImpl,
 				k,
 			)
 			rtLogger.Debug(fmt.Sprintf("using kubernetes sync-provider for: %s", syncProvider.URI))
 		case syncProviderHTTP:
-			r.SyncImpl = append(
-				r.SyncImpl,
+			syncImpl = append(
+				syncImpl,
 				r.newHTTP(syncProvider, logger),
 			)
 			rtLogger.Debug(fmt.Sprintf("using remote sync-provider for: %s", syncProvider.URI))
 		case syncProviderGrpc:
-			r.SyncImpl = append(
-				r.SyncImpl,
+			syncImpl = append(
+				syncImpl,
 				r.newGRPC(syncProvider, logger),
 			)
+		case syncProviderStdin:
+			if r.stdinSynced {
+				return nil, errors.New("stdin can only be the sync uri of a single source")
+			}
+			r.stdinSynced = true
+			syncImpl = append(syncImpl, r.newStdin(logger))
+			rtLogger.Debug("using stdin sync-provider")
+		case syncProviderConsul:
+			c, err := r.newConsul(syncProvider, logger)
+			if err != nil {
+				return nil, err
+			}
+			syncImpl = append(syncImpl, c)
+			rtLogger.Debug(fmt.Sprintf("using consul sync-provider for: %s", syncProvider.URI))
+		case syncProviderOCI:
+			o, err := r.newOCI(syncProvider, logger)
+			if err != nil {
+				return nil, err
+			}
+			syncImpl = append(syncImpl, o)
+			rtLogger.Debug(fmt.Sprintf("using oci sync-provider for: %s", syncProvider.URI))
+		case syncProviderS3:
+			s, err := r.newS3(syncProvider, logger)
+			if err != nil {
+				return nil, err
+			}
+			syncImpl = append(syncImpl, s)
+			rtLogger.Debug(fmt.Sprintf("using s3 sync-provider for: %s", syncProvider.URI))
 		default:
-			return fmt.Errorf("invalid sync uri argument: %s, must start with 'file:', 'http(s)://', 'grpc://',"+
-				" or 'core.openfeature.dev'", syncProvider.URI)
+			return nil, fmt.Errorf("invalid sync uri argument: %s, must start with 'file:', 'http(s)://', 'grpc://',"+
+				" 'consul(+https)://', 'oci://', 's3://' or 'core.openfeature.dev', or be 'stdin'", syncProvider.URI)
+		}
+		if verifier != nil {
+			signed, err := r.newSigned(syncProvider, syncImpl[len(syncImpl)-1], verifier, logger)
+			if err != nil {
+				return nil, err
+			}
+			syncImpl[len(syncImpl)-1] = signed
 		}
 	}
-	return nil
+	return syncImpl, nil
+}
+
+// newFailover creates the sync provider of a source with fallbacks, the sync providers of the source and its fallbacks
+// are created as those of any source
+func (r *Runtime) newFailover(
+	config sync.SourceConfig, verifier *signature.Verifier, logger *logger.Logger,
+) (*failover.Sync, error) {
+	primary := config
+	primary.Fallbacks = nil
+	candidates := append([]sync.SourceConfig{primary}, config.Fallbacks...)
+	for _, fallback := range config.Fallbacks {
+		if len(fallback.Fallbacks) > 0 {
+			return nil, fmt.Errorf("fallback %s of source %s can't have fallbacks", fallback.URI, config.URI)
+		}
+	}
+	syncImpl, err := r.syncImplFromSources(logger, candidates, verifier)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]failover.Source, 0, len(candidates))
+	for i, candidate := range candidates {
+		sources = append(sources, failover.Source{URI: candidate.URI, Sync: syncImpl[i]})
+	}
+	return &failover.Sync{
+		URI:     config.URI,
+		Sources: sources,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "failover"),
+		),
+		StartupTimeout: r.config.SourceFallbackTimeout,
+		RetryInterval:  r.config.SourceFallbackRetryInterval,
+	}, nil
+}
+
+func (r *Runtime) newSigned(
+	config sync.SourceConfig, syncImpl sync.ISync, verifier *signature.Verifier, logger *logger.Logger,
+) (*signature.Sync, error) {
+	signatureURI := config.SignatureURI
+	if signatureURI == "" {
+		signatureURI = config.URI + signature.Extension
+	}
+	var fetcher signature.Fetcher
+	switch config.Provider {
+	case syncProviderFile:
+		fetcher = signature.FileFetcher(signatureURI)
+	case syncProviderHTTP:
+		fetcher = signature.HTTPFetcher(&http.Client{Timeout: r.syncTimeout()}, signatureURI, config.BearerToken)
+	default:
+		return nil, fmt.Errorf("signature verification isn't supported by the %s sync provider of: %s",
+			config.Provider, config.URI)
+	}
+	return &signature.Sync{
+		ISync:     syncImpl,
+		Verifier:  verifier,
+		Signature: fetcher,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "signature"),
+		),
+	}, nil
+}
+
+// bundleVerifier verifies the bundle of the source against its detached signature
+func bundleVerifier(config sync.SourceConfig, verifier *signature.Verifier) func(context.Context, []byte) error {
+	signatureURI := config.SignatureURI
+	if signatureURI == "" {
+		signatureURI = config.URI + signature.Extension
+	}
+	fetcher := signature.FileFetcher(signatureURI)
+	return func(ctx context.Context, bundle []byte) error {
+		sig, err := fetcher(ctx)
+		if err != nil {
+			return err
+		}
+		return verifier.Verify(bundle, sig)
+	}
 }
 
 func (r *Runtime) newGRPC(config sync.SourceConfig, logger *logger.Logger) *grpc.Sync {
@@ -138,6 +561,7 @@ func (r *Runtime) newGRPC(config sync.SourceConfig, logger *logger.Logger) *grpc
 		CertPath:   config.CertPath,
 		ProviderID: config.ProviderID,
 		Selector:   config.Selector,
+		Timeout:    r.syncTimeout(),
 	}
 }
 
@@ -145,7 +569,7 @@ func (r *Runtime) newHTTP(config sync.SourceConfig, logger *logger.Logger) *http
 	return &httpSync.Sync{
 		URI: config.URI,
 		Client: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout: r.syncTimeout(),
 		},
 		Logger: logger.WithFields(
 			zap.String("component", "sync"),
@@ -156,6 +580,143 @@ func (r *Runtime) newHTTP(config sync.SourceConfig, logger *logger.Logger) *http
 	}
 }
 
+// sourcePrefixes returns the flag key prefixes of the sources of the configuration, keyed by source URI
+func sourcePrefixes(config Config) map[string]string {
+	prefixes := map[string]string{}
+	add := func(sources []sync.SourceConfig) {
+		for _, source := range sources {
+			if source.Prefix != "" {
+				prefixes[source.URI] = source.Prefix
+			}
+		}
+	}
+	add(config.SyncProviders)
+	add(config.CanarySyncProviders)
+	for _, sources := range config.TenantSyncProviders {
+		add(sources)
+	}
+	return prefixes
+}
+
+// newFlagStore returns the store of the flags of a configuration, compressing their large variants if configured
+func newFlagStore(config Config) *store.Flags {
+	if config.StoreCompression {
+		return store.NewFlagsWithStore(store.NewCompressedStore(store.NewMemoryStore()))
+	}
+	return store.NewFlags()
+}
+
+// syncTimeout is the timeout of the requests of remote sources
+func (r *Runtime) syncTimeout() time.Duration {
+	if r.config.SyncTimeout <= 0 {
+		return defaultSyncTimeout
+	}
+	return r.config.SyncTimeout
+}
+
+func (r *Runtime) newConsul(config sync.SourceConfig, logger *logger.Logger) (*kv.Sync, error) {
+	address, key, err := kv.ParseConsulURI(config.URI)
+	if err != nil {
+		return nil, err
+	}
+	if config.CertPath != "" && !strings.HasPrefix(config.URI, kv.ConsulSecurePrefix) {
+		return nil, fmt.Errorf("certPath of consul source %s requires a %s uri", config.URI, kv.ConsulSecurePrefix)
+	}
+	client, err := kv.NewConsulClient(config.CertPath)
+	if err != nil {
+		return nil, err
+	}
+	store := &kv.ConsulStore{
+		Address: address,
+		Token:   config.BearerToken,
+		Client:  client,
+	}
+	if err := store.Validate(); err != nil {
+		return nil, err
+	}
+	return &kv.Sync{
+		URI:   config.URI,
+		Key:   key,
+		Store: store,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "consul"),
+		),
+	}, nil
+}
+
+func (r *Runtime) newOCI(config sync.SourceConfig, logger *logger.Logger) (*oci.Sync, error) {
+	pollInterval, err := parsePollInterval(config)
+	if err != nil {
+		return nil, err
+	}
+	return &oci.Sync{
+		URI:    config.URI,
+		Client: &http.Client{Timeout: r.syncTimeout()},
+		Credentials: oci.Credentials{
+			Username: config.Username,
+			Password: config.Password,
+			Token:    config.BearerToken,
+		},
+		PollInterval: pollInterval,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "oci"),
+		),
+	}, nil
+}
+
+func (r *Runtime) newS3(config sync.SourceConfig, logger *logger.Logger) (*s3.Sync, error) {
+	pollInterval, err := parsePollInterval(config)
+	if err != nil {
+		return nil, err
+	}
+	region := config.Region
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region == "" {
+			region = os.Getenv(env)
+		}
+	}
+	if region == "" {
+		return nil, fmt.Errorf("no region of source %s, set its region or AWS_REGION", config.URI)
+	}
+	client, err := s3.NewAWSClient(context.Background(), region, "", r.syncTimeout())
+	if err != nil {
+		return nil, err
+	}
+	return &s3.Sync{
+		URI:          config.URI,
+		Client:       client,
+		PollInterval: pollInterval,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "s3"),
+		),
+	}, nil
+}
+
+// parsePollInterval returns the poll interval of the source, 0 if unset
+func parsePollInterval(config sync.SourceConfig) (time.Duration, error) {
+	if config.PollInterval == "" {
+		return 0, nil
+	}
+	pollInterval, err := time.ParseDuration(config.PollInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid poll interval %s of source %s: %w", config.PollInterval, config.URI, err)
+	}
+	return pollInterval, nil
+}
+
+func (r *Runtime) newStdin(logger *logger.Logger) *stdin.Sync {
+	return &stdin.Sync{
+		Reader: os.Stdin,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "stdin"),
+		),
+	}
+}
+
 func (r *Runtime) newK8s(uri string, logger *logger.Logger) (*kubernetes.Sync, error) {
 	reader, dynamic, err := kubernetes.GetClients()
 	if err != nil {
@@ -179,7 +740,8 @@ func (r *Runtime) newFile(config sync.SourceConfig, logger *logger.Logger) *file
 			zap.String("component", "sync"),
 			zap.String("sync", "filepath"),
 		),
-		Mux: &msync.RWMutex{},
+		Debounce: r.config.FileSyncDebounce,
+		Mux:      &msync.RWMutex{},
 	}
 }
 
@@ -229,9 +791,30 @@ func SyncProvidersFromURIs(uris []string) ([]sync.SourceConfig, error) {
 				URI:      uri,
 				Provider: syncProviderGrpc,
 			})
+		case regConsul.Match(uriB), regConsulSecure.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderConsul,
+			})
+		case regOCI.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderOCI,
+			})
+		case regS3.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderS3,
+			})
+		case regStdin.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderStdin,
+			})
 		default:
 			return syncProvidersParsed, fmt.Errorf("invalid sync uri argument: %s, must start with 'file:', "+
-				"'http(s)://', 'grpc://', or 'core.openfeature.dev'", uri)
+				"'http(s)://', 'grpc://', 'consul(+https)://', 'oci://', 's3://' or 'core.openfeature.dev', or be 'stdin'",
+				uri)
 		}
 	}
 	return syncProvidersParsed, nil
@@ -0,0 +1,146 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/failover"
+	"github.com/open-feature/flagd/core/pkg/sync/file"
+	httpSync "github.com/open-feature/flagd/core/pkg/sync/http"
+	"github.com/open-feature/flagd/core/pkg/sync/kv"
+	"github.com/open-feature/flagd/core/pkg/sync/oci"
+	"github.com/open-feature/flagd/core/pkg/sync/s3"
+	"github.com/open-feature/flagd/core/pkg/sync/stdin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdinSource(t *testing.T) {
+	r := &Runtime{}
+	source := sync.SourceConfig{URI: stdin.URI, Provider: syncProviderStdin}
+	syncImpl, err := r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.Nil(t, err)
+	require.Len(t, syncImpl, 1)
+	require.IsType(t, &stdin.Sync{}, syncImpl[0])
+
+	// the standard input can only be read once, e.g. by a candidate or tenant source
+	_, err = r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.EqualError(t, err, "stdin can only be the sync uri of a single source")
+}
+
+func TestOCISource(t *testing.T) {
+	r := &Runtime{}
+	source := sync.SourceConfig{
+		URI:          "oci://registry.example.com/flags/payments:v1",
+		Provider:     syncProviderOCI,
+		Username:     "jane",
+		Password:     "s3cret",
+		PollInterval: "1m",
+	}
+	syncImpl, err := r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.Nil(t, err)
+	require.Len(t, syncImpl, 1)
+	o, ok := syncImpl[0].(*oci.Sync)
+	require.True(t, ok)
+	require.Equal(t, oci.Credentials{Username: "jane", Password: "s3cret"}, o.Credentials)
+	require.Equal(t, time.Minute, o.PollInterval)
+
+	source.PollInterval = "often"
+	_, err = r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.ErrorContains(t, err, "invalid poll interval often of source oci://registry.example.com/flags/payments:v1")
+}
+
+func TestConsulSource(t *testing.T) {
+	r := &Runtime{}
+	sources := func(source sync.SourceConfig) ([]sync.ISync, error) {
+		source.Provider = syncProviderConsul
+		return r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	}
+
+	syncImpl, err := sources(sync.SourceConfig{URI: "consul+https://consul.example.com:8501/flagd/flags",
+		BearerToken: "secret"})
+	require.Nil(t, err)
+	require.Equal(t, "https://consul.example.com:8501", syncImpl[0].(*kv.Sync).Store.(*kv.ConsulStore).Address)
+
+	_, err = sources(sync.SourceConfig{URI: "consul://consul.example.com:8500/flagd/flags", BearerToken: "secret"})
+	require.EqualError(t, err, "refusing to send the consul ACL token over http to consul.example.com:8500, "+
+		"use a consul+https:// uri")
+	_, err = sources(sync.SourceConfig{URI: "consul://consul.example.com:8500/flagd/flags", CertPath: "ca.pem"})
+	require.EqualError(t, err, "certPath of consul source consul://consul.example.com:8500/flagd/flags requires a "+
+		"consul+https:// uri")
+}
+
+func TestS3Source(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	r := &Runtime{}
+	source := sync.SourceConfig{
+		URI:          "s3://my-bucket/flagd/flags.json",
+		Provider:     syncProviderS3,
+		Region:       "eu-west-1",
+		PollInterval: "30s",
+	}
+	syncImpl, err := r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.Nil(t, err)
+	require.Len(t, syncImpl, 1)
+	s, ok := syncImpl[0].(*s3.Sync)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, s.PollInterval)
+	client, ok := s.Client.(*s3.AWSClient)
+	require.True(t, ok)
+	require.Equal(t, "eu-west-1", client.Region())
+
+	t.Setenv("AWS_REGION", "us-east-1")
+	source.Region = ""
+	syncImpl, err = r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.Nil(t, err)
+	require.Equal(t, "us-east-1", syncImpl[0].(*s3.Sync).Client.(*s3.AWSClient).Region(),
+		"the region should default to the region of the environment")
+
+	t.Setenv("AWS_REGION", "")
+	_, err = r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.EqualError(t, err, "no region of source s3://my-bucket/flagd/flags.json, set its region or AWS_REGION")
+}
+
+func TestFallbackSources(t *testing.T) {
+	r := &Runtime{}
+	source := sync.SourceConfig{
+		URI:       "https://flags.example.com/flags.json",
+		Provider:  syncProviderHTTP,
+		Fallbacks: []sync.SourceConfig{{URI: "backup.json", Provider: syncProviderFile}},
+	}
+	syncImpl, err := r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.Nil(t, err)
+	require.Len(t, syncImpl, 1)
+	f, ok := syncImpl[0].(*failover.Sync)
+	require.True(t, ok)
+	require.Equal(t, source.URI, f.URI)
+	require.Len(t, f.Sources, 2)
+	require.Equal(t, source.URI, f.Sources[0].URI)
+	require.IsType(t, &httpSync.Sync{}, f.Sources[0].Sync)
+	require.Equal(t, "backup.json", f.Sources[1].URI)
+	require.IsType(t, &file.Sync{}, f.Sources[1].Sync)
+
+	source.Fallbacks[0].Fallbacks = []sync.SourceConfig{{URI: "older-backup.json", Provider: syncProviderFile}}
+	_, err = r.syncImplFromSources(logger.NewLogger(nil, false), []sync.SourceConfig{source}, nil)
+	require.EqualError(t, err, "fallback backup.json of source https://flags.example.com/flags.json can't have fallbacks")
+}
+
+func TestSourcePrefixes(t *testing.T) {
+	prefixes := sourcePrefixes(Config{
+		SyncProviders: []sync.SourceConfig{
+			{URI: "team-a.json", Provider: syncProviderFile, Prefix: "teamA/"},
+			{URI: "shared.json", Provider: syncProviderFile},
+		},
+		CanarySyncProviders: []sync.SourceConfig{{URI: "team-a-canary.json", Provider: syncProviderFile, Prefix: "teamA/"}},
+		TenantSyncProviders: map[string][]sync.SourceConfig{
+			"acme": {{URI: "acme.json", Provider: syncProviderFile, Prefix: "acme/"}},
+		},
+	})
+	require.Equal(t, map[string]string{
+		"team-a.json":        "teamA/",
+		"team-a-canary.json": "teamA/",
+		"acme.json":          "acme/",
+	}, prefixes)
+}
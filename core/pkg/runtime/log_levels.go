@@ -0,0 +1,51 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// The subsystems whose log level can be set independently
+const (
+	// LogSubsystemSync logs the sync providers
+	LogSubsystemSync = "sync"
+	// LogSubsystemEvaluation logs the evaluators
+	LogSubsystemEvaluation = "evaluation"
+	// LogSubsystemServer logs the flag evaluation service
+	LogSubsystemServer = "server"
+	// LogSubsystemAudit logs the changes made to the running configuration, through the admin API or signals
+	LogSubsystemAudit = "audit"
+)
+
+// subsystemLoggers returns the loggers of the subsystems keyed by subsystem, filtering their logs to the level of
+// the subsystem in levels, or to the level of log if it isn't set
+func subsystemLoggers(log *logger.Logger, levels map[string]string) (map[string]*logger.Logger, error) {
+	loggers := map[string]*logger.Logger{
+		LogSubsystemSync:       log,
+		LogSubsystemEvaluation: log,
+		LogSubsystemServer:     log,
+		LogSubsystemAudit:      log,
+	}
+	for subsystem, name := range levels {
+		if _, ok := loggers[subsystem]; !ok {
+			return nil, fmt.Errorf("unknown log subsystem: '%s', expected one of '%s', '%s', '%s' or '%s'", subsystem,
+				LogSubsystemSync, LogSubsystemEvaluation, LogSubsystemServer, LogSubsystemAudit)
+		}
+		level, err := zapcore.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("log level of subsystem: %s: %w", subsystem, err)
+		}
+		loggers[subsystem] = log.WithLevel(level)
+	}
+	return loggers, nil
+}
+
+// audit returns the logger of the changes made to the running configuration
+func (r *Runtime) audit() *logger.Logger {
+	if r.auditLogger == nil {
+		return r.Logger
+	}
+	return r.auditLogger
+}
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSubsystemLoggers(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := logger.NewVerboseLogger(zap.New(core), zap.InfoLevel, false)
+	loggers, err := subsystemLoggers(log, map[string]string{
+		LogSubsystemSync:       "debug",
+		LogSubsystemEvaluation: "warn",
+		LogSubsystemAudit:      "error",
+	})
+	require.Nil(t, err)
+
+	for subsystem, l := range loggers {
+		l = l.WithFields(zap.String("subsystem", subsystem))
+		l.Debug("debug")
+		l.Info("info")
+		l.Warn("warn")
+		l.Error("error")
+	}
+	logged := func(subsystem string) []string {
+		var messages []string
+		for _, entry := range logs.FilterField(zap.String("subsystem", subsystem)).All() {
+			messages = append(messages, entry.Message)
+		}
+		return messages
+	}
+	require.Equal(t, []string{"debug", "info", "warn", "error"}, logged(LogSubsystemSync))
+	require.Equal(t, []string{"warn", "error"}, logged(LogSubsystemEvaluation))
+	require.Equal(t, []string{"error"}, logged(LogSubsystemAudit))
+	require.Equal(t, []string{"info", "warn", "error"}, logged(LogSubsystemServer),
+		"subsystems without a level should log at the level of the logger")
+}
+
+func TestSubsystemLoggers_Invalid(t *testing.T) {
+	log := logger.NewLogger(nil, false)
+	_, err := subsystemLoggers(log, map[string]string{"storage": "debug"})
+	require.ErrorContains(t, err, "unknown log subsystem: 'storage'")
+	_, err = subsystemLoggers(log, map[string]string{LogSubsystemSync: "verbose"})
+	require.ErrorContains(t, err, "log level of subsystem: sync")
+
+	_, err = FromConfig(log, Config{LogLevels: map[string]string{"storage": "debug"}})
+	require.ErrorContains(t, err, "unknown log subsystem")
+}
@@ -0,0 +1,31 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// startReloadSignal resyncs every source on the reload signal. Reloads are coalesced with the pending resyncs and
+// the updates of the sources, e.g. of a changed file, are applied one at a time.
+func (r *Runtime) startReloadSignal(ctx context.Context) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, reloadSignals...)
+
+	go func() {
+		defer signal.Stop(reload)
+		for {
+			select {
+			case sig := <-reload:
+				r.Logger.Info(fmt.Sprintf("received %s, reloading the configuration of every source", sig))
+				r.resync()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
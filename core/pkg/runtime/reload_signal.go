@@ -0,0 +1,11 @@
+//go:build !windows
+
+package runtime
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals reload the whole configuration of every source
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package runtime
+
+import "os"
+
+// reloadSignals reload the whole configuration of every source, windows has no hangup signal
+var reloadSignals = []os.Signal{}
@@ -0,0 +1,123 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gosync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// reloadingSync sends a new generation of its configuration on each change and resync
+type reloadingSync struct {
+	chanSync
+	generations atomic.Int32
+}
+
+func (s *reloadingSync) next() sync.DataSync {
+	generation := s.generations.Add(1)
+	flags := map[string]interface{}{}
+	for i := 0; i < 20; i++ {
+		flags[fmt.Sprintf("flag-%d", i)] = map[string]interface{}{
+			"state":          "ENABLED",
+			"variants":       map[string]interface{}{"generation": generation},
+			"defaultVariant": "generation",
+		}
+	}
+	config, _ := json.Marshal(map[string]interface{}{"flags": flags})
+	return sync.DataSync{FlagData: string(config), Source: "flags.json", Type: sync.ALL}
+}
+
+func (s *reloadingSync) ReSync(_ context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- s.next()
+	return nil
+}
+
+// generations returns the generations of the flags of the state
+func generations(state string) (map[float64]struct{}, error) {
+	var flags struct {
+		Flags map[string]model.Flag `json:"flags"`
+	}
+	if err := json.Unmarshal([]byte(state), &flags); err != nil {
+		return nil, err
+	}
+	generations := map[float64]struct{}{}
+	for _, flag := range flags.Flags {
+		generations[flag.Variants["generation"].(float64)] = struct{}{}
+	}
+	return generations, nil
+}
+
+func TestConcurrentReloads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &reloadingSync{chanSync: chanSync{updates: make(chan sync.DataSync)}}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{source},
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	require.Nil(t, r.startSyncs(gCtx, g, r.SyncImpl, r.updateWithNotify))
+
+	done := make(chan struct{})
+	readers := gosync.WaitGroup{}
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			state, err := r.Evaluator.GetState()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			loaded, err := generations(state)
+			if err != nil || len(loaded) > 1 {
+				t.Errorf("state mixes the flags of generations %v: %v", loaded, err)
+				return
+			}
+		}
+	}()
+
+	// file changes and reload signals fire concurrently
+	triggers := gosync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		triggers.Add(2)
+		go func() {
+			defer triggers.Done()
+			r.resync()
+		}()
+		go func() {
+			defer triggers.Done()
+			source.updates <- source.next()
+		}()
+	}
+	triggers.Wait()
+
+	close(done)
+	readers.Wait()
+
+	require.Eventually(t, func() bool {
+		state, err := r.Evaluator.GetState()
+		require.Nil(t, err)
+		loaded, err := generations(state)
+		require.Nil(t, err)
+		return len(loaded) == 1
+	}, time.Second, time.Millisecond, "the state should hold a single generation once the reloads are applied")
+}
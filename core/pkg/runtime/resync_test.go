@@ -0,0 +1,47 @@
+package runtime
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// countingSync counts its resyncs
+type countingSync struct {
+	chanSync
+	resyncs atomic.Int32
+}
+
+func (c *countingSync) ReSync(context.Context, chan<- sync.DataSync) error {
+	c.resyncs.Add(1)
+	return nil
+}
+
+func TestResync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sources := []*countingSync{{chanSync: chanSync{updates: make(chan sync.DataSync)}}, {}}
+	r := Runtime{
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{sources[0]},
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	require.Nil(t, r.startSyncs(gCtx, g, r.SyncImpl, r.updateWithNotify))
+	require.Nil(t, r.startSyncs(gCtx, g, []sync.ISync{sources[1]}, r.updateWithNotify))
+
+	r.resync()
+	for _, source := range sources {
+		require.Eventually(t, func() bool { return source.resyncs.Load() == 1 }, time.Second, time.Millisecond,
+			"the sources of every sync must be resynced")
+	}
+}
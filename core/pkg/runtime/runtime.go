@@ -6,39 +6,307 @@ import (
 	"os"
 	"os/signal"
 	msync "sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/eval"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/otel"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/sync/errgroup"
 )
 
 type Runtime struct {
-	Evaluator   eval.IEvaluator
-	Logger      *logger.Logger
-	Service     service.IFlagEvaluationService
-	SyncImpl    []sync.ISync
-	config      Config
-	metrics     *otel.MetricsRecorder
-	mu          msync.Mutex
-	serviceName string
+	Evaluator eval.IEvaluator
+	Logger    *logger.Logger
+	Service   service.IFlagEvaluationService
+	SyncImpl  []sync.ISync
+	// Canary is set when a candidate configuration is rolled out, its state is synced from CanarySyncImpl
+	Canary         *eval.CanaryEvaluator
+	CanarySyncImpl []sync.ISync
+	// Tenants is set when tenants have their own configuration, the state of each tenant is synced from TenantSyncImpl
+	Tenants        *eval.TenantEvaluator
+	TenantSyncImpl map[string][]sync.ISync
+	config         Config
+	metrics        *otel.MetricsRecorder
+	mu             msync.Mutex
+	serviceName    string
+
+	freeze        configFreeze
+	flaps         flapReleases
+	degraded      atomic.Bool
+	syncedSources map[string]struct{}
+	summaryOnce   msync.Once
+	// startedSources are the sources which applied their initial configuration, started is closed once all have
+	startedSources map[string]struct{}
+	started        chan struct{}
+	sourceStatuses *sync.SourceStatuses
+	// auditLogger logs the changes made to the running configuration, Logger if nil
+	auditLogger *logger.Logger
+	// stdinSynced is set once a source reads the standard input, which can only be read once
+	stdinSynced bool
+	// resyncs request the resync of the sources started by each startSyncs
+	resyncs []chan struct{}
+	// tracerProvider traces the evaluations of the service, nil unless a collector is configured
+	tracerProvider *sdktrace.TracerProvider
+	// emptyConfiguration is the policy of initial configurations without flags, flagsLoaded is set once a
+	// configuration holding a flag is applied under the not-ready policy
+	emptyConfiguration EmptyConfiguration
+	flagsLoaded        atomic.Bool
+	// configLoaded is the unix time in nanoseconds of the last reload of the configuration
+	configLoaded atomic.Int64
+	// swapped is the evaluator swapped in by SwapEvaluator, swap the swap pending until the sources resync
+	swapped atomic.Pointer[eval.IEvaluator]
+	swap    *evaluatorSwap
 }
 
 type Config struct {
 	ServicePort       uint16
 	MetricsPort       uint16
 	ServiceSocketPath string
-	ServiceCertPath   string
-	ServiceKeyPath    string
+	// ServiceSocketWithPort serves ServicePort alongside ServiceSocketPath, which is otherwise served alone
+	ServiceSocketWithPort bool
+	ServiceCertPath       string
+	ServiceKeyPath        string
+	// ServiceReadTimeout bounds the time taken reading a request, 10s when 0
+	ServiceReadTimeout time.Duration
+	// ServiceWriteTimeout bounds the time taken writing a response, including streams, unbounded when 0
+	ServiceWriteTimeout time.Duration
+	// ServiceIdleTimeout closes the service connections idle for longer, 2m when 0
+	ServiceIdleTimeout time.Duration
+	// ServiceMaxHeaderBytes bounds the size of request headers, 64KiB when 0
+	ServiceMaxHeaderBytes int
+	// ServiceMaxRequestBytes bounds the size of request bodies, 4MiB when 0
+	ServiceMaxRequestBytes int64
+	// ServiceCompressMinBytes is the size of the smallest response compressed for clients accepting compression,
+	// 1KiB when 0
+	ServiceCompressMinBytes int
 
 	SyncProviders []sync.SourceConfig
 	CORS          []string
+	// FileSyncDebounce coalesces the changes of file sources within the window into a single reload
+	FileSyncDebounce time.Duration
+	// SourceFallbackTimeout bounds the time a source with fallbacks, or one of its fallbacks, takes to load its
+	// configuration before the next one is synced. The sources preferred over the active one are retried every
+	// SourceFallbackRetryInterval.
+	SourceFallbackTimeout       time.Duration
+	SourceFallbackRetryInterval time.Duration
+	// SyncTimeout bounds the requests of remote sources, and the time a gRPC sync stream takes to deliver its first
+	// message. Defaults to 10 seconds when 0.
+	SyncTimeout time.Duration
+
+	ValidationWorkers    int
+	DisabledResolveTypes []string
+	// DefaultVariantFallback falls back to the first variant of flags lacking a valid default variant, instead of
+	// rejecting their configuration
+	DefaultVariantFallback bool
+	// ContextCoercion converts evaluation context values compared by targeting rules to the type of the comparison,
+	// either off, coerce, converting numeric and boolean strings, or strict, failing evaluations comparing another type
+	ContextCoercion string
+	// ContextKeyNormalization normalizes evaluation context keys and the keys referenced by targeting rules, e.g.
+	// lowercase for case-insensitive matching
+	ContextKeyNormalization string
+	// ContextSamples is the number of recent evaluation contexts sampled, with their targeting key hashed, to compare
+	// candidate configurations through the admin API. Contexts aren't sampled when 0 or without the admin API.
+	ContextSamples int
+	// DuplicateFlagKeys is the policy of flag keys defined more than once, one of error, first-wins or last-wins
+	DuplicateFlagKeys string
+	// BucketingHash is the hash function bucketing the context values of fractional evaluations, either xxh3,
+	// murmur3 or fnv1a
+	BucketingHash string
+	// LargeIntegers is the policy of integers of object variants beyond the exact range of float64 numbers, either
+	// error or string
+	LargeIntegers string
+	// SchemaMismatch is the policy of object variants which don't conform to the schema of their flag, either error
+	// or warn
+	SchemaMismatch string
+	// VariantTypeMismatch is the policy of variants whose value isn't of the type of the default variant of their
+	// flag, either error or warn
+	VariantTypeMismatch string
+	// FlagKeyCharacters is the pattern matching each allowed character of flag keys, flag keys aren't checked when
+	// empty. InvalidFlagKeys is the policy of keys with other characters, either error or sanitize.
+	FlagKeyCharacters string
+	InvalidFlagKeys   string
+	// UndefinedVariants is the policy of targeting rules resolving variants which their flag doesn't define, either
+	// fallback or error
+	UndefinedVariants string
+	// FractionalRemainder is the policy of fractional evaluations whose percentages sum to under 100, either error or
+	// default resolving the default variant for the remaining buckets
+	FractionalRemainder string
+	// TemplateMissingKeys is the policy of the placeholders of templated flags missing from the evaluation context,
+	// either keep or error
+	TemplateMissingKeys string
+	// EvaluationHash adds a stable hash of each evaluation to its resolution metadata, e.g. for analytics to dedupe
+	// identical decisions
+	EvaluationHash bool
+	// ContextKeysMetadata adds the evaluation context keys read by each flag to its resolution metadata, so clients
+	// can prune the context they send
+	ContextKeysMetadata bool
+	// FlapThreshold is the number of changes of the definition of a flag within FlapWindow beyond which its value is
+	// held until it stabilizes, flap detection is disabled when 0
+	FlapThreshold int
+	FlapWindow    time.Duration
+	// CircuitBreakerFailures is the number of failed evaluations of the targeting of a flag in a row, counting those
+	// slower than CircuitBreakerSlow if set, after which the flag resolves its default variant with the ERROR reason
+	// for CircuitBreakerCooldown. Circuit breakers are disabled when 0.
+	CircuitBreakerFailures int
+	CircuitBreakerSlow     time.Duration
+	CircuitBreakerCooldown time.Duration
+	// NamespaceFallthrough resolves flags which aren't defined through their closest defined ancestor namespace, the
+	// namespaces of flag keys being separated by NamespaceSeparator
+	NamespaceFallthrough bool
+	NamespaceSeparator   string
+	// StoreCompression stores the large string and object variants of flags compressed in memory, decompressing them
+	// on each evaluation of their flag
+	StoreCompression bool
+	// MaxVariants rejects flags with more variants when loading their configuration, keeping its other flags. The
+	// number of variants is unbounded when 0.
+	MaxVariants int
+	// RuleStatistics records the matched branch of the top-level if of the targeting rule of each evaluation, served by
+	// the admin API and as metrics
+	RuleStatistics bool
+	// RuleWarmup parses the targeting rules of new configurations before swapping them in, logging the warmup time
+	RuleWarmup bool
+	// TargetingReordering orders the exclusive conditions of the targeting of flags by the evaluations they matched
+	// while the previous configuration was served, when loading a configuration
+	TargetingReordering bool
+	// LogContextKeys lists the evaluation context keys whose values may be logged, other values are redacted
+	LogContextKeys []string
+	// ContextHeaders maps request headers, e.g. gRPC metadata set by a gateway, to the evaluation context keys their
+	// values are merged into, the context of the request taking precedence
+	ContextHeaders map[string]string
+	// TargetingKeySalt keys the hash of the targeting keys surfaced in logs and evaluation events, which never include
+	// the raw key
+	TargetingKeySalt string
+	// MaxStreamSubscribers bounds the concurrent event stream subscribers, unbounded when 0
+	MaxStreamSubscribers int
+	// MaxConcurrentEvaluations bounds the evaluations served at once, queueing further evaluations, unbounded when 0
+	MaxConcurrentEvaluations int
+	// MaxQueuedEvaluations bounds the queued evaluations, further evaluations are shed, unbounded when 0
+	MaxQueuedEvaluations int
+	// DisableGRPCWeb rejects gRPC-web requests of browser clients
+	DisableGRPCWeb bool
+	// EnableAdminAPI serves the admin endpoints of flag management interfaces, disabled by default
+	EnableAdminAPI bool
+	// AuthTokens lists the bearer tokens accepted by the flag evaluation service, unauthenticated when empty
+	AuthTokens []string
+	// StartupReadiness reports flagd as ready only once every source has applied its initial configuration, validated
+	// and with its targeting rules warmed up, rather than once the sources can watch for changes
+	StartupReadiness bool
+	// EmptyConfiguration is the policy of initial configurations without flags, either serve, reporting flagd as ready,
+	// not-ready, until a flag is loaded, or fail, stopping flagd once every source applied its initial configuration
+	EmptyConfiguration string
+	// ServeAfterStartup delays serving evaluations, and the probes, until every source has applied its initial
+	// configuration
+	ServeAfterStartup bool
+	// SourceDisconnectThreshold reports flagd as not ready once a remote source is unreachable for longer, while its
+	// last configuration keeps being served. Disconnected sources don't affect readiness when 0.
+	SourceDisconnectThreshold time.Duration
+	// StaleThreshold marks the resolutions served while a remote source is unreachable for longer with the stale
+	// metadata, as the configuration may be stale. Resolutions aren't marked when 0.
+	StaleThreshold time.Duration
+	// ConfigAgeMetadata adds the load time and age of the served configuration to the resolution metadata, so
+	// clients can detect when flagd hasn't reloaded it recently
+	ConfigAgeMetadata bool
+	// UnknownReasons is the policy of evaluation reasons which aren't part of the flagd schema, either normalize,
+	// responding UNKNOWN, or pass-through
+	UnknownReasons string
+	// UnsupportedContextValues is the policy of evaluation context values which aren't representable as json, either
+	// drop, evaluating the context without them, or error, rejecting the request
+	UnsupportedContextValues string
+	// EmptyFlagKeys is the policy of resolve requests whose flag key is empty or only whitespace, either reject,
+	// responding an invalid argument error, or not-found, evaluating them
+	EmptyFlagKeys string
+	// MaxContextValueBytes bounds the size of each evaluation context value, unbounded when 0. Larger values are
+	// handled by the OversizedContextValues policy, either error, rejecting the request, or drop, evaluating the
+	// context without them.
+	MaxContextValueBytes   int
+	OversizedContextValues string
+	// DisabledFlags is the policy of disabled flags in ResolveAll responses, either include, responding them with the
+	// DISABLED reason, or omit
+	DisabledFlags string
+	// TargetingKeyFallback is the targeting key of evaluation contexts without one, either none, uuid, generating one
+	// per request, or context:<key>, the value of a context key
+	TargetingKeyFallback string
+	// ReadReplicaRPCs lists the RPCs served from a read replica of the flag store, among bulk, list and what-if,
+	// refreshed once older than ReadReplicaStaleness. RPCs are served from the live flags when the staleness is 0.
+	ReadReplicaRPCs      []string
+	ReadReplicaStaleness time.Duration
+	// StrictContextConversion rejects evaluation contexts holding any value requiring a lossy conversion, i.e.
+	// unsupported values and integers beyond 2^53, rather than evaluating a degraded context
+	StrictContextConversion bool
+	// OverrideTokenSecret verifies the signed override tokens of evaluation contexts pinning flags to variants,
+	// override tokens are ignored when empty
+	OverrideTokenSecret string
+	// PinnedFlags lists the flags whose stored definition is kept when a reload changes them, until their pending
+	// definition is applied through the admin API
+	PinnedFlags []string
+	// AllowedContextKeys lists the context keys targeting rules may reference, along with their nested paths,
+	// configurations referencing other keys are rejected. Any key may be referenced when empty.
+	AllowedContextKeys []string
+	// NotFoundGracePeriod reports flags which aren't defined as not ready rather than not found for the period
+	// following their first request, so a source about to define them can sync. It's disabled when 0.
+	NotFoundGracePeriod time.Duration
+	// FallbackChain lists the levels of the chain resolving flags among override, targeting, flag-default,
+	// client-default and zero-value, they apply in this order. It's override, targeting and flag-default when empty.
+	FallbackChain []string
+	// VerboseFlags lists the flags whose evaluations are logged at the debug level whatever the log level, along
+	// with their full evaluation context
+	VerboseFlags []string
+	// VariantDistributionWindow is the window over which the returned variants of each flag are counted, exposed by
+	// the admin API and as metrics. Variants aren't counted when 0.
+	VariantDistributionWindow time.Duration
+	// MetricsStreamInterval is the interval of the snapshots of the metrics stream of the evaluation service, it
+	// isn't served when 0
+	MetricsStreamInterval time.Duration
+	// MetricsDroppedFlagKeys lists the flags whose metrics are recorded without the flag_key label, aggregated
+	// together, and MetricsMaxReasons caps the distinct reason labels of the flag evaluations, e.g. to bound the
+	// cardinality of high-cardinality flags
+	MetricsDroppedFlagKeys []string
+	MetricsMaxReasons      int
+	// EvaluationWebhookURL is the url successful evaluations are posted to, in batches of up to
+	// EvaluationWebhookBatch evaluations at least every EvaluationWebhookInterval. Evaluations aren't posted when
+	// empty.
+	EvaluationWebhookURL      string
+	EvaluationWebhookBatch    int
+	EvaluationWebhookInterval time.Duration
+	// SignaturePublicKeyPath is the ed25519 public key verifying the detached signatures of the configurations of file
+	// and HTTP sources, unsigned configurations are loaded when empty
+	SignaturePublicKeyPath string
+
+	// OtelCollectorURI is the gRPC endpoint of the OpenTelemetry collector the traces of evaluations are exported to,
+	// evaluations aren't traced when empty
+	OtelCollectorURI string
+	// TraceSamplingRates are the fractions of the evaluations of flags which are traced, keyed by flag, taking
+	// precedence over the traceSampling of the flags. Evaluations of flags without a rate are traced at
+	// DefaultTraceSampling, and evaluations whose caller propagates a sampling decision follow it.
+	TraceSamplingRates   map[string]string
+	DefaultTraceSampling float64
+
+	// LogLevels are the log levels of the subsystems, keyed by subsystem: sync, evaluation, server or audit. The
+	// subsystems whose level isn't set log at the level of the logger.
+	LogLevels map[string]string
+	// AccessLog logs a line per RPC and per request to the http endpoints, written apart from the other logs in the
+	// AccessLogFormat, either json or console
+	AccessLog       bool
+	AccessLogFormat string
+
+	// CanarySyncProviders are the sources of a candidate configuration serving CanaryPercentage of the evaluations,
+	// the candidate is promoted after CanarySoakPeriod, if set, or when flagd receives the promotion signal
+	CanarySyncProviders []sync.SourceConfig
+	CanaryPercentage    int
+	CanarySoakPeriod    time.Duration
+
+	// TenantSyncProviders are the sources of the configurations of tenants, keyed by tenant. Evaluations are served by
+	// the configuration of the tenant identified by the TenantContextKey value of their evaluation context, falling
+	// back to the configuration of SyncProviders.
+	TenantSyncProviders map[string][]sync.SourceConfig
+	TenantContextKey    string
 }
 
-// nolint: funlen
 func (r *Runtime) Start() error {
 	if r.Service == nil {
 		return errors.New("no service set")
@@ -51,67 +319,171 @@ func (r *Runtime) Start() error {
 	}
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	return r.run(ctx)
+}
+
+// run syncs the sources and serves evaluations until the context is done
+// nolint: funlen
+func (r *Runtime) run(ctx context.Context) error {
 	g, gCtx := errgroup.WithContext(ctx)
-	dataSync := make(chan sync.DataSync, len(r.SyncImpl))
+	if err := r.startSyncs(gCtx, g, r.SyncImpl, r.updateWithNotify); err != nil {
+		return err
+	}
+	if r.Canary != nil {
+		if err := r.startSyncs(gCtx, g, r.CanarySyncImpl, r.updateCandidateWithNotify); err != nil {
+			return err
+		}
+		r.startCanaryPromotion(gCtx)
+	}
+	for tenant, syncImpl := range r.TenantSyncImpl {
+		if err := r.startSyncs(gCtx, g, syncImpl, r.updateTenantWithNotify(tenant)); err != nil {
+			return err
+		}
+	}
+	r.startFreezeToggle(gCtx)
+	r.startReloadSignal(gCtx)
+	if r.emptyConfiguration == EmptyConfigurationFail {
+		g.Go(func() error {
+			return r.awaitFlags(gCtx)
+		})
+	}
+	summaryTimer := r.logStartupSummaryAfterTimeout()
+	defer summaryTimer.Stop()
+	g.Go(func() error {
+		if r.config.ServeAfterStartup {
+			r.Logger.Info("waiting for every flag source to apply its initial configuration before serving")
+			if !r.awaitStartup(gCtx) {
+				return nil
+			}
+		}
+		return r.Service.Serve(gCtx, r.evaluator(), service.Configuration{
+			ReadinessProbe: r.isReady,
+			StartupProbe:   r.startupComplete,
+			StaleProbe:     r.staleProbe(),
+			ConfigLoaded:   r.configLoadedProbe(),
+			Resync:         r.resync,
+			SourceStatuses: r.sourceStatuses,
+			Port:           r.config.ServicePort,
+			MetricsPort:    r.config.MetricsPort,
+			ServiceName:    r.serviceName,
+		})
+	})
+	<-gCtx.Done()
+	defer r.shutdownTracerProvider()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// startSyncs initializes and starts the sync providers, applying their data through update
+func (r *Runtime) startSyncs(
+	gCtx context.Context, g *errgroup.Group, syncImpl []sync.ISync, update func(sync.DataSync) bool,
+) error {
+	dataSync := make(chan sync.DataSync, len(syncImpl))
+	resync := make(chan struct{}, 1)
+	r.mu.Lock()
+	r.resyncs = append(r.resyncs, resync)
+	r.mu.Unlock()
+	resyncAll := func() {
+		for _, s := range syncImpl {
+			p := s
+			go func() {
+				g.Go(func() error {
+					return p.ReSync(gCtx, dataSync)
+				})
+			}()
+		}
+	}
+	apply := func(data sync.DataSync) {
+		if r.hold(data, dataSync) {
+			return
+		}
+		// resync events are triggered when a delete occurs during flag merges in the store
+		// resync events may trigger further resync events, however for a flag to be deleted from the store
+		// its source must match, preventing the opportunity for resync events to snowball
+		resyncRequired := update(data)
+		r.scheduleFlapRelease(gCtx, data, dataSync)
+		if resyncRequired {
+			resyncAll()
+		}
+	}
+	// the updates held while the configuration was frozen are replayed before any update received after it
+	replay := func() {
+		for _, data := range r.takeReplay(dataSync) {
+			apply(data)
+		}
+	}
+	replayWakeup := r.replayWakeup(dataSync)
 	// Initialize DataSync channel watcher
 	g.Go(func() error {
 		for {
 			select {
+			case <-replayWakeup:
+				replay()
 			case data := <-dataSync:
-				// resync events are triggered when a delete occurs during flag merges in the store
-				// resync events may trigger further resync events, however for a flag to be deleted from the store
-				// its source must match, preventing the opportunity for resync events to snowball
-				if resyncRequired := r.updateWithNotify(data); resyncRequired {
-					for _, s := range r.SyncImpl {
-						p := s
-						go func() {
-							g.Go(func() error {
-								return p.ReSync(gCtx, dataSync)
-							})
-						}()
-					}
-				}
+				replay()
+				apply(data)
+			case <-resync:
+				resyncAll()
 			case <-gCtx.Done():
 				return nil
 			}
 		}
 	})
 	// Init sync providers
-	for _, s := range r.SyncImpl {
+	for _, s := range syncImpl {
 		if err := s.Init(gCtx); err != nil {
 			return err
 		}
 	}
 	// Start sync provider
-	for _, s := range r.SyncImpl {
+	for _, s := range syncImpl {
 		p := s
 		g.Go(func() error {
 			return p.Sync(gCtx, dataSync)
 		})
 	}
-	g.Go(func() error {
-		return r.Service.Serve(gCtx, r.Evaluator, service.Configuration{
-			ReadinessProbe: r.isReady,
-			Port:           r.config.ServicePort,
-			MetricsPort:    r.config.MetricsPort,
-			ServiceName:    r.serviceName,
-		})
-	})
-	<-gCtx.Done()
-	if err := g.Wait(); err != nil {
-		return err
-	}
 	return nil
 }
 
+// resync requests the resync of the whole configuration of every source, requests made while a resync is pending
+// are coalesced
+func (r *Runtime) resync() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, resync := range r.resyncs {
+		select {
+		case resync <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (r *Runtime) isReady() bool {
+	if r.config.StartupReadiness && !r.startupComplete() {
+		return false
+	}
+	if r.emptyConfiguration == EmptyConfigurationNotReady && !r.flagsLoaded.Load() {
+		return false
+	}
 	// if all providers can watch for flag changes, we are ready.
-	for _, p := range r.SyncImpl {
+	syncImpl := r.syncImpls()
+	for _, p := range syncImpl {
 		if !p.IsReady() {
 			return false
 		}
 	}
-	return true
+	return !r.disconnected(syncImpl)
+}
+
+// syncImpls returns the sync providers of the configuration, the candidate and the tenants
+func (r *Runtime) syncImpls() []sync.ISync {
+	syncImpl := append(append([]sync.ISync{}, r.SyncImpl...), r.CanarySyncImpl...)
+	for _, tenantSyncImpl := range r.TenantSyncImpl {
+		syncImpl = append(syncImpl, tenantSyncImpl...)
+	}
+	return syncImpl
 }
 
 // updateWithNotify helps to update state and notify listeners
@@ -119,11 +491,18 @@ func (r *Runtime) updateWithNotify(payload sync.DataSync) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	notifications, resyncRequired, err := r.Evaluator.SetState(payload)
+	notifications, resyncRequired, err := r.evaluator().SetState(payload)
 	if err != nil {
 		r.Logger.Error(err.Error())
+		r.recordSync(payload.Source, err)
 		return false
 	}
+	r.applyToSwap(payload)
+	r.recordSync(payload.Source, nil)
+	r.markSynced(payload.Source)
+	r.markConfigLoaded()
+	r.markStarted(payload.Source)
+	r.markFlagsLoaded()
 
 	r.Service.Notify(service.Notification{
 		Type: service.ConfigurationChange,
@@ -134,3 +513,15 @@ func (r *Runtime) updateWithNotify(payload sync.DataSync) bool {
 
 	return resyncRequired
 }
+
+// recordSync tracks the outcome of an update from a source
+func (r *Runtime) recordSync(source string, err error) {
+	if r.sourceStatuses == nil {
+		return
+	}
+	if err != nil {
+		r.sourceStatuses.RecordError(source, err, time.Now())
+		return
+	}
+	r.sourceStatuses.RecordSync(source, time.Now())
+}
@@ -140,6 +140,7 @@ func TestSyncProvidersFromURIs(t *testing.T) {
 				"https://test.com",
 				"grpc://host:port",
 				"core.openfeature.dev/default/my-crd",
+				"consul://host:8500/flagd/flags",
 			},
 			expectErr: false,
 			out: []sync.SourceConfig{
@@ -159,8 +160,32 @@ func TestSyncProvidersFromURIs(t *testing.T) {
 					URI:      "default/my-crd",
 					Provider: "kubernetes",
 				},
+				{
+					URI:      "consul://host:8500/flagd/flags",
+					Provider: "consul",
+				},
 			},
 		},
+		"oci": {
+			in:        []string{"oci://registry.example.com/flags/payments:v1"},
+			expectErr: false,
+			out:       []sync.SourceConfig{{URI: "oci://registry.example.com/flags/payments:v1", Provider: "oci"}},
+		},
+		"consul over https": {
+			in:        []string{"consul+https://consul.example.com:8501/flagd/flags"},
+			expectErr: false,
+			out:       []sync.SourceConfig{{URI: "consul+https://consul.example.com:8501/flagd/flags", Provider: "consul"}},
+		},
+		"s3": {
+			in:        []string{"s3://my-bucket/flagd/flags.json"},
+			expectErr: false,
+			out:       []sync.SourceConfig{{URI: "s3://my-bucket/flagd/flags.json", Provider: "s3"}},
+		},
+		"stdin": {
+			in:        []string{"stdin"},
+			expectErr: false,
+			out:       []sync.SourceConfig{{URI: "stdin", Provider: "stdin"}},
+		},
 		"empty": {
 			in:        []string{},
 			expectErr: false,
@@ -189,3 +214,61 @@ func TestSyncProvidersFromURIs(t *testing.T) {
 		})
 	}
 }
+
+func TestTenantSyncProvidersFromURIs(t *testing.T) {
+	test := map[string]struct {
+		in        []string
+		expectErr bool
+		out       map[string][]sync.SourceConfig
+	}{
+		"tenants": {
+			in: []string{
+				"tenantA=file:tenant-a.json",
+				"tenantB=https://test.com/tenant-b",
+				"tenantA=file:tenant-a-overrides.json",
+			},
+			out: map[string][]sync.SourceConfig{
+				"tenantA": {
+					{URI: "tenant-a.json", Provider: "file"},
+					{URI: "tenant-a-overrides.json", Provider: "file"},
+				},
+				"tenantB": {
+					{URI: "https://test.com/tenant-b", Provider: "http"},
+				},
+			},
+		},
+		"empty": {
+			in:  []string{},
+			out: map[string][]sync.SourceConfig{},
+		},
+		"missing tenant": {
+			in:        []string{"file:tenant-a.json"},
+			expectErr: true,
+		},
+		"empty tenant": {
+			in:        []string{"=file:tenant-a.json"},
+			expectErr: true,
+		},
+		"parse-failure": {
+			in:        []string{"tenantA=care.openfeature.dev/will/fail"},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range test {
+		t.Run(name, func(t *testing.T) {
+			out, err := runtime.TenantSyncProvidersFromURIs(tt.in)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got none")
+				}
+				return
+			} else if err != nil {
+				t.Errorf("did not expect error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(out, tt.out) {
+				t.Errorf("unexpected output, expected %v, got %v", tt.out, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+)
+
+// markStarted records the initial configuration of a source being applied, its validation and the warmup of its
+// targeting rules included, completing the startup once every source of the configuration, the candidate and the
+// tenants has applied its own. The caller must hold r.mu.
+func (r *Runtime) markStarted(source string) {
+	if r.startedSources == nil {
+		r.startedSources = map[string]struct{}{}
+	}
+	r.startedSources[source] = struct{}{}
+	if len(r.startedSources) < len(r.syncImpls()) {
+		return
+	}
+	started := r.startedChan()
+	select {
+	case <-started:
+	default:
+		r.Logger.Info(fmt.Sprintf("startup complete, %d flag source(s) applied their initial configuration",
+			len(r.startedSources)))
+		close(started)
+	}
+}
+
+// startedChan returns the channel closed once the startup completes. The caller must hold r.mu.
+func (r *Runtime) startedChan() chan struct{} {
+	if r.started == nil {
+		r.started = make(chan struct{})
+	}
+	return r.started
+}
+
+// startupComplete reports whether every source has applied its initial configuration
+func (r *Runtime) startupComplete() bool {
+	r.mu.Lock()
+	started := r.startedChan()
+	r.mu.Unlock()
+	select {
+	case <-started:
+		return true
+	default:
+		return false
+	}
+}
+
+// awaitStartup blocks until the startup completes, returning false if the context is done first
+func (r *Runtime) awaitStartup(ctx context.Context) bool {
+	r.mu.Lock()
+	started := r.startedChan()
+	r.mu.Unlock()
+	select {
+	case <-started:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startupSummaryTimeout bounds the wait for the initial state of every source before the summary is logged
+const startupSummaryTimeout = 10 * time.Second
+
+// markSynced records the initial sync of a source, logging the startup summary once every source has synced.
+// The caller must hold r.mu.
+func (r *Runtime) markSynced(source string) {
+	if r.syncedSources == nil {
+		r.syncedSources = map[string]struct{}{}
+	}
+	r.syncedSources[source] = struct{}{}
+	if len(r.syncedSources) >= len(r.SyncImpl) {
+		r.summaryOnce.Do(r.logStartupSummary)
+	}
+}
+
+// logStartupSummaryAfterTimeout logs the startup summary if some sources haven't synced within the timeout
+func (r *Runtime) logStartupSummaryAfterTimeout() *time.Timer {
+	return time.AfterFunc(startupSummaryTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.summaryOnce.Do(r.logStartupSummary)
+	})
+}
+
+// logStartupSummary emits a single entry describing the started configuration. The caller must hold r.mu.
+func (r *Runtime) logStartupSummary() {
+	address := fmt.Sprintf(":%d", r.config.ServicePort)
+	switch {
+	case r.config.ServiceSocketPath != "" && r.config.ServiceSocketWithPort:
+		address = fmt.Sprintf("%s, :%d", r.config.ServiceSocketPath, r.config.ServicePort)
+	case r.config.ServiceSocketPath != "":
+		address = r.config.ServiceSocketPath
+	}
+
+	sources := make([]string, 0, len(r.config.SyncProviders))
+	pending := []string{}
+	for _, provider := range r.config.SyncProviders {
+		sources = append(sources, provider.URI)
+		if _, ok := r.syncedSources[provider.URI]; !ok {
+			pending = append(pending, provider.URI)
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("address", address),
+		zap.Bool("tls", r.config.ServiceCertPath != "" && r.config.ServiceKeyPath != ""),
+		zap.Uint16("metrics-port", r.config.MetricsPort),
+		zap.Int("flags", r.flagCount()),
+		zap.Strings("sources", sources),
+		zap.Strings("pending-sources", pending),
+		zap.Strings("cors-origins", r.config.CORS),
+		zap.Strings("disabled-resolve-types", r.config.DisabledResolveTypes),
+		zap.Strings("features", r.enabledFeatures()),
+	}
+	if r.Canary != nil {
+		fields = append(fields, zap.Int("canary-percentage", r.Canary.Percentage()))
+	}
+	if r.Tenants != nil {
+		fields = append(fields, zap.Int("tenants", len(r.TenantSyncImpl)))
+	}
+	r.Logger.Info("flagd started", fields...)
+}
+
+// enabledFeatures returns the optional features of the started configuration, metrics being always served on the
+// metrics port
+func (r *Runtime) enabledFeatures() []string {
+	features := []string{"metrics"}
+	for _, feature := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"metrics-stream", r.config.MetricsStreamInterval > 0},
+		{"tracing", r.config.OtelCollectorURI != ""},
+		{"grpc-web", !r.config.DisableGRPCWeb},
+		{"admin-api", r.config.EnableAdminAPI},
+		{"auth", len(r.config.AuthTokens) > 0},
+		{"access-log", r.config.AccessLog},
+		{"evaluation-webhook", r.config.EvaluationWebhookURL != ""},
+		{"override-tokens", r.config.OverrideTokenSecret != ""},
+		{"signature-verification", r.config.SignaturePublicKeyPath != ""},
+		{"store-compression", r.config.StoreCompression},
+		{"rule-statistics", r.config.RuleStatistics},
+	} {
+		if feature.enabled {
+			features = append(features, feature.name)
+		}
+	}
+	return features
+}
+
+func (r *Runtime) flagCount() int {
+	state, err := r.evaluator().GetState()
+	if err != nil {
+		return 0
+	}
+	var flags struct {
+		Flags map[string]json.RawMessage `json:"flags"`
+	}
+	if err := json.Unmarshal([]byte(state), &flags); err != nil {
+		return 0
+	}
+	return len(flags.Flags)
+}
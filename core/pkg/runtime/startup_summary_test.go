@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type noopService struct{}
+
+func (noopService) Serve(context.Context, eval.IEvaluator, service.Configuration) error { return nil }
+
+func (noopService) Notify(service.Notification) {}
+
+func TestStartupSummary(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := logger.NewLogger(zap.New(core), false)
+	r := Runtime{
+		config: Config{
+			ServicePort:     8013,
+			MetricsPort:     8014,
+			ServiceCertPath: "cert.pem",
+			ServiceKeyPath:  "key.pem",
+			EnableAdminAPI:  true,
+			DisableGRPCWeb:  true,
+			SyncProviders: []sync.SourceConfig{
+				{URI: "a.json", Provider: syncProviderFile},
+				{URI: "b.json", Provider: syncProviderFile},
+			},
+		},
+		Logger:    log,
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   noopService{},
+		SyncImpl:  make([]sync.ISync, 2),
+	}
+
+	r.updateWithNotify(sync.DataSync{Source: "a.json", Type: sync.ALL, FlagData: `{
+  "flags": {
+    "a": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" },
+    "b": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`})
+	require.Equal(t, 0, logs.FilterMessage("flagd started").Len(), "summary logged before every source synced")
+
+	r.updateWithNotify(sync.DataSync{Source: "b.json", Type: sync.ALL, FlagData: `{
+  "flags": {
+    "c": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`})
+	r.updateWithNotify(sync.DataSync{Source: "b.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+
+	entries := logs.FilterMessage("flagd started").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, ":8013", fields["address"])
+	require.Equal(t, true, fields["tls"])
+	require.Equal(t, int64(3), fields["flags"])
+	require.Equal(t, []interface{}{"a.json", "b.json"}, fields["sources"])
+	require.Equal(t, []interface{}{}, fields["pending-sources"])
+	require.Equal(t, []interface{}{"metrics", "admin-api"}, fields["features"])
+}
+
+func TestUpdateRecordsSourceStatus(t *testing.T) {
+	r := Runtime{
+		Logger:         logger.NewLogger(nil, false),
+		Evaluator:      eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:        noopService{},
+		sourceStatuses: sync.NewSourceStatuses("a.json"),
+	}
+
+	r.updateWithNotify(sync.DataSync{Source: "a.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+	r.updateWithNotify(sync.DataSync{Source: "a.json", Type: sync.ALL, FlagData: `{"flags": `})
+
+	statuses := r.sourceStatuses.Snapshot()
+	require.Len(t, statuses, 1)
+	require.Equal(t, int64(1), statuses[0].Updates)
+	require.Equal(t, int64(1), statuses[0].Errors)
+	require.False(t, statuses[0].LastSync.IsZero())
+	require.NotEmpty(t, statuses[0].LastError)
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupReadiness(t *testing.T) {
+	r := Runtime{
+		config:    Config{StartupReadiness: true},
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags(), eval.WithRuleWarmup(true)),
+		Service:   noopService{},
+		SyncImpl:  []sync.ISync{&chanSync{}, &chanSync{}},
+	}
+	require.False(t, r.isReady(), "sources which haven't applied their configuration shouldn't be ready")
+	require.False(t, r.startupComplete())
+
+	r.updateWithNotify(sync.DataSync{Source: "a.json", Type: sync.ALL, FlagData: fmt.Sprintf(freezeFlagConfig, "red")})
+	require.False(t, r.isReady(), "the startup shouldn't complete before every source applied its configuration")
+
+	r.updateWithNotify(sync.DataSync{Source: "b.json", Type: sync.ALL, FlagData: `{"flags": `})
+	require.False(t, r.isReady(), "configurations failing validation shouldn't complete the startup")
+
+	r.updateWithNotify(sync.DataSync{Source: "b.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+	require.True(t, r.isReady())
+	require.True(t, r.startupComplete())
+
+	r.updateWithNotify(sync.DataSync{Source: "b.json", Type: sync.ALL, FlagData: `{"flags": {}}`})
+	require.True(t, r.startupComplete(), "the startup should stay complete")
+
+	r.config.StartupReadiness = false
+	r.startedSources, r.started = nil, nil
+	require.True(t, r.isReady(), "readiness shouldn't wait for the startup unless configured to")
+}
+
+// startedService records the time the runtime starts serving
+type startedService struct {
+	noopService
+	serving chan service.Configuration
+}
+
+func (s startedService) Serve(ctx context.Context, _ eval.IEvaluator, conf service.Configuration) error {
+	s.serving <- conf
+	<-ctx.Done()
+	return nil
+}
+
+func TestServeAfterStartup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &chanSync{updates: make(chan sync.DataSync)}
+	svc := startedService{serving: make(chan service.Configuration, 1)}
+	r := Runtime{
+		config:    Config{ServeAfterStartup: true},
+		Logger:    logger.NewLogger(nil, false),
+		Evaluator: eval.NewJSONEvaluator(nil, store.NewFlags()),
+		Service:   svc,
+		SyncImpl:  []sync.ISync{source},
+	}
+	started := make(chan error)
+	go func() { started <- r.run(ctx) }()
+
+	select {
+	case <-svc.serving:
+		t.Fatal("evaluations shouldn't be served before the startup completes")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	source.updates <- sync.DataSync{Source: "flags.json", Type: sync.ALL, FlagData: fmt.Sprintf(freezeFlagConfig, "red")}
+	select {
+	case conf := <-svc.serving:
+		require.True(t, conf.StartupProbe())
+	case <-time.After(time.Second):
+		t.Fatal("evaluations should be served once the startup completes")
+	}
+
+	cancel()
+	require.Nil(t, <-started)
+}
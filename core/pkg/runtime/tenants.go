@@ -0,0 +1,54 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// TenantSyncProvidersFromURIs parses tenant=uri values into the sources of each tenant, a tenant may have several
+// sources
+func TenantSyncProvidersFromURIs(values []string) (map[string][]sync.SourceConfig, error) {
+	tenantSources := map[string][]sync.SourceConfig{}
+	for _, value := range values {
+		tenant, uri, ok := strings.Cut(value, "=")
+		if !ok || tenant == "" || uri == "" {
+			return nil, fmt.Errorf("invalid tenant sync uri argument: %s, must be of the form tenant=uri", value)
+		}
+		sources, err := SyncProvidersFromURIs([]string{uri})
+		if err != nil {
+			return nil, err
+		}
+		tenantSources[tenant] = append(tenantSources[tenant], sources...)
+	}
+	return tenantSources, nil
+}
+
+// updateTenantWithNotify returns the update of the configuration of the tenant, notifying listeners
+func (r *Runtime) updateTenantWithNotify(tenant string) func(payload sync.DataSync) bool {
+	return func(payload sync.DataSync) bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		notifications, resyncRequired, err := r.Tenants.SetTenantState(tenant, payload)
+		if err != nil {
+			r.Logger.Error(fmt.Sprintf("configuration of tenant: %s: %v", tenant, err))
+			r.recordSync(payload.Source, err)
+			return false
+		}
+		r.recordSync(payload.Source, nil)
+		r.markStarted(tenant + "/" + payload.Source)
+		r.markConfigLoaded()
+
+		r.Service.Notify(service.Notification{
+			Type: service.ConfigurationChange,
+			Data: map[string]interface{}{
+				"flags": notifications,
+			},
+		})
+
+		return resyncRequired
+	}
+}
@@ -0,0 +1,73 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/otel"
+)
+
+// tracerShutdownTimeout bounds the export of the spans still batched when the runtime stops
+const tracerShutdownTimeout = 5 * time.Second
+
+// parseTraceSamplingRates parses the trace sampling rates keyed by flag, fractions between 0 and 1
+func parseTraceSamplingRates(rates map[string]string) (map[string]float64, error) {
+	parsed := make(map[string]float64, len(rates))
+	for flagKey, value := range rates {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("trace sampling rate: '%s' of flag: '%s' isn't between 0 and 1", value, flagKey)
+		}
+		parsed[flagKey] = rate
+	}
+	return parsed, nil
+}
+
+// setTracerProvider traces the evaluations to the configured collector, sampling them by the rate configured for
+// their flag by the server, or by the flag itself, and at the default rate otherwise. Evaluations aren't traced
+// without a collector.
+func (r *Runtime) setTracerProvider(rates map[string]float64) error {
+	if r.config.OtelCollectorURI == "" {
+		return nil
+	}
+	if rate := r.config.DefaultTraceSampling; rate < 0 || rate > 1 {
+		return fmt.Errorf("default trace sampling rate: %g isn't between 0 and 1", rate)
+	}
+	sampler := otel.NewEvaluationSampler(r.flagSamplingRates(rates), r.config.DefaultTraceSampling)
+	provider, err := otel.NewTracerProvider(context.Background(), r.config.OtelCollectorURI, r.serviceName, sampler)
+	if err != nil {
+		return err
+	}
+	r.tracerProvider = provider
+	return nil
+}
+
+// flagSamplingRates returns the trace sampling rates of flags, the rates configured by the server taking precedence
+// over the rates configured by the flags
+func (r *Runtime) flagSamplingRates(rates map[string]float64) otel.FlagSamplingRates {
+	return func(flagKey string) (float64, bool) {
+		if rate, ok := rates[flagKey]; ok {
+			return rate, true
+		}
+		sampling, ok := r.evaluator().(eval.TraceSampling)
+		if !ok {
+			return 0, false
+		}
+		return sampling.TraceSamplingRate(flagKey)
+	}
+}
+
+// shutdownTracerProvider exports the spans still batched by the tracer provider, if evaluations are traced
+func (r *Runtime) shutdownTracerProvider() {
+	if r.tracerProvider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+	defer cancel()
+	if err := r.tracerProvider.Shutdown(ctx); err != nil {
+		r.Logger.Warn(fmt.Sprintf("exporting the remaining spans: %v", err))
+	}
+}
@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceSamplingRates(t *testing.T) {
+	rates, err := parseTraceSamplingRates(map[string]string{"highVolumeFlag": "0.01", "rareFlag": "1"})
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{"highVolumeFlag": 0.01, "rareFlag": 1}, rates)
+
+	_, err = parseTraceSamplingRates(map[string]string{"highVolumeFlag": "1%"})
+	require.EqualError(t, err, "trace sampling rate: '1%' of flag: 'highVolumeFlag' isn't between 0 and 1")
+	_, err = parseTraceSamplingRates(map[string]string{"highVolumeFlag": "2"})
+	require.EqualError(t, err, "trace sampling rate: '2' of flag: 'highVolumeFlag' isn't between 0 and 1")
+}
+
+func TestFlagSamplingRates(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "highVolumeFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on", "traceSampling": 0.1 },
+    "rareFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on", "traceSampling": 1 },
+    "otherFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	r := &Runtime{Evaluator: evaluator}
+	rates := r.flagSamplingRates(map[string]float64{"highVolumeFlag": 0.01})
+
+	rate, ok := rates("highVolumeFlag")
+	require.True(t, ok)
+	require.Equal(t, 0.01, rate, "the rate of the server should take precedence over the rate of the flag")
+	rate, ok = rates("rareFlag")
+	require.True(t, ok)
+	require.Equal(t, float64(1), rate)
+	_, ok = rates("otherFlag")
+	require.False(t, ok, "flags without a rate should be sampled at the default rate")
+}
+
+func TestSetTracerProvider(t *testing.T) {
+	r := &Runtime{serviceName: svcName}
+	require.Nil(t, r.setTracerProvider(nil))
+	require.Nil(t, r.tracerProvider, "evaluations shouldn't be traced without a collector")
+
+	r.config = Config{OtelCollectorURI: "localhost:4317", DefaultTraceSampling: 1.5}
+	require.EqualError(t, r.setTracerProvider(nil), "default trace sampling rate: 1.5 isn't between 0 and 1")
+
+	r.config.DefaultTraceSampling = 0.5
+	require.Nil(t, r.setTracerProvider(nil))
+	require.NotNil(t, r.tracerProvider)
+	r.shutdownTracerProvider()
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const accessLogMessage = "access"
+
+// accessLog logs a line per RPC, and per request to the http endpoints served alongside, whatever its outcome: the
+// method, the flag key, the address and user agent of the client, the status and the duration. Lines are written to
+// the access logger only, apart from the evaluation and audit logs.
+type accessLog struct {
+	logger *logger.Logger
+}
+
+func (a accessLog) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		started := time.Now()
+		res, err := next(ctx, req)
+		var flagKey string
+		if keyed, ok := req.Any().(interface{ GetFlagKey() string }); ok {
+			flagKey = keyed.GetFlagKey()
+		}
+		a.log(req.Spec().Procedure, flagKey, req.Peer(), req.Header(), connectStatus(err), time.Since(started))
+		return res, err
+	}
+}
+
+func (a accessLog) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (a accessLog) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		started := time.Now()
+		err := next(ctx, conn)
+		a.log(conn.Spec().Procedure, "", conn.Peer(), conn.RequestHeader(), connectStatus(err), time.Since(started))
+		return err
+	}
+}
+
+// handler logs the requests to the http endpoints served alongside the connect handler, their status being the http
+// status of the response
+func (a accessLog) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		a.log(r.Method+" "+r.URL.Path, r.URL.Query().Get("flagKey"),
+			connect.Peer{Addr: r.RemoteAddr, Protocol: "http"}, r.Header, strconv.Itoa(recorder.status),
+			time.Since(started))
+	})
+}
+
+func (a accessLog) log(
+	method string, flagKey string, peer connect.Peer, header http.Header, status string, duration time.Duration,
+) {
+	fields := []zap.Field{zap.String("method", method)}
+	if flagKey != "" {
+		fields = append(fields, zap.String("flagKey", flagKey))
+	}
+	fields = append(fields,
+		zap.String("client", peer.Addr),
+		zap.String("protocol", peer.Protocol),
+		zap.String("userAgent", header.Get("User-Agent")),
+		zap.String("status", status),
+		zap.Duration("duration", duration),
+	)
+	a.logger.Info(accessLogMessage, fields...)
+}
+
+// connectStatus returns the name of the code of an RPC error, ok for RPCs which succeeded
+func connectStatus(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return connect.CodeOf(err).String()
+}
+
+// statusRecorder records the status of the response, flushing the writer it wraps for streamed responses
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func accessLogService(t *testing.T, config *ConnectServiceConfiguration) (*httptest.Server, *observer.ObservedLogs,
+	*observer.ObservedLogs,
+) {
+	t.Helper()
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	accessCore, accessLogs := observer.New(zap.InfoLevel)
+	auditCore, auditLogs := observer.New(zap.InfoLevel)
+	svc := ConnectService{
+		ConnectServiceConfiguration: config,
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		AuditLogger:                 logger.NewLogger(zap.New(auditCore), false),
+		AccessLogger:                logger.NewLogger(zap.New(accessCore), false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), "access-log"),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	t.Cleanup(server.Close)
+	return server, accessLogs, auditLogs
+}
+
+func TestAccessLog(t *testing.T) {
+	server, accessLogs, _ := accessLogService(t, &ConnectServiceConfiguration{})
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	_, err := client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+	require.Nil(t, err)
+	_, err = client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "unknown"}))
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	_, err = client.ResolveString(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveStringRequest{FlagKey: "myBoolFlag"}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	lines := accessLogs.All()
+	require.Len(t, lines, 3, "a line should be logged per resolve, whether it succeeded or not")
+	for i, want := range []struct {
+		method  string
+		flagKey string
+		status  string
+	}{
+		{method: "/schema.v1.Service/ResolveBoolean", flagKey: "myBoolFlag", status: "ok"},
+		{method: "/schema.v1.Service/ResolveBoolean", flagKey: "unknown", status: "not_found"},
+		{method: "/schema.v1.Service/ResolveString", flagKey: "myBoolFlag", status: "invalid_argument"},
+	} {
+		fields := lines[i].ContextMap()
+		require.Equal(t, accessLogMessage, lines[i].Message)
+		require.Equal(t, want.method, fields["method"])
+		require.Equal(t, want.flagKey, fields["flagKey"])
+		require.Equal(t, want.status, fields["status"])
+		require.Equal(t, connect.ProtocolConnect, fields["protocol"])
+		require.Contains(t, fields["userAgent"], "connect-go")
+		require.NotEmpty(t, fields["client"])
+		require.Contains(t, fields, "duration")
+	}
+}
+
+func TestAccessLog_HTTPEndpoints(t *testing.T) {
+	server, accessLogs, auditLogs := accessLogService(t, &ConnectServiceConfiguration{EnableAdminAPI: true})
+
+	res, err := http.Post(server.URL+ResolveAnyPath, "application/json", strings.NewReader(`{"flagKey":"unknown"}`))
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	res, err = http.Post(server.URL+CacheFlushPath, "application/json", nil)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	lines := accessLogs.All()
+	require.Len(t, lines, 2)
+	require.Equal(t, "POST "+ResolveAnyPath, lines[0].ContextMap()["method"])
+	require.Equal(t, "404", lines[0].ContextMap()["status"])
+	require.Equal(t, "POST "+CacheFlushPath, lines[1].ContextMap()["method"])
+	require.Equal(t, "200", lines[1].ContextMap()["status"])
+
+	require.Equal(t, 1, auditLogs.Len(), "the flush should be audited once")
+	require.Zero(t, auditLogs.FilterMessage(accessLogMessage).Len(), "requests shouldn't be logged to the audit log")
+}
+
+func TestAccessLog_Unauthenticated(t *testing.T) {
+	server, accessLogs, _ := accessLogService(t, &ConnectServiceConfiguration{AuthTokens: []string{"secret"}})
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	_, err := client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+	require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	res, err := http.Post(server.URL+ResolveAnyPath, "application/json", strings.NewReader(`{"flagKey":"myBoolFlag"}`))
+	require.Nil(t, err)
+	res.Body.Close()
+
+	lines := accessLogs.All()
+	require.Len(t, lines, 2, "rejected requests should be logged")
+	require.Equal(t, "unauthenticated", lines[0].ContextMap()["status"])
+	require.Equal(t, "401", lines[1].ContextMap()["status"])
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/bufbuild/connect-go"
+)
+
+// evaluationAdmission tracks the unary evaluations in flight and queued waiting for a slot. Evaluations are only
+// queued when the concurrent evaluations are bounded, they're shed with codes.ResourceExhausted once the queue is
+// full.
+type evaluationAdmission struct {
+	// slots bounds the concurrent evaluations, unbounded when nil
+	slots     chan struct{}
+	maxQueued int64
+	inFlight  atomic.Int64
+	queued    atomic.Int64
+	// backoff suggests the retry delay of shed evaluations
+	backoff retryBackoff
+}
+
+// newEvaluationAdmission bounds the concurrent evaluations to maxConcurrent and the queued ones to maxQueued,
+// either is unbounded when 0
+func newEvaluationAdmission(maxConcurrent int, maxQueued int) *evaluationAdmission {
+	a := &evaluationAdmission{maxQueued: int64(maxQueued)}
+	if maxConcurrent > 0 {
+		a.slots = make(chan struct{}, maxConcurrent)
+	}
+	return a
+}
+
+// InFlight returns the number of evaluations being served
+func (a *evaluationAdmission) InFlight() int64 {
+	return a.inFlight.Load()
+}
+
+// Queued returns the number of evaluations waiting for a slot
+func (a *evaluationAdmission) Queued() int64 {
+	return a.queued.Load()
+}
+
+// admit waits for a slot for the evaluation, the returned func releases it. Evaluations are shed when the queue is
+// full, or their context is done while they're queued.
+func (a *evaluationAdmission) admit(ctx context.Context) (func(), error) {
+	release, err := a.acquire(ctx)
+	if err == nil {
+		a.backoff.reset()
+	}
+	return release, err
+}
+
+func (a *evaluationAdmission) acquire(ctx context.Context) (func(), error) {
+	if a.slots == nil {
+		a.inFlight.Add(1)
+		return a.release, nil
+	}
+	select {
+	case a.slots <- struct{}{}:
+		a.inFlight.Add(1)
+		return a.release, nil
+	default:
+	}
+
+	if queued := a.queued.Add(1); a.maxQueued > 0 && queued > a.maxQueued {
+		a.queued.Add(-1)
+		return nil, errors.New("evaluation queue is full")
+	}
+	defer a.queued.Add(-1)
+	select {
+	case a.slots <- struct{}{}:
+		a.inFlight.Add(1)
+		return a.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (a *evaluationAdmission) release() {
+	a.inFlight.Add(-1)
+	if a.slots != nil {
+		<-a.slots
+	}
+}
+
+// admissionInterceptor admits unary evaluations, shedding the ones which can't be queued with codes.ResourceExhausted
+// along with a retry delay growing with the evaluations shed
+func admissionInterceptor(admission *evaluationAdmission) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			release, err := admission.admit(ctx)
+			if err != nil {
+				shedErr := fmt.Errorf("%s, %s", ErrorPrefix, err.Error())
+				switch {
+				case errors.Is(err, context.DeadlineExceeded):
+					return nil, connect.NewError(connect.CodeDeadlineExceeded, shedErr)
+				case errors.Is(err, context.Canceled):
+					return nil, connect.NewError(connect.CodeCanceled, shedErr)
+				}
+				return nil, withRetryInfo(connect.NewError(connect.CodeResourceExhausted, shedErr), admission.backoff.next())
+			}
+			defer release()
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingEvaluation is an evaluation blocking until released
+func blockingEvaluation(release <-chan struct{}) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		<-release
+		return connect.NewResponse(&schemaV1.ResolveBooleanResponse{Value: true}), nil
+	}
+}
+
+func TestAdmissionInterceptor(t *testing.T) {
+	admission := newEvaluationAdmission(1, 1)
+	release := make(chan struct{})
+	evaluate := admissionInterceptor(admission)(blockingEvaluation(release))
+	req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+
+	results := make(chan error, 2)
+	go func() {
+		_, err := evaluate(context.Background(), req)
+		results <- err
+	}()
+	require.Eventually(t, func() bool { return admission.InFlight() == 1 }, time.Second, time.Millisecond)
+	go func() {
+		_, err := evaluate(context.Background(), req)
+		results <- err
+	}()
+	require.Eventually(t, func() bool { return admission.Queued() == 1 }, time.Second, time.Millisecond)
+
+	_, err := evaluate(context.Background(), req)
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err), "evaluations beyond the queue must be shed")
+	require.Equal(t, int64(1), admission.InFlight())
+	require.Equal(t, int64(1), admission.Queued())
+
+	release <- struct{}{}
+	require.Nil(t, <-results)
+	require.Eventually(t, func() bool { return admission.Queued() == 0 }, time.Second, time.Millisecond,
+		"the queued evaluation must be admitted once a slot is released")
+	require.Equal(t, int64(1), admission.InFlight())
+	release <- struct{}{}
+	require.Nil(t, <-results)
+	require.Equal(t, int64(0), admission.InFlight())
+}
+
+func TestAdmissionInterceptor_QueuedCancellation(t *testing.T) {
+	admission := newEvaluationAdmission(1, 0)
+	release := make(chan struct{})
+	defer close(release)
+	evaluate := admissionInterceptor(admission)(blockingEvaluation(release))
+	req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+
+	go func() {
+		_, _ = evaluate(context.Background(), req)
+	}()
+	require.Eventually(t, func() bool { return admission.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := evaluate(ctx, req)
+	require.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+	require.Equal(t, int64(0), admission.Queued(), "an evaluation timing out in the queue must leave it")
+}
+
+func TestAdmissionInterceptor_Unbounded(t *testing.T) {
+	admission := newEvaluationAdmission(0, 1)
+	release := make(chan struct{})
+	evaluate := admissionInterceptor(admission)(blockingEvaluation(release))
+	req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+
+	const evaluations = 5
+	results := make(chan error, evaluations)
+	for i := 0; i < evaluations; i++ {
+		go func() {
+			_, err := evaluate(context.Background(), req)
+			results <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return admission.InFlight() == evaluations }, time.Second, time.Millisecond,
+		"unbounded evaluations must all be in flight")
+	require.Equal(t, int64(0), admission.Queued())
+	close(release)
+	for i := 0; i < evaluations; i++ {
+		require.Nil(t, <-results)
+	}
+	require.Equal(t, int64(0), admission.InFlight())
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestBearerTokens(t *testing.T) {
+	tokens := newBearerTokens([]string{"current", "", "previous"})
+	require.Len(t, tokens, 2, "empty tokens shouldn't be accepted")
+
+	tests := map[string]struct {
+		authorization string
+		want          bool
+	}{
+		"valid token":           {authorization: "Bearer current", want: true},
+		"rotated token":         {authorization: "Bearer previous", want: true},
+		"case insensitive type": {authorization: "bearer current", want: true},
+		"invalid token":         {authorization: "Bearer random"},
+		"token prefix":          {authorization: "Bearer curr"},
+		"missing token":         {authorization: "Bearer "},
+		"missing type":          {authorization: "current"},
+		"basic auth":            {authorization: "Basic current"},
+		"missing header":        {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, tokens.valid(tt.authorization))
+		})
+	}
+}
+
+func TestConnectService_Auth(t *testing.T) {
+	tests := map[string]struct {
+		authTokens    []string
+		authorization string
+		wantCode      connect.Code
+		wantStatus    int
+	}{
+		"valid token": {
+			authTokens:    []string{"current", "previous"},
+			authorization: "Bearer current",
+			wantStatus:    http.StatusOK,
+		},
+		"rotated token": {
+			authTokens:    []string{"current", "previous"},
+			authorization: "Bearer previous",
+			wantStatus:    http.StatusOK,
+		},
+		"invalid token": {
+			authTokens:    []string{"current", "previous"},
+			authorization: "Bearer random",
+			wantCode:      connect.CodeUnauthenticated,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		"missing token": {
+			authTokens: []string{"current", "previous"},
+			wantCode:   connect.CodeUnauthenticated,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"auth disabled": {
+			wantStatus: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+			require.Nil(t, err)
+			svc := ConnectService{
+				ConnectServiceConfiguration: &ConnectServiceConfiguration{
+					AuthTokens:     tt.authTokens,
+					EnableAdminAPI: true,
+				},
+				Eval:    evaluator,
+				Logger:  logger.NewLogger(nil, false),
+				Metrics: otel.NewOTelRecorder(metric.NewManualReader(), name),
+				eventingConfiguration: &eventingConfiguration{
+					subs: make(map[interface{}]chan iservice.Notification),
+					mu:   &sync.RWMutex{},
+				},
+			}
+			server := httptest.NewServer(svc.serviceHandler())
+			defer server.Close()
+			client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+			req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+			if tt.authorization != "" {
+				req.Header().Set(authorizationHeader, tt.authorization)
+			}
+			_, err = client.ResolveBoolean(context.Background(), req)
+			if tt.wantCode == 0 {
+				require.Nil(t, err)
+			} else {
+				require.Equal(t, tt.wantCode, connect.CodeOf(err))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			streamReq := connect.NewRequest(&schemaV1.EventStreamRequest{})
+			if tt.authorization != "" {
+				streamReq.Header().Set(authorizationHeader, tt.authorization)
+			}
+			stream, err := client.EventStream(ctx, streamReq)
+			require.Nil(t, err)
+			if tt.wantCode == 0 {
+				require.True(t, stream.Receive())
+				require.Equal(t, string(iservice.ProviderReady), stream.Msg().Type)
+			} else {
+				require.False(t, stream.Receive())
+				require.Equal(t, tt.wantCode, connect.CodeOf(stream.Err()))
+			}
+			cancel()
+
+			for path, method := range map[string]string{
+				DeltaPath:                            http.MethodPost,
+				VariantsPath + "?flagKey=myBoolFlag": http.MethodGet,
+			} {
+				httpReq, err := http.NewRequest(method, server.URL+path, strings.NewReader("{}"))
+				require.Nil(t, err)
+				if tt.authorization != "" {
+					httpReq.Header.Set(authorizationHeader, tt.authorization)
+				}
+				res, err := server.Client().Do(httpReq)
+				require.Nil(t, err)
+				res.Body.Close()
+				require.Equal(t, tt.wantStatus, res.StatusCode, path)
+			}
+		})
+	}
+}
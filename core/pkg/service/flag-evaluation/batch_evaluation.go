@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	goruntime "runtime"
+	"sync"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// BatchEvaluationPath evaluates a flag against each context of a batch, e.g. to size an experiment on a list of
+	// users, or a list of flags each against its own context keyed by client-supplied slot IDs
+	BatchEvaluationPath = "/resolve-batch"
+	// MaxBatchContexts bounds the contexts, or the slots, of a batch evaluation
+	MaxBatchContexts = 1000
+	// MaxBatchRequestBytes bounds the size of batch evaluation requests
+	MaxBatchRequestBytes = 1 << 20
+)
+
+type batchEvaluationRequest struct {
+	FlagKey  string                   `json:"flagKey"`
+	Contexts []map[string]interface{} `json:"contexts"`
+	// Slots are resolved instead of FlagKey against Contexts, the results being keyed by their slot ID
+	Slots []batchEvaluationSlot `json:"slots,omitempty"`
+}
+
+// batchEvaluationSlot is a flag to resolve against a context, identified by a slot ID of the client so the same flag
+// may be resolved in several slots
+type batchEvaluationSlot struct {
+	SlotID  string                 `json:"slotId"`
+	FlagKey string                 `json:"flagKey"`
+	Context map[string]interface{} `json:"context"`
+}
+
+// batchEvaluationResult is the resolution of the flag for the context of the same index in the request
+type batchEvaluationResult struct {
+	Variant   string `json:"variant,omitempty"`
+	Reason    string `json:"reason"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+type batchEvaluationResponse struct {
+	FlagKey string                  `json:"flagKey"`
+	Results []batchEvaluationResult `json:"results"`
+}
+
+// batchSlotResult is the resolution of the flag of a slot, unlike the results of contexts it holds the value
+type batchSlotResult struct {
+	FlagKey   string      `json:"flagKey"`
+	Value     interface{} `json:"value"`
+	Variant   string      `json:"variant,omitempty"`
+	Reason    string      `json:"reason"`
+	ErrorCode string      `json:"errorCode,omitempty"`
+}
+
+type batchSlotsResponse struct {
+	Slots map[string]batchSlotResult `json:"slots"`
+}
+
+// batchResolverFunc resolves the value, variant and reason of a flag
+type batchResolverFunc func(
+	ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+) (interface{}, string, string, error)
+
+func batchResolver[T constraints](s *FlagEvaluationService, resolver resolverFunc[T]) batchResolverFunc {
+	resolver = normalizeReasons(s, resolver)
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (interface{}, string, string, error) {
+		value, variant, reason, _, err := resolver(ctx, reqID, flagKey, evalCtx)
+		return value, variant, reason, err
+	}
+}
+
+// BatchEvaluationHandler evaluates a flag against each context of a batch concurrently, returning the variant and
+// reason of each context in the order of the request. Requests may instead hold slots, each a flag and a context,
+// the value, variant and reason of each slot being returned by its slot ID. Batch evaluations aren't recorded by the
+// evaluation webhook nor the variant distribution.
+func (s *FlagEvaluationService) BatchEvaluationHandler() http.Handler {
+	return http.HandlerFunc(s.serveBatchEvaluation)
+}
+
+func (s *FlagEvaluationService) serveBatchEvaluation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	types, ok := s.evaluatorOf(ReadReplicaBulk).(eval.FlagTypes)
+	if !ok {
+		http.Error(w, "the evaluator can't infer the type of flags", http.StatusNotImplemented)
+		return
+	}
+	var req batchEvaluationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBatchRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxBatchRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Slots) > 0 {
+		s.serveBatchSlots(w, r, types, req)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Contexts) > MaxBatchContexts {
+		http.Error(w, fmt.Sprintf("batch of %d contexts exceeds the maximum of %d", len(req.Contexts),
+			MaxBatchContexts), http.StatusRequestEntityTooLarge)
+		return
+	}
+	contexts := make([]*structpb.Struct, len(req.Contexts))
+	for i, context := range req.Contexts {
+		evalCtx, err := batchContext(context)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("context %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		contexts[i] = evalCtx
+	}
+
+	resolver, err := s.batchResolverOf(types, req.FlagKey, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(batchEvaluationResponse{
+		FlagKey: req.FlagKey,
+		Results: s.evaluateBatch(r.Context(), resolver, req.FlagKey, contexts),
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *FlagEvaluationService) serveBatchSlots(
+	w http.ResponseWriter, r *http.Request, types eval.FlagTypes, req batchEvaluationRequest,
+) {
+	if req.FlagKey != "" || req.Contexts != nil {
+		http.Error(w, "slots can't be requested along with flagKey and contexts", http.StatusBadRequest)
+		return
+	}
+	if len(req.Slots) > MaxBatchContexts {
+		http.Error(w, fmt.Sprintf("batch of %d slots exceeds the maximum of %d", len(req.Slots),
+			MaxBatchContexts), http.StatusRequestEntityTooLarge)
+		return
+	}
+	contexts := make([]*structpb.Struct, len(req.Slots))
+	resolvers := make([]batchResolverFunc, len(req.Slots))
+	slotIDs := make(map[string]struct{}, len(req.Slots))
+	for i, slot := range req.Slots {
+		if slot.SlotID == "" || slot.FlagKey == "" {
+			http.Error(w, fmt.Sprintf("slot %d: slotId and flagKey are required", i), http.StatusBadRequest)
+			return
+		}
+		if _, ok := slotIDs[slot.SlotID]; ok {
+			http.Error(w, fmt.Sprintf("slot %d: duplicate slotId: %s", i, slot.SlotID), http.StatusBadRequest)
+			return
+		}
+		slotIDs[slot.SlotID] = struct{}{}
+		evalCtx, err := batchContext(slot.Context)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("slot %s: %v", slot.SlotID, err), http.StatusBadRequest)
+			return
+		}
+		contexts[i] = evalCtx
+		if resolvers[i], err = s.batchResolverOf(types, slot.FlagKey, evalCtx); err != nil {
+			http.Error(w, fmt.Sprintf("slot %s: %v", slot.SlotID, err), http.StatusNotImplemented)
+			return
+		}
+	}
+
+	results := make([]batchSlotResult, len(req.Slots))
+	concurrently(len(req.Slots), func(i int) {
+		results[i] = s.evaluateBatchSlot(r.Context(), resolvers[i], req.Slots[i].FlagKey, contexts[i])
+	})
+	res := batchSlotsResponse{Slots: make(map[string]batchSlotResult, len(req.Slots))}
+	for i, slot := range req.Slots {
+		res.Slots[slot.SlotID] = results[i]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// batchContext returns the evaluation context of a context of a batch, failing for contexts which aren't valid
+func batchContext(context map[string]interface{}) (*structpb.Struct, error) {
+	evalCtx, err := structpb.NewStruct(context)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateContext(evalCtx); err != nil {
+		return nil, err
+	}
+	return evalCtx, nil
+}
+
+// batchResolverOf returns the resolver of the type of the flag, failing if the type is disabled
+func (s *FlagEvaluationService) batchResolverOf(
+	types eval.FlagTypes, flagKey string, evalCtx *structpb.Struct,
+) (batchResolverFunc, error) {
+	flagType, ok := types.FlagType(flagKey, evalCtx)
+	if !ok {
+		// the flag is resolved as a boolean flag, so the evaluator reports why it can't be resolved
+		flagType = eval.BooleanFlagType
+	}
+	if err := s.checkEnabled(flagType); err != nil {
+		return nil, err
+	}
+	evaluator := s.evaluatorOf(ReadReplicaBulk)
+	switch flagType {
+	case eval.BooleanFlagType:
+		return batchResolver(s, evaluator.ResolveBooleanValue), nil
+	case eval.StringFlagType:
+		return batchResolver(s, evaluator.ResolveStringValue), nil
+	case eval.IntFlagType:
+		return batchResolver(s, evaluator.ResolveIntValue), nil
+	case eval.FloatFlagType:
+		return batchResolver(s, evaluator.ResolveFloatValue), nil
+	default:
+		return batchResolver(s, evaluator.ResolveObjectValue), nil
+	}
+}
+
+// evaluateBatch resolves the flag for each context with a pool of workers, the results are in the order of the
+// contexts. The contexts left once ctx is done fail with its error.
+func (s *FlagEvaluationService) evaluateBatch(
+	ctx context.Context, resolver batchResolverFunc, flagKey string, contexts []*structpb.Struct,
+) []batchEvaluationResult {
+	results := make([]batchEvaluationResult, len(contexts))
+	concurrently(len(contexts), func(i int) {
+		results[i] = s.evaluateBatchContext(ctx, resolver, flagKey, contexts[i])
+	})
+	return results
+}
+
+// concurrently calls evaluate with each index up to n with a pool of workers, returning once every call returned
+func concurrently(n int, evaluate func(i int)) {
+	indexes := make(chan int)
+	workers := goruntime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				evaluate(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func (s *FlagEvaluationService) evaluateBatchContext(
+	ctx context.Context, resolver batchResolverFunc, flagKey string, evalCtx *structpb.Struct,
+) batchEvaluationResult {
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	_, variant, reason, err := resolver(ctx, reqID, flagKey, evalCtx)
+	if err != nil {
+		return batchEvaluationResult{Reason: model.ErrorReason, ErrorCode: err.Error()}
+	}
+	return batchEvaluationResult{Variant: variant, Reason: reason}
+}
+
+func (s *FlagEvaluationService) evaluateBatchSlot(
+	ctx context.Context, resolver batchResolverFunc, flagKey string, evalCtx *structpb.Struct,
+) batchSlotResult {
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	value, variant, reason, err := resolver(ctx, reqID, flagKey, evalCtx)
+	if err != nil {
+		return batchSlotResult{FlagKey: flagKey, Reason: model.ErrorReason, ErrorCode: err.Error()}
+	}
+	return batchSlotResult{FlagKey: flagKey, Value: value, Variant: variant, Reason: reason}
+}
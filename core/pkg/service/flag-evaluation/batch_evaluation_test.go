@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const batchEvaluationFlagConfig = `{
+  "flags": {
+    "checkout": {
+      "state": "ENABLED",
+      "variants": { "control": "control", "treatment": "treatment", "beta": "beta" },
+      "defaultVariant": "control",
+      "targeting": {
+        "if": [
+          { "in": ["@faas.com", { "var": "email" }] }, "beta",
+          { "==": [{ "var": "plan" }, "premium"] }, "treatment",
+          null
+        ]
+      }
+    },
+    "discount": {
+      "state": "ENABLED",
+      "variants": { "none": 0, "loyal": 15 },
+      "defaultVariant": "none",
+      "targeting": { "if": [{ ">=": [{ "var": "orders" }, 10] }, "loyal", null] }
+    },
+    "disabledFlag": {
+      "state": "DISABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`
+
+func postBatchEvaluation(t *testing.T, url string, req batchEvaluationRequest) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.Nil(t, err)
+	res, err := http.Post(url, "application/json", bytes.NewReader(body))
+	require.Nil(t, err)
+	return res
+}
+
+func TestBatchEvaluationHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	res := postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "checkout", Contexts: []map[string]interface{}{
+		{"email": "user@faas.com", "plan": "premium"},
+		{"email": "user@example.com", "plan": "premium"},
+		{"email": "user@example.com", "plan": "free"},
+		{},
+	}})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body batchEvaluationResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, "checkout", body.FlagKey)
+	require.Equal(t, []batchEvaluationResult{
+		{Variant: "beta", Reason: model.TargetingMatchReason},
+		{Variant: "treatment", Reason: model.TargetingMatchReason},
+		{Variant: "control", Reason: model.DefaultReason},
+		{Variant: "control", Reason: model.DefaultReason},
+	}, body.Results, "results should be in the order of the contexts")
+}
+
+func TestBatchEvaluationHandler_Concurrent(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	contexts := make([]map[string]interface{}, MaxBatchContexts)
+	for i := range contexts {
+		plan := "free"
+		if i%2 == 0 {
+			plan = "premium"
+		}
+		contexts[i] = map[string]interface{}{"email": fmt.Sprintf("user-%d@example.com", i), "plan": plan}
+	}
+	res := postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "checkout", Contexts: contexts})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body batchEvaluationResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Len(t, body.Results, MaxBatchContexts)
+	for i, result := range body.Results {
+		want := "control"
+		if i%2 == 0 {
+			want = "treatment"
+		}
+		require.Equal(t, want, result.Variant, "context %d", i)
+	}
+}
+
+func TestBatchEvaluationHandler_Slots(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	res := postBatchEvaluation(t, server.URL, batchEvaluationRequest{Slots: []batchEvaluationSlot{
+		{SlotID: "header", FlagKey: "checkout", Context: map[string]interface{}{"email": "user@faas.com"}},
+		{SlotID: "footer", FlagKey: "checkout", Context: map[string]interface{}{"plan": "premium"}},
+		{SlotID: "sidebar", FlagKey: "checkout"},
+		{SlotID: "cart", FlagKey: "discount", Context: map[string]interface{}{"orders": 12}},
+		{SlotID: "banner", FlagKey: "disabledFlag"},
+		{SlotID: "modal", FlagKey: "missing"},
+	}})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body batchSlotsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, map[string]batchSlotResult{
+		"header":  {FlagKey: "checkout", Value: "beta", Variant: "beta", Reason: model.TargetingMatchReason},
+		"footer":  {FlagKey: "checkout", Value: "treatment", Variant: "treatment", Reason: model.TargetingMatchReason},
+		"sidebar": {FlagKey: "checkout", Value: "control", Variant: "control", Reason: model.DefaultReason},
+		"cart":    {FlagKey: "discount", Value: float64(15), Variant: "loyal", Reason: model.TargetingMatchReason},
+		"banner": {
+			FlagKey: "disabledFlag", Reason: model.ErrorReason, ErrorCode: model.FlagDisabledErrorCode,
+		},
+		"modal": {FlagKey: "missing", Reason: model.ErrorReason, ErrorCode: model.FlagNotFoundErrorCode},
+	}, body.Slots, "the same flag should be resolved against the context of each of its slots")
+}
+
+func TestBatchEvaluationHandler_SlotsConcurrent(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	slots := make([]batchEvaluationSlot, MaxBatchContexts)
+	for i := range slots {
+		plan := "free"
+		if i%2 == 0 {
+			plan = "premium"
+		}
+		slots[i] = batchEvaluationSlot{
+			SlotID: fmt.Sprintf("slot-%d", i), FlagKey: "checkout", Context: map[string]interface{}{"plan": plan},
+		}
+	}
+	res := postBatchEvaluation(t, server.URL, batchEvaluationRequest{Slots: slots})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body batchSlotsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Len(t, body.Slots, MaxBatchContexts)
+	for i := range slots {
+		want := "control"
+		if i%2 == 0 {
+			want = "treatment"
+		}
+		require.Equal(t, want, body.Slots[fmt.Sprintf("slot-%d", i)].Variant, "slot %d", i)
+	}
+}
+
+func TestBatchEvaluationHandler_SlotsErrors(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, WithDisabledResolveTypes(
+		[]string{ResolveTypeInt},
+	))
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		req      batchEvaluationRequest
+		wantCode int
+	}{
+		"duplicate slot ids": {
+			req: batchEvaluationRequest{Slots: []batchEvaluationSlot{
+				{SlotID: "header", FlagKey: "checkout"}, {SlotID: "header", FlagKey: "discount"},
+			}},
+			wantCode: http.StatusBadRequest,
+		},
+		"without slot id": {
+			req:      batchEvaluationRequest{Slots: []batchEvaluationSlot{{FlagKey: "checkout"}}},
+			wantCode: http.StatusBadRequest,
+		},
+		"without flag key": {
+			req:      batchEvaluationRequest{Slots: []batchEvaluationSlot{{SlotID: "header"}}},
+			wantCode: http.StatusBadRequest,
+		},
+		"along with a flag key": {
+			req: batchEvaluationRequest{
+				FlagKey: "checkout", Slots: []batchEvaluationSlot{{SlotID: "header", FlagKey: "checkout"}},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid context": {
+			req: batchEvaluationRequest{Slots: []batchEvaluationSlot{
+				{SlotID: "header", FlagKey: "checkout", Context: map[string]interface{}{"targetingKey": 42}},
+			}},
+			wantCode: http.StatusBadRequest,
+		},
+		"too many slots": {
+			req:      batchEvaluationRequest{Slots: make([]batchEvaluationSlot, MaxBatchContexts+1)},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		"disabled type": {
+			req: batchEvaluationRequest{Slots: []batchEvaluationSlot{
+				{SlotID: "header", FlagKey: "checkout"}, {SlotID: "cart", FlagKey: "discount"},
+			}},
+			wantCode: http.StatusNotImplemented,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := postBatchEvaluation(t, server.URL, tt.req)
+			res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestBatchEvaluationHandler_Errors(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, batchEvaluationFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	res, err = http.Post(server.URL, "application/json", strings.NewReader("{"))
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res = postBatchEvaluation(t, server.URL, batchEvaluationRequest{Contexts: []map[string]interface{}{{}}})
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode, "the flag key is required")
+
+	res = postBatchEvaluation(t, server.URL, batchEvaluationRequest{
+		FlagKey: "checkout", Contexts: make([]map[string]interface{}, MaxBatchContexts+1),
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+
+	res = postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "checkout", Contexts: []map[string]interface{}{
+		{"plan": "premium"}, {"targetingKey": 42},
+	}})
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode, "invalid contexts should fail the batch")
+
+	res = postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "missing", Contexts: []map[string]interface{}{
+		{"plan": "premium"},
+	}})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body batchEvaluationResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, []batchEvaluationResult{{Reason: model.ErrorReason, ErrorCode: model.FlagNotFoundErrorCode}},
+		body.Results)
+
+	res = postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "disabledFlag", Contexts: []map[string]interface{}{
+		{},
+	}})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, []batchEvaluationResult{{Reason: model.ErrorReason, ErrorCode: model.FlagDisabledErrorCode}}, body.Results)
+}
+
+func TestBatchEvaluationHandler_NotImplemented(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	server := httptest.NewServer(s.BatchEvaluationHandler())
+	defer server.Close()
+
+	res := postBatchEvaluation(t, server.URL, batchEvaluationRequest{FlagKey: "checkout"})
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNotImplemented, res.StatusCode)
+}
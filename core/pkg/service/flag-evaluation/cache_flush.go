@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/service"
+)
+
+// CacheFlushPath flushes the caches of the evaluator and resyncs the sources with a POST request, it's only served
+// with the admin API
+const CacheFlushPath = "/admin/flush-caches"
+
+type cacheFlushResponse struct {
+	eval.FlushedCaches
+	// Resync is set when the sources are resynced
+	Resync bool `json:"resync"`
+}
+
+// withResyncTrigger resyncs the sources through the trigger once the caches are flushed
+func withResyncTrigger(resync service.ResyncTrigger) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.resync = resync
+	}
+}
+
+// CacheFlushHandler drops the parsed targeting rules and compiled patterns cached by the evaluator, then resyncs
+// the whole configuration of every source, so every evaluation is served fresh
+func (s *FlagEvaluationService) CacheFlushHandler() http.Handler {
+	return http.HandlerFunc(s.serveCacheFlush)
+}
+
+func (s *FlagEvaluationService) serveCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var res cacheFlushResponse
+	if flusher, ok := s.evaluator().(eval.CacheFlush); ok {
+		res.FlushedCaches = flusher.FlushCaches()
+	}
+	if s.resync != nil {
+		s.resync()
+		res.Resync = true
+	}
+	s.auditLogger.Info(fmt.Sprintf("flushed %d targeting rules and %d patterns, resyncing the sources: %t",
+		res.Rules, res.Patterns, res.Resync))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFlushHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+	resyncs := 0
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		withResyncTrigger(func() { resyncs++ }))
+	_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "beta"}))
+	require.Nil(t, err)
+
+	server := httptest.NewServer(s.CacheFlushHandler())
+	defer server.Close()
+	res, err := http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body cacheFlushResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, cacheFlushResponse{FlushedCaches: eval.FlushedCaches{Rules: 1}, Resync: true}, body)
+	require.Equal(t, 1, resyncs)
+	require.Equal(t, eval.FlushedCaches{}, evaluator.FlushCaches(), "the caches must be empty after the flush")
+
+	res, err = http.Get(server.URL)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	require.Equal(t, 1, resyncs)
+}
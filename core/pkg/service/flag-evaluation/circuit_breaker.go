@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// CircuitBreakersPath returns the state of the circuit breaker of each flag, e.g.
+// /admin/circuit-breakers?flagKey=my-flag, it's only served with the admin API and circuit breakers enabled
+const CircuitBreakersPath = "/admin/circuit-breakers"
+
+type circuitBreakersResponse struct {
+	Flags map[string]eval.CircuitBreakerState `json:"flags"`
+}
+
+// CircuitBreakersHandler returns the state of the circuit breaker of each flag whose targeting was evaluated, so
+// operators can tell which flags are short-circuited to their default variant
+func (s *FlagEvaluationService) CircuitBreakersHandler() http.Handler {
+	return http.HandlerFunc(s.serveCircuitBreakers)
+}
+
+func (s *FlagEvaluationService) serveCircuitBreakers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	breakers, ok := s.evaluator().(eval.CircuitBreakers)
+	if !ok {
+		http.Error(w, "circuit breakers aren't enabled", http.StatusNotFound)
+		return
+	}
+	states := breakers.CircuitBreakers()
+	if states == nil {
+		http.Error(w, "circuit breakers aren't enabled", http.StatusNotFound)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	res := circuitBreakersResponse{Flags: map[string]eval.CircuitBreakerState{}}
+	for key, state := range states {
+		if flagKey != "" && key != flagKey {
+			continue
+		}
+		res.Flags[key] = state
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCircuitBreakersHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig,
+		eval.WithCircuitBreaker(1, time.Nanosecond, time.Hour))
+	require.Nil(t, err)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "pro"})
+	require.Nil(t, err)
+	_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+	require.Nil(t, err)
+	_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "", "static", evalCtx)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.CircuitBreakersHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?flagKey=headerColor")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var breakers circuitBreakersResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&breakers))
+	require.Len(t, breakers.Flags, 1, "flags without targeting don't have a breaker")
+	breaker := breakers.Flags["headerColor"]
+	require.Equal(t, eval.CircuitOpen, breaker.State)
+	require.Equal(t, 1, breaker.ConsecutiveFailures)
+	require.Equal(t, int64(1), breaker.Trips)
+	require.False(t, breaker.OpenedAt.IsZero())
+
+	res, err = http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	disabled, err := eval.NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig)
+	require.Nil(t, err)
+	rec := httptest.NewRecorder()
+	NewFlagEvaluationService(logger.NewLogger(nil, false), disabled, nil).CircuitBreakersHandler().
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CircuitBreakersPath, nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
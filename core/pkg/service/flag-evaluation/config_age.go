@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ConfigLoadedMetadataKey is the metadata key holding the RFC 3339 time the served configuration was loaded at
+	ConfigLoadedMetadataKey = "configLoadedAt"
+	// ConfigAgeMetadataKey is the metadata key holding the whole seconds elapsed since the configuration was loaded
+	ConfigAgeMetadataKey = "configAgeSeconds"
+)
+
+// withConfigLoadedProbe adds the load time and age of the configuration to the resolutions
+func withConfigLoadedProbe(probe service.ConfigLoadedProbe) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.configLoaded = probe
+	}
+}
+
+// markConfigAge wraps the resolver, adding the load time and age of the configuration to successful resolutions, so
+// clients can tell when flagd hasn't reloaded its configuration recently. The age is measured by flagd, unaffected by
+// the clock of the clients.
+func markConfigAge[T constraints](probe service.ConfigLoadedProbe, resolver resolverFunc[T]) resolverFunc[T] {
+	if probe == nil {
+		return resolver
+	}
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (T, string, string, map[string]interface{}, error) {
+		value, variant, reason, metadata, err := resolver(ctx, reqID, flagKey, evalCtx)
+		loaded := probe()
+		if err != nil || loaded.IsZero() {
+			return value, variant, reason, metadata, err
+		}
+		// the metadata may be shared by the resolutions of the flag
+		aged := make(map[string]interface{}, len(metadata)+2)
+		for key, v := range metadata {
+			aged[key] = v
+		}
+		aged[ConfigLoadedMetadataKey] = loaded.UTC().Format(time.RFC3339)
+		aged[ConfigAgeMetadataKey] = math.Max(0, math.Floor(time.Since(loaded).Seconds()))
+		return value, variant, reason, aged, nil
+	}
+}
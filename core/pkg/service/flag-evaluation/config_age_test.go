@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/golang/mock/gomock"
+	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigAgeMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"team": "checkout"}
+	ctrl := gomock.NewController(t)
+	evaluator := mock.NewMockIEvaluator(ctrl)
+	evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).Return(
+		true, "on", model.StaticReason, metadata, nil,
+	).AnyTimes()
+
+	var loaded time.Time
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		withConfigLoadedProbe(func() time.Time { return loaded }))
+	resolve := func() map[string]interface{} {
+		res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+		))
+		require.Nil(t, err)
+		var got map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(res.Header().Get(MetadataHeader)), &got))
+		return got
+	}
+
+	require.Equal(t, map[string]interface{}{"team": "checkout"}, resolve(), "configuration not loaded yet")
+
+	loaded = time.Now().Add(-90 * time.Second)
+	require.Equal(t, map[string]interface{}{
+		"team":                  "checkout",
+		ConfigLoadedMetadataKey: loaded.UTC().Format(time.RFC3339),
+		ConfigAgeMetadataKey:    float64(90),
+	}, resolve())
+	require.Equal(t, map[string]interface{}{"team": "checkout"}, metadata, "the metadata of the flag shouldn't change")
+
+	// a reload resets the age
+	loaded = time.Now()
+	got := resolve()
+	require.Equal(t, float64(0), got[ConfigAgeMetadataKey])
+	require.Equal(t, loaded.UTC().Format(time.RFC3339), got[ConfigLoadedMetadataKey])
+}
+
+func TestConfigAgeMetadata_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	evaluator := mock.NewMockIEvaluator(ctrl)
+	evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).Return(
+		true, "on", model.StaticReason, nil, nil,
+	)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+	))
+	require.Nil(t, err)
+	require.Empty(t, res.Header().Get(MetadataHeader))
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ConfigComparisonPath evaluates the sampled evaluation contexts against the configuration and the candidate
+// configuration posted, counting the evaluations of each flag which differ. It's only served with the admin API.
+const ConfigComparisonPath = "/admin/config-comparison"
+
+// DefaultContextSamples is the number of recent evaluation contexts sampled for configuration comparisons
+const DefaultContextSamples = 1000
+
+type configComparisonResponse struct {
+	Contexts int                             `json:"contexts"`
+	Flags    map[string]flagDivergenceResult `json:"flags"`
+}
+
+type flagDivergenceResult struct {
+	Evaluations int `json:"evaluations"`
+	Divergent   int `json:"divergent"`
+}
+
+// contextSamples holds the latest evaluation contexts, anonymized, replacing the oldest once full
+type contextSamples struct {
+	mu      sync.Mutex
+	samples []*structpb.Struct
+	// next is the index of the oldest sample once full
+	next int
+}
+
+func newContextSamples(size int) *contextSamples {
+	return &contextSamples{samples: make([]*structpb.Struct, 0, size)}
+}
+
+// WithContextSamples samples the latest evaluation contexts, up to size, to compare configurations at
+// ConfigComparisonPath. Contexts aren't sampled if size isn't positive.
+func WithContextSamples(size int) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		if size > 0 {
+			s.contextSamples = newContextSamples(size)
+		}
+	}
+}
+
+func (c *contextSamples) add(ctx *structpb.Struct) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.samples) < cap(c.samples) {
+		c.samples = append(c.samples, ctx)
+		return
+	}
+	c.samples[c.next] = ctx
+	c.next = (c.next + 1) % len(c.samples)
+}
+
+func (c *contextSamples) list() []*structpb.Struct {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*structpb.Struct{}, c.samples...)
+}
+
+// sampleContext samples the evaluation context if enabled, with its targeting key replaced by its hash. The other
+// values are kept, as targeting rules match them.
+func (s *FlagEvaluationService) sampleContext(ctx *structpb.Struct) {
+	if s.contextSamples == nil {
+		return
+	}
+	fields := make(map[string]*structpb.Value, len(ctx.GetFields()))
+	for key, value := range ctx.GetFields() {
+		fields[key] = value
+	}
+	if targetingKey := fields[targetingKeyField].GetStringValue(); targetingKey != "" {
+		fields[targetingKeyField] = structpb.NewStringValue(s.logContextKeys.targetingKey.hash(targetingKey))
+	}
+	s.contextSamples.add(&structpb.Struct{Fields: fields})
+}
+
+// ConfigComparisonHandler evaluates the sampled evaluation contexts against both the configuration and the candidate
+// configuration posted, so operators can tell how many evaluations of each flag a configuration change would flip
+// before rolling it out. The configuration is left unchanged.
+func (s *FlagEvaluationService) ConfigComparisonHandler() http.Handler {
+	return http.HandlerFunc(s.serveConfigComparison)
+}
+
+func (s *FlagEvaluationService) serveConfigComparison(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	comparison, ok := s.evaluator().(eval.ConfigComparison)
+	if !ok {
+		http.Error(w, "the evaluator can't compare configurations", http.StatusNotImplemented)
+		return
+	}
+	if s.contextSamples == nil {
+		http.Error(w, "evaluation contexts aren't sampled", http.StatusNotImplemented)
+		return
+	}
+	config, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading candidate configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	divergence, err := comparison.CompareConfig(reqID, string(config), s.contextSamples.list())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := configComparisonResponse{
+		Contexts: divergence.Contexts,
+		Flags:    make(map[string]flagDivergenceResult, len(divergence.Flags)),
+	}
+	for flagKey, flag := range divergence.Flags {
+		res.Flags[flagKey] = flagDivergenceResult{Evaluations: flag.Evaluations, Divergent: flag.Divergent}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding config comparison response: %v", err))
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const comparisonConfig = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null] }
+    },
+    "color": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    }
+  }
+}`
+
+// comparisonCandidate enables beta for every email of example.com rather than faas.com
+const comparisonCandidate = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@example.com", { "var": "email" }] }, "on", null] }
+    },
+    "color": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red"
+    }
+  }
+}`
+
+func TestConfigComparison(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, WithContextSamples(3))
+
+	// the oldest context is replaced once the samples are full
+	for i, email := range []string{"old@faas.com", "user@faas.com", "user@example.com", "user@other.com"} {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": email, "targetingKey": email})
+		require.Nil(t, err)
+		_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "beta", Context: evalCtx},
+		))
+		require.Nil(t, err, "evaluation %d", i)
+	}
+	samples := s.contextSamples.list()
+	require.Len(t, samples, 3)
+	emails := make([]string, 0, len(samples))
+	for _, sample := range samples {
+		email := sample.GetFields()["email"].GetStringValue()
+		emails = append(emails, email)
+		require.NotEqual(t, email, sample.GetFields()[targetingKeyField].GetStringValue(),
+			"the targeting key is hashed")
+	}
+	require.ElementsMatch(t, []string{"user@faas.com", "user@example.com", "user@other.com"}, emails)
+
+	server := httptest.NewServer(s.ConfigComparisonHandler())
+	defer server.Close()
+	res, err := http.Post(server.URL, "application/json", strings.NewReader(comparisonCandidate))
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body configComparisonResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, configComparisonResponse{
+		Contexts: 3,
+		Flags: map[string]flagDivergenceResult{
+			"beta":  {Evaluations: 3, Divergent: 2},
+			"color": {Evaluations: 3, Divergent: 0},
+		},
+	}, body)
+
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.NotContains(t, state, "@example.com", "the configuration is left unchanged")
+}
+
+func TestConfigComparisonHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		samples int
+		method  string
+		body    string
+		want    int
+	}{
+		"unknown method":        {samples: 1, method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		"invalid configuration": {samples: 1, method: http.MethodPost, body: "flags", want: http.StatusBadRequest},
+		"sampling disabled":     {method: http.MethodPost, body: comparisonCandidate, want: http.StatusNotImplemented},
+		"no samples yet":        {samples: 1, method: http.MethodPost, body: comparisonCandidate, want: http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+				WithContextSamples(tt.samples))
+			server := httptest.NewServer(s.ConfigComparisonHandler())
+			defer server.Close()
+
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+			require.Nil(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			res.Body.Close()
+			require.Equal(t, tt.want, res.StatusCode)
+		})
+	}
+}
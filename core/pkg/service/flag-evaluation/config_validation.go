@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// ConfigValidationPath validates the candidate configuration posted without loading it, it's only served with the
+// admin API enabled
+const ConfigValidationPath = "/admin/config-validation"
+
+type configValidationResponse struct {
+	Valid  bool                `json:"valid"`
+	Issues []configIssueResult `json:"issues"`
+}
+
+type configIssueResult struct {
+	FlagKey string `json:"flagKey,omitempty"`
+	Message string `json:"message"`
+}
+
+// ConfigValidationHandler validates the candidate configuration posted as it would be when loaded, so pipelines can
+// reject a configuration before it's pushed to a source. Every issue is reported, the configuration is left unchanged.
+// Invalid candidates are answered with 422 along with their issues.
+func (s *FlagEvaluationService) ConfigValidationHandler() http.Handler {
+	return http.HandlerFunc(s.serveConfigValidation)
+}
+
+func (s *FlagEvaluationService) serveConfigValidation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	validation, ok := s.evaluator().(eval.ConfigValidation)
+	if !ok {
+		http.Error(w, "the evaluator can't validate configurations", http.StatusNotImplemented)
+		return
+	}
+	config, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading candidate configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+	issues, err := validation.ValidateConfig(string(config))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := configValidationResponse{Valid: len(issues) == 0, Issues: make([]configIssueResult, 0, len(issues))}
+	for _, issue := range issues {
+		res.Issues = append(res.Issues, configIssueResult{FlagKey: issue.FlagKey, Message: issue.Message})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !res.Valid {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Error(fmt.Sprintf("encoding config validation response: %v", err))
+	}
+}
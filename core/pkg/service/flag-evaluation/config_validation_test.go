@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenCandidate uses an unknown operator, references an undefined variant and derives flags from each other
+const brokenCandidate = `{
+  "flags": {
+    "valid": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off"
+    },
+    "unknownOperator": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "matches_glob": [{ "var": "email" }, "*@faas.com"] }, "on", null] }
+    },
+    "undefinedVariant": {
+      "state": "ENABLED", "variants": { "red": "#FF0000", "blue": "#0000FF" }, "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "green", null] }
+    },
+    "first": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "derived": { "and": ["second"] }
+    },
+    "second": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "derived": { "and": ["first"] }
+    }
+  }
+}`
+
+func TestConfigValidation(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.ConfigValidationHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		candidate  string
+		wantStatus int
+		wantIssues []configIssueResult
+	}{
+		"valid": {
+			candidate:  comparisonCandidate,
+			wantStatus: http.StatusOK,
+			wantIssues: []configIssueResult{},
+		},
+		"broken": {
+			candidate:  brokenCandidate,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantIssues: []configIssueResult{
+				{FlagKey: "undefinedVariant", Message: "targeting of flag: 'undefinedVariant' references undefined " +
+					"variants: variant: 'green' at targeting.if[1]"},
+				{FlagKey: "unknownOperator", Message: "targeting of flag: 'unknownOperator': unsupported " +
+					"operators: 'matches_glob'"},
+				{Message: "derived flags form a cycle: first -> second -> first"},
+			},
+		},
+		"malformed": {
+			candidate:  `{"flags": {`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantIssues: []configIssueResult{
+				{Message: "transposing evaluators: unmarshal: unexpected end of JSON input"},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := http.Post(server.URL, "application/json", strings.NewReader(tt.candidate))
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			var body configValidationResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+			require.Equal(t, tt.wantStatus == http.StatusOK, body.Valid)
+			require.Equal(t, tt.wantIssues, body.Issues)
+		})
+	}
+
+	after, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Equal(t, state, after, "validating a candidate shouldn't change the configuration")
+}
@@ -3,27 +3,39 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	"github.com/bufbuild/connect-go"
 	"github.com/open-feature/flagd/core/pkg/eval"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/otel"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/service/middleware"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/net/http2"
-	"golang.org/x/net/http2/h2c"
+	"golang.org/x/sync/errgroup"
 )
 
-const ErrorPrefix = "FlagdError:"
+const (
+	ErrorPrefix = "FlagdError:"
+	// SourceStatusPath serves the status of the flag sources on the metrics server
+	SourceStatusPath = "/sources"
+
+	// grpcWebContentTypePrefix identifies gRPC-web requests, e.g. application/grpc-web+proto
+	grpcWebContentTypePrefix = "application/grpc-web"
+)
 
 type ConnectService struct {
 	ConnectServiceConfiguration *ConnectServiceConfiguration
@@ -31,106 +43,411 @@ type ConnectService struct {
 	Logger                      *logger.Logger
 	Metrics                     *otel.MetricsRecorder
 	eventingConfiguration       *eventingConfiguration
+	distribution                *variantDistribution
+	admission                   *evaluationAdmission
+	webhook                     *evaluationWebhook
+	evaluator                   swappableEvaluator
+	stale                       service.StaleProbe
+	configLoaded                service.ConfigLoadedProbe
+	resync                      service.ResyncTrigger
+	connections                 *connectionCounter
+	evaluationStats             *evaluationStats
+	sourceStatuses              *isync.SourceStatuses
 	server                      http.Server
+	// AuditLogger logs the changes made to the running configuration through the admin API, Logger if nil
+	AuditLogger *logger.Logger
+	// AccessLogger logs a line per RPC and per request to the http endpoints, whether it succeeded or not. Requests
+	// aren't logged if nil.
+	AccessLogger *logger.Logger
+	// TracerProvider traces the unary evaluations, which aren't traced if nil
+	TracerProvider trace.TracerProvider
 }
 type ConnectServiceConfiguration struct {
 	ServerCertPath   string
 	ServerKeyPath    string
 	ServerSocketPath string
-	CORS             []string
+	// ServerSocketWithPort serves the port alongside ServerSocketPath, e.g. local tooling on the socket and remote
+	// clients over TCP. Only the socket is served otherwise, if set.
+	ServerSocketWithPort bool
+	CORS                 []string
+	// DisabledResolveTypes lists the resolve types whose handlers return an unimplemented error
+	DisabledResolveTypes []string
+	// LogContextKeys lists the evaluation context keys whose values are logged, other values are redacted
+	LogContextKeys []string
+	// ContextHeaders maps request headers to the evaluation context keys their values are merged into, the context
+	// of the request taking precedence
+	ContextHeaders map[string]string
+	// TargetingKeySalt keys the hash of the targeting keys surfaced in logs and evaluation events, which never
+	// include the raw key
+	TargetingKeySalt string
+	// MaxStreamSubscribers bounds the concurrent event stream and SSE subscribers, unbounded when 0
+	MaxStreamSubscribers int
+	// DisableGRPCWeb rejects gRPC-web requests, which are otherwise served alongside gRPC and Connect requests
+	DisableGRPCWeb bool
+	// EnableAdminAPI serves the admin endpoints, such as the variants of flags at VariantsPath, the evaluation of inline
+	// flag definitions at SandboxPath and of overridden variants at WhatIfPath, the raw values of object flags at
+	// RawObjectResolutionPath, the resolutions of the prerequisites of flags at ResolutionChainPath, the distribution of
+	// returned variants at DistributionPath, the matched branches of targeting rules at RuleStatisticsPath, the circuit
+	// breakers of flags at CircuitBreakersPath, the flags logged verbosely at VerboseFlagsPath, the comparison of
+	// candidate configurations at ConfigComparisonPath and their validation at ConfigValidationPath, the linting of
+	// targeting rules at RuleLintProcedure, the pinned flags at PinnedFlagsPath, the cache flush at CacheFlushPath, the
+	// Rego policies of the flags at RegoBundlePath, the evaluation context snapshots at ContextSnapshotsPath and
+	// ContextSnapshotEvaluationPath, the runtime diagnostics at DiagnosticsPath and, with AuthTokens, the overrides of
+	// flags at FlagOverridesPath
+	EnableAdminAPI bool
+	// AuthTokens lists the bearer tokens accepted in the authorization header, requests aren't authenticated when
+	// empty. Listing several tokens allows rotating them.
+	AuthTokens []string
+	// VariantDistributionWindow is the window over which the returned variants of each flag are counted, served at
+	// DistributionPath and as metrics. Variants aren't counted when 0.
+	VariantDistributionWindow time.Duration
+	// MaxConcurrentEvaluations bounds the unary evaluations served at once, further evaluations are queued.
+	// Evaluations aren't queued when 0.
+	MaxConcurrentEvaluations int
+	// MaxQueuedEvaluations bounds the queued evaluations, further evaluations are shed with codes.ResourceExhausted.
+	// The queue is unbounded when 0.
+	MaxQueuedEvaluations int
+	// EvaluationWebhookURL is the url successful evaluations are posted to in batches, along with their hashed
+	// targeting key. Evaluations aren't posted when empty.
+	EvaluationWebhookURL string
+	// EvaluationWebhookBatchSize is the number of evaluations posted at once, 100 when 0
+	EvaluationWebhookBatchSize int
+	// EvaluationWebhookInterval is the maximum delay of an evaluation before its batch is posted, 1s when 0
+	EvaluationWebhookInterval time.Duration
+	// UnknownReasons is the policy of reasons returned by the evaluator which aren't part of the flagd schema,
+	// normalized to UNKNOWN by default
+	UnknownReasons UnknownReasons
+	// UnsupportedContextValues is the policy of evaluation context values which aren't representable as json,
+	// dropped by default
+	UnsupportedContextValues UnsupportedContextValues
+	// EmptyFlagKeys is the policy of resolve requests whose flag key is empty or only whitespace, rejected with an
+	// invalid argument error by default
+	EmptyFlagKeys EmptyFlagKeys
+	// MaxContextValueBytes bounds the size of each evaluation context value, larger values being rejected with an
+	// invalid context error, or dropped as set by OversizedContextValues. Values are unbounded when 0.
+	MaxContextValueBytes   int
+	OversizedContextValues OversizedContextValues
+	// StrictContextConversion rejects evaluation contexts holding values requiring a lossy conversion, such as
+	// unsupported values and integers beyond 2^53, with an invalid context error
+	StrictContextConversion bool
+	// ReadTimeout bounds the time taken reading a request, including its body, DefaultReadTimeout when 0
+	ReadTimeout time.Duration
+	// WriteTimeout bounds the time taken writing a response, including streamed responses which are closed past it.
+	// Responses are unbounded when 0.
+	WriteTimeout time.Duration
+	// IdleTimeout closes the connections idle for longer, DefaultIdleTimeout when 0
+	IdleTimeout time.Duration
+	// MaxHeaderBytes bounds the size of request headers, DefaultMaxHeaderBytes when 0
+	MaxHeaderBytes int
+	// MaxRequestBytes bounds the size of request bodies, DefaultMaxRequestBytes when 0
+	MaxRequestBytes int64
+	// CompressMinBytes is the size of the smallest response compressed for clients accepting compression, smaller
+	// responses are sent uncompressed. DefaultCompressMinBytes when 0.
+	CompressMinBytes int
+	// VerboseFlags lists the flags whose evaluations are logged at the debug level whatever the log level, replaced
+	// at runtime at VerboseFlagsPath
+	VerboseFlags []string
+	// ContextSamples is the number of recent evaluation contexts sampled, anonymized, to compare candidate
+	// configurations at ConfigComparisonPath. Contexts are only sampled with the admin API enabled.
+	ContextSamples int
+	// MetricsStreamInterval is the interval of the snapshots of the metrics stream at MetricsStreamProcedure, the
+	// stream isn't served when 0
+	MetricsStreamInterval time.Duration
+	// DisabledFlags is the policy of disabled flags in ResolveAll responses, included with the DISABLED reason by
+	// default
+	DisabledFlags DisabledFlags
+	// TargetingKeyFallback is the targeting key of the evaluation contexts without one, they're evaluated as they are
+	// by default
+	TargetingKeyFallback TargetingKeyFallback
+	// ReadReplicaRPCs are served from a read replica of the flag store, refreshed by their first evaluation once
+	// older than ReadReplicaStaleness, so they don't contend with the evaluations of the hot path. RPCs are served
+	// from the live flags when ReadReplicaStaleness is 0.
+	ReadReplicaRPCs      []ReadReplicaRPC
+	ReadReplicaStaleness time.Duration
 }
 
 func (s *ConnectService) Serve(ctx context.Context, eval eval.IEvaluator, svcConf service.Configuration) error {
+	if _, err := s.ConnectServiceConfiguration.tlsEnabled(); err != nil {
+		return err
+	}
 	s.Eval = eval
+	s.stale = svcConf.StaleProbe
+	s.configLoaded = svcConf.ConfigLoaded
+	s.resync = svcConf.Resync
 	s.eventingConfiguration = &eventingConfiguration{
-		subs: make(map[interface{}]chan service.Notification),
-		mu:   &sync.RWMutex{},
+		subs:           make(map[interface{}]chan service.Notification),
+		mu:             &sync.RWMutex{},
+		maxSubscribers: s.ConnectServiceConfiguration.MaxStreamSubscribers,
 	}
-	lis, err := s.setupServer(svcConf)
-	if err != nil {
-		return err
+	s.distribution = newVariantDistribution(s.ConnectServiceConfiguration.VariantDistributionWindow)
+	s.connections = &connectionCounter{}
+	s.evaluationStats = &evaluationStats{}
+	s.sourceStatuses = svcConf.SourceStatuses
+	s.admission = newEvaluationAdmission(
+		s.ConnectServiceConfiguration.MaxConcurrentEvaluations, s.ConnectServiceConfiguration.MaxQueuedEvaluations,
+	)
+	s.webhook = newEvaluationWebhook(
+		s.Logger.WithFields(zap.String("component", "evaluationwebhook")),
+		s.ConnectServiceConfiguration.EvaluationWebhookURL,
+		s.ConnectServiceConfiguration.EvaluationWebhookBatchSize,
+		s.ConnectServiceConfiguration.EvaluationWebhookInterval,
+	)
+	if s.webhook != nil {
+		go s.webhook.run(ctx)
 	}
-
-	errChan := make(chan error, 1)
-	go func() {
-		s.Logger.Info(fmt.Sprintf("Flag Evaluation listening at %s", lis.Addr()))
-		if s.ConnectServiceConfiguration.ServerCertPath != "" && s.ConnectServiceConfiguration.ServerKeyPath != "" {
-			if err := s.server.ServeTLS(
-				lis,
-				s.ConnectServiceConfiguration.ServerCertPath,
-				s.ConnectServiceConfiguration.ServerKeyPath,
-			); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				errChan <- err
+	if s.Metrics != nil {
+		if s.webhook != nil {
+			if err := s.Metrics.RegisterDroppedEvaluationEvents(s.webhook.Overloaded, s.webhook.Undelivered); err != nil {
+				return err
 			}
-		} else {
-			if err := s.server.Serve(
-				lis,
-			); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				errChan <- err
+		}
+		if err := s.Metrics.RegisterEvaluationAdmission(s.admission.InFlight, s.admission.Queued); err != nil {
+			return err
+		}
+		if err := s.Metrics.RegisterStreamSubscribers(s.eventingConfiguration.subscriberCount); err != nil {
+			return err
+		}
+		if err := s.Metrics.RegisterRuntimeDiagnostics(s.connections.Active); err != nil {
+			return err
+		}
+		if err := s.Metrics.RegisterEvaluationDuration(prometheus.DefaultRegisterer); err != nil {
+			return err
+		}
+		if s.distribution != nil {
+			if err := s.Metrics.RegisterVariantDistribution(s.distribution.Snapshot); err != nil {
+				return err
 			}
 		}
-		close(errChan)
-	}()
+		if _, ok := ruleStatistics(eval); ok {
+			if err := s.Metrics.RegisterRuleStatistics(metricsRuleStatistics(eval)); err != nil {
+				return err
+			}
+		}
+	}
+	listeners, err := s.setupServer(svcConf)
+	if err != nil {
+		return err
+	}
+	return s.serveListeners(ctx, listeners)
+}
 
-	select {
-	case err := <-errChan:
+// serveListeners serves the server on every listener until the context is done or a listener fails, shutting the
+// server down then, which closes every listener
+func (s *ConnectService) serveListeners(ctx context.Context, listeners []net.Listener) error {
+	tlsEnabled, err := s.ConnectServiceConfiguration.tlsEnabled()
+	if err != nil {
 		return err
-	case <-ctx.Done():
-		return s.server.Shutdown(ctx)
 	}
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, lis := range listeners {
+		lis := lis
+		g.Go(func() error {
+			var err error
+			if tlsEnabled {
+				s.Logger.Info(fmt.Sprintf("Flag Evaluation listening at %s over TLS, certificate: %s", lis.Addr(),
+					s.ConnectServiceConfiguration.ServerCertPath))
+				err = s.server.ServeTLS(
+					lis,
+					s.ConnectServiceConfiguration.ServerCertPath,
+					s.ConnectServiceConfiguration.ServerKeyPath,
+				)
+			} else {
+				s.Logger.Info(fmt.Sprintf("Flag Evaluation listening at %s without TLS, no server certificate is set",
+					lis.Addr()))
+				err = s.server.Serve(lis)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		<-gCtx.Done()
+		return s.server.Shutdown(ctx)
+	})
+	return g.Wait()
 }
 
-func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listener, error) {
-	var lis net.Listener
-	var err error
-	mux := http.NewServeMux()
+// setupServer listens on the socket path if set, and on the port unless only the socket is served
+func (s *ConnectService) setupServer(svcConf service.Configuration) ([]net.Listener, error) {
+	var listeners []net.Listener
 	if s.ConnectServiceConfiguration.ServerSocketPath != "" {
-		lis, err = net.Listen("unix", s.ConnectServiceConfiguration.ServerSocketPath)
-	} else {
-		address := fmt.Sprintf(":%d", svcConf.Port)
-		lis, err = net.Listen("tcp", address)
+		lis, err := net.Listen("unix", s.ConnectServiceConfiguration.ServerSocketPath)
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, s.connections.listener(lis))
 	}
-	if err != nil {
-		return nil, err
+	if s.ConnectServiceConfiguration.ServerSocketPath == "" || s.ConnectServiceConfiguration.ServerSocketWithPort {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", svcConf.Port))
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, s.connections.listener(lis))
 	}
+	go bindMetrics(s, svcConf)
+
+	s.configureServer(s.serviceHandler())
+	return listeners, nil
+}
+
+// serviceHandler serves the flag evaluation service over the gRPC, gRPC-web and Connect protocols, along with the
+// Server-Sent Events and delta endpoints
+func (s *ConnectService) serviceHandler() http.Handler {
+	mux := http.NewServeMux()
+	// contexts are only sampled to be compared at the admin API
+	contextSamples := 0
+	if s.ConnectServiceConfiguration.EnableAdminAPI {
+		contextSamples = s.ConnectServiceConfiguration.ContextSamples
+	}
+	s.evaluator.init(s.Eval, s.readReplicaOf(s.Eval))
 	fes := NewFlagEvaluationService(
 		s.Logger.WithFields(zap.String("component", "flagservice")),
 		s.Eval,
 		s.Metrics,
+		WithDisabledResolveTypes(s.ConnectServiceConfiguration.DisabledResolveTypes),
+		WithLogContextKeys(s.ConnectServiceConfiguration.LogContextKeys),
+		WithContextHeaders(s.ConnectServiceConfiguration.ContextHeaders),
+		WithTargetingKeySalt(s.ConnectServiceConfiguration.TargetingKeySalt),
+		withEventingConfiguration(s.eventingConfiguration),
+		withVariantDistribution(s.distribution),
+		withEvaluationWebhook(s.webhook),
+		withConnectionCounter(s.connections),
+		WithUnknownReasons(s.ConnectServiceConfiguration.UnknownReasons),
+		WithMaxContextValueBytes(
+			s.ConnectServiceConfiguration.MaxContextValueBytes, s.ConnectServiceConfiguration.OversizedContextValues,
+		),
+		WithUnsupportedContextValues(s.ConnectServiceConfiguration.UnsupportedContextValues),
+		WithEmptyFlagKeys(s.ConnectServiceConfiguration.EmptyFlagKeys),
+		WithDisabledFlags(s.ConnectServiceConfiguration.DisabledFlags),
+		WithTargetingKeyFallback(s.ConnectServiceConfiguration.TargetingKeyFallback),
+		WithStrictContextConversion(s.ConnectServiceConfiguration.StrictContextConversion),
+		WithVerboseFlags(s.ConnectServiceConfiguration.VerboseFlags),
+		WithContextSamples(contextSamples),
+		withStaleProbe(s.stale),
+		withConfigLoadedProbe(s.configLoaded),
+		withSwappableEvaluator(&s.evaluator),
+		withReadReplica(nil, s.ConnectServiceConfiguration.ReadReplicaRPCs),
+		withResyncTrigger(s.resync),
+		WithAuditLogger(s.AuditLogger),
 	)
-	path, handler := schemaConnectV1.NewServiceHandler(fes)
+	opts := []connect.HandlerOption{compressionOption(s.ConnectServiceConfiguration)}
+	httpHandler := func(h http.Handler) http.Handler { return h }
+	// requests are logged before they're authenticated, so rejected requests are logged too
+	if s.AccessLogger != nil {
+		access := accessLog{logger: s.AccessLogger}
+		opts = append(opts, connect.WithInterceptors(access))
+		httpHandler = access.handler
+	}
+	tokens := newBearerTokens(s.ConnectServiceConfiguration.AuthTokens)
+	if len(tokens) > 0 {
+		opts = append(opts, connect.WithInterceptors(tokens.interceptor()))
+		logged := httpHandler
+		httpHandler = func(h http.Handler) http.Handler { return logged(tokens.handler(h)) }
+	}
+	if s.TracerProvider != nil {
+		opts = append(opts, connect.WithInterceptors(evaluationTracingInterceptor(s.TracerProvider)))
+	}
+	if s.Metrics != nil {
+		opts = append(opts, connect.WithInterceptors(evaluationMetricsInterceptor(s.Metrics)))
+	}
+	if s.admission != nil {
+		opts = append(opts, connect.WithInterceptors(admissionInterceptor(s.admission)))
+	}
+	if s.ConnectServiceConfiguration.EnableAdminAPI {
+		var lintHandler http.Handler = connect.NewUnaryHandler(RuleLintProcedure, fes.LintRules, opts...)
+		if s.ConnectServiceConfiguration.DisableGRPCWeb {
+			lintHandler = withoutGRPCWeb(lintHandler)
+		}
+		mux.Handle(RuleLintProcedure, lintHandler)
+	}
+	if interval := s.ConnectServiceConfiguration.MetricsStreamInterval; interval > 0 {
+		opts = append(opts, connect.WithInterceptors(evaluationStatsInterceptor(s.evaluationStats)))
+		stream := &metricsStream{
+			logger:         s.Logger.WithFields(zap.String("component", "metricsstream")),
+			interval:       interval,
+			stats:          s.evaluationStats,
+			eventing:       s.eventingConfiguration,
+			connections:    s.connections,
+			sourceStatuses: s.sourceStatuses,
+		}
+		var streamHandler http.Handler = connect.NewServerStreamHandler(
+			MetricsStreamProcedure, stream.StreamMetrics, opts...)
+		if s.ConnectServiceConfiguration.DisableGRPCWeb {
+			streamHandler = withoutGRPCWeb(streamHandler)
+		}
+		mux.Handle(MetricsStreamProcedure, streamHandler)
+	}
+	if s.sourceStatuses != nil {
+		var statusHandler http.Handler = connect.NewUnaryHandler(
+			SourceStatusesProcedure, sourceStatuses{statuses: s.sourceStatuses}.SourceStatuses, opts...)
+		if s.ConnectServiceConfiguration.DisableGRPCWeb {
+			statusHandler = withoutGRPCWeb(statusHandler)
+		}
+		mux.Handle(SourceStatusesProcedure, statusHandler)
+	}
+	path, handler := schemaConnectV1.NewServiceHandler(fes, opts...)
+	if s.ConnectServiceConfiguration.DisableGRPCWeb {
+		handler = withoutGRPCWeb(handler)
+	}
 	mux.Handle(path, handler)
+	mux.Handle(SSEPath, httpHandler(fes.SSEHandler()))
+	mux.Handle(DeltaPath, httpHandler(fes.DeltaHandler()))
+	mux.Handle(ResolveAnyPath, httpHandler(fes.ResolveAnyHandler()))
+	mux.Handle(ResolveRawContextPath, httpHandler(fes.ResolveRawContextHandler()))
+	mux.Handle(BatchEvaluationPath, httpHandler(fes.BatchEvaluationHandler()))
+	mux.Handle(InfoPath, httpHandler(fes.InfoHandler()))
+	mux.Handle(ContextKeysPath, httpHandler(fes.ContextKeysHandler()))
+	if s.ConnectServiceConfiguration.EnableAdminAPI {
+		mux.Handle(VariantsPath, httpHandler(fes.VariantsHandler()))
+		mux.Handle(SandboxPath, httpHandler(fes.SandboxHandler()))
+		mux.Handle(WhatIfPath, httpHandler(fes.WhatIfHandler()))
+		mux.Handle(RawObjectResolutionPath, httpHandler(fes.RawObjectResolutionHandler()))
+		mux.Handle(ResolutionChainPath, httpHandler(fes.ResolutionChainHandler()))
+		mux.Handle(DistributionPath, httpHandler(fes.DistributionHandler()))
+		mux.Handle(RuleStatisticsPath, httpHandler(fes.RuleStatisticsHandler()))
+		mux.Handle(CircuitBreakersPath, httpHandler(fes.CircuitBreakersHandler()))
+		mux.Handle(VerboseFlagsPath, httpHandler(fes.VerboseFlagsHandler()))
+		mux.Handle(ConfigComparisonPath, httpHandler(fes.ConfigComparisonHandler()))
+		mux.Handle(ConfigValidationPath, httpHandler(fes.ConfigValidationHandler()))
+		mux.Handle(PinnedFlagsPath, httpHandler(fes.PinnedFlagsHandler()))
+		mux.Handle(CacheFlushPath, httpHandler(fes.CacheFlushHandler()))
+		mux.Handle(RegoBundlePath, httpHandler(fes.RegoBundleHandler()))
+		mux.Handle(ContextSnapshotsPath, httpHandler(fes.ContextSnapshotsHandler()))
+		mux.Handle(ContextSnapshotEvaluationPath, httpHandler(fes.ContextSnapshotEvaluationHandler()))
+		mux.Handle(DiagnosticsPath, httpHandler(fes.DiagnosticsHandler()))
+		if len(tokens) > 0 {
+			// flags are only edited by authenticated requests
+			mux.Handle(FlagOverridesPath, httpHandler(fes.FlagOverridesHandler()))
+		}
+	}
 
 	mdlw := middleware.NewHttpMetric(middleware.Config{
 		Service:        "openfeature/flagd",
 		MetricRecorder: s.Metrics,
 		Logger:         s.Logger,
 	})
-	h := middleware.Handler("", mdlw, mux)
-
-	go bindMetrics(s, svcConf)
+	return middleware.Handler("", mdlw, mux)
+}
 
-	if s.ConnectServiceConfiguration.ServerCertPath != "" && s.ConnectServiceConfiguration.ServerKeyPath != "" {
-		handler = s.newCORS().Handler(h)
-	} else {
-		handler = h2c.NewHandler(
-			s.newCORS().Handler(h),
-			&http2.Server{},
-		)
-	}
-	s.server = http.Server{
-		ReadHeaderTimeout: time.Second,
-		Handler:           handler,
-	}
-	return lis, nil
+// withoutGRPCWeb rejects gRPC-web requests, as connect handlers don't allow disabling protocols. The gRPC and
+// Connect protocols are still served.
+func withoutGRPCWeb(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentTypePrefix) {
+			http.Error(w, "gRPC-web is disabled", http.StatusUnsupportedMediaType)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 func (s *ConnectService) Notify(n service.Notification) {
-	s.eventingConfiguration.mu.RLock()
-	defer s.eventingConfiguration.mu.RUnlock()
-	for _, send := range s.eventingConfiguration.subs {
-		send <- n
-	}
+	s.eventingConfiguration.notify(n)
 }
 
 func (s *ConnectService) newCORS() *cors.Cors {
@@ -158,16 +475,34 @@ func (s *ConnectService) newCORS() *cors.Cors {
 			"Grpc-Message",
 			"Grpc-Status",
 			"Grpc-Status-Details-Bin",
+			MetadataHeader,
+			ResolveErrorsHeader,
+			ResolveStatusHeader,
 		},
 	})
 }
 
+func serveSourceStatuses(w http.ResponseWriter, statuses *isync.SourceStatuses) {
+	if statuses == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses.Snapshot()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 func bindMetrics(s *ConnectService, svcConf service.Configuration) {
 	s.Logger.Info(fmt.Sprintf("metrics and probes listening at %d", svcConf.MetricsPort))
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", svcConf.MetricsPort),
 		ReadHeaderTimeout: 3 * time.Second,
 	}
+	// OpenMetrics is negotiated by scrapers requesting it, it's required to expose exemplars
+	metricsHandler := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(
+		prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true},
+	))
 	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/healthz":
@@ -178,8 +513,16 @@ func bindMetrics(s *ConnectService, svcConf service.Configuration) {
 			} else {
 				w.WriteHeader(http.StatusPreconditionFailed)
 			}
+		case "/startupz":
+			if svcConf.StartupProbe == nil || svcConf.StartupProbe() {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusPreconditionFailed)
+			}
 		case "/metrics":
-			promhttp.Handler().ServeHTTP(w, r)
+			metricsHandler.ServeHTTP(w, r)
+		case SourceStatusPath:
+			serveSourceStatuses(w, svcConf.SourceStatuses)
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
@@ -4,20 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
 	schemaGrpcV1 "buf.build/gen/go/open-feature/flagd/grpc/go/schema/v1/schemav1grpc"
 	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
 	"github.com/golang/mock/gomock"
+	"github.com/open-feature/flagd/core/pkg/eval"
 	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/otel"
 	iservice "github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -66,10 +77,11 @@ func TestConnectService_UnixConnection(t *testing.T) {
 			_ = os.Remove(tt.socketPath)
 			ctrl := gomock.NewController(t)
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveBooleanValue(gomock.Any(), tt.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), tt.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.evalFields.err,
 			).AnyTimes()
 			// configure OTel Metrics
@@ -119,3 +131,129 @@ func TestConnectService_UnixConnection(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectService_SocketWithPort(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), "socket-with-port"),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	svc.server = http.Server{
+		ReadHeaderTimeout: time.Second,
+		Handler:           h2c.NewHandler(svc.serviceHandler(), &http2.Server{}),
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "flagd.sock")
+	socket, err := net.Listen("unix", socketPath)
+	require.Nil(t, err)
+	port, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- svc.serveListeners(ctx, []net.Listener{socket, port})
+	}()
+
+	for _, target := range []string{"unix://" + socketPath, port.Addr().String()} {
+		conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.Nil(t, err)
+		res, err := schemaGrpcV1.NewServiceClient(conn).ResolveBoolean(
+			context.Background(), &schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}, grpc.WaitForReady(true),
+		)
+		require.Nil(t, err, target)
+		require.True(t, res.Value, target)
+		require.Nil(t, conn.Close())
+	}
+
+	// shutting down closes both listeners
+	cancel()
+	select {
+	case err := <-served:
+		require.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the service didn't shut down")
+	}
+	_, err = net.Dial("unix", socketPath)
+	require.NotNil(t, err)
+	_, err = net.Dial("tcp", port.Addr().String())
+	require.NotNil(t, err)
+}
+
+func TestServeSourceStatuses(t *testing.T) {
+	rec := httptest.NewRecorder()
+	serveSourceStatuses(rec, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	statuses := isync.NewSourceStatuses("a.json")
+	statuses.RecordError("a.json", errors.New("invalid configuration"), time.Unix(100, 0).UTC())
+	rec = httptest.NewRecorder()
+	serveSourceStatuses(rec, statuses)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `[{
+		"source": "a.json",
+		"lastSync": "0001-01-01T00:00:00Z",
+		"lastError": "invalid configuration",
+		"lastErrorTime": "1970-01-01T00:01:40Z",
+		"updates": 0,
+		"errors": 1
+	}]`, rec.Body.String())
+}
+
+func TestConnectService_GRPCWeb(t *testing.T) {
+	tests := map[string]struct {
+		disableGRPCWeb bool
+		wantCode       connect.Code
+	}{
+		"enabled":  {},
+		"disabled": {disableGRPCWeb: true, wantCode: connect.CodeUnknown},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "myBoolFlag": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" }
+  }
+}`)
+			require.Nil(t, err)
+			svc := ConnectService{
+				ConnectServiceConfiguration: &ConnectServiceConfiguration{
+					CORS:           []string{"https://app.faas.com"},
+					DisableGRPCWeb: tt.disableGRPCWeb,
+				},
+				Eval:    evaluator,
+				Logger:  logger.NewLogger(nil, false),
+				Metrics: otel.NewOTelRecorder(metric.NewManualReader(), name),
+				eventingConfiguration: &eventingConfiguration{
+					subs: make(map[interface{}]chan iservice.Notification),
+					mu:   &sync.RWMutex{},
+				},
+			}
+			server := httptest.NewServer(svc.newCORS().Handler(svc.serviceHandler()))
+			defer server.Close()
+
+			// gRPC-web is served over HTTP/1.1, as used by browsers
+			client := schemaConnectV1.NewServiceClient(server.Client(), server.URL, connect.WithGRPCWeb())
+			req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+			req.Header().Set("Origin", "https://app.faas.com")
+			res, err := client.ResolveBoolean(context.Background(), req)
+			if tt.disableGRPCWeb {
+				require.Equal(t, tt.wantCode, connect.CodeOf(err))
+				return
+			}
+			require.Nil(t, err)
+			require.True(t, res.Msg.Value)
+			require.Equal(t, "on", res.Msg.Variant)
+			require.Equal(t, model.StaticReason, res.Msg.Reason)
+			require.Equal(t, "https://app.faas.com", res.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// UnsupportedContextValues defines how evaluation context values which aren't representable as json are handled,
+// i.e. values without kind and non-finite numbers, as sent by buggy clients
+type UnsupportedContextValues string
+
+const (
+	// UnsupportedContextValuesDrop evaluates the context without its unsupported values, logging a warning, the
+	// default
+	UnsupportedContextValuesDrop UnsupportedContextValues = "drop"
+	// UnsupportedContextValuesError rejects contexts holding unsupported values with an invalid context error
+	UnsupportedContextValuesError UnsupportedContextValues = "error"
+)
+
+// maxExactInteger is the largest integer magnitude float64 numbers, and therefore structpb numbers, hold exactly
+const maxExactInteger = 1 << 53
+
+// ParseUnsupportedContextValues returns the unsupported context value policy of its name, an empty name defaults to
+// drop
+func ParseUnsupportedContextValues(policy string) (UnsupportedContextValues, error) {
+	switch UnsupportedContextValues(policy) {
+	case "":
+		return UnsupportedContextValuesDrop, nil
+	case UnsupportedContextValuesDrop, UnsupportedContextValuesError:
+		return UnsupportedContextValues(policy), nil
+	default:
+		return "", fmt.Errorf("unknown unsupported context value policy: '%s', expected '%s' or '%s'",
+			policy, UnsupportedContextValuesDrop, UnsupportedContextValuesError)
+	}
+}
+
+// WithUnsupportedContextValues sets the policy of evaluation context values which aren't representable as json
+func WithUnsupportedContextValues(policy UnsupportedContextValues) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.unsupportedContextValues = policy
+	}
+}
+
+// WithStrictContextConversion rejects evaluation contexts holding any value requiring a lossy conversion, i.e. values
+// which would be dropped as they aren't representable as json and integers beyond the exact integer range of numbers,
+// whatever the unsupported context value policy
+func WithStrictContextConversion(strict bool) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.strictContextConversion = strict
+	}
+}
+
+// requestContext returns the context evaluating a request from its evaluation context, writing its log fields: an
+// empty context in place of a missing one, with the fallback targeting key if it has none, without the values which
+// aren't representable as json or exceed the maximum value size unless their policies reject them. Contexts without unsupported values are returned as
+// is. Valid contexts are sampled, if enabled.
+func (s *FlagEvaluationService) requestContext(reqID string, ctx *structpb.Struct) (*structpb.Struct, error) {
+	ctx = s.withFallbackTargetingKey(reqID, evaluationContext(ctx))
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(ctx)...)
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, err := s.limitContextValues(reqID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var unsupported []string
+	matchingFields(ctx.GetFields(), "", unsupportedValue, &unsupported)
+	sort.Strings(unsupported)
+	if s.strictContextConversion {
+		if err := s.lossyConversions(reqID, ctx, unsupported); err != nil {
+			return nil, err
+		}
+	}
+	if len(unsupported) == 0 {
+		s.sampleContext(ctx)
+		return ctx, nil
+	}
+	if s.unsupportedContextValues == UnsupportedContextValuesError {
+		return nil, &contextValidationError{violations: fieldViolations(unsupported, "%s isn't representable as json")}
+	}
+	s.logger.WarnWithID(reqID, fmt.Sprintf("dropping unsupported evaluation context values: %s",
+		strings.Join(unsupported, ", ")))
+	ctx = sanitizeStruct(ctx)
+	s.sampleContext(ctx)
+	return ctx, nil
+}
+
+// lossyConversions returns the invalid context error listing the unsupported values of the context and its inexact
+// numbers, nil if the context converts without loss
+func (s *FlagEvaluationService) lossyConversions(reqID string, ctx *structpb.Struct, unsupported []string) error {
+	var inexact []string
+	matchingFields(ctx.GetFields(), "", inexactNumber, &inexact)
+	sort.Strings(inexact)
+	if len(unsupported) == 0 && len(inexact) == 0 {
+		return nil
+	}
+	s.logger.WarnWithID(reqID, fmt.Sprintf("rejecting evaluation context values requiring a lossy conversion: %s",
+		strings.Join(append(append([]string{}, unsupported...), inexact...), ", ")))
+	violations := fieldViolations(unsupported, "%s isn't representable as json")
+	violations = append(violations, fieldViolations(inexact,
+		"%s is beyond the exact integer range of numbers (2^53), it may have lost precision")...)
+	return &contextValidationError{violations: violations}
+}
+
+// fieldViolations returns the violations of the fields, their description formatted with the field
+func fieldViolations(fields []string, description string) []*errdetails.BadRequest_FieldViolation {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for _, field := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf(description, field),
+		})
+	}
+	return violations
+}
+
+// unsupportedValue returns whether the value isn't representable as json, the values of structs and lists aside
+func unsupportedValue(v *structpb.Value) bool {
+	return !supportedValue(v)
+}
+
+// inexactNumber returns whether the value is a number beyond the exact integer range of float64 numbers, e.g. a
+// large integer id which lost precision converting to a structpb number
+func inexactNumber(v *structpb.Value) bool {
+	n, ok := v.GetKind().(*structpb.Value_NumberValue)
+	return ok && math.Abs(n.NumberValue) >= maxExactInteger
+}
+
+// supportedValue returns whether the value is representable as json, the values of structs and lists aside
+func supportedValue(v *structpb.Value) bool {
+	switch kind := v.GetKind().(type) {
+	case nil:
+		return false
+	case *structpb.Value_NumberValue:
+		return !math.IsNaN(kind.NumberValue) && !math.IsInf(kind.NumberValue, 0)
+	default:
+		return true
+	}
+}
+
+// matchingFields collects the paths of the values of the fields matching, nested fields being joined by dots and
+// list elements indexed, e.g. user.tags[1]. The values nested in a matching value aren't matched.
+func matchingFields(
+	fields map[string]*structpb.Value, path string, match func(*structpb.Value) bool, matching *[]string,
+) {
+	for key, value := range fields {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		matchingValues(value, fieldPath, match, matching)
+	}
+}
+
+func matchingValues(value *structpb.Value, path string, match func(*structpb.Value) bool, matching *[]string) {
+	if match(value) {
+		*matching = append(*matching, path)
+		return
+	}
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		matchingFields(kind.StructValue.GetFields(), path, match, matching)
+	case *structpb.Value_ListValue:
+		for i, element := range kind.ListValue.GetValues() {
+			matchingValues(element, fmt.Sprintf("%s[%d]", path, i), match, matching)
+		}
+	}
+}
+
+// sanitizeStruct returns a copy of the struct without its unsupported values, unsupported list elements are removed
+// from their list
+func sanitizeStruct(s *structpb.Struct) *structpb.Struct {
+	fields := make(map[string]*structpb.Value, len(s.GetFields()))
+	for key, value := range s.GetFields() {
+		if sanitized, ok := sanitizeValue(value); ok {
+			fields[key] = sanitized
+		}
+	}
+	return &structpb.Struct{Fields: fields}
+}
+
+func sanitizeValue(value *structpb.Value) (*structpb.Value, bool) {
+	if !supportedValue(value) {
+		return nil, false
+	}
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		return structpb.NewStructValue(sanitizeStruct(kind.StructValue)), true
+	case *structpb.Value_ListValue:
+		values := make([]*structpb.Value, 0, len(kind.ListValue.GetValues()))
+		for _, element := range kind.ListValue.GetValues() {
+			if sanitized, ok := sanitizeValue(element); ok {
+				values = append(values, sanitized)
+			}
+		}
+		return structpb.NewListValue(&structpb.ListValue{Values: values}), true
+	default:
+		return value, true
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// WithContextHeaders maps request headers, e.g. the gRPC metadata set by a gateway, into the evaluation context of
+// the resolve requests, keyed by header name to the context key they're mapped to. The context of the request body
+// takes precedence over the mapped headers.
+func WithContextHeaders(headers map[string]string) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		if len(headers) == 0 {
+			return
+		}
+		s.contextHeaders = make(map[string]string, len(headers))
+		for header, key := range headers {
+			s.contextHeaders[http.CanonicalHeaderKey(header)] = key
+		}
+	}
+}
+
+// headerContext returns the evaluation context merged with the values of the mapped headers of the request, the
+// context is returned as is without a mapped header set. Keys of the context aren't overridden by the headers, and
+// only the first value of headers set more than once is mapped.
+func (s *FlagEvaluationService) headerContext(ctx *structpb.Struct, header http.Header) *structpb.Struct {
+	var merged *structpb.Struct
+	for name, key := range s.contextHeaders {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		if _, ok := ctx.GetFields()[key]; ok {
+			continue
+		}
+		if _, ok := merged.GetFields()[key]; ok {
+			continue
+		}
+		if merged == nil {
+			// the context of the request is copied, it's owned by the request message
+			merged = &structpb.Struct{Fields: make(map[string]*structpb.Value, len(ctx.GetFields())+1)}
+			for k, v := range ctx.GetFields() {
+				merged.Fields[k] = v
+			}
+		}
+		merged.Fields[key] = structpb.NewStringValue(value)
+	}
+	if merged == nil {
+		return ctx
+	}
+	return merged
+}
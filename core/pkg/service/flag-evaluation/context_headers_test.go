@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const contextHeadersFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF", "green": "#00FF00" },
+      "defaultVariant": "red",
+      "targeting": {
+        "if": [
+          { "==": [{ "var": "plan" }, "premium"] }, "blue",
+          { "==": [{ "var": "plan" }, "free"] }, "green",
+          null
+        ]
+      }
+    }
+  }
+}`
+
+func TestContextHeaders(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextHeadersFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		WithContextHeaders(map[string]string{"x-user-plan": "plan", "x-user-region": "region"}))
+
+	tests := map[string]struct {
+		context map[string]interface{}
+		header  map[string]string
+		variant string
+	}{
+		"header mapped into the context": {
+			header:  map[string]string{"X-User-Plan": "premium"},
+			variant: "blue",
+		},
+		"header names are case insensitive": {
+			header:  map[string]string{"x-user-plan": "premium"},
+			variant: "blue",
+		},
+		"body context takes precedence": {
+			context: map[string]interface{}{"plan": "free"},
+			header:  map[string]string{"X-User-Plan": "premium"},
+			variant: "green",
+		},
+		"unmapped header is ignored": {
+			header:  map[string]string{"X-Plan": "premium"},
+			variant: "red",
+		},
+		"no header": {
+			context: map[string]interface{}{"plan": "premium"},
+			variant: "blue",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx, err := structpb.NewStruct(tt.context)
+			require.Nil(t, err)
+			req := connect.NewRequest(&schemaV1.ResolveStringRequest{FlagKey: "headerColor", Context: evalCtx})
+			for key, value := range tt.header {
+				req.Header().Set(key, value)
+			}
+			res, err := s.ResolveString(context.Background(), req)
+			require.Nil(t, err)
+			require.Equal(t, tt.variant, res.Msg.GetVariant())
+			require.Equal(t, len(tt.context), len(evalCtx.GetFields()), "the request context isn't modified")
+		})
+	}
+}
+
+func TestContextHeaders_ResolveAll(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, contextHeadersFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		WithContextHeaders(map[string]string{"x-user-plan": "plan"}))
+
+	req := connect.NewRequest(&schemaV1.ResolveAllRequest{})
+	req.Header().Set("X-User-Plan", "premium")
+	res, err := s.ResolveAll(context.Background(), req)
+	require.Nil(t, err)
+	require.Equal(t, "blue", res.Msg.GetFlags()["headerColor"].GetVariant())
+}
+
+func TestHeaderContext(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil,
+		WithContextHeaders(map[string]string{"x-user-plan": "plan", "x-plan": "plan"}))
+	header := http.Header{}
+	header.Set("X-User-Plan", "premium")
+	header.Add("X-User-Plan", "free")
+
+	merged := s.headerContext(nil, header)
+	require.Equal(t, map[string]interface{}{"plan": "premium"}, merged.AsMap(), "the first value is mapped")
+
+	ctx := &structpb.Struct{}
+	require.Same(t, ctx, s.headerContext(ctx, http.Header{}), "contexts without mapped headers are returned as is")
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// ContextKeysPath returns the evaluation context keys a flag reads, e.g. /context-keys?flagKey=my-flag
+const ContextKeysPath = "/context-keys"
+
+type contextKeysResponse struct {
+	FlagKey     string   `json:"flagKey"`
+	ContextKeys []string `json:"contextKeys"`
+	// Unbounded is whether the flag may read any key of the context, which can't be pruned to ContextKeys then
+	Unbounded bool `json:"unbounded"`
+}
+
+// ContextKeysHandler returns the context keys the evaluation of a flag reads, computed from its current definition,
+// so clients can send the flag only the context it reads, minimizing payloads and the personal data they carry
+func (s *FlagEvaluationService) ContextKeysHandler() http.Handler {
+	return http.HandlerFunc(s.serveContextKeys)
+}
+
+func (s *FlagEvaluationService) serveContextKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	analysis, ok := s.evaluator().(eval.ContextKeys)
+	if !ok {
+		http.Error(w, "the evaluator can't analyse the context keys of flags", http.StatusNotImplemented)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	if flagKey == "" {
+		http.Error(w, "flagKey query parameter is required", http.StatusBadRequest)
+		return
+	}
+	keys, ok := analysis.FlagContextKeys(flagKey)
+	if !ok {
+		http.Error(w, model.FlagNotFoundErrorCode, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(contextKeysResponse{
+		FlagKey:     flagKey,
+		ContextKeys: keys.Keys,
+		Unbounded:   keys.Unbounded,
+	}); err != nil {
+		s.logger.Error(fmt.Sprintf("encoding context keys of flag %s: %v", flagKey, err))
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextKeysHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "checkout": {
+      "state": "ENABLED",
+      "variants": { "control": "control", "beta": "beta" },
+      "defaultVariant": "control",
+      "targeting": {
+        "if": [{ "and": [{ "in": ["@faas.com", { "var": "email" }] }, { "!": { "var": "user.banned" } }] }, "beta", null]
+      }
+    },
+    "anyKey": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["x", { "var": "" }] }, "on", null] }
+    }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.ContextKeysHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		query        string
+		wantCode     int
+		wantResponse contextKeysResponse
+	}{
+		"referenced keys": {
+			query:    "?flagKey=checkout",
+			wantCode: http.StatusOK,
+			wantResponse: contextKeysResponse{
+				FlagKey: "checkout", ContextKeys: []string{"email", "user.banned"},
+			},
+		},
+		"whole context": {
+			query:        "?flagKey=anyKey",
+			wantCode:     http.StatusOK,
+			wantResponse: contextKeysResponse{FlagKey: "anyKey", ContextKeys: []string{}, Unbounded: true},
+		},
+		"missing flag": {
+			query:    "?flagKey=missing",
+			wantCode: http.StatusNotFound,
+		},
+		"without flag key": {
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := http.Get(server.URL + tt.query)
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got contextKeysResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Equal(t, tt.wantResponse, got)
+		})
+	}
+
+	res, err := http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// redactedValue replaces the value of evaluation context keys which aren't allowed to be logged
+const redactedValue = "[REDACTED]"
+
+// WithLogContextKeys logs the values of the provided evaluation context keys alongside each evaluation, the values
+// of any other key are redacted. Evaluations still use the full context. The targeting key is logged by its hash, the
+// override token is always redacted.
+func WithLogContextKeys(keys []string) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		for _, key := range keys {
+			s.logContextKeys.allowed[key] = struct{}{}
+		}
+	}
+}
+
+// contextKeys is the set of evaluation context keys whose values are safe to log, along with the hasher of the
+// targeting key
+type contextKeys struct {
+	allowed      map[string]struct{}
+	targetingKey targetingKeyHasher
+}
+
+func newContextKeys() contextKeys {
+	return contextKeys{allowed: map[string]struct{}{}, targetingKey: newTargetingKeyHasher("")}
+}
+
+// fields returns the log fields describing an evaluation context, only the keys are logged unless some are allowed
+func (k contextKeys) fields(ctx *structpb.Struct) []zap.Field {
+	fields := []zap.Field{zap.Strings("context-keys", formatContextKeys(ctx))}
+	if len(k.allowed) == 0 {
+		return fields
+	}
+	return append(fields, zap.Any("context", k.redact(ctx)))
+}
+
+func (k contextKeys) redact(ctx *structpb.Struct) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(ctx.GetFields()))
+	for key, value := range ctx.GetFields() {
+		_, ok := k.allowed[key]
+		switch {
+		case !ok || key == eval.OverrideTokenContextKey:
+			redacted[key] = redactedValue
+		case key == targetingKeyField && value.GetStringValue() != "":
+			redacted[key] = k.targetingKey.hash(value.GetStringValue())
+		default:
+			redacted[key] = value.AsInterface()
+		}
+	}
+	return redacted
+}
+
+// verbose returns the values of an evaluation context logged by the evaluations logged verbosely: the values of
+// every key unless some are allowed, the values of the allowed keys otherwise. The targeting key is replaced with its
+// hash and the override token is always redacted.
+func (k contextKeys) verbose(ctx *structpb.Struct) map[string]interface{} {
+	if len(k.allowed) != 0 {
+		return k.redact(ctx)
+	}
+	values := ctx.AsMap()
+	if targetingKey := ctx.GetFields()[targetingKeyField].GetStringValue(); targetingKey != "" {
+		values[targetingKeyField] = k.targetingKey.hash(targetingKey)
+	}
+	if _, ok := values[eval.OverrideTokenContextKey]; ok {
+		values[eval.OverrideTokenContextKey] = redactedValue
+	}
+	return values
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ContextSnapshotsPath lists the registered evaluation context snapshots, registers one with a PUT request or
+	// deletes one with a DELETE request, it's only served with the admin API
+	ContextSnapshotsPath = "/admin/context-snapshots"
+	// ContextSnapshotEvaluationPath evaluates a stored flag against a registered snapshot, it's only served with the
+	// admin API
+	ContextSnapshotEvaluationPath = "/admin/context-snapshots/evaluate"
+	// maxContextSnapshots bounds the snapshots held in memory, registering more is rejected until some are deleted
+	maxContextSnapshots = 100
+)
+
+type contextSnapshotRequest struct {
+	ID      string                 `json:"id"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type contextSnapshotEvaluationRequest struct {
+	ID      string `json:"id"`
+	FlagKey string `json:"flagKey"`
+}
+
+// contextSnapshotInfo describes a registered snapshot, its values are never returned as they may hold personal data
+type contextSnapshotInfo struct {
+	ID           string    `json:"id"`
+	Keys         []string  `json:"keys"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+type contextSnapshotsResponse struct {
+	Snapshots []contextSnapshotInfo `json:"snapshots"`
+}
+
+type contextSnapshot struct {
+	context      *structpb.Struct
+	registeredAt time.Time
+}
+
+// contextSnapshots holds the evaluation contexts registered by id, e.g. the context sent by a client during an
+// incident, so they can be evaluated again without sending them
+type contextSnapshots struct {
+	mu        sync.RWMutex
+	snapshots map[string]contextSnapshot
+}
+
+func newContextSnapshots() *contextSnapshots {
+	return &contextSnapshots{snapshots: map[string]contextSnapshot{}}
+}
+
+// register stores the context under the id, replacing the snapshot of the same id, and reports whether there was
+// room for it
+func (c *contextSnapshots) register(id string, ctx *structpb.Struct, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.snapshots[id]; !ok && len(c.snapshots) >= maxContextSnapshots {
+		return false
+	}
+	c.snapshots[id] = contextSnapshot{context: ctx, registeredAt: now}
+	return true
+}
+
+func (c *contextSnapshots) get(id string) (*structpb.Struct, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	snapshot, ok := c.snapshots[id]
+	return snapshot.context, ok
+}
+
+func (c *contextSnapshots) delete(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.snapshots[id]
+	delete(c.snapshots, id)
+	return ok
+}
+
+func (c *contextSnapshots) list() []contextSnapshotInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	infos := make([]contextSnapshotInfo, 0, len(c.snapshots))
+	for id, snapshot := range c.snapshots {
+		keys := formatContextKeys(snapshot.context)
+		sort.Strings(keys)
+		infos = append(infos, contextSnapshotInfo{ID: id, Keys: keys, RegisteredAt: snapshot.registeredAt})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// ContextSnapshotsHandler lists the registered evaluation context snapshots by id along with their keys, registers
+// the snapshot of a PUT request, replacing the snapshot of the same id, or deletes the snapshot of the id query
+// parameter of a DELETE request. Snapshots are only held in memory.
+func (s *FlagEvaluationService) ContextSnapshotsHandler() http.Handler {
+	return http.HandlerFunc(s.serveContextSnapshots)
+}
+
+func (s *FlagEvaluationService) serveContextSnapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		if !s.registerContextSnapshot(w, r) {
+			return
+		}
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if !s.contextSnapshots.delete(id) {
+			http.Error(w, fmt.Sprintf("context snapshot: '%s' isn't registered", id), http.StatusNotFound)
+			return
+		}
+		s.logger.Info(fmt.Sprintf("deleted context snapshot: %s", id))
+	default:
+		w.Header().Set("Allow", fmt.Sprintf("%s, %s, %s", http.MethodGet, http.MethodPut, http.MethodDelete))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(contextSnapshotsResponse{Snapshots: s.contextSnapshots.list()}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// registerContextSnapshot registers the snapshot of the request, reporting whether it was registered, the error
+// response is written otherwise
+func (s *FlagEvaluationService) registerContextSnapshot(w http.ResponseWriter, r *http.Request) bool {
+	var req contextSnapshotRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return false
+	}
+	if req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return false
+	}
+	ctx, err := structpb.NewStruct(req.Context)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+		return false
+	}
+	if err := validateContext(ctx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if !s.contextSnapshots.register(req.ID, ctx, time.Now()) {
+		http.Error(w, fmt.Sprintf("%d context snapshots are registered, delete some first", maxContextSnapshots),
+			http.StatusConflict)
+		return false
+	}
+	s.logger.Info(fmt.Sprintf("registered context snapshot: %s", req.ID))
+	return true
+}
+
+// ContextSnapshotEvaluationHandler evaluates a stored flag against a registered context snapshot, replaying the
+// context against the current configuration. The response is traced like the sandbox responses.
+func (s *FlagEvaluationService) ContextSnapshotEvaluationHandler() http.Handler {
+	return http.HandlerFunc(s.serveContextSnapshotEvaluation)
+}
+
+func (s *FlagEvaluationService) serveContextSnapshotEvaluation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	whatIf, ok := s.evaluatorOf(ReadReplicaWhatIf).(eval.WhatIf)
+	if !ok {
+		http.Error(w, "the evaluator can't evaluate stored flags", http.StatusNotImplemented)
+		return
+	}
+	var req contextSnapshotEvaluationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	evalCtx, ok := s.contextSnapshots.get(req.ID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("context snapshot: '%s' isn't registered", req.ID), http.StatusNotFound)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	// evaluating without overrides evaluates the stored flag as is
+	result, err := whatIf.EvaluateWhatIf(reqID, req.FlagKey, nil, evalCtx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sandboxResponse{
+		FlagKey:   req.FlagKey,
+		Value:     result.Value,
+		Variant:   result.Variant,
+		Reason:    result.Reason,
+		Metadata:  result.Metadata,
+		ErrorCode: result.ErrorCode,
+		Trace:     result.Trace,
+	}); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding context snapshot evaluation response: %v", err))
+	}
+}
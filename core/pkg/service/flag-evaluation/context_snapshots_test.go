@@ -0,0 +1,114 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSnapshots(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	snapshots := httptest.NewServer(s.ContextSnapshotsHandler())
+	defer snapshots.Close()
+	evaluations := httptest.NewServer(s.ContextSnapshotEvaluationHandler())
+	defer evaluations.Close()
+
+	do := func(method string, url string, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.Nil(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	evaluate := func(id string) sandboxResponse {
+		t.Helper()
+		res := do(http.MethodPost, evaluations.URL, fmt.Sprintf(`{"id": %q, "flagKey": "beta"}`, id))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var body sandboxResponse
+		require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+		return body
+	}
+
+	res := do(http.MethodPut, snapshots.URL, `{"id": "incident-42", "context": {"email": "user@faas.com", "plan": "pro"}}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var list contextSnapshotsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list.Snapshots, 1)
+	require.Equal(t, "incident-42", list.Snapshots[0].ID)
+	require.Equal(t, []string{"email", "plan"}, list.Snapshots[0].Keys, "only the keys of snapshots are listed")
+
+	result := evaluate("incident-42")
+	require.Equal(t, true, result.Value)
+	require.Equal(t, model.TargetingMatchReason, result.Reason)
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: comparisonCandidate, Source: "flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+	result = evaluate("incident-42")
+	require.Equal(t, false, result.Value, "snapshots are replayed against the current configuration")
+	require.Equal(t, model.DefaultReason, result.Reason)
+
+	res = do(http.MethodPost, evaluations.URL, `{"id": "unknown", "flagKey": "beta"}`)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res = do(http.MethodDelete, snapshots.URL+"?id=incident-42", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res = do(http.MethodPost, evaluations.URL, `{"id": "incident-42", "flagKey": "beta"}`)
+	require.Equal(t, http.StatusNotFound, res.StatusCode, "deleted snapshots can't be evaluated")
+	res = do(http.MethodDelete, snapshots.URL+"?id=incident-42", "")
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestContextSnapshots_Invalid(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	tests := map[string]struct {
+		method   string
+		body     string
+		wantCode int
+	}{
+		"invalid json":          {method: http.MethodPut, body: `{`, wantCode: http.StatusBadRequest},
+		"missing id":            {method: http.MethodPut, body: `{"context": {}}`, wantCode: http.StatusBadRequest},
+		"invalid targeting key": {method: http.MethodPut, body: `{"id": "a", "context": {"targetingKey": 1}}`, wantCode: http.StatusBadRequest},
+		"unsupported method":    {method: http.MethodPost, body: `{}`, wantCode: http.StatusMethodNotAllowed},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			s.ContextSnapshotsHandler().ServeHTTP(res,
+				httptest.NewRequest(tt.method, ContextSnapshotsPath, strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantCode, res.Code)
+		})
+	}
+
+	res := httptest.NewRecorder()
+	s.ContextSnapshotEvaluationHandler().ServeHTTP(res, httptest.NewRequest(http.MethodPost,
+		ContextSnapshotEvaluationPath, strings.NewReader(`{"id": "a", "flagKey": "beta"}`)))
+	require.Equal(t, http.StatusNotImplemented, res.Code)
+}
+
+func TestContextSnapshots_Bounded(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	register := func(id string) int {
+		res := httptest.NewRecorder()
+		s.ContextSnapshotsHandler().ServeHTTP(res, httptest.NewRequest(http.MethodPut, ContextSnapshotsPath,
+			strings.NewReader(fmt.Sprintf(`{"id": %q, "context": {}}`, id))))
+		return res.Code
+	}
+	for i := 0; i < maxContextSnapshots; i++ {
+		require.Equal(t, http.StatusOK, register(fmt.Sprintf("snapshot-%d", i)))
+	}
+	require.Equal(t, http.StatusConflict, register("one-too-many"))
+	require.Equal(t, http.StatusOK, register("snapshot-0"), "registered snapshots can be replaced")
+}
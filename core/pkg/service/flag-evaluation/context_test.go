@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/golang/mock/gomock"
+	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRequestContext(t *testing.T) {
+	tests := map[string]struct {
+		ctx         *structpb.Struct
+		want        map[string]interface{}
+		unsupported []string
+	}{
+		"missing context": {
+			want: map[string]interface{}{},
+		},
+		"supported values": {
+			ctx: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"email":  structpb.NewStringValue("user@faas.com"),
+				"age":    structpb.NewNumberValue(42),
+				"beta":   structpb.NewBoolValue(true),
+				"null":   structpb.NewNullValue(),
+				"nested": structpb.NewStructValue(&structpb.Struct{}),
+				"roles":  structpb.NewListValue(&structpb.ListValue{}),
+			}},
+			want: map[string]interface{}{
+				"email": "user@faas.com", "age": float64(42), "beta": true, "null": nil,
+				"nested": map[string]interface{}{}, "roles": []interface{}{},
+			},
+		},
+		"value without kind": {
+			ctx: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"email": structpb.NewStringValue("user@faas.com"),
+				"empty": {},
+			}},
+			want:        map[string]interface{}{"email": "user@faas.com"},
+			unsupported: []string{"empty"},
+		},
+		"nil value": {
+			ctx:         &structpb.Struct{Fields: map[string]*structpb.Value{"nil": nil}},
+			want:        map[string]interface{}{},
+			unsupported: []string{"nil"},
+		},
+		"non-finite numbers": {
+			ctx: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"nan":  structpb.NewNumberValue(math.NaN()),
+				"inf":  structpb.NewNumberValue(math.Inf(1)),
+				"-inf": structpb.NewNumberValue(math.Inf(-1)),
+				"max":  structpb.NewNumberValue(math.MaxFloat64),
+			}},
+			want:        map[string]interface{}{"max": math.MaxFloat64},
+			unsupported: []string{"-inf", "inf", "nan"},
+		},
+		"nested values": {
+			ctx: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"user": structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+					"score": structpb.NewNumberValue(math.NaN()),
+					"name":  structpb.NewStringValue("ada"),
+					"tags": structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{
+						structpb.NewStringValue("a"), {}, structpb.NewStringValue("b"), nil,
+					}}),
+				}}),
+			}},
+			want: map[string]interface{}{"user": map[string]interface{}{
+				"name": "ada", "tags": []interface{}{"a", "b"},
+			}},
+			unsupported: []string{"user.score", "user.tags[1]", "user.tags[3]"},
+		},
+		"nil nested struct and list": {
+			ctx: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"nested": {Kind: &structpb.Value_StructValue{}},
+				"roles":  {Kind: &structpb.Value_ListValue{}},
+			}},
+			want: map[string]interface{}{"nested": map[string]interface{}{}, "roles": []interface{}{}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			s := NewFlagEvaluationService(logger.NewLogger(zap.New(core), true), nil, nil)
+			fields := len(tt.ctx.GetFields())
+
+			ctx, err := s.requestContext("", tt.ctx)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, ctx.AsMap())
+			require.Len(t, tt.ctx.GetFields(), fields, "the request context is left as is")
+			if len(tt.unsupported) == 0 {
+				require.Equal(t, 0, logs.Len())
+			} else {
+				require.Equal(t, 1, logs.FilterMessageSnippet("dropping unsupported evaluation context values").Len())
+			}
+
+			s = NewFlagEvaluationService(logger.NewLogger(zap.New(core), false), nil, nil,
+				WithUnsupportedContextValues(UnsupportedContextValuesError))
+			_, err = s.requestContext("", tt.ctx)
+			if len(tt.unsupported) == 0 {
+				require.Nil(t, err)
+				return
+			}
+			var vErr *contextValidationError
+			require.ErrorAs(t, err, &vErr)
+			var violations []string
+			for _, violation := range vErr.violations {
+				violations = append(violations, violation.Field)
+			}
+			require.Equal(t, tt.unsupported, violations)
+		})
+	}
+}
+
+func TestUnsupportedContextValues(t *testing.T) {
+	ctx := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"email": structpb.NewStringValue("user@faas.com"),
+		"score": structpb.NewNumberValue(math.Inf(1)),
+	}}
+
+	t.Run("dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		evaluator := mock.NewMockIEvaluator(ctrl)
+		evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).DoAndReturn(
+			func(
+				ctx context.Context, reqID string, flagKey string, evalCtx *structpb.Struct,
+			) (bool, string, string, map[string]interface{}, error) {
+				require.Equal(t, map[string]interface{}{"email": "user@faas.com"}, evalCtx.AsMap())
+				return true, "on", "STATIC", nil, nil
+			},
+		)
+		s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+		res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: ctx},
+		))
+		require.Nil(t, err)
+		require.True(t, res.Msg.Value)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		evaluator := mock.NewMockIEvaluator(ctrl)
+		s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+			WithUnsupportedContextValues(UnsupportedContextValuesError))
+
+		_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: ctx},
+		))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Len(t, connectErr.Details(), 1)
+		detail, err := connectErr.Details()[0].Value()
+		require.Nil(t, err)
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Equal(t, "score", badRequest.FieldViolations[0].Field)
+
+		_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{Context: ctx}))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestStrictContextConversion(t *testing.T) {
+	lossy := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"email":     structpb.NewStringValue("user@faas.com"),
+		"accountId": structpb.NewNumberValue(9007199254740993),
+		"user": structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"scores": structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewNumberValue(1), structpb.NewNumberValue(math.NaN()),
+			}}),
+		}}),
+	}}
+	clean, err := structpb.NewStruct(map[string]interface{}{
+		"email":     "user@faas.com",
+		"accountId": 9007199254740991,
+		"user":      map[string]interface{}{"scores": []interface{}{1, 2.5}},
+	})
+	require.Nil(t, err)
+
+	t.Run("lossy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		evaluator := mock.NewMockIEvaluator(ctrl)
+		s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, WithStrictContextConversion(true))
+
+		_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: lossy},
+		))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Len(t, connectErr.Details(), 1)
+		detail, err := connectErr.Details()[0].Value()
+		require.Nil(t, err)
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		require.True(t, ok)
+		fields := make([]string, 0, len(badRequest.FieldViolations))
+		for _, violation := range badRequest.FieldViolations {
+			fields = append(fields, violation.Field)
+		}
+		require.Equal(t, []string{"user.scores[1]", "accountId"}, fields)
+
+		_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{Context: lossy}))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		evaluator := mock.NewMockIEvaluator(ctrl)
+		evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).DoAndReturn(
+			func(
+				ctx context.Context, reqID string, flagKey string, evalCtx *structpb.Struct,
+			) (bool, string, string, map[string]interface{}, error) {
+				require.Equal(t, clean.AsMap(), evalCtx.AsMap())
+				return true, "on", "STATIC", nil, nil
+			},
+		)
+		s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, WithStrictContextConversion(true))
+
+		res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: clean},
+		))
+		require.Nil(t, err)
+		require.True(t, res.Msg.Value)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		evaluator := mock.NewMockIEvaluator(ctrl)
+		evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).DoAndReturn(
+			func(
+				ctx context.Context, reqID string, flagKey string, evalCtx *structpb.Struct,
+			) (bool, string, string, map[string]interface{}, error) {
+				require.Equal(t, float64(9007199254740993), evalCtx.AsMap()["accountId"])
+				require.Equal(t, map[string]interface{}{"scores": []interface{}{float64(1)}}, evalCtx.AsMap()["user"])
+				return true, "on", "STATIC", nil, nil
+			},
+		)
+		s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+		_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: lossy},
+		))
+		require.Nil(t, err, "lossy contexts should be evaluated degraded unless strict")
+	})
+}
+
+func TestParseUnsupportedContextValues(t *testing.T) {
+	policy, err := ParseUnsupportedContextValues("")
+	require.Nil(t, err)
+	require.Equal(t, UnsupportedContextValuesDrop, policy)
+
+	policy, err = ParseUnsupportedContextValues("error")
+	require.Nil(t, err)
+	require.Equal(t, UnsupportedContextValuesError, policy)
+
+	_, err = ParseUnsupportedContextValues("ignore")
+	require.EqualError(t, err, "unknown unsupported context value policy: 'ignore', expected 'drop' or 'error'")
+}
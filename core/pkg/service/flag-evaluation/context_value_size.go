@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// OversizedContextValues defines how evaluation context values larger than the maximum value size are handled, e.g.
+// a serialized blob sent by mistake, which slows rule evaluation and logging
+type OversizedContextValues string
+
+const (
+	// OversizedContextValuesError rejects contexts holding oversized values with an invalid context error, the default
+	OversizedContextValuesError OversizedContextValues = "error"
+	// OversizedContextValuesDrop evaluates the context without its oversized values, logging a warning
+	OversizedContextValuesDrop OversizedContextValues = "drop"
+)
+
+// ParseOversizedContextValues returns the oversized context value policy of its name, an empty name defaults to error
+func ParseOversizedContextValues(policy string) (OversizedContextValues, error) {
+	switch OversizedContextValues(policy) {
+	case "":
+		return OversizedContextValuesError, nil
+	case OversizedContextValuesError, OversizedContextValuesDrop:
+		return OversizedContextValues(policy), nil
+	default:
+		return "", fmt.Errorf("unknown oversized context value policy: '%s', expected '%s' or '%s'",
+			policy, OversizedContextValuesError, OversizedContextValuesDrop)
+	}
+}
+
+// WithMaxContextValueBytes bounds the size of each value of the evaluation contexts, handling larger values with the
+// policy. Values are unbounded when 0.
+func WithMaxContextValueBytes(maxBytes int, policy OversizedContextValues) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.maxContextValueBytes = maxBytes
+		s.oversizedContextValues = policy
+	}
+}
+
+// limitContextValues returns the context without its values exceeding the maximum value size if the policy drops
+// them, an invalid context error listing them otherwise. Contexts without oversized values are returned as is.
+func (s *FlagEvaluationService) limitContextValues(reqID string, ctx *structpb.Struct) (*structpb.Struct, error) {
+	if s.maxContextValueBytes <= 0 {
+		return ctx, nil
+	}
+	var oversized []string
+	for key, value := range ctx.GetFields() {
+		if contextValueSize(value) > s.maxContextValueBytes {
+			oversized = append(oversized, key)
+		}
+	}
+	if len(oversized) == 0 {
+		return ctx, nil
+	}
+	sort.Strings(oversized)
+	if s.oversizedContextValues != OversizedContextValuesDrop {
+		return nil, &contextValidationError{violations: fieldViolations(oversized,
+			fmt.Sprintf("%%s exceeds the maximum evaluation context value size of %d bytes", s.maxContextValueBytes))}
+	}
+	s.logger.WarnWithID(reqID, fmt.Sprintf("dropping evaluation context values exceeding %d bytes: %s",
+		s.maxContextValueBytes, strings.Join(oversized, ", ")))
+	fields := make(map[string]*structpb.Value, len(ctx.GetFields()))
+	for key, value := range ctx.GetFields() {
+		fields[key] = value
+	}
+	for _, key := range oversized {
+		delete(fields, key)
+	}
+	return &structpb.Struct{Fields: fields}, nil
+}
+
+// contextValueSize returns the size of a value: the length in bytes of strings, the length of the json encoding of
+// other values, nested values included. Values which aren't representable as json aren't counted, they're handled by
+// the unsupported context value policy.
+func contextValueSize(value *structpb.Value) int {
+	if s, ok := value.GetKind().(*structpb.Value_StringValue); ok {
+		return len(s.StringValue)
+	}
+	encoded, err := value.MarshalJSON()
+	if err != nil {
+		sanitized, ok := sanitizeValue(value)
+		if !ok {
+			return 0
+		}
+		if encoded, err = sanitized.MarshalJSON(); err != nil {
+			return 0
+		}
+	}
+	return len(encoded)
+}
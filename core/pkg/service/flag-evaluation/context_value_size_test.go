@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/golang/mock/gomock"
+	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestContextValueSize(t *testing.T) {
+	nested, err := structpb.NewValue(map[string]interface{}{"a": 1})
+	require.Nil(t, err)
+	require.Equal(t, 5, contextValueSize(structpb.NewStringValue("abcde")))
+	// multi-byte characters count their bytes
+	require.Equal(t, 2, contextValueSize(structpb.NewStringValue("é")))
+	require.Equal(t, 7, contextValueSize(nested))
+	require.Equal(t, 4, contextValueSize(structpb.NewBoolValue(true)))
+}
+
+func TestMaxContextValueBytes(t *testing.T) {
+	ctx := func(plan string) *structpb.Struct {
+		return &structpb.Struct{Fields: map[string]*structpb.Value{
+			"email": structpb.NewStringValue("user@faas.com"),
+			"plan":  structpb.NewStringValue(plan),
+		}}
+	}
+	atLimit := strings.Repeat("a", 16)
+
+	tests := map[string]struct {
+		plan    string
+		policy  OversizedContextValues
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		"at the limit": {
+			plan: atLimit,
+			want: map[string]interface{}{"email": "user@faas.com", "plan": atLimit},
+		},
+		"beyond the limit": {plan: atLimit + "a", wantErr: true},
+		"dropped beyond the limit": {
+			plan:   atLimit + "a",
+			policy: OversizedContextValuesDrop,
+			want:   map[string]interface{}{"email": "user@faas.com"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			evaluator := mock.NewMockIEvaluator(ctrl)
+			if !tt.wantErr {
+				evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).DoAndReturn(
+					func(
+						ctx context.Context, reqID string, flagKey string, evalCtx *structpb.Struct,
+					) (bool, string, string, map[string]interface{}, error) {
+						require.Equal(t, tt.want, evalCtx.AsMap())
+						return true, "on", "STATIC", nil, nil
+					},
+				)
+			}
+			policy := tt.policy
+			if policy == "" {
+				policy = OversizedContextValuesError
+			}
+			s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+				WithMaxContextValueBytes(16, policy))
+
+			_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+				&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: ctx(tt.plan)},
+			))
+			if !tt.wantErr {
+				require.Nil(t, err)
+				return
+			}
+			require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			require.Len(t, connectErr.Details(), 1)
+			detail, err := connectErr.Details()[0].Value()
+			require.Nil(t, err)
+			badRequest, ok := detail.(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Equal(t, []*errdetails.BadRequest_FieldViolation{{
+				Field:       "plan",
+				Description: "plan exceeds the maximum evaluation context value size of 16 bytes",
+			}}, badRequest.FieldViolations)
+		})
+	}
+}
+
+func TestParseOversizedContextValues(t *testing.T) {
+	policy, err := ParseOversizedContextValues("")
+	require.Nil(t, err)
+	require.Equal(t, OversizedContextValuesError, policy)
+
+	policy, err = ParseOversizedContextValues("drop")
+	require.Nil(t, err)
+	require.Equal(t, OversizedContextValuesDrop, policy)
+
+	_, err = ParseOversizedContextValues("truncate")
+	require.EqualError(t, err, "unknown oversized context value policy: 'truncate', expected 'error' or 'drop'")
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// DeltaPath resolves the flags changed since the configuration version identified by a client token
+const DeltaPath = "/resolve-delta"
+
+type deltaRequest struct {
+	// Token is the configuration version last seen by the client, an empty token resolves every flag
+	Token   string                 `json:"token"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type deltaResponse struct {
+	Token string `json:"token"`
+	// Full is set when the token is empty, unknown or too old, Flags then holds every flag and the client
+	// replaces its cache
+	Full  bool                              `json:"full"`
+	Flags map[string]map[string]interface{} `json:"flags"`
+	// Removed lists the changed flags which can no longer be resolved, e.g. deleted or disabled flags
+	Removed []string `json:"removed"`
+}
+
+// DeltaHandler resolves the flags whose definition changed since the configuration version of the token sent by
+// the client, together with the token of the current version. Tokens are derived from the change notifications of
+// the event stream, tokens issued by another flagd instance or before a restart resolve every flag.
+func (s *FlagEvaluationService) DeltaHandler() http.Handler {
+	return http.HandlerFunc(s.serveDelta)
+}
+
+func (s *FlagEvaluationService) serveDelta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.checkEnabled(ResolveTypeAll); err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	var req deltaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the changes are collected before evaluating, so a concurrent change is resolved again by the next request
+	changed, latest, full := s.changedFlagsSince(req.Token)
+	res := deltaResponse{
+		Token:   s.eventingConfiguration.history.token(latest),
+		Full:    full,
+		Flags:   map[string]map[string]interface{}{},
+		Removed: []string{},
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	for _, value := range s.evaluator().ResolveAllValues(r.Context(), reqID, evalCtx) {
+		if _, ok := changed[value.FlagKey]; full || ok {
+			res.Flags[value.FlagKey] = flagValue(value)
+		}
+	}
+	for key := range changed {
+		if _, ok := res.Flags[key]; !ok {
+			res.Removed = append(res.Removed, key)
+		}
+	}
+	sort.Strings(res.Removed)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding delta response: %v", err))
+	}
+}
+
+// changedFlagsSince returns the keys of the flags changed since the token, along with the id of the latest
+// notification. full is set if the changes can't be determined from the retained notifications.
+func (s *FlagEvaluationService) changedFlagsSince(token string) (map[string]struct{}, uint64, bool) {
+	history := &s.eventingConfiguration.history
+	lastID, ok := history.parseToken(token)
+	if !ok {
+		return nil, history.latest(), true
+	}
+	notifications, latest, missed := history.since(lastID)
+	if missed {
+		return nil, latest, true
+	}
+	changed := map[string]struct{}{}
+	for _, n := range notifications {
+		flags, ok := n.notification.Data["flags"].(map[string]interface{})
+		if !ok {
+			// notifications without flag details may have changed any flag
+			return nil, latest, true
+		}
+		for key := range flags {
+			changed[key] = struct{}{}
+		}
+	}
+	return changed, latest, false
+}
+
+// token identifies the configuration version following the notification id, ids are only meaningful to the
+// instance which issued them
+func (h *notificationHistory) token(id uint64) string {
+	return fmt.Sprintf("%s.%d", h.instance(), id)
+}
+
+// parseToken returns the notification id of a token issued by this instance
+func (h *notificationHistory) parseToken(token string) (uint64, bool) {
+	instance, rawID, found := strings.Cut(token, ".")
+	if !found || instance != h.instance() {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *notificationHistory) instance() string {
+	h.instanceOnce.Do(func() {
+		h.instanceID = xid.New().String()
+	})
+	return h.instanceID
+}
+
+func flagValue(value eval.AnyValue) map[string]interface{} {
+	return map[string]interface{}{
+		"value":   value.Value,
+		"variant": value.Variant,
+		"reason":  value.Reason,
+	}
+}
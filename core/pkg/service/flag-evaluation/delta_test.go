@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func postDelta(t *testing.T, url string, req deltaRequest) deltaResponse {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.Nil(t, err)
+	res, err := http.Post(url, "application/json", bytes.NewReader(body))
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var delta deltaResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&delta))
+	return delta
+}
+
+func TestDeltaHandler(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(nil, nil)
+	eventing := &eventingConfiguration{
+		subs: make(map[interface{}]chan service.Notification),
+		mu:   &sync.RWMutex{},
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, withEventingConfiguration(eventing))
+	server := httptest.NewServer(s.DeltaHandler())
+	defer server.Close()
+
+	// setGeneration applies a configuration the way the runtime does, notifying the changes
+	setGeneration := func(config string) {
+		notifications, _, err := evaluator.SetState(isync.DataSync{FlagData: config, Source: "flags.json"})
+		require.Nil(t, err)
+		eventing.notify(service.Notification{
+			Type: service.ConfigurationChange,
+			Data: map[string]interface{}{"flags": notifications},
+		})
+	}
+	setGeneration(`{
+  "flags": {
+    "a": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" },
+    "b": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" }
+  }
+}`)
+
+	first := postDelta(t, server.URL, deltaRequest{})
+	require.True(t, first.Full)
+	require.Len(t, first.Flags, 2)
+	require.Equal(t, false, first.Flags["a"]["value"])
+
+	require.Equal(t, deltaResponse{
+		Token: first.Token, Flags: map[string]map[string]interface{}{}, Removed: []string{},
+	}, postDelta(t, server.URL, deltaRequest{Token: first.Token}), "unchanged configuration")
+
+	setGeneration(`{
+  "flags": {
+    "a": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" },
+    "b": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" }
+  }
+}`)
+	second := postDelta(t, server.URL, deltaRequest{Token: first.Token})
+	require.False(t, second.Full)
+	require.NotEqual(t, first.Token, second.Token)
+	require.Equal(t, map[string]map[string]interface{}{
+		"a": {"value": true, "variant": "on", "reason": "STATIC"},
+	}, second.Flags)
+	require.Empty(t, second.Removed)
+
+	setGeneration(`{
+  "flags": {
+    "a": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" },
+    "c": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "on", null] }
+    }
+  }
+}`)
+	third := postDelta(t, server.URL, deltaRequest{
+		Token: second.Token, Context: map[string]interface{}{"email": "x@faas.com"},
+	})
+	require.Equal(t, map[string]map[string]interface{}{
+		"c": {"value": true, "variant": "on", "reason": "TARGETING_MATCH"},
+	}, third.Flags)
+	require.Equal(t, []string{"b"}, third.Removed)
+
+	// changes accumulate across generations
+	sinceFirst := postDelta(t, server.URL, deltaRequest{Token: first.Token})
+	require.Equal(t, third.Token, sinceFirst.Token)
+	require.Len(t, sinceFirst.Flags, 2)
+	require.Contains(t, sinceFirst.Flags, "a")
+	require.Contains(t, sinceFirst.Flags, "c")
+	require.Equal(t, []string{"b"}, sinceFirst.Removed)
+
+	// tokens of another instance resolve every flag
+	other := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	full := postDelta(t, server.URL, deltaRequest{Token: other.eventingConfiguration.history.token(1)})
+	require.True(t, full.Full)
+	require.Len(t, full.Flags, 2)
+}
+
+func TestDeltaHandler_InvalidRequest(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil)
+	server := httptest.NewServer(s.DeltaHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	for _, body := range []string{"notjson", `{"context": {"targetingKey": 1}}`} {
+		res, err := http.Post(server.URL, "application/json", bytes.NewBufferString(body))
+		require.Nil(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	}
+}
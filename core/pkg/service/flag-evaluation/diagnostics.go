@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	goruntime "runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DiagnosticsPath serves the runtime diagnostics of the server, e.g. to spot leaking connections or streams, it's
+// only served with the admin API
+const DiagnosticsPath = "/admin/diagnostics"
+
+// diagnostics describes the health of the server itself rather than of its flags
+type diagnostics struct {
+	// Connections is the number of open connections to the evaluation service, across protocols
+	Connections int64 `json:"connections"`
+	// EventStreams and SSEStreams are the numbers of active event stream and Server-Sent Events subscribers
+	EventStreams   int64  `json:"eventStreams"`
+	SSEStreams     int64  `json:"sseStreams"`
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	HeapObjects    uint64 `json:"heapObjects"`
+}
+
+// connectionCounter counts the open connections accepted by the listeners it wraps. Connections are counted from
+// the listeners rather than the connection states of the server, as h2c connections, e.g. gRPC without TLS, are
+// hijacked from the server once upgraded.
+type connectionCounter struct {
+	active atomic.Int64
+}
+
+// Active returns the number of open connections, 0 for a nil counter
+func (c *connectionCounter) Active() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.active.Load()
+}
+
+// listener wraps the listener, counting its accepted connections until they're closed
+func (c *connectionCounter) listener(lis net.Listener) net.Listener {
+	return &countedListener{Listener: lis, counter: c}
+}
+
+type countedListener struct {
+	net.Listener
+	counter *connectionCounter
+}
+
+func (l *countedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.counter.active.Add(1)
+	return &countedConn{Conn: conn, counter: l.counter}, nil
+}
+
+type countedConn struct {
+	net.Conn
+	counter *connectionCounter
+	once    sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { c.counter.active.Add(-1) })
+	return c.Conn.Close()
+}
+
+// withConnectionCounter reports the connections counted by the counter at DiagnosticsPath
+func withConnectionCounter(connections *connectionCounter) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.connections = connections
+	}
+}
+
+// streamCounts returns the number of active event stream and SSE subscribers, SSE subscribers being keyed by their
+// http request
+func (e *eventingConfiguration) streamCounts() (int64, int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var eventStreams, sseStreams int64
+	for key := range e.subs {
+		if _, ok := key.(*http.Request); ok {
+			sseStreams++
+		} else {
+			eventStreams++
+		}
+	}
+	return eventStreams, sseStreams
+}
+
+// DiagnosticsHandler serves the open connections, the active event stream and SSE subscribers, the goroutines and
+// the heap of the server
+func (s *FlagEvaluationService) DiagnosticsHandler() http.Handler {
+	return http.HandlerFunc(s.serveDiagnostics)
+}
+
+func (s *FlagEvaluationService) serveDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var mem goruntime.MemStats
+	goruntime.ReadMemStats(&mem)
+	res := diagnostics{
+		Connections:    s.connections.Active(),
+		Goroutines:     goruntime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+	}
+	res.EventStreams, res.SSEStreams = s.eventingConfiguration.streamCounts()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
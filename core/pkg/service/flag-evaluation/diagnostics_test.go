@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+)
+
+func getDiagnostics(t *testing.T, url string) diagnostics {
+	t.Helper()
+	res, err := http.Get(url)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body diagnostics
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	return body
+}
+
+func TestDiagnosticsHandler_Streams(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "bool": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off" }
+  }
+}`)
+	require.Nil(t, err)
+	eventing := &eventingConfiguration{
+		subs: make(map[interface{}]chan service.Notification),
+		mu:   &sync.RWMutex{},
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, withEventingConfiguration(eventing))
+	sse := httptest.NewServer(s.SSEHandler())
+	defer sse.Close()
+	server := httptest.NewServer(s.DiagnosticsHandler())
+	defer server.Close()
+
+	body := getDiagnostics(t, server.URL)
+	require.Zero(t, body.EventStreams)
+	require.Zero(t, body.SSEStreams)
+	require.Positive(t, body.Goroutines)
+	require.Positive(t, body.HeapAllocBytes)
+
+	// event streams are keyed by their connect request, any key other than an http request counts as one
+	key := struct{}{}
+	notifications, err := eventing.subscribe(&key)
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	openSSEStream(t, ctx, sse.URL+"?flags=bool", "")
+	openSSEStream(t, ctx, sse.URL+"?flags=bool", "")
+
+	body = getDiagnostics(t, server.URL)
+	require.Equal(t, int64(1), body.EventStreams)
+	require.Equal(t, int64(2), body.SSEStreams)
+
+	eventing.unsubscribe(&key, notifications)
+	cancel()
+	require.Eventually(t, func() bool {
+		body := getDiagnostics(t, server.URL)
+		return body.EventStreams == 0 && body.SSEStreams == 0
+	}, time.Second, 10*time.Millisecond, "closed streams should no longer be counted")
+}
+
+func TestDiagnosticsHandler_MethodNotAllowed(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	server := httptest.NewServer(s.DiagnosticsHandler())
+	defer server.Close()
+
+	res, err := http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestConnectionCounter(t *testing.T) {
+	counter := &connectionCounter{}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	lis = counter.listener(lis)
+	defer lis.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	first, err := net.Dial("tcp", lis.Addr().String())
+	require.Nil(t, err)
+	defer first.Close()
+	second, err := net.Dial("tcp", lis.Addr().String())
+	require.Nil(t, err)
+	defer second.Close()
+	conn := <-accepted
+	other := <-accepted
+	require.Equal(t, int64(2), counter.Active())
+
+	require.Nil(t, conn.Close())
+	_ = conn.Close()
+	require.Equal(t, int64(1), counter.Active(), "closing a connection twice should count it once")
+	require.Nil(t, other.Close())
+	require.Zero(t, counter.Active())
+
+	var unset *connectionCounter
+	require.Zero(t, unset.Active())
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// DisabledFlagsHeader is the request header selecting the disabled flags policy of a ResolveAll request, overriding
+// the policy of the service
+const DisabledFlagsHeader = "Flagd-Disabled-Flags"
+
+// DisabledFlags defines whether disabled flags appear in ResolveAll responses
+type DisabledFlags string
+
+const (
+	// DisabledFlagsInclude responds disabled flags with the DISABLED reason and without a value, so clients fall
+	// back to their default value, the default
+	DisabledFlagsInclude DisabledFlags = "include"
+	// DisabledFlagsOmit leaves disabled flags out of responses, without reporting them as failing to resolve
+	DisabledFlagsOmit DisabledFlags = "omit"
+)
+
+// ParseDisabledFlags returns the disabled flags policy of its name, an empty name defaults to include
+func ParseDisabledFlags(policy string) (DisabledFlags, error) {
+	switch DisabledFlags(policy) {
+	case "":
+		return DisabledFlagsInclude, nil
+	case DisabledFlagsInclude, DisabledFlagsOmit:
+		return DisabledFlags(policy), nil
+	default:
+		return "", fmt.Errorf("unknown disabled flags policy: '%s', expected '%s' or '%s'",
+			policy, DisabledFlagsInclude, DisabledFlagsOmit)
+	}
+}
+
+// WithDisabledFlags sets whether disabled flags appear in ResolveAll responses, unless the request selects another
+// policy through DisabledFlagsHeader
+func WithDisabledFlags(policy DisabledFlags) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.disabledFlags = policy
+	}
+}
+
+// disabledFlagsPolicy returns the policy selected by the request, the policy of the service if the request selects
+// none or an unknown one. An unset policy includes disabled flags.
+func (s *FlagEvaluationService) disabledFlagsPolicy(header http.Header) DisabledFlags {
+	switch policy := DisabledFlags(strings.ToLower(strings.TrimSpace(header.Get(DisabledFlagsHeader)))); policy {
+	case DisabledFlagsInclude, DisabledFlagsOmit:
+		return policy
+	}
+	if s.disabledFlags == DisabledFlagsOmit {
+		return DisabledFlagsOmit
+	}
+	return DisabledFlagsInclude
+}
+
+// applyDisabledFlags removes the disabled flags from the flags failing to resolve, adding them to the resolved flags
+// with the DISABLED reason as per the policy
+func applyDisabledFlags(
+	policy DisabledFlags, flags map[string]*schemaV1.AnyFlag, flagErrors []eval.FlagError,
+) []eval.FlagError {
+	var failed []eval.FlagError
+	for _, flagError := range flagErrors {
+		if flagError.ErrorCode != model.FlagDisabledErrorCode {
+			failed = append(failed, flagError)
+			continue
+		}
+		if policy == DisabledFlagsInclude {
+			flags[flagError.FlagKey] = &schemaV1.AnyFlag{Reason: model.DisabledReason}
+		}
+	}
+	return failed
+}
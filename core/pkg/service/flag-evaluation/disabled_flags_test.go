@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const disabledFlagsConfig = `{
+  "flags": {
+    "enabled": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" },
+    "disabled": { "state": "DISABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" }
+  }
+}`
+
+func TestResolveAll_DisabledFlags(t *testing.T) {
+	tests := map[string]struct {
+		options      []FlagEvaluationServiceOption
+		header       string
+		wantDisabled bool
+	}{
+		"included by default": {
+			wantDisabled: true,
+		},
+		"omitted by the service": {
+			options: []FlagEvaluationServiceOption{WithDisabledFlags(DisabledFlagsOmit)},
+		},
+		"omitted by the request": {
+			header: "omit",
+		},
+		"included by the request": {
+			options:      []FlagEvaluationServiceOption{WithDisabledFlags(DisabledFlagsOmit)},
+			header:       " Include ",
+			wantDisabled: true,
+		},
+		"unknown header policy": {
+			options: []FlagEvaluationServiceOption{WithDisabledFlags(DisabledFlagsOmit)},
+			header:  "hide",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, disabledFlagsConfig)
+			require.Nil(t, err)
+			s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, tt.options...)
+
+			req := connect.NewRequest(&schemaV1.ResolveAllRequest{})
+			if tt.header != "" {
+				req.Header().Set(DisabledFlagsHeader, tt.header)
+			}
+			res, err := s.ResolveAll(context.Background(), req)
+			require.Nil(t, err)
+			require.Equal(t, ResolveStatusSuccess, res.Header().Get(ResolveStatusHeader),
+				"disabled flags aren't failing to resolve")
+			require.Empty(t, res.Header().Get(ResolveErrorsHeader))
+			require.Contains(t, res.Msg.Flags, "enabled")
+
+			disabled, ok := res.Msg.Flags["disabled"]
+			require.Equal(t, tt.wantDisabled, ok)
+			if tt.wantDisabled {
+				require.Equal(t, model.DisabledReason, disabled.GetReason())
+				require.Nil(t, disabled.GetValue(), "disabled flags are responded without a value")
+				require.Empty(t, disabled.GetVariant())
+			}
+		})
+	}
+}
+
+func TestResolveAll_DisabledFlagsMinimal(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, disabledFlagsConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	req := connect.NewRequest(&schemaV1.ResolveAllRequest{})
+	req.Header().Set(VerbosityHeader, VerbosityMinimal)
+	res, err := s.ResolveAll(context.Background(), req)
+	require.Nil(t, err)
+	require.Equal(t, model.DisabledReason, res.Msg.Flags["disabled"].GetReason(),
+		"the reason tells disabled flags apart from minimal responses")
+}
+
+func TestParseDisabledFlags(t *testing.T) {
+	policy, err := ParseDisabledFlags("")
+	require.Nil(t, err)
+	require.Equal(t, DisabledFlagsInclude, policy)
+
+	policy, err = ParseDisabledFlags("omit")
+	require.Nil(t, err)
+	require.Equal(t, DisabledFlagsOmit, policy)
+
+	_, err = ParseDisabledFlags("hide")
+	require.EqualError(t, err, "unknown disabled flags policy: 'hide', expected 'include' or 'omit'")
+}
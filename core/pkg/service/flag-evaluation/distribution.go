@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// DistributionPath returns the variants returned by each flag over the distribution window, e.g.
+	// /admin/distribution?flagKey=my-flag, it's only served with the admin API and a distribution window set
+	DistributionPath = "/admin/distribution"
+
+	// distributionBuckets is the number of buckets the window is divided into, the window slides by a bucket at once
+	distributionBuckets = 60
+)
+
+// variantCounts counts the evaluations of each flag by returned variant
+type variantCounts map[string]map[string]int64
+
+func (c variantCounts) add(flagKey string, variant string, n int64) {
+	variants, ok := c[flagKey]
+	if !ok {
+		variants = map[string]int64{}
+		c[flagKey] = variants
+	}
+	variants[variant] += n
+}
+
+type distributionBucket struct {
+	// slot is the index of the bucket's time span since the unix epoch
+	slot   int64
+	counts variantCounts
+}
+
+// variantDistribution counts the variants returned by each flag over a rolling window. The window is divided into
+// buckets, counts expire a bucket at a time once they're older than the window.
+type variantDistribution struct {
+	window  time.Duration
+	width   time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets []distributionBucket
+}
+
+// newVariantDistribution returns a distribution over the window, nil if the window isn't positive
+func newVariantDistribution(window time.Duration) *variantDistribution {
+	if window <= 0 {
+		return nil
+	}
+	width := window / distributionBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &variantDistribution{
+		window:  window,
+		width:   width,
+		now:     time.Now,
+		buckets: make([]distributionBucket, distributionBuckets),
+	}
+}
+
+func (d *variantDistribution) slot() int64 {
+	return d.now().UnixNano() / int64(d.width)
+}
+
+// record counts an evaluation of the flag returning the variant
+func (d *variantDistribution) record(flagKey string, variant string) {
+	if d == nil || variant == "" {
+		return
+	}
+	slot := d.slot()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	bucket := &d.buckets[slot%distributionBuckets]
+	if bucket.slot != slot || bucket.counts == nil {
+		bucket.slot, bucket.counts = slot, variantCounts{}
+	}
+	bucket.counts.add(flagKey, variant, 1)
+}
+
+// Snapshot returns the variant counts of each flag within the window
+func (d *variantDistribution) Snapshot() map[string]map[string]int64 {
+	counts := variantCounts{}
+	if d == nil {
+		return counts
+	}
+	oldest := d.slot() - distributionBuckets
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, bucket := range d.buckets {
+		if bucket.slot <= oldest {
+			continue
+		}
+		for flagKey, variants := range bucket.counts {
+			for variant, n := range variants {
+				counts.add(flagKey, variant, n)
+			}
+		}
+	}
+	return counts
+}
+
+// withVariantDistribution counts the variants returned by the evaluations of the service
+func withVariantDistribution(distribution *variantDistribution) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.distribution = distribution
+	}
+}
+
+// recordVariants wraps the resolver, counting the variants of successful evaluations
+func recordVariants[T constraints](distribution *variantDistribution, resolver resolverFunc[T]) resolverFunc[T] {
+	if distribution == nil {
+		return resolver
+	}
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (T, string, string, map[string]interface{}, error) {
+		value, variant, reason, metadata, err := resolver(ctx, reqID, flagKey, evalCtx)
+		if err == nil {
+			distribution.record(flagKey, variant)
+		}
+		return value, variant, reason, metadata, err
+	}
+}
+
+type flagDistribution struct {
+	Total    int64            `json:"total"`
+	Variants map[string]int64 `json:"variants"`
+}
+
+type distributionResponse struct {
+	Window string                      `json:"window"`
+	Flags  map[string]flagDistribution `json:"flags"`
+}
+
+// DistributionHandler returns the number of evaluations of each flag by returned variant over the distribution
+// window, so experiments can confirm the split of their flags
+func (s *FlagEvaluationService) DistributionHandler() http.Handler {
+	return http.HandlerFunc(s.serveDistribution)
+}
+
+func (s *FlagEvaluationService) serveDistribution(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.distribution == nil {
+		http.Error(w, "the variant distribution isn't recorded", http.StatusNotFound)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	res := distributionResponse{Window: s.distribution.window.String(), Flags: map[string]flagDistribution{}}
+	for key, variants := range s.distribution.Snapshot() {
+		if flagKey != "" && key != flagKey {
+			continue
+		}
+		flag := flagDistribution{Variants: variants}
+		for _, n := range variants {
+			flag.Total += n
+		}
+		res.Flags[key] = flag
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const experimentFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "fractionalEvaluation": ["email", ["red", 50], ["blue", 50]] }
+    }
+  }
+}`
+
+func TestVariantDistribution(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, experimentFlagConfig)
+	require.Nil(t, err)
+	now := time.Unix(1680000000, 0)
+	distribution := newVariantDistribution(time.Minute)
+	distribution.now = func() time.Time { return now }
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{EnableAdminAPI: true},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), "variant-distribution"),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		distribution: distribution,
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	const evaluations = 1000
+	for i := 0; i < evaluations; i++ {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": fmt.Sprintf("user-%d@faas.com", i)})
+		require.Nil(t, err)
+		_, err = client.ResolveString(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveStringRequest{FlagKey: "headerColor", Context: evalCtx},
+		))
+		require.Nil(t, err)
+	}
+	// failed evaluations aren't counted
+	_, err = client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "headerColor"},
+	))
+	require.NotNil(t, err)
+
+	got := getDistribution(t, server.URL+DistributionPath+"?flagKey=headerColor")
+	require.Equal(t, "1m0s", got.Window)
+	require.Len(t, got.Flags, 1)
+	flag := got.Flags["headerColor"]
+	require.Equal(t, int64(evaluations), flag.Total)
+	require.Len(t, flag.Variants, 2)
+	for variant, n := range flag.Variants {
+		require.InDelta(t, evaluations/2, n, evaluations/10, "variant: %s", variant)
+	}
+
+	// counts expire once they're older than the window
+	now = now.Add(30 * time.Second)
+	_, err = client.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err)
+	require.Equal(t, int64(evaluations+1), getDistribution(t, server.URL+DistributionPath).Flags["headerColor"].Total)
+	now = now.Add(45 * time.Second)
+	require.Equal(t, int64(1), getDistribution(t, server.URL+DistributionPath).Flags["headerColor"].Total)
+	now = now.Add(time.Minute)
+	require.Empty(t, getDistribution(t, server.URL+DistributionPath).Flags)
+}
+
+func TestDistributionHandler_Disabled(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, experimentFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.DistributionHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func getDistribution(t *testing.T, url string) distributionResponse {
+	t.Helper()
+	res, err := http.Get(url)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var got distributionResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+	return got
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// EmptyFlagKeys defines how resolve requests whose flag key is empty, or only whitespace, are handled
+type EmptyFlagKeys string
+
+const (
+	// EmptyFlagKeysReject rejects the requests with an invalid argument error, the default
+	EmptyFlagKeysReject EmptyFlagKeys = "reject"
+	// EmptyFlagKeysNotFound evaluates the requests, which fail as the flag isn't found
+	EmptyFlagKeysNotFound EmptyFlagKeys = "not-found"
+
+	flagKeyField = "flag_key"
+)
+
+// ParseEmptyFlagKeys returns the empty flag key policy of its name, an empty name defaults to reject
+func ParseEmptyFlagKeys(policy string) (EmptyFlagKeys, error) {
+	switch EmptyFlagKeys(policy) {
+	case "":
+		return EmptyFlagKeysReject, nil
+	case EmptyFlagKeysReject, EmptyFlagKeysNotFound:
+		return EmptyFlagKeys(policy), nil
+	default:
+		return "", fmt.Errorf("unknown empty flag key policy: '%s', expected '%s' or '%s'",
+			policy, EmptyFlagKeysReject, EmptyFlagKeysNotFound)
+	}
+}
+
+// WithEmptyFlagKeys sets the policy of resolve requests whose flag key is empty
+func WithEmptyFlagKeys(policy EmptyFlagKeys) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.emptyFlagKeys = policy
+	}
+}
+
+// checkFlagKey returns an invalid argument error if the flag key of a resolve request is empty or only whitespace,
+// unless such keys are evaluated. The error details the flag_key field as a google.rpc.BadRequest violation.
+func (s *FlagEvaluationService) checkFlagKey(flagKey string) error {
+	if s.emptyFlagKeys == EmptyFlagKeysNotFound || strings.TrimSpace(flagKey) != "" {
+		return nil
+	}
+	connectErr := connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s, flag key must not be empty", ErrorPrefix))
+	if detail, err := connect.NewErrorDetail(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: flagKeyField, Description: "flag key must not be empty"},
+		},
+	}); err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
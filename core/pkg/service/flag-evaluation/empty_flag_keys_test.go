@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// resolveFlagKey resolves the flag key through each of the Resolve* RPCs, by resolve type
+func resolveFlagKey(s *FlagEvaluationService, flagKey string) map[string]error {
+	ctx := context.Background()
+	_, boolErr := s.ResolveBoolean(ctx, connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: flagKey}))
+	_, stringErr := s.ResolveString(ctx, connect.NewRequest(&schemaV1.ResolveStringRequest{FlagKey: flagKey}))
+	_, intErr := s.ResolveInt(ctx, connect.NewRequest(&schemaV1.ResolveIntRequest{FlagKey: flagKey}))
+	_, floatErr := s.ResolveFloat(ctx, connect.NewRequest(&schemaV1.ResolveFloatRequest{FlagKey: flagKey}))
+	_, objectErr := s.ResolveObject(ctx, connect.NewRequest(&schemaV1.ResolveObjectRequest{FlagKey: flagKey}))
+	return map[string]error{
+		ResolveTypeBoolean: boolErr,
+		ResolveTypeString:  stringErr,
+		ResolveTypeInt:     intErr,
+		ResolveTypeFloat:   floatErr,
+		ResolveTypeObject:  objectErr,
+	}
+}
+
+func TestEmptyFlagKeys(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAnyFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	for _, flagKey := range []string{"", " ", "\t\n"} {
+		for resolveType, err := range resolveFlagKey(s, flagKey) {
+			require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "%s resolution of '%s'",
+				resolveType, flagKey)
+			require.EqualError(t, err, "invalid_argument: FlagdError:, flag key must not be empty")
+
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			require.Len(t, connectErr.Details(), 1)
+			detail, err := connectErr.Details()[0].Value()
+			require.Nil(t, err)
+			badRequest, ok := detail.(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Equal(t, flagKeyField, badRequest.GetFieldViolations()[0].GetField())
+		}
+	}
+	for resolveType, err := range resolveFlagKey(s, "unknown") {
+		require.Equal(t, connect.CodeNotFound, connect.CodeOf(err), resolveType)
+	}
+}
+
+func TestEmptyFlagKeys_NotFound(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAnyFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		WithEmptyFlagKeys(EmptyFlagKeysNotFound))
+
+	for _, flagKey := range []string{"", " "} {
+		for resolveType, err := range resolveFlagKey(s, flagKey) {
+			require.Equal(t, connect.CodeNotFound, connect.CodeOf(err), "%s resolution of '%s'",
+				resolveType, flagKey)
+		}
+	}
+}
+
+func TestEmptyFlagKeys_ResolveAny(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAnyFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.ResolveAnyHandler())
+	defer server.Close()
+
+	res := postResolveAny(t, server.URL, resolveAnyRequest{FlagKey: "  "})
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	raw := httptest.NewServer(s.ResolveRawContextHandler())
+	defer raw.Close()
+	status, body := postRawContext(t, raw.URL, " ", `{}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, "flag key must not be empty")
+}
+
+func TestParseEmptyFlagKeys(t *testing.T) {
+	policy, err := ParseEmptyFlagKeys("")
+	require.Nil(t, err)
+	require.Equal(t, EmptyFlagKeysReject, policy)
+	policy, err = ParseEmptyFlagKeys("not-found")
+	require.Nil(t, err)
+	require.Equal(t, EmptyFlagKeysNotFound, policy)
+	_, err = ParseEmptyFlagKeys("ignore")
+	require.EqualError(t, err, "unknown empty flag key policy: 'ignore', expected 'reject' or 'not-found'")
+}
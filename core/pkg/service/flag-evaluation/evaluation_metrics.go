@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// evaluationMetricsInterceptor records the latency of unary evaluations, along with the reason of the evaluations of
+// a single flag, failed evaluations having the error reason. The span of the evaluation identifies its
+// trace when evaluations are traced, the trace context propagated by the caller in the traceparent header otherwise.
+func evaluationMetricsInterceptor(metrics *otel.MetricsRecorder) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			started := time.Now()
+			res, err := next(ctx, req)
+			traceCtx := ctx
+			if !trace.SpanContextFromContext(ctx).IsValid() {
+				traceCtx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(req.Header()))
+			}
+			metrics.EvaluationDuration(traceCtx, path.Base(req.Spec().Procedure), time.Since(started))
+			if flagReq, ok := req.Any().(interface{ GetFlagKey() string }); ok {
+				reason := model.ErrorReason
+				if err == nil {
+					if reasonRes, ok := res.Any().(interface{ GetReason() string }); ok {
+						reason = reasonRes.GetReason()
+					}
+				}
+				metrics.FlagEvaluation(ctx, flagReq.GetFlagKey(), reason)
+			}
+			return res, err
+		}
+	}
+}
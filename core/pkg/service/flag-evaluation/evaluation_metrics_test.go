@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestEvaluationMetricsInterceptor(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	reader := metric.NewManualReader()
+	metrics := otel.NewOTelRecorder(reader, "evaluation-metrics",
+		otel.WithMetricLabels(otel.MetricLabels{DroppedFlagKeys: []string{"myIntFlag"}}))
+	registry := prometheus.NewRegistry()
+	require.Nil(t, metrics.RegisterEvaluationDuration(registry))
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     metrics,
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	traced := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+	traced.Header().Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err = client.ResolveBoolean(context.Background(), traced)
+	require.Nil(t, err)
+	_, err = client.ResolveInt(context.Background(), connect.NewRequest(&schemaV1.ResolveIntRequest{FlagKey: "myIntFlag"}))
+	require.Nil(t, err)
+
+	families, err := registry.Gather()
+	require.Nil(t, err)
+	require.Len(t, families, 1)
+	exemplars := map[string][]string{}
+	for _, m := range families[0].GetMetric() {
+		method := m.GetLabel()[0].GetValue()
+		require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount(), method)
+		for _, bucket := range m.GetHistogram().GetBucket() {
+			if exemplar := bucket.GetExemplar(); exemplar != nil {
+				exemplars[method] = append(exemplars[method], exemplar.GetLabel()[0].GetValue())
+			}
+		}
+	}
+	require.Equal(t, map[string][]string{"ResolveBoolean": {"4bf92f3577b34da6a3ce929d0e0e4736"}}, exemplars)
+
+	_, err = client.ResolveBoolean(context.Background(),
+		connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "missingFlag"}))
+	require.NotNil(t, err)
+	data, err := reader.Collect(context.Background())
+	require.Nil(t, err)
+	evaluations := map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok || m.Name != "flag_evaluations" {
+			continue
+		}
+		for _, p := range sum.DataPoints {
+			flagKey, _ := p.Attributes.Value("flag_key")
+			reason, _ := p.Attributes.Value("reason")
+			evaluations[flagKey.AsString()+"/"+reason.AsString()] += p.Value
+		}
+	}
+	// the flag key of myIntFlag is dropped
+	require.Equal(t, map[string]int64{"myBoolFlag/DEFAULT": 1, "/STATIC": 1, "missingFlag/ERROR": 1}, evaluations)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/open-feature/flagd/core/pkg/service/flag-evaluation"
+
+// evaluationTracingInterceptor traces unary evaluations as children of the trace context propagated by the caller in
+// the traceparent header. The span of an evaluation carries the key of its flag from its start, so the sampler of the
+// tracer provider can sample evaluations per flag.
+func evaluationTracingInterceptor(provider trace.TracerProvider) connect.UnaryInterceptorFunc {
+	tracer := provider.Tracer(tracerName)
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(req.Header()))
+			attrs := []attribute.KeyValue{semconv.RPCMethodKey.String(req.Spec().Procedure)}
+			if keyed, ok := req.Any().(interface{ GetFlagKey() string }); ok {
+				attrs = append(attrs, semconv.FeatureFlagKey(keyed.GetFlagKey()))
+			}
+			ctx, span := tracer.Start(ctx, req.Spec().Procedure,
+				trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+			res, err := next(ctx, req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return res, err
+		}
+	}
+}
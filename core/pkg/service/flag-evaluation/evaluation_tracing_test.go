@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+)
+
+func TestEvaluationTracingInterceptor(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(otel.NewEvaluationSampler(func(flagKey string) (float64, bool) {
+			rate, ok := map[string]float64{"myBoolFlag": 1, "myIntFlag": 0}[flagKey]
+			return rate, ok
+		}, 0)),
+	)
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), "evaluation-tracing"),
+		TracerProvider:              provider,
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	_, err = client.ResolveBoolean(context.Background(),
+		connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+	require.Nil(t, err)
+	_, err = client.ResolveInt(context.Background(), connect.NewRequest(&schemaV1.ResolveIntRequest{FlagKey: "myIntFlag"}))
+	require.Nil(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1, "only the evaluations of the flag sampled at 1 should be traced")
+	require.Contains(t, spans[0].Attributes(), semconv.FeatureFlagKey("myBoolFlag"))
+	require.False(t, spans[0].Parent().IsValid())
+
+	traced := connect.NewRequest(&schemaV1.ResolveIntRequest{FlagKey: "myIntFlag"})
+	traced.Header().Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err = client.ResolveInt(context.Background(), traced)
+	require.Nil(t, err)
+	notSampled := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+	notSampled.Header().Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, err = client.ResolveBoolean(context.Background(), notSampled)
+	require.Nil(t, err)
+	spans = recorder.Ended()
+	require.Len(t, spans, 2, "evaluations should follow the sampling decision of their caller")
+	require.Contains(t, spans[1].Attributes(), semconv.FeatureFlagKey("myIntFlag"))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].Parent().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", spans[1].Parent().SpanID().String())
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync/atomic"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// servingEvaluators are the evaluator serving the requests and its read replica, nil unless RPCs are served from it
+type servingEvaluators struct {
+	live        eval.IEvaluator
+	readReplica eval.IEvaluator
+}
+
+// swappableEvaluator holds the evaluators serving the requests, which are swapped atomically. Requests load them
+// once, so requests in flight while the evaluator is swapped finish on the evaluator they started with.
+type swappableEvaluator struct {
+	evaluators atomic.Pointer[servingEvaluators]
+}
+
+func newSwappableEvaluator(evaluator eval.IEvaluator) *swappableEvaluator {
+	e := &swappableEvaluator{}
+	e.evaluators.Store(&servingEvaluators{live: evaluator})
+	return e
+}
+
+// load returns the evaluators serving the requests
+func (e *swappableEvaluator) load() *servingEvaluators {
+	if evaluators := e.evaluators.Load(); evaluators != nil {
+		return evaluators
+	}
+	return &servingEvaluators{}
+}
+
+// init serves the requests with the evaluators unless an evaluator was swapped in already
+func (e *swappableEvaluator) init(evaluator eval.IEvaluator, readReplica eval.IEvaluator) {
+	e.evaluators.CompareAndSwap(nil, &servingEvaluators{live: evaluator, readReplica: readReplica})
+}
+
+// swap serves the requests with the evaluators, returning the evaluator previously serving them
+func (e *swappableEvaluator) swap(evaluator eval.IEvaluator, readReplica eval.IEvaluator) eval.IEvaluator {
+	previous := e.evaluators.Swap(&servingEvaluators{live: evaluator, readReplica: readReplica})
+	if previous == nil {
+		return nil
+	}
+	return previous.live
+}
+
+// withSwappableEvaluator serves the requests with the evaluators of the holder, shared with the connect service
+func withSwappableEvaluator(evaluator *swappableEvaluator) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.evaluators = evaluator
+	}
+}
+
+// evaluator returns the evaluator serving the requests, handlers load it once so that the whole request is served
+// by the same evaluator
+func (s *FlagEvaluationService) evaluator() eval.IEvaluator {
+	return s.evaluators.load().live
+}
+
+// SwapEvaluator serves the requests with the evaluator, returning the evaluator previously serving them. Requests in
+// flight finish on the previous evaluator. RPCs served from a read replica are served from the live flags of the
+// evaluator.
+func (s *FlagEvaluationService) SwapEvaluator(next eval.IEvaluator) eval.IEvaluator {
+	return s.evaluators.swap(next, nil)
+}
+
+// SwapEvaluator serves the requests with the evaluator, returning the evaluator previously serving them. Requests in
+// flight finish on the previous evaluator. The read replica RPCs are served from a read replica of the evaluator.
+func (s *ConnectService) SwapEvaluator(next eval.IEvaluator) eval.IEvaluator {
+	return s.evaluator.swap(next, s.readReplicaOf(next))
+}
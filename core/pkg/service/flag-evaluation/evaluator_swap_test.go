@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// the color flag is a string flag of the first evaluator and a boolean flag of the second one, so a request whose
+// type is inferred by one evaluator and resolved by the other fails with a type mismatch
+const (
+	swapConfigString = `{"flags": {"color": {"state": "ENABLED", "variants": {"blue": "blue"}, ` +
+		`"defaultVariant": "blue"}}}`
+	swapConfigBoolean = `{"flags": {"color": {"state": "ENABLED", "variants": {"on": true}, ` +
+		`"defaultVariant": "on"}}}`
+)
+
+func TestSwapEvaluator_ConcurrentLoad(t *testing.T) {
+	stringEvaluator, err := eval.NewJSONEvaluatorFromConfig(nil, swapConfigString)
+	require.Nil(t, err)
+	booleanEvaluator, err := eval.NewJSONEvaluatorFromConfig(nil, swapConfigBoolean)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), stringEvaluator, nil)
+	handler := s.ResolveAnyHandler()
+
+	done := make(chan struct{})
+	swapped := make(chan int)
+	go func() {
+		evaluators := []eval.IEvaluator{booleanEvaluator, stringEvaluator}
+		previous := eval.IEvaluator(stringEvaluator)
+		swaps := 0
+		for ; ; swaps++ {
+			select {
+			case <-done:
+				swapped <- swaps
+				return
+			default:
+			}
+			next := evaluators[swaps%2]
+			if s.SwapEvaluator(next) != previous {
+				swapped <- -1
+				return
+			}
+			previous = next
+		}
+	}()
+
+	var wg gosync.WaitGroup
+	torn := make(chan string, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, ResolveAnyPath,
+					strings.NewReader(`{"flagKey": "color"}`)))
+				var res resolveAnyResponse
+				if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+					torn <- w.Body.String()
+					return
+				}
+				stringValue := res.Variant == "blue" && strings.Contains(string(res.Value), "StringValue")
+				boolValue := res.Variant == "on" && strings.Contains(string(res.Value), "BoolValue")
+				if !stringValue && !boolValue {
+					torn <- w.Body.String()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	require.Positive(t, <-swapped, "each swap should return the evaluator it replaced")
+	close(torn)
+	for res := range torn {
+		t.Errorf("torn resolution while swapping the evaluator: %s", res)
+	}
+}
+
+func TestSwapEvaluator_ReadReplica(t *testing.T) {
+	stringEvaluator, err := eval.NewJSONEvaluatorFromConfig(nil, swapConfigString)
+	require.Nil(t, err)
+	booleanEvaluator, err := eval.NewJSONEvaluatorFromConfig(nil, swapConfigBoolean)
+	require.Nil(t, err)
+	resolveAll := func(s *FlagEvaluationService) *schemaV1.AnyFlag {
+		t.Helper()
+		res, err := s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+		require.Nil(t, err)
+		return res.Msg.GetFlags()["color"]
+	}
+
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), stringEvaluator, nil,
+		withReadReplica(stringEvaluator.ReadReplica(time.Hour), []ReadReplicaRPC{ReadReplicaBulk}))
+	require.Equal(t, "blue", resolveAll(s).GetStringValue())
+	s.SwapEvaluator(booleanEvaluator)
+	require.True(t, resolveAll(s).GetBoolValue(),
+		"bulk evaluations should be served by the evaluator swapped in, rather than the replica of the previous one")
+
+	connectService := &ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{
+			ReadReplicaRPCs:      []ReadReplicaRPC{ReadReplicaBulk},
+			ReadReplicaStaleness: time.Hour,
+		},
+		Logger: logger.NewLogger(nil, false),
+	}
+	connectService.evaluator.init(stringEvaluator, connectService.readReplicaOf(stringEvaluator))
+	s = NewFlagEvaluationService(logger.NewLogger(nil, false), stringEvaluator, nil,
+		withSwappableEvaluator(&connectService.evaluator),
+		withReadReplica(nil, connectService.ConnectServiceConfiguration.ReadReplicaRPCs))
+	require.Same(t, stringEvaluator, connectService.SwapEvaluator(booleanEvaluator))
+	require.IsType(t, &eval.ReadReplica{}, connectService.evaluator.load().readReplica,
+		"the read replica rpcs should be served from the replica of the evaluator swapped in")
+	require.True(t, resolveAll(s).GetBoolValue())
+
+	connectService.evaluator.init(stringEvaluator, nil)
+	require.Same(t, booleanEvaluator, s.evaluator(), "serving shouldn't replace the evaluator swapped in")
+}
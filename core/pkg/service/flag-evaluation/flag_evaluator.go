@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,44 +17,201 @@ import (
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/rs/xid"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// Resolve types which can be disabled through WithDisabledResolveTypes
+const (
+	ResolveTypeAll     = "all"
+	ResolveTypeBoolean = "boolean"
+	ResolveTypeString  = "string"
+	ResolveTypeInt     = "int"
+	ResolveTypeFloat   = "float"
+	ResolveTypeObject  = "object"
+)
+
+var resolveTypes = []string{
+	ResolveTypeAll, ResolveTypeBoolean, ResolveTypeString, ResolveTypeInt, ResolveTypeFloat, ResolveTypeObject,
+}
+
 type FlagEvaluationService struct {
-	logger                *logger.Logger
-	eval                  eval.IEvaluator
+	logger *logger.Logger
+	// evaluators serve the requests, swapped atomically by SwapEvaluator
+	evaluators            *swappableEvaluator
 	metrics               *otel.MetricsRecorder
 	eventingConfiguration *eventingConfiguration
+	disabledResolveTypes  map[string]struct{}
+	logContextKeys        contextKeys
+	// distribution counts the returned variants of each flag, if set
+	distribution *variantDistribution
+	// webhook posts the evaluations to the evaluation webhook, if set
+	webhook *evaluationWebhook
+	// unknownReasons is the policy of reasons which aren't part of the flagd schema
+	unknownReasons UnknownReasons
+	// stale reports whether the configuration may be stale, if set
+	stale service.StaleProbe
+	// configLoaded returns the load time of the configuration added to the resolutions, if set
+	configLoaded service.ConfigLoadedProbe
+	// unsupportedContextValues is the policy of evaluation context values which aren't representable as json
+	unsupportedContextValues UnsupportedContextValues
+	// maxContextValueBytes bounds the size of each evaluation context value, larger values being handled by the
+	// oversized context value policy. Values are unbounded when 0.
+	maxContextValueBytes   int
+	oversizedContextValues OversizedContextValues
+	// strictContextConversion rejects contexts holding values requiring a lossy conversion
+	strictContextConversion bool
+	// contextHeaders maps the canonical names of request headers to the evaluation context keys they're merged into
+	contextHeaders map[string]string
+	// contextSnapshots are the evaluation contexts registered through the admin API
+	contextSnapshots *contextSnapshots
+	// connections counts the open connections of the server, if set
+	connections *connectionCounter
+	// verboseFlags are the flags whose evaluations are logged at the debug level
+	verboseFlags *verboseFlags
+	// contextSamples holds the latest evaluation contexts to compare configurations with, if set
+	contextSamples *contextSamples
+	// resync resyncs the sources once the caches are flushed, if set
+	resync service.ResyncTrigger
+	// auditLogger logs the changes made to the running configuration through the admin API
+	auditLogger *logger.Logger
+	// disabledFlags is whether disabled flags appear in ResolveAll responses, unless the request selects a policy
+	disabledFlags DisabledFlags
+	// targetingKeyFallback is the targeting key of the evaluation contexts without one
+	targetingKeyFallback TargetingKeyFallback
+	// readReplicaRPCs are served from the read replica of the evaluators, if set
+	readReplicaRPCs map[ReadReplicaRPC]struct{}
+	// emptyFlagKeys is the policy of resolve requests whose flag key is empty
+	emptyFlagKeys EmptyFlagKeys
+}
+
+type FlagEvaluationServiceOption func(s *FlagEvaluationService)
+
+// WithDisabledResolveTypes disables the Resolve* handlers of the provided resolve types, requests to a disabled
+// handler fail with an unimplemented error
+func WithDisabledResolveTypes(types []string) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		for _, t := range types {
+			s.disabledResolveTypes[t] = struct{}{}
+		}
+	}
+}
+
+// ValidateResolveTypes returns an error if any of the provided resolve types is unknown
+func ValidateResolveTypes(types []string) error {
+	valid := make(map[string]struct{}, len(resolveTypes))
+	for _, t := range resolveTypes {
+		valid[t] = struct{}{}
+	}
+	for _, t := range types {
+		if _, ok := valid[t]; !ok {
+			return fmt.Errorf("unknown resolve type '%s', valid types are: %s", t, strings.Join(resolveTypes, ", "))
+		}
+	}
+	return nil
 }
 
 type eventingConfiguration struct {
 	mu   *sync.RWMutex
 	subs map[interface{}]chan service.Notification
+	// history holds the latest notifications, allowing SSE clients to resume from their Last-Event-ID
+	history notificationHistory
+	// maxSubscribers bounds the concurrent subscribers, unbounded when 0
+	maxSubscribers int
+	// backoff suggests the retry delay of the subscriptions rejected once the subscribers are bounded
+	backoff retryBackoff
+}
+
+// withEventingConfiguration subscribes the event streams of the service to the provided notifications
+func withEventingConfiguration(eventing *eventingConfiguration) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.eventingConfiguration = eventing
+	}
+}
+
+// WithAuditLogger logs the changes made to the running configuration through the admin API, such as cache flushes,
+// with the logger rather than the logger of the service, unless nil
+func WithAuditLogger(log *logger.Logger) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		if log != nil {
+			s.auditLogger = log.WithFields(zap.String("component", "flagservice"))
+		}
+	}
 }
 
-func NewFlagEvaluationService(log *logger.Logger, eval eval.IEvaluator, metricsRecorder *otel.MetricsRecorder) *FlagEvaluationService {
-	return &FlagEvaluationService{
-		logger:  log,
-		eval:    eval,
-		metrics: metricsRecorder,
+func NewFlagEvaluationService(
+	log *logger.Logger,
+	eval eval.IEvaluator,
+	metricsRecorder *otel.MetricsRecorder,
+	opts ...FlagEvaluationServiceOption,
+) *FlagEvaluationService {
+	s := &FlagEvaluationService{
+		logger:      log,
+		auditLogger: log,
+		evaluators:  newSwappableEvaluator(eval),
+		metrics:     metricsRecorder,
 		eventingConfiguration: &eventingConfiguration{
 			subs: make(map[interface{}]chan service.Notification),
 			mu:   &sync.RWMutex{},
 		},
+		disabledResolveTypes:     map[string]struct{}{},
+		logContextKeys:           newContextKeys(),
+		unknownReasons:           UnknownReasonsNormalize,
+		unsupportedContextValues: UnsupportedContextValuesDrop,
+		oversizedContextValues:   OversizedContextValuesError,
+		contextSnapshots:         newContextSnapshots(),
+		verboseFlags:             newVerboseFlags(),
+		emptyFlagKeys:            EmptyFlagKeysReject,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// checkEnabled returns an unimplemented error if the resolve type has been disabled
+func (s *FlagEvaluationService) checkEnabled(resolveType string) error {
+	if _, disabled := s.disabledResolveTypes[resolveType]; disabled {
+		return connect.NewError(
+			connect.CodeUnimplemented, fmt.Errorf("%s, %s resolution is disabled", ErrorPrefix, resolveType),
+		)
+	}
+	return nil
 }
 
 func (s *FlagEvaluationService) ResolveAll(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveAllRequest],
 ) (*connect.Response[schemaV1.ResolveAllResponse], error) {
+	if err := s.checkEnabled(ResolveTypeAll); err != nil {
+		return nil, err
+	}
 	reqID := xid.New().String()
 	defer s.logger.ClearFields(reqID)
 	res := &schemaV1.ResolveAllResponse{
 		Flags: make(map[string]*schemaV1.AnyFlag),
 	}
-	values := s.eval.ResolveAllValues(reqID, req.Msg.GetContext())
+	evalCtx, err := s.requestContext(reqID, s.headerContext(req.Msg.GetContext(), req.Header()))
+	if err != nil {
+		s.logger.WarnWithID(reqID, fmt.Sprintf("returning error response, reason: %v", err))
+		return nil, errFormat(err)
+	}
+	values, flagErrors := resolveAllWithErrors(ctx, s.evaluatorOf(ReadReplicaBulk), reqID, evalCtx)
+	if err := ctx.Err(); err != nil {
+		s.logger.WarnWithID(reqID, fmt.Sprintf("returning error response, reason: %v", err))
+		return nil, errFormat(err)
+	}
+	minimal := minimalResponse(req.Header())
+	var targetingKey string
+	if s.webhook != nil {
+		targetingKey = s.logContextKeys.targetingKey.hashContext(evalCtx)
+	}
 	for _, value := range values {
+		s.distribution.record(value.FlagKey, value.Variant)
+		value.Reason = s.normalizeReason(value.FlagKey, value.Reason)
+		if value.Reason != model.ErrorReason {
+			s.webhook.record(value.FlagKey, value.Variant, value.Reason, targetingKey)
+		}
 		switch v := value.Value.(type) {
 		case bool:
 			res.Flags[value.FlagKey] = &schemaV1.AnyFlag{
@@ -82,6 +241,7 @@ func (s *FlagEvaluationService) ResolveAll(
 			val, err := structpb.NewStruct(v)
 			if err != nil {
 				s.logger.ErrorWithID(reqID, fmt.Sprintf("struct response construction: %v", err))
+				flagErrors = append(flagErrors, eval.FlagError{FlagKey: value.FlagKey, ErrorCode: model.GeneralErrorCode})
 				continue
 			}
 			res.Flags[value.FlagKey] = &schemaV1.AnyFlag{
@@ -92,9 +252,18 @@ func (s *FlagEvaluationService) ResolveAll(
 				},
 			}
 		}
+		if flag, ok := res.Flags[value.FlagKey]; ok && minimal {
+			flag.Reason, flag.Variant = "", ""
+		}
 	}
 
-	return connect.NewResponse(res), nil
+	flagErrors = applyDisabledFlags(s.disabledFlagsPolicy(req.Header()), res.Flags, flagErrors)
+
+	resp := connect.NewResponse(res)
+	if err := setResolveErrorsHeaders(resp.Header(), flagErrors); err != nil {
+		s.logger.ErrorWithID(reqID, err.Error())
+	}
+	return resp, nil
 }
 
 func (s *FlagEvaluationService) EventStream(
@@ -102,21 +271,18 @@ func (s *FlagEvaluationService) EventStream(
 	req *connect.Request[schemaV1.EventStreamRequest],
 	stream *connect.ServerStream[schemaV1.EventStreamResponse],
 ) error {
-	requestNotificationChan := make(chan service.Notification, 1)
-	s.eventingConfiguration.mu.Lock()
-	s.eventingConfiguration.subs[req] = requestNotificationChan
-	s.eventingConfiguration.mu.Unlock()
-	defer func() {
-		s.eventingConfiguration.mu.Lock()
-		delete(s.eventingConfiguration.subs, req)
-		s.eventingConfiguration.mu.Unlock()
-	}()
+	requestNotificationChan, err := s.eventingConfiguration.subscribe(req)
+	if err != nil {
+		return withRetryInfo(connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("%s, %s", ErrorPrefix, err.Error())),
+			s.eventingConfiguration.backoff.next())
+	}
+	defer s.eventingConfiguration.unsubscribe(req, requestNotificationChan)
 	requestNotificationChan <- service.Notification{
 		Type: service.ProviderReady,
 	}
 	for {
 		select {
-		case <-time.After(20 * time.Second):
+		case <-time.After(keepAliveInterval):
 			err := stream.Send(&schemaV1.EventStreamResponse{
 				Type: string(service.KeepAlive),
 			})
@@ -142,43 +308,72 @@ func (s *FlagEvaluationService) EventStream(
 }
 
 func resolve[T constraints](
-	logger *logger.Logger,
-	resolver func(reqID, flagKey string, ctx *structpb.Struct) (T, string, string, error),
+	ctx context.Context,
+	s *FlagEvaluationService,
+	resolver resolverFunc[T],
 	flagKey string,
-	ctx *structpb.Struct,
+	evalCtx *structpb.Struct,
+	minimal bool,
 	resp response[T],
 ) error {
 	reqID := xid.New().String()
-	defer logger.ClearFields(reqID)
+	defer s.logger.ClearFields(reqID)
+	verbose := s.setVerbose(reqID, flagKey)
 
-	logger.WriteFields(
-		reqID,
-		zap.String("flag-key", flagKey),
-		zap.Strings("context-keys", formatContextKeys(ctx)),
-	)
+	s.logger.WriteFields(reqID, zap.String("flag-key", flagKey))
+	evalCtx, err := s.requestContext(reqID, evalCtx)
+	if err != nil {
+		s.logger.WarnWithID(reqID, fmt.Sprintf("returning error response, reason: %v", err))
+		return errFormat(err)
+	}
+	if verbose {
+		s.logVerboseContext(reqID, flagKey, evalCtx)
+	}
 
-	result, variant, reason, evalErr := resolver(reqID, flagKey, ctx)
+	result, variant, reason, metadata, evalErr := resolver(ctx, reqID, flagKey, evalCtx)
 	if evalErr != nil {
-		logger.WarnWithID(reqID, fmt.Sprintf("returning error response, reason: %v", evalErr))
+		s.logger.WarnWithID(reqID, fmt.Sprintf("returning error response, reason: %v", evalErr))
 		reason = model.ErrorReason
 		evalErr = errFormat(evalErr)
+	} else if verbose {
+		s.logger.DebugWithID(reqID, fmt.Sprintf("resolved verbose flag: %s, variant: %s, reason: %s",
+			flagKey, variant, reason), zap.Any("metadata", metadata))
+	}
+	if minimal {
+		variant, reason, metadata = "", "", nil
 	}
 
-	if err := resp.SetResult(result, variant, reason); err != nil && evalErr == nil {
-		logger.ErrorWithID(reqID, err.Error())
+	if err := resp.SetResult(result, variant, reason, metadata); err != nil && evalErr == nil {
+		s.logger.ErrorWithID(reqID, err.Error())
 		return err
 	}
 
 	return evalErr
 }
 
+// serviceResolver wraps the resolver of the evaluator with the reason normalization, variant counting, evaluation
+// webhook, stale indicator and configuration age of the service
+func serviceResolver[T constraints](s *FlagEvaluationService, resolver resolverFunc[T]) resolverFunc[T] {
+	return markConfigAge(s.configLoaded, markStale(s.stale, recordEvaluations(
+		s.webhook, s.logContextKeys.targetingKey, recordVariants(s.distribution, normalizeReasons(s, resolver)),
+	)))
+}
+
 func (s *FlagEvaluationService) ResolveBoolean(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveBooleanRequest],
 ) (*connect.Response[schemaV1.ResolveBooleanResponse], error) {
+	if err := s.checkEnabled(ResolveTypeBoolean); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlagKey(req.Msg.GetFlagKey()); err != nil {
+		return nil, err
+	}
 	res := connect.NewResponse(&schemaV1.ResolveBooleanResponse{})
 	err := resolve[bool](
-		s.logger, s.eval.ResolveBooleanValue, req.Msg.GetFlagKey(), req.Msg.GetContext(), &booleanResponse{res},
+		ctx, s, serviceResolver(s, s.evaluator().ResolveBooleanValue),
+		req.Msg.GetFlagKey(), s.headerContext(req.Msg.GetContext(), req.Header()), minimalResponse(req.Header()),
+		&booleanResponse{res},
 	)
 
 	return res, err
@@ -188,9 +383,17 @@ func (s *FlagEvaluationService) ResolveString(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveStringRequest],
 ) (*connect.Response[schemaV1.ResolveStringResponse], error) {
+	if err := s.checkEnabled(ResolveTypeString); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlagKey(req.Msg.GetFlagKey()); err != nil {
+		return nil, err
+	}
 	res := connect.NewResponse(&schemaV1.ResolveStringResponse{})
 	err := resolve[string](
-		s.logger, s.eval.ResolveStringValue, req.Msg.GetFlagKey(), req.Msg.GetContext(), &stringResponse{res},
+		ctx, s, serviceResolver(s, s.evaluator().ResolveStringValue),
+		req.Msg.GetFlagKey(), s.headerContext(req.Msg.GetContext(), req.Header()), minimalResponse(req.Header()),
+		&stringResponse{res},
 	)
 
 	return res, err
@@ -200,9 +403,17 @@ func (s *FlagEvaluationService) ResolveInt(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveIntRequest],
 ) (*connect.Response[schemaV1.ResolveIntResponse], error) {
+	if err := s.checkEnabled(ResolveTypeInt); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlagKey(req.Msg.GetFlagKey()); err != nil {
+		return nil, err
+	}
 	res := connect.NewResponse(&schemaV1.ResolveIntResponse{})
 	err := resolve[int64](
-		s.logger, s.eval.ResolveIntValue, req.Msg.GetFlagKey(), req.Msg.GetContext(), &intResponse{res},
+		ctx, s, serviceResolver(s, s.evaluator().ResolveIntValue),
+		req.Msg.GetFlagKey(), s.headerContext(req.Msg.GetContext(), req.Header()), minimalResponse(req.Header()),
+		&intResponse{res},
 	)
 
 	return res, err
@@ -212,9 +423,17 @@ func (s *FlagEvaluationService) ResolveFloat(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveFloatRequest],
 ) (*connect.Response[schemaV1.ResolveFloatResponse], error) {
+	if err := s.checkEnabled(ResolveTypeFloat); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlagKey(req.Msg.GetFlagKey()); err != nil {
+		return nil, err
+	}
 	res := connect.NewResponse(&schemaV1.ResolveFloatResponse{})
 	err := resolve[float64](
-		s.logger, s.eval.ResolveFloatValue, req.Msg.GetFlagKey(), req.Msg.GetContext(), &floatResponse{res},
+		ctx, s, serviceResolver(s, s.evaluator().ResolveFloatValue),
+		req.Msg.GetFlagKey(), s.headerContext(req.Msg.GetContext(), req.Header()), minimalResponse(req.Header()),
+		&floatResponse{res},
 	)
 
 	return res, err
@@ -224,14 +443,30 @@ func (s *FlagEvaluationService) ResolveObject(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveObjectRequest],
 ) (*connect.Response[schemaV1.ResolveObjectResponse], error) {
+	if err := s.checkEnabled(ResolveTypeObject); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlagKey(req.Msg.GetFlagKey()); err != nil {
+		return nil, err
+	}
 	res := connect.NewResponse(&schemaV1.ResolveObjectResponse{})
 	err := resolve[map[string]any](
-		s.logger, s.eval.ResolveObjectValue, req.Msg.GetFlagKey(), req.Msg.GetContext(), &objectResponse{res},
+		ctx, s, serviceResolver(s, s.evaluator().ResolveObjectValue),
+		req.Msg.GetFlagKey(), s.headerContext(req.Msg.GetContext(), req.Header()), minimalResponse(req.Header()),
+		&objectResponse{res},
 	)
 
 	return res, err
 }
 
+// evaluationContext treats a missing evaluation context as an empty one, so evaluators never receive a nil context
+func evaluationContext(ctx *structpb.Struct) *structpb.Struct {
+	if ctx == nil {
+		return &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	}
+	return ctx
+}
+
 func formatContextKeys(context *structpb.Struct) []string {
 	res := []string{}
 	for k := range context.AsMap() {
@@ -241,16 +476,85 @@ func formatContextKeys(context *structpb.Struct) []string {
 }
 
 func errFormat(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the client went away, nobody reads the response
+		return connect.NewError(connect.CodeCanceled, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+	}
 	switch err.Error() {
 	case model.FlagNotFoundErrorCode:
 		return connect.NewError(connect.CodeNotFound, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
 	case model.TypeMismatchErrorCode:
+		var tErr *eval.TypeMismatchError
+		if errors.As(err, &tErr) {
+			return connect.NewError(connect.CodeInvalidArgument,
+				fmt.Errorf("%s, %s: %s", ErrorPrefix, err.Error(), tErr.Description()))
+		}
 		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
 	case model.DisabledReason:
 		return connect.NewError(connect.CodeUnavailable, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+	case model.ProviderNotReadyErrorCode:
+		// the initial sync usually completes shortly, retrying won't help disabled flags
+		return withRetryInfo(connect.NewError(connect.CodeUnavailable, fmt.Errorf("%s, %s", ErrorPrefix, err.Error())),
+			minRetryDelay)
 	case model.ParseErrorCode:
 		return connect.NewError(connect.CodeDataLoss, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+	case model.InvalidContextErrorCode:
+		connectErr := connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+		var vErr *contextValidationError
+		if errors.As(err, &vErr) {
+			if detail, dErr := connect.NewErrorDetail(&errdetails.BadRequest{FieldViolations: vErr.violations}); dErr == nil {
+				connectErr.AddDetail(detail)
+			}
+		}
+		return connectErr
+	case model.MissingContextErrorCode:
+		connectErr := connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("%s, %s", ErrorPrefix, err.Error()))
+		var mErr *eval.MissingContextError
+		if errors.As(err, &mErr) {
+			violations := make([]*errdetails.PreconditionFailure_Violation, 0, len(mErr.Keys))
+			for _, key := range mErr.Keys {
+				violations = append(violations, &errdetails.PreconditionFailure_Violation{
+					Type:        "CONTEXT",
+					Subject:     key,
+					Description: fmt.Sprintf("%s is required by the flag", key),
+				})
+			}
+			if detail, dErr := connect.NewErrorDetail(&errdetails.PreconditionFailure{Violations: violations}); dErr == nil {
+				connectErr.AddDetail(detail)
+			}
+		}
+		return connectErr
 	}
 
 	return err
 }
+
+// contextValidationError lists the evaluation context fields which failed validation, the violations are attached
+// to the error response as google.rpc.BadRequest details
+type contextValidationError struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (e *contextValidationError) Error() string {
+	return model.InvalidContextErrorCode
+}
+
+// validateContext checks the evaluation context fields with a reserved meaning
+func validateContext(context *structpb.Struct) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if v, ok := context.GetFields()[targetingKeyField]; ok {
+		if _, isString := v.GetKind().(*structpb.Value_StringValue); !isString {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       targetingKeyField,
+				Description: fmt.Sprintf("%s must be a string", targetingKeyField),
+			})
+		}
+	}
+	if len(violations) > 0 {
+		return &contextValidationError{violations: violations}
+	}
+	return nil
+}
@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
 	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
 	"github.com/bufbuild/connect-go"
 	"github.com/golang/mock/gomock"
@@ -12,7 +19,13 @@ import (
 	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -80,7 +93,7 @@ func TestConnectService_ResolveAll(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any()).Return(
+			eval.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 				tt.evalRes,
 			).AnyTimes()
 			s := NewFlagEvaluationService(
@@ -173,10 +186,11 @@ func TestFlag_Evaluation_ResolveBoolean(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveBooleanValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.wantErr,
 			).AnyTimes()
 			s := NewFlagEvaluationService(
@@ -220,10 +234,11 @@ func BenchmarkFlag_Evaluation_ResolveBoolean(b *testing.B) {
 	}
 	for name, tt := range tests {
 		eval := mock.NewMockIEvaluator(ctrl)
-		eval.EXPECT().ResolveBooleanValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+		eval.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 			tt.evalFields.result,
 			tt.evalFields.variant,
 			tt.evalFields.reason,
+			nil,
 			tt.wantErr,
 		).AnyTimes()
 		s := NewFlagEvaluationService(
@@ -307,10 +322,11 @@ func TestFlag_Evaluation_ResolveString(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveStringValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveStringValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.wantErr,
 			)
 			s := NewFlagEvaluationService(
@@ -354,10 +370,11 @@ func BenchmarkFlag_Evaluation_ResolveString(b *testing.B) {
 	}
 	for name, tt := range tests {
 		eval := mock.NewMockIEvaluator(ctrl)
-		eval.EXPECT().ResolveStringValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+		eval.EXPECT().ResolveStringValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 			tt.evalFields.result,
 			tt.evalFields.variant,
 			tt.evalFields.reason,
+			nil,
 			tt.wantErr,
 		).AnyTimes()
 
@@ -442,10 +459,11 @@ func TestFlag_Evaluation_ResolveFloat(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveFloatValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveFloatValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.wantErr,
 			).AnyTimes()
 			s := NewFlagEvaluationService(
@@ -489,10 +507,11 @@ func BenchmarkFlag_Evaluation_ResolveFloat(b *testing.B) {
 	}
 	for name, tt := range tests {
 		eval := mock.NewMockIEvaluator(ctrl)
-		eval.EXPECT().ResolveFloatValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+		eval.EXPECT().ResolveFloatValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 			tt.evalFields.result,
 			tt.evalFields.variant,
 			tt.evalFields.reason,
+			nil,
 			tt.wantErr,
 		).AnyTimes()
 
@@ -577,10 +596,11 @@ func TestFlag_Evaluation_ResolveInt(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveIntValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveIntValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.wantErr,
 			).AnyTimes()
 			s := NewFlagEvaluationService(
@@ -624,10 +644,11 @@ func BenchmarkFlag_Evaluation_ResolveInt(b *testing.B) {
 	}
 	for name, tt := range tests {
 		eval := mock.NewMockIEvaluator(ctrl)
-		eval.EXPECT().ResolveIntValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+		eval.EXPECT().ResolveIntValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 			tt.evalFields.result,
 			tt.evalFields.variant,
 			tt.evalFields.reason,
+			nil,
 			tt.wantErr,
 		).AnyTimes()
 
@@ -715,10 +736,11 @@ func TestFlag_Evaluation_ResolveObject(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveObjectValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+			eval.EXPECT().ResolveObjectValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 				tt.evalFields.result,
 				tt.evalFields.variant,
 				tt.evalFields.reason,
+				nil,
 				tt.wantErr,
 			).AnyTimes()
 			s := NewFlagEvaluationService(
@@ -770,10 +792,11 @@ func BenchmarkFlag_Evaluation_ResolveObject(b *testing.B) {
 	}
 	for name, tt := range tests {
 		eval := mock.NewMockIEvaluator(ctrl)
-		eval.EXPECT().ResolveObjectValue(gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
+		eval.EXPECT().ResolveObjectValue(gomock.Any(), gomock.Any(), tt.functionArgs.req.FlagKey, gomock.Any()).Return(
 			tt.evalFields.result,
 			tt.evalFields.variant,
 			tt.evalFields.reason,
+			nil,
 			tt.wantErr,
 		).AnyTimes()
 
@@ -801,3 +824,430 @@ func BenchmarkFlag_Evaluation_ResolveObject(b *testing.B) {
 		})
 	}
 }
+
+func TestFlag_Evaluation_ResolveMetadataHeader(t *testing.T) {
+	tests := map[string]struct {
+		metadata   map[string]interface{}
+		wantHeader string
+	}{
+		"metadata is encoded": {
+			metadata:   map[string]interface{}{"ruleId": "premium-users", "bucket": 42},
+			wantHeader: `{"bucket":42,"ruleId":"premium-users"}`,
+		},
+		"no metadata": {
+			metadata:   nil,
+			wantHeader: "",
+		},
+	}
+	ctrl := gomock.NewController(t)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			eval := mock.NewMockIEvaluator(ctrl)
+			eval.EXPECT().ResolveStringValue(gomock.Any(), gomock.Any(), "flag", gomock.Any()).Return(
+				"value",
+				"variant",
+				model.TargetingMatchReason,
+				tt.metadata,
+				nil,
+			).AnyTimes()
+			s := NewFlagEvaluationService(
+				logger.NewLogger(nil, false),
+				eval,
+				nil,
+			)
+			got, err := s.ResolveString(
+				context.Background(),
+				connect.NewRequest(&schemaV1.ResolveStringRequest{FlagKey: "flag"}),
+			)
+			require.Nil(t, err)
+			require.Equal(t, tt.wantHeader, got.Header().Get(MetadataHeader))
+		})
+	}
+}
+
+func TestFlag_Evaluation_InvalidContextDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eval := mock.NewMockIEvaluator(ctrl)
+	s := NewFlagEvaluationService(
+		logger.NewLogger(nil, false),
+		eval,
+		nil,
+	)
+	evalContext, err := structpb.NewStruct(map[string]interface{}{"targetingKey": 12})
+	require.Nil(t, err)
+
+	tests := map[string]func() error{
+		"resolve": func() error {
+			_, err := s.ResolveBoolean(
+				context.Background(),
+				connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "flag", Context: evalContext}),
+			)
+			return err
+		},
+		"resolve all": func() error {
+			_, err := s.ResolveAll(
+				context.Background(),
+				connect.NewRequest(&schemaV1.ResolveAllRequest{Context: evalContext}),
+			)
+			return err
+		},
+	}
+	for name, call := range tests {
+		t.Run(name, func(t *testing.T) {
+			var connectErr *connect.Error
+			require.ErrorAs(t, call(), &connectErr)
+			require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+			require.Contains(t, connectErr.Message(), model.InvalidContextErrorCode)
+			require.Len(t, connectErr.Details(), 1)
+
+			detail, err := connectErr.Details()[0].Value()
+			require.Nil(t, err)
+			badRequest, ok := detail.(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Len(t, badRequest.GetFieldViolations(), 1)
+			require.Equal(t, "targetingKey", badRequest.GetFieldViolations()[0].GetField())
+		})
+	}
+}
+
+func TestFlag_Evaluation_DisabledResolveTypes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eval := mock.NewMockIEvaluator(ctrl)
+	eval.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "flag", gomock.Any()).Return(
+		true, "on", model.StaticReason, nil, nil,
+	)
+	s := NewFlagEvaluationService(
+		logger.NewLogger(nil, false),
+		eval,
+		nil,
+		WithDisabledResolveTypes([]string{ResolveTypeObject, ResolveTypeFloat, ResolveTypeAll}),
+	)
+
+	got, err := s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "flag"}))
+	require.Nil(t, err)
+	require.True(t, got.Msg.Value)
+
+	_, err = s.ResolveObject(context.Background(), connect.NewRequest(&schemaV1.ResolveObjectRequest{FlagKey: "flag"}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err))
+	_, err = s.ResolveFloat(context.Background(), connect.NewRequest(&schemaV1.ResolveFloatRequest{FlagKey: "flag"}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err))
+	_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err))
+}
+
+func TestValidateResolveTypes(t *testing.T) {
+	require.Nil(t, ValidateResolveTypes([]string{ResolveTypeObject, ResolveTypeInt}))
+	require.NotNil(t, ValidateResolveTypes([]string{"double"}))
+}
+
+func TestFlag_Evaluation_NilContext(t *testing.T) {
+	const config = `{
+  "flags": {
+    "bool": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "in": ["@faas.com", { "var": "email" }] }, "on", null] }
+    },
+    "string": {
+      "state": "ENABLED", "variants": { "a": "a", "b": "b" }, "defaultVariant": "a",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "b", null] }
+    },
+    "int": {
+      "state": "ENABLED", "variants": { "one": 1, "two": 2 }, "defaultVariant": "one",
+      "targeting": { "fractionalEvaluation": ["email", ["one", 50], ["two", 50]] }
+    },
+    "float": {
+      "state": "ENABLED", "variants": { "one": 1.5, "two": 2.5 }, "defaultVariant": "one",
+      "targeting": { "if": [{ "regex": [{ "var": "userAgent" }, "Firefox"] }, "two", null] }
+    },
+    "object": {
+      "state": "ENABLED", "variants": { "a": { "a": true }, "b": { "b": true } }, "defaultVariant": "a",
+      "targeting": { "if": [{ "in": [{ "var": "email" }, ["x@faas.com"]] }, "b", null] }
+    }
+  }
+}`
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, config)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	ctx := context.Background()
+
+	boolRes, err := s.ResolveBoolean(ctx, connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "bool"}))
+	require.Nil(t, err)
+	require.Equal(t, "off", boolRes.Msg.Variant)
+	require.Equal(t, model.DefaultReason, boolRes.Msg.Reason)
+
+	stringRes, err := s.ResolveString(ctx, connect.NewRequest(&schemaV1.ResolveStringRequest{FlagKey: "string"}))
+	require.Nil(t, err)
+	require.Equal(t, "a", stringRes.Msg.Value)
+	require.Equal(t, model.DefaultReason, stringRes.Msg.Reason)
+
+	intRes, err := s.ResolveInt(ctx, connect.NewRequest(&schemaV1.ResolveIntRequest{FlagKey: "int"}))
+	require.Nil(t, err)
+	require.Equal(t, int64(1), intRes.Msg.Value)
+	require.Equal(t, model.DefaultReason, intRes.Msg.Reason)
+
+	floatRes, err := s.ResolveFloat(ctx, connect.NewRequest(&schemaV1.ResolveFloatRequest{FlagKey: "float"}))
+	require.Nil(t, err)
+	require.Equal(t, 1.5, floatRes.Msg.Value)
+	require.Equal(t, model.DefaultReason, floatRes.Msg.Reason)
+
+	objectRes, err := s.ResolveObject(ctx, connect.NewRequest(&schemaV1.ResolveObjectRequest{FlagKey: "object"}))
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"a": true}, objectRes.Msg.Value.AsMap())
+	require.Equal(t, model.DefaultReason, objectRes.Msg.Reason)
+
+	allRes, err := s.ResolveAll(ctx, connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err)
+	require.Len(t, allRes.Msg.Flags, 5)
+	for key, flag := range allRes.Msg.Flags {
+		require.Equal(t, model.DefaultReason, flag.Reason, key)
+	}
+}
+
+func TestFlag_Evaluation_NilContextPassedAsEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	evaluator := mock.NewMockIEvaluator(ctrl)
+	evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "flag", gomock.Not(gomock.Nil())).Return(
+		true, "on", model.StaticReason, nil, nil,
+	)
+	evaluator.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).Return([]eval.AnyValue{})
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "flag"}))
+	require.Nil(t, err)
+	_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err)
+}
+
+func TestFlag_Evaluation_LogContextKeys(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{"flags": {}}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(
+		logger.NewLogger(zap.New(core), true), evaluator, nil, WithLogContextKeys([]string{"plan"}),
+	)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com", "plan": "pro"})
+	require.Nil(t, err)
+
+	_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{
+		FlagKey: "missing",
+		Context: evalCtx,
+	}))
+	require.NotNil(t, err)
+
+	entries := logs.FilterMessageSnippet("returning error response").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, map[string]interface{}{"email": redactedValue, "plan": "pro"}, fields["context"])
+	require.ElementsMatch(t, []interface{}{"email", "plan"}, fields["context-keys"])
+	for _, entry := range logs.All() {
+		for _, value := range entry.ContextMap() {
+			require.NotContains(t, fmt.Sprint(value), "user@faas.com")
+		}
+	}
+}
+
+func TestFlag_Evaluation_LogContextKeysUnset(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{"flags": {}}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(zap.New(core), true), evaluator, nil)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"targetingKey": 1, "email": "user@faas.com"})
+	require.Nil(t, err)
+
+	_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{Context: evalCtx}))
+	require.NotNil(t, err)
+
+	entries := logs.FilterMessageSnippet("returning error response").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.NotContains(t, fields, "context")
+	require.ElementsMatch(t, []interface{}{"targetingKey", "email"}, fields["context-keys"])
+}
+
+func TestFlag_Evaluation_TypedMetadata(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "metadataFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "metadata": { "owner": "checkout", "version": 3, "experimental": true }
+    }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{
+		FlagKey: "metadataFlag",
+	}))
+	require.Nil(t, err)
+
+	var metadata map[string]interface{}
+	require.Nil(t, json.Unmarshal([]byte(res.Header().Get(MetadataHeader)), &metadata))
+	require.Equal(t, map[string]interface{}{"owner": "checkout", "version": float64(3), "experimental": true}, metadata)
+}
+
+func TestFlag_Evaluation_EventStreamSubscriberLimit(t *testing.T) {
+	eventing := &eventingConfiguration{
+		subs:           make(map[interface{}]chan service.Notification),
+		mu:             &sync.RWMutex{},
+		maxSubscribers: 1,
+	}
+	s := NewFlagEvaluationService(
+		logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil, withEventingConfiguration(eventing),
+	)
+	path, handler := schemaConnectV1.NewServiceHandler(s)
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	server := httptest.NewUnstartedServer(mux)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	streamCtx, closeStream := context.WithCancel(ctx)
+	stream, err := client.EventStream(streamCtx, connect.NewRequest(&schemaV1.EventStreamRequest{}))
+	require.Nil(t, err)
+	require.True(t, stream.Receive())
+	require.Equal(t, string(service.ProviderReady), stream.Msg().Type)
+	require.Equal(t, int64(1), eventing.subscriberCount())
+
+	rejected, err := client.EventStream(ctx, connect.NewRequest(&schemaV1.EventStreamRequest{}))
+	require.Nil(t, err)
+	require.False(t, rejected.Receive())
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(rejected.Err()))
+
+	// disconnecting frees the slot
+	closeStream()
+	require.Eventually(t, func() bool {
+		return eventing.subscriberCount() == 0
+	}, time.Second, 10*time.Millisecond)
+	stream, err = client.EventStream(ctx, connect.NewRequest(&schemaV1.EventStreamRequest{}))
+	require.Nil(t, err)
+	require.True(t, stream.Receive())
+	require.Equal(t, string(service.ProviderReady), stream.Msg().Type)
+}
+
+func TestFlag_Evaluation_NotReady(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(nil, store.NewFlags())
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+
+	_, err := s.ResolveBoolean(context.Background(), req)
+	require.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+	require.Contains(t, err.Error(), model.ProviderNotReadyErrorCode)
+
+	_, _, err = evaluator.SetState(isync.DataSync{FlagData: `{"flags": {}}`, Type: isync.ALL})
+	require.Nil(t, err)
+	_, err = s.ResolveBoolean(context.Background(), req)
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func TestFlag_Evaluation_ResponseVerbosity(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "myBoolFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "on", null] },
+      "metadata": { "team": "growth" }
+    }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		verbosity string
+		full      bool
+	}{
+		"default":           {full: true},
+		"full":              {verbosity: VerbosityFull, full: true},
+		"unknown verbosity": {verbosity: "random", full: true},
+		"minimal":           {verbosity: VerbosityMinimal},
+		"case insensitive":  {verbosity: "Minimal"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: evalCtx})
+			req.Header().Set(VerbosityHeader, tt.verbosity)
+			got, err := s.ResolveBoolean(context.Background(), req)
+			require.Nil(t, err)
+			require.True(t, got.Msg.Value)
+			allReq := connect.NewRequest(&schemaV1.ResolveAllRequest{Context: evalCtx})
+			allReq.Header().Set(VerbosityHeader, tt.verbosity)
+			all, err := s.ResolveAll(context.Background(), allReq)
+			require.Nil(t, err)
+			require.True(t, all.Msg.Flags["myBoolFlag"].GetBoolValue())
+
+			if tt.full {
+				require.Equal(t, "on", got.Msg.Variant)
+				require.Equal(t, model.TargetingMatchReason, got.Msg.Reason)
+				require.Equal(t, `{"team":"growth"}`, got.Header().Get(MetadataHeader))
+				require.Equal(t, "on", all.Msg.Flags["myBoolFlag"].Variant)
+				require.Equal(t, model.TargetingMatchReason, all.Msg.Flags["myBoolFlag"].Reason)
+			} else {
+				require.Empty(t, got.Msg.Variant)
+				require.Empty(t, got.Msg.Reason)
+				require.Empty(t, got.Header().Get(MetadataHeader))
+				require.Empty(t, all.Msg.Flags["myBoolFlag"].Variant)
+				require.Empty(t, all.Msg.Flags["myBoolFlag"].Reason)
+			}
+		})
+	}
+
+	t.Run("minimal errors", func(t *testing.T) {
+		req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "aMissingFlag"})
+		req.Header().Set(VerbosityHeader, VerbosityMinimal)
+		_, err := s.ResolveBoolean(context.Background(), req)
+		require.Equal(t, connect.CodeNotFound, connect.CodeOf(err), "errors should be returned in minimal responses")
+	})
+}
+
+func TestFlag_Evaluation_Canceled(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "myBoolFlag": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = s.ResolveBoolean(ctx, connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+	require.Equal(t, connect.CodeCanceled, connect.CodeOf(err))
+	_, err = s.ResolveAll(ctx, connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Equal(t, connect.CodeCanceled, connect.CodeOf(err))
+
+	deadline, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = s.ResolveBoolean(deadline, connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+	require.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+}
+
+func TestFlag_Evaluation_TypeMismatch(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "headerColor": { "state": "ENABLED", "variants": { "red": "#FF0000" }, "defaultVariant": "red" }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	_, err = s.ResolveObject(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveObjectRequest{FlagKey: "headerColor"}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	require.EqualError(t, err, "invalid_argument: FlagdError:, TYPE_MISMATCH: variant: 'red' of flag: "+
+		"'headerColor' is a string, not an object")
+	_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "headerColor"}))
+	require.EqualError(t, err, "invalid_argument: FlagdError:, TYPE_MISMATCH: variant: 'red' of flag: "+
+		"'headerColor' is a string, not a boolean")
+}
@@ -1,15 +1,29 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 
 	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
 	"github.com/bufbuild/connect-go"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// MetadataHeader is the response header carrying the json encoded resolution metadata
+const MetadataHeader = "Flagd-Metadata"
+
+// targetingKeyField is the evaluation context field identifying the subject of the evaluation
+const targetingKeyField = "targetingKey"
+
+// resolverFunc resolves the value, variant, reason and metadata of a flag, e.g. IEvaluator.ResolveBooleanValue
+type resolverFunc[T constraints] func(ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct) (
+	T, string, string, map[string]interface{}, error,
+)
+
 type response[T constraints] interface {
-	SetResult(value T, variant, reason string) error
+	SetResult(value T, variant, reason string, metadata map[string]interface{}) error
 }
 
 type constraints interface {
@@ -20,51 +34,53 @@ type booleanResponse struct {
 	*connect.Response[schemaV1.ResolveBooleanResponse]
 }
 
-func (r *booleanResponse) SetResult(value bool, variant, reason string) error {
+func (r *booleanResponse) SetResult(value bool, variant, reason string, metadata map[string]interface{}) error {
 	r.Msg.Value = value
 	r.Msg.Variant = variant
 	r.Msg.Reason = reason
-	return nil
+	return setMetadataHeader(r.Header(), metadata)
 }
 
 type stringResponse struct {
 	*connect.Response[schemaV1.ResolveStringResponse]
 }
 
-func (r *stringResponse) SetResult(value, variant, reason string) error {
+func (r *stringResponse) SetResult(value, variant, reason string, metadata map[string]interface{}) error {
 	r.Msg.Value = value
 	r.Msg.Variant = variant
 	r.Msg.Reason = reason
-	return nil
+	return setMetadataHeader(r.Header(), metadata)
 }
 
 type floatResponse struct {
 	*connect.Response[schemaV1.ResolveFloatResponse]
 }
 
-func (r *floatResponse) SetResult(value float64, variant, reason string) error {
+func (r *floatResponse) SetResult(value float64, variant, reason string, metadata map[string]interface{}) error {
 	r.Msg.Value = value
 	r.Msg.Variant = variant
 	r.Msg.Reason = reason
-	return nil
+	return setMetadataHeader(r.Header(), metadata)
 }
 
 type intResponse struct {
 	*connect.Response[schemaV1.ResolveIntResponse]
 }
 
-func (r *intResponse) SetResult(value int64, variant, reason string) error {
+func (r *intResponse) SetResult(value int64, variant, reason string, metadata map[string]interface{}) error {
 	r.Msg.Value = value
 	r.Msg.Variant = variant
 	r.Msg.Reason = reason
-	return nil
+	return setMetadataHeader(r.Header(), metadata)
 }
 
 type objectResponse struct {
 	*connect.Response[schemaV1.ResolveObjectResponse]
 }
 
-func (r *objectResponse) SetResult(value map[string]any, variant, reason string) error {
+func (r *objectResponse) SetResult(
+	value map[string]any, variant, reason string, metadata map[string]interface{},
+) error {
 	r.Msg.Reason = reason
 	val, err := structpb.NewStruct(value)
 	if err != nil {
@@ -73,5 +89,19 @@ func (r *objectResponse) SetResult(value map[string]any, variant, reason string)
 
 	r.Msg.Value = val
 	r.Msg.Variant = variant
+	return setMetadataHeader(r.Header(), metadata)
+}
+
+// setMetadataHeader writes the resolution metadata (if any) as a json encoded response header,
+// the schema does not yet carry metadata in the response messages
+func setMetadataHeader(header http.Header, metadata map[string]interface{}) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("metadata header construction: %w", err)
+	}
+	header.Set(MetadataHeader, string(b))
 	return nil
 }
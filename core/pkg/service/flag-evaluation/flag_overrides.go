@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/service"
+)
+
+// FlagOverridesPath sets the default variant or the state of a stored flag with a POST request, it's only served
+// with the admin API when authentication tokens are configured
+const FlagOverridesPath = "/admin/flag-overrides"
+
+type flagOverrideRequest struct {
+	FlagKey string `json:"flagKey"`
+	eval.FlagOverride
+}
+
+type flagOverrideResponse struct {
+	FlagKey string `json:"flagKey"`
+	eval.FlagOverride
+	// Previous holds the default variant and state of the flag before the override
+	Previous eval.FlagOverride `json:"previous"`
+}
+
+// FlagOverridesHandler overrides the default variant or the state of the flag of a POST request in the store, e.g. to
+// flip a kill switch without editing its source. The override holds until the next reload of the source changes the
+// flag, overrides are audit logged.
+func (s *FlagEvaluationService) FlagOverridesHandler() http.Handler {
+	return http.HandlerFunc(s.serveFlagOverrides)
+}
+
+func (s *FlagEvaluationService) serveFlagOverrides(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	overrides, ok := s.evaluator().(eval.FlagOverrides)
+	if !ok {
+		http.Error(w, "the evaluator can't override flags", http.StatusNotImplemented)
+		return
+	}
+	var req flagOverrideRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+
+	previous, notifications, err := overrides.OverrideFlag(req.FlagKey, req.FlagOverride)
+	if err != nil {
+		if err.Error() == model.FlagNotFoundErrorCode {
+			http.Error(w, fmt.Sprintf("flag: %s isn't stored", req.FlagKey), http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("invalid override: %v", err), http.StatusBadRequest)
+		return
+	}
+	res := flagOverrideResponse{FlagKey: req.FlagKey, FlagOverride: previous, Previous: previous}
+	if req.DefaultVariant != "" {
+		res.DefaultVariant = req.DefaultVariant
+	}
+	if req.State != "" {
+		res.State = req.State
+	}
+	s.auditLogger.Info(fmt.Sprintf("overrode flag: %s, default variant: %s -> %s, state: %s -> %s", req.FlagKey,
+		previous.DefaultVariant, res.DefaultVariant, previous.State, res.State))
+	s.eventingConfiguration.notify(service.Notification{
+		Type: service.ConfigurationChange,
+		Data: map[string]interface{}{
+			"flags": notifications,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFlagOverridesHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	core, logs := observer.New(zap.InfoLevel)
+	s := NewFlagEvaluationService(logger.NewLogger(zap.New(core), false), evaluator, nil)
+	server := httptest.NewServer(s.FlagOverridesHandler())
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{name: "invalid body", method: http.MethodPost, body: "myBoolFlag", want: http.StatusBadRequest},
+		{name: "missing flag key", method: http.MethodPost, body: `{"state":"DISABLED"}`, want: http.StatusBadRequest},
+		{name: "unknown flag", method: http.MethodPost, body: `{"flagKey":"other","state":"DISABLED"}`,
+			want: http.StatusNotFound},
+		{name: "unknown variant", method: http.MethodPost, body: `{"flagKey":"myBoolFlag","defaultVariant":"maybe"}`,
+			want: http.StatusBadRequest},
+		{name: "override", method: http.MethodPost, body: `{"flagKey":"myBoolFlag","defaultVariant":"off"}`,
+			want: http.StatusOK},
+		{name: "unknown method", method: http.MethodGet, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+		require.Nil(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, tt.want, res.StatusCode, tt.name)
+		if tt.want == http.StatusOK {
+			var body flagOverrideResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+			require.Equal(t, flagOverrideResponse{
+				FlagKey:      "myBoolFlag",
+				FlagOverride: eval.FlagOverride{DefaultVariant: "off", State: eval.Enabled},
+				Previous:     eval.FlagOverride{DefaultVariant: "on", State: eval.Enabled},
+			}, body, tt.name)
+		}
+		res.Body.Close()
+	}
+
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Contains(t, state, `"defaultVariant":"off"`, "the override should be applied to the store")
+	require.Equal(t, 1, logs.FilterMessage("overrode flag: myBoolFlag, default variant: on -> off, "+
+		"state: ENABLED -> ENABLED").Len(), "the override should be audit logged")
+}
+
+func TestConnectService_FlagOverrides(t *testing.T) {
+	tests := map[string]struct {
+		authTokens    []string
+		authorization string
+		wantStatus    int
+	}{
+		"authenticated":     {authTokens: []string{"token"}, authorization: "Bearer token", wantStatus: http.StatusOK},
+		"not authenticated": {authTokens: []string{"token"}, wantStatus: http.StatusUnauthorized},
+		"auth disabled":     {wantStatus: http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+			require.Nil(t, err)
+			svc := ConnectService{
+				ConnectServiceConfiguration: &ConnectServiceConfiguration{
+					AuthTokens:     tt.authTokens,
+					EnableAdminAPI: true,
+				},
+				Eval:    evaluator,
+				Logger:  logger.NewLogger(nil, false),
+				Metrics: otel.NewOTelRecorder(metric.NewManualReader(), name),
+				eventingConfiguration: &eventingConfiguration{
+					subs: make(map[interface{}]chan iservice.Notification),
+					mu:   &sync.RWMutex{},
+				},
+			}
+			server := httptest.NewServer(svc.serviceHandler())
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+FlagOverridesPath,
+				strings.NewReader(`{"flagKey":"myBoolFlag","state":"DISABLED"}`))
+			require.Nil(t, err)
+			if tt.authorization != "" {
+				req.Header.Set(authorizationHeader, tt.authorization)
+			}
+			res, err := server.Client().Do(req)
+			require.Nil(t, err)
+			res.Body.Close()
+			require.Equal(t, tt.wantStatus, res.StatusCode, "flags should only be overridden by authenticated requests")
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// InfoPath returns the evaluation engine version and the targeting dialect of flagd
+const InfoPath = "/info"
+
+type infoResponse struct {
+	EngineVersion int      `json:"engineVersion"`
+	Dialect       string   `json:"dialect"`
+	Operators     []string `json:"operators"`
+}
+
+// InfoHandler returns the version of the evaluation engine and the operators targeting rules may use, so clients can
+// check a flagd evaluates a configuration before pushing it
+func (s *FlagEvaluationService) InfoHandler() http.Handler {
+	return http.HandlerFunc(s.serveInfo)
+}
+
+func (s *FlagEvaluationService) serveInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	res := infoResponse{
+		EngineVersion: eval.EngineVersion,
+		Dialect:       eval.TargetingDialect,
+		Operators:     eval.SupportedOperators(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoHandler(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil)
+	server := httptest.NewServer(s.InfoHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var info infoResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&info))
+	require.Equal(t, eval.EngineVersion, info.EngineVersion)
+	require.Equal(t, "json-logic", info.Dialect)
+	require.Contains(t, info.Operators, "fractionalEvaluation")
+
+	res, err = http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
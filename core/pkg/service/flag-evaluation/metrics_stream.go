@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MetricsStreamProcedure is the server-streaming RPC emitting a snapshot of the metrics of the server at every
+// interval, served over gRPC, gRPC-web and Connect alongside the evaluation service as its messages are well-known
+// types: the request is a google.protobuf.Empty and each snapshot a google.protobuf.Struct
+const MetricsStreamProcedure = "/flagd.metrics.v1.MetricsService/StreamMetrics"
+
+// evaluationStats counts the unary evaluations served and their latency, for the snapshots of the metrics stream
+type evaluationStats struct {
+	evaluations atomic.Int64
+	errors      atomic.Int64
+	// latency is the sum of the latencies of the evaluations, in nanoseconds
+	latency atomic.Int64
+}
+
+func (e *evaluationStats) record(latency time.Duration, err error) {
+	e.evaluations.Add(1)
+	if err != nil {
+		e.errors.Add(1)
+	}
+	e.latency.Add(int64(latency))
+}
+
+// evaluationStatsInterceptor records the unary evaluations served and their latency in the stats
+func evaluationStatsInterceptor(stats *evaluationStats) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			started := time.Now()
+			res, err := next(ctx, req)
+			stats.record(time.Since(started), err)
+			return res, err
+		}
+	}
+}
+
+// metricsStream emits the metrics of the server, every interval, to each subscriber of MetricsStreamProcedure
+type metricsStream struct {
+	logger         *logger.Logger
+	interval       time.Duration
+	stats          *evaluationStats
+	eventing       *eventingConfiguration
+	connections    *connectionCounter
+	sourceStatuses *isync.SourceStatuses
+	// streams is the number of active subscribers of the metrics stream
+	streams atomic.Int64
+}
+
+// StreamMetrics sends a snapshot of the metrics as soon as the client subscribes, then every interval. Snapshots are
+// taken on schedule whatever the pace of the client, a client too slow to receive a snapshot before the next one is
+// taken only receives the latest one, the stale snapshot is dropped.
+func (m *metricsStream) StreamMetrics(
+	ctx context.Context, _ *connect.Request[emptypb.Empty], stream *connect.ServerStream[structpb.Struct],
+) error {
+	m.streams.Add(1)
+	defer m.streams.Add(-1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	snapshots := make(chan *structpb.Struct, 1)
+	go m.takeSnapshots(ctx, snapshots)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case snapshot := <-snapshots:
+			if err := stream.Send(snapshot); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// takeSnapshots offers a snapshot of the metrics at once then every interval until the context is done
+func (m *metricsStream) takeSnapshots(ctx context.Context, snapshots chan *structpb.Struct) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	var previous evaluationTotals
+	for {
+		var snapshot *structpb.Struct
+		snapshot, previous = m.snapshot(previous)
+		if offerLatest(snapshots, snapshot) {
+			m.logger.Debug("dropped a stale metrics snapshot of a slow metrics stream subscriber")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// offerLatest sends the snapshot to the channel of a single producer, replacing the snapshot left unread. It returns
+// whether an unread snapshot was dropped.
+func offerLatest(snapshots chan *structpb.Struct, snapshot *structpb.Struct) bool {
+	dropped := false
+	for {
+		select {
+		case snapshots <- snapshot:
+			return dropped
+		default:
+		}
+		select {
+		case <-snapshots:
+			dropped = true
+		default:
+		}
+	}
+}
+
+// evaluationTotals are the evaluation counters at the time of a snapshot, so the next one reports the evaluations of
+// its interval
+type evaluationTotals struct {
+	at          time.Time
+	evaluations int64
+	latency     int64
+}
+
+// snapshot returns the metrics of the server, the rate and mean latency of the evaluations being measured since the
+// previous totals
+func (m *metricsStream) snapshot(previous evaluationTotals) (*structpb.Struct, evaluationTotals) {
+	totals := evaluationTotals{
+		at:          time.Now(),
+		evaluations: m.stats.evaluations.Load(),
+		latency:     m.stats.latency.Load(),
+	}
+	var rate, meanLatency float64
+	if evaluations := totals.evaluations - previous.evaluations; evaluations > 0 {
+		meanLatency = float64(totals.latency-previous.latency) / float64(evaluations) / float64(time.Millisecond)
+		if !previous.at.IsZero() {
+			rate = float64(evaluations) / totals.at.Sub(previous.at).Seconds()
+		}
+	}
+	eventStreams, sseStreams := m.eventing.streamCounts()
+	snapshot, err := structpb.NewStruct(map[string]interface{}{
+		"time":                 totals.at.UTC().Format(time.RFC3339Nano),
+		"evaluations":          totals.evaluations,
+		"evaluationErrors":     m.stats.errors.Load(),
+		"evaluationsPerSecond": rate,
+		"meanLatencyMs":        meanLatency,
+		"connections":          m.connections.Active(),
+		"eventStreams":         eventStreams,
+		"sseStreams":           sseStreams,
+		"metricsStreams":       m.streams.Load(),
+		"sources":              sourceStatusValues(m.sourceStatuses),
+	})
+	if err != nil {
+		// the values are numbers, strings and lists of them, which structpb converts
+		m.logger.Error("metrics snapshot construction: " + err.Error())
+		return &structpb.Struct{}, totals
+	}
+	return snapshot, totals
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMetricsStream_Cadence(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "myBoolFlag": { "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	statuses := isync.NewSourceStatuses("file:flags.json")
+	statuses.RecordSync("file:flags.json", time.Now())
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{MetricsStreamInterval: interval},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		connections:     &connectionCounter{},
+		evaluationStats: &evaluationStats{},
+		sourceStatuses:  statuses,
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+
+	evaluations := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+	for i := 0; i < 3; i++ {
+		_, err := evaluations.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+		require.Nil(t, err)
+	}
+	_, err = evaluations.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "aMissingFlag"}))
+	require.NotNil(t, err)
+
+	client := connect.NewClient[emptypb.Empty, structpb.Struct](server.Client(), server.URL+MetricsStreamProcedure)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscribed := time.Now()
+	stream, err := client.CallServerStream(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.Nil(t, err)
+	// the stream is closed by canceling the call, as closing it drains the snapshots to come
+
+	var received []time.Time
+	var snapshots []map[string]interface{}
+	for i := 0; i < 3; i++ {
+		require.True(t, stream.Receive(), "snapshot %d: %v", i, stream.Err())
+		received = append(received, time.Now())
+		snapshots = append(snapshots, stream.Msg().AsMap())
+	}
+	require.Less(t, received[0].Sub(subscribed), interval, "the first snapshot should be sent at once")
+	for i := 1; i < len(received); i++ {
+		require.GreaterOrEqual(t, received[i].Sub(received[i-1]), interval/2,
+			"snapshots should be sent every interval")
+	}
+
+	first := snapshots[0]
+	require.Equal(t, float64(4), first["evaluations"])
+	require.Equal(t, float64(1), first["evaluationErrors"])
+	require.Equal(t, float64(1), first["metricsStreams"])
+	require.Equal(t, float64(0), first["eventStreams"])
+	sources, ok := first["sources"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, sources, 1)
+	source := sources[0].(map[string]interface{})
+	require.Equal(t, "file:flags.json", source["source"])
+	require.Equal(t, float64(1), source["updates"])
+	require.Contains(t, source, "lastSync")
+
+	require.Equal(t, float64(0), snapshots[1]["evaluationsPerSecond"],
+		"no evaluation was served during the interval")
+	require.Equal(t, float64(0), snapshots[1]["meanLatencyMs"])
+}
+
+func TestMetricsStream_Disabled(t *testing.T) {
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		evaluationStats: &evaluationStats{},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+
+	client := connect.NewClient[emptypb.Empty, structpb.Struct](server.Client(), server.URL+MetricsStreamProcedure)
+	stream, err := client.CallServerStream(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.Nil(t, err)
+	defer stream.Close()
+	require.False(t, stream.Receive())
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(stream.Err()),
+		"the stream shouldn't be served without an interval")
+}
+
+func TestMetricsStream_SlowConsumer(t *testing.T) {
+	m := &metricsStream{
+		logger:   logger.NewLogger(nil, false),
+		interval: 5 * time.Millisecond,
+		stats:    &evaluationStats{},
+		eventing: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	snapshots := make(chan *structpb.Struct, 1)
+	done := make(chan struct{})
+	go func() {
+		m.takeSnapshots(ctx, snapshots)
+		close(done)
+	}()
+
+	// the consumer is stalled for several intervals, during which evaluations are served
+	started := time.Now()
+	time.Sleep(20 * time.Millisecond)
+	m.stats.record(time.Millisecond, nil)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	require.Len(t, snapshots, 1, "stale snapshots should be dropped rather than queued")
+	snapshot := (<-snapshots).AsMap()
+	taken, err := time.Parse(time.RFC3339Nano, snapshot["time"].(string))
+	require.Nil(t, err)
+	require.True(t, taken.After(started.Add(20*time.Millisecond)), "the latest snapshot should be kept")
+	require.Equal(t, float64(1), snapshot["evaluations"])
+}
+
+func TestOfferLatest(t *testing.T) {
+	snapshots := make(chan *structpb.Struct, 1)
+	first := &structpb.Struct{Fields: map[string]*structpb.Value{"n": structpb.NewNumberValue(1)}}
+	second := &structpb.Struct{Fields: map[string]*structpb.Value{"n": structpb.NewNumberValue(2)}}
+
+	require.False(t, offerLatest(snapshots, first))
+	require.True(t, offerLatest(snapshots, second), "the unread snapshot should be dropped")
+	require.Same(t, second, <-snapshots)
+	require.Empty(t, snapshots)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/service"
+)
+
+// PinnedFlagsPath returns the pinned flags, or applies the pending definition of a pinned flag with a POST request,
+// it's only served with the admin API
+const PinnedFlagsPath = "/admin/pinned-flags"
+
+type pinnedFlagsRequest struct {
+	FlagKey string `json:"flagKey"`
+}
+
+type pinnedFlagsResponse struct {
+	Flags []eval.PinnedFlag `json:"flags"`
+}
+
+// PinnedFlagsHandler returns the pinned flags along with whether a reload proposed another definition, or applies the
+// pending definition of the flag of a POST request, the only path changing a pinned flag
+func (s *FlagEvaluationService) PinnedFlagsHandler() http.Handler {
+	return http.HandlerFunc(s.servePinnedFlags)
+}
+
+func (s *FlagEvaluationService) servePinnedFlags(w http.ResponseWriter, r *http.Request) {
+	pinned, ok := s.evaluator().(eval.PinnedFlags)
+	if !ok {
+		http.Error(w, "the evaluator can't pin flags", http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req pinnedFlagsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !isPinned(pinned.PinnedFlags(), req.FlagKey) {
+			http.Error(w, fmt.Sprintf("flag: %s isn't pinned", req.FlagKey), http.StatusNotFound)
+			return
+		}
+		notifications, applied := pinned.ApplyPinnedFlag(req.FlagKey)
+		if !applied {
+			http.Error(w, fmt.Sprintf("flag: %s has no pending definition", req.FlagKey), http.StatusConflict)
+			return
+		}
+		s.auditLogger.Info(fmt.Sprintf("applied the pending definition of pinned flag: %s", req.FlagKey))
+		s.eventingConfiguration.notify(service.Notification{
+			Type: service.ConfigurationChange,
+			Data: map[string]interface{}{
+				"flags": notifications,
+			},
+		})
+	default:
+		w.Header().Set("Allow", fmt.Sprintf("%s, %s", http.MethodGet, http.MethodPost))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	res := pinnedFlagsResponse{Flags: pinned.PinnedFlags()}
+	if res.Flags == nil {
+		res.Flags = []eval.PinnedFlag{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func isPinned(flags []eval.PinnedFlag, flagKey string) bool {
+	for _, flag := range flags {
+		if flag.FlagKey == flagKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const pinnedConfig = `{
+  "flags": {
+    "killSwitch": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "%s"
+    }
+  }
+}`
+
+func TestPinnedFlagsHandler(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(logger.NewLogger(nil, false), store.NewFlags(),
+		eval.WithPinnedFlags([]string{"killSwitch"}))
+	for _, variant := range []string{"off", "on"} {
+		_, _, err := evaluator.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(pinnedConfig, variant), Source: "flags.json", Type: sync.ALL,
+		})
+		require.Nil(t, err)
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.PinnedFlagsHandler())
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		want    int
+		pending bool
+	}{
+		{name: "list", method: http.MethodGet, want: http.StatusOK, pending: true},
+		{name: "invalid body", method: http.MethodPost, body: "killSwitch", want: http.StatusBadRequest},
+		{name: "not pinned", method: http.MethodPost, body: `{"flagKey":"other"}`, want: http.StatusNotFound},
+		{name: "apply", method: http.MethodPost, body: `{"flagKey":"killSwitch"}`, want: http.StatusOK},
+		{name: "nothing pending", method: http.MethodPost, body: `{"flagKey":"killSwitch"}`, want: http.StatusConflict},
+		{name: "unknown method", method: http.MethodDelete, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+		require.Nil(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, tt.want, res.StatusCode, tt.name)
+		if tt.want == http.StatusOK {
+			var body pinnedFlagsResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+			require.Equal(t, []eval.PinnedFlag{{FlagKey: "killSwitch", Pending: tt.pending}}, body.Flags, tt.name)
+		}
+		res.Body.Close()
+	}
+
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Contains(t, state, `"defaultVariant":"on"`, "the pending definition is applied")
+}
+
+func TestPinnedFlagsHandler_NotImplemented(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	res := httptest.NewRecorder()
+	s.PinnedFlagsHandler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, PinnedFlagsPath, nil))
+	require.Equal(t, http.StatusNotImplemented, res.Code)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ResolveRawContextPath resolves a flag of any type against the evaluation context given as the json body of the
+// request, the key of the flag being the flagKey query parameter
+const ResolveRawContextPath = "/resolve-raw-context"
+
+// ResolveRawContextHandler resolves a flag of any type as ResolveAnyHandler does, against an evaluation context sent
+// as raw json bytes. Callers already holding the context as json, e.g. gateways, forward it as is rather than
+// encoding it into a protobuf Struct, and the bytes are decoded straight into the evaluation context.
+func (s *FlagEvaluationService) ResolveRawContextHandler() http.Handler {
+	return http.HandlerFunc(s.serveResolveRawContext)
+}
+
+func (s *FlagEvaluationService) serveResolveRawContext(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	evaluator := s.evaluator()
+	types, ok := evaluator.(eval.FlagTypes)
+	if !ok {
+		http.Error(w, "the evaluator can't infer the type of flags", http.StatusNotImplemented)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	if flagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading the context: %v", err), http.StatusBadRequest)
+		return
+	}
+	evalCtx, err := rawContext(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.serveAny(w, r, evaluator, types, flagKey, evalCtx)
+}
+
+// rawContext decodes an evaluation context from its json, an empty body being an empty context
+func rawContext(body []byte) (*structpb.Struct, error) {
+	evalCtx := evaluationContext(nil)
+	if len(body) == 0 {
+		return evalCtx, nil
+	}
+	if err := protojson.Unmarshal(body, evalCtx); err != nil {
+		return nil, fmt.Errorf("context isn't a valid json object: %w", err)
+	}
+	return evalCtx, nil
+}
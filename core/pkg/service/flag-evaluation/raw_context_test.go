@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const rawContextFlagConfig = `{
+  "flags": {
+    "adultFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "and": [
+        { ">=": [{ "var": "user.age" }, 18] },
+        { "in": [{ "var": "country" }, ["CA", "US"]] }
+      ] }, "on", "off"] }
+    },
+    "planFlag": {
+      "state": "ENABLED",
+      "variants": { "free": "free", "pro": "pro" },
+      "defaultVariant": "free",
+      "targeting": { "if": [{ "in": ["pro", { "var": "plans" }] }, "pro", null] }
+    }
+  }
+}`
+
+var rawContexts = []string{
+	``,
+	`{}`,
+	`{"user": {"age": 30}, "country": "CA"}`,
+	`{"user": {"age": 17.5}, "country": "US", "beta": true}`,
+	`{"user": {"age": 1e2}, "country": "FR", "targetingKey": "user-1"}`,
+	`{"plans": ["free", "pro"], "nickname": "café", "nothing": null}`,
+	`{"plans": "free"}`,
+}
+
+func rawContextService(t testing.TB) *FlagEvaluationService {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, rawContextFlagConfig)
+	require.Nil(t, err)
+	return NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+}
+
+func postRawContext(t *testing.T, serverURL string, flagKey string, rawContext string) (int, string) {
+	t.Helper()
+	res, err := http.Post(serverURL+"?flagKey="+url.QueryEscape(flagKey), "application/json",
+		strings.NewReader(rawContext))
+	require.Nil(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestResolveRawContextHandler(t *testing.T) {
+	s := rawContextService(t)
+	raw := httptest.NewServer(s.ResolveRawContextHandler())
+	defer raw.Close()
+	resolveAny := httptest.NewServer(s.ResolveAnyHandler())
+	defer resolveAny.Close()
+
+	for _, rawContext := range rawContexts {
+		for _, flagKey := range []string{"adultFlag", "planFlag"} {
+			t.Run(flagKey+rawContext, func(t *testing.T) {
+				status, resolution := postRawContext(t, raw.URL, flagKey, rawContext)
+				require.Equal(t, http.StatusOK, status, resolution)
+
+				var values map[string]interface{}
+				if rawContext != "" {
+					require.Nil(t, json.Unmarshal([]byte(rawContext), &values))
+				}
+				res := postResolveAny(t, resolveAny.URL, resolveAnyRequest{FlagKey: flagKey, Context: values})
+				defer res.Body.Close()
+				want, err := io.ReadAll(res.Body)
+				require.Nil(t, err)
+				require.JSONEq(t, string(want), resolution,
+					"the raw context should resolve as the decoded context does")
+			})
+		}
+	}
+}
+
+func TestResolveRawContextHandler_StructpbEquivalence(t *testing.T) {
+	s := rawContextService(t)
+	raw := httptest.NewServer(s.ResolveRawContextHandler())
+	defer raw.Close()
+
+	for _, rawContext := range rawContexts {
+		t.Run(rawContext, func(t *testing.T) {
+			evalCtx := &structpb.Struct{}
+			if rawContext != "" {
+				require.Nil(t, protojson.Unmarshal([]byte(rawContext), evalCtx))
+			}
+			want, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+				&schemaV1.ResolveBooleanRequest{FlagKey: "adultFlag", Context: evalCtx}))
+			require.Nil(t, err)
+
+			status, body := postRawContext(t, raw.URL, "adultFlag", rawContext)
+			require.Equal(t, http.StatusOK, status, body)
+			var resolved resolveAnyResponse
+			require.Nil(t, json.Unmarshal([]byte(body), &resolved))
+			require.Equal(t, want.Msg.GetVariant(), resolved.Variant)
+			require.Equal(t, want.Msg.GetReason(), resolved.Reason)
+			require.JSONEq(t, `{"@type": "type.googleapis.com/google.protobuf.BoolValue", "value": `+
+				strconv.FormatBool(want.Msg.GetValue())+`}`, string(resolved.Value))
+		})
+	}
+}
+
+func TestResolveRawContextHandler_Errors(t *testing.T) {
+	server := httptest.NewServer(rawContextService(t).ResolveRawContextHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		flagKey    string
+		context    string
+		wantStatus int
+	}{
+		"missing flag key": {context: `{}`, wantStatus: http.StatusBadRequest},
+		"invalid json":     {flagKey: "adultFlag", context: `{"user":`, wantStatus: http.StatusBadRequest},
+		"not an object":    {flagKey: "adultFlag", context: `["user"]`, wantStatus: http.StatusBadRequest},
+		"unknown flag":     {flagKey: "unknown", context: `{}`, wantStatus: http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, _ := postRawContext(t, server.URL, tt.flagKey, tt.context)
+			require.Equal(t, tt.wantStatus, status)
+		})
+	}
+
+	res, err := http.Get(server.URL + "?flagKey=adultFlag")
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+// BenchmarkRawContext compares the decoding of an evaluation context held as json, encoded into the Struct of a
+// typed resolve request and decoded by flagd, with its decoding from the raw bytes
+func BenchmarkRawContext(b *testing.B) {
+	body := []byte(`{"targetingKey": "user-1", "email": "user@faas.com", "country": "CA", ` +
+		`"user": {"age": 30, "plan": "pro", "groups": ["beta", "staff"], "devices": {"ios": "17.1", "web": true}}}`)
+
+	b.Run("structpb", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var values map[string]interface{}
+			if err := json.Unmarshal(body, &values); err != nil {
+				b.Fatal(err)
+			}
+			evalCtx, err := structpb.NewStruct(values)
+			if err != nil {
+				b.Fatal(err)
+			}
+			wire, err := proto.Marshal(&schemaV1.ResolveBooleanRequest{FlagKey: "adultFlag", Context: evalCtx})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := proto.Unmarshal(wire, &schemaV1.ResolveBooleanRequest{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := rawContext(body); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
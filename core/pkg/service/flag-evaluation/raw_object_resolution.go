@@ -0,0 +1,105 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// RawObjectResolutionPath resolves an object flag along with the raw value of its variant, it's only served with the
+// admin API enabled
+const RawObjectResolutionPath = "/admin/raw-object-resolution"
+
+type rawObjectResolutionRequest struct {
+	FlagKey string                 `json:"flagKey"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type rawObjectResolutionResponse struct {
+	FlagKey string `json:"flagKey"`
+	// RawValue is the value of the variant as configured, Value the value resolved by the evaluation endpoints
+	RawValue map[string]interface{} `json:"rawValue"`
+	Value    map[string]interface{} `json:"value"`
+	// Transformed is whether the resolved value differs from the raw value, e.g. as fields are masked
+	Transformed bool                   `json:"transformed"`
+	Variant     string                 `json:"variant,omitempty"`
+	Reason      string                 `json:"reason"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	ErrorCode   string                 `json:"errorCode,omitempty"`
+}
+
+// RawObjectResolutionHandler resolves an object flag against an evaluation context, responding the value of its
+// resolved variant as configured alongside the resolved value, so flag owners can diagnose the transforms of the
+// value, e.g. its masked fields
+func (s *FlagEvaluationService) RawObjectResolutionHandler() http.Handler {
+	return http.HandlerFunc(s.serveRawObjectResolution)
+}
+
+func (s *FlagEvaluationService) serveRawObjectResolution(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resolver, ok := s.evaluator().(eval.RawObjectResolver)
+	if !ok {
+		http.Error(w, "the evaluator can't resolve raw object values", http.StatusNotImplemented)
+		return
+	}
+	var req rawObjectResolutionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	res := rawObjectResolutionResponse{FlagKey: req.FlagKey}
+	resolution, err := resolver.ResolveRawObjectValue(r.Context(), reqID, req.FlagKey, evalCtx)
+	if err != nil {
+		res.Reason = model.ErrorReason
+		res.ErrorCode = err.Error()
+	} else {
+		res.RawValue = resolution.RawValue
+		res.Value = resolution.Value
+		res.Transformed = !reflect.DeepEqual(resolution.RawValue, resolution.Value)
+		res.Variant = resolution.Variant
+		res.Reason = s.normalizeReason(req.FlagKey, resolution.Reason)
+		res.Metadata = resolution.Metadata
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding raw object resolution response: %v", err))
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const rawObjectFlagConfig = `{
+  "flags": {
+    "checkoutConfig": {
+      "state": "ENABLED",
+      "variants": {
+        "default": { "title": "Checkout", "internal": { "owner": "payments" }, "tuning": { "retries": 3, "timeoutMs": 500 } },
+        "beta": { "title": "Checkout beta" }
+      },
+      "defaultVariant": "default",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "beta"] }, "beta", null] },
+      "maskedFields": ["internal", "tuning.timeoutMs"]
+    },
+    "myStringFlag": {
+      "state": "ENABLED",
+      "variants": { "key1": "val1" },
+      "defaultVariant": "key1"
+    }
+  }
+}`
+
+func TestRawObjectResolutionHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, rawObjectFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.RawObjectResolutionHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		body         string
+		wantCode     int
+		wantResponse rawObjectResolutionResponse
+	}{
+		"masked fields": {
+			body:     `{"flagKey": "checkoutConfig"}`,
+			wantCode: http.StatusOK,
+			wantResponse: rawObjectResolutionResponse{
+				FlagKey: "checkoutConfig",
+				RawValue: map[string]interface{}{
+					"title":    "Checkout",
+					"internal": map[string]interface{}{"owner": "payments"},
+					"tuning":   map[string]interface{}{"retries": float64(3), "timeoutMs": float64(500)},
+				},
+				Value: map[string]interface{}{
+					"title":  "Checkout",
+					"tuning": map[string]interface{}{"retries": float64(3)},
+				},
+				Transformed: true,
+				Variant:     "default",
+				Reason:      model.DefaultReason,
+			},
+		},
+		"variant without masked fields": {
+			body:     `{"flagKey": "checkoutConfig", "context": {"plan": "beta"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: rawObjectResolutionResponse{
+				FlagKey:  "checkoutConfig",
+				RawValue: map[string]interface{}{"title": "Checkout beta"},
+				Value:    map[string]interface{}{"title": "Checkout beta"},
+				Variant:  "beta",
+				Reason:   model.TargetingMatchReason,
+			},
+		},
+		"not an object flag": {
+			body:     `{"flagKey": "myStringFlag"}`,
+			wantCode: http.StatusOK,
+			wantResponse: rawObjectResolutionResponse{
+				FlagKey:   "myStringFlag",
+				Reason:    model.ErrorReason,
+				ErrorCode: model.TypeMismatchErrorCode,
+			},
+		},
+		"missing flag": {
+			body:     `{"flagKey": "aMissingFlag"}`,
+			wantCode: http.StatusOK,
+			wantResponse: rawObjectResolutionResponse{
+				FlagKey:   "aMissingFlag",
+				Reason:    model.ErrorReason,
+				ErrorCode: model.FlagNotFoundErrorCode,
+			},
+		},
+		"without flag key": {
+			body:     `{"context": {}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid json": {
+			body:     `{"flagKey": `,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := http.Post(server.URL, "application/json", strings.NewReader(tt.body))
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got rawObjectResolutionResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Equal(t, tt.wantResponse, got)
+		})
+	}
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
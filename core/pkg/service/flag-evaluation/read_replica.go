@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// ReadReplicaRPC identifies the RPCs which may be served from the read replica of the flag store
+type ReadReplicaRPC string
+
+const (
+	// ReadReplicaBulk serves the evaluations of every flag at ResolveAll and the batch evaluations at
+	// BatchEvaluationPath from the read replica
+	ReadReplicaBulk ReadReplicaRPC = "bulk"
+	// ReadReplicaList serves the flags listed at VariantsPath from the read replica
+	ReadReplicaList ReadReplicaRPC = "list"
+	// ReadReplicaWhatIf serves the what-if evaluations at WhatIfPath and ContextSnapshotEvaluationPath from the read
+	// replica
+	ReadReplicaWhatIf ReadReplicaRPC = "what-if"
+)
+
+// ParseReadReplicaRPCs returns the read replica RPCs of their names
+func ParseReadReplicaRPCs(names []string) ([]ReadReplicaRPC, error) {
+	rpcs := make([]ReadReplicaRPC, 0, len(names))
+	for _, name := range names {
+		switch rpc := ReadReplicaRPC(strings.TrimSpace(name)); rpc {
+		case ReadReplicaBulk, ReadReplicaList, ReadReplicaWhatIf:
+			rpcs = append(rpcs, rpc)
+		default:
+			return nil, fmt.Errorf("unknown read replica rpc: '%s', expected '%s', '%s' or '%s'",
+				name, ReadReplicaBulk, ReadReplicaList, ReadReplicaWhatIf)
+		}
+	}
+	return rpcs, nil
+}
+
+// withReadReplica serves the RPCs from the read replica of the flag store rather than the evaluator, the replica
+// being the one of the evaluators if nil
+func withReadReplica(replica eval.IEvaluator, rpcs []ReadReplicaRPC) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		if replica != nil {
+			s.evaluators.swap(s.evaluator(), replica)
+		}
+		s.readReplicaRPCs = make(map[ReadReplicaRPC]struct{}, len(rpcs))
+		for _, rpc := range rpcs {
+			s.readReplicaRPCs[rpc] = struct{}{}
+		}
+	}
+}
+
+// evaluatorOf returns the evaluator serving the RPC, the read replica if the RPC is served from it
+func (s *FlagEvaluationService) evaluatorOf(rpc ReadReplicaRPC) eval.IEvaluator {
+	evaluators := s.evaluators.load()
+	if _, ok := s.readReplicaRPCs[rpc]; ok && evaluators.readReplica != nil {
+		return evaluators.readReplica
+	}
+	return evaluators.live
+}
+
+// readReplicaOf returns the read replica of the evaluator if RPCs are served from it, logging a warning when the
+// evaluator doesn't support read replicas, e.g. the canary and tenant evaluators, whose RPCs are served live
+func (s *ConnectService) readReplicaOf(evaluator eval.IEvaluator) eval.IEvaluator {
+	config := s.ConnectServiceConfiguration
+	if config.ReadReplicaStaleness <= 0 || len(config.ReadReplicaRPCs) == 0 {
+		return nil
+	}
+	replicas, ok := evaluator.(eval.ReadReplicas)
+	if !ok {
+		s.Logger.Warn("the evaluator doesn't support read replicas, the read replica rpcs are served from the live flags")
+		return nil
+	}
+	return replicas.ReadReplica(config.ReadReplicaStaleness)
+}
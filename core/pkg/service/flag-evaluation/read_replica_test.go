@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	replicaConfigV1 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "off"}}}`
+	replicaConfigV2 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "on"}}}`
+)
+
+func TestReadReplica(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, replicaConfigV1)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		withReadReplica(evaluator.ReadReplica(time.Hour), []ReadReplicaRPC{ReadReplicaBulk, ReadReplicaList}))
+	resolveAll := func() bool {
+		t.Helper()
+		res, err := s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+		require.Nil(t, err)
+		return res.Msg.GetFlags()["checkout"].GetBoolValue()
+	}
+	listed := func() string {
+		t.Helper()
+		server := httptest.NewServer(s.VariantsHandler())
+		defer server.Close()
+		res, err := http.Get(server.URL + "?flagKey=checkout")
+		require.Nil(t, err)
+		defer res.Body.Close()
+		var variants map[string]interface{}
+		require.Nil(t, json.NewDecoder(res.Body).Decode(&variants))
+		return variants["defaultVariant"].(string)
+	}
+
+	require.False(t, resolveAll())
+	require.Equal(t, "off", listed())
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: replicaConfigV2, Source: "file:flags.json", Type: sync.ALL})
+	require.Nil(t, err)
+
+	res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "checkout"}))
+	require.Nil(t, err)
+	require.True(t, res.Msg.GetValue(), "single flag resolves should be served from the live flags")
+	require.False(t, resolveAll(), "bulk evaluations shouldn't reflect the update until the replica is refreshed")
+	require.Equal(t, "off", listed(), "listed flags shouldn't reflect the update until the replica is refreshed")
+}
+
+func TestReadReplica_Disabled(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, replicaConfigV1)
+	require.Nil(t, err)
+	svc := &ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{ReadReplicaRPCs: []ReadReplicaRPC{ReadReplicaBulk}},
+		Logger:                      logger.NewLogger(nil, false),
+	}
+	require.Nil(t, svc.readReplicaOf(evaluator), "rpcs should be served live without a staleness")
+
+	svc.ConnectServiceConfiguration.ReadReplicaStaleness = time.Second
+	require.NotNil(t, svc.readReplicaOf(evaluator))
+	canary := eval.NewCanaryEvaluator(nil, evaluator, evaluator, 10, nil)
+	require.Nil(t, svc.readReplicaOf(canary), "evaluators without read replicas should be served live")
+}
+
+func TestParseReadReplicaRPCs(t *testing.T) {
+	rpcs, err := ParseReadReplicaRPCs([]string{"bulk", " what-if"})
+	require.Nil(t, err)
+	require.Equal(t, []ReadReplicaRPC{ReadReplicaBulk, ReadReplicaWhatIf}, rpcs)
+	_, err = ParseReadReplicaRPCs([]string{"resolve"})
+	require.EqualError(t, err, "unknown read replica rpc: 'resolve', expected 'bulk', 'list' or 'what-if'")
+}
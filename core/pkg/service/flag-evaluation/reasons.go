@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// UnknownReasons defines how reasons returned by the evaluator which aren't part of the flagd schema are responded
+type UnknownReasons string
+
+const (
+	// UnknownReasonsNormalize responds unknown reasons as UNKNOWN, logging a warning, the default
+	UnknownReasonsNormalize UnknownReasons = "normalize"
+	// UnknownReasonsPassThrough responds unknown reasons verbatim
+	UnknownReasonsPassThrough UnknownReasons = "pass-through"
+)
+
+// knownReasons are the reasons of the flagd schema, strict clients may reject any other reason
+var knownReasons = map[string]struct{}{
+	model.TargetingMatchReason: {},
+	model.SplitReason:          {},
+	model.DisabledReason:       {},
+	model.DefaultReason:        {},
+	model.UnknownReason:        {},
+	model.ErrorReason:          {},
+	model.StaticReason:         {},
+	model.DerivedReason:        {},
+	model.OverrideReason:       {},
+	model.StaleReason:          {},
+	model.ClientDefaultReason:  {},
+	model.ZeroValueReason:      {},
+}
+
+// ParseUnknownReasons returns the unknown reasons policy of its name, an empty name defaults to normalize
+func ParseUnknownReasons(policy string) (UnknownReasons, error) {
+	switch UnknownReasons(policy) {
+	case "":
+		return UnknownReasonsNormalize, nil
+	case UnknownReasonsNormalize, UnknownReasonsPassThrough:
+		return UnknownReasons(policy), nil
+	default:
+		return "", fmt.Errorf("unknown reasons policy: '%s', expected '%s' or '%s'",
+			policy, UnknownReasonsNormalize, UnknownReasonsPassThrough)
+	}
+}
+
+// WithUnknownReasons sets the policy of reasons returned by the evaluator which aren't part of the flagd schema
+func WithUnknownReasons(policy UnknownReasons) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.unknownReasons = policy
+	}
+}
+
+// normalizeReason returns UNKNOWN in place of a reason which isn't part of the flagd schema, unless unknown reasons
+// pass through. An unset policy normalizes.
+func (s *FlagEvaluationService) normalizeReason(flagKey string, reason string) string {
+	if _, ok := knownReasons[reason]; ok || reason == "" {
+		return reason
+	}
+	if s.unknownReasons == UnknownReasonsPassThrough {
+		return reason
+	}
+	s.logger.Warn(fmt.Sprintf("flag: %s resolved the unknown reason: '%s', responding %s",
+		flagKey, reason, model.UnknownReason))
+	return model.UnknownReason
+}
+
+// normalizeReasons wraps the resolver, normalizing the reasons it returns
+func normalizeReasons[T constraints](s *FlagEvaluationService, resolver resolverFunc[T]) resolverFunc[T] {
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (T, string, string, map[string]interface{}, error) {
+		value, variant, reason, metadata, err := resolver(ctx, reqID, flagKey, evalCtx)
+		return value, variant, s.normalizeReason(flagKey, reason), metadata, err
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/golang/mock/gomock"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const novelReason = "NOVEL_REASON"
+
+func TestUnknownReasons(t *testing.T) {
+	tests := map[string]struct {
+		policy     UnknownReasons
+		reason     string
+		wantReason string
+		wantLogs   int
+	}{
+		"unknown reason normalized by default": {
+			reason:     novelReason,
+			wantReason: model.UnknownReason,
+			wantLogs:   1,
+		},
+		"unknown reason normalized": {
+			policy:     UnknownReasonsNormalize,
+			reason:     novelReason,
+			wantReason: model.UnknownReason,
+			wantLogs:   1,
+		},
+		"unknown reason passed through": {
+			policy:     UnknownReasonsPassThrough,
+			reason:     novelReason,
+			wantReason: novelReason,
+		},
+		"known reason": {
+			reason:     model.TargetingMatchReason,
+			wantReason: model.TargetingMatchReason,
+		},
+	}
+	ctrl := gomock.NewController(t)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			evaluator := mock.NewMockIEvaluator(ctrl)
+			evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).Return(
+				true, "on", tt.reason, nil, nil,
+			)
+			evaluator.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any(), gomock.Any()).Return([]eval.AnyValue{
+				{Value: true, Variant: "on", Reason: tt.reason, FlagKey: "myBoolFlag"},
+			})
+			var opts []FlagEvaluationServiceOption
+			if tt.policy != "" {
+				opts = append(opts, WithUnknownReasons(tt.policy))
+			}
+			s := NewFlagEvaluationService(logger.NewLogger(zap.New(core), false), evaluator, nil, opts...)
+
+			res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+				&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+			))
+			require.Nil(t, err)
+			require.Equal(t, tt.wantReason, res.Msg.Reason)
+			all, err := s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+			require.Nil(t, err)
+			require.Equal(t, tt.wantReason, all.Msg.Flags["myBoolFlag"].Reason)
+			require.Equal(t, 2*tt.wantLogs, logs.FilterMessageSnippet(novelReason).Len())
+		})
+	}
+}
+
+func TestParseUnknownReasons(t *testing.T) {
+	policy, err := ParseUnknownReasons("")
+	require.Nil(t, err)
+	require.Equal(t, UnknownReasonsNormalize, policy)
+	policy, err = ParseUnknownReasons("pass-through")
+	require.Nil(t, err)
+	require.Equal(t, UnknownReasonsPassThrough, policy)
+	_, err = ParseUnknownReasons("drop")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+)
+
+// RegoBundlePath serves the targeting of the flags translated into an OPA bundle of Rego policies, it's only served
+// with the admin API
+const RegoBundlePath = "/admin/rego-bundle"
+
+// RegoBundleHandler serves the Rego policies translating the targeting of the flags as a gzipped OPA bundle, the
+// flags which can't be translated are listed with their reason in its data
+func (s *FlagEvaluationService) RegoBundleHandler() http.Handler {
+	return http.HandlerFunc(s.serveRegoBundle)
+}
+
+func (s *FlagEvaluationService) serveRegoBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	exporter, ok := s.evaluator().(eval.RegoExport)
+	if !ok {
+		http.Error(w, "the evaluator can't export rego policies", http.StatusNotImplemented)
+		return
+	}
+
+	bundle := exporter.ExportRego()
+	// the bundle is written before the response, so a failure is reported with its status
+	var buf bytes.Buffer
+	if err := bundle.WriteBundle(&buf); err != nil {
+		http.Error(w, fmt.Sprintf("writing the bundle: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info(fmt.Sprintf("exported the rego policies of %d flags, %d flags can't be translated",
+		len(bundle.Policies)-1, len(bundle.Untranslated)))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="bundle.tar.gz"`)
+	_, _ = w.Write(buf.Bytes())
+}
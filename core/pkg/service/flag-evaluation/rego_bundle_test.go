@@ -0,0 +1,55 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegoBundleHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, comparisonConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.RegoBundleHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/gzip", res.Header.Get("Content-Type"))
+	gz, err := gzip.NewReader(res.Body)
+	require.Nil(t, err)
+	tr := tar.NewReader(gz)
+	var paths []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		paths = append(paths, header.Name)
+	}
+	require.Equal(t, []string{
+		".manifest", "data.json", "flagd/flags/beta/policy.rego", "flagd/flags/color/policy.rego", "flagd/lib/lib.rego",
+	}, paths)
+
+	post, err := http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	post.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, post.StatusCode)
+}
+
+func TestRegoBundleHandler_NotImplemented(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil)
+	res := httptest.NewRecorder()
+	s.RegoBundleHandler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, RegoBundlePath, nil))
+	require.Equal(t, http.StatusNotImplemented, res.Code)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRequireContext(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "entitlement": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "premium"] }, "on", null] },
+      "requireContext": ["plan"]
+    }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "premium"})
+	require.Nil(t, err)
+	res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "entitlement", Context: evalCtx},
+	))
+	require.Nil(t, err)
+	require.True(t, res.Msg.GetValue())
+
+	_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "entitlement"},
+	))
+	require.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Len(t, connectErr.Details(), 1)
+	detail, err := connectErr.Details()[0].Value()
+	require.Nil(t, err)
+	failure, ok := detail.(*errdetails.PreconditionFailure)
+	require.True(t, ok)
+	require.Len(t, failure.GetViolations(), 1)
+	require.Equal(t, "plan", failure.GetViolations()[0].GetSubject())
+}
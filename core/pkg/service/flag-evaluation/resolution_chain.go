@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ResolutionChainPath resolves a flag along with the resolutions of the prerequisite flags it depends on, it's only
+// served with the admin API enabled
+const ResolutionChainPath = "/admin/resolution-chain"
+
+type resolutionChainRequest struct {
+	FlagKey string                 `json:"flagKey"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type resolutionChainResponse struct {
+	FlagKey string `json:"flagKey"`
+	// Chain holds the resolutions of the evaluated prerequisite flags in their evaluation order, followed by the
+	// resolution of the flag
+	Chain []resolutionChainStep `json:"chain"`
+}
+
+type resolutionChainStep struct {
+	FlagKey   string `json:"flagKey"`
+	Depth     int    `json:"depth"`
+	Variant   string `json:"variant,omitempty"`
+	Reason    string `json:"reason"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// ResolutionChainHandler resolves a flag against an evaluation context, responding the resolution of each prerequisite
+// flag of its derived expression leading to the resolution of the flag, so flag owners can diagnose the resolutions
+// of derived flags
+func (s *FlagEvaluationService) ResolutionChainHandler() http.Handler {
+	return http.HandlerFunc(s.serveResolutionChain)
+}
+
+func (s *FlagEvaluationService) serveResolutionChain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	chains, ok := s.evaluator().(eval.ResolutionChains)
+	if !ok {
+		http.Error(w, "the evaluator can't resolve the chain of flags", http.StatusNotImplemented)
+		return
+	}
+	var req resolutionChainRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	// the error of the resolution is the error code of its last step
+	steps, _ := chains.ResolveChain(r.Context(), reqID, req.FlagKey, evalCtx)
+	res := resolutionChainResponse{FlagKey: req.FlagKey, Chain: make([]resolutionChainStep, 0, len(steps))}
+	for _, step := range steps {
+		res.Chain = append(res.Chain, resolutionChainStep{
+			FlagKey:   step.FlagKey,
+			Depth:     step.Depth,
+			Variant:   step.Variant,
+			Reason:    s.normalizeReason(step.FlagKey, step.Reason),
+			ErrorCode: step.ErrorCode,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding resolution chain response: %v", err))
+	}
+}
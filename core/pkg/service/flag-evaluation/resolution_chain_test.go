@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const resolutionChainFlagConfig = `{
+  "flags": {
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "beta"] }, "on", null] }
+    },
+    "blocked": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    },
+    "eligible": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": { "and": ["beta", { "not": "blocked" }] }
+    },
+    "newCheckout": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "derived": "eligible"
+    }
+  }
+}`
+
+func TestResolutionChainHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolutionChainFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.ResolutionChainHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		body         string
+		wantCode     int
+		wantResponse resolutionChainResponse
+	}{
+		"two levels of prerequisites": {
+			body:     `{"flagKey": "newCheckout", "context": {"plan": "beta"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: resolutionChainResponse{
+				FlagKey: "newCheckout",
+				Chain: []resolutionChainStep{
+					{FlagKey: "beta", Depth: 2, Variant: "on", Reason: model.TargetingMatchReason},
+					{FlagKey: "blocked", Depth: 2, Variant: "off", Reason: model.StaticReason},
+					{FlagKey: "eligible", Depth: 1, Variant: "on", Reason: model.DerivedReason},
+					{FlagKey: "newCheckout", Variant: "on", Reason: model.DerivedReason},
+				},
+			},
+		},
+		"missing flag": {
+			body:     `{"flagKey": "aMissingFlag"}`,
+			wantCode: http.StatusOK,
+			wantResponse: resolutionChainResponse{
+				FlagKey: "aMissingFlag",
+				Chain: []resolutionChainStep{
+					{FlagKey: "aMissingFlag", Reason: model.ErrorReason, ErrorCode: model.FlagNotFoundErrorCode},
+				},
+			},
+		},
+		"without flag key": {
+			body:     `{"context": {}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid json": {
+			body:     `{"flagKey": `,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := http.Post(server.URL, "application/json", strings.NewReader(tt.body))
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got resolutionChainResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Equal(t, tt.wantResponse, got)
+		})
+	}
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ResolveStatusHeader is the response header of ResolveAll requests telling whether every flag resolved, either
+	// ResolveStatusSuccess or ResolveStatusPartial
+	ResolveStatusHeader = "Flagd-Resolve-Status"
+	// ResolveErrorsHeader is the response header of ResolveAll requests carrying the json encoded errors of the flags
+	// which failed to resolve, which the response leaves out
+	ResolveErrorsHeader = "Flagd-Resolve-Errors"
+
+	// ResolveStatusSuccess is the resolve status of responses holding every flag
+	ResolveStatusSuccess = "success"
+	// ResolveStatusPartial is the resolve status of responses leaving out flags which failed to resolve
+	ResolveStatusPartial = "partial"
+
+	// maxResolveErrors bounds the flag errors of the errors header, so it stays within the header limits of clients
+	maxResolveErrors = 100
+)
+
+// resolveError is the json encoding of a flag which failed to resolve in the errors header
+type resolveError struct {
+	FlagKey   string `json:"flagKey"`
+	ErrorCode string `json:"errorCode"`
+	Reason    string `json:"reason"`
+}
+
+// resolveAllWithErrors resolves every flag, along with the errors of the flags which failed to resolve if the
+// evaluator reports them
+func resolveAllWithErrors(
+	ctx context.Context, evaluator eval.IEvaluator, reqID string, evalCtx *structpb.Struct,
+) ([]eval.AnyValue, []eval.FlagError) {
+	if bulk, ok := evaluator.(eval.BulkErrors); ok {
+		return bulk.ResolveAllValuesWithErrors(ctx, reqID, evalCtx)
+	}
+	return evaluator.ResolveAllValues(ctx, reqID, evalCtx), nil
+}
+
+// setResolveErrorsHeaders sets the resolve status of a ResolveAll response, along with the errors of the flags which
+// failed to resolve, the first maxResolveErrors of them
+func setResolveErrorsHeaders(header http.Header, flagErrors []eval.FlagError) error {
+	if len(flagErrors) == 0 {
+		header.Set(ResolveStatusHeader, ResolveStatusSuccess)
+		return nil
+	}
+	header.Set(ResolveStatusHeader, ResolveStatusPartial)
+	if len(flagErrors) > maxResolveErrors {
+		flagErrors = flagErrors[:maxResolveErrors]
+	}
+	errs := make([]resolveError, 0, len(flagErrors))
+	for _, flagError := range flagErrors {
+		errs = append(errs, resolveError{
+			FlagKey: flagError.FlagKey, ErrorCode: flagError.ErrorCode, Reason: model.ErrorReason,
+		})
+	}
+	encoded, err := json.Marshal(errs)
+	if err != nil {
+		return fmt.Errorf("resolve errors header encoding: %w", err)
+	}
+	header.Set(ResolveErrorsHeader, string(encoded))
+	return nil
+}
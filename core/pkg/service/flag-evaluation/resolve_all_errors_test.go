@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const resolveAllErrorsFlagConfig = `{
+  "flags": {
+    "resolvable": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "color": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red"
+    },
+    "disabled": {
+      "state": "DISABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    },
+    "entitlement": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "requireContext": ["plan"]
+    }
+  }
+}`
+
+func TestResolveAll_PartialResults(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAllErrorsFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	res, err := s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err, "flags failing to resolve shouldn't fail the whole resolution")
+	require.Len(t, res.Msg.Flags, 3)
+	require.Contains(t, res.Msg.Flags, "resolvable")
+	require.Contains(t, res.Msg.Flags, "color")
+	require.Equal(t, model.DisabledReason, res.Msg.Flags["disabled"].GetReason(),
+		"disabled flags are included rather than failing to resolve")
+	require.Equal(t, ResolveStatusPartial, res.Header().Get(ResolveStatusHeader))
+
+	var errs []resolveError
+	require.Nil(t, json.Unmarshal([]byte(res.Header().Get(ResolveErrorsHeader)), &errs))
+	require.Equal(t, []resolveError{
+		{FlagKey: "entitlement", ErrorCode: model.MissingContextErrorCode, Reason: model.ErrorReason},
+	}, errs)
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": "premium"})
+	require.Nil(t, err)
+	res, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{Context: evalCtx}))
+	require.Nil(t, err)
+	require.Len(t, res.Msg.Flags, 4)
+	require.Equal(t, ResolveStatusSuccess, res.Header().Get(ResolveStatusHeader))
+	require.Empty(t, res.Header().Get(ResolveErrorsHeader))
+}
+
+func TestResolveAll_Success(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "resolvable": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	res, err := s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err)
+	require.Len(t, res.Msg.Flags, 1)
+	require.Equal(t, ResolveStatusSuccess, res.Header().Get(ResolveStatusHeader))
+	require.Empty(t, res.Header().Get(ResolveErrorsHeader))
+}
+
+func TestSetResolveErrorsHeaders_Bounded(t *testing.T) {
+	flagErrors := make([]eval.FlagError, maxResolveErrors+10)
+	for i := range flagErrors {
+		flagErrors[i] = eval.FlagError{FlagKey: fmt.Sprintf("flag-%03d", i), ErrorCode: model.GeneralErrorCode}
+	}
+	res := connect.NewResponse(&schemaV1.ResolveAllResponse{})
+	require.Nil(t, setResolveErrorsHeaders(res.Header(), flagErrors))
+	require.Equal(t, ResolveStatusPartial, res.Header().Get(ResolveStatusHeader))
+	var errs []resolveError
+	require.Nil(t, json.Unmarshal([]byte(res.Header().Get(ResolveErrorsHeader)), &errs))
+	require.Len(t, errs, maxResolveErrors)
+}
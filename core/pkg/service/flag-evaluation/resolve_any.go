@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ResolveAnyPath resolves a flag of any type, its value is returned as a typed protobuf Any
+const ResolveAnyPath = "/resolve"
+
+type resolveAnyRequest struct {
+	FlagKey string                 `json:"flagKey"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type resolveAnyResponse struct {
+	// Value is the json encoding of the Any wrapping the value, e.g. a google.protobuf.BoolValue for boolean flags
+	Value   json.RawMessage `json:"value"`
+	Variant string          `json:"variant,omitempty"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+// anyResponse wraps the resolved value in a protobuf Any of the well known type matching the type of the flag
+type anyResponse[T constraints] struct {
+	header http.Header
+	res    resolveAnyResponse
+}
+
+func (r *anyResponse[T]) SetResult(value T, variant, reason string, metadata map[string]interface{}) error {
+	var msg proto.Message
+	switch v := any(value).(type) {
+	case bool:
+		msg = wrapperspb.Bool(v)
+	case string:
+		msg = wrapperspb.String(v)
+	case int64:
+		msg = wrapperspb.Int64(v)
+	case float64:
+		msg = wrapperspb.Double(v)
+	case map[string]any:
+		val, err := structpb.NewStruct(v)
+		if err != nil {
+			return fmt.Errorf("struct response construction: %w", err)
+		}
+		msg = val
+	}
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return fmt.Errorf("any response construction: %w", err)
+	}
+	if r.res.Value, err = protojson.Marshal(wrapped); err != nil {
+		return fmt.Errorf("any response encoding: %w", err)
+	}
+	r.res.Variant = variant
+	r.res.Reason = reason
+	return setMetadataHeader(r.header, metadata)
+}
+
+// ResolveAnyHandler resolves a flag whose type is inferred from its configuration, so clients which don't know the
+// type of a flag upfront can resolve it through a single endpoint
+func (s *FlagEvaluationService) ResolveAnyHandler() http.Handler {
+	return http.HandlerFunc(s.serveResolveAny)
+}
+
+func (s *FlagEvaluationService) serveResolveAny(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	evaluator := s.evaluator()
+	types, ok := evaluator.(eval.FlagTypes)
+	if !ok {
+		http.Error(w, "the evaluator can't infer the type of flags", http.StatusNotImplemented)
+		return
+	}
+	var req resolveAnyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	s.serveAny(w, r, evaluator, types, req.FlagKey, evalCtx)
+}
+
+// serveAny responds the resolution of a flag of any type against the evaluation context of a request, by the
+// evaluator the types of flags are inferred by
+func (s *FlagEvaluationService) serveAny(
+	w http.ResponseWriter,
+	r *http.Request,
+	evaluator eval.IEvaluator,
+	types eval.FlagTypes,
+	flagKey string,
+	evalCtx *structpb.Struct,
+) {
+	if err := s.checkFlagKey(flagKey); err != nil {
+		http.Error(w, err.Error(), resolveAnyStatus(err))
+		return
+	}
+	evalCtx = s.headerContext(evalCtx, r.Header)
+
+	flagType, ok := types.FlagType(flagKey, evalCtx)
+	if !ok {
+		// the flag is resolved as a boolean flag, so the evaluator reports why it can't be resolved, e.g. before
+		// the initial sync
+		flagType = eval.BooleanFlagType
+	}
+	// flag types are named after the resolve types resolving them
+	if err := s.checkEnabled(flagType); err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	minimal := minimalResponse(r.Header)
+	var res resolveAnyResponse
+	var err error
+	switch flagType {
+	case eval.BooleanFlagType:
+		res, err = resolveAny(r.Context(), s, evaluator.ResolveBooleanValue, flagKey, evalCtx, minimal, w.Header())
+	case eval.StringFlagType:
+		res, err = resolveAny(r.Context(), s, evaluator.ResolveStringValue, flagKey, evalCtx, minimal, w.Header())
+	case eval.IntFlagType:
+		res, err = resolveAny(r.Context(), s, evaluator.ResolveIntValue, flagKey, evalCtx, minimal, w.Header())
+	case eval.FloatFlagType:
+		res, err = resolveAny(r.Context(), s, evaluator.ResolveFloatValue, flagKey, evalCtx, minimal, w.Header())
+	default:
+		res, err = resolveAny(r.Context(), s, evaluator.ResolveObjectValue, flagKey, evalCtx, minimal, w.Header())
+	}
+	if err != nil {
+		http.Error(w, err.Error(), resolveAnyStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func resolveAny[T constraints](
+	ctx context.Context, s *FlagEvaluationService, resolver resolverFunc[T], flagKey string, evalCtx *structpb.Struct,
+	minimal bool, header http.Header,
+) (resolveAnyResponse, error) {
+	resp := &anyResponse[T]{header: header}
+	err := resolve[T](ctx, s, serviceResolver(s, resolver), flagKey, evalCtx, minimal, resp)
+	return resp.res, err
+}
+
+// resolveAnyStatus returns the http status of a failed resolution, as connect maps the code of the error
+func resolveAnyStatus(err error) int {
+	switch connect.CodeOf(err) {
+	case connect.CodeNotFound:
+		return http.StatusNotFound
+	case connect.CodeInvalidArgument:
+		return http.StatusBadRequest
+	case connect.CodeFailedPrecondition:
+		return http.StatusPreconditionFailed
+	case connect.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
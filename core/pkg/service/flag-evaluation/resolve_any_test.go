@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const resolveAnyFlagConfig = `{
+  "flags": {
+    "boolFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "free"] }, "off", "on"] }
+    },
+    "stringFlag": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red"
+    },
+    "intFlag": {
+      "state": "ENABLED",
+      "variants": { "one": 1, "two": 2 },
+      "defaultVariant": "two"
+    },
+    "floatFlag": {
+      "state": "ENABLED",
+      "variants": { "half": 0.5, "one": 1 },
+      "defaultVariant": "one"
+    },
+    "objectFlag": {
+      "state": "ENABLED",
+      "variants": { "dark": { "theme": "dark" } },
+      "defaultVariant": "dark"
+    },
+    "disabledFlag": {
+      "state": "DISABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on"
+    }
+  }
+}`
+
+func postResolveAny(t *testing.T, url string, req resolveAnyRequest) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.Nil(t, err)
+	res, err := http.Post(url, "application/json", bytes.NewReader(body))
+	require.Nil(t, err)
+	return res
+}
+
+func TestResolveAnyHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAnyFlagConfig)
+	require.Nil(t, err)
+	object, err := structpb.NewStruct(map[string]interface{}{"theme": "dark"})
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.ResolveAnyHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		req         resolveAnyRequest
+		wantValue   proto.Message
+		wantVariant string
+		wantReason  string
+	}{
+		"boolean": {
+			req:         resolveAnyRequest{FlagKey: "boolFlag", Context: map[string]interface{}{"plan": "free"}},
+			wantValue:   wrapperspb.Bool(false),
+			wantVariant: "off",
+			wantReason:  "TARGETING_MATCH",
+		},
+		"string": {
+			req:         resolveAnyRequest{FlagKey: "stringFlag"},
+			wantValue:   wrapperspb.String("#FF0000"),
+			wantVariant: "red",
+			wantReason:  "STATIC",
+		},
+		"int": {
+			req:         resolveAnyRequest{FlagKey: "intFlag"},
+			wantValue:   wrapperspb.Int64(2),
+			wantVariant: "two",
+			wantReason:  "STATIC",
+		},
+		"float": {
+			req:         resolveAnyRequest{FlagKey: "floatFlag"},
+			wantValue:   wrapperspb.Double(1),
+			wantVariant: "one",
+			wantReason:  "STATIC",
+		},
+		"object": {
+			req:         resolveAnyRequest{FlagKey: "objectFlag"},
+			wantValue:   object,
+			wantVariant: "dark",
+			wantReason:  "STATIC",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := postResolveAny(t, server.URL, tt.req)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			var resolved resolveAnyResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&resolved))
+			require.Equal(t, tt.wantVariant, resolved.Variant)
+			require.Equal(t, tt.wantReason, resolved.Reason)
+
+			value := &anypb.Any{}
+			require.Nil(t, protojson.Unmarshal(resolved.Value, value))
+			want, err := anypb.New(tt.wantValue)
+			require.Nil(t, err)
+			require.Equal(t, want.GetTypeUrl(), value.GetTypeUrl())
+			got, err := value.UnmarshalNew()
+			require.Nil(t, err)
+			require.True(t, proto.Equal(tt.wantValue, got))
+		})
+	}
+}
+
+func TestResolveAnyHandler_Errors(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, resolveAnyFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		WithDisabledResolveTypes([]string{ResolveTypeObject}))
+	server := httptest.NewServer(s.ResolveAnyHandler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		req        resolveAnyRequest
+		wantStatus int
+	}{
+		"missing flag key": {req: resolveAnyRequest{}, wantStatus: http.StatusBadRequest},
+		"unknown flag":     {req: resolveAnyRequest{FlagKey: "unknown"}, wantStatus: http.StatusNotFound},
+		"disabled flag":    {req: resolveAnyRequest{FlagKey: "disabledFlag"}, wantStatus: http.StatusInternalServerError},
+		"disabled type":    {req: resolveAnyRequest{FlagKey: "objectFlag"}, wantStatus: http.StatusNotImplemented},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := postResolveAny(t, server.URL, tt.req)
+			res.Body.Close()
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+		})
+	}
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	notSynced := httptest.NewServer(
+		NewFlagEvaluationService(logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil).ResolveAnyHandler())
+	defer notSynced.Close()
+	res = postResolveAny(t, notSynced.URL, resolveAnyRequest{FlagKey: "boolFlag"})
+	res.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
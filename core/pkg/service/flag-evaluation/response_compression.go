@@ -0,0 +1,17 @@
+package service
+
+import "github.com/bufbuild/connect-go"
+
+// DefaultCompressMinBytes is the size of the smallest response compressed when the configuration leaves it unset, as
+// compressing smaller responses costs more CPU time than the transfer it saves
+const DefaultCompressMinBytes = 1 << 10
+
+// compressionOption sends the responses smaller than the configured threshold uncompressed, even to clients accepting
+// compressed responses. Larger responses are compressed with the compression accepted by the client.
+func compressionOption(conf *ConnectServiceConfiguration) connect.HandlerOption {
+	minBytes := conf.CompressMinBytes
+	if minBytes <= 0 {
+		minBytes = DefaultCompressMinBytes
+	}
+	return connect.WithCompressMinBytes(minBytes)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+const compressionFlagConfig = `{
+  "flags": {
+    "banner": {
+      "state": "ENABLED",
+      "variants": { "long": "%s" },
+      "defaultVariant": "long"
+    },
+    "killSwitch": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    }
+  }
+}`
+
+// resolveEncoding resolves the flag with the Connect protocol, returning the encoding and size of the response
+func resolveEncoding(t *testing.T, compressMinBytes int, procedure, flagKey, acceptEncoding string) (string, int) {
+	t.Helper()
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil,
+		fmt.Sprintf(compressionFlagConfig, strings.Repeat("a", 2*DefaultCompressMinBytes)))
+	require.Nil(t, err)
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{CompressMinBytes: compressMinBytes},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/schema.v1.Service/"+procedure,
+		strings.NewReader(fmt.Sprintf(`{"flagKey":"%s"}`, flagKey)))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	res, err := server.Client().Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	return res.Header.Get("Content-Encoding"), len(body)
+}
+
+func TestCompressMinBytes(t *testing.T) {
+	encoding, size := resolveEncoding(t, 0, "ResolveString", "banner", "identity")
+	require.Empty(t, encoding)
+
+	tests := map[string]struct {
+		compressMinBytes int
+		procedure        string
+		flagKey          string
+		wantEncoding     string
+	}{
+		"large response": {
+			procedure: "ResolveString", flagKey: "banner", wantEncoding: "gzip",
+		},
+		"small response": {
+			procedure: "ResolveBoolean", flagKey: "killSwitch",
+		},
+		"response at the threshold": {
+			compressMinBytes: size, procedure: "ResolveString", flagKey: "banner", wantEncoding: "gzip",
+		},
+		"response below the threshold": {
+			compressMinBytes: size + 1, procedure: "ResolveString", flagKey: "banner",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoding, _ := resolveEncoding(t, tt.compressMinBytes, tt.procedure, tt.flagKey, "gzip")
+			require.Equal(t, tt.wantEncoding, encoding)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// minRetryDelay is the retry delay suggested to requests served while flagd isn't ready, and to the first shed
+	// requests
+	minRetryDelay = 100 * time.Millisecond
+	// maxRetryDelay bounds the retry delay suggested to shed requests
+	maxRetryDelay = 10 * time.Second
+)
+
+// retryBackoff suggests the retry delay of shed requests, doubling with each doubling of the requests shed since a
+// request was last admitted, so the suggested delay grows with the load
+type retryBackoff struct {
+	shed atomic.Int64
+}
+
+// next counts a shed request and returns its suggested retry delay
+func (b *retryBackoff) next() time.Duration {
+	delay := minRetryDelay
+	for shed := b.shed.Add(1); shed > 1 && delay < maxRetryDelay; shed /= 2 {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// reset restores the minimum delay once a request is admitted
+func (b *retryBackoff) reset() {
+	if b.shed.Load() != 0 {
+		b.shed.Store(0)
+	}
+}
+
+// withRetryInfo attaches the retry delay to the error as a google.rpc.RetryInfo detail, which clients may honor
+// rather than retrying immediately
+func withRetryInfo(err *connect.Error, delay time.Duration) *connect.Error {
+	if detail, dErr := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); dErr == nil {
+		err.AddDetail(detail)
+	}
+	return err
+}
+
+// retryAfter formats the retry delay as the value of a Retry-After header, in whole seconds
+func retryAfter(delay time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(delay.Seconds())))
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// retryDelayOf decodes the google.rpc.RetryInfo detail of the error
+func retryDelayOf(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Len(t, connectErr.Details(), 1)
+	detail, err := connectErr.Details()[0].Value()
+	require.Nil(t, err)
+	retryInfo, ok := detail.(*errdetails.RetryInfo)
+	require.True(t, ok)
+	return retryInfo.GetRetryDelay().AsDuration()
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var backoff retryBackoff
+	var delays []time.Duration
+	for i := 0; i < 8; i++ {
+		delays = append(delays, backoff.next())
+	}
+	require.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		400 * time.Millisecond, 400 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond,
+	}, delays)
+
+	for i := 0; i < 10000; i++ {
+		backoff.next()
+	}
+	require.Equal(t, maxRetryDelay, backoff.next(), "the delay is bounded")
+
+	backoff.reset()
+	require.Equal(t, minRetryDelay, backoff.next())
+}
+
+func TestRetryInfo_Shed(t *testing.T) {
+	admission := newEvaluationAdmission(1, 1)
+	release := make(chan struct{})
+	defer close(release)
+	evaluate := admissionInterceptor(admission)(blockingEvaluation(release))
+	req := connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"})
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _ = evaluate(context.Background(), req)
+		}()
+	}
+	require.Eventually(t, func() bool { return admission.Queued() == 1 }, time.Second, time.Millisecond)
+
+	_, err := evaluate(context.Background(), req)
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+	require.Equal(t, minRetryDelay, retryDelayOf(t, err))
+	_, err = evaluate(context.Background(), req)
+	require.Equal(t, 2*minRetryDelay, retryDelayOf(t, err), "the delay grows with the evaluations shed")
+}
+
+func TestRetryInfo_NotReady(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(nil, store.NewFlags())
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	_, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+	))
+	require.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+	require.Equal(t, minRetryDelay, retryDelayOf(t, err))
+
+	var connectErr *connect.Error
+	require.True(t, errors.As(errFormat(errors.New(model.DisabledReason)), &connectErr))
+	require.Empty(t, connectErr.Details(), "retrying won't help disabled flags")
+}
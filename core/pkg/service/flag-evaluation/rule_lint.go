@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// RuleLintProcedure is the unary RPC linting the targeting rules of flags, served over gRPC, gRPC-web and Connect
+// alongside the evaluation service as its messages are well-known types: the request and the response are
+// google.protobuf.Struct. It's only served with the admin API enabled.
+const RuleLintProcedure = "/flagd.lint.v1.LintService/LintRules"
+
+// LintRules reports the inefficient or suspicious expressions of the targeting rules, e.g. large in lists, constant
+// conditions and unreachable branches, so pipelines can check rules before they're pushed to a source. The request
+// lints the flags of its configuration if set, the loaded flags otherwise, only linting the flag of its flagKey if
+// set. Rules are analyzed without being evaluated, the configuration is left unchanged. The response lists the
+// warnings by flag and path: {"warnings": [{"flagKey", "rule", "severity", "path", "message"}]}.
+func (s *FlagEvaluationService) LintRules(
+	_ context.Context, req *connect.Request[structpb.Struct],
+) (*connect.Response[structpb.Struct], error) {
+	linting, ok := s.evaluator().(eval.RuleLinting)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("the evaluator can't lint rules"))
+	}
+	fields := req.Msg.GetFields()
+	flagKey := fields["flagKey"].GetStringValue()
+	warnings, err := linting.LintRules(fields["configuration"].GetStringValue(), flagKey)
+	switch {
+	case errors.Is(err, eval.ErrInvalidConfig):
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	case err != nil && err.Error() == model.FlagNotFoundErrorCode:
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("flag not found: %s", flagKey))
+	case err != nil:
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	results := make([]interface{}, 0, len(warnings))
+	for _, warning := range warnings {
+		results = append(results, map[string]interface{}{
+			"flagKey":  warning.FlagKey,
+			"rule":     warning.Rule,
+			"severity": string(warning.Severity),
+			"path":     warning.Path,
+			"message":  warning.Message,
+		})
+	}
+	res, err := structpb.NewStruct(map[string]interface{}{"warnings": results})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("encoding lint warnings: %w", err))
+	}
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const lintConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "pro"] }, "blue", { "==": [1, 1] }, "red", "blue"] }
+    },
+    "beta": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off",
+      "targeting": { "if": [{ "in": [{ "var": "email" }, ["a@faas.com"]] }, "on", "off"] }
+    }
+  }
+}`
+
+func newLintServer(t *testing.T, enableAdminAPI bool) *httptest.Server {
+	t.Helper()
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, lintConfig)
+	require.Nil(t, err)
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{EnableAdminAPI: enableAdminAPI},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		evaluationStats: &evaluationStats{},
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLintRules(t *testing.T) {
+	server := newLintServer(t, true)
+	client := connect.NewClient[structpb.Struct, structpb.Struct](server.Client(), server.URL+RuleLintProcedure)
+	lint := func(values map[string]interface{}) (map[string]interface{}, error) {
+		t.Helper()
+		req, err := structpb.NewStruct(values)
+		require.Nil(t, err)
+		res, err := client.CallUnary(context.Background(), connect.NewRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return res.Msg.AsMap(), nil
+	}
+
+	res, err := lint(map[string]interface{}{})
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"warnings": []interface{}{
+		map[string]interface{}{
+			"flagKey":  "headerColor",
+			"rule":     eval.LintRuleAlwaysTrueCondition,
+			"severity": string(eval.LintSeverityWarning),
+			"path":     "targeting.if[2]",
+			"message":  "the condition never reads the evaluation context and is always true",
+		},
+		map[string]interface{}{
+			"flagKey":  "headerColor",
+			"rule":     eval.LintRuleUnreachableBranch,
+			"severity": string(eval.LintSeverityWarning),
+			"path":     "targeting.if[4]",
+			"message":  "the branches from here on are unreachable, the condition at targeting.if[2] is always true",
+		},
+	}}, res)
+
+	res, err = lint(map[string]interface{}{"flagKey": "beta"})
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"warnings": []interface{}{}}, res)
+
+	candidate := `{"flags": {"beta": {"state": "ENABLED", "variants": {"on": true, "off": false}, ` +
+		`"defaultVariant": "off", "targeting": {"if": [false, "on", "off"]}}}}`
+	res, err = lint(map[string]interface{}{"configuration": candidate, "flagKey": "beta"})
+	require.Nil(t, err)
+	warnings := res["warnings"].([]interface{})
+	require.Len(t, warnings, 1)
+	require.Equal(t, eval.LintRuleAlwaysFalseCondition, warnings[0].(map[string]interface{})["rule"])
+
+	_, err = lint(map[string]interface{}{"flagKey": "missing"})
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+	_, err = lint(map[string]interface{}{"configuration": `{"flags": {"beta": {"state": "ENABLED"}}}`})
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestLintRules_AdminAPIDisabled(t *testing.T) {
+	server := newLintServer(t, false)
+	client := connect.NewClient[structpb.Struct, structpb.Struct](server.Client(), server.URL+RuleLintProcedure)
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(&structpb.Struct{}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err),
+		"rules shouldn't be linted without the admin API")
+}
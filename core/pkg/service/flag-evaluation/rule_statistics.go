@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/otel"
+)
+
+// RuleStatisticsPath returns the matched branches of the targeting rule of each flag, e.g.
+// /admin/rule-statistics?flagKey=my-flag, it's only served with the admin API and rule statistics recorded
+const RuleStatisticsPath = "/admin/rule-statistics"
+
+type flagRuleStatistics struct {
+	eval.FlagRuleStatistics
+	AverageConditions float64 `json:"averageConditions"`
+}
+
+type ruleStatisticsResponse struct {
+	Flags map[string]flagRuleStatistics `json:"flags"`
+}
+
+// ruleStatistics returns the rule statistics of the evaluator, false if it doesn't record them
+func ruleStatistics(evaluator eval.IEvaluator) (map[string]eval.FlagRuleStatistics, bool) {
+	recorder, ok := evaluator.(eval.RuleStatistics)
+	if !ok {
+		return nil, false
+	}
+	stats := recorder.RuleStatistics()
+	return stats, stats != nil
+}
+
+// metricsRuleStatistics returns the rule statistics of the evaluator as observed by the metrics
+func metricsRuleStatistics(evaluator eval.IEvaluator) func() map[string]otel.RuleStatistics {
+	return func() map[string]otel.RuleStatistics {
+		stats, _ := ruleStatistics(evaluator)
+		observed := make(map[string]otel.RuleStatistics, len(stats))
+		for flagKey, flag := range stats {
+			observed[flagKey] = otel.RuleStatistics{
+				ConditionsEvaluated: flag.ConditionsEvaluated,
+				Branches:            flag.Branches,
+			}
+		}
+		return observed
+	}
+}
+
+// RuleStatisticsHandler returns the number of evaluations of the targeting rule of each flag by matched branch and
+// the average number of conditions evaluated, so flag authors can order the conditions of their rules
+func (s *FlagEvaluationService) RuleStatisticsHandler() http.Handler {
+	return http.HandlerFunc(s.serveRuleStatistics)
+}
+
+func (s *FlagEvaluationService) serveRuleStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	stats, ok := ruleStatistics(s.evaluator())
+	if !ok {
+		http.Error(w, "the rule statistics aren't recorded", http.StatusNotFound)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	res := ruleStatisticsResponse{Flags: map[string]flagRuleStatistics{}}
+	for key, flag := range stats {
+		if flagKey != "" && key != flagKey {
+			continue
+		}
+		res.Flags[key] = flagRuleStatistics{FlagRuleStatistics: flag, AverageConditions: flag.AverageConditions()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const ruleStatisticsFlagConfig = `{
+  "flags": {
+    "headerColor": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000", "blue": "#0000FF" },
+      "defaultVariant": "red",
+      "targeting": { "if": [{ "==": [{ "var": "plan" }, "pro"] }, "blue", "red"] }
+    },
+    "static": {
+      "state": "ENABLED",
+      "variants": { "red": "#FF0000" },
+      "defaultVariant": "red"
+    }
+  }
+}`
+
+func TestRuleStatisticsHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig, eval.WithRuleStatistics(true))
+	require.Nil(t, err)
+	for _, plan := range []string{"pro", "free", "free", "free"} {
+		evalCtx, err := structpb.NewStruct(map[string]interface{}{"plan": plan})
+		require.Nil(t, err)
+		_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "", "headerColor", evalCtx)
+		require.Nil(t, err)
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.RuleStatisticsHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?flagKey=headerColor")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var stats ruleStatisticsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&stats))
+	require.Equal(t, ruleStatisticsResponse{Flags: map[string]flagRuleStatistics{
+		"headerColor": {
+			FlagRuleStatistics: eval.FlagRuleStatistics{
+				Evaluations:         4,
+				Branches:            map[string]int64{"0": 1, "else": 3},
+				ConditionsEvaluated: 4,
+			},
+			AverageConditions: 1,
+		},
+	}}, stats)
+
+	res, err = http.Post(server.URL, "application/json", nil)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	disabled, err := eval.NewJSONEvaluatorFromConfig(nil, ruleStatisticsFlagConfig)
+	require.Nil(t, err)
+	rec := httptest.NewRecorder()
+	NewFlagEvaluationService(logger.NewLogger(nil, false), disabled, nil).RuleStatisticsHandler().
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RuleStatisticsPath, nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// SandboxPath evaluates an inline flag definition, it's only served with the admin API enabled
+	SandboxPath = "/admin/sandbox"
+	// MaxSandboxRequestBytes bounds the size of sandbox requests
+	MaxSandboxRequestBytes = 64 << 10
+
+	// sandboxFlagKey is the key of inline definitions sent without a flag key
+	sandboxFlagKey = "sandbox"
+)
+
+type sandboxRequest struct {
+	FlagKey string                 `json:"flagKey"`
+	Flag    json.RawMessage        `json:"flag"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type sandboxResponse struct {
+	FlagKey   string                 `json:"flagKey"`
+	Value     interface{}            `json:"value"`
+	Variant   string                 `json:"variant,omitempty"`
+	Reason    string                 `json:"reason"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ErrorCode string                 `json:"errorCode,omitempty"`
+	// Trace lists the steps of the evaluation, e.g. the result of the targeting rule
+	Trace []string `json:"trace"`
+}
+
+// SandboxHandler evaluates an inline flag definition against an evaluation context, so flag authors can try their
+// targeting rules before committing them. The definition is validated as the flags of a configuration are, but it's
+// neither stored nor visible to other requests.
+func (s *FlagEvaluationService) SandboxHandler() http.Handler {
+	return http.HandlerFunc(s.serveSandbox)
+}
+
+func (s *FlagEvaluationService) serveSandbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sandbox, ok := s.evaluator().(eval.Sandbox)
+	if !ok {
+		http.Error(w, "the evaluator can't evaluate inline flag definitions", http.StatusNotImplemented)
+		return
+	}
+	var req sandboxRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Flag) == 0 {
+		http.Error(w, "flag is required", http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		req.FlagKey = sandboxFlagKey
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	result, err := sandbox.EvaluateSandbox(reqID, req.FlagKey, req.Flag, evalCtx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid flag definition: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sandboxResponse{
+		FlagKey:   req.FlagKey,
+		Value:     result.Value,
+		Variant:   result.Variant,
+		Reason:    result.Reason,
+		Metadata:  result.Metadata,
+		ErrorCode: result.ErrorCode,
+		Trace:     result.Trace,
+	}); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding sandbox response: %v", err))
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const sandboxTargetingFlag = `{
+  "state": "ENABLED",
+  "variants": { "red": "#FF0000", "blue": "#0000FF" },
+  "defaultVariant": "red",
+  "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "blue", null] }
+}`
+
+func TestSandboxHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.SandboxHandler())
+	defer server.Close()
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		method       string
+		body         string
+		wantCode     int
+		wantResponse sandboxResponse
+	}{
+		"targeting match": {
+			body:     `{"flagKey": "headerColor", "flag": ` + sandboxTargetingFlag + `, "context": {"email": "user@faas.com"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "headerColor",
+				Value:   "#0000FF",
+				Variant: "blue",
+				Reason:  model.TargetingMatchReason,
+				Trace: []string{
+					"validated the definition of flag: headerColor",
+					`evaluating targeting: {"if":[{"==":[{"var":"email"},"user@faas.com"]},"blue",null]}`,
+					`targeting resolved: "blue"`,
+					"variant: blue matched",
+				},
+			},
+		},
+		"targeting fallback to the default variant": {
+			body:     `{"flag": ` + sandboxTargetingFlag + `, "context": {"email": "other@faas.com"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: sandboxFlagKey,
+				Value:   "#FF0000",
+				Variant: "red",
+				Reason:  model.DefaultReason,
+				Trace: []string{
+					"validated the definition of flag: sandbox",
+					`evaluating targeting: {"if":[{"==":[{"var":"email"},"user@faas.com"]},"blue",null]}`,
+					"targeting resolved: null",
+					"'null' isn't a variant of the flag, resolving the default variant: red",
+				},
+			},
+		},
+		"static": {
+			body:     `{"flagKey": "myFlag", "flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "myFlag",
+				Value:   true,
+				Variant: "on",
+				Reason:  model.StaticReason,
+				Trace: []string{
+					"validated the definition of flag: myFlag",
+					"flag has no targeting, resolving the default variant: on",
+				},
+			},
+		},
+		"disabled": {
+			body:     `{"flagKey": "myFlag", "flag": {"state": "DISABLED", "variants": {"on": true}, "defaultVariant": "on"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey:   "myFlag",
+				Reason:    model.ErrorReason,
+				ErrorCode: model.FlagDisabledErrorCode,
+				Trace:     []string{"validated the definition of flag: myFlag", "flag is disabled"},
+			},
+		},
+		"invalid definition": {
+			body:     `{"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "off"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid targeting": {
+			body: `{"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on", ` +
+				`"targeting": "on"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"missing flag": {
+			body:     `{"flagKey": "myFlag"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid json": {
+			body:     `{"flag": `,
+			wantCode: http.StatusBadRequest,
+		},
+		"oversized request": {
+			body:     `{"flag": "` + strings.Repeat("a", MaxSandboxRequestBytes) + `"}`,
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		"invalid method": {
+			method:   http.MethodGet,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.method == "" {
+				tt.method = http.MethodPost
+			}
+			req, err := http.NewRequest(tt.method, server.URL, bytes.NewBufferString(tt.body))
+			require.Nil(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got sandboxResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Equal(t, tt.wantResponse, got)
+		})
+	}
+
+	after, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Equal(t, state, after, "sandbox evaluations mustn't change the stored flags")
+	_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "", "headerColor", nil)
+	require.NotNil(t, err, "sandbox flags mustn't be resolvable")
+}
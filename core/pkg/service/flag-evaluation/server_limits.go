@@ -0,0 +1,61 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Limits of the requests served by the flag evaluation server, used when the configuration leaves them unset
+const (
+	DefaultReadTimeout     = 10 * time.Second
+	DefaultIdleTimeout     = 2 * time.Minute
+	DefaultMaxHeaderBytes  = 64 << 10
+	DefaultMaxRequestBytes = 4 << 20
+
+	readHeaderTimeout = time.Second
+)
+
+// configureServer sets up the server serving the handler, bounding the time taken reading requests, the size of
+// their headers and bodies and the lifetime of idle connections, against slow and oversized requests
+func (s *ConnectService) configureServer(h http.Handler) {
+	conf := s.ConnectServiceConfiguration
+	readTimeout := durationOrDefault(conf.ReadTimeout, DefaultReadTimeout)
+	headerTimeout := readHeaderTimeout
+	if readTimeout < headerTimeout {
+		headerTimeout = readTimeout
+	}
+	idleTimeout := durationOrDefault(conf.IdleTimeout, DefaultIdleTimeout)
+	maxRequestBytes := conf.MaxRequestBytes
+	if maxRequestBytes <= 0 {
+		maxRequestBytes = DefaultMaxRequestBytes
+	}
+	maxHeaderBytes := conf.MaxHeaderBytes
+	if maxHeaderBytes <= 0 {
+		maxHeaderBytes = DefaultMaxHeaderBytes
+	}
+
+	// oversized gRPC and Connect requests fail with a resource exhausted error, http requests with a bad request
+	h = s.newCORS().Handler(http.MaxBytesHandler(h, maxRequestBytes))
+	if conf.ServerCertPath == "" || conf.ServerKeyPath == "" {
+		// h2c connections are served by the http2 server, which doesn't inherit the idle timeout
+		h = h2c.NewHandler(h, &http2.Server{IdleTimeout: idleTimeout})
+	}
+	s.server = http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: headerTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+func durationOrDefault(d time.Duration, defaultDuration time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultDuration
+	}
+	return d
+}
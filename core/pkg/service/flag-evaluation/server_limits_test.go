@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// serveLimited serves the flag evaluation service with the configured limits, returning its address
+func serveLimited(t *testing.T, conf *ConnectServiceConfiguration) string {
+	t.Helper()
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	svc := ConnectService{
+		ConnectServiceConfiguration: conf,
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+	}
+	svc.configureServer(svc.serviceHandler())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		_ = svc.server.Serve(lis)
+	}()
+	t.Cleanup(func() { _ = svc.server.Close() })
+	return lis.Addr().String()
+}
+
+func TestConfigureServer_Defaults(t *testing.T) {
+	svc := ConnectService{ConnectServiceConfiguration: &ConnectServiceConfiguration{}}
+	svc.configureServer(http.NotFoundHandler())
+	require.Equal(t, DefaultReadTimeout, svc.server.ReadTimeout)
+	require.Equal(t, time.Duration(0), svc.server.WriteTimeout)
+	require.Equal(t, DefaultIdleTimeout, svc.server.IdleTimeout)
+	require.Equal(t, DefaultMaxHeaderBytes, svc.server.MaxHeaderBytes)
+	require.Equal(t, readHeaderTimeout, svc.server.ReadHeaderTimeout)
+}
+
+func TestConfigureServer_OversizedRequests(t *testing.T) {
+	addr := serveLimited(t, &ConnectServiceConfiguration{MaxRequestBytes: 1 << 10, MaxHeaderBytes: 1 << 10})
+	client := schemaConnectV1.NewServiceClient(http.DefaultClient, "http://"+addr)
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+	res, err := client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: evalCtx},
+	))
+	require.Nil(t, err, "requests within the limits are served")
+	require.False(t, res.Msg.Value)
+
+	evalCtx, err = structpb.NewStruct(map[string]interface{}{"padding": strings.Repeat("x", 2<<10)})
+	require.Nil(t, err)
+	_, err = client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: evalCtx},
+	))
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err), "oversized body")
+
+	body := fmt.Sprintf(`{"flagKey":"myBoolFlag","context":{"padding":"%s"}}`, strings.Repeat("x", 2<<10))
+	httpRes, err := http.Post("http://"+addr+ResolveAnyPath, "application/json", strings.NewReader(body))
+	require.Nil(t, err)
+	httpRes.Body.Close()
+	require.Equal(t, http.StatusBadRequest, httpRes.StatusCode, "oversized http body")
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+InfoPath, nil)
+	require.Nil(t, err)
+	req.Header.Set("X-Padding", strings.Repeat("x", 8<<10))
+	httpRes, err = http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	httpRes.Body.Close()
+	require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, httpRes.StatusCode, "oversized headers")
+}
+
+func TestConfigureServer_SlowRequests(t *testing.T) {
+	addr := serveLimited(t, &ConnectServiceConfiguration{ReadTimeout: 200 * time.Millisecond})
+
+	tests := map[string]string{
+		"slow headers": "POST /schema.v1.Service/ResolveBoolean HTTP/1.1\r\nHost: flagd\r\n",
+		"slow body": "POST /schema.v1.Service/ResolveBoolean HTTP/1.1\r\nHost: flagd\r\n" +
+			"Content-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"flagKey\":",
+	}
+	for name, partial := range tests {
+		t.Run(name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", addr)
+			require.Nil(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte(partial))
+			require.Nil(t, err)
+
+			// the server closes the connection rather than waiting for the rest of the request
+			require.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			started := time.Now()
+			_, err = io.ReadAll(conn)
+			require.Nil(t, err)
+			require.Less(t, time.Since(started), 5*time.Second)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import "fmt"
+
+// tlsEnabled reports whether the server is served over TLS, which requires both the certificate and key paths. A
+// single path fails rather than falling back to serving in plaintext.
+func (c *ConnectServiceConfiguration) tlsEnabled() (bool, error) {
+	switch {
+	case c.ServerCertPath != "" && c.ServerKeyPath != "":
+		return true, nil
+	case c.ServerCertPath != "":
+		return false, fmt.Errorf("server certificate %s is set without a key, TLS requires both", c.ServerCertPath)
+	case c.ServerKeyPath != "":
+		return false, fmt.Errorf("server key %s is set without a certificate, TLS requires both", c.ServerKeyPath)
+	default:
+		return false, nil
+	}
+}
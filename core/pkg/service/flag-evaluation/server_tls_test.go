@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// writeCertificate writes a self-signed certificate of localhost and its key to the directory
+func writeCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	certPath, keyPath := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	require.Nil(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.Nil(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, certPath, keyPath
+}
+
+func TestTLSEnabled(t *testing.T) {
+	tests := map[string]struct {
+		conf    ConnectServiceConfiguration
+		want    bool
+		wantErr string
+	}{
+		"both set": {
+			conf: ConnectServiceConfiguration{ServerCertPath: "server.crt", ServerKeyPath: "server.key"},
+			want: true,
+		},
+		"certificate only": {
+			conf:    ConnectServiceConfiguration{ServerCertPath: "server.crt"},
+			wantErr: "server certificate server.crt is set without a key, TLS requires both",
+		},
+		"key only": {
+			conf:    ConnectServiceConfiguration{ServerKeyPath: "server.key"},
+			wantErr: "server key server.key is set without a certificate, TLS requires both",
+		},
+		"neither set": {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.conf.tlsEnabled()
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConnectService_PartialTLS(t *testing.T) {
+	for name, conf := range map[string]ConnectServiceConfiguration{
+		"certificate only": {ServerCertPath: "server.crt"},
+		"key only":         {ServerKeyPath: "server.key"},
+	} {
+		conf := conf
+		t.Run(name, func(t *testing.T) {
+			svc := ConnectService{ConnectServiceConfiguration: &conf, Logger: logger.NewLogger(nil, false)}
+			_, wantErr := conf.tlsEnabled()
+			require.NotNil(t, wantErr)
+			// the service fails before listening rather than serving in plaintext
+			require.Equal(t, wantErr, svc.Serve(context.Background(), nil, iservice.Configuration{}))
+		})
+	}
+}
+
+func TestConnectService_TLS(t *testing.T) {
+	cert, certPath, keyPath := writeCertificate(t, t.TempDir())
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		certPath string
+		keyPath  string
+		wantLog  string
+	}{
+		"both set":    {certPath: certPath, keyPath: keyPath, wantLog: "over TLS, certificate: " + certPath},
+		"neither set": {wantLog: "without TLS, no server certificate is set"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			svc := ConnectService{
+				ConnectServiceConfiguration: &ConnectServiceConfiguration{
+					ServerCertPath: tt.certPath,
+					ServerKeyPath:  tt.keyPath,
+				},
+				Eval:    evaluator,
+				Logger:  logger.NewLogger(zap.New(core), false),
+				Metrics: otel.NewOTelRecorder(metric.NewManualReader(), name),
+				eventingConfiguration: &eventingConfiguration{
+					subs: make(map[interface{}]chan iservice.Notification),
+					mu:   &sync.RWMutex{},
+				},
+			}
+			svc.configureServer(svc.serviceHandler())
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.Nil(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			served := make(chan error, 1)
+			go func() {
+				served <- svc.serveListeners(ctx, []net.Listener{lis})
+			}()
+
+			transport := &http.Transport{}
+			url := "http://" + lis.Addr().String()
+			if tt.certPath != "" {
+				roots := x509.NewCertPool()
+				roots.AddCert(cert)
+				transport.TLSClientConfig = &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12}
+				url = "https://" + lis.Addr().String()
+			}
+			res, err := schemaConnectV1.NewServiceClient(&http.Client{Transport: transport}, url).ResolveBoolean(
+				context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"}))
+			require.Nil(t, err)
+			require.True(t, res.Msg.Value)
+			require.Equal(t, 1, logs.FilterMessage(
+				fmt.Sprintf("Flag Evaluation listening at %s %s", lis.Addr(), tt.wantLog)).Len(), logs.All())
+
+			cancel()
+			require.Nil(t, <-served)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SourceStatusesProcedure is the unary RPC returning the status of the flag sources, served over gRPC, gRPC-web and
+// Connect alongside the evaluation service as its messages are well-known types: the request is a
+// google.protobuf.Empty and the response a google.protobuf.Struct listing the statuses under "sources"
+const SourceStatusesProcedure = "/flagd.sync.v1.SyncService/SourceStatuses"
+
+// sourceStatuses serves the status of the flag sources at SourceStatusesProcedure
+type sourceStatuses struct {
+	statuses *isync.SourceStatuses
+}
+
+// SourceStatuses returns the status of every flag source, the timestamps being RFC 3339 strings left out until the
+// source syncs or fails to
+func (s sourceStatuses) SourceStatuses(
+	_ context.Context, _ *connect.Request[emptypb.Empty],
+) (*connect.Response[structpb.Struct], error) {
+	res, err := structpb.NewStruct(map[string]interface{}{"sources": sourceStatusValues(s.statuses)})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("source statuses construction: "+err.Error()))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// sourceStatusValues returns the status of the flag sources as values structpb converts, none for nil statuses
+func sourceStatusValues(statuses *isync.SourceStatuses) []interface{} {
+	sources := []interface{}{}
+	if statuses == nil {
+		return sources
+	}
+	for _, status := range statuses.Snapshot() {
+		source := map[string]interface{}{
+			"source":  status.Source,
+			"updates": status.Updates,
+			"errors":  status.Errors,
+		}
+		if !status.LastSync.IsZero() {
+			source["lastSync"] = status.LastSync.UTC().Format(time.RFC3339Nano)
+		}
+		if status.LastError != "" {
+			source["lastError"] = status.LastError
+			source["lastErrorTime"] = status.LastErrorTime.UTC().Format(time.RFC3339Nano)
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// sourceStatusesService returns a service serving the statuses, along with the client of their procedure
+func sourceStatusesService(
+	t *testing.T, statuses *isync.SourceStatuses,
+) (*connect.Client[emptypb.Empty, structpb.Struct], func()) {
+	t.Helper()
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), t.Name()),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		sourceStatuses: statuses,
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	client := connect.NewClient[emptypb.Empty, structpb.Struct](server.Client(), server.URL+SourceStatusesProcedure)
+	return client, server.Close
+}
+
+func TestSourceStatuses(t *testing.T) {
+	statuses := isync.NewSourceStatuses("file:a.json", "file:b.json")
+	statuses.RecordSync("file:a.json", time.Unix(100, 0))
+	statuses.RecordError("file:b.json", errors.New("invalid configuration"), time.Unix(200, 0))
+	client, stop := sourceStatusesService(t, statuses)
+	defer stop()
+
+	res, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{
+		"sources": []interface{}{
+			map[string]interface{}{
+				"source":   "file:a.json",
+				"lastSync": "1970-01-01T00:01:40Z",
+				"updates":  float64(1),
+				"errors":   float64(0),
+			},
+			map[string]interface{}{
+				"source":        "file:b.json",
+				"lastError":     "invalid configuration",
+				"lastErrorTime": "1970-01-01T00:03:20Z",
+				"updates":       float64(0),
+				"errors":        float64(1),
+			},
+		},
+	}, res.Msg.AsMap())
+}
+
+func TestSourceStatuses_Disabled(t *testing.T) {
+	client, stop := sourceStatusesService(t, nil)
+	defer stop()
+
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err),
+		"the statuses shouldn't be served without sources")
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// SSEPath streams flag change events as Server-Sent Events
+	SSEPath = "/events"
+
+	flagValuesEvent         = "flag_values"
+	keepAliveInterval       = 20 * time.Second
+	notificationHistorySize = 100
+)
+
+type sequencedNotification struct {
+	id           uint64
+	notification service.Notification
+}
+
+// notificationHistory numbers notifications sequentially, retaining the latest notificationHistorySize of them
+type notificationHistory struct {
+	mu    sync.RWMutex
+	seq   uint64
+	items []sequencedNotification
+
+	instanceOnce sync.Once
+	instanceID   string
+}
+
+func (h *notificationHistory) add(n service.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	h.items = append(h.items, sequencedNotification{id: h.seq, notification: n})
+	if len(h.items) > notificationHistorySize {
+		h.items = h.items[len(h.items)-notificationHistorySize:]
+	}
+}
+
+func (h *notificationHistory) latest() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.seq
+}
+
+// since returns the notifications following lastID, missed is set if some of them are no longer retained
+func (h *notificationHistory) since(lastID uint64) (
+	notifications []sequencedNotification, latest uint64, missed bool,
+) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if lastID > h.seq {
+		// the id was issued before a restart
+		return nil, h.seq, true
+	}
+	for _, item := range h.items {
+		if item.id > lastID {
+			notifications = append(notifications, item)
+		}
+	}
+	if len(notifications) > 0 && notifications[0].id > lastID+1 {
+		missed = true
+	}
+	return notifications, h.seq, missed
+}
+
+func (e *eventingConfiguration) notify(n service.Notification) {
+	e.history.add(n)
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, send := range e.subs {
+		send <- n
+	}
+}
+
+// errSubscriberLimit is returned when subscribing beyond the configured maximum of concurrent subscribers
+var errSubscriberLimit = errors.New("maximum number of stream subscribers reached")
+
+func (e *eventingConfiguration) subscribe(key interface{}) (chan service.Notification, error) {
+	notifications := make(chan service.Notification, 1)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.maxSubscribers > 0 && len(e.subs) >= e.maxSubscribers {
+		return nil, errSubscriberLimit
+	}
+	e.backoff.reset()
+	e.subs[key] = notifications
+	return notifications, nil
+}
+
+// subscriberCount returns the number of active subscribers, across event streams and SSE clients
+func (e *eventingConfiguration) subscriberCount() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return int64(len(e.subs))
+}
+
+// unsubscribe removes a subscription, draining its channel so a pending notify can't block the removal
+func (e *eventingConfiguration) unsubscribe(key interface{}, notifications chan service.Notification) {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-notifications:
+			case <-done:
+				return
+			}
+		}
+	}()
+	e.mu.Lock()
+	delete(e.subs, key)
+	e.mu.Unlock()
+	close(done)
+}
+
+// SSEHandler streams the flag change notifications of the event stream as Server-Sent Events. Clients may
+// subscribe to the values of flags through the flags query parameter, evaluated with the json encoded context
+// query parameter, which are re-evaluated after every change and sent when their value, variant or reason changed
+// for the context. Reconnecting clients resume from the Last-Event-ID.
+func (s *FlagEvaluationService) SSEHandler() http.Handler {
+	return http.HandlerFunc(s.serveSSE)
+}
+
+func (s *FlagEvaluationService) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	keys, evalCtx, err := parseSSERequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(keys) > 0 {
+		if err := s.checkEnabled(ResolveTypeAll); err != nil {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+	}
+
+	lastID, resumed, err := lastEventID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := s.eventingConfiguration.subscribe(r)
+	if err != nil {
+		w.Header().Set("Retry-After", retryAfter(s.eventingConfiguration.backoff.next()))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer s.eventingConfiguration.unsubscribe(r, notifications)
+	if !resumed {
+		lastID = s.eventingConfiguration.history.latest()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent(w, "", string(service.ProviderReady), nil)
+	lastID = s.writeNotificationsSince(w, lastID)
+	sent := s.writeFlagValues(r.Context(), w, keys, evalCtx, nil)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-time.After(keepAliveInterval):
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-notifications:
+			lastID = s.writeNotificationsSince(w, lastID)
+			sent = s.writeFlagValues(r.Context(), w, keys, evalCtx, sent)
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeNotificationsSince writes the notifications following lastID, returning the id of the latest notification.
+// If notifications were missed a configuration change without data is written, signalling clients to refresh.
+func (s *FlagEvaluationService) writeNotificationsSince(w http.ResponseWriter, lastID uint64) uint64 {
+	notifications, latest, missed := s.eventingConfiguration.history.since(lastID)
+	if missed {
+		writeEvent(w, strconv.FormatUint(latest, 10), string(service.ConfigurationChange), map[string]interface{}{})
+		return latest
+	}
+	for _, n := range notifications {
+		writeEvent(w, strconv.FormatUint(n.id, 10), string(n.notification.Type), n.notification.Data)
+	}
+	return latest
+}
+
+// flagValues are the values of the subscribed flags last sent to a client, by flag key
+type flagValues map[string]map[string]interface{}
+
+// writeFlagValues writes the values of the subscribed flags which changed since they were sent, returning the values
+// sent to the client. Flags which can no longer be resolved are written as null, and no event is written if no
+// value changed. Every subscribed flag is written when nothing was sent yet.
+func (s *FlagEvaluationService) writeFlagValues(
+	ctx context.Context, w http.ResponseWriter, keys map[string]struct{}, evalCtx *structpb.Struct, sent flagValues,
+) flagValues {
+	if len(keys) == 0 {
+		return sent
+	}
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	current := flagValues{}
+	for _, value := range s.evaluator().ResolveAllValues(ctx, reqID, evalCtx) {
+		if _, ok := keys[value.FlagKey]; ok {
+			current[value.FlagKey] = flagValue(value)
+		}
+	}
+	changed := map[string]interface{}{}
+	for key, value := range current {
+		if previous, ok := sent[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changed[key] = value
+		}
+	}
+	for key := range sent {
+		if _, ok := current[key]; !ok {
+			changed[key] = nil
+		}
+	}
+	if sent == nil || len(changed) > 0 {
+		writeEvent(w, "", flagValuesEvent, changed)
+	}
+	return current
+}
+
+func writeEvent(w http.ResponseWriter, id string, event string, data map[string]interface{}) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\n", event)
+	if data != nil {
+		if encoded, err := json.Marshal(data); err == nil {
+			fmt.Fprintf(w, "data: %s\n", encoded)
+		}
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func parseSSERequest(r *http.Request) (map[string]struct{}, *structpb.Struct, error) {
+	keys := map[string]struct{}{}
+	for _, param := range r.URL.Query()["flags"] {
+		for _, key := range strings.Split(param, ",") {
+			if key != "" {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+
+	evalCtx := evaluationContext(nil)
+	if raw := r.URL.Query().Get("context"); raw != "" {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return nil, nil, fmt.Errorf("context isn't a json object: %w", err)
+		}
+		var err error
+		if evalCtx, err = structpb.NewStruct(fields); err != nil {
+			return nil, nil, fmt.Errorf("context: %w", err)
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		return nil, nil, err
+	}
+	return keys, evalCtx, nil
+}
+
+// lastEventID returns the id from which a reconnecting client resumes, sent by browsers as the Last-Event-ID header
+func lastEventID(r *http.Request) (uint64, bool, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid Last-Event-ID: %s", raw)
+	}
+	return id, true, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+func readSSEEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func openSSEStream(t *testing.T, ctx context.Context, url string, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.Nil(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	return bufio.NewReader(res.Body)
+}
+
+func TestSSEHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, `{
+  "flags": {
+    "bool": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "on", null] }
+    },
+    "other": { "state": "ENABLED", "variants": { "on": true }, "defaultVariant": "on" }
+  }
+}`)
+	require.Nil(t, err)
+	eventing := &eventingConfiguration{
+		subs: make(map[interface{}]chan service.Notification),
+		mu:   &sync.RWMutex{},
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, withEventingConfiguration(eventing))
+	server := httptest.NewServer(s.SSEHandler())
+	defer server.Close()
+	url := server.URL + `?flags=bool&context={"email":"x@faas.com"}`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	streamCtx, closeStream := context.WithCancel(ctx)
+	stream := openSSEStream(t, streamCtx, url, "")
+
+	require.Equal(t, sseEvent{event: string(service.ProviderReady)}, readSSEEvent(t, stream))
+	require.Equal(t, sseEvent{
+		event: flagValuesEvent,
+		data:  `{"bool":{"reason":"TARGETING_MATCH","value":true,"variant":"on"}}`,
+	}, readSSEEvent(t, stream))
+
+	eventing.notify(service.Notification{
+		Type: service.ConfigurationChange,
+		Data: map[string]interface{}{"flags": map[string]interface{}{"bool": map[string]interface{}{"type": "update"}}},
+	})
+	require.Equal(t, sseEvent{
+		id:    "1",
+		event: string(service.ConfigurationChange),
+		data:  `{"flags":{"bool":{"type":"update"}}}`,
+	}, readSSEEvent(t, stream))
+
+	// changes while disconnected are replayed on reconnection
+	closeStream()
+	eventing.notify(service.Notification{Type: service.ConfigurationChange, Data: map[string]interface{}{"n": 2}})
+	eventing.notify(service.Notification{Type: service.ConfigurationChange, Data: map[string]interface{}{"n": 3}})
+
+	stream = openSSEStream(t, ctx, url, "1")
+	require.Equal(t, string(service.ProviderReady), readSSEEvent(t, stream).event)
+	require.Equal(t, sseEvent{id: "2", event: string(service.ConfigurationChange), data: `{"n":2}`}, readSSEEvent(t, stream))
+	require.Equal(t, sseEvent{id: "3", event: string(service.ConfigurationChange), data: `{"n":3}`}, readSSEEvent(t, stream))
+	require.Equal(t, flagValuesEvent, readSSEEvent(t, stream).event)
+
+	// ids which are no longer retained signal clients to refresh
+	stream = openSSEStream(t, ctx, server.URL, "42")
+	require.Equal(t, string(service.ProviderReady), readSSEEvent(t, stream).event)
+	require.Equal(t, sseEvent{id: "3", event: string(service.ConfigurationChange), data: `{}`}, readSSEEvent(t, stream))
+}
+
+func TestSSEHandler_ChangedFlagValues(t *testing.T) {
+	evaluator := eval.NewJSONEvaluator(nil, nil)
+	eventing := &eventingConfiguration{
+		subs: make(map[interface{}]chan service.Notification),
+		mu:   &sync.RWMutex{},
+	}
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil, withEventingConfiguration(eventing))
+	server := httptest.NewServer(s.SSEHandler())
+	defer server.Close()
+
+	// setConfig applies a configuration the way the runtime does, notifying the changes
+	setConfig := func(boolDefault string, other string) {
+		config := fmt.Sprintf(`{
+  "flags": {
+    "bool": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "%s",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "x@faas.com"] }, "on", null] }
+    }%s
+  }
+}`, boolDefault, other)
+		notifications, _, err := evaluator.SetState(isync.DataSync{FlagData: config, Source: "flags.json"})
+		require.Nil(t, err)
+		eventing.notify(service.Notification{
+			Type: service.ConfigurationChange,
+			Data: map[string]interface{}{"flags": notifications},
+		})
+	}
+	otherFlag := func(value string) string {
+		return fmt.Sprintf(`,
+    "other": { "state": "ENABLED", "variants": { "v": "%s" }, "defaultVariant": "v" }`, value)
+	}
+	setConfig("off", otherFlag("a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	targeted := openSSEStream(t, ctx, server.URL+`?flags=bool,other&context={"email":"x@faas.com"}`, "")
+	untargeted := openSSEStream(t, ctx, server.URL+`?flags=bool&context={"email":"y@faas.com"}`, "")
+	for _, stream := range []*bufio.Reader{targeted, untargeted} {
+		require.Equal(t, string(service.ProviderReady), readSSEEvent(t, stream).event)
+	}
+	require.Equal(t, sseEvent{
+		event: flagValuesEvent,
+		data: `{"bool":{"reason":"TARGETING_MATCH","value":true,"variant":"on"},` +
+			`"other":{"reason":"STATIC","value":"a","variant":"v"}}`,
+	}, readSSEEvent(t, targeted), "every subscribed flag on connection")
+	require.Equal(t, sseEvent{
+		event: flagValuesEvent,
+		data:  `{"bool":{"reason":"DEFAULT","value":false,"variant":"off"}}`,
+	}, readSSEEvent(t, untargeted))
+
+	// the default variant doesn't change the targeted variant
+	setConfig("on", otherFlag("a"))
+	require.Equal(t, string(service.ConfigurationChange), readSSEEvent(t, targeted).event)
+	require.Equal(t, string(service.ConfigurationChange), readSSEEvent(t, untargeted).event)
+	require.Equal(t, sseEvent{
+		event: flagValuesEvent,
+		data:  `{"bool":{"reason":"DEFAULT","value":true,"variant":"on"}}`,
+	}, readSSEEvent(t, untargeted))
+
+	setConfig("on", otherFlag("b"))
+	require.Equal(t, string(service.ConfigurationChange), readSSEEvent(t, targeted).event)
+	require.Equal(t, sseEvent{
+		event: flagValuesEvent,
+		data:  `{"other":{"reason":"STATIC","value":"b","variant":"v"}}`,
+	}, readSSEEvent(t, targeted), "only the changed flag, without a values event for the previous change")
+
+	setConfig("on", "")
+	require.Equal(t, string(service.ConfigurationChange), readSSEEvent(t, targeted).event)
+	require.Equal(t, sseEvent{event: flagValuesEvent, data: `{"other":null}`}, readSSEEvent(t, targeted),
+		"removed flags are null")
+	for i := 0; i < 2; i++ {
+		require.Equal(t, string(service.ConfigurationChange), readSSEEvent(t, untargeted).event)
+	}
+}
+
+func TestSSEHandler_InvalidRequest(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil)
+	server := httptest.NewServer(s.SSEHandler())
+	defer server.Close()
+
+	for _, url := range []string{server.URL + "?context=notjson", server.URL + `?context={"targetingKey":1}`} {
+		res, err := http.Get(url)
+		require.Nil(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestSSEHandler_SubscriberLimit(t *testing.T) {
+	eventing := &eventingConfiguration{
+		subs:           make(map[interface{}]chan service.Notification),
+		mu:             &sync.RWMutex{},
+		maxSubscribers: 1,
+	}
+	s := NewFlagEvaluationService(
+		logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil, withEventingConfiguration(eventing),
+	)
+	server := httptest.NewServer(s.SSEHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	streamCtx, closeStream := context.WithCancel(ctx)
+	stream := openSSEStream(t, streamCtx, server.URL, "")
+	require.Equal(t, string(service.ProviderReady), readSSEEvent(t, stream).event)
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+	closeStream()
+	require.Eventually(t, func() bool {
+		return eventing.subscriberCount() == 0
+	}, time.Second, 10*time.Millisecond)
+	stream = openSSEStream(t, ctx, server.URL, "")
+	require.Equal(t, string(service.ProviderReady), readSSEEvent(t, stream).event)
+}
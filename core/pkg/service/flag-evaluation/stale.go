@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// StaleMetadataKey is the metadata key set to true on the resolutions served while the configuration may be stale
+const StaleMetadataKey = "stale"
+
+// withStaleProbe reports the resolutions served while the probe reports the configuration as stale
+func withStaleProbe(probe service.StaleProbe) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.stale = probe
+	}
+}
+
+// markStale wraps the resolver, adding the stale metadata to successful resolutions while the probe reports the
+// configuration as stale. Resolutions served from a fresh configuration don't hold the stale metadata.
+func markStale[T constraints](probe service.StaleProbe, resolver resolverFunc[T]) resolverFunc[T] {
+	if probe == nil {
+		return resolver
+	}
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (T, string, string, map[string]interface{}, error) {
+		value, variant, reason, metadata, err := resolver(ctx, reqID, flagKey, evalCtx)
+		if err != nil || !probe() {
+			return value, variant, reason, metadata, err
+		}
+		// the metadata may be shared by the resolutions of the flag
+		stale := make(map[string]interface{}, len(metadata)+1)
+		for key, v := range metadata {
+			stale[key] = v
+		}
+		stale[StaleMetadataKey] = true
+		return value, variant, reason, stale, nil
+	}
+}
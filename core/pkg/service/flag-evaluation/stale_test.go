@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/golang/mock/gomock"
+	mock "github.com/open-feature/flagd/core/pkg/eval/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"team": "checkout"}
+	ctrl := gomock.NewController(t)
+	evaluator := mock.NewMockIEvaluator(ctrl)
+	evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).Return(
+		true, "on", model.StaticReason, metadata, nil,
+	).AnyTimes()
+
+	stale := false
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+		withStaleProbe(func() bool { return stale }))
+	resolve := func() map[string]interface{} {
+		res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+		))
+		require.Nil(t, err)
+		var got map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(res.Header().Get(MetadataHeader)), &got))
+		return got
+	}
+
+	require.Equal(t, map[string]interface{}{"team": "checkout"}, resolve(), "fresh configuration")
+
+	stale = true
+	require.Equal(t, map[string]interface{}{"team": "checkout", StaleMetadataKey: true}, resolve(),
+		"stale configuration")
+	require.Equal(t, map[string]interface{}{"team": "checkout"}, metadata, "the metadata of the flag shouldn't change")
+
+	stale = false
+	require.Equal(t, map[string]interface{}{"team": "checkout"}, resolve(), "fresh configuration again")
+}
+
+func TestStaleMetadata_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	evaluator := mock.NewMockIEvaluator(ctrl)
+	evaluator.EXPECT().ResolveBooleanValue(gomock.Any(), gomock.Any(), "myBoolFlag", gomock.Any()).Return(
+		true, "on", model.StaticReason, nil, nil,
+	)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+
+	res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag"},
+	))
+	require.Nil(t, err)
+	require.Empty(t, res.Header().Get(MetadataHeader))
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// targetingKeyHasher hashes the targeting keys surfaced by the service, in logs and evaluation events, so raw user
+// ids never leave flagd. The evaluators are always given the raw key, e.g. for fractional and canary bucketing.
+type targetingKeyHasher struct {
+	salt []byte
+}
+
+// newTargetingKeyHasher returns a hasher keyed by the salt, the hash of a key is then only reproducible with the salt
+func newTargetingKeyHasher(salt string) targetingKeyHasher {
+	return targetingKeyHasher{salt: []byte(salt)}
+}
+
+// hash returns the hex encoded HMAC-SHA256 of the targeting key, keyed by the salt
+func (h targetingKeyHasher) hash(targetingKey string) string {
+	mac := hmac.New(sha256.New, h.salt)
+	mac.Write([]byte(targetingKey))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hashContext returns the hash of the targeting key of the context, empty if it has none
+func (h targetingKeyHasher) hashContext(ctx *structpb.Struct) string {
+	targetingKey := ctx.GetFields()[targetingKeyField].GetStringValue()
+	if targetingKey == "" {
+		return ""
+	}
+	return h.hash(targetingKey)
+}
+
+// WithTargetingKeySalt keys the hash of the targeting keys surfaced by the service with the salt, so their hashes
+// can't be matched against the hashes of known user ids
+func WithTargetingKeySalt(salt string) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.logContextKeys.targetingKey = newTargetingKeyHasher(salt)
+	}
+}
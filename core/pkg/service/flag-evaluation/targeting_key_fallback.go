@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TargetingKeyFallback defines the targeting key of evaluation contexts without one, so fractional evaluations
+// bucketing by the targeting key still distribute them
+type TargetingKeyFallback string
+
+const (
+	// TargetingKeyFallbackNone evaluates contexts without a targeting key as they are, the default
+	TargetingKeyFallbackNone TargetingKeyFallback = "none"
+	// TargetingKeyFallbackUUID sets the targeting key of contexts without one to a random UUID generated per request,
+	// so their fractional evaluations are distributed but not sticky
+	TargetingKeyFallbackUUID TargetingKeyFallback = "uuid"
+
+	// targetingKeyFallbackContext prefixes the context key whose value is the targeting key of contexts without one,
+	// e.g. context:sessionId
+	targetingKeyFallbackContext = "context:"
+)
+
+// ParseTargetingKeyFallback returns the targeting key fallback of its name, either none, uuid or context:<key> for
+// a context key, an empty name defaults to none
+func ParseTargetingKeyFallback(fallback string) (TargetingKeyFallback, error) {
+	switch TargetingKeyFallback(fallback) {
+	case "":
+		return TargetingKeyFallbackNone, nil
+	case TargetingKeyFallbackNone, TargetingKeyFallbackUUID:
+		return TargetingKeyFallback(fallback), nil
+	}
+	if key := strings.TrimPrefix(fallback, targetingKeyFallbackContext); key != fallback && key != "" &&
+		key != targetingKeyField {
+		return TargetingKeyFallback(fallback), nil
+	}
+	return "", fmt.Errorf("unknown targeting key fallback: '%s', expected '%s', '%s' or '%s<key>'",
+		fallback, TargetingKeyFallbackNone, TargetingKeyFallbackUUID, targetingKeyFallbackContext)
+}
+
+// WithTargetingKeyFallback sets the targeting key of the evaluation contexts without one
+func WithTargetingKeyFallback(fallback TargetingKeyFallback) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.targetingKeyFallback = fallback
+	}
+}
+
+// withFallbackTargetingKey returns the context with the fallback targeting key if it has no targeting key, i.e. none,
+// a null or an empty one. The context is returned as is if it has one, without a fallback or if the fallback context
+// key isn't a non empty string.
+func (s *FlagEvaluationService) withFallbackTargetingKey(reqID string, ctx *structpb.Struct) *structpb.Struct {
+	if s.targetingKeyFallback == "" || s.targetingKeyFallback == TargetingKeyFallbackNone {
+		return ctx
+	}
+	switch kind := ctx.GetFields()[targetingKeyField].GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+	case *structpb.Value_StringValue:
+		if kind.StringValue != "" {
+			return ctx
+		}
+	default:
+		// targeting keys of other types are left to the evaluators
+		return ctx
+	}
+
+	var targetingKey string
+	if s.targetingKeyFallback == TargetingKeyFallbackUUID {
+		targetingKey = uuid.NewString()
+	} else {
+		key := strings.TrimPrefix(string(s.targetingKeyFallback), targetingKeyFallbackContext)
+		if targetingKey = ctx.GetFields()[key].GetStringValue(); targetingKey == "" {
+			return ctx
+		}
+	}
+	s.logger.DebugWithID(reqID, fmt.Sprintf("evaluating without a targeting key, falling back to the %s targeting key",
+		s.targetingKeyFallback))
+
+	// the context of the request is copied, it's owned by the request message
+	withKey := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(ctx.GetFields())+1)}
+	for k, v := range ctx.GetFields() {
+		withKey.Fields[k] = v
+	}
+	withKey.Fields[targetingKeyField] = structpb.NewStringValue(targetingKey)
+	return withKey
+}
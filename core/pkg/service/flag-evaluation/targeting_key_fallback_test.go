@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const targetingKeyFallbackFlagConfig = `{
+  "flags": {
+    "rollout": {
+      "state": "ENABLED",
+      "variants": { "old": "old", "new": "new" },
+      "defaultVariant": "old",
+      "targeting": { "fractionalEvaluation": ["targetingKey", ["old", 50], ["new", 50]] }
+    }
+  }
+}`
+
+func resolveRollout(
+	t *testing.T, s *FlagEvaluationService, evalCtx map[string]interface{},
+) *schemaV1.ResolveStringResponse {
+	t.Helper()
+	ctx, err := structpb.NewStruct(evalCtx)
+	require.Nil(t, err)
+	res, err := s.ResolveString(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveStringRequest{FlagKey: "rollout", Context: ctx}))
+	require.Nil(t, err)
+	return res.Msg
+}
+
+func TestTargetingKeyFallback(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, targetingKeyFallbackFlagConfig)
+	require.Nil(t, err)
+	bucketed := resolveRollout(t, NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil),
+		map[string]interface{}{"targetingKey": "session-42"})
+	require.Equal(t, model.TargetingMatchReason, bucketed.GetReason())
+
+	tests := map[string]struct {
+		fallback TargetingKeyFallback
+		context  map[string]interface{}
+		// reason is TARGETING_MATCH if the context is bucketed, with the variant of targeting key session-42 if set
+		reason  string
+		variant string
+	}{
+		"no fallback": {
+			fallback: TargetingKeyFallbackNone,
+			context:  map[string]interface{}{"sessionId": "session-42"},
+			reason:   model.DefaultReason,
+			variant:  "old",
+		},
+		"unset fallback": {
+			context: map[string]interface{}{"sessionId": "session-42"},
+			reason:  model.DefaultReason,
+			variant: "old",
+		},
+		"context key": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"sessionId": "session-42"},
+			reason:   model.TargetingMatchReason,
+			variant:  bucketed.GetVariant(),
+		},
+		"context key of an empty targeting key": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"targetingKey": "", "sessionId": "session-42"},
+			reason:   model.TargetingMatchReason,
+			variant:  bucketed.GetVariant(),
+		},
+		"context key of a null targeting key": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"targetingKey": nil, "sessionId": "session-42"},
+			reason:   model.TargetingMatchReason,
+			variant:  bucketed.GetVariant(),
+		},
+		"missing context key": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"userId": "session-42"},
+			reason:   model.DefaultReason,
+			variant:  "old",
+		},
+		"non string context key": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"sessionId": 42},
+			reason:   model.DefaultReason,
+			variant:  "old",
+		},
+		"targeting key takes precedence": {
+			fallback: "context:sessionId",
+			context:  map[string]interface{}{"targetingKey": "session-42", "sessionId": "another-session"},
+			reason:   model.TargetingMatchReason,
+			variant:  bucketed.GetVariant(),
+		},
+		"uuid": {
+			fallback: TargetingKeyFallbackUUID,
+			context:  map[string]interface{}{},
+			reason:   model.TargetingMatchReason,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil,
+				WithTargetingKeyFallback(tt.fallback))
+			res := resolveRollout(t, s, tt.context)
+			require.Equal(t, tt.reason, res.GetReason())
+			if tt.variant != "" {
+				require.Equal(t, tt.variant, res.GetVariant())
+			}
+		})
+	}
+}
+
+func TestTargetingKeyFallback_UUIDPerRequest(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), nil, nil,
+		WithTargetingKeyFallback(TargetingKeyFallbackUUID))
+	reqCtx := &structpb.Struct{Fields: map[string]*structpb.Value{"plan": structpb.NewStringValue("premium")}}
+
+	keys := map[string]struct{}{}
+	for i := 0; i < 10; i++ {
+		evalCtx := s.withFallbackTargetingKey("", reqCtx)
+		targetingKey := evalCtx.GetFields()[targetingKeyField].GetStringValue()
+		_, err := uuid.Parse(targetingKey)
+		require.Nil(t, err)
+		require.Equal(t, "premium", evalCtx.GetFields()["plan"].GetStringValue())
+		keys[targetingKey] = struct{}{}
+	}
+	require.Len(t, keys, 10, "a targeting key should be generated per request")
+	require.NotContains(t, reqCtx.GetFields(), targetingKeyField, "the context of the request shouldn't be modified")
+
+	withKey := &structpb.Struct{Fields: map[string]*structpb.Value{targetingKeyField: structpb.NewStringValue("u1")}}
+	require.Same(t, withKey, s.withFallbackTargetingKey("", withKey))
+}
+
+func TestParseTargetingKeyFallback(t *testing.T) {
+	for policy, expected := range map[string]TargetingKeyFallback{
+		"":                  TargetingKeyFallbackNone,
+		"none":              TargetingKeyFallbackNone,
+		"uuid":              TargetingKeyFallbackUUID,
+		"context:sessionId": "context:sessionId",
+	} {
+		fallback, err := ParseTargetingKeyFallback(policy)
+		require.Nil(t, err)
+		require.Equal(t, expected, fallback)
+	}
+	for _, policy := range []string{"random", "context:", "context:targetingKey", "sessionId"} {
+		_, err := ParseTargetingKeyFallback(policy)
+		require.EqualError(t, err, "unknown targeting key fallback: '"+policy+
+			"', expected 'none', 'uuid' or 'context:<key>'")
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestTargetingKeyHasher(t *testing.T) {
+	hasher := newTargetingKeyHasher("pepper")
+	require.Equal(t, hasher.hash("user-1"), newTargetingKeyHasher("pepper").hash("user-1"))
+	require.NotEqual(t, hasher.hash("user-1"), hasher.hash("user-2"))
+	require.NotEqual(t, hasher.hash("user-1"), newTargetingKeyHasher("salt").hash("user-1"))
+	require.Len(t, hasher.hash("user-1"), 64)
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"targetingKey": "user-1"})
+	require.Nil(t, err)
+	require.Equal(t, hasher.hash("user-1"), hasher.hashContext(evalCtx))
+	require.Empty(t, hasher.hashContext(&structpb.Struct{}))
+}
+
+// TestTargetingKeyOutputs checks the targeting key is only surfaced hashed, by the logs and evaluation events of
+// every evaluation path
+func TestTargetingKeyOutputs(t *testing.T) {
+	const rawKey = "user-8f41c2"
+	core, logs := observer.New(zap.DebugLevel)
+	log := logger.NewLogger(zap.New(core), true)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(log, variantsFlagConfig)
+	require.Nil(t, err)
+	receiver := &webhookReceiver{}
+	webhook := newTestWebhook(t, receiver, 3, time.Hour)
+	runWebhook(t, webhook)
+	s := NewFlagEvaluationService(log, evaluator, nil,
+		WithLogContextKeys([]string{"targetingKey", "plan"}),
+		WithTargetingKeySalt("pepper"),
+		withEvaluationWebhook(webhook),
+	)
+	hashed := newTargetingKeyHasher("pepper").hash(rawKey)
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"targetingKey": rawKey, "plan": "pro"})
+	require.Nil(t, err)
+
+	_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(&schemaV1.ResolveBooleanRequest{
+		FlagKey: "myBoolFlag", Context: evalCtx,
+	}))
+	require.Nil(t, err)
+	_, err = s.ResolveInt(context.Background(), connect.NewRequest(&schemaV1.ResolveIntRequest{
+		FlagKey: "missing", Context: evalCtx,
+	}))
+	require.NotNil(t, err)
+	_, err = s.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{Context: evalCtx}))
+	require.Nil(t, err)
+
+	entries := logs.FilterMessageSnippet("returning error response").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, map[string]interface{}{"targetingKey": hashed, "plan": "pro"}, entries[0].ContextMap()["context"])
+	for _, entry := range logs.All() {
+		require.NotContains(t, entry.Message, rawKey)
+		for _, value := range entry.ContextMap() {
+			require.NotContains(t, fmt.Sprint(value), rawKey)
+		}
+	}
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+	events := receiver.received()[0].Events
+	require.Len(t, events, 3)
+	for _, event := range events {
+		require.Equal(t, hashed, event.TargetingKey, event.FlagKey)
+	}
+	raw, err := json.Marshal(receiver.received())
+	require.Nil(t, err)
+	require.NotContains(t, string(raw), rawKey)
+}
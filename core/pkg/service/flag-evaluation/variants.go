@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// VariantsPath returns the variants of a flag, e.g. /admin/variants?flagKey=my-flag, it's only served with the
+// admin API enabled
+const VariantsPath = "/admin/variants"
+
+// VariantsHandler returns the variant to value map and the default variant of a flag, without evaluating its
+// targeting, so admin interfaces can render the values each variant would return
+func (s *FlagEvaluationService) VariantsHandler() http.Handler {
+	return http.HandlerFunc(s.serveVariants)
+}
+
+func (s *FlagEvaluationService) serveVariants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flagKey := r.URL.Query().Get("flagKey")
+	if flagKey == "" {
+		http.Error(w, "flagKey query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	state, err := s.evaluatorOf(ReadReplicaList).GetState()
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("get state: %v", err))
+		http.Error(w, "flag state is unavailable", http.StatusInternalServerError)
+		return
+	}
+	var flags struct {
+		Flags map[string]model.Flag `json:"flags"`
+	}
+	if err := json.Unmarshal([]byte(state), &flags); err != nil {
+		s.logger.Error(fmt.Sprintf("unmarshal state: %v", err))
+		http.Error(w, "flag state is unavailable", http.StatusInternalServerError)
+		return
+	}
+	flag, ok := flags.Flags[flagKey]
+	if !ok {
+		http.Error(w, model.FlagNotFoundErrorCode, http.StatusNotFound)
+		return
+	}
+
+	// the variants are converted as resolved values are, so object variants render as they would be resolved
+	res, err := structpb.NewStruct(map[string]interface{}{
+		"flagKey":        flagKey,
+		"state":          flag.State,
+		"defaultVariant": flag.DefaultVariant,
+		"variants":       flag.Variants,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("flag %s variants: %v", flagKey, err))
+		http.Error(w, model.ParseErrorCode, http.StatusInternalServerError)
+		return
+	}
+	body, err := protojson.Marshal(res)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("marshal flag %s variants: %v", flagKey, err))
+		http.Error(w, model.ParseErrorCode, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+const variantsFlagConfig = `{
+  "flags": {
+    "myBoolFlag": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "on",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "off", null] }
+    },
+    "myObjectFlag": {
+      "state": "DISABLED",
+      "variants": {
+        "object1": { "key": "val", "nested": { "list": [1, "two"] } },
+        "object2": { "key": true }
+      },
+      "defaultVariant": "object1"
+    },
+    "myIntFlag": {
+      "state": "ENABLED",
+      "variants": { "one": 1, "two": 2 },
+      "defaultVariant": "two"
+    }
+  }
+}`
+
+func TestVariantsHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.VariantsHandler())
+	defer server.Close()
+
+	var config struct {
+		Flags map[string]map[string]interface{} `json:"flags"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(variantsFlagConfig), &config))
+	for _, flagKey := range []string{"myBoolFlag", "myObjectFlag", "myIntFlag"} {
+		t.Run(flagKey, func(t *testing.T) {
+			res, err := http.Get(server.URL + "?flagKey=" + flagKey)
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+			var variants map[string]interface{}
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&variants))
+			flag := config.Flags[flagKey]
+			require.Equal(t, flagKey, variants["flagKey"])
+			require.Equal(t, flag["state"], variants["state"])
+			require.Equal(t, flag["defaultVariant"], variants["defaultVariant"])
+			require.Equal(t, flag["variants"], variants["variants"])
+		})
+	}
+
+	tests := map[string]struct {
+		method   string
+		query    string
+		wantCode int
+	}{
+		"missing flag":     {method: http.MethodGet, query: "?flagKey=aMissingFlag", wantCode: http.StatusNotFound},
+		"missing flag key": {method: http.MethodGet, wantCode: http.StatusBadRequest},
+		"wrong method":     {method: http.MethodPost, query: "?flagKey=myBoolFlag", wantCode: http.StatusMethodNotAllowed},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.query, nil)
+			require.Nil(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestConnectService_AdminAPI(t *testing.T) {
+	tests := map[string]struct {
+		enableAdminAPI bool
+		wantCode       int
+	}{
+		"enabled":  {enableAdminAPI: true, wantCode: http.StatusOK},
+		"disabled": {wantCode: http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+			require.Nil(t, err)
+			svc := ConnectService{
+				ConnectServiceConfiguration: &ConnectServiceConfiguration{EnableAdminAPI: tt.enableAdminAPI},
+				Eval:                        evaluator,
+				Logger:                      logger.NewLogger(nil, false),
+				Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), name),
+				eventingConfiguration: &eventingConfiguration{
+					subs: make(map[interface{}]chan iservice.Notification),
+					mu:   &sync.RWMutex{},
+				},
+			}
+			server := httptest.NewServer(svc.serviceHandler())
+			defer server.Close()
+
+			res, err := http.Get(server.URL + VariantsPath + "?flagKey=myBoolFlag")
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+
+			sandboxRes, err := http.Post(server.URL+SandboxPath, "application/json",
+				strings.NewReader(`{"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}`))
+			require.Nil(t, err)
+			defer sandboxRes.Body.Close()
+			require.Equal(t, tt.wantCode, sandboxRes.StatusCode)
+
+			whatIfRes, err := http.Post(server.URL+WhatIfPath, "application/json",
+				strings.NewReader(`{"flagKey": "myBoolFlag", "overrides": {"on": false}}`))
+			require.Nil(t, err)
+			defer whatIfRes.Body.Close()
+			require.Equal(t, tt.wantCode, whatIfRes.StatusCode)
+
+			rawRes, err := http.Post(server.URL+RawObjectResolutionPath, "application/json",
+				strings.NewReader(`{"flagKey": "myBoolFlag"}`))
+			require.Nil(t, err)
+			defer rawRes.Body.Close()
+			require.Equal(t, tt.wantCode, rawRes.StatusCode)
+
+			chainRes, err := http.Post(server.URL+ResolutionChainPath, "application/json",
+				strings.NewReader(`{"flagKey": "myBoolFlag"}`))
+			require.Nil(t, err)
+			defer chainRes.Body.Close()
+			require.Equal(t, tt.wantCode, chainRes.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// VerboseFlagsPath returns, or replaces with a PUT request, the flags whose evaluations are logged at the debug
+// level, it's only served with the admin API
+const VerboseFlagsPath = "/admin/verbose-flags"
+
+type verboseFlagsRequest struct {
+	Flags []string `json:"flags"`
+}
+
+type verboseFlagsResponse struct {
+	Flags []string `json:"flags"`
+}
+
+// verboseFlags is the set of flag keys whose evaluations are logged at the debug level, replaced at runtime
+type verboseFlags struct {
+	mu   sync.RWMutex
+	keys map[string]struct{}
+}
+
+func newVerboseFlags() *verboseFlags {
+	return &verboseFlags{keys: map[string]struct{}{}}
+}
+
+// WithVerboseFlags logs the evaluations of the provided flags at the debug level, along with their evaluation context,
+// whatever the log level. The context is logged in full unless WithLogContextKeys restricts the logged keys. The
+// flags can be replaced at runtime at VerboseFlagsPath.
+func WithVerboseFlags(keys []string) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.verboseFlags.set(keys)
+	}
+}
+
+func (v *verboseFlags) contains(key string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	_, ok := v.keys[key]
+	return ok
+}
+
+func (v *verboseFlags) set(keys []string) {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			set[key] = struct{}{}
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = set
+}
+
+func (v *verboseFlags) list() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	keys := make([]string, 0, len(v.keys))
+	for key := range v.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setVerbose logs the request at the debug level if the flag is verbose, reporting whether it is
+func (s *FlagEvaluationService) setVerbose(reqID string, flagKey string) bool {
+	if !s.verboseFlags.contains(flagKey) {
+		return false
+	}
+	s.logger.SetVerbose(reqID)
+	return true
+}
+
+// logVerboseContext logs the evaluation context of a verbose request, in full unless the logged context keys are
+// restricted, the targeting key by its hash and without the override token
+func (s *FlagEvaluationService) logVerboseContext(reqID string, flagKey string, ctx *structpb.Struct) {
+	s.logger.DebugWithID(reqID, fmt.Sprintf("evaluating verbose flag: %s", flagKey),
+		zap.Any("evaluation-context", s.logContextKeys.verbose(ctx)))
+}
+
+// VerboseFlagsHandler returns the flags whose evaluations are logged at the debug level, or replaces them with the
+// flags of a PUT request, so operators can debug a flag without raising the log level of every evaluation
+func (s *FlagEvaluationService) VerboseFlagsHandler() http.Handler {
+	return http.HandlerFunc(s.serveVerboseFlags)
+}
+
+func (s *FlagEvaluationService) serveVerboseFlags(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req verboseFlagsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+			return
+		}
+		s.verboseFlags.set(req.Flags)
+		s.logger.Info(fmt.Sprintf("logging the evaluations of flags: %v at the debug level", s.verboseFlags.list()))
+	default:
+		w.Header().Set("Allow", fmt.Sprintf("%s, %s", http.MethodGet, http.MethodPut))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(verboseFlagsResponse{Flags: s.verboseFlags.list()}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const verboseFlagsConfig = `{
+  "flags": {
+    "verbose": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "on", null] }
+    },
+    "quiet": {
+      "state": "ENABLED", "variants": { "on": true, "off": false }, "defaultVariant": "off",
+      "targeting": { "if": [{ "==": [{ "var": "email" }, "user@faas.com"] }, "on", null] }
+    }
+  }
+}`
+
+func TestVerboseFlags(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := logger.NewVerboseLogger(zap.New(core), zapcore.InfoLevel, false)
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(log.WithFields(zap.String("component", "evaluator")),
+		verboseFlagsConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(log, evaluator, nil, WithVerboseFlags([]string{"verbose"}))
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com", "targetingKey": "user-1"})
+	require.Nil(t, err)
+
+	resolve := func(flagKey string) {
+		t.Helper()
+		logs.TakeAll()
+		res, err := s.ResolveBoolean(context.Background(), connect.NewRequest(
+			&schemaV1.ResolveBooleanRequest{FlagKey: flagKey, Context: evalCtx},
+		))
+		require.Nil(t, err)
+		require.True(t, res.Msg.Value)
+	}
+
+	resolve("quiet")
+	require.Equal(t, 0, logs.Len(), "quiet flags aren't logged")
+
+	resolve("verbose")
+	require.Greater(t, logs.FilterMessageSnippet("evaluating boolean flag: verbose").Len(), 0,
+		"the evaluator logs verbose flags")
+	require.Equal(t, 1, logs.FilterMessageSnippet("targeting of flag: verbose resolved: on").Len(), "rule trace")
+	entries := logs.FilterMessage("evaluating verbose flag: verbose").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, "verbose", fields["flag-key"])
+	evaluationContext, ok := fields["evaluation-context"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "user@faas.com", evaluationContext["email"], "the full context is logged")
+	require.NotEqual(t, "user-1", evaluationContext["targetingKey"], "the targeting key is hashed")
+	require.Equal(t, 1, logs.FilterMessageSnippet("resolved verbose flag: verbose, variant: on").Len())
+	for _, entry := range logs.All() {
+		require.Equal(t, zapcore.DebugLevel, entry.Level)
+		require.NotEqual(t, "quiet", entry.ContextMap()["flag-key"])
+	}
+
+	// the verbose flags are replaced at runtime
+	server := httptest.NewServer(s.VerboseFlagsHandler())
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"flags":["quiet"]}`))
+	require.Nil(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body verboseFlagsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, []string{"quiet"}, body.Flags)
+
+	resolve("verbose")
+	require.Equal(t, 0, logs.Len(), "flags are no longer verbose once replaced")
+	resolve("quiet")
+	require.Equal(t, 1, logs.FilterMessage("evaluating verbose flag: quiet").Len())
+}
+
+func TestVerboseFlags_RedactedContext(t *testing.T) {
+	tests := map[string]struct {
+		logContextKeys []string
+		want           map[string]interface{}
+	}{
+		"full context": {
+			want: map[string]interface{}{"email": "user@faas.com", "plan": "pro", eval.OverrideTokenContextKey: redactedValue},
+		},
+		"allowed keys": {
+			logContextKeys: []string{"plan", eval.OverrideTokenContextKey},
+			want: map[string]interface{}{
+				"email": redactedValue, "plan": "pro", eval.OverrideTokenContextKey: redactedValue,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			log := logger.NewVerboseLogger(zap.New(core), zapcore.InfoLevel, false)
+			evaluator, err := eval.NewJSONEvaluatorFromConfig(log, verboseFlagsConfig)
+			require.Nil(t, err)
+			s := NewFlagEvaluationService(log, evaluator, nil, WithVerboseFlags([]string{"verbose"}),
+				WithLogContextKeys(tt.logContextKeys))
+			evalCtx, err := structpb.NewStruct(map[string]interface{}{
+				"email": "user@faas.com", "plan": "pro", eval.OverrideTokenContextKey: "signed.token.value",
+			})
+			require.Nil(t, err)
+			_, err = s.ResolveBoolean(context.Background(), connect.NewRequest(
+				&schemaV1.ResolveBooleanRequest{FlagKey: "verbose", Context: evalCtx},
+			))
+			require.Nil(t, err)
+
+			entries := logs.FilterMessage("evaluating verbose flag: verbose").All()
+			require.Len(t, entries, 1)
+			require.Equal(t, tt.want, entries[0].ContextMap()["evaluation-context"])
+			for _, entry := range logs.All() {
+				require.NotContains(t, fmt.Sprint(entry.ContextMap()), "signed.token.value",
+					"the override token shouldn't be logged")
+			}
+		})
+	}
+}
+
+func TestVerboseFlagsHandler(t *testing.T) {
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval.NewJSONEvaluator(nil, nil), nil,
+		WithVerboseFlags([]string{"b", "a"}))
+	server := httptest.NewServer(s.VerboseFlagsHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var body verboseFlagsResponse
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, []string{"a", "b"}, body.Flags)
+
+	tests := map[string]struct {
+		method string
+		body   string
+		want   int
+	}{
+		"invalid json":    {method: http.MethodPut, body: "flags", want: http.StatusBadRequest},
+		"unknown method":  {method: http.MethodPost, body: `{"flags":[]}`, want: http.StatusMethodNotAllowed},
+		"disable logging": {method: http.MethodPut, body: `{"flags":[]}`, want: http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+			require.Nil(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			res.Body.Close()
+			require.Equal(t, tt.want, res.StatusCode)
+		})
+	}
+	require.Empty(t, s.verboseFlags.list())
+}
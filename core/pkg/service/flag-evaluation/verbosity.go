@@ -0,0 +1,22 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+)
+
+// VerbosityHeader is the request header selecting the verbosity of resolve responses, full by default
+const VerbosityHeader = "Flagd-Response-Verbosity"
+
+const (
+	// VerbosityFull responds with the value, variant, reason and metadata of resolutions
+	VerbosityFull = "full"
+	// VerbosityMinimal responds with the value of resolutions only, e.g. for high volume clients
+	VerbosityMinimal = "minimal"
+)
+
+// minimalResponse reports whether the request selects minimal responses, unknown verbosities default to full
+// responses
+func minimalResponse(header http.Header) bool {
+	return strings.EqualFold(strings.TrimSpace(header.Get(VerbosityHeader)), VerbosityMinimal)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// defaultWebhookBatchSize is the number of evaluation events posted at once, unless configured otherwise
+	defaultWebhookBatchSize = 100
+	// defaultWebhookInterval is the maximum delay of evaluation events before they're posted, unless configured
+	// otherwise
+	defaultWebhookInterval = time.Second
+	// webhookQueuedBatches is the number of batches queued before evaluation events are dropped
+	webhookQueuedBatches = 10
+	// webhookAttempts is the number of attempts to post a batch before it's dropped
+	webhookAttempts = 5
+	// webhookBackoff is the delay before the first retry of a batch, doubled after each attempt
+	webhookBackoff = 100 * time.Millisecond
+	// webhookTimeout bounds each attempt to post a batch
+	webhookTimeout = 10 * time.Second
+)
+
+// evaluationEvent is an evaluation posted to the evaluation webhook. The targeting key is hashed, so the receiver
+// can correlate the evaluations of a subject without learning its identity.
+type evaluationEvent struct {
+	FlagKey      string    `json:"flagKey"`
+	Variant      string    `json:"variant"`
+	Reason       string    `json:"reason"`
+	TargetingKey string    `json:"targetingKey,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+type evaluationBatch struct {
+	Events []evaluationEvent `json:"events"`
+}
+
+// evaluationWebhook posts batches of evaluation events to a webhook. Events are queued without blocking the
+// evaluations, they're dropped once the queue is full, or after their batch failed to be posted on every attempt.
+type evaluationWebhook struct {
+	url       string
+	client    *http.Client
+	logger    *logger.Logger
+	batchSize int
+	interval  time.Duration
+	backoff   time.Duration
+	now       func() time.Time
+	events    chan evaluationEvent
+	// overloaded counts the events dropped as the queue was full, undelivered the events of batches which failed
+	overloaded  atomic.Int64
+	undelivered atomic.Int64
+}
+
+// newEvaluationWebhook returns a webhook posting to the url, nil if the url is empty. A batch is posted once it
+// holds batchSize events, or interval after its first event.
+func newEvaluationWebhook(
+	log *logger.Logger, url string, batchSize int, interval time.Duration,
+) *evaluationWebhook {
+	if url == "" {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultWebhookBatchSize
+	}
+	if interval <= 0 {
+		interval = defaultWebhookInterval
+	}
+	return &evaluationWebhook{
+		url:       url,
+		client:    &http.Client{Timeout: webhookTimeout},
+		logger:    log,
+		batchSize: batchSize,
+		interval:  interval,
+		backoff:   webhookBackoff,
+		now:       time.Now,
+		events:    make(chan evaluationEvent, batchSize*webhookQueuedBatches),
+	}
+}
+
+// Overloaded returns the number of evaluation events dropped as the queue was full
+func (w *evaluationWebhook) Overloaded() int64 {
+	return w.overloaded.Load()
+}
+
+// Undelivered returns the number of evaluation events of batches which failed to be posted
+func (w *evaluationWebhook) Undelivered() int64 {
+	return w.undelivered.Load()
+}
+
+// record queues an evaluation event of the hashed targeting key, dropping it if the queue is full
+func (w *evaluationWebhook) record(flagKey string, variant string, reason string, targetingKey string) {
+	if w == nil {
+		return
+	}
+	event := evaluationEvent{
+		FlagKey:      flagKey,
+		Variant:      variant,
+		Reason:       reason,
+		TargetingKey: targetingKey,
+		Timestamp:    w.now(),
+	}
+	select {
+	case w.events <- event:
+	default:
+		w.overloaded.Add(1)
+	}
+}
+
+// run batches the queued events and posts them until the context is done, the pending events are posted then
+func (w *evaluationWebhook) run(ctx context.Context) {
+	batch := make([]evaluationEvent, 0, w.batchSize)
+	flush := time.NewTimer(w.interval)
+	flush.Stop()
+	defer flush.Stop()
+	post := func() {
+		if len(batch) > 0 {
+			w.post(ctx, batch)
+			batch = make([]evaluationEvent, 0, w.batchSize)
+		}
+	}
+	for ctx.Err() == nil {
+		select {
+		case event := <-w.events:
+			if len(batch) == 0 {
+				flush.Reset(w.interval)
+			}
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				flush.Stop()
+				post()
+			}
+		case <-flush.C:
+			post()
+		case <-ctx.Done():
+		}
+	}
+	w.flushPending(batch)
+}
+
+// flushPending posts the pending batch along with the queued events once the webhook stops, in a single attempt
+func (w *evaluationWebhook) flushPending(batch []evaluationEvent) {
+	for pending := len(w.events); pending > 0; pending-- {
+		batch = append(batch, <-w.events)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := w.send(context.Background(), batch); err != nil {
+		w.undelivered.Add(int64(len(batch)))
+		w.logger.Warn(fmt.Sprintf("dropping %d evaluation events: %v", len(batch), err))
+	}
+}
+
+// post posts the batch, retrying with an exponential backoff. The batch is dropped if every attempt failed, or the
+// context is done.
+func (w *evaluationWebhook) post(ctx context.Context, batch []evaluationEvent) {
+	backoff := w.backoff
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if err = w.send(ctx, batch); err == nil {
+			return
+		}
+		if attempt == webhookAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			attempt = webhookAttempts
+		}
+	}
+	w.undelivered.Add(int64(len(batch)))
+	w.logger.Warn(fmt.Sprintf("dropping %d evaluation events: %v", len(batch), err))
+}
+
+func (w *evaluationWebhook) send(ctx context.Context, batch []evaluationEvent) error {
+	body, err := json.Marshal(evaluationBatch{Events: batch})
+	if err != nil {
+		return fmt.Errorf("marshalling evaluation events: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting evaluation events: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status: %d", res.StatusCode)
+	}
+	return nil
+}
+
+// withEvaluationWebhook posts the evaluations of the service to the webhook
+func withEvaluationWebhook(webhook *evaluationWebhook) FlagEvaluationServiceOption {
+	return func(s *FlagEvaluationService) {
+		s.webhook = webhook
+	}
+}
+
+// recordEvaluations wraps the resolver, queueing the successful evaluations to the webhook with their hashed
+// targeting key
+func recordEvaluations[T constraints](
+	webhook *evaluationWebhook, targetingKey targetingKeyHasher, resolver resolverFunc[T],
+) resolverFunc[T] {
+	if webhook == nil {
+		return resolver
+	}
+	return func(
+		ctx context.Context, reqID, flagKey string, evalCtx *structpb.Struct,
+	) (T, string, string, map[string]interface{}, error) {
+		value, variant, reason, metadata, err := resolver(ctx, reqID, flagKey, evalCtx)
+		if err == nil {
+			webhook.record(flagKey, variant, reason, targetingKey.hashContext(evalCtx))
+		}
+		return value, variant, reason, metadata, err
+	}
+}
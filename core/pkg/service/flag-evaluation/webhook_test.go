@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/bufbuild/connect-go/schema/v1/schemav1connect"
+	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
+	"github.com/bufbuild/connect-go"
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/otel"
+	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// webhookReceiver records the batches posted to it, failing the first failures requests
+type webhookReceiver struct {
+	mu       sync.Mutex
+	batches  []evaluationBatch
+	failures atomic.Int64
+	requests atomic.Int64
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests.Add(1)
+	if r.failures.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var batch evaluationBatch
+	if err := json.NewDecoder(req.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+}
+
+func (r *webhookReceiver) received() []evaluationBatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]evaluationBatch(nil), r.batches...)
+}
+
+func newTestWebhook(t *testing.T, receiver *webhookReceiver, batchSize int, interval time.Duration) *evaluationWebhook {
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	webhook := newEvaluationWebhook(logger.NewLogger(nil, false), server.URL, batchSize, interval)
+	webhook.backoff = time.Millisecond
+	return webhook
+}
+
+func runWebhook(t *testing.T, webhook *evaluationWebhook) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		webhook.run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestEvaluationWebhookBatches(t *testing.T) {
+	receiver := &webhookReceiver{}
+	webhook := newTestWebhook(t, receiver, 2, time.Hour)
+	runWebhook(t, webhook)
+
+	webhook.record("myBoolFlag", "on", "STATIC", "5e1b2c")
+	webhook.record("myIntFlag", "two", "STATIC", "")
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+
+	events := receiver.received()[0].Events
+	require.Len(t, events, 2)
+	require.Equal(t, "myBoolFlag", events[0].FlagKey)
+	require.Equal(t, "on", events[0].Variant)
+	require.Equal(t, "STATIC", events[0].Reason)
+	require.Equal(t, "5e1b2c", events[0].TargetingKey)
+	require.False(t, events[0].Timestamp.IsZero())
+	require.Empty(t, events[1].TargetingKey)
+}
+
+func TestEvaluationWebhookInterval(t *testing.T) {
+	receiver := &webhookReceiver{}
+	webhook := newTestWebhook(t, receiver, 100, 10*time.Millisecond)
+	runWebhook(t, webhook)
+
+	webhook.record("myBoolFlag", "on", "STATIC", "")
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+	require.Len(t, receiver.received()[0].Events, 1)
+}
+
+func TestEvaluationWebhookRetries(t *testing.T) {
+	receiver := &webhookReceiver{}
+	receiver.failures.Store(2)
+	webhook := newTestWebhook(t, receiver, 1, time.Hour)
+	runWebhook(t, webhook)
+
+	webhook.record("myBoolFlag", "on", "STATIC", "")
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, int64(3), receiver.requests.Load())
+	require.Zero(t, webhook.Undelivered())
+
+	// batches failing every attempt are dropped
+	receiver.failures.Store(webhookAttempts)
+	webhook.record("myBoolFlag", "on", "STATIC", "")
+	require.Eventually(t, func() bool { return webhook.Undelivered() == 1 }, time.Second, time.Millisecond)
+	require.Len(t, receiver.received(), 1)
+}
+
+func TestEvaluationWebhookOverload(t *testing.T) {
+	receiver := &webhookReceiver{}
+	// the webhook isn't running, nothing drains its queue
+	webhook := newTestWebhook(t, receiver, 1, time.Hour)
+	for i := 0; i < webhookQueuedBatches+3; i++ {
+		webhook.record("myBoolFlag", "on", "STATIC", "")
+	}
+	require.Equal(t, int64(3), webhook.Overloaded())
+
+	// the queued events are posted once the webhook stops
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	webhook.run(ctx)
+	require.Len(t, receiver.received(), 1)
+	require.Len(t, receiver.received()[0].Events, webhookQueuedBatches)
+}
+
+func TestEvaluationWebhookResolve(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	receiver := &webhookReceiver{}
+	webhook := newTestWebhook(t, receiver, 3, time.Hour)
+	runWebhook(t, webhook)
+	svc := ConnectService{
+		ConnectServiceConfiguration: &ConnectServiceConfiguration{},
+		Eval:                        evaluator,
+		Logger:                      logger.NewLogger(nil, false),
+		Metrics:                     otel.NewOTelRecorder(metric.NewManualReader(), "evaluation-webhook"),
+		eventingConfiguration: &eventingConfiguration{
+			subs: make(map[interface{}]chan iservice.Notification),
+			mu:   &sync.RWMutex{},
+		},
+		webhook: webhook,
+	}
+	server := httptest.NewServer(svc.serviceHandler())
+	defer server.Close()
+	client := schemaConnectV1.NewServiceClient(server.Client(), server.URL)
+
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+	_, err = client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myBoolFlag", Context: evalCtx},
+	))
+	require.Nil(t, err)
+	// failed evaluations aren't posted
+	_, err = client.ResolveBoolean(context.Background(), connect.NewRequest(
+		&schemaV1.ResolveBooleanRequest{FlagKey: "myIntFlag"},
+	))
+	require.NotNil(t, err)
+	_, err = client.ResolveAll(context.Background(), connect.NewRequest(&schemaV1.ResolveAllRequest{}))
+	require.Nil(t, err)
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+
+	posted := map[string]string{}
+	for _, event := range receiver.received()[0].Events {
+		posted[event.FlagKey+"/"+event.Variant] = event.Reason
+	}
+	require.Equal(t, map[string]string{
+		"myBoolFlag/off": "TARGETING_MATCH",
+		"myBoolFlag/on":  "DEFAULT",
+		"myIntFlag/two":  "STATIC",
+	}, posted)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// WhatIfPath evaluates a stored flag with the values of some of its variants overridden, it's only served with the
+// admin API enabled
+const WhatIfPath = "/admin/what-if"
+
+type whatIfRequest struct {
+	FlagKey string `json:"flagKey"`
+	// Overrides are the values of the variants evaluated as if they were live, keyed by variant
+	Overrides map[string]json.RawMessage `json:"overrides"`
+	Context   map[string]interface{}     `json:"context"`
+}
+
+// WhatIfHandler evaluates a stored flag against an evaluation context as if the overridden values of its variants
+// were live, so tooling can preview the impact of a value change. The store is left unchanged.
+func (s *FlagEvaluationService) WhatIfHandler() http.Handler {
+	return http.HandlerFunc(s.serveWhatIf)
+}
+
+func (s *FlagEvaluationService) serveWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	whatIf, ok := s.evaluatorOf(ReadReplicaWhatIf).(eval.WhatIf)
+	if !ok {
+		http.Error(w, "the evaluator can't evaluate overridden variants", http.StatusNotImplemented)
+		return
+	}
+	var req whatIfRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSandboxRequestBytes)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request exceeds %d bytes", MaxSandboxRequestBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("request isn't valid json: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.FlagKey == "" {
+		http.Error(w, "flagKey is required", http.StatusBadRequest)
+		return
+	}
+	evalCtx := evaluationContext(nil)
+	if req.Context != nil {
+		var err error
+		if evalCtx, err = structpb.NewStruct(req.Context); err != nil {
+			http.Error(w, fmt.Sprintf("context: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateContext(evalCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqID := xid.New().String()
+	defer s.logger.ClearFields(reqID)
+	s.logger.WriteFields(reqID, s.logContextKeys.fields(evalCtx)...)
+	result, err := whatIf.EvaluateWhatIf(reqID, req.FlagKey, req.Overrides, evalCtx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid overrides: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sandboxResponse{
+		FlagKey:   req.FlagKey,
+		Value:     result.Value,
+		Variant:   result.Variant,
+		Reason:    result.Reason,
+		Metadata:  result.Metadata,
+		ErrorCode: result.ErrorCode,
+		Trace:     result.Trace,
+	}); err != nil {
+		s.logger.ErrorWithID(reqID, fmt.Sprintf("encoding what-if response: %v", err))
+	}
+}
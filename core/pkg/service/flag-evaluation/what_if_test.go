@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestWhatIfHandler(t *testing.T) {
+	evaluator, err := eval.NewJSONEvaluatorFromConfig(nil, variantsFlagConfig)
+	require.Nil(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), evaluator, nil)
+	server := httptest.NewServer(s.WhatIfHandler())
+	defer server.Close()
+	state, err := evaluator.GetState()
+	require.Nil(t, err)
+
+	tests := map[string]struct {
+		method       string
+		body         string
+		wantCode     int
+		wantResponse sandboxResponse
+	}{
+		"without override": {
+			body:     `{"flagKey": "myIntFlag"}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "myIntFlag",
+				Value:   float64(2),
+				Variant: "two",
+				Reason:  model.StaticReason,
+				Trace:   []string{"flag has no targeting, resolving the default variant: two"},
+			},
+		},
+		"override of the resolved variant": {
+			body:     `{"flagKey": "myIntFlag", "overrides": {"two": 20}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "myIntFlag",
+				Value:   float64(20),
+				Variant: "two",
+				Reason:  model.StaticReason,
+				Trace: []string{
+					"overrode the value of variant: two with 20",
+					"flag has no targeting, resolving the default variant: two",
+				},
+			},
+		},
+		"override of another variant": {
+			body:     `{"flagKey": "myIntFlag", "overrides": {"one": 10}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "myIntFlag",
+				Value:   float64(2),
+				Variant: "two",
+				Reason:  model.StaticReason,
+				Trace: []string{
+					"overrode the value of variant: one with 10",
+					"flag has no targeting, resolving the default variant: two",
+				},
+			},
+		},
+		"override of the targeted variant": {
+			body:     `{"flagKey": "myBoolFlag", "overrides": {"off": true}, "context": {"email": "user@faas.com"}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey: "myBoolFlag",
+				Value:   true,
+				Variant: "off",
+				Reason:  model.TargetingMatchReason,
+				Trace: []string{
+					"overrode the value of variant: off with true",
+					`evaluating targeting: {"if":[{"==":[{"var":"email"},"user@faas.com"]},"off",null]}`,
+					`targeting resolved: "off"`,
+					"variant: off matched",
+				},
+			},
+		},
+		"disabled flag": {
+			body:     `{"flagKey": "myObjectFlag", "overrides": {"object1": {"key": "other"}}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey:   "myObjectFlag",
+				Reason:    model.ErrorReason,
+				ErrorCode: model.FlagDisabledErrorCode,
+				Trace:     []string{`overrode the value of variant: object1 with {"key":"other"}`, "flag is disabled"},
+			},
+		},
+		"missing flag": {
+			body:     `{"flagKey": "missing", "overrides": {"on": false}}`,
+			wantCode: http.StatusOK,
+			wantResponse: sandboxResponse{
+				FlagKey:   "missing",
+				Reason:    model.ErrorReason,
+				ErrorCode: model.FlagNotFoundErrorCode,
+				Trace:     []string{"flag: missing isn't stored"},
+			},
+		},
+		"unknown variant": {
+			body:     `{"flagKey": "myIntFlag", "overrides": {"three": 3}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"override of another type": {
+			body:     `{"flagKey": "myIntFlag", "overrides": {"two": "2"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"missing flag key": {
+			body:     `{"overrides": {"two": 20}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid method": {
+			method:   http.MethodGet,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.method == "" {
+				tt.method = http.MethodPost
+			}
+			req, err := http.NewRequest(tt.method, server.URL, bytes.NewBufferString(tt.body))
+			require.Nil(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got sandboxResponse
+			require.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Equal(t, tt.wantResponse, got)
+		})
+	}
+
+	after, err := evaluator.GetState()
+	require.Nil(t, err)
+	require.Equal(t, state, after, "what-if evaluations mustn't change the stored flags")
+	evalCtx, err := structpb.NewStruct(map[string]interface{}{"email": "user@faas.com"})
+	require.Nil(t, err)
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "myBoolFlag", evalCtx)
+	require.Nil(t, err)
+	require.False(t, value, "overridden values mustn't be live")
+}
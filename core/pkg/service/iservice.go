@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/eval"
+	"github.com/open-feature/flagd/core/pkg/sync"
 )
 
 type NotificationType string
@@ -21,8 +23,30 @@ type Notification struct {
 
 type ReadinessProbe func() bool
 
+// StaleProbe reports whether the configuration being served may be stale, as a source is unreachable
+type StaleProbe func() bool
+
+// ConfigLoadedProbe returns the time the configuration being served was last reloaded, zero before it's loaded
+type ConfigLoadedProbe func() time.Time
+
+// StartupProbe reports whether the startup is complete, every source having applied its initial configuration
+type StartupProbe func() bool
+
+// ResyncTrigger resyncs the whole configuration of every source
+type ResyncTrigger func()
+
 type Configuration struct {
 	ReadinessProbe ReadinessProbe
+	// StartupProbe, if set, is served by the metrics server, reporting whether the startup is complete
+	StartupProbe StartupProbe
+	// StaleProbe, if set, marks the resolutions served while the configuration may be stale
+	StaleProbe StaleProbe
+	// ConfigLoaded, if set, adds the load time and age of the configuration to the resolutions
+	ConfigLoaded ConfigLoadedProbe
+	// Resync, if set, is triggered once the caches are flushed through the admin API
+	Resync ResyncTrigger
+	// SourceStatuses, if set, are served as json by the metrics server
+	SourceStatuses *sync.SourceStatuses
 	Port           uint16
 	MetricsPort    uint16
 	ServiceName    string
@@ -37,6 +61,13 @@ type IFlagEvaluationService interface {
 	Notify(n Notification)
 }
 
+// EvaluatorSwapper is implemented by the services whose evaluator can be swapped while serving
+type EvaluatorSwapper interface {
+	// SwapEvaluator serves the requests with the evaluator, returning the evaluator previously serving them.
+	// Requests in flight finish on the previous evaluator.
+	SwapEvaluator(next eval.IEvaluator) eval.IEvaluator
+}
+
 /*
 IFlagEvaluationService implementations define handlers for a particular transport,
 which call the IEvaluator implementation.
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// minCompressedSize is the size below which string and object variants are stored as is, as compressing them
+// wouldn't save memory
+const minCompressedSize = 256
+
+// compressedVariant is a string or object variant stored compressed, objects are compressed as json
+type compressedVariant struct {
+	object bool
+	data   []byte
+}
+
+// CompressedStore wraps a Store, compressing the large string and object variants of the flags it stores and
+// decompressing them when the flags are read. It trades the CPU time of decompressing the variants of a flag on each
+// read for the memory of large configurations.
+type CompressedStore struct {
+	Store
+	writers sync.Pool
+}
+
+// NewCompressedStore returns a Store compressing the variants of the flags it stores in the provided backend
+func NewCompressedStore(s Store) *CompressedStore {
+	return &CompressedStore{
+		Store: s,
+		writers: sync.Pool{New: func() interface{} {
+			// the level is valid, so NewWriter doesn't fail
+			w, _ := flate.NewWriter(nil, flate.BestSpeed)
+			return w
+		}},
+	}
+}
+
+func (c *CompressedStore) Get(key string) (model.Flag, bool) {
+	flag, ok := c.Store.Get(key)
+	if !ok {
+		return flag, false
+	}
+	return decompressFlag(flag), true
+}
+
+func (c *CompressedStore) List() map[string]model.Flag {
+	flags := c.Store.List()
+	for key, flag := range flags {
+		flags[key] = decompressFlag(flag)
+	}
+	return flags
+}
+
+func (c *CompressedStore) Set(key string, flag model.Flag) {
+	c.Store.Set(key, c.compressFlag(flag))
+}
+
+// compressFlag returns a copy of the flag whose large string and object variants are compressed
+func (c *CompressedStore) compressFlag(flag model.Flag) model.Flag {
+	if flag.Variants == nil {
+		return flag
+	}
+	variants := make(map[string]any, len(flag.Variants))
+	for variant, value := range flag.Variants {
+		variants[variant] = c.compressVariant(value)
+	}
+	flag.Variants = variants
+	return flag
+}
+
+func (c *CompressedStore) compressVariant(value any) any {
+	var data []byte
+	object := false
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return value
+		}
+		data, object = encoded, true
+	default:
+		return value
+	}
+	if len(data) < minCompressedSize {
+		return value
+	}
+
+	var buf bytes.Buffer
+	w, _ := c.writers.Get().(*flate.Writer)
+	defer c.writers.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return value
+	}
+	if err := w.Close(); err != nil {
+		return value
+	}
+	return compressedVariant{object: object, data: buf.Bytes()}
+}
+
+// decompressFlag returns a copy of the flag whose compressed variants are decompressed
+func decompressFlag(flag model.Flag) model.Flag {
+	if flag.Variants == nil {
+		return flag
+	}
+	variants := make(map[string]any, len(flag.Variants))
+	for variant, value := range flag.Variants {
+		compressed, ok := value.(compressedVariant)
+		if !ok {
+			variants[variant] = value
+			continue
+		}
+		decompressed, err := compressed.decompress()
+		if err != nil {
+			// the variants are compressed by the store, so they decompress, or resolve a type mismatch otherwise
+			variants[variant] = value
+			continue
+		}
+		variants[variant] = decompressed
+	}
+	flag.Variants = variants
+	return flag
+}
+
+func (v compressedVariant) decompress() (any, error) {
+	r := flate.NewReader(bytes.NewReader(v.data))
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !v.object {
+		return string(data), nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
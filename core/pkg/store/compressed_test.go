@@ -0,0 +1,155 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// largeObject returns an object variant of nested objects, arrays, numbers, booleans and strings, encoded as json
+// of about the given number of entries
+func largeObject(entries int) map[string]interface{} {
+	items := make([]interface{}, 0, entries)
+	for i := 0; i < entries; i++ {
+		items = append(items, map[string]interface{}{
+			"id":      float64(i),
+			"price":   float64(i) + 0.99,
+			"enabled": i%2 == 0,
+			"label":   fmt.Sprintf("item-%d", i),
+			"tags":    []interface{}{"catalog", "checkout", nil},
+		})
+	}
+	return map[string]interface{}{
+		"version": float64(3),
+		"theme":   map[string]interface{}{"name": "dark", "contrast": 1.5e-3},
+		"items":   items,
+	}
+}
+
+func TestCompressedStore(t *testing.T) {
+	testStore(t, func() Store {
+		return NewCompressedStore(NewMemoryStore())
+	})
+}
+
+func TestCompressedStore_RoundTrip(t *testing.T) {
+	flag := model.Flag{
+		State:          "ENABLED",
+		DefaultVariant: "large",
+		Variants: map[string]any{
+			"large":      largeObject(1000),
+			"largeEmpty": largeObject(0),
+			"text":       strings.Repeat("lorem ipsum ", 100),
+			"unicode":    strings.Repeat("grüße 🚀 ", 50),
+			"short":      "short",
+			"small":      map[string]interface{}{"a": true},
+			"number":     3.14,
+			"flag":       true,
+		},
+		Metadata: map[string]interface{}{"owner": "catalog"},
+	}
+	backend := NewMemoryStore()
+	s := NewCompressedStore(backend)
+	s.Set("catalog", flag)
+
+	got, ok := s.Get("catalog")
+	require.True(t, ok)
+	require.Equal(t, flag, got)
+	require.Equal(t, map[string]model.Flag{"catalog": flag}, s.List())
+
+	stored, _ := backend.Get("catalog")
+	for _, variant := range []string{"large", "text", "unicode"} {
+		require.IsType(t, compressedVariant{}, stored.Variants[variant], variant)
+	}
+	for _, variant := range []string{"largeEmpty", "short", "small", "number", "flag"} {
+		require.Equal(t, flag.Variants[variant], stored.Variants[variant], variant)
+	}
+	encoded, err := json.Marshal(flag.Variants["large"])
+	require.Nil(t, err)
+	require.Less(t, len(stored.Variants["large"].(compressedVariant).data), len(encoded)/4)
+
+	got.Variants["large"].(map[string]interface{})["version"] = float64(4)
+	again, _ := s.Get("catalog")
+	require.Equal(t, float64(3), again.Variants["large"].(map[string]interface{})["version"],
+		"modifying a read flag mustn't modify the store")
+}
+
+func TestCompressedStore_Merge(t *testing.T) {
+	f := NewFlagsWithStore(NewCompressedStore(NewMemoryStore()))
+	flags := map[string]model.Flag{
+		"catalog": {State: "ENABLED", DefaultVariant: "large", Variants: map[string]any{"large": largeObject(100)}},
+	}
+	notifications, _ := f.Merge(logger.NewLogger(nil, false), "A", flags)
+	require.Len(t, notifications, 1)
+
+	notifications, _ = f.Merge(logger.NewLogger(nil, false), "A", flags)
+	require.Empty(t, notifications, "an unchanged flag shouldn't be updated")
+}
+
+// largeFlags returns flags whose object variants hold the given number of entries
+func largeFlags(flags int, entries int) map[string]model.Flag {
+	all := make(map[string]model.Flag, flags)
+	for i := 0; i < flags; i++ {
+		all[fmt.Sprintf("flag-%d", i)] = model.Flag{
+			State:          "ENABLED",
+			DefaultVariant: "on",
+			Variants:       map[string]any{"on": largeObject(entries), "off": largeObject(entries / 2)},
+		}
+	}
+	return all
+}
+
+// heapInUse returns the bytes of the live heap
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// BenchmarkStoreMemory reports the heap bytes of each flag held by the memory and compressed stores
+func BenchmarkStoreMemory(b *testing.B) {
+	for name, newStore := range map[string]func() Store{
+		"memory":     func() Store { return NewMemoryStore() },
+		"compressed": func() Store { return NewCompressedStore(NewMemoryStore()) },
+	} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				s := newStore()
+				for key, flag := range largeFlags(100, 200) {
+					s.Set(key, flag)
+				}
+				after := heapInUse()
+				b.ReportMetric(float64(int64(after)-int64(before))/100, "heap-bytes/flag")
+				runtime.KeepAlive(s)
+			}
+		})
+	}
+}
+
+// BenchmarkStoreGet reports the cost of reading a flag, as done by each evaluation
+func BenchmarkStoreGet(b *testing.B) {
+	for name, s := range map[string]Store{
+		"memory":     NewMemoryStore(),
+		"compressed": NewCompressedStore(NewMemoryStore()),
+	} {
+		for key, flag := range largeFlags(1, 200) {
+			s.Set(key, flag)
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, ok := s.Get("flag-0"); !ok {
+					b.Fatal("flag not found")
+				}
+			}
+		})
+	}
+}
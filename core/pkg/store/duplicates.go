@@ -0,0 +1,78 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// DuplicateKeys defines how flags whose key is defined by more than one source, or more than once by a source, are
+// handled
+type DuplicateKeys string
+
+const (
+	// DuplicateKeysLastWins keeps the definition of the source defined last, or the last definition of a source, the
+	// default
+	DuplicateKeysLastWins DuplicateKeys = "last-wins"
+	// DuplicateKeysFirstWins keeps the definition of the source defined first, or the first definition of a source
+	DuplicateKeysFirstWins DuplicateKeys = "first-wins"
+	// DuplicateKeysError rejects configurations defining a key which is already defined
+	DuplicateKeysError DuplicateKeys = "error"
+)
+
+// ParseDuplicateKeys returns the duplicate key policy of its name, an empty name defaults to last-wins
+func ParseDuplicateKeys(policy string) (DuplicateKeys, error) {
+	switch DuplicateKeys(policy) {
+	case "":
+		return DuplicateKeysLastWins, nil
+	case DuplicateKeysLastWins, DuplicateKeysFirstWins, DuplicateKeysError:
+		return DuplicateKeys(policy), nil
+	default:
+		return "", fmt.Errorf("unknown duplicate flag key policy: '%s', expected one of '%s', '%s' or '%s'",
+			policy, DuplicateKeysError, DuplicateKeysFirstWins, DuplicateKeysLastWins)
+	}
+}
+
+// CheckDuplicates returns an error naming both sources if the policy is error and any of the flags of the source is
+// already defined by another source
+func (f *Flags) CheckDuplicates(source string, flags map[string]model.Flag) error {
+	if f.DuplicateKeys != DuplicateKeysError {
+		return nil
+	}
+	keys := make([]string, 0, len(flags))
+	for key := range flags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if stored, ok := f.Get(key); ok && stored.Source != source {
+			return fmt.Errorf("duplicate flag key: '%s' of source: '%s' is already defined by source: '%s'",
+				key, source, stored.Source)
+		}
+	}
+	return nil
+}
+
+// resolveDuplicate reports whether the flag of the new source replaces the one of the stored source, logging the
+// resolution of keys defined by both
+func (f *Flags) resolveDuplicate(logger *logger.Logger, key string, stored string, new string) bool {
+	replace := f.hasPriority(stored, new)
+	if stored != new {
+		kept := stored
+		if replace {
+			kept = new
+		}
+		logger.Warn(fmt.Sprintf("duplicate flag key: '%s' is defined by sources: '%s' and '%s', keeping '%s' (%s)",
+			key, stored, new, kept, f.duplicateKeys()))
+	}
+	return replace
+}
+
+func (f *Flags) duplicateKeys() DuplicateKeys {
+	if f.DuplicateKeys == "" {
+		return DuplicateKeysLastWins
+	}
+	return f.DuplicateKeys
+}
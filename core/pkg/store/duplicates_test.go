@@ -0,0 +1,93 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseDuplicateKeys(t *testing.T) {
+	for policy, want := range map[string]DuplicateKeys{
+		"":           DuplicateKeysLastWins,
+		"last-wins":  DuplicateKeysLastWins,
+		"first-wins": DuplicateKeysFirstWins,
+		"error":      DuplicateKeysError,
+	} {
+		got, err := ParseDuplicateKeys(policy)
+		require.Nil(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err := ParseDuplicateKeys("random")
+	require.NotNil(t, err)
+}
+
+func TestFlags_DuplicateKeys(t *testing.T) {
+	tests := map[string]struct {
+		policy     DuplicateKeys
+		sources    []string
+		wantSource string
+		wantLog    string
+	}{
+		"last wins": {
+			policy:     DuplicateKeysLastWins,
+			sources:    []string{"A", "B"},
+			wantSource: "B",
+			wantLog:    "duplicate flag key: 'hello' is defined by sources: 'A' and 'B', keeping 'B' (last-wins)",
+		},
+		"unset policy is last wins": {
+			sources:    []string{"A", "B"},
+			wantSource: "B",
+			wantLog:    "duplicate flag key: 'hello' is defined by sources: 'A' and 'B', keeping 'B' (last-wins)",
+		},
+		"first wins": {
+			policy:     DuplicateKeysFirstWins,
+			sources:    []string{"A", "B"},
+			wantSource: "A",
+			wantLog:    "duplicate flag key: 'hello' is defined by sources: 'A' and 'B', keeping 'A' (first-wins)",
+		},
+		"first wins by source order": {
+			policy:     DuplicateKeysFirstWins,
+			sources:    []string{"B", "A"},
+			wantSource: "B",
+			wantLog:    "duplicate flag key: 'hello' is defined by sources: 'A' and 'B', keeping 'B' (first-wins)",
+		},
+		"first wins for unordered sources": {
+			policy:     DuplicateKeysFirstWins,
+			wantSource: "A",
+			wantLog:    "duplicate flag key: 'hello' is defined by sources: 'A' and 'B', keeping 'A' (first-wins)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			log := logger.NewLogger(zap.New(core), false)
+			flags := newTestFlags(nil, tt.sources...)
+			flags.DuplicateKeys = tt.policy
+
+			flags.Merge(log, "A", map[string]model.Flag{"hello": {DefaultVariant: "off"}})
+			require.Nil(t, flags.CheckDuplicates("B", map[string]model.Flag{"hello": {DefaultVariant: "on"}}))
+			flags.Merge(log, "B", map[string]model.Flag{"hello": {DefaultVariant: "on"}})
+
+			flag, ok := flags.Get("hello")
+			require.True(t, ok)
+			require.Equal(t, tt.wantSource, flag.Source)
+			require.Equal(t, 1, logs.FilterMessage(tt.wantLog).Len())
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		flags := newTestFlags(nil, "A", "B")
+		flags.DuplicateKeys = DuplicateKeysError
+		flags.Merge(logger.NewLogger(nil, false), "A", map[string]model.Flag{"hello": {DefaultVariant: "off"}})
+
+		require.Nil(t, flags.CheckDuplicates("A", map[string]model.Flag{"hello": {DefaultVariant: "on"}}),
+			"a source may redefine its own flags")
+		err := flags.CheckDuplicates("B", map[string]model.Flag{"hello": {DefaultVariant: "on"}})
+		require.EqualError(t, err, "duplicate flag key: 'hello' of source: 'B' is already defined by source: 'A'")
+	})
+}
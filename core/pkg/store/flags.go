@@ -11,16 +11,33 @@ import (
 	"github.com/open-feature/flagd/core/pkg/model"
 )
 
+// Flags merges the flags of multiple sources by their priority, storing the merged flags in a Store backend
 type Flags struct {
-	mx          sync.RWMutex
-	Flags       map[string]model.Flag `json:"flags"`
-	FlagSources []string              `json:"flagSources"`
+	// mx serializes the merging of sources, the backend guards its own state
+	mx          sync.Mutex
+	store       Store
+	FlagSources []string
+	// DuplicateKeys is the policy resolving keys defined by more than one source, last-wins when unset
+	DuplicateKeys DuplicateKeys
 }
 
+// hasPriority reports whether the flags of the new source replace those of the stored source. Sources are ordered
+// by FlagSources, sources missing from it are ordered by the time their flags are stored.
 func (f *Flags) hasPriority(stored string, new string) bool {
 	if stored == new {
 		return true
 	}
+	if f.DuplicateKeys == DuplicateKeysFirstWins {
+		for _, source := range f.FlagSources {
+			switch source {
+			case stored:
+				return false
+			case new:
+				return true
+			}
+		}
+		return false
+	}
 	for i := len(f.FlagSources) - 1; i >= 0; i-- {
 		switch f.FlagSources[i] {
 		case stored:
@@ -32,34 +49,42 @@ func (f *Flags) hasPriority(stored string, new string) bool {
 	return true
 }
 
+// NewFlags returns Flags stored in memory
 func NewFlags() *Flags {
-	return &Flags{Flags: map[string]model.Flag{}}
+	return NewFlagsWithStore(NewMemoryStore())
+}
+
+// NewFlagsWithStore returns Flags stored in the provided backend, e.g. a queryable store for large flag sets
+func NewFlagsWithStore(s Store) *Flags {
+	return &Flags{store: s}
+}
+
+// Store returns the backend of the flags, e.g. to watch their changes
+func (f *Flags) Store() Store {
+	return f.store
 }
 
 func (f *Flags) Set(key string, flag model.Flag) {
-	f.mx.Lock()
-	defer f.mx.Unlock()
-	f.Flags[key] = flag
+	f.store.Set(key, flag)
 }
 
 func (f *Flags) Get(key string) (model.Flag, bool) {
-	f.mx.RLock()
-	defer f.mx.RUnlock()
-	flag, ok := f.Flags[key]
-
-	return flag, ok
+	return f.store.Get(key)
 }
 
 func (f *Flags) Delete(key string) {
-	f.mx.Lock()
-	defer f.mx.Unlock()
-	delete(f.Flags, key)
+	f.store.Delete(key)
 }
 
+// String returns the json of the flags, a snapshot taken between merges
 func (f *Flags) String() (string, error) {
-	f.mx.RLock()
-	defer f.mx.RUnlock()
-	bytes, err := json.Marshal(f)
+	bytes, err := json.Marshal(struct {
+		Flags       map[string]model.Flag `json:"flags"`
+		FlagSources []string              `json:"flagSources"`
+	}{
+		Flags:       f.GetAll(),
+		FlagSources: f.FlagSources,
+	})
 	if err != nil {
 		return "", err
 	}
@@ -67,26 +92,23 @@ func (f *Flags) String() (string, error) {
 	return string(bytes), nil
 }
 
-// GetAll returns a copy of the store's state (copy in order to be concurrency safe)
+// GetAll returns a copy of the store's state (copy in order to be concurrency safe), taken between merges so it
+// never holds a partially merged update
 func (f *Flags) GetAll() map[string]model.Flag {
-	f.mx.RLock()
-	defer f.mx.RUnlock()
-	state := make(map[string]model.Flag, len(f.Flags))
-
-	for key, flag := range f.Flags {
-		state[key] = flag
-	}
-
-	return state
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return f.store.List()
 }
 
 // Add new flags from source.
 func (f *Flags) Add(logger *logger.Logger, source string, flags map[string]model.Flag) map[string]interface{} {
+	f.mx.Lock()
+	defer f.mx.Unlock()
 	notifications := map[string]interface{}{}
 
 	for k, newFlag := range flags {
 		storedFlag, ok := f.Get(k)
-		if ok && !f.hasPriority(storedFlag.Source, source) {
+		if ok && !f.resolveDuplicate(logger, k, storedFlag.Source, source) {
 			logger.Debug(
 				fmt.Sprintf(
 					"not overwriting: flag %s from source %s does not have priority over %s",
@@ -113,6 +135,8 @@ func (f *Flags) Add(logger *logger.Logger, source string, flags map[string]model
 
 // Update existing flags from source.
 func (f *Flags) Update(logger *logger.Logger, source string, flags map[string]model.Flag) map[string]interface{} {
+	f.mx.Lock()
+	defer f.mx.Unlock()
 	notifications := map[string]interface{}{}
 
 	for k, flag := range flags {
@@ -125,7 +149,7 @@ func (f *Flags) Update(logger *logger.Logger, source string, flags map[string]mo
 
 			continue
 		}
-		if !f.hasPriority(storedFlag.Source, source) {
+		if !f.resolveDuplicate(logger, k, storedFlag.Source, source) {
 			logger.Debug(
 				fmt.Sprintf(
 					"not updating: flag %s from source %s does not have priority over %s",
@@ -151,6 +175,8 @@ func (f *Flags) Update(logger *logger.Logger, source string, flags map[string]mo
 
 // DeleteFlags matching flags from source.
 func (f *Flags) DeleteFlags(logger *logger.Logger, source string, flags map[string]model.Flag) map[string]interface{} {
+	f.mx.Lock()
+	defer f.mx.Unlock()
 	logger.Debug(
 		fmt.Sprintf(
 			"store resync triggered: delete event from source %s",
@@ -159,7 +185,7 @@ func (f *Flags) DeleteFlags(logger *logger.Logger, source string, flags map[stri
 	)
 	notifications := map[string]interface{}{}
 	if len(flags) == 0 {
-		allFlags := f.GetAll()
+		allFlags := f.store.List()
 		for key, flag := range allFlags {
 			if flag.Source != source {
 				continue
@@ -212,11 +238,12 @@ func (f *Flags) Merge(
 	notifications := map[string]interface{}{}
 	resyncRequired := false
 	f.mx.Lock()
-	for k, v := range f.Flags {
+	defer f.mx.Unlock()
+	for k, v := range f.store.List() {
 		if v.Source == source {
 			if _, ok := flags[k]; !ok {
 				// flag has been deleted
-				delete(f.Flags, k)
+				f.Delete(k)
 				notifications[k] = map[string]interface{}{
 					"type":   string(model.NotificationDelete),
 					"source": source,
@@ -232,12 +259,11 @@ func (f *Flags) Merge(
 			}
 		}
 	}
-	f.mx.Unlock()
 	for k, newFlag := range flags {
 		newFlag.Source = source
 		storedFlag, ok := f.Get(k)
 		if ok {
-			if !f.hasPriority(storedFlag.Source, source) {
+			if !f.resolveDuplicate(logger, k, storedFlag.Source, source) {
 				logger.Debug(
 					fmt.Sprintf(
 						"not merging: flag %s from source %s does not have priority over %s",
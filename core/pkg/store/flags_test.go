@@ -83,58 +83,48 @@ func TestMergeFlags(t *testing.T) {
 		wantResync bool
 	}{
 		{
-			name: "both nil",
-			current: &Flags{
-				Flags: nil,
-			},
+			name:       "both nil",
+			current:    newTestFlags(nil),
 			new:        nil,
-			want:       &Flags{Flags: map[string]model.Flag{}},
+			want:       newTestFlags(map[string]model.Flag{}),
 			wantNotifs: map[string]interface{}{},
 		},
 		{
-			name: "both empty flags",
-			current: &Flags{
-				Flags: map[string]model.Flag{},
-			},
+			name:       "both empty flags",
+			current:    newTestFlags(map[string]model.Flag{}),
 			new:        map[string]model.Flag{},
-			want:       &Flags{Flags: map[string]model.Flag{}},
+			want:       newTestFlags(map[string]model.Flag{}),
 			wantNotifs: map[string]interface{}{},
 		},
 		{
-			name: "empty current",
-			current: &Flags{
-				Flags: nil,
-			},
+			name:       "empty current",
+			current:    newTestFlags(nil),
 			new:        map[string]model.Flag{},
-			want:       &Flags{Flags: map[string]model.Flag{}},
+			want:       newTestFlags(map[string]model.Flag{}),
 			wantNotifs: map[string]interface{}{},
 		},
 		{
-			name: "empty new",
-			current: &Flags{
-				Flags: map[string]model.Flag{},
-			},
+			name:       "empty new",
+			current:    newTestFlags(map[string]model.Flag{}),
 			new:        nil,
-			want:       &Flags{Flags: map[string]model.Flag{}},
+			want:       newTestFlags(map[string]model.Flag{}),
 			wantNotifs: map[string]interface{}{},
 		},
 		{
 			name: "extra fields on each",
-			current: &Flags{
-				Flags: map[string]model.Flag{
-					"waka": {
-						DefaultVariant: "off",
-						Source:         "1",
-					},
+			current: newTestFlags(map[string]model.Flag{
+				"waka": {
+					DefaultVariant: "off",
+					Source:         "1",
 				},
-			},
+			}),
 			new: map[string]model.Flag{
 				"paka": {
 					DefaultVariant: "on",
 				},
 			},
 			newSource: "2",
-			want: &Flags{Flags: map[string]model.Flag{
+			want: newTestFlags(map[string]model.Flag{
 				"waka": {
 					DefaultVariant: "off",
 					Source:         "1",
@@ -143,50 +133,44 @@ func TestMergeFlags(t *testing.T) {
 					DefaultVariant: "on",
 					Source:         "2",
 				},
-			}},
+			}),
 			wantNotifs: map[string]interface{}{
 				"paka": map[string]interface{}{"type": "write", "source": "2"},
 			},
 		},
 		{
-			name: "override",
-			current: &Flags{
-				Flags: map[string]model.Flag{"waka": {DefaultVariant: "off"}},
-			},
+			name:    "override",
+			current: newTestFlags(map[string]model.Flag{"waka": {DefaultVariant: "off"}}),
 			new: map[string]model.Flag{
 				"waka": {DefaultVariant: "on"},
 				"paka": {DefaultVariant: "on"},
 			},
-			want: &Flags{Flags: map[string]model.Flag{
+			want: newTestFlags(map[string]model.Flag{
 				"waka": {DefaultVariant: "on"},
 				"paka": {DefaultVariant: "on"},
-			}},
+			}),
 			wantNotifs: map[string]interface{}{
 				"waka": map[string]interface{}{"type": "update", "source": ""},
 				"paka": map[string]interface{}{"type": "write", "source": ""},
 			},
 		},
 		{
-			name: "identical",
-			current: &Flags{
-				Flags: map[string]model.Flag{"hello": {DefaultVariant: "off"}},
-			},
+			name:    "identical",
+			current: newTestFlags(map[string]model.Flag{"hello": {DefaultVariant: "off"}}),
 			new: map[string]model.Flag{
 				"hello": {DefaultVariant: "off"},
 			},
-			want: &Flags{Flags: map[string]model.Flag{
+			want: newTestFlags(map[string]model.Flag{
 				"hello": {DefaultVariant: "off"},
-			}},
+			}),
 			wantNotifs: map[string]interface{}{},
 		},
 		{
-			name: "deleted flag",
-			current: &Flags{
-				Flags: map[string]model.Flag{"hello": {DefaultVariant: "off", Source: "A"}},
-			},
+			name:      "deleted flag",
+			current:   newTestFlags(map[string]model.Flag{"hello": {DefaultVariant: "off", Source: "A"}}),
 			new:       map[string]model.Flag{},
 			newSource: "A",
-			want:      &Flags{Flags: map[string]model.Flag{}},
+			want:      newTestFlags(map[string]model.Flag{}),
 			wantNotifs: map[string]interface{}{
 				"hello": map[string]interface{}{"type": "delete", "source": "A"},
 			},
@@ -194,34 +178,28 @@ func TestMergeFlags(t *testing.T) {
 		},
 		{
 			name: "no merge priority",
-			current: &Flags{
-				FlagSources: []string{
-					"B",
-					"A",
-				},
-				Flags: map[string]model.Flag{
-					"hello": {
-						DefaultVariant: "off",
-						Source:         "A",
-					},
+			current: newTestFlags(map[string]model.Flag{
+				"hello": {
+					DefaultVariant: "off",
+					Source:         "A",
 				},
-			},
+			}, []string{
+				"B",
+				"A",
+			}...),
 			new: map[string]model.Flag{
 				"hello": {DefaultVariant: "off"},
 			},
 			newSource: "B",
-			want: &Flags{
-				FlagSources: []string{
-					"B",
-					"A",
-				},
-				Flags: map[string]model.Flag{
-					"hello": {
-						DefaultVariant: "off",
-						Source:         "A",
-					},
+			want: newTestFlags(map[string]model.Flag{
+				"hello": {
+					DefaultVariant: "off",
+					Source:         "A",
 				},
-			},
+			}, []string{
+				"B",
+				"A",
+			}...),
 			wantNotifs: map[string]interface{}{},
 		},
 	}
@@ -257,32 +235,26 @@ func TestFlags_Add(t *testing.T) {
 	}{
 		{
 			name: "Add success",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+			}),
 			addRequest: request{
 				source: mockSource,
 				flags: map[string]model.Flag{
 					"B": {Source: mockSource},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+			}),
 			expectedNotificationKeys: []string{"B"},
 		},
 		{
 			name: "Add multiple success",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+			}),
 			addRequest: request{
 				source: mockSource,
 				flags: map[string]model.Flag{
@@ -290,33 +262,27 @@ func TestFlags_Add(t *testing.T) {
 					"C": {Source: mockSource},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-					"C": {Source: mockSource},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+				"C": {Source: mockSource},
+			}),
 			expectedNotificationKeys: []string{"B", "C"},
 		},
 		{
 			name: "Add success - conflict and override",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+			}),
 			addRequest: request{
 				source: mockOverrideSource,
 				flags: map[string]model.Flag{
 					"A": {Source: mockOverrideSource},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockOverrideSource},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockOverrideSource},
+			}),
 			expectedNotificationKeys: []string{"A"},
 		},
 	}
@@ -353,32 +319,26 @@ func TestFlags_Update(t *testing.T) {
 	}{
 		{
 			name: "Update success",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource, DefaultVariant: "True"},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource, DefaultVariant: "True"},
+			}),
 			UpdateRequest: request{
 				source: mockSource,
 				flags: map[string]model.Flag{
 					"A": {Source: mockSource, DefaultVariant: "False"},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource, DefaultVariant: "False"},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource, DefaultVariant: "False"},
+			}),
 			expectedNotificationKeys: []string{"A"},
 		},
 		{
 			name: "Update multiple success",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource, DefaultVariant: "True"},
-					"B": {Source: mockSource, DefaultVariant: "True"},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource, DefaultVariant: "True"},
+				"B": {Source: mockSource, DefaultVariant: "True"},
+			}),
 			UpdateRequest: request{
 				source: mockSource,
 				flags: map[string]model.Flag{
@@ -386,52 +346,42 @@ func TestFlags_Update(t *testing.T) {
 					"B": {Source: mockSource, DefaultVariant: "False"},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource, DefaultVariant: "False"},
-					"B": {Source: mockSource, DefaultVariant: "False"},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource, DefaultVariant: "False"},
+				"B": {Source: mockSource, DefaultVariant: "False"},
+			}),
 			expectedNotificationKeys: []string{"A", "B"},
 		},
 		{
 			name: "Update success - conflict and override",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource, DefaultVariant: "True"},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource, DefaultVariant: "True"},
+			}),
 			UpdateRequest: request{
 				source: mockOverrideSource,
 				flags: map[string]model.Flag{
 					"A": {Source: mockOverrideSource, DefaultVariant: "True"},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockOverrideSource, DefaultVariant: "True"},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockOverrideSource, DefaultVariant: "True"},
+			}),
 			expectedNotificationKeys: []string{"A"},
 		},
 		{
 			name: "Update fail",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+			}),
 			UpdateRequest: request{
 				source: mockSource,
 				flags: map[string]model.Flag{
 					"B": {Source: mockSource},
 				},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+			}),
 			expectedNotificationKeys: []string{},
 		},
 	}
@@ -464,76 +414,60 @@ func TestFlags_Delete(t *testing.T) {
 	}{
 		{
 			name: "Remove success",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-					"C": {Source: mockSource2},
-				},
-				FlagSources: []string{
-					mockSource,
-					mockSource2,
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+				"C": {Source: mockSource2},
+			}, []string{
+				mockSource,
+				mockSource2,
+			}...),
 			deleteRequest: map[string]model.Flag{
 				"A": {Source: mockSource},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"B": {Source: mockSource},
-					"C": {Source: mockSource2},
-				},
-				FlagSources: []string{
-					mockSource,
-					mockSource2,
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"B": {Source: mockSource},
+				"C": {Source: mockSource2},
+			}, []string{
+				mockSource,
+				mockSource2,
+			}...),
 			expectedNotificationKeys: []string{"A"},
 		},
 		{
 			name: "Nothing to remove",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-					"C": {Source: mockSource2},
-				},
-				FlagSources: []string{
-					mockSource,
-					mockSource2,
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+				"C": {Source: mockSource2},
+			}, []string{
+				mockSource,
+				mockSource2,
+			}...),
 			deleteRequest: map[string]model.Flag{
 				"C": {Source: mockSource},
 			},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-					"C": {Source: mockSource2},
-				},
-				FlagSources: []string{
-					mockSource,
-					mockSource2,
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+				"C": {Source: mockSource2},
+			}, []string{
+				mockSource,
+				mockSource2,
+			}...),
 			expectedNotificationKeys: []string{},
 		},
 		{
 			name: "Remove all",
-			storedState: &Flags{
-				Flags: map[string]model.Flag{
-					"A": {Source: mockSource},
-					"B": {Source: mockSource},
-					"C": {Source: mockSource2},
-				},
-			},
+			storedState: newTestFlags(map[string]model.Flag{
+				"A": {Source: mockSource},
+				"B": {Source: mockSource},
+				"C": {Source: mockSource2},
+			}),
 			deleteRequest: map[string]model.Flag{},
-			expectedState: &Flags{
-				Flags: map[string]model.Flag{
-					"C": {Source: mockSource2},
-				},
-			},
+			expectedState: newTestFlags(map[string]model.Flag{
+				"C": {Source: mockSource2},
+			}),
 			expectedNotificationKeys: []string{"A", "B"},
 		},
 	}
@@ -550,3 +484,13 @@ func TestFlags_Delete(t *testing.T) {
 		})
 	}
 }
+
+// newTestFlags returns in memory Flags holding the flags, merged from the sources in order of priority
+func newTestFlags(flags map[string]model.Flag, sources ...string) *Flags {
+	f := NewFlags()
+	f.FlagSources = sources
+	for key, flag := range flags {
+		f.Set(key, flag)
+	}
+	return f
+}
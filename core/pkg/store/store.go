@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// watchBufferSize bounds the changes buffered for each watcher of a MemoryStore
+const watchBufferSize = 64
+
+// Store is the storage backend of flag definitions, keyed by flag key. Implementations must be safe for concurrent
+// use, the merging of sources by priority is left to Flags.
+type Store interface {
+	Get(key string) (model.Flag, bool)
+	// List returns a copy of the stored flags
+	List() map[string]model.Flag
+	Set(key string, flag model.Flag)
+	Delete(key string)
+	// Watch returns a channel receiving the changes of the stored flags, it's closed once the context is done.
+	// Implementations may close it earlier for watchers falling behind the changes, such watchers missed changes and
+	// should List the flags again before watching anew.
+	Watch(ctx context.Context) <-chan Change
+}
+
+// Change describes the modification of a stored flag
+type Change struct {
+	Key  string
+	Type model.StateChangeNotificationType
+}
+
+// MemoryStore is the default Store, holding the flags in memory
+type MemoryStore struct {
+	mx       sync.RWMutex
+	flags    map[string]model.Flag
+	watchers map[chan Change]struct{}
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{flags: map[string]model.Flag{}}
+}
+
+func (m *MemoryStore) Get(key string) (model.Flag, bool) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	flag, ok := m.flags[key]
+	return flag, ok
+}
+
+func (m *MemoryStore) List() map[string]model.Flag {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	flags := make(map[string]model.Flag, len(m.flags))
+	for key, flag := range m.flags {
+		flags[key] = flag
+	}
+	return flags
+}
+
+func (m *MemoryStore) Set(key string, flag model.Flag) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	change := Change{Key: key, Type: model.NotificationCreate}
+	if _, ok := m.flags[key]; ok {
+		change.Type = model.NotificationUpdate
+	}
+	m.flags[key] = flag
+	m.notify(change)
+}
+
+func (m *MemoryStore) Delete(key string) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if _, ok := m.flags[key]; !ok {
+		return
+	}
+	delete(m.flags, key)
+	m.notify(Change{Key: key, Type: model.NotificationDelete})
+}
+
+// Watch returns a channel receiving the changes of the stored flags. Writes don't wait for watchers, the channel of a
+// watcher more than watchBufferSize changes behind is closed in place of dropping changes.
+func (m *MemoryStore) Watch(ctx context.Context) <-chan Change {
+	changes := make(chan Change, watchBufferSize)
+	m.mx.Lock()
+	if m.watchers == nil {
+		m.watchers = map[chan Change]struct{}{}
+	}
+	m.watchers[changes] = struct{}{}
+	m.mx.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		m.unwatch(changes)
+	}()
+	return changes
+}
+
+// notify sends the change to the watchers, the write lock must be held
+func (m *MemoryStore) notify(change Change) {
+	for watcher := range m.watchers {
+		select {
+		case watcher <- change:
+		default:
+			m.unwatch(watcher)
+		}
+	}
+}
+
+// unwatch closes the channel of the watcher unless it's already closed, the write lock must be held
+func (m *MemoryStore) unwatch(watcher chan Change) {
+	if _, ok := m.watchers[watcher]; !ok {
+		return
+	}
+	delete(m.watchers, watcher)
+	close(watcher)
+}
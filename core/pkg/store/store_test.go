@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the behaviour tests of the Store interface against the stores returned by newStore
+func testStore(t *testing.T, newStore func() Store) {
+	t.Run("get set delete", func(t *testing.T) {
+		s := newStore()
+		_, ok := s.Get("hello")
+		require.False(t, ok)
+
+		s.Set("hello", model.Flag{DefaultVariant: "off"})
+		flag, ok := s.Get("hello")
+		require.True(t, ok)
+		require.Equal(t, model.Flag{DefaultVariant: "off"}, flag)
+
+		s.Set("hello", model.Flag{DefaultVariant: "on"})
+		flag, _ = s.Get("hello")
+		require.Equal(t, "on", flag.DefaultVariant)
+
+		s.Delete("hello")
+		_, ok = s.Get("hello")
+		require.False(t, ok)
+		s.Delete("hello")
+	})
+
+	t.Run("list copies the flags", func(t *testing.T) {
+		s := newStore()
+		require.Empty(t, s.List())
+		s.Set("a", model.Flag{DefaultVariant: "off"})
+		s.Set("b", model.Flag{DefaultVariant: "on"})
+
+		flags := s.List()
+		require.Equal(t, map[string]model.Flag{
+			"a": {DefaultVariant: "off"},
+			"b": {DefaultVariant: "on"},
+		}, flags)
+		delete(flags, "a")
+		_, ok := s.Get("a")
+		require.True(t, ok, "modifying the list mustn't modify the store")
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		s := newStore()
+		ctx, cancel := context.WithCancel(context.Background())
+		changes := s.Watch(ctx)
+
+		s.Set("hello", model.Flag{DefaultVariant: "off"})
+		s.Set("hello", model.Flag{DefaultVariant: "on"})
+		s.Delete("hello")
+		for _, want := range []Change{
+			{Key: "hello", Type: model.NotificationCreate},
+			{Key: "hello", Type: model.NotificationUpdate},
+			{Key: "hello", Type: model.NotificationDelete},
+		} {
+			select {
+			case change := <-changes:
+				require.Equal(t, want, change)
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for change %v", want)
+			}
+		}
+
+		cancel()
+		select {
+		case _, ok := <-changes:
+			require.False(t, ok, "the channel should be closed once the context is done")
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the channel to close")
+		}
+	})
+
+	t.Run("watch overflow", func(t *testing.T) {
+		s := newStore()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := s.Watch(ctx)
+		other := s.Watch(ctx)
+
+		for i := 0; i <= watchBufferSize; i++ {
+			s.Set(fmt.Sprintf("flag-%d", i), model.Flag{DefaultVariant: "off"})
+			<-other
+		}
+		received := 0
+		for range changes {
+			received++
+		}
+		require.Equal(t, watchBufferSize, received, "the buffered changes should be received before the close")
+		s.Set("flag-0", model.Flag{DefaultVariant: "on"})
+		select {
+		case change, ok := <-other:
+			require.True(t, ok, "watchers keeping up shouldn't be closed")
+			require.Equal(t, Change{Key: "flag-0", Type: model.NotificationUpdate}, change)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the change of the watcher keeping up")
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func() Store {
+		return NewMemoryStore()
+	})
+}
+
+func TestNewFlagsWithStore(t *testing.T) {
+	backend := NewMemoryStore()
+	f := NewFlagsWithStore(backend)
+	f.FlagSources = []string{"A", "B"}
+
+	f.Merge(logger.NewLogger(nil, false), "A", map[string]model.Flag{"hello": {DefaultVariant: "off"}})
+	f.Merge(logger.NewLogger(nil, false), "B", map[string]model.Flag{"hello": {DefaultVariant: "on"}})
+	require.Equal(t, map[string]model.Flag{"hello": {DefaultVariant: "on", Source: "B"}}, backend.List())
+	require.Equal(t, backend, f.Store())
+
+	state, err := f.String()
+	require.Nil(t, err)
+	require.JSONEq(t, `{
+  "flags": { "hello": { "state": "", "defaultVariant": "on", "variants": null, "source": "B" } },
+  "flagSources": ["A", "B"]
+}`, state)
+}
@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Connectivity is implemented by sync providers of remote sources, reporting whether the source is reachable
+type Connectivity interface {
+	// DisconnectedSince returns when the source became unreachable, the zero time while it is reachable
+	DisconnectedSince() time.Time
+}
+
+// ConnectivityTracker records since when a source is unreachable, it is safe for concurrent use
+type ConnectivityTracker struct {
+	// since holds the time.Time the source became unreachable, the zero time while it is reachable
+	since atomic.Value
+}
+
+// Disconnected records the source as unreachable at the time, unless it already is
+func (c *ConnectivityTracker) Disconnected(at time.Time) {
+	if c.DisconnectedSince().IsZero() {
+		c.since.Store(at)
+	}
+}
+
+// Connected records the source as reachable
+func (c *ConnectivityTracker) Connected() {
+	c.since.Store(time.Time{})
+}
+
+func (c *ConnectivityTracker) DisconnectedSince() time.Time {
+	since, _ := c.since.Load().(time.Time)
+	return since
+}
@@ -0,0 +1,278 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+const (
+	// defaultStartupTimeout bounds the time a source takes to load its first configuration, unless configured
+	// otherwise
+	defaultStartupTimeout = 10 * time.Second
+	// defaultRetryInterval is the interval sources preferred over the active source are retried at, unless configured
+	// otherwise
+	defaultRetryInterval = time.Minute
+)
+
+// Source is a source of an ordered list of sources, its URI identifies it in logs
+type Source struct {
+	URI  string
+	Sync sync.ISync
+}
+
+// Sync syncs the configuration of the first of its sources loading one, falling back to the next source when the
+// active source fails or doesn't load its configuration within StartupTimeout. While a fallback is active, the sources
+// preferred over it are retried every RetryInterval, the first of them loading its configuration becomes active again.
+//
+// The data syncs of every source are sent as those of URI, so switching sources replaces the flags of the previously
+// active source.
+type Sync struct {
+	URI            string
+	Sources        []Source
+	Logger         *logger.Logger
+	StartupTimeout time.Duration
+	RetryInterval  time.Duration
+
+	mu          msync.Mutex
+	active      *attempt
+	initialized map[int]bool
+}
+
+// attempt is a running sync of a source
+type attempt struct {
+	index  int
+	cancel context.CancelFunc
+	data   chan sync.DataSync
+	// done receives the result of the sync, it's closed once the sync returned
+	done chan error
+}
+
+// Init validates the sources, each source is initialized before it's first synced
+func (fs *Sync) Init(ctx context.Context) error {
+	if len(fs.Sources) == 0 {
+		return errors.New("no failover sources set")
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.initialized = map[int]bool{}
+	return nil
+}
+
+// IsReady returns whether the active source is ready
+func (fs *Sync) IsReady() bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.active != nil && fs.Sources[fs.active.index].Sync.IsReady()
+}
+
+// DisconnectedSince returns when the active source became unreachable, the zero time while it's reachable or doesn't
+// track its connectivity
+func (fs *Sync) DisconnectedSince() time.Time {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.active == nil {
+		return time.Time{}
+	}
+	if c, ok := fs.Sources[fs.active.index].Sync.(sync.Connectivity); ok {
+		return c.DisconnectedSince()
+	}
+	return time.Time{}
+}
+
+// Sync syncs the first source loading its configuration until the context is done, failing if no source loads one
+func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	active, data, ok := fs.activate(ctx, len(fs.Sources))
+	if !ok {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("none of the sources of %s loaded a configuration", fs.URI)
+	}
+	fs.forward(ctx, dataSync, data)
+	retry := time.NewTicker(fs.retryInterval())
+	defer retry.Stop()
+	for {
+		select {
+		case data := <-active.data:
+			fs.forward(ctx, dataSync, data)
+		case err := <-active.done:
+			active.cancel()
+			if ctx.Err() != nil {
+				return nil
+			}
+			fs.Logger.Warn(fmt.Sprintf("source %s of %s failed, falling back: %v",
+				fs.Sources[active.index].URI, fs.URI, failure(err)))
+			if active, data, ok = fs.activate(ctx, len(fs.Sources)); !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("none of the sources of %s loaded a configuration", fs.URI)
+			}
+			fs.forward(ctx, dataSync, data)
+		case <-retry.C:
+			if active.index == 0 {
+				continue
+			}
+			// only the sources preferred over the active one are retried
+			preferred, data, ok := fs.activate(ctx, active.index)
+			if !ok {
+				continue
+			}
+			active.stop()
+			active = preferred
+			fs.forward(ctx, dataSync, data)
+		case <-ctx.Done():
+			active.stop()
+			return nil
+		}
+	}
+}
+
+// ReSync resyncs the active source
+func (fs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	fs.mu.Lock()
+	active := fs.active
+	fs.mu.Unlock()
+	if active == nil {
+		return nil
+	}
+	data := make(chan sync.DataSync, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- fs.Sources[active.index].Sync.ReSync(ctx, data)
+	}()
+	for {
+		select {
+		case d := <-data:
+			fs.forward(ctx, dataSync, d)
+		case err := <-done:
+			// the data synced before ReSync returned is still forwarded
+			for {
+				select {
+				case d := <-data:
+					fs.forward(ctx, dataSync, d)
+				default:
+					return err
+				}
+			}
+		}
+	}
+}
+
+// activate syncs the first of the sources before last loading its configuration, returning its first data sync
+func (fs *Sync) activate(ctx context.Context, last int) (*attempt, sync.DataSync, bool) {
+	for index := 0; index < last && ctx.Err() == nil; index++ {
+		a := fs.start(ctx, index)
+		data, err := fs.loaded(ctx, a)
+		if err != nil {
+			a.stop()
+			if ctx.Err() == nil {
+				fs.Logger.Warn(fmt.Sprintf("source %s of %s didn't load a configuration: %v",
+					fs.Sources[index].URI, fs.URI, err))
+			}
+			continue
+		}
+		fs.mu.Lock()
+		fs.active = a
+		fs.mu.Unlock()
+		fs.Logger.Info(fmt.Sprintf("syncing %s from source %s", fs.URI, fs.Sources[index].URI))
+		return a, data, true
+	}
+	return nil, sync.DataSync{}, false
+}
+
+// start initializes the source unless it already is and syncs it
+func (fs *Sync) start(ctx context.Context, index int) *attempt {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	a := &attempt{index: index, cancel: cancel, data: make(chan sync.DataSync, 1), done: make(chan error, 1)}
+	source := fs.Sources[index].Sync
+	go func() {
+		defer close(a.done)
+		if err := fs.init(attemptCtx, index); err != nil {
+			a.done <- err
+			return
+		}
+		a.done <- source.Sync(attemptCtx, a.data)
+	}()
+	return a
+}
+
+func (fs *Sync) init(ctx context.Context, index int) error {
+	fs.mu.Lock()
+	initialized := fs.initialized[index]
+	fs.mu.Unlock()
+	if initialized {
+		return nil
+	}
+	if err := fs.Sources[index].Sync.Init(ctx); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	fs.mu.Lock()
+	fs.initialized[index] = true
+	fs.mu.Unlock()
+	return nil
+}
+
+// loaded waits for the first data sync of the attempt, failing if its sync returned or timed out first
+func (fs *Sync) loaded(ctx context.Context, a *attempt) (sync.DataSync, error) {
+	timeout := time.NewTimer(fs.startupTimeout())
+	defer timeout.Stop()
+	select {
+	case data := <-a.data:
+		return data, nil
+	case err := <-a.done:
+		return sync.DataSync{}, failure(err)
+	case <-timeout.C:
+		return sync.DataSync{}, fmt.Errorf("no configuration within %s", fs.startupTimeout())
+	case <-ctx.Done():
+		return sync.DataSync{}, ctx.Err()
+	}
+}
+
+// stop cancels the sync of the attempt, discarding the data it still sends until it returns
+func (a *attempt) stop() {
+	a.cancel()
+	go func() {
+		for {
+			select {
+			case <-a.data:
+			case <-a.done:
+				return
+			}
+		}
+	}()
+}
+
+// forward sends the data sync as a data sync of the failover source
+func (fs *Sync) forward(ctx context.Context, dataSync chan<- sync.DataSync, data sync.DataSync) {
+	data.Source = fs.URI
+	sync.Send(ctx, dataSync, data)
+}
+
+func (fs *Sync) startupTimeout() time.Duration {
+	if fs.StartupTimeout <= 0 {
+		return defaultStartupTimeout
+	}
+	return fs.StartupTimeout
+}
+
+func (fs *Sync) retryInterval() time.Duration {
+	if fs.RetryInterval <= 0 {
+		return defaultRetryInterval
+	}
+	return fs.RetryInterval
+}
+
+// failure describes the result of a sync which returned, a sync returning without an error stopped nonetheless
+func failure(err error) error {
+	if err == nil {
+		return errors.New("sync stopped")
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	msync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeSource sends its configuration once synced, unless it's down
+type fakeSource struct {
+	uri    string
+	config string
+	// down fails the syncs starting while it's set, hang delays them until their context is done instead
+	down  atomic.Bool
+	hang  atomic.Bool
+	syncs atomic.Int32
+	mu    msync.Mutex
+	fail  chan error
+}
+
+func newFakeSource(uri string, config string) *fakeSource {
+	return &fakeSource{uri: uri, config: config, fail: make(chan error, 1)}
+}
+
+func (f *fakeSource) Init(ctx context.Context) error { return nil }
+
+func (f *fakeSource) IsReady() bool { return f.syncs.Load() > 0 && !f.down.Load() }
+
+func (f *fakeSource) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	if f.down.Load() {
+		return errors.New("unreachable")
+	}
+	if f.hang.Load() {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	f.syncs.Add(1)
+	dataSync <- sync.DataSync{FlagData: f.config, Source: f.uri, Type: sync.ALL}
+	select {
+	case err := <-f.fail:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (f *fakeSource) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- sync.DataSync{FlagData: f.config, Source: f.uri, Type: sync.ALL}
+	return nil
+}
+
+func newFailover(log *logger.Logger, sources ...*fakeSource) *Sync {
+	fs := &Sync{
+		URI:            sources[0].uri,
+		Logger:         log,
+		StartupTimeout: 50 * time.Millisecond,
+		RetryInterval:  20 * time.Millisecond,
+	}
+	for _, source := range sources {
+		fs.Sources = append(fs.Sources, Source{URI: source.uri, Sync: source})
+	}
+	return fs
+}
+
+func runFailover(t *testing.T, fs *Sync) (chan sync.DataSync, chan error, context.CancelFunc) {
+	t.Helper()
+	require.Nil(t, fs.Init(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	dataSync := make(chan sync.DataSync, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- fs.Sync(ctx, dataSync)
+	}()
+	return dataSync, done, cancel
+}
+
+func receive(t *testing.T, dataSync chan sync.DataSync) sync.DataSync {
+	t.Helper()
+	select {
+	case data := <-dataSync:
+		return data
+	case <-time.After(time.Second):
+		require.Fail(t, "no data sync received")
+		return sync.DataSync{}
+	}
+}
+
+func TestFailoverSync_PrimaryRecovery(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	primary := newFakeSource("https://flags.example.com/flags.json", "primary")
+	backup := newFakeSource("backup.json", "backup")
+	primary.down.Store(true)
+	fs := newFailover(logger.NewLogger(zap.New(core), false), primary, backup)
+	dataSync, done, cancel := runFailover(t, fs)
+
+	require.Equal(t, sync.DataSync{FlagData: "backup", Source: primary.uri, Type: sync.ALL}, receive(t, dataSync),
+		"the backup must be synced as the primary source")
+	require.True(t, fs.IsReady())
+	require.Equal(t, 1, logs.FilterMessage("syncing https://flags.example.com/flags.json from source backup.json").Len())
+
+	require.Nil(t, fs.ReSync(context.Background(), dataSync))
+	require.Equal(t, "backup", receive(t, dataSync).FlagData, "resyncs must resync the active source")
+
+	primary.down.Store(false)
+	require.Equal(t, sync.DataSync{FlagData: "primary", Source: primary.uri, Type: sync.ALL}, receive(t, dataSync),
+		"the primary must be retried while the backup is active")
+	require.Equal(t, 1, logs.FilterMessage("syncing https://flags.example.com/flags.json from source "+
+		"https://flags.example.com/flags.json").Len())
+
+	primary.down.Store(true)
+	primary.fail <- errors.New("connection reset")
+	require.Equal(t, "backup", receive(t, dataSync).FlagData, "a failing active source must fall back")
+
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestFailoverSync_StartupTimeout(t *testing.T) {
+	primary := newFakeSource("grpc://flags.example.com", "primary")
+	backup := newFakeSource("backup.json", "backup")
+	primary.hang.Store(true)
+	fs := newFailover(logger.NewLogger(nil, false), primary, backup)
+	fs.RetryInterval = time.Hour
+	dataSync, done, cancel := runFailover(t, fs)
+
+	require.Equal(t, "backup", receive(t, dataSync).FlagData, "a source loading no configuration must fall back")
+
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestFailoverSync_NoSourceLoads(t *testing.T) {
+	primary := newFakeSource("https://flags.example.com/flags.json", "primary")
+	backup := newFakeSource("backup.json", "backup")
+	primary.down.Store(true)
+	backup.down.Store(true)
+	fs := newFailover(logger.NewLogger(nil, false), primary, backup)
+	_, done, cancel := runFailover(t, fs)
+	defer cancel()
+
+	require.EqualError(t, <-done,
+		"none of the sources of https://flags.example.com/flags.json loaded a configuration")
+	require.False(t, fs.IsReady())
+}
+
+func TestFailoverSync_NoSources(t *testing.T) {
+	require.NotNil(t, (&Sync{}).Init(context.Background()))
+}
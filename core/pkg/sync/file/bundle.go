@@ -0,0 +1,199 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxBundleBytes bounds the extracted size of the flag files of a bundle
+const maxBundleBytes = 64 << 20
+
+// errUnverified marks bundles whose signature verification failed, they're dropped rather than applied
+var errUnverified = errors.New("bundle verification failed")
+
+// IsBundle reports whether the URI is a tar bundle of flag files, optionally gzip compressed
+func IsBundle(uri string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(uri, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleFile is a flag file of a bundle
+type bundleFile struct {
+	name    string
+	content []byte
+}
+
+// readBundle extracts the json and yaml flag files of the bundle, merging them into a single configuration. Files
+// are merged in the lexical order of their paths, other files are ignored.
+func readBundle(raw []byte, gzipped bool) (string, error) {
+	var r io.Reader = bytes.NewReader(raw)
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", fmt.Errorf("decompress bundle: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	files, err := bundleFiles(tar.NewReader(r))
+	if err != nil {
+		return "", err
+	}
+	return mergeBundleFiles(files)
+}
+
+func bundleFiles(tr *tar.Reader) ([]bundleFile, error) {
+	var files []bundleFile
+	var size int64
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		base := path.Base(header.Name)
+		if header.Typeflag != tar.TypeReg || strings.HasPrefix(base, ".") {
+			continue
+		}
+		switch path.Ext(base) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if size += header.Size; size > maxBundleBytes {
+			return nil, fmt.Errorf("bundle exceeds %d bytes of flag files", maxBundleBytes)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, header.Size))
+		if err != nil {
+			return nil, fmt.Errorf("read %s of bundle: %w", header.Name, err)
+		}
+		files = append(files, bundleFile{name: header.Name, content: content})
+	}
+	if len(files) == 0 {
+		return nil, errors.New("bundle doesn't contain any json or yaml flag file")
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+// mergeBundleFiles concatenates the flags of the files, keys defined by several files are repeated in the merged
+// configuration so the duplicate flag key policy resolves them as keys defined more than once by a source. Shared
+// evaluators and base contexts must be uniquely named, or defined identically.
+func mergeBundleFiles(files []bundleFile) (string, error) {
+	var flags bytes.Buffer
+	evaluators := map[string]json.RawMessage{}
+	evaluatorFiles := map[string]string{}
+	baseContexts := map[string]json.RawMessage{}
+	baseContextFiles := map[string]string{}
+	for _, f := range files {
+		content := f.content
+		if ext := path.Ext(f.name); ext == ".yaml" || ext == ".yml" {
+			converted, err := yamlToJSON(content)
+			if err != nil {
+				return "", fmt.Errorf("%s of bundle: %w", f.name, err)
+			}
+			content = []byte(converted)
+		}
+		var config struct {
+			Flags        json.RawMessage            `json:"flags"`
+			Evaluators   map[string]json.RawMessage `json:"$evaluators"`
+			BaseContexts map[string]json.RawMessage `json:"$baseContexts"`
+		}
+		if err := json.Unmarshal(content, &config); err != nil {
+			return "", fmt.Errorf("%s of bundle: %w", f.name, err)
+		}
+		if err := appendObjectMembers(&flags, config.Flags); err != nil {
+			return "", fmt.Errorf("flags of %s of bundle: %w", f.name, err)
+		}
+		for name, evaluator := range config.Evaluators {
+			if defined, ok := evaluators[name]; ok && !bytes.Equal(compactJSON(defined), compactJSON(evaluator)) {
+				return "", fmt.Errorf("evaluator: '%s' of %s of bundle is already defined by %s",
+					name, f.name, evaluatorFiles[name])
+			}
+			evaluators[name], evaluatorFiles[name] = evaluator, f.name
+		}
+		for name, baseContext := range config.BaseContexts {
+			if defined, ok := baseContexts[name]; ok && !bytes.Equal(compactJSON(defined), compactJSON(baseContext)) {
+				return "", fmt.Errorf("base context: '%s' of %s of bundle is already defined by %s",
+					name, f.name, baseContextFiles[name])
+			}
+			baseContexts[name], baseContextFiles[name] = baseContext, f.name
+		}
+	}
+
+	merged := bytes.NewBufferString(`{"flags":{`)
+	merged.Write(flags.Bytes())
+	merged.WriteString("}")
+	if len(evaluators) > 0 {
+		raw, err := json.Marshal(evaluators)
+		if err != nil {
+			return "", fmt.Errorf("merge evaluators of bundle: %w", err)
+		}
+		merged.WriteString(`,"$evaluators":`)
+		merged.Write(raw)
+	}
+	if len(baseContexts) > 0 {
+		raw, err := json.Marshal(baseContexts)
+		if err != nil {
+			return "", fmt.Errorf("merge base contexts of bundle: %w", err)
+		}
+		merged.WriteString(`,"$baseContexts":`)
+		merged.Write(raw)
+	}
+	merged.WriteString("}")
+	return merged.String(), nil
+}
+
+// appendObjectMembers appends the members of the json object to the buffer, comma separated, keeping repeated keys
+func appendObjectMembers(buf *bytes.Buffer, object json.RawMessage) error {
+	if len(object) == 0 || string(object) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("isn't an object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		key, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(",")
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	return nil
+}
+
+func compactJSON(raw json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,214 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	msync "sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+type bundleEntry struct {
+	name    string
+	content string
+}
+
+var bundleEntries = []bundleEntry{
+	{name: "teams/payments.yaml", content: "flags:\n  checkout:\n    state: DISABLED\n"},
+	{name: "teams/growth.json", content: `{"flags": {"checkout": {"state": "ENABLED"}, "banner": {"state": "ENABLED"}},
+		"$evaluators": {"emailWithFaas": {"in": ["@faas.com", {"var": ["email"]}]}}}`},
+	{name: "README.md", content: "# flags"},
+	{name: "teams/.hidden.json", content: "not json"},
+	{name: "shared.json", content: `{"$evaluators": {"emailWithFaas": {"in": ["@faas.com", {"var": ["email"]}]}}}`},
+}
+
+func writeBundle(t *testing.T, path string, gzipped bool, entries []bundleEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: e.name, Mode: 0o600, Size: int64(len(e.content)), Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gzipped {
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(archive.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if err := gw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		buf = archive
+	}
+	// written to a temporary file then renamed, so watchers never read a partial bundle
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetchBundle(t *testing.T) {
+	const merged = `{"flags":{"checkout":{"state": "ENABLED"},"banner":{"state": "ENABLED"},` +
+		`"checkout":{"state":"DISABLED"}},"$evaluators":{"emailWithFaas":{"in":["@faas.com",{"var":["email"]}]}}}`
+	tests := map[string]struct {
+		fileName string
+		gzipped  bool
+		entries  []bundleEntry
+		want     string
+		wantErr  bool
+	}{
+		"tar": {
+			fileName: "flags.tar",
+			entries:  bundleEntries,
+			want:     merged,
+		},
+		"tar.gz": {
+			fileName: "flags.tar.gz",
+			gzipped:  true,
+			entries:  bundleEntries,
+			want:     merged,
+		},
+		"tgz": {
+			fileName: "flags.tgz",
+			gzipped:  true,
+			entries:  bundleEntries,
+			want:     merged,
+		},
+		"conflicting evaluators": {
+			fileName: "flags.tar",
+			entries: []bundleEntry{
+				{name: "a.json", content: `{"$evaluators": {"rule": {"==": [1, 1]}}}`},
+				{name: "b.json", content: `{"$evaluators": {"rule": {"==": [1, 2]}}}`},
+			},
+			wantErr: true,
+		},
+		"base contexts": {
+			fileName: "flags.tar",
+			entries: []bundleEntry{
+				{name: "a.json", content: `{"flags": {}, "$baseContexts": {"checkout": {"experiment": "checkout"}}}`},
+				{name: "b.json", content: `{"$baseContexts": {"checkout": {"experiment": "checkout"}}}`},
+			},
+			want: `{"flags":{},"$baseContexts":{"checkout":{"experiment":"checkout"}}}`,
+		},
+		"conflicting base contexts": {
+			fileName: "flags.tar",
+			entries: []bundleEntry{
+				{name: "a.json", content: `{"$baseContexts": {"checkout": {"experiment": "checkout"}}}`},
+				{name: "b.json", content: `{"$baseContexts": {"checkout": {"experiment": "banner"}}}`},
+			},
+			wantErr: true,
+		},
+		"no flag file": {
+			fileName: "flags.tar",
+			entries:  []bundleEntry{{name: "README.md", content: "# flags"}},
+			wantErr:  true,
+		},
+		"invalid flag file": {
+			fileName: "flags.tar",
+			entries:  []bundleEntry{{name: "flags.json", content: `{"flags": [`}},
+			wantErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			uri := filepath.Join(t.TempDir(), tt.fileName)
+			writeBundle(t, uri, tt.gzipped, tt.entries)
+			fs := Sync{URI: uri, Logger: logger.NewLogger(nil, false)}
+
+			got, err := fs.fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected merged configuration: %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchBundle_Verification(t *testing.T) {
+	uri := filepath.Join(t.TempDir(), "flags.tar")
+	writeBundle(t, uri, false, bundleEntries)
+	refused := errors.New("invalid signature")
+	fs := Sync{
+		URI:    uri,
+		Logger: logger.NewLogger(nil, false),
+		VerifyBundle: func(_ context.Context, bundle []byte) error {
+			return refused
+		},
+	}
+	if _, err := fs.fetch(context.Background()); !errors.Is(err, errUnverified) {
+		t.Fatalf("expected the bundle to fail verification, got: %v", err)
+	}
+
+	dataSyncChan := make(chan sync.DataSync, 1)
+	fs.sendDataSync(context.Background(), sync.ALL, dataSyncChan)
+	select {
+	case data := <-dataSyncChan:
+		t.Errorf("expected unverified bundles to be dropped, got: %v", data)
+	default:
+	}
+}
+
+func TestBundleSync(t *testing.T) {
+	uri := filepath.Join(t.TempDir(), "flags.tar.gz")
+	writeBundle(t, uri, true, bundleEntries[:1])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataSyncChan := make(chan sync.DataSync, 10)
+	syncHandler := Sync{URI: uri, Logger: logger.NewLogger(nil, false), Mux: &msync.RWMutex{}}
+	if err := syncHandler.Init(ctx); err != nil {
+		t.Fatalf("init sync: %v", err)
+	}
+	go func() {
+		if err := syncHandler.Sync(ctx, dataSyncChan); err != nil {
+			log.Fatalf("Error start sync: %s", err.Error())
+		}
+	}()
+	// the replacement of the bundle may notify several events, the data syncs are awaited until the expected one
+	expectFlagData := func(want string) {
+		t.Helper()
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case data := <-dataSyncChan:
+				if data.FlagData == want && data.Type == sync.ALL && data.Source == uri {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("expected flag data: %s, timeout out after 10 seconds", want)
+			}
+		}
+	}
+	expectFlagData(`{"flags":{"checkout":{"state":"DISABLED"}}}`)
+
+	writeBundle(t, uri, true, []bundleEntry{
+		{name: "banner.json", content: `{"flags": {"banner": {"state": "ENABLED"}}}`},
+		{name: "checkout.json", content: `{"flags": {"checkout": {"state": "ENABLED"}}}`},
+	})
+	expectFlagData(`{"flags":{"banner":{"state": "ENABLED"},"checkout":{"state": "ENABLED"}}}`)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	msync "sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -19,6 +20,12 @@ import (
 type Sync struct {
 	URI    string
 	Logger *logger.Logger
+	// Debounce coalesces the changes of the file within the window into a single reload, reading the file once no
+	// change occurred for the window. Changes are reloaded immediately when 0.
+	Debounce time.Duration
+	// VerifyBundle verifies the content of a bundle source before it's extracted, e.g. against its detached
+	// signature. Bundles failing verification are dropped, leaving the last configuration of the source in place.
+	VerifyBundle func(ctx context.Context, bundle []byte) error
 	// FileType indicates the file type e.g., json, yaml/yml etc.,
 	fileType string
 	watcher  *fsnotify.Watcher
@@ -66,8 +73,13 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	fs.sendDataSync(ctx, sync.ALL, dataSync)
 	fs.setReady(true)
 	fs.Logger.Info(fmt.Sprintf("watching filepath: %s", fs.URI))
+	reload := debouncer{window: fs.Debounce}
+	defer reload.stop()
 	for {
 		select {
+		case <-reload.fired():
+			reload.stop()
+			fs.sendDataSync(ctx, sync.ALL, dataSync)
 		case event, ok := <-fs.watcher.Events:
 			if !ok {
 				fs.Logger.Info("filepath notifier closed")
@@ -77,7 +89,7 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 			fs.Logger.Info(fmt.Sprintf("filepath event: %s %s", event.Name, event.Op.String()))
 			switch {
 			case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
-				fs.sendDataSync(ctx, sync.ALL, dataSync)
+				fs.reload(ctx, &reload, dataSync)
 			case event.Has(fsnotify.Remove):
 				// K8s exposes config maps as symlinks.
 				// Updates cause a remove event, we need to re-add the watcher in this case.
@@ -85,6 +97,7 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 				if err != nil {
 					// the watcher could not be re-added, so the file must have been deleted
 					fs.Logger.Error(fmt.Sprintf("error restoring watcher, file may have been deleted: %s", err.Error()))
+					reload.stop()
 					fs.sendDataSync(ctx, sync.DELETE, dataSync)
 					continue
 				}
@@ -92,12 +105,13 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 				// Counterintuitively, remove events are the only meaningful ones seen in K8s.
 				// K8s handles mounted ConfigMap updates by modifying symbolic links, which is an atomic operation.
 				// At the point the remove event is fired, we have our new data, so we can send it down the channel.
-				fs.sendDataSync(ctx, sync.ALL, dataSync)
+				fs.reload(ctx, &reload, dataSync)
 			case event.Has(fsnotify.Chmod):
 				// on linux the REMOVE event will not fire until all file descriptors are closed, this cannot happen
 				// while the file is being watched, os.Stat is used here to infer deletion
 				if _, err := os.Stat(fs.URI); errors.Is(err, os.ErrNotExist) {
 					fs.Logger.Error(fmt.Sprintf("file has been deleted: %s", err.Error()))
+					reload.stop()
 					fs.sendDataSync(ctx, sync.DELETE, dataSync)
 				}
 			}
@@ -116,12 +130,52 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	}
 }
 
+// reload sends the content of the file, once the debounce window elapsed without further changes if set
+func (fs *Sync) reload(ctx context.Context, reload *debouncer, dataSync chan<- sync.DataSync) {
+	if reload.window <= 0 {
+		fs.sendDataSync(ctx, sync.ALL, dataSync)
+		return
+	}
+	fs.Logger.Debug(fmt.Sprintf("debouncing the reload of %s for %s", fs.URI, reload.window))
+	reload.reset()
+}
+
+// debouncer is a timer restarting on each change, firing once no change occurred for the window
+type debouncer struct {
+	window time.Duration
+	timer  *time.Timer
+}
+
+func (d *debouncer) reset() {
+	d.stop()
+	d.timer = time.NewTimer(d.window)
+}
+
+func (d *debouncer) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// fired returns the channel of the pending timer, a nil channel blocking forever if none is
+func (d *debouncer) fired() <-chan time.Time {
+	if d.timer == nil {
+		return nil
+	}
+	return d.timer.C
+}
+
 func (fs *Sync) sendDataSync(ctx context.Context, syncType sync.Type, dataSync chan<- sync.DataSync) {
 	fs.Logger.Debug(fmt.Sprintf("Configuration %s:  %s", fs.URI, syncType.String()))
 
 	msg := defaultState
 	if syncType != sync.DELETE {
 		m, err := fs.fetch(ctx)
+		if errors.Is(err, errUnverified) {
+			fs.Logger.Error(fmt.Sprintf("refusing the bundle %s: %s", fs.URI, err.Error()))
+			return
+		}
 		if err != nil {
 			fs.Logger.Error(fmt.Sprintf("Error fetching %s: %s", fs.URI, err.Error()))
 		}
@@ -135,10 +189,13 @@ func (fs *Sync) sendDataSync(ctx context.Context, syncType sync.Type, dataSync c
 	dataSync <- sync.DataSync{FlagData: msg, Source: fs.URI, Type: syncType}
 }
 
-func (fs *Sync) fetch(_ context.Context) (string, error) {
+func (fs *Sync) fetch(ctx context.Context) (string, error) {
 	if fs.URI == "" {
 		return "", errors.New("no filepath string set")
 	}
+	if IsBundle(fs.URI) {
+		return fs.fetchBundle(ctx)
+	}
 	if fs.fileType == "" {
 		uriSplit := strings.Split(fs.URI, ".")
 		fs.fileType = uriSplit[len(uriSplit)-1]
@@ -158,6 +215,20 @@ func (fs *Sync) fetch(_ context.Context) (string, error) {
 	}
 }
 
+// fetchBundle reads the bundle, verifying it if VerifyBundle is set, and merges its flag files
+func (fs *Sync) fetchBundle(ctx context.Context) (string, error) {
+	raw, err := os.ReadFile(fs.URI)
+	if err != nil {
+		return "", err
+	}
+	if fs.VerifyBundle != nil {
+		if err := fs.VerifyBundle(ctx, raw); err != nil {
+			return "", fmt.Errorf("%w: %v", errUnverified, err)
+		}
+	}
+	return readBundle(raw, !strings.HasSuffix(fs.URI, ".tar"))
+}
+
 // yamlToJSON is a generic helper function to convert
 // yaml to json
 func yamlToJSON(rawFile []byte) (string, error) {
@@ -323,3 +323,82 @@ func writeToFile(t *testing.T, fileContents string) {
 		t.Fatal(err)
 	}
 }
+
+func TestDebouncedSync(t *testing.T) {
+	const debounce = 200 * time.Millisecond
+	tests := map[string]struct {
+		manipulation     func(t *testing.T)
+		expectedDataSync sync.DataSync
+	}{
+		"rapid writes": {
+			manipulation: func(t *testing.T) {
+				// a generator writing the configuration field by field
+				content := ""
+				for i := 0; i < 10; i++ {
+					content += fmt.Sprintf("field%d;", i)
+					writeToFile(t, content)
+					time.Sleep(debounce / 10)
+				}
+			},
+			expectedDataSync: sync.DataSync{
+				FlagData: "field0;field1;field2;field3;field4;field5;field6;field7;field8;field9;",
+				Source:   fmt.Sprintf("%s/%s", fetchDirName, fetchFileName),
+				Type:     sync.ALL,
+			},
+		},
+		"write then delete": {
+			manipulation: func(t *testing.T) {
+				writeToFile(t, fetchFileContents)
+				deleteFile(t, fetchDirName, fetchFileName)
+			},
+			expectedDataSync: sync.DataSync{
+				FlagData: defaultState,
+				Source:   fmt.Sprintf("%s/%s", fetchDirName, fetchFileName),
+				Type:     sync.DELETE,
+			},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			defer t.Cleanup(cleanupFilePath)
+			setupDir(t)
+			createFile(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			dataSyncChan := make(chan sync.DataSync, 10)
+			syncHandler := Sync{
+				URI:      fmt.Sprintf("%s/%s", fetchDirName, fetchFileName),
+				Logger:   logger.NewLogger(nil, false),
+				Mux:      &msync.RWMutex{},
+				Debounce: debounce,
+			}
+			if err := syncHandler.Init(ctx); err != nil {
+				t.Fatalf("init sync: %v", err)
+			}
+			go func() {
+				if err := syncHandler.Sync(ctx, dataSyncChan); err != nil {
+					log.Fatalf("Error start sync: %s", err.Error())
+				}
+			}()
+			<-dataSyncChan
+
+			tt.manipulation(t)
+			select {
+			case data := <-dataSyncChan:
+				if !reflect.DeepEqual(tt.expectedDataSync, data) {
+					t.Errorf("expected datasync: %v, got: %v", tt.expectedDataSync, data)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("event not found, timeout out after 10 seconds")
+			}
+
+			select {
+			case data := <-dataSyncChan:
+				t.Errorf("expected the changes to coalesce into a single datasync, got another: %v", data)
+			case <-time.After(3 * debounce):
+			}
+		})
+	}
+}
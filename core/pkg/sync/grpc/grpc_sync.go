@@ -3,11 +3,13 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	credentials2 "github.com/open-feature/flagd/core/pkg/sync/grpc/credentials"
 	"math"
 	"strings"
 	msync "sync"
+	"sync/atomic"
 	"time"
 
 	"buf.build/gen/go/open-feature/flagd/grpc/go/sync/v1/syncv1grpc"
@@ -32,8 +34,14 @@ const (
 	constantBackOffDelay = 60
 
 	tlsVersion = tls.VersionTLS12
+
+	// defaultTimeout bounds the calls to the grpc target, unless configured otherwise
+	defaultTimeout = 10 * time.Second
 )
 
+// errTimeout reports a call to the grpc target which didn't complete within the timeout
+var errTimeout = errors.New("timed out")
+
 // type aliases for interfaces required by this component - needed for mock generation with gomock
 
 type FlagSyncServiceClient interface {
@@ -52,9 +60,13 @@ type Sync struct {
 	CertPath          string
 	Logger            *logger.Logger
 	CredentialBuilder credentials2.Builder
+	// Timeout bounds FetchAllFlags calls and the time a sync stream takes to deliver its first message, defaulting to
+	// 10 seconds
+	Timeout time.Duration
 
-	client FlagSyncServiceClient
-	ready  bool
+	client       FlagSyncServiceClient
+	ready        bool
+	connectivity sync.ConnectivityTracker
 }
 
 func (g *Sync) Init(ctx context.Context) error {
@@ -83,16 +95,22 @@ func (g *Sync) Init(ctx context.Context) error {
 }
 
 func (g *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
-	res, err := g.client.FetchAllFlags(ctx, &v1.FetchAllFlagsRequest{})
+	fetchCtx, cancel := context.WithTimeout(ctx, g.timeout())
+	defer cancel()
+	res, err := g.client.FetchAllFlags(fetchCtx, &v1.FetchAllFlagsRequest{})
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+			g.Logger.Error(fmt.Sprintf("fetching all flags from grpc target: %s timed out after %s", g.URI, g.timeout()))
+			return fmt.Errorf("fetching all flags: %w", errTimeout)
+		}
 		g.Logger.Error(fmt.Sprintf("fetching all flags: %s", err.Error()))
 		return err
 	}
-	dataSync <- sync.DataSync{
+	sync.Send(ctx, dataSync, sync.DataSync{
 		FlagData: res.GetFlagConfiguration(),
 		Source:   g.URI,
 		Type:     sync.ALL,
-	}
+	})
 	return nil
 }
 
@@ -100,45 +118,83 @@ func (g *Sync) IsReady() bool {
 	return g.ready
 }
 
+// DisconnectedSince returns when the stream of the grpc target was lost, the zero time while it's connected
+func (g *Sync) DisconnectedSince() time.Time {
+	return g.connectivity.DisconnectedSince()
+}
+
 func (g *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	// Initialize SyncFlags client. This fails if server connection establishment fails (ex:- grpc server offline)
-	syncClient, err := g.client.SyncFlags(ctx, &v1.SyncFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector})
+	syncClient, cancel, err := g.openStream(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Initial stream listening. Error will be logged and continue and retry connection establishment
-	err = g.handleFlagSync(syncClient, dataSync)
-	if err == nil {
-		// This should not happen as handleFlagSync expects to return with an error
-		return err
+	err = g.handleFlagSync(ctx, syncClient, cancel, dataSync)
+	if err == nil || ctx.Err() != nil {
+		// handleFlagSync only returns without an error once the context is done
+		return nil
 	}
 
-	g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
+	g.logStreamError(err)
 
 	// retry connection establishment
 	for {
-		syncClient, ok := g.connectWithRetry(ctx)
+		syncClient, cancel, ok := g.connectWithRetry(ctx)
 		if !ok {
 			// We shall exit
 			return nil
 		}
 
-		err = g.handleFlagSync(syncClient, dataSync)
+		err = g.handleFlagSync(ctx, syncClient, cancel, dataSync)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
-			g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
+			g.logStreamError(err)
 			continue
 		}
 	}
 }
 
+// openStream opens a sync stream, which is cancelled by the returned function
+func (g *Sync) openStream(
+	ctx context.Context,
+) (syncv1grpc.FlagSyncService_SyncFlagsClient, context.CancelFunc, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	syncClient, err := g.client.SyncFlags(
+		streamCtx, &v1.SyncFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector},
+	)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return syncClient, cancel, nil
+}
+
+func (g *Sync) logStreamError(err error) {
+	if errors.Is(err, errTimeout) {
+		g.Logger.Warn(fmt.Sprintf("sync stream of grpc target: %s timed out, no message within %s", g.URI, g.timeout()))
+		return
+	}
+	g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
+}
+
+func (g *Sync) timeout() time.Duration {
+	if g.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return g.Timeout
+}
+
 // connectWithRetry is a helper that performs exponential back off after retrying connection attempts periodically until
 // a successful connection is established. Caller must not expect an error. Hence, errors are handled, logged
 // internally. However, if the provided context is done, method exit with a non-ok state which must be verified by the
 // caller
 func (g *Sync) connectWithRetry(
 	ctx context.Context,
-) (syncv1grpc.FlagSyncService_SyncFlagsClient, bool) {
+) (syncv1grpc.FlagSyncService_SyncFlagsClient, context.CancelFunc, bool) {
 	var iteration int
 
 	for {
@@ -156,66 +212,89 @@ func (g *Sync) connectWithRetry(
 			break
 		case <-ctx.Done():
 			// context done means we shall exit
-			return nil, false
+			return nil, nil, false
 		}
 
 		g.Logger.Warn(fmt.Sprintf("connection re-establishment attempt in-progress for grpc target: %s", g.URI))
 
-		syncClient, err := g.client.SyncFlags(ctx, &v1.SyncFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector})
+		syncClient, cancel, err := g.openStream(ctx)
 		if err != nil {
 			g.Logger.Debug(fmt.Sprintf("error opening service client: %s", err.Error()))
 			continue
 		}
 
 		g.Logger.Info(fmt.Sprintf("connection re-established with grpc target: %s", g.URI))
-		return syncClient, true
+		return syncClient, cancel, true
 	}
 }
 
-// handleFlagSync wraps the stream listening and push updates through dataSync channel
-func (g *Sync) handleFlagSync(stream syncv1grpc.FlagSyncService_SyncFlagsClient, dataSync chan<- sync.DataSync) error {
+// handleFlagSync wraps the stream listening and push updates through dataSync channel. The stream is cancelled, failing
+// with errTimeout, if its first message doesn't arrive within the timeout.
+func (g *Sync) handleFlagSync(
+	ctx context.Context,
+	stream syncv1grpc.FlagSyncService_SyncFlagsClient,
+	cancel context.CancelFunc,
+	dataSync chan<- sync.DataSync,
+) error {
+	defer cancel()
 	// Set ready state once only
 	once.Do(func() {
 		g.ready = true
 	})
 
+	var timedOut atomic.Bool
+	firstMessage := time.AfterFunc(g.timeout(), func() {
+		timedOut.Store(true)
+		cancel()
+	})
+	defer firstMessage.Stop()
 	for {
 		data, err := stream.Recv()
 		if err != nil {
+			g.connectivity.Disconnected(time.Now())
+			if timedOut.Load() && ctx.Err() == nil {
+				return errTimeout
+			}
 			return err
 		}
+		if !firstMessage.Stop() && timedOut.Load() {
+			// the timeout raced the first message, the stream is cancelled already
+			g.connectivity.Disconnected(time.Now())
+			return errTimeout
+		}
+		g.connectivity.Connected()
 
 		switch data.State {
 		case v1.SyncState_SYNC_STATE_ALL:
-			dataSync <- sync.DataSync{
+			sync.Send(ctx, dataSync, sync.DataSync{
 				FlagData: data.FlagConfiguration,
 				Source:   g.URI,
 				Type:     sync.ALL,
-			}
+			})
 
 			g.Logger.Debug("received full configuration payload")
 		case v1.SyncState_SYNC_STATE_ADD:
-			dataSync <- sync.DataSync{
+			sync.Send(ctx, dataSync, sync.DataSync{
 				FlagData: data.FlagConfiguration,
 				Source:   g.URI,
 				Type:     sync.ADD,
-			}
+			})
 
 			g.Logger.Debug("received an add payload")
 		case v1.SyncState_SYNC_STATE_UPDATE:
-			dataSync <- sync.DataSync{
+			sync.Send(ctx, dataSync, sync.DataSync{
 				FlagData: data.FlagConfiguration,
 				Source:   g.URI,
 				Type:     sync.UPDATE,
-			}
+			})
 
 			g.Logger.Debug("received an update payload")
 		case v1.SyncState_SYNC_STATE_DELETE:
-			dataSync <- sync.DataSync{
+			sync.Send(ctx, dataSync, sync.DataSync{
 				FlagData: data.FlagConfiguration,
 				Source:   g.URI,
 				Type:     sync.DELETE,
-			}
+			})
 
 			g.Logger.Debug("received a delete payload")
 		case v1.SyncState_SYNC_STATE_PING:
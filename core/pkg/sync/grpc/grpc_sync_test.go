@@ -7,6 +7,8 @@ import (
 	"github.com/golang/mock/gomock"
 	credendialsmock "github.com/open-feature/flagd/core/pkg/sync/grpc/credentials/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc/credentials"
 	"io"
 	"log"
@@ -588,7 +590,7 @@ func Test_ConnectWithRetry(t *testing.T) {
 
 	// start connection retry attempts
 	go func() {
-		client, ok := grpcSync.connectWithRetry(tCtx)
+		client, _, ok := grpcSync.connectWithRetry(tCtx)
 		if !ok {
 			clientChan <- nil
 		}
@@ -713,6 +715,53 @@ func Test_SyncRetry(t *testing.T) {
 	}
 }
 
+func Test_UnresponsiveRemote(t *testing.T) {
+	bufListener := bufconn.Listen(1)
+	go serve(&bufferedServer{listener: bufListener, hang: true})
+
+	clientConn, err := grpc.Dial("grpc://local",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return bufListener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err)
+
+	core, logs := observer.New(zap.WarnLevel)
+	grpcSync := Sync{
+		URI:     "grpc://local",
+		Logger:  logger.NewLogger(zap.New(core), false),
+		Timeout: 100 * time.Millisecond,
+		client:  syncv1grpc.NewFlagSyncServiceClient(clientConn),
+	}
+
+	t.Run("fetching all flags times out", func(t *testing.T) {
+		start := time.Now()
+		err := grpcSync.ReSync(context.Background(), make(chan sync.DataSync, 1))
+		require.ErrorIs(t, err, errTimeout)
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("sync stream times out and shuts down on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- grpcSync.Sync(ctx, make(chan sync.DataSync))
+		}()
+
+		require.Eventually(t, func() bool {
+			return logs.FilterMessageSnippet("sync stream of grpc target: grpc://local timed out").Len() > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		cancel()
+		select {
+		case err := <-done:
+			require.Nil(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sync didn't return once its context was cancelled")
+		}
+	})
+}
+
 // Mock implementations
 
 // serve serves a bufferedServer. This is a blocking call
@@ -737,9 +786,15 @@ type bufferedServer struct {
 	mockResponses         []serverPayload
 	fetchAllFlagsResponse *v1.FetchAllFlagsResponse
 	fetchAllFlagsError    error
+	// hang blocks the calls until they're cancelled, as a remote which never responds
+	hang bool
 }
 
 func (b *bufferedServer) SyncFlags(req *v1.SyncFlagsRequest, stream syncv1grpc.FlagSyncService_SyncFlagsServer) error {
+	if b.hang {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
 	for _, response := range b.mockResponses {
 		err := stream.Send(&v1.SyncFlagsResponse{
 			FlagConfiguration: response.flags,
@@ -755,5 +810,9 @@ func (b *bufferedServer) SyncFlags(req *v1.SyncFlagsRequest, stream syncv1grpc.F
 }
 
 func (b *bufferedServer) FetchAllFlags(ctx context.Context, req *v1.FetchAllFlagsRequest) (*v1.FetchAllFlagsResponse, error) {
+	if b.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	return b.fetchAllFlagsResponse, b.fetchAllFlagsError
 }
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
@@ -22,7 +24,8 @@ type Sync struct {
 	Logger      *logger.Logger
 	BearerToken string
 
-	ready bool
+	ready        bool
+	connectivity sync.ConnectivityTracker
 }
 
 // Client defines the behaviour required of a http client
@@ -55,6 +58,11 @@ func (hs *Sync) IsReady() bool {
 	return hs.ready
 }
 
+// DisconnectedSince returns when fetching the configuration started failing, the zero time while it succeeds
+func (hs *Sync) DisconnectedSince() time.Time {
+	return hs.connectivity.DisconnectedSince()
+}
+
 func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	// Initial fetch
 	fetch, err := hs.Fetch(ctx)
@@ -68,7 +76,7 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	_ = hs.Cron.AddFunc("*/5 * * * *", func() {
 		body, err := hs.fetchBodyFromURL(ctx, hs.URI)
 		if err != nil {
-			hs.Logger.Error(err.Error())
+			hs.logFetchError(err)
 			return
 		}
 
@@ -79,9 +87,9 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 				hs.Logger.Debug("new configuration created")
 				msg, err := hs.Fetch(ctx)
 				if err != nil {
-					hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
+					hs.logFetchError(err)
 				} else {
-					dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
+					sync.Send(ctx, dataSync, sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL})
 				}
 			} else {
 				currentSHA := hs.generateSha(body)
@@ -89,9 +97,9 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 					hs.Logger.Debug("configuration modified")
 					msg, err := hs.Fetch(ctx)
 					if err != nil {
-						hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
+						hs.logFetchError(err)
 					} else {
-						dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
+						sync.Send(ctx, dataSync, sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL})
 					}
 				}
 
@@ -102,7 +110,7 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 
 	hs.Cron.Start()
 
-	dataSync <- sync.DataSync{FlagData: fetch, Source: hs.URI, Type: sync.ALL}
+	sync.Send(ctx, dataSync, sync.DataSync{FlagData: fetch, Source: hs.URI, Type: sync.ALL})
 
 	<-ctx.Done()
 	hs.Cron.Stop()
@@ -125,8 +133,13 @@ func (hs *Sync) fetchBodyFromURL(ctx context.Context, url string) ([]byte, error
 
 	resp, err := hs.Client.Do(req)
 	if err != nil {
+		hs.connectivity.Disconnected(time.Now())
+		if ctx.Err() == nil && isTimeout(err) {
+			return nil, fmt.Errorf("timed out fetching the configuration from %s: %w", url, err)
+		}
 		return nil, err
 	}
+	hs.connectivity.Connected()
 	defer func() {
 		err = resp.Body.Close()
 		if err != nil {
@@ -142,6 +155,21 @@ func (hs *Sync) fetchBodyFromURL(ctx context.Context, url string) ([]byte, error
 	return body, nil
 }
 
+// logFetchError logs the error of a periodic fetch, timeouts being logged as warnings as the fetch is retried
+func (hs *Sync) logFetchError(err error) {
+	if isTimeout(err) {
+		hs.Logger.Warn(err.Error())
+		return
+	}
+	hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
+}
+
+// isTimeout reports whether the error is the timeout of the client, or the deadline of the request
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
 func (hs *Sync) generateSha(body []byte) string {
 	hasher := sha3.New256()
 	hasher.Write(body)
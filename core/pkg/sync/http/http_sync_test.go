@@ -2,9 +2,11 @@ package http
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
@@ -245,3 +247,79 @@ func TestHTTPSync_Resync(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPSync_Connectivity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := syncmock.NewMockClient(ctrl)
+	httpSync := Sync{
+		URI:    "http://localhost",
+		Client: mockClient,
+		Logger: logger.NewLogger(nil, false),
+	}
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused")).Times(2)
+	if _, err := httpSync.Fetch(context.Background()); err == nil {
+		t.Fatal("expected err, got nil")
+	}
+	disconnectedSince := httpSync.DisconnectedSince()
+	if disconnectedSince.IsZero() {
+		t.Fatal("expected the source to be disconnected")
+	}
+	_, _ = httpSync.Fetch(context.Background())
+	if !httpSync.DisconnectedSince().Equal(disconnectedSince) {
+		t.Errorf("expected the source to be disconnected since: %s, got: %s",
+			disconnectedSince, httpSync.DisconnectedSince())
+	}
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Body: io.NopCloser(strings.NewReader("test response")),
+	}, nil)
+	if _, err := httpSync.Fetch(context.Background()); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if !httpSync.DisconnectedSince().IsZero() {
+		t.Errorf("expected the source to be connected, disconnected since: %s", httpSync.DisconnectedSince())
+	}
+}
+
+func TestHTTPSync_UnresponsiveRemote(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+
+	httpSync := Sync{
+		URI:    server.URL,
+		Client: &http.Client{Timeout: 100 * time.Millisecond},
+		Logger: logger.NewLogger(nil, false),
+	}
+
+	start := time.Now()
+	_, err := httpSync.Fetch(context.Background())
+	if err == nil {
+		t.Fatal("expected err, got nil")
+	}
+	if !isTimeout(err) || !strings.Contains(err.Error(), "timed out fetching the configuration from "+server.URL) {
+		t.Errorf("expected a timeout, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the fetch to time out, it took: %s", elapsed)
+	}
+	if httpSync.DisconnectedSince().IsZero() {
+		t.Error("expected the source to be disconnected")
+	}
+
+	// a cancelled fetch isn't reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	httpSync.Client = &http.Client{}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = httpSync.Fetch(ctx)
+	if err == nil || strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected the fetch to be cancelled, got: %v", err)
+	}
+}
@@ -58,6 +58,14 @@ type DataSync struct {
 	Type
 }
 
+// Send sends the data sync unless the context is done first, so a shutdown isn't blocked by a pending data sync
+func Send(ctx context.Context, dataSync chan<- DataSync, data DataSync) {
+	select {
+	case dataSync <- data:
+	case <-ctx.Done():
+	}
+}
+
 type SourceConfig struct {
 	URI      string `json:"uri"`
 	Provider string `json:"provider"`
@@ -66,4 +74,18 @@ type SourceConfig struct {
 	CertPath    string `json:"certPath,omitempty"`
 	ProviderID  string `json:"providerID,omitempty"`
 	Selector    string `json:"selector,omitempty"`
+	// SignatureURI locates the detached signature of the configuration, the URI followed by .sig by default
+	SignatureURI string `json:"signatureURI,omitempty"`
+	// Prefix is prepended to the keys of the flags of the source, e.g. teamA/ to namespace the flags of a team
+	Prefix string `json:"prefix,omitempty"`
+	// Fallbacks are the sources synced in order when this source fails, e.g. a local backup of a remote source
+	Fallbacks []SourceConfig `json:"fallbacks,omitempty"`
+	// Username and Password authenticate the pulls of oci sources, BearerToken being sent as a registry token instead
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// PollInterval is the interval oci sources referenced by tag are polled at for a moved tag, e.g. 1m, they aren't
+	// polled if unset. s3 sources are polled every minute if unset.
+	PollInterval string `json:"pollInterval,omitempty"`
+	// Region is the AWS region of the bucket of s3 sources, AWS_REGION or AWS_DEFAULT_REGION if unset
+	Region string `json:"region,omitempty"`
 }
@@ -0,0 +1,152 @@
+package kv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ConsulPrefix for Consul KV URIs, e.g. consul://localhost:8500/flagd/flags reads the key flagd/flags
+	ConsulPrefix = "consul://"
+	// ConsulSecurePrefix for Consul KV URIs read over https, e.g. consul+https://consul.example.com:8501/flagd/flags
+	ConsulSecurePrefix = "consul+https://"
+
+	consulIndexHeader = "X-Consul-Index"
+	consulTokenHeader = "X-Consul-Token"
+	// consulWaitTime bounds Consul blocking queries, after which the current value is returned unmodified
+	consulWaitTime = 5 * time.Minute
+)
+
+// Client defines the behaviour required of a http client
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ConsulStore reads keys through the Consul KV HTTP API, watching them with blocking queries
+type ConsulStore struct {
+	// Address of the Consul agent, e.g. http://localhost:8500
+	Address string
+	// Token is the ACL token sent with each request, if set
+	Token  string
+	Client Client
+}
+
+// ParseConsulURI splits a consul:// or consul+https:// URI into the address of the agent and the key
+func ParseConsulURI(uri string) (string, string, error) {
+	scheme, hostAndKey := "http://", strings.TrimPrefix(uri, ConsulPrefix)
+	if strings.HasPrefix(uri, ConsulSecurePrefix) {
+		scheme, hostAndKey = "https://", strings.TrimPrefix(uri, ConsulSecurePrefix)
+	}
+	host, key, found := strings.Cut(hostAndKey, "/")
+	if !found || host == "" || strings.Trim(key, "/") == "" {
+		return "", "", fmt.Errorf("invalid consul uri %s, expected consul://<host>:<port>/<key> or "+
+			"consul+https://<host>:<port>/<key>", uri)
+	}
+	return scheme + host, strings.Trim(key, "/"), nil
+}
+
+// NewConsulClient returns the http client of an agent, trusting the CA certificates of certPath if set, or the
+// certificates of the system otherwise
+func NewConsulClient(certPath string) (*http.Client, error) {
+	if certPath == "" {
+		return &http.Client{}, nil
+	}
+	certBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certBytes) {
+		return nil, fmt.Errorf("invalid certificate provided at path: %s", certPath)
+	}
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool},
+	}}, nil
+}
+
+// Validate fails if the ACL token would be sent in cleartext, over http to an agent which isn't a loopback address
+func (cs *ConsulStore) Validate() error {
+	if cs.Token == "" {
+		return nil
+	}
+	address, err := url.Parse(cs.Address)
+	if err != nil {
+		return fmt.Errorf("invalid consul address %s: %w", cs.Address, err)
+	}
+	if address.Scheme == "https" || isLoopback(address.Hostname()) {
+		return nil
+	}
+	return fmt.Errorf("refusing to send the consul ACL token over http to %s, use a %s uri", address.Host,
+		ConsulSecurePrefix)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (cs *ConsulStore) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	return cs.get(ctx, key, url.Values{})
+}
+
+func (cs *ConsulStore) Watch(ctx context.Context, key string, index uint64) ([]byte, uint64, error) {
+	return cs.get(ctx, key, url.Values{
+		"index": []string{strconv.FormatUint(index, 10)},
+		"wait":  []string{consulWaitTime.String()},
+	})
+}
+
+func (cs *ConsulStore) get(ctx context.Context, key string, query url.Values) ([]byte, uint64, error) {
+	if err := cs.Validate(); err != nil {
+		return nil, 0, err
+	}
+	query.Set("raw", "")
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, fmt.Sprintf("%s/v1/kv/%s?%s", cs.Address, key, query.Encode()), nil,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	if cs.Token != "" {
+		req.Header.Set(consulTokenHeader, cs.Token)
+	}
+
+	resp, err := cs.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// a missing key is an empty configuration, the index still allows watching for its creation
+		body = nil
+	default:
+		return nil, 0, fmt.Errorf("consul returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	index, err := strconv.ParseUint(resp.Header.Get(consulIndexHeader), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid %s header: %w", consulIndexHeader, err)
+	}
+	return body, index, nil
+}
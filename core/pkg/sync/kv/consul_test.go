@@ -0,0 +1,140 @@
+package kv
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsulURI(t *testing.T) {
+	address, key, err := ParseConsulURI("consul://localhost:8500/flagd/flags")
+	require.Nil(t, err)
+	require.Equal(t, "http://localhost:8500", address)
+	require.Equal(t, "flagd/flags", key)
+
+	address, key, err = ParseConsulURI("consul+https://consul.example.com:8501/flagd/flags")
+	require.Nil(t, err)
+	require.Equal(t, "https://consul.example.com:8501", address)
+	require.Equal(t, "flagd/flags", key)
+
+	for _, uri := range []string{
+		"consul://localhost:8500", "consul://localhost:8500/", "consul:///flags", "consul+https:///flags",
+	} {
+		_, _, err := ParseConsulURI(uri)
+		require.NotNil(t, err, uri)
+	}
+}
+
+func TestConsulStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get(consulTokenHeader))
+		require.Contains(t, r.URL.Query(), "raw")
+		switch r.URL.Path {
+		case "/v1/kv/flagd/flags":
+			if r.URL.Query().Get("index") == "7" {
+				require.Equal(t, consulWaitTime.String(), r.URL.Query().Get("wait"))
+				w.Header().Set(consulIndexHeader, "8")
+				_, _ = w.Write([]byte(`{"flags": {"b": {}}}`))
+				return
+			}
+			w.Header().Set(consulIndexHeader, "7")
+			_, _ = w.Write([]byte(`{"flags": {"a": {}}}`))
+		case "/v1/kv/missing":
+			w.Header().Set(consulIndexHeader, "3")
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("Permission denied"))
+		}
+	}))
+	defer server.Close()
+	store := &ConsulStore{Address: server.URL, Token: "secret", Client: server.Client()}
+	ctx := context.Background()
+
+	value, index, err := store.Get(ctx, "flagd/flags")
+	require.Nil(t, err)
+	require.Equal(t, `{"flags": {"a": {}}}`, string(value))
+	require.Equal(t, uint64(7), index)
+
+	value, index, err = store.Watch(ctx, "flagd/flags", index)
+	require.Nil(t, err)
+	require.Equal(t, `{"flags": {"b": {}}}`, string(value))
+	require.Equal(t, uint64(8), index)
+
+	value, index, err = store.Get(ctx, "missing")
+	require.Nil(t, err)
+	require.Empty(t, value)
+	require.Equal(t, uint64(3), index)
+
+	_, _, err = store.Get(ctx, "forbidden")
+	require.ErrorContains(t, err, "consul returned status 403: Permission denied")
+}
+
+func TestConsulStore_Validate(t *testing.T) {
+	tests := map[string]struct {
+		address string
+		token   string
+		err     string
+	}{
+		"token over https":              {address: "https://consul.example.com:8501", token: "secret"},
+		"token over http to localhost":  {address: "http://localhost:8500", token: "secret"},
+		"token over http to a loopback": {address: "http://127.0.0.1:8500", token: "secret"},
+		"token over http to ipv6 loopback": {
+			address: "http://[::1]:8500", token: "secret",
+		},
+		"no token over http": {address: "http://consul.example.com:8500"},
+		"token over http": {
+			address: "http://consul.example.com:8500",
+			token:   "secret",
+			err:     "refusing to send the consul ACL token over http to consul.example.com:8500, use a consul+https:// uri",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &ConsulStore{Address: tt.address, Token: tt.token, Client: http.DefaultClient}
+			err := store.Validate()
+			if tt.err == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+			_, _, err = store.Get(context.Background(), "flagd/flags")
+			require.EqualError(t, err, tt.err, "the token shouldn't be sent")
+		})
+	}
+}
+
+func TestNewConsulClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get(consulTokenHeader))
+		w.Header().Set(consulIndexHeader, "7")
+		_, _ = w.Write([]byte(`{"flags": {}}`))
+	}))
+	defer server.Close()
+	certPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.Nil(t, os.WriteFile(certPath,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	client, err := NewConsulClient(certPath)
+	require.Nil(t, err)
+	store := &ConsulStore{Address: server.URL, Token: "secret", Client: client}
+	value, index, err := store.Get(context.Background(), "flagd/flags")
+	require.Nil(t, err)
+	require.Equal(t, `{"flags": {}}`, string(value))
+	require.Equal(t, uint64(7), index)
+
+	client, err = NewConsulClient("")
+	require.Nil(t, err)
+	store.Client = client
+	_, _, err = store.Get(context.Background(), "flagd/flags")
+	require.ErrorContains(t, err, "certificate", "the certificate of the agent isn't trusted by the system")
+
+	_, err = NewConsulClient(filepath.Join(t.TempDir(), "missing.pem"))
+	require.NotNil(t, err)
+}
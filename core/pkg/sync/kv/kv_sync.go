@@ -0,0 +1,122 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+const (
+	// Watch retry constants, the delay doubles after each consecutive failure up to maxRetryDelay
+	initialRetryDelay = time.Second
+	maxRetryDelay     = time.Minute
+)
+
+// Store defines the behaviour required of a KV store holding a flag configuration
+type Store interface {
+	// Get returns the value of the key along with its modification index, a missing key has an empty value
+	Get(ctx context.Context, key string) ([]byte, uint64, error)
+	// Watch blocks until the key is modified after the provided index, or a store defined timeout elapses, and
+	// returns its value along with its modification index
+	Watch(ctx context.Context, key string, index uint64) ([]byte, uint64, error)
+}
+
+// Sync reads the flag configuration from a single key of a KV store and watches it for changes
+type Sync struct {
+	URI    string
+	Key    string
+	Store  Store
+	Logger *logger.Logger
+
+	index      uint64
+	ready      bool
+	retryDelay time.Duration
+}
+
+func (ks *Sync) Init(ctx context.Context) error {
+	if ks.Key == "" {
+		return errors.New("no KV key set")
+	}
+	if ks.Store == nil {
+		return errors.New("no KV store set")
+	}
+	return nil
+}
+
+func (ks *Sync) IsReady() bool {
+	return ks.ready
+}
+
+func (ks *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	value, _, err := ks.Store.Get(ctx, ks.Key)
+	if err != nil {
+		return fmt.Errorf("get key %s: %w", ks.Key, err)
+	}
+	ks.send(value, dataSync)
+	return nil
+}
+
+func (ks *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	value, index, err := ks.Store.Get(ctx, ks.Key)
+	if err != nil {
+		return fmt.Errorf("get key %s: %w", ks.Key, err)
+	}
+	ks.index = index
+	ks.ready = true
+	ks.send(value, dataSync)
+
+	retryDelay := ks.initialRetryDelay()
+	for {
+		value, index, err := ks.Store.Watch(ctx, ks.Key, ks.index)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			ks.Logger.Warn(fmt.Sprintf("watch key %s failed, retrying in %s: %v", ks.Key, retryDelay, err))
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return nil
+			}
+			retryDelay *= 2
+			if retryDelay > maxRetryDelay {
+				retryDelay = maxRetryDelay
+			}
+			continue
+		}
+		retryDelay = ks.initialRetryDelay()
+
+		switch {
+		case index == ks.index:
+			// the watch timed out without modification
+			continue
+		case index < ks.index:
+			// the index went backwards, e.g. after the store was restored, the key is read again
+			ks.Logger.Debug(fmt.Sprintf("index of key %s was reset", ks.Key))
+		default:
+			ks.Logger.Debug(fmt.Sprintf("key %s modified", ks.Key))
+		}
+		ks.index = index
+		ks.send(value, dataSync)
+	}
+}
+
+// send forwards the value of the key, a missing or empty key leaves the previous configuration in place
+func (ks *Sync) send(value []byte, dataSync chan<- sync.DataSync) {
+	if len(value) == 0 {
+		ks.Logger.Debug(fmt.Sprintf("key %s has no value", ks.Key))
+		return
+	}
+	dataSync <- sync.DataSync{FlagData: string(value), Source: ks.URI, Type: sync.ALL}
+}
+
+func (ks *Sync) initialRetryDelay() time.Duration {
+	if ks.retryDelay > 0 {
+		return ks.retryDelay
+	}
+	return initialRetryDelay
+}
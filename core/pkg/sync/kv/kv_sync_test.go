@@ -0,0 +1,123 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	msync "sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is an in-memory Store whose watches block until the key is modified
+type memStore struct {
+	mu       msync.Mutex
+	value    []byte
+	index    uint64
+	changed  chan struct{}
+	failures int
+}
+
+func newMemStore(value string) *memStore {
+	return &memStore{value: []byte(value), index: 1, changed: make(chan struct{})}
+}
+
+func (m *memStore) Get(_ context.Context, _ string) ([]byte, uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.value, m.index, nil
+}
+
+func (m *memStore) Watch(ctx context.Context, key string, index uint64) ([]byte, uint64, error) {
+	m.mu.Lock()
+	if m.failures > 0 {
+		m.failures--
+		m.mu.Unlock()
+		return nil, 0, errors.New("connection refused")
+	}
+	changed := m.changed
+	current := m.index
+	m.mu.Unlock()
+	if current != index {
+		return m.Get(ctx, key)
+	}
+	select {
+	case <-changed:
+		return m.Get(ctx, key)
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+}
+
+func (m *memStore) put(value string, failures int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.value = []byte(value)
+	m.index++
+	m.failures = failures
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func TestSync(t *testing.T) {
+	store := newMemStore(`{"flags": {"a": {}}}`)
+	ks := &Sync{URI: "consul://localhost:8500/flags", Key: "flags", Store: store, Logger: logger.NewLogger(nil, false)}
+	require.Nil(t, ks.Init(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dataSync := make(chan sync.DataSync)
+	done := make(chan error)
+	go func() {
+		done <- ks.Sync(ctx, dataSync)
+	}()
+
+	require.Equal(t, sync.DataSync{
+		FlagData: `{"flags": {"a": {}}}`, Source: "consul://localhost:8500/flags", Type: sync.ALL,
+	}, <-dataSync)
+	require.True(t, ks.IsReady())
+
+	store.put(`{"flags": {"b": {}}}`, 0)
+	require.Equal(t, `{"flags": {"b": {}}}`, (<-dataSync).FlagData)
+
+	// deleted keys keep the previous configuration
+	store.put("", 0)
+	store.put(`{"flags": {"c": {}}}`, 0)
+	require.Equal(t, `{"flags": {"c": {}}}`, (<-dataSync).FlagData)
+
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestSync_RetriesFailedWatches(t *testing.T) {
+	store := newMemStore(`{"flags": {}}`)
+	ks := &Sync{
+		Key: "flags", Store: store, Logger: logger.NewLogger(nil, false), retryDelay: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dataSync := make(chan sync.DataSync)
+	go func() {
+		_ = ks.Sync(ctx, dataSync)
+	}()
+	<-dataSync
+
+	store.put(`{"flags": {"a": {}}}`, 3)
+	require.Equal(t, `{"flags": {"a": {}}}`, (<-dataSync).FlagData)
+}
+
+func TestSync_Init(t *testing.T) {
+	require.NotNil(t, (&Sync{Store: newMemStore("")}).Init(context.Background()))
+	require.NotNil(t, (&Sync{Key: "flags"}).Init(context.Background()))
+}
+
+func TestReSync(t *testing.T) {
+	ks := &Sync{Key: "flags", Store: newMemStore(`{"flags": {}}`), Logger: logger.NewLogger(nil, false)}
+	dataSync := make(chan sync.DataSync, 1)
+	require.Nil(t, ks.ReSync(context.Background(), dataSync))
+	require.Equal(t, `{"flags": {}}`, (<-dataSync).FlagData)
+}
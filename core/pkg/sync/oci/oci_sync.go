@@ -0,0 +1,154 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// Sync pulls the flag configuration from the json layer of an OCI artifact, verifying the digests of its manifest and
+// layer. Artifacts referenced by tag are polled every PollInterval, if set, the configuration being pulled again
+// once the tag moves to another manifest. Artifacts referenced by digest are immutable, they're never polled.
+type Sync struct {
+	URI          string
+	Client       Client
+	Credentials  Credentials
+	PollInterval time.Duration
+	Logger       *logger.Logger
+
+	ref          Reference
+	registry     *registry
+	ready        bool
+	connectivity sync.ConnectivityTracker
+
+	mx msync.Mutex
+	// digest is the digest of the manifest of the last configuration sent
+	digest string
+}
+
+func (s *Sync) Init(ctx context.Context) error {
+	ref, err := ParseReference(s.URI)
+	if err != nil {
+		return err
+	}
+	if ref.Digest != "" && s.PollInterval > 0 {
+		return fmt.Errorf("oci uri %s references an immutable digest, it can't be polled", s.URI)
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	s.ref = ref
+	s.registry = &registry{ref: ref, client: client, credentials: s.Credentials}
+	return nil
+}
+
+func (s *Sync) IsReady() bool {
+	return s.ready
+}
+
+// DisconnectedSince returns when pulling the configuration started failing, the zero time while it succeeds
+func (s *Sync) DisconnectedSince() time.Time {
+	return s.connectivity.DisconnectedSince()
+}
+
+func (s *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	config, _, err := s.pull(ctx, "")
+	if err != nil {
+		return err
+	}
+	sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+	return nil
+}
+
+func (s *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	config, _, err := s.pull(ctx, "")
+	if err != nil {
+		return err
+	}
+	s.ready = true
+	sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+	if s.PollInterval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		config, moved, err := s.pull(ctx, s.lastDigest())
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			s.Logger.Warn(fmt.Sprintf("polling %s failed, keeping the last configuration: %v", s.ref, err))
+			continue
+		}
+		if moved {
+			s.Logger.Debug(fmt.Sprintf("tag of %s moved", s.ref))
+			sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+		}
+	}
+}
+
+// pull pulls the flag configuration of the artifact unless its manifest has the known digest, returning whether it
+// was pulled
+func (s *Sync) pull(ctx context.Context, known string) (string, bool, error) {
+	m, digest, err := s.registry.manifest(ctx)
+	if err != nil {
+		s.disconnected(ctx)
+		return "", false, err
+	}
+	if digest == known {
+		s.connectivity.Connected()
+		return "", false, nil
+	}
+	layer, err := configLayer(m)
+	if err != nil {
+		return "", false, fmt.Errorf("artifact %s: %w", s.ref, err)
+	}
+	config, err := s.registry.blob(ctx, layer)
+	if err != nil {
+		s.disconnected(ctx)
+		return "", false, err
+	}
+	s.connectivity.Connected()
+	s.mx.Lock()
+	s.digest = digest
+	s.mx.Unlock()
+	return string(config), true, nil
+}
+
+func (s *Sync) disconnected(ctx context.Context) {
+	if ctx.Err() == nil {
+		s.connectivity.Disconnected(time.Now())
+	}
+}
+
+func (s *Sync) lastDigest() string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.digest
+}
+
+// configLayer returns the first json layer of the manifest, e.g. pushed as flags.json:application/json
+func configLayer(m manifest) (descriptor, error) {
+	for _, layer := range m.Layers {
+		if layer.MediaType == "application/json" || strings.HasSuffix(layer.MediaType, "+json") {
+			return layer, nil
+		}
+	}
+	return descriptor{}, errors.New("no layer holds a json flag configuration")
+}
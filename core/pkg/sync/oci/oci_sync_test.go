@@ -0,0 +1,254 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	msync "sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	paymentsV1 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`
+	paymentsV2 = `{"flags": {"checkout": {"state": "DISABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`
+)
+
+// mockRegistry serves the artifacts of the flags/payments repository, authenticating pulls as configured
+type mockRegistry struct {
+	t      *testing.T
+	server *httptest.Server
+	// auth is either basic, challenging basic credentials, or bearer, challenging a token from the /token service
+	auth string
+
+	mx        msync.Mutex
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	tags      map[string]string
+	pulls     int
+}
+
+func newMockRegistry(t *testing.T, auth string) *mockRegistry {
+	r := &mockRegistry{
+		t: t, auth: auth, manifests: map[string][]byte{}, blobs: map[string][]byte{}, tags: map[string]string{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// push pushes an artifact holding the configuration as a json layer, tagging its manifest, and returns its digest
+func (r *mockRegistry) push(tag string, config string) string {
+	layer := descriptor{MediaType: "application/json", Digest: digestOf([]byte(config)), Size: int64(len(config))}
+	body, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     manifestMediaType,
+		"config":        descriptor{MediaType: "application/vnd.oci.empty.v1+json", Digest: digestOf([]byte("{}")), Size: 2},
+		"layers":        []descriptor{{MediaType: "text/plain", Digest: digestOf([]byte("readme")), Size: 6}, layer},
+	})
+	require.Nil(r.t, err)
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	digest := digestOf(body)
+	r.manifests[digest] = body
+	r.blobs[layer.Digest] = []byte(config)
+	r.tags[tag] = digest
+	return digest
+}
+
+func (r *mockRegistry) uri(reference string) string {
+	return fmt.Sprintf("oci://%s/flags/payments%s", strings.TrimPrefix(r.server.URL, "http://"), reference)
+}
+
+func (r *mockRegistry) pullCount() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.pulls
+}
+
+func (r *mockRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		require.Equal(r.t, "mock-registry", req.URL.Query().Get("service"))
+		require.Equal(r.t, "repository:flags/payments:pull", req.URL.Query().Get("scope"))
+		if username, password, _ := req.BasicAuth(); username != "jane" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token": "pull-token"}`))
+		return
+	}
+	if !r.authorized(req) {
+		if r.auth == "basic" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mock-registry"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Bearer realm="%s/token",service="mock-registry",scope="repository:flags/payments:pull"`, r.server.URL))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	switch resource := strings.TrimPrefix(req.URL.Path, "/v2/flags/payments/"); {
+	case strings.HasPrefix(resource, "manifests/"):
+		require.Equal(r.t, manifestMediaType, req.Header.Get("Accept"))
+		reference := strings.TrimPrefix(resource, "manifests/")
+		if digest, ok := r.tags[reference]; ok {
+			reference = digest
+		}
+		manifest, ok := r.manifests[reference]
+		if !ok {
+			http.Error(w, `{"errors": [{"code": "MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		r.pulls++
+		w.Header().Set("Content-Type", manifestMediaType)
+		_, _ = w.Write(manifest)
+	case strings.HasPrefix(resource, "blobs/"):
+		blob, ok := r.blobs[strings.TrimPrefix(resource, "blobs/")]
+		if !ok {
+			http.Error(w, `{"errors": [{"code": "BLOB_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *mockRegistry) authorized(req *http.Request) bool {
+	if r.auth == "basic" {
+		username, password, _ := req.BasicAuth()
+		return username == "jane" && password == "s3cret"
+	}
+	return req.Header.Get("Authorization") == "Bearer pull-token"
+}
+
+func newSync(t *testing.T, uri string, credentials Credentials, pollInterval time.Duration) *Sync {
+	s := &Sync{
+		URI:          uri,
+		Client:       &http.Client{},
+		Credentials:  credentials,
+		PollInterval: pollInterval,
+		Logger:       logger.NewLogger(nil, false),
+	}
+	require.Nil(t, s.Init(context.Background()))
+	return s
+}
+
+func TestSync_Authentication(t *testing.T) {
+	for _, auth := range []string{"basic", "bearer"} {
+		t.Run(auth, func(t *testing.T) {
+			registry := newMockRegistry(t, auth)
+			registry.push("v1", paymentsV1)
+			s := newSync(t, registry.uri(":v1"), Credentials{Username: "jane", Password: "s3cret"}, 0)
+
+			dataSync := make(chan sync.DataSync, 1)
+			require.Nil(t, s.ReSync(context.Background(), dataSync))
+			data := <-dataSync
+			require.Equal(t, sync.DataSync{FlagData: paymentsV1, Source: registry.uri(":v1"), Type: sync.ALL}, data)
+
+			s = newSync(t, registry.uri(":v1"), Credentials{Username: "jane", Password: "wrong"}, 0)
+			require.NotNil(t, s.ReSync(context.Background(), dataSync), "pulls with invalid credentials should fail")
+			require.False(t, s.DisconnectedSince().IsZero())
+		})
+	}
+}
+
+func TestSync_Token(t *testing.T) {
+	registry := newMockRegistry(t, "bearer")
+	registry.push("v1", paymentsV1)
+	dataSync := make(chan sync.DataSync, 1)
+
+	s := newSync(t, registry.uri(":v1"), Credentials{Token: "pull-token"}, 0)
+	require.Nil(t, s.ReSync(context.Background(), dataSync))
+	require.Equal(t, paymentsV1, (<-dataSync).FlagData)
+
+	s = newSync(t, registry.uri(":v1"), Credentials{Token: "expired-token"}, 0)
+	require.ErrorContains(t, s.ReSync(context.Background(), dataSync), "registry rejected the token")
+}
+
+func TestSync_Digest(t *testing.T) {
+	registry := newMockRegistry(t, "basic")
+	digest := registry.push("v1", paymentsV1)
+	credentials := Credentials{Username: "jane", Password: "s3cret"}
+	dataSync := make(chan sync.DataSync, 1)
+
+	s := newSync(t, registry.uri("@"+digest), credentials, 0)
+	require.Nil(t, s.ReSync(context.Background(), dataSync))
+	require.Equal(t, paymentsV1, (<-dataSync).FlagData)
+
+	// the registry serving another manifest than the referenced one
+	other := registry.push("v2", paymentsV2)
+	registry.mx.Lock()
+	registry.manifests[digest] = registry.manifests[other]
+	registry.mx.Unlock()
+	err := s.ReSync(context.Background(), dataSync)
+	require.EqualError(t, err, fmt.Sprintf("manifest of %s has digest %s", s.ref, other))
+
+	immutable := &Sync{URI: registry.uri("@" + digest), PollInterval: time.Minute}
+	require.EqualError(t, immutable.Init(context.Background()), fmt.Sprintf(
+		"oci uri %s references an immutable digest, it can't be polled", registry.uri("@"+digest)))
+}
+
+func TestSync_TamperedLayer(t *testing.T) {
+	registry := newMockRegistry(t, "basic")
+	registry.push("v1", paymentsV1)
+	registry.mx.Lock()
+	registry.blobs[digestOf([]byte(paymentsV1))] = []byte(paymentsV2)
+	registry.mx.Unlock()
+
+	s := newSync(t, registry.uri(":v1"), Credentials{Username: "jane", Password: "s3cret"}, 0)
+	err := s.ReSync(context.Background(), make(chan sync.DataSync, 1))
+	require.EqualError(t, err, fmt.Sprintf("layer %s of %s doesn't match its digest", digestOf([]byte(paymentsV1)), s.ref))
+}
+
+func TestSync_WithoutJSONLayer(t *testing.T) {
+	registry := newMockRegistry(t, "basic")
+	manifest := []byte(`{"schemaVersion": 2, "layers": [{"mediaType": "text/plain"}]}`)
+	registry.manifests[digestOf(manifest)] = manifest
+	registry.tags["v1"] = digestOf(manifest)
+
+	s := newSync(t, registry.uri(":v1"), Credentials{Username: "jane", Password: "s3cret"}, 0)
+	err := s.ReSync(context.Background(), make(chan sync.DataSync, 1))
+	require.EqualError(t, err, fmt.Sprintf("artifact %s: no layer holds a json flag configuration", s.ref))
+}
+
+func TestSync_PollMovedTag(t *testing.T) {
+	registry := newMockRegistry(t, "bearer")
+	registry.push("v1", paymentsV1)
+	s := newSync(t, registry.uri(":v1"), Credentials{Username: "jane", Password: "s3cret"}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataSync := make(chan sync.DataSync)
+	done := make(chan error)
+	go func() { done <- s.Sync(ctx, dataSync) }()
+	require.Equal(t, paymentsV1, (<-dataSync).FlagData)
+	require.True(t, s.IsReady())
+
+	// polls of an unmoved tag don't resend the configuration
+	require.Eventually(t, func() bool { return registry.pullCount() > 3 }, time.Second, 5*time.Millisecond)
+	select {
+	case data := <-dataSync:
+		t.Fatalf("unexpected data sync of an unmoved tag: %v", data)
+	default:
+	}
+
+	registry.push("v1", paymentsV2)
+	select {
+	case data := <-dataSync:
+		require.Equal(t, paymentsV2, data.FlagData)
+	case <-time.After(time.Second):
+		t.Fatal("the moved tag wasn't pulled")
+	}
+	cancel()
+	require.Nil(t, <-done)
+}
@@ -0,0 +1,98 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Prefix for OCI artifact URIs, e.g. oci://registry.example.com/flags/payments:v1 pulls the tag v1 of the
+	// flags/payments repository
+	Prefix = "oci://"
+
+	defaultTag = "latest"
+)
+
+var (
+	// regRepository and regTag match the repository names and tags of the OCI distribution specification
+	regRepository = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	regTag        = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+	// regDigest matches the sha256 digests of content, the only algorithm verified
+	regDigest = regexp.MustCompile("^sha256:[a-f0-9]{64}$")
+)
+
+// Reference locates an artifact in a registry, by its digest if set, by its tag otherwise
+type Reference struct {
+	// Registry is the host of the registry, along with its port if set, e.g. registry.example.com:5000
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an oci://<registry>/<repository>[:<tag>][@<digest>] URI, the tag being latest unless a tag
+// or digest is set
+func ParseReference(uri string) (Reference, error) {
+	invalid := fmt.Errorf("invalid oci uri %s, expected oci://<registry>/<repository>[:<tag>][@<digest>]", uri)
+	registry, name, found := strings.Cut(strings.TrimPrefix(uri, Prefix), "/")
+	if !found || registry == "" {
+		return Reference{}, invalid
+	}
+	ref := Reference{Registry: registry}
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !regDigest.MatchString(ref.Digest) {
+			return Reference{}, fmt.Errorf("invalid digest %s of oci uri %s, expected sha256:<hex>", ref.Digest, uri)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+		if !regTag.MatchString(ref.Tag) {
+			return Reference{}, invalid
+		}
+	}
+	if !regRepository.MatchString(name) {
+		return Reference{}, invalid
+	}
+	ref.Repository = name
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+	return ref, nil
+}
+
+// manifestReference is the digest of the artifact if set, its tag otherwise
+func (r Reference) manifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// scheme is http for local registries, https otherwise
+func (r Reference) scheme() string {
+	host := r.Registry
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	switch host {
+	case "localhost", "127.0.0.1", "[::1]":
+		return "http"
+	default:
+		return "https"
+	}
+}
+
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
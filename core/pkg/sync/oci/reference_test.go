@@ -0,0 +1,71 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + "4bf92f3577b34da6a3ce929d0e0e4736" + "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := map[string]struct {
+		uri string
+		ref Reference
+		err string
+	}{
+		"tag": {
+			uri: "oci://registry.example.com/flags/payments:v1",
+			ref: Reference{Registry: "registry.example.com", Repository: "flags/payments", Tag: "v1"},
+		},
+		"latest tag by default": {
+			uri: "oci://registry.example.com:5000/payments",
+			ref: Reference{Registry: "registry.example.com:5000", Repository: "payments", Tag: "latest"},
+		},
+		"digest": {
+			uri: "oci://registry.example.com/flags/payments@" + digest,
+			ref: Reference{Registry: "registry.example.com", Repository: "flags/payments", Digest: digest},
+		},
+		"tag and digest": {
+			uri: "oci://localhost:5000/flags/payments:v1@" + digest,
+			ref: Reference{Registry: "localhost:5000", Repository: "flags/payments", Tag: "v1", Digest: digest},
+		},
+		"without repository": {
+			uri: "oci://registry.example.com",
+			err: "invalid oci uri oci://registry.example.com, expected oci://<registry>/<repository>[:<tag>][@<digest>]",
+		},
+		"invalid repository": {
+			uri: "oci://registry.example.com/Flags//payments:v1",
+			err: "invalid oci uri oci://registry.example.com/Flags//payments:v1, expected " +
+				"oci://<registry>/<repository>[:<tag>][@<digest>]",
+		},
+		"empty tag": {
+			uri: "oci://registry.example.com/payments:",
+			err: "invalid oci uri oci://registry.example.com/payments:, expected " +
+				"oci://<registry>/<repository>[:<tag>][@<digest>]",
+		},
+		"unsupported digest": {
+			uri: "oci://registry.example.com/payments@sha512:abc",
+			err: "invalid digest sha512:abc of oci uri oci://registry.example.com/payments@sha512:abc, expected " +
+				"sha256:<hex>",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseReference(tt.uri)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.ref, ref)
+		})
+	}
+}
+
+func TestReferenceScheme(t *testing.T) {
+	require.Equal(t, "http", Reference{Registry: "localhost:5000"}.scheme())
+	require.Equal(t, "http", Reference{Registry: "127.0.0.1"}.scheme())
+	require.Equal(t, "http", Reference{Registry: "[::1]:5000"}.scheme())
+	require.Equal(t, "https", Reference{Registry: "registry.example.com:5000"}.scheme())
+	require.Equal(t, "https", Reference{Registry: "localhost.example.com"}.scheme())
+}
@@ -0,0 +1,238 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// maxManifestBytes bounds the manifests read, the manifest of a flag configuration lists a handful of layers
+	maxManifestBytes = 4 << 20
+	// maxConfigBytes bounds the flag configurations read from the layers of artifacts
+	maxConfigBytes = 64 << 20
+)
+
+// regChallengeParam matches the key="value" parameters of WWW-Authenticate challenges
+var regChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Client defines the behaviour required of a http client
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Credentials authenticate the pulls of a registry, anonymous pulls are attempted when empty
+type Credentials struct {
+	Username string
+	Password string
+	// Token is a registry token sent as a bearer token, rather than exchanged from the username and password
+	Token string
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []descriptor `json:"layers"`
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// registry pulls the manifests and blobs of a repository through the OCI distribution API, authenticating as
+// challenged by the registry: with basic credentials, or with a bearer token from the token service of the registry
+type registry struct {
+	ref         Reference
+	client      Client
+	credentials Credentials
+
+	mx            sync.Mutex
+	authorization string
+}
+
+// manifest returns the manifest of the artifact along with its digest, verified when the artifact is referenced by
+// digest
+func (r *registry) manifest(ctx context.Context) (manifest, string, error) {
+	body, err := r.get(ctx, "manifests/"+r.ref.manifestReference(), manifestMediaType, maxManifestBytes)
+	if err != nil {
+		return manifest{}, "", fmt.Errorf("pull manifest of %s: %w", r.ref, err)
+	}
+	digest := digestOf(body)
+	if r.ref.Digest != "" && digest != r.ref.Digest {
+		return manifest{}, "", fmt.Errorf("manifest of %s has digest %s", r.ref, digest)
+	}
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return manifest{}, "", fmt.Errorf("manifest of %s isn't valid json: %w", r.ref, err)
+	}
+	return m, digest, nil
+}
+
+// blob returns the content of the blob, verifying its size and digest
+func (r *registry) blob(ctx context.Context, desc descriptor) ([]byte, error) {
+	if !regDigest.MatchString(desc.Digest) {
+		return nil, fmt.Errorf("unsupported digest %s of a layer of %s, expected sha256:<hex>", desc.Digest, r.ref)
+	}
+	if desc.Size > maxConfigBytes {
+		return nil, fmt.Errorf("layer %s of %s exceeds %d bytes", desc.Digest, r.ref, maxConfigBytes)
+	}
+	body, err := r.get(ctx, "blobs/"+desc.Digest, "", desc.Size)
+	if err != nil {
+		return nil, fmt.Errorf("pull layer %s of %s: %w", desc.Digest, r.ref, err)
+	}
+	if int64(len(body)) != desc.Size || digestOf(body) != desc.Digest {
+		return nil, fmt.Errorf("layer %s of %s doesn't match its digest", desc.Digest, r.ref)
+	}
+	return body, nil
+}
+
+// get reads the resource of the repository, authenticating once if challenged. Bodies exceeding maxBytes are
+// truncated, failing their verification.
+func (r *registry) get(ctx context.Context, resource string, accept string, maxBytes int64) ([]byte, error) {
+	resp, err := r.do(ctx, resource, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if err := r.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = r.do(ctx, resource, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func (r *registry) do(ctx context.Context, resource string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s://%s/v2/%s/%s", r.ref.scheme(), r.ref.Registry, r.ref.Repository, resource), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if authorization := r.currentAuthorization(); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return r.client.Do(req)
+}
+
+func (r *registry) currentAuthorization() string {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.authorization == "" && r.credentials.Token != "" {
+		r.authorization = "Bearer " + r.credentials.Token
+	}
+	return r.authorization
+}
+
+// authenticate answers the WWW-Authenticate challenge of the registry, the authorization is reused by the following
+// requests until the registry challenges them
+func (r *registry) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	var authorization string
+	switch {
+	case r.credentials.Token != "":
+		return errors.New("registry rejected the token")
+	case strings.EqualFold(scheme, "basic"):
+		if r.credentials.Username == "" {
+			return errors.New("registry requires credentials")
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(r.credentials.Username, r.credentials.Password)
+		authorization = req.Header.Get("Authorization")
+	case strings.EqualFold(scheme, "bearer"):
+		token, err := r.token(ctx, params)
+		if err != nil {
+			return err
+		}
+		authorization = "Bearer " + token
+	default:
+		return fmt.Errorf("unsupported registry challenge: '%s'", challenge)
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.authorization = authorization
+	return nil
+}
+
+// token requests a pull token of the repository from the token service of the registry, with the basic credentials
+// if set, anonymously otherwise
+func (r *registry) token(ctx context.Context, challengeParams string) (string, error) {
+	params := map[string]string{}
+	for _, match := range regChallengeParam.FindAllStringSubmatch(challengeParams, -1) {
+		params[match[1]] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm: '%s' of the registry challenge", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", r.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if r.credentials.Username != "" {
+		req.SetBasicAuth(r.credentials.Username, r.credentials.Password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token service of the registry returned status %d", resp.StatusCode)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("token of the registry isn't valid json: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token service of the registry returned no token")
+	}
+	return token.AccessToken, nil
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
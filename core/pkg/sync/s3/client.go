@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// AWSClient reads objects with the S3 client of the AWS SDK, its credentials are resolved by the default credential
+// chain of the SDK
+type AWSClient struct {
+	client *awss3.Client
+}
+
+// NewAWSClient returns a client of the objects of the region, loading the default configuration of the AWS SDK. The
+// endpoint of an S3 compatible store, e.g. http://localhost:9000, addresses objects by path, buckets are addressed
+// as virtual hosts of the regional AWS endpoint when it's empty. Requests time out after the timeout, unless it's 0.
+func NewAWSClient(ctx context.Context, region, endpoint string, timeout time.Duration) (*AWSClient, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region),
+		config.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(timeout)))
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %w", err)
+	}
+	return &AWSClient{client: awss3.NewFromConfig(cfg, func(o *awss3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})}, nil
+}
+
+// Region returns the region of the objects of the client
+func (c *AWSClient) Region() string {
+	return c.client.Options().Region
+}
+
+// GetObject reads the object, conditional on the ETag if set
+func (c *AWSClient) GetObject(ctx context.Context, bucket, key, etag string) ([]byte, string, error) {
+	input := &awss3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	if etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+	out, err := c.client.GetObject(ctx, input)
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotModified {
+			return nil, "", ErrNotModified
+		}
+		return nil, "", err
+	}
+	defer out.Body.Close()
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, aws.ToString(out.ETag), nil
+}
@@ -0,0 +1,153 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+const (
+	// Prefix for S3 URIs, e.g. s3://my-bucket/flagd/flags.json reads the object flagd/flags.json of my-bucket
+	Prefix = "s3://"
+
+	// defaultPollInterval is the interval the object is polled at for changes, unless configured otherwise
+	defaultPollInterval = time.Minute
+)
+
+// ErrNotModified is returned by clients when the ETag of the object matches the known ETag
+var ErrNotModified = errors.New("object not modified")
+
+// Client defines the behaviour required of an S3 client
+type Client interface {
+	// GetObject returns the content of the object along with its ETag. Objects whose ETag matches the provided ETag
+	// aren't read again, ErrNotModified being returned instead.
+	GetObject(ctx context.Context, bucket, key, etag string) ([]byte, string, error)
+}
+
+// Sync reads the flag configuration from an S3 object, polling it every PollInterval with a conditional GET on its
+// ETag. The configuration is only sent again once the object changes, failed polls keep the last configuration.
+type Sync struct {
+	URI          string
+	Client       Client
+	PollInterval time.Duration
+	Logger       *logger.Logger
+
+	bucket       string
+	key          string
+	ready        bool
+	connectivity sync.ConnectivityTracker
+
+	mx msync.Mutex
+	// etag is the ETag of the object of the last configuration sent
+	etag string
+}
+
+// ParseURI splits an s3:// URI into the bucket and the key of the object
+func ParseURI(uri string) (string, string, error) {
+	bucket, key, found := strings.Cut(strings.TrimPrefix(uri, Prefix), "/")
+	if !found || bucket == "" || strings.Trim(key, "/") == "" {
+		return "", "", fmt.Errorf("invalid s3 uri %s, expected s3://<bucket>/<key>", uri)
+	}
+	return bucket, strings.TrimPrefix(key, "/"), nil
+}
+
+func (s *Sync) Init(ctx context.Context) error {
+	bucket, key, err := ParseURI(s.URI)
+	if err != nil {
+		return err
+	}
+	if s.Client == nil {
+		return errors.New("no S3 client set")
+	}
+	s.bucket, s.key = bucket, key
+	return nil
+}
+
+func (s *Sync) IsReady() bool {
+	return s.ready
+}
+
+// DisconnectedSince returns when reading the object started failing, the zero time while it succeeds
+func (s *Sync) DisconnectedSince() time.Time {
+	return s.connectivity.DisconnectedSince()
+}
+
+func (s *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	config, _, err := s.fetch(ctx, "")
+	if err != nil {
+		return err
+	}
+	sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+	return nil
+}
+
+func (s *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	config, _, err := s.fetch(ctx, "")
+	if err != nil {
+		return err
+	}
+	s.ready = true
+	sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		config, modified, err := s.fetch(ctx, s.lastETag())
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			s.Logger.Warn(fmt.Sprintf("polling %s failed, keeping the last configuration: %v", s.URI, err))
+			continue
+		}
+		if modified {
+			s.Logger.Debug(fmt.Sprintf("object %s modified", s.URI))
+			sync.Send(ctx, dataSync, sync.DataSync{FlagData: config, Source: s.URI, Type: sync.ALL})
+		}
+	}
+}
+
+// fetch reads the flag configuration of the object unless its ETag is the known ETag, returning whether it was read
+func (s *Sync) fetch(ctx context.Context, known string) (string, bool, error) {
+	config, etag, err := s.Client.GetObject(ctx, s.bucket, s.key, known)
+	if errors.Is(err, ErrNotModified) {
+		s.connectivity.Connected()
+		return "", false, nil
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			s.connectivity.Disconnected(time.Now())
+		}
+		return "", false, fmt.Errorf("get object %s: %w", s.URI, err)
+	}
+	s.connectivity.Connected()
+	if etag != "" && etag == known {
+		// clients ignoring the conditional request return the known object again
+		return "", false, nil
+	}
+	s.mx.Lock()
+	s.etag = etag
+	s.mx.Unlock()
+	return string(config), true, nil
+}
+
+func (s *Sync) lastETag() string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.etag
+}
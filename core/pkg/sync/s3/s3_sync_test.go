@@ -0,0 +1,187 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	msync "sync"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flagsV1 = `{"flags": {"checkout": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`
+	flagsV2 = `{"flags": {"checkout": {"state": "DISABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`
+)
+
+// mockClient serves a single object, versioned by its ETag, failing its reads while err is set
+type mockClient struct {
+	mx      msync.Mutex
+	content string
+	version int
+	err     error
+	gets    int
+	// etags are the ETags the gets were conditional on
+	etags []string
+}
+
+func (m *mockClient) GetObject(_ context.Context, bucket, key, etag string) ([]byte, string, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.gets++
+	m.etags = append(m.etags, etag)
+	if bucket != "my-bucket" || key != "flagd/flags.json" {
+		return nil, "", fmt.Errorf("unexpected object %s/%s", bucket, key)
+	}
+	if m.err != nil {
+		return nil, "", m.err
+	}
+	current := fmt.Sprintf(`"v%d"`, m.version)
+	if etag == current {
+		return nil, "", ErrNotModified
+	}
+	return []byte(m.content), current, nil
+}
+
+func (m *mockClient) put(content string) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.content = content
+	m.version++
+}
+
+func (m *mockClient) fail(err error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.err = err
+}
+
+func (m *mockClient) getCount() int {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	return m.gets
+}
+
+func newSync(t *testing.T, client Client) *Sync {
+	t.Helper()
+	s := &Sync{
+		URI:          "s3://my-bucket/flagd/flags.json",
+		Client:       client,
+		PollInterval: 10 * time.Millisecond,
+		Logger:       logger.NewLogger(nil, false),
+	}
+	require.Nil(t, s.Init(context.Background()))
+	return s
+}
+
+func TestParseURI(t *testing.T) {
+	bucket, key, err := ParseURI("s3://my-bucket/flagd/flags.json")
+	require.Nil(t, err)
+	require.Equal(t, "my-bucket", bucket)
+	require.Equal(t, "flagd/flags.json", key)
+
+	for _, uri := range []string{"s3://my-bucket", "s3://my-bucket/", "s3:///flags.json"} {
+		_, _, err := ParseURI(uri)
+		require.EqualError(t, err, fmt.Sprintf("invalid s3 uri %s, expected s3://<bucket>/<key>", uri))
+	}
+}
+
+func TestSync_PollModifiedObject(t *testing.T) {
+	client := &mockClient{}
+	client.put(flagsV1)
+	s := newSync(t, client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataSync := make(chan sync.DataSync)
+	done := make(chan error)
+	go func() { done <- s.Sync(ctx, dataSync) }()
+	data := <-dataSync
+	require.Equal(t, sync.DataSync{FlagData: flagsV1, Source: s.URI, Type: sync.ALL}, data)
+	require.True(t, s.IsReady())
+
+	// polls of an unmodified object are conditional on its ETag, and don't resend the configuration
+	require.Eventually(t, func() bool { return client.getCount() > 3 }, time.Second, 5*time.Millisecond)
+	select {
+	case data := <-dataSync:
+		t.Fatalf("unexpected data sync of an unmodified object: %v", data)
+	default:
+	}
+	client.mx.Lock()
+	require.Equal(t, "", client.etags[0])
+	require.Equal(t, `"v1"`, client.etags[1])
+	client.mx.Unlock()
+
+	client.put(flagsV2)
+	select {
+	case data := <-dataSync:
+		require.Equal(t, flagsV2, data.FlagData)
+	case <-time.After(time.Second):
+		t.Fatal("the modified object wasn't read")
+	}
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestSync_KeepsLastConfigurationOnFailure(t *testing.T) {
+	client := &mockClient{}
+	client.put(flagsV1)
+	s := newSync(t, client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataSync := make(chan sync.DataSync)
+	done := make(chan error)
+	go func() { done <- s.Sync(ctx, dataSync) }()
+	require.Equal(t, flagsV1, (<-dataSync).FlagData)
+
+	client.fail(errors.New("s3 returned status 503: SlowDown"))
+	gets := client.getCount()
+	require.Eventually(t, func() bool { return client.getCount() > gets+2 }, time.Second, 5*time.Millisecond)
+	select {
+	case data := <-dataSync:
+		t.Fatalf("unexpected data sync of a failed poll: %v", data)
+	default:
+	}
+	require.False(t, s.DisconnectedSince().IsZero(), "failed polls should be reported as disconnected")
+
+	client.fail(nil)
+	require.Eventually(t, func() bool { return s.DisconnectedSince().IsZero() }, time.Second, 5*time.Millisecond)
+	select {
+	case data := <-dataSync:
+		t.Fatalf("the unmodified object shouldn't be resent once reachable: %v", data)
+	default:
+	}
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestSync_InitialFailure(t *testing.T) {
+	client := &mockClient{err: errors.New("s3 returned status 404: NoSuchKey")}
+	s := newSync(t, client)
+	err := s.Sync(context.Background(), make(chan sync.DataSync))
+	require.EqualError(t, err, "get object s3://my-bucket/flagd/flags.json: s3 returned status 404: NoSuchKey")
+	require.False(t, s.IsReady())
+}
+
+func TestSync_ReSync(t *testing.T) {
+	client := &mockClient{}
+	client.put(flagsV1)
+	s := newSync(t, client)
+	dataSync := make(chan sync.DataSync, 1)
+	require.Nil(t, s.ReSync(context.Background(), dataSync))
+	require.Equal(t, flagsV1, (<-dataSync).FlagData)
+	client.mx.Lock()
+	defer client.mx.Unlock()
+	require.Equal(t, []string{""}, client.etags, "resyncs should read the object unconditionally")
+}
+
+func TestSync_Init(t *testing.T) {
+	require.EqualError(t, (&Sync{URI: "s3://my-bucket/flags.json"}).Init(context.Background()), "no S3 client set")
+	require.EqualError(t, (&Sync{URI: "s3://my-bucket", Client: &mockClient{}}).Init(context.Background()),
+		"invalid s3 uri s3://my-bucket, expected s3://<bucket>/<key>")
+}
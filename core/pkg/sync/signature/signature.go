@@ -0,0 +1,107 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Extension is appended to the URI of a source to locate its detached signature, unless set otherwise
+const Extension = ".sig"
+
+var errMissingSignature = errors.New("missing signature")
+
+// Verifier verifies the detached ed25519 signatures of flag configurations
+type Verifier struct {
+	publicKey ed25519.PublicKey
+}
+
+func NewVerifier(publicKey ed25519.PublicKey) *Verifier {
+	return &Verifier{publicKey: publicKey}
+}
+
+// LoadVerifier reads the PEM encoded ed25519 public key of the verifier, e.g. as exported by `openssl pkey -pubout`
+func LoadVerifier(path string) (*Verifier, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s isn't PEM encoded", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s isn't an ed25519 key", path)
+	}
+	return NewVerifier(publicKey), nil
+}
+
+// Verify checks the signature of the payload, either raw or base64 encoded
+func (v *Verifier) Verify(payload []byte, signature []byte) error {
+	if len(bytes.TrimSpace(signature)) == 0 {
+		return errMissingSignature
+	}
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+		if err != nil {
+			return fmt.Errorf("signature is neither %d bytes long nor base64 encoded", ed25519.SignatureSize)
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(v.publicKey, payload, signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// Fetcher fetches the current detached signature of a source
+type Fetcher func(ctx context.Context) ([]byte, error)
+
+// FileFetcher reads the signature from a file
+func FileFetcher(path string) Fetcher {
+	return func(_ context.Context) ([]byte, error) {
+		signature, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errMissingSignature
+		}
+		return signature, err
+	}
+}
+
+// HTTPFetcher downloads the signature, authenticated by the bearer token if set
+func HTTPFetcher(client *http.Client, url string, bearerToken string) Fetcher {
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if bearerToken != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", bearerToken))
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, errMissingSignature
+		case resp.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("fetch signature: unexpected status: %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+}
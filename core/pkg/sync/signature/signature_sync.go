@@ -0,0 +1,83 @@
+package signature
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// Sync verifies the configurations of the wrapped sync provider against their detached signatures. Configurations
+// lacking a valid signature are dropped, leaving the last verified configuration of the source in place.
+type Sync struct {
+	sync.ISync
+	Verifier  *Verifier
+	Signature Fetcher
+	Logger    *logger.Logger
+}
+
+func (ss *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return ss.relay(ctx, dataSync, ss.ISync.Sync)
+}
+
+func (ss *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return ss.relay(ctx, dataSync, ss.ISync.ReSync)
+}
+
+// DisconnectedSince reports the connectivity of the wrapped provider, if it's a remote source
+func (ss *Sync) DisconnectedSince() time.Time {
+	if c, ok := ss.ISync.(sync.Connectivity); ok {
+		return c.DisconnectedSince()
+	}
+	return time.Time{}
+}
+
+// relay runs the sync function of the wrapped provider, forwarding its verified data syncs until it returns
+func (ss *Sync) relay(
+	ctx context.Context,
+	dataSync chan<- sync.DataSync,
+	syncFunc func(context.Context, chan<- sync.DataSync) error,
+) error {
+	unverified := make(chan sync.DataSync)
+	done := make(chan error, 1)
+	go func() {
+		done <- syncFunc(ctx, unverified)
+	}()
+	for {
+		select {
+		case data := <-unverified:
+			if !ss.verified(ctx, data) {
+				continue
+			}
+			select {
+			case dataSync <- data:
+			case <-ctx.Done():
+				return nil
+			}
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// verified reports whether the data sync may be applied. Deletions carry no configuration and aren't verified.
+func (ss *Sync) verified(ctx context.Context, data sync.DataSync) bool {
+	if data.Type == sync.DELETE {
+		return true
+	}
+	signature, err := ss.Signature(ctx)
+	if err == nil {
+		err = ss.Verifier.Verify([]byte(data.FlagData), signature)
+	}
+	if err != nil {
+		ss.Logger.Error(fmt.Sprintf(
+			"refusing the configuration of source: %s, signature verification failed: %v", data.Source, err))
+		return false
+	}
+	ss.Logger.Info(fmt.Sprintf("verified the signature of the configuration of source: %s", data.Source))
+	return true
+}
@@ -0,0 +1,191 @@
+package signature
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const flagConfig = `{"flags": {}}`
+
+// staticSync is a sync provider sending its data syncs then returning
+type staticSync struct {
+	data []sync.DataSync
+}
+
+func (s *staticSync) Init(_ context.Context) error { return nil }
+
+func (s *staticSync) IsReady() bool { return true }
+
+func (s *staticSync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	for _, data := range s.data {
+		dataSync <- data
+	}
+	return nil
+}
+
+func (s *staticSync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return s.Sync(ctx, dataSync)
+}
+
+func TestVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	signature := ed25519.Sign(privateKey, []byte(flagConfig))
+
+	tests := map[string]struct {
+		signature []byte
+		wantErr   string
+	}{
+		"raw signature": {
+			signature: signature,
+		},
+		"base64 signature": {
+			signature: []byte(base64.StdEncoding.EncodeToString(signature) + "\n"),
+		},
+		"missing signature": {
+			wantErr: "missing signature",
+		},
+		"signature of another key": {
+			signature: ed25519.Sign(otherKey, []byte(flagConfig)),
+			wantErr:   "invalid signature",
+		},
+		"signature of another payload": {
+			signature: ed25519.Sign(privateKey, []byte(`{"flags": {"tampered": {}}}`)),
+			wantErr:   "invalid signature",
+		},
+		"malformed signature": {
+			signature: []byte("not a signature"),
+			wantErr:   "signature is neither 64 bytes long nor base64 encoded",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewVerifier(publicKey).Verify([]byte(flagConfig), tt.signature)
+			if tt.wantErr == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.Nil(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	verifier, err := LoadVerifier(path)
+	require.Nil(t, err)
+	require.Nil(t, verifier.Verify([]byte(flagConfig), ed25519.Sign(privateKey, []byte(flagConfig))))
+
+	require.Nil(t, os.WriteFile(path, []byte("not a key"), 0o600))
+	_, err = LoadVerifier(path)
+	require.EqualError(t, err, "public key "+path+" isn't PEM encoded")
+}
+
+func TestSync(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	signature := ed25519.Sign(privateKey, []byte(flagConfig))
+
+	tests := map[string]struct {
+		data      sync.DataSync
+		signature []byte
+		forwarded bool
+		wantLog   string
+	}{
+		"valid signature": {
+			data:      sync.DataSync{FlagData: flagConfig, Source: "flags.json", Type: sync.ALL},
+			signature: signature,
+			forwarded: true,
+			wantLog:   "verified the signature of the configuration of source: flags.json",
+		},
+		"invalid signature": {
+			data:      sync.DataSync{FlagData: `{"flags": {"tampered": {}}}`, Source: "flags.json", Type: sync.ALL},
+			signature: signature,
+			wantLog:   "refusing the configuration of source: flags.json, signature verification failed: invalid signature",
+		},
+		"missing signature": {
+			data:    sync.DataSync{FlagData: flagConfig, Source: "flags.json", Type: sync.ALL},
+			wantLog: "refusing the configuration of source: flags.json, signature verification failed: missing signature",
+		},
+		"deletion": {
+			data:      sync.DataSync{FlagData: "{}", Source: "flags.json", Type: sync.DELETE},
+			forwarded: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flags.json.sig")
+			if tt.signature != nil {
+				require.Nil(t, os.WriteFile(path, tt.signature, 0o600))
+			}
+			core, logs := observer.New(zapcore.InfoLevel)
+			ss := &Sync{
+				ISync:     &staticSync{data: []sync.DataSync{tt.data}},
+				Verifier:  NewVerifier(publicKey),
+				Signature: FileFetcher(path),
+				Logger:    logger.NewLogger(zap.New(core), false),
+			}
+
+			for _, syncFunc := range []func(context.Context, chan<- sync.DataSync) error{ss.Sync, ss.ReSync} {
+				dataSync := make(chan sync.DataSync, 1)
+				require.Nil(t, syncFunc(context.Background(), dataSync))
+				if !tt.forwarded {
+					require.Empty(t, dataSync, "configurations failing verification shouldn't be applied")
+					continue
+				}
+				require.Equal(t, tt.data, <-dataSync)
+			}
+			if tt.wantLog != "" {
+				require.Equal(t, 2, logs.FilterMessage(tt.wantLog).Len())
+			}
+		})
+	}
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/flags.json.sig":
+			_, _ = w.Write([]byte("signature"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	signature, err := HTTPFetcher(server.Client(), server.URL+"/flags.json.sig", "token")(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "signature", string(signature))
+
+	_, err = HTTPFetcher(server.Client(), server.URL+"/other.json.sig", "token")(context.Background())
+	require.EqualError(t, err, "missing signature")
+
+	_, err = HTTPFetcher(server.Client(), server.URL+"/flags.json.sig", "")(context.Background())
+	require.EqualError(t, err, "fetch signature: unexpected status: 401 Unauthorized")
+}
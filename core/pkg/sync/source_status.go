@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"sort"
+	msync "sync"
+	"time"
+)
+
+// SourceStatus describes the updates received from a flag source
+type SourceStatus struct {
+	Source        string    `json:"source"`
+	LastSync      time.Time `json:"lastSync"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorTime time.Time `json:"lastErrorTime"`
+	Updates       int64     `json:"updates"`
+	Errors        int64     `json:"errors"`
+}
+
+// SourceStatuses tracks the status of flag sources, it is safe for concurrent use
+type SourceStatuses struct {
+	mu       msync.RWMutex
+	statuses map[string]*SourceStatus
+}
+
+func NewSourceStatuses(sources ...string) *SourceStatuses {
+	s := &SourceStatuses{statuses: make(map[string]*SourceStatus, len(sources))}
+	for _, source := range sources {
+		s.statuses[source] = &SourceStatus{Source: source}
+	}
+	return s
+}
+
+// RecordSync records an update of the source which was applied successfully
+func (s *SourceStatuses) RecordSync(source string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status(source)
+	status.LastSync = at
+	status.Updates++
+}
+
+// RecordError records an update of the source which failed to be applied
+func (s *SourceStatuses) RecordError(source string, err error, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status(source)
+	status.LastError = err.Error()
+	status.LastErrorTime = at
+	status.Errors++
+}
+
+// Snapshot returns the status of every source, ordered by source
+func (s *SourceStatuses) Snapshot() []SourceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot := make([]SourceStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		snapshot = append(snapshot, *status)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Source < snapshot[j].Source
+	})
+	return snapshot
+}
+
+func (s *SourceStatuses) status(source string) *SourceStatus {
+	status, ok := s.statuses[source]
+	if !ok {
+		status = &SourceStatus{Source: source}
+		s.statuses[source] = status
+	}
+	return status
+}
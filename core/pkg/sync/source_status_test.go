@@ -0,0 +1,26 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceStatuses(t *testing.T) {
+	statuses := NewSourceStatuses("b.json", "a.json")
+	first := time.Unix(100, 0)
+	second := time.Unix(200, 0)
+
+	statuses.RecordSync("a.json", first)
+	statuses.RecordSync("a.json", second)
+	statuses.RecordError("b.json", errors.New("invalid configuration"), first)
+	statuses.RecordSync("c.json", first)
+
+	require.Equal(t, []SourceStatus{
+		{Source: "a.json", LastSync: second, Updates: 2},
+		{Source: "b.json", LastError: "invalid configuration", LastErrorTime: first, Errors: 1},
+		{Source: "c.json", LastSync: first, Updates: 1},
+	}, statuses.Snapshot())
+}
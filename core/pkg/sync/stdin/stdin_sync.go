@@ -0,0 +1,85 @@
+package stdin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// URI is the sync uri of the standard input
+const URI = "stdin"
+
+// Sync serves the flag configuration read from a reader, the standard input of flagd, at startup. The input is read
+// once, until EOF, and isn't watched for changes.
+type Sync struct {
+	Reader io.Reader
+	Logger *logger.Logger
+
+	config string
+	ready  atomic.Bool
+}
+
+// Init reads the whole input, failing if it isn't a JSON flag configuration
+func (ss *Sync) Init(ctx context.Context) error {
+	if ss.Reader == nil {
+		return errors.New("no stdin reader set")
+	}
+	ss.Logger.Info("reading the flag configuration from stdin")
+	raw, err := io.ReadAll(ss.Reader)
+	if err != nil {
+		return fmt.Errorf("read stdin: %w", err)
+	}
+	if err := validate(raw); err != nil {
+		return fmt.Errorf("invalid flag configuration on stdin: %w", err)
+	}
+	ss.config = string(raw)
+	return nil
+}
+
+// validate checks the input is a JSON object holding a flags object, the flags themselves are validated by the
+// evaluator
+func validate(raw []byte) error {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return err
+	}
+	flags, ok := config["flags"]
+	if !ok {
+		return errors.New("missing flags")
+	}
+	var definitions map[string]json.RawMessage
+	if err := json.Unmarshal(flags, &definitions); err != nil {
+		return fmt.Errorf("flags: %w", err)
+	}
+	return nil
+}
+
+func (ss *Sync) IsReady() bool {
+	return ss.ready.Load()
+}
+
+// Sync sends the configuration read by Init, then blocks until the context is done
+func (ss *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	ss.send(ctx, dataSync)
+	ss.ready.Store(true)
+	<-ctx.Done()
+	return nil
+}
+
+func (ss *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	ss.send(ctx, dataSync)
+	return nil
+}
+
+func (ss *Sync) send(ctx context.Context, dataSync chan<- sync.DataSync) {
+	select {
+	case dataSync <- sync.DataSync{FlagData: ss.config, Source: URI, Type: sync.ALL}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,69 @@
+package stdin
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+const flagConfig = `{"flags":{"myBoolFlag":{"state":"ENABLED","variants":{"on":true,"off":false},` +
+	`"defaultVariant":"on"}}}`
+
+func TestStdinSync(t *testing.T) {
+	// the configuration is piped, as by `cat flags.json | flagd start --uri stdin`
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	defer r.Close()
+	go func() {
+		defer w.Close()
+		for _, chunk := range []string{flagConfig[:20], flagConfig[20:]} {
+			_, _ = w.WriteString(chunk)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	ss := &Sync{Reader: r, Logger: logger.NewLogger(nil, false)}
+	require.Nil(t, ss.Init(context.Background()))
+	require.False(t, ss.IsReady())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dataSync := make(chan sync.DataSync, 1)
+	done := make(chan error)
+	go func() {
+		done <- ss.Sync(ctx, dataSync)
+	}()
+	data := <-dataSync
+	require.Equal(t, sync.DataSync{FlagData: flagConfig, Source: URI, Type: sync.ALL}, data)
+	require.Eventually(t, ss.IsReady, time.Second, time.Millisecond)
+
+	require.Nil(t, ss.ReSync(ctx, dataSync))
+	require.Equal(t, data, <-dataSync)
+
+	cancel()
+	require.Nil(t, <-done)
+}
+
+func TestStdinSyncInvalid(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		err   string
+	}{
+		"empty":         {input: "", err: "unexpected end of JSON input"},
+		"malformed":     {input: `{"flags": {`, err: "unexpected end of JSON input"},
+		"missing flags": {input: `{"$schema": "https://flagd.dev/schema/v0/flags.json"}`, err: "missing flags"},
+		"flags array":   {input: `{"flags": []}`, err: "flags: json: cannot unmarshal array"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ss := &Sync{Reader: strings.NewReader(tt.input), Logger: logger.NewLogger(nil, false)}
+			err := ss.Init(context.Background())
+			require.ErrorContains(t, err, "invalid flag configuration on stdin: "+tt.err)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/runtime"
@@ -15,21 +16,113 @@ import (
 )
 
 const (
-	bearerTokenFlagName    = "bearer-token"
-	corsFlagName           = "cors-origin"
-	evaluatorFlagName      = "evaluator"
-	logFormatFlagName      = "log-format"
-	metricsPortFlagName    = "metrics-port"
-	portFlagName           = "port"
-	providerArgsFlagName   = "sync-provider-args"
-	serverCertPathFlagName = "server-cert-path"
-	serverKeyPathFlagName  = "server-key-path"
-	socketPathFlagName     = "socket-path"
-	sourcesFlagName        = "sources"
-	syncProviderFlagName   = "sync-provider"
-	uriFlagName            = "uri"
+	accessLogFlagName         = "access-log"
+	accessLogFormatFlagName   = "access-log-format"
+	adminAPIFlagName          = "admin-api"
+	allowedContextFlagName    = "allowed-context-keys"
+	authTokensFlagName        = "auth-tokens"
+	bearerTokenFlagName       = "bearer-token"
+	breakerCooldownFlagName   = "circuit-breaker-cooldown"
+	breakerFailuresFlagName   = "circuit-breaker-failures"
+	breakerSlowFlagName       = "circuit-breaker-slow-evaluation"
+	bucketingHashFlagName     = "bucketing-hash"
+	canaryPercentageFlagName  = "canary-percentage"
+	canarySoakPeriodFlagName  = "canary-soak-period"
+	canaryURIFlagName         = "canary-uri"
+	configAgeFlagName         = "config-age-metadata"
+	contextCoercionFlagName   = "context-coercion"
+	contextHeadersFlagName    = "context-headers"
+	contextKeysFlagName       = "context-key-normalization"
+	contextMetadataFlagName   = "context-keys-metadata"
+	contextSamplesFlagName    = "context-samples"
+	corsFlagName              = "cors-origin"
+	defaultVariantFlagName    = "default-variant-fallback"
+	disableResolveFlagName    = "disable-resolve-types"
+	disabledFlagsFlagName     = "disabled-flags"
+	duplicateKeysFlagName     = "duplicate-flag-keys"
+	emptyConfigFlagName       = "empty-configuration"
+	emptyFlagKeysFlagName     = "empty-flag-keys"
+	evaluationHashFlagName    = "evaluation-hash"
+	evaluatorFlagName         = "evaluator"
+	fallbackChainFlagName     = "fallback-chain"
+	fallbackRetryFlagName     = "source-fallback-retry-interval"
+	fallbackTimeoutFlagName   = "source-fallback-timeout"
+	fileDebounceFlagName      = "file-sync-debounce"
+	flagKeyCharsFlagName      = "flag-key-characters"
+	flapThresholdFlagName     = "flap-threshold"
+	flapWindowFlagName        = "flap-window"
+	fractionalRemFlagName     = "fractional-remainder"
+	grpcWebFlagName           = "grpc-web"
+	invalidKeysFlagName       = "invalid-flag-keys"
+	largeIntegersFlagName     = "large-integers"
+	logContextKeysFlagName    = "log-context-keys"
+	logFormatFlagName         = "log-format"
+	logLevelsFlagName         = "log-levels"
+	maxConcurrentFlagName     = "max-concurrent-evaluations"
+	maxContextValueFlagName   = "max-context-value-bytes"
+	maxQueuedFlagName         = "max-queued-evaluations"
+	maxSubscribersFlagName    = "max-stream-subscribers"
+	maxVariantsFlagName       = "max-variants"
+	metricsDroppedFlagName    = "metrics-dropped-flag-keys"
+	metricsPortFlagName       = "metrics-port"
+	metricsReasonsFlagName    = "metrics-max-reasons"
+	metricsStreamFlagName     = "metrics-stream-interval"
+	namespaceFlagName         = "namespace-fallthrough"
+	namespaceSepFlagName      = "namespace-separator"
+	notFoundGraceFlagName     = "not-found-grace-period"
+	otelCollectorFlagName     = "otel-collector-uri"
+	overrideSecretFlagName    = "override-token-secret"
+	oversizedCtxFlagName      = "oversized-context-values"
+	pinnedFlagsFlagName       = "pinned-flags"
+	portFlagName              = "port"
+	providerArgsFlagName      = "sync-provider-args"
+	replicaRPCsFlagName       = "read-replica-rpcs"
+	replicaStalenessFlagName  = "read-replica-staleness"
+	ruleStatisticsFlagName    = "rule-statistics"
+	ruleWarmupFlagName        = "rule-warmup"
+	schemaMismatchFlagName    = "schema-mismatch"
+	serverCertPathFlagName    = "server-cert-path"
+	serverCompressFlagName    = "server-compress-min-bytes"
+	serverHeaderFlagName      = "server-max-header-bytes"
+	serverIdleFlagName        = "server-idle-timeout"
+	serveAfterStartupFlagName = "serve-after-startup"
+	serverKeyPathFlagName     = "server-key-path"
+	serverReadFlagName        = "server-read-timeout"
+	serverRequestFlagName     = "server-max-request-bytes"
+	serverWriteFlagName       = "server-write-timeout"
+	signatureKeyFlagName      = "signature-public-key"
+	socketPathFlagName        = "socket-path"
+	socketWithPortFlagName    = "socket-with-port"
+	sourceDisconnectFlagName  = "source-disconnect-threshold"
+	sourcesFlagName           = "sources"
+	staleThresholdFlagName    = "stale-threshold"
+	startupReadinessFlagName  = "startup-readiness"
+	storeCompressionFlagName  = "store-compression"
+	strictContextFlagName     = "strict-context-conversion"
+	syncProviderFlagName      = "sync-provider"
+	syncTimeoutFlagName       = "sync-timeout"
+	targetingFallbackFlagName = "targeting-key-fallback"
+	targetingSaltFlagName     = "targeting-key-salt"
+	targetingReorderFlagName  = "targeting-reordering"
+	templateMissingFlagName   = "template-missing-keys"
+	tenantContextKeyFlagName  = "tenant-context-key"
+	tenantURIFlagName         = "tenant-uri"
+	traceDefaultFlagName      = "trace-sampling-default"
+	traceSamplingFlagName     = "trace-sampling"
+	undefinedVariantsFlagName = "undefined-variants"
+	unknownReasonsFlagName    = "unknown-reasons"
+	unsupportedCtxFlagName    = "unsupported-context-values"
+	uriFlagName               = "uri"
+	validationWorkersFlagName = "validation-workers"
+	variantTypesFlagName      = "variant-type-mismatch"
+	variantWindowFlagName     = "variant-distribution-window"
+	verboseFlagsFlagName      = "verbose-flags"
+	webhookBatchFlagName      = "evaluation-webhook-batch-size"
+	webhookIntervalFlagName   = "evaluation-webhook-interval"
+	webhookURLFlagName        = "evaluation-webhook-url"
 )
 
+// nolint: funlen
 func init() {
 	flags := startCmd.Flags()
 
@@ -41,15 +134,31 @@ func init() {
 	flags.StringP(socketPathFlagName, "d", "", "Flagd socket path. "+
 		"With grpc the service will become available on this address. "+
 		"With http(s) the grpc-gateway proxy will use this address internally.")
+	flags.Bool(socketWithPortFlagName, false, "Listen on --port alongside --socket-path, e.g. serving local "+
+		"tooling on the socket and remote clients over TCP")
 	flags.StringP(evaluatorFlagName, "e", "json", "DEPRECATED: Set an evaluator e.g. json, yaml/yml."+
 		"Please note that yaml/yml and json evaluations work the same (yaml/yml files are converted to json internally)")
-	flags.StringP(serverCertPathFlagName, "c", "", "Server side tls certificate path")
-	flags.StringP(serverKeyPathFlagName, "k", "", "Server side tls key path")
+	flags.StringP(serverCertPathFlagName, "c", "", "Server side tls certificate path, set along with --server-key-path")
+	flags.StringP(serverKeyPathFlagName, "k", "", "Server side tls key path, set along with --server-cert-path")
+	flags.Duration(serverReadFlagName, 10*time.Second, "Maximum time taken reading a request to the flag "+
+		"evaluation service, including its body")
+	flags.Duration(serverWriteFlagName, 0, "Maximum time taken writing a response of the flag evaluation "+
+		"service, closing event streams past it, unbounded when 0")
+	flags.Duration(serverIdleFlagName, 2*time.Minute, "Maximum time a connection to the flag evaluation service "+
+		"stays open without requests")
+	flags.Int(serverCompressFlagName, 1<<10, "Minimum size in bytes of the responses of the flag evaluation "+
+		"service compressed for clients accepting compression, smaller responses are sent uncompressed")
+	flags.Int(serverHeaderFlagName, 64<<10, "Maximum size in bytes of the headers of a request to the flag "+
+		"evaluation service")
+	flags.Int64(serverRequestFlagName, 4<<20, "Maximum size in bytes of the body of a request to the flag "+
+		"evaluation service, larger requests are rejected")
 	flags.StringToStringP(providerArgsFlagName,
 		"a", nil, "DEPRECATED: Sync provider arguments as key values separated by =")
 	flags.StringSliceP(
 		uriFlagName, "f", []string{}, "Set a sync provider uri to read data from, this can be a filepath,"+
-			"url (http and grpc) or FeatureFlagConfiguration. When flag keys are duplicated across multiple providers the "+
+			"url (http and grpc), consul key (consul(+https)://host:port/key), oci artifact (oci://registry/repository:tag), "+
+			"s3 object (s3://bucket/key), stdin or FeatureFlagConfiguration. "+
+			"When flag keys are duplicated across multiple providers the "+
 			"merge priority follows the index of the flag arguments, as such flags from the uri at index 0 take the "+
 			"lowest precedence, with duplicated keys being overwritten by those from the uri at index 1. "+
 			"Please note that if you are using filepath, flagd only supports files with `.yaml/.yml/.json` extension.",
@@ -57,6 +166,17 @@ func init() {
 	flags.StringP(
 		bearerTokenFlagName, "b", "", "DEPRECATED: Superseded by --sources.")
 	flags.StringSliceP(corsFlagName, "C", []string{}, "CORS allowed origins, * will allow all origins")
+	flags.Bool(accessLogFlagName, false, "Log a line per RPC and per request to the http endpoints, whether it "+
+		"succeeded or not, with its method, flag key, client, status and duration, apart from the other logs")
+	flags.String(accessLogFormatFlagName, "json", "Format of the access log, e.g. json or console")
+	flags.Bool(adminAPIFlagName, false, "Serve the admin endpoints of flag management interfaces, "+
+		"such as the variants of a flag")
+	flags.Int(contextSamplesFlagName, 1000, "Number of recent evaluation contexts sampled, with a hashed "+
+		"targeting key, to compare candidate configurations through the admin API. Contexts aren't sampled when 0")
+	flags.StringSlice(authTokensFlagName, []string{}, "Bearer tokens accepted in the authorization header of "+
+		"flag evaluation requests, several tokens allow rotating them. Requests aren't authenticated when unset")
+	flags.Bool(grpcWebFlagName, true, "Serve gRPC-web requests of browser clients alongside gRPC requests, "+
+		"allowed origins are set by --cors-origin")
 	flags.StringP(
 		syncProviderFlagName, "y", "", "DEPRECATED: Set a sync provider e.g. filepath or remote",
 	)
@@ -66,20 +186,282 @@ func init() {
 			"https://github.com/open-feature/flagd/blob/main/docs/configuration/configuration.md#sync-provider-customisation",
 	)
 	flags.StringP(logFormatFlagName, "z", "console", "Set the logging format, e.g. console or json ")
+	flags.Bool(defaultVariantFlagName, false, "Fall back to the first variant of flags lacking a valid default "+
+		"variant, with a warning, instead of rejecting their configuration")
+	flags.String(contextCoercionFlagName, "off", "Conversion of evaluation context values compared by targeting "+
+		"rules to the type of the comparison, either 'off', 'coerce' converting numeric and boolean strings or "+
+		"'strict' failing evaluations comparing values of another type")
+	flags.String(contextKeysFlagName, "", "Normalization of evaluation context keys and the keys referenced by "+
+		"targeting rules, e.g. lowercase for case-insensitive matching, keys match exactly when unset")
+	flags.String(duplicateKeysFlagName, "last-wins", "Handling of flag keys defined by more than one source, "+
+		"or more than once by a source, e.g. error, first-wins or last-wins in the order of the sources")
+	flags.String(flagKeyCharsFlagName, "", "Pattern matching each allowed character of flag keys, e.g. "+
+		"'[A-Za-z0-9_.-]', flag keys aren't checked when empty")
+	flags.String(invalidKeysFlagName, "error", "Handling of flag keys with characters outside of "+
+		"--flag-key-characters, either 'error' rejecting the configuration or 'sanitize' replacing them with '_'")
+	flags.String(bucketingHashFlagName, "xxh3", "Hash function bucketing the context values of fractional "+
+		"evaluations, either 'xxh3', 'murmur3' or 'fnv1a', changing it reassigns most values to other buckets")
+	flags.String(largeIntegersFlagName, "error", "Handling of integers of object variants beyond 2^53, "+
+		"which can't be resolved exactly, either 'error' rejecting the flag or 'string' encoding them as strings")
+	flags.String(schemaMismatchFlagName, "error", "Handling of object variants which don't conform to the "+
+		"schema of their flag, either 'error' rejecting the flag or 'warn' loading it with a warning")
+	flags.String(variantTypesFlagName, "error", "Handling of variants whose value isn't of the type of the "+
+		"default variant of their flag, either 'error' rejecting the flag or 'warn' loading it without them")
+	flags.String(undefinedVariantsFlagName, "fallback", "Handling of targeting rules resolving variants which "+
+		"their flag doesn't define, either 'fallback' to the default variant with a warning or 'error' rejecting the "+
+		"flag and failing the evaluation")
+	flags.String(fractionalRemFlagName, "error", "Handling of fractional evaluations whose percentages sum to "+
+		"under 100, either 'error' rejecting the flag or 'default' resolving the default variant for the remaining "+
+		"buckets. Percentages summing to over 100 are always rejected.")
+	flags.String(templateMissingFlagName, "keep", "Handling of the placeholders of templated flags whose "+
+		"context key is missing, either 'keep' leaving the placeholder or 'error' failing the evaluation")
+	flags.StringToString(logLevelsFlagName, nil, "Log levels of subsystems, as subsystem=level pairs, e.g. "+
+		"sync=debug,evaluation=warn, the subsystems being sync, evaluation, server and audit, other logs are "+
+		"logged at the level of --debug")
+	flags.StringToString(contextHeadersFlagName, nil, "Request headers merged into the evaluation context of "+
+		"resolve requests as header=contextKey pairs, e.g. gRPC metadata set by a gateway, the context of the "+
+		"request taking precedence")
+	flags.StringSlice(logContextKeysFlagName, []string{}, "Evaluation context keys whose values are safe to log, "+
+		"the values of other keys are redacted")
+	flags.Duration(notFoundGraceFlagName, 0, "Period following the first request of a flag which isn't defined "+
+		"during which it's reported as not ready rather than not found, so a source about to define it can sync, "+
+		"disabled when 0")
+	flags.StringSlice(allowedContextFlagName, []string{}, "Context keys targeting rules may reference, along with "+
+		"their nested paths, configurations referencing other keys are rejected when loaded, any key when unset")
+	flags.StringSlice(pinnedFlagsFlagName, []string{}, "Flags whose stored definition is kept when a reload "+
+		"changes or removes them, applying the pending definition only through the admin API, e.g. kill switches")
+	flags.StringSlice(replicaRPCsFlagName, []string{}, "RPCs served from a read replica of the flag store rather "+
+		"than the live flags, among 'bulk' (ResolveAll and batch evaluations), 'list' (the flag variants of the admin "+
+		"API) and 'what-if' (the what-if evaluations of the admin API), so they don't contend with evaluations")
+	flags.Duration(replicaStalenessFlagName, 0, "Maximum staleness of the read replica of --read-replica-rpcs, "+
+		"refreshed by its first evaluation once older, the rpcs are served from the live flags when 0")
+	flags.StringSlice(verboseFlagsFlagName, []string{}, "Flags whose evaluations are logged at the debug level "+
+		"without --debug, along with their evaluation context restricted to --log-context-keys if set, replaceable at "+
+		"runtime through the admin API")
+	flags.StringSlice(fallbackChainFlagName, []string{}, "Levels of the chain resolving flags, applied in order among "+
+		"'override', 'targeting', 'flag-default', 'client-default' (the defaultValue of the evaluation context, with "+
+		"the CLIENT_DEFAULT reason) and 'zero-value' (the zero value of the type, with the ZERO_VALUE reason), the "+
+		"last two resolving flags which fail to resolve, override, targeting and flag-default when unset")
+	flags.String(overrideSecretFlagName, "", "Secret verifying the HS256 override tokens of evaluation contexts, "+
+		"which pin flags to variants with the OVERRIDE reason, override tokens are ignored when empty")
+	flags.String(targetingFallbackFlagName, "none", "Targeting key of evaluation contexts without one, so fractional "+
+		"evaluations bucketing by it still distribute them, either none, uuid, generating one per request, or "+
+		"context:<key>, the value of a context key, e.g. context:sessionId")
+	flags.String(targetingSaltFlagName, "", "Salt of the HMAC-SHA256 hash of the targeting keys surfaced in logs "+
+		"and evaluation events, which never include the raw key")
+	flags.StringSlice(disableResolveFlagName, []string{}, "Resolve types whose endpoints are disabled, "+
+		"e.g. all, boolean, string, int, float or object")
+	flags.StringSlice(canaryURIFlagName, []string{}, "Set a sync provider uri to read a candidate configuration "+
+		"from, the candidate serves --canary-percentage of the evaluations bucketed by targeting key, "+
+		"it is promoted after --canary-soak-period or on SIGUSR1")
+	flags.Int(canaryPercentageFlagName, 10, "Percentage of evaluations served by the candidate configuration")
+	flags.Duration(canarySoakPeriodFlagName, 0, "Duration after which the candidate configuration is promoted, "+
+		"disabled when 0")
+	flags.StringSlice(tenantURIFlagName, []string{}, "Set a tenant=uri sync provider uri to read the configuration "+
+		"of a tenant from, evaluations of the tenant fall back to the --uri configuration for missing flags")
+	flags.String(tenantContextKeyFlagName, "tenantId", "Evaluation context key identifying the tenant of "+
+		"evaluations, evaluations of unknown tenants are served by the --uri configuration")
+	flags.Duration(fileDebounceFlagName, 0, "Coalesce the changes of file sources within the window, e.g. 500ms, "+
+		"into a single reload of the latest content, changes are reloaded immediately when 0")
+	flags.Duration(fallbackTimeoutFlagName, 10*time.Second, "Time a source with fallbacks, or one of its "+
+		"fallbacks, takes to load its configuration before the next fallback is synced")
+	flags.Duration(fallbackRetryFlagName, time.Minute, "Interval the sources preferred over the active fallback "+
+		"of a source are retried at")
+	flags.Duration(syncTimeoutFlagName, 10*time.Second, "Timeout of the requests of remote grpc and http sources, "+
+		"and of the first message of grpc sync streams, which are reconnected once it elapses")
+	flags.Int(flapThresholdFlagName, 0, "Hold the value of a flag whose definition changes more than this number "+
+		"of times within --flap-window, logging a warning, until it stabilizes, disabled when 0")
+	flags.Duration(flapWindowFlagName, time.Minute, "Window of the changes of flag definitions counted by "+
+		"--flap-threshold, a held flag stabilizes once its definition hasn't changed for the window")
+	flags.Int(breakerFailuresFlagName, 0, "Short-circuit a flag to its default variant with the ERROR reason "+
+		"for --circuit-breaker-cooldown after its targeting failed, or was slow, this number of times in a row, "+
+		"disabled when 0")
+	flags.Duration(breakerSlowFlagName, 0, "Count evaluations of targeting rules taking longer, e.g. 50ms, as "+
+		"failures of the circuit breaker of their flag, slow evaluations aren't failures when 0")
+	flags.Duration(breakerCooldownFlagName, 30*time.Second, "Duration a circuit breaker stays open before a "+
+		"trial evaluation of the targeting of its flag decides whether it closes")
+	flags.Duration(sourceDisconnectFlagName, 0, "Report flagd as not ready once a remote grpc or http source "+
+		"is unreachable for longer, e.g. 5m, while its last configuration keeps being served, disabled when 0")
+	flags.Bool(startupReadinessFlagName, false, "Report flagd as ready only once every source has applied its "+
+		"initial configuration, validated and with its targeting rules warmed up, also served by /startupz")
+	flags.String(emptyConfigFlagName, "serve", "Handling of initial configurations without flags, either 'serve' "+
+		"reporting flagd as ready, 'not-ready' until a flag is loaded or 'fail' stopping flagd once every source "+
+		"applied its initial configuration")
+	flags.Bool(serveAfterStartupFlagName, false, "Delay serving evaluations and probes until every source has "+
+		"applied its initial configuration")
+	flags.Duration(staleThresholdFlagName, 0, "Add stale: true to the metadata of the resolutions served while a "+
+		"remote grpc or http source is unreachable for longer, e.g. 5m, disabled when 0")
+	flags.Bool(configAgeFlagName, false, "Add the load time (configLoadedAt) and age in seconds (configAgeSeconds) "+
+		"of the served configuration to the metadata of the resolutions, so clients can detect missed reloads")
+	flags.String(unknownReasonsFlagName, "normalize", "Response of evaluation reasons which aren't part of "+
+		"the flagd schema, either normalize, responding UNKNOWN with a warning, or pass-through")
+	flags.String(disabledFlagsFlagName, "include", "Handling of disabled flags in ResolveAll responses, either "+
+		"include, responding them with the DISABLED reason and without a value, or omit, leaving them out")
+	flags.String(unsupportedCtxFlagName, "drop", "Handling of evaluation context values which aren't "+
+		"representable as json, e.g. non-finite numbers, either drop, evaluating without them with a warning, "+
+		"or error, rejecting the request")
+	flags.String(emptyFlagKeysFlagName, "reject", "Handling of resolve requests whose flag key is empty or only "+
+		"whitespace, either reject, responding an invalid argument error, or not-found, evaluating them")
+	flags.Int(maxContextValueFlagName, 0, "Maximum size in bytes of each evaluation context value, strings "+
+		"counting their length and other values their json encoding, unbounded when 0")
+	flags.String(oversizedCtxFlagName, "error", "Handling of evaluation context values exceeding "+
+		"--max-context-value-bytes, either error, rejecting the request, or drop, evaluating without them with a "+
+		"warning")
+	flags.Bool(strictContextFlagName, false, "Reject evaluation contexts holding any value requiring a lossy "+
+		"conversion, i.e. unsupported values and integers beyond 2^53, with an invalid context error")
+	flags.Bool(contextMetadataFlagName, false, "Add the evaluation context keys read by each flag to its "+
+		"resolution metadata, so clients can prune the context they send for the flag")
+	flags.Bool(evaluationHashFlagName, false, "Add a stable hash of the flag key, variant, reason and relevant "+
+		"evaluation context of each evaluation to its resolution metadata, e.g. to dedupe identical decisions")
+	flags.String(webhookURLFlagName, "", "URL successful evaluations are posted to in batches, with their flag "+
+		"key, variant, reason, hashed targeting key and timestamp, disabled when empty")
+	flags.Int(webhookBatchFlagName, 100, "Maximum number of evaluations posted to --evaluation-webhook-url at once")
+	flags.Duration(webhookIntervalFlagName, time.Second, "Maximum delay of evaluations before they're posted to "+
+		"--evaluation-webhook-url")
+	flags.StringSlice(metricsDroppedFlagName, nil, "Flags whose metrics are recorded without the flag_key label, "+
+		"aggregated together, e.g. high-cardinality flags generated per customer")
+	flags.Int(metricsReasonsFlagName, 0, "Maximum distinct reason labels of the flag_evaluations metric, further "+
+		"reasons being recorded as other, not capped when 0")
+	flags.Duration(metricsStreamFlagName, 0, "Stream a snapshot of the evaluation, stream and source metrics at "+
+		"the interval, e.g. 1s, over the StreamMetrics RPC of the evaluation service, not served when 0")
+	flags.String(otelCollectorFlagName, "", "gRPC endpoint of the OpenTelemetry collector traces of evaluations "+
+		"are exported to, e.g. localhost:4317, evaluations aren't traced when empty")
+	flags.StringToString(traceSamplingFlagName, nil, "Fraction of the evaluations of flags which are traced, as "+
+		"flag=rate pairs, e.g. highVolumeFlag=0.01, taking precedence over the traceSampling of the flags")
+	flags.Float64(traceDefaultFlagName, 1, "Fraction of the evaluations which are traced for flags without a rate, "+
+		"evaluations whose caller propagates a sampling decision follow it")
+	flags.Duration(variantWindowFlagName, 0, "Count the variants returned by each flag over the window, e.g. 5m, "+
+		"served by the admin API and as metrics, disabled when 0")
+	flags.Int(maxConcurrentFlagName, 0, "Maximum number of evaluations served at once, further evaluations "+
+		"are queued, unbounded when 0")
+	flags.Int(maxQueuedFlagName, 0, "Maximum number of evaluations queued by --max-concurrent-evaluations, "+
+		"further evaluations are rejected with ResourceExhausted, unbounded when 0")
+	flags.Int(maxSubscribersFlagName, 0, "Maximum number of concurrent event stream subscribers, "+
+		"unbounded when 0")
+	flags.Bool(storeCompressionFlagName, false, "Store the large string and object variants of flags compressed "+
+		"in memory, trading the CPU time of decompressing them on each evaluation for memory")
+	flags.Int(maxVariantsFlagName, 0, "Maximum number of variants of a flag, flags with more variants are "+
+		"rejected while the other flags of their configuration are loaded, unbounded when 0")
+	flags.String(signatureKeyFlagName, "", "Path of the PEM encoded ed25519 public key verifying the detached "+
+		"signatures of file and HTTP flag configurations, configurations without a valid signature are refused")
+	flags.Bool(ruleStatisticsFlagName, false, "Count the matched branch of the top-level if of the targeting "+
+		"rule of each evaluation and the conditions it evaluated, served by the admin API and as metrics")
+	flags.Bool(namespaceFlagName, false, "Resolve flags which aren't defined through their closest defined "+
+		"namespace, e.g. team.feature then team for team.feature.x")
+	flags.String(namespaceSepFlagName, ".", "Separator of the namespaces of flag keys resolved by "+
+		"--namespace-fallthrough")
+	flags.Bool(ruleWarmupFlagName, false, "Warm up the targeting rules of new flag configurations before "+
+		"swapping them in, so the first evaluations of the new configuration don't parse rules")
+	flags.Bool(targetingReorderFlagName, false, "Order the exclusive conditions of the top-level if of targeting "+
+		"rules by the evaluations they matched while the previous configuration was served, when loading a "+
+		"configuration")
+	flags.Int(validationWorkersFlagName, 0, "Number of workers validating flag configurations concurrently, "+
+		"defaults to the number of available CPUs")
 
+	_ = viper.BindPFlag(accessLogFlagName, flags.Lookup(accessLogFlagName))
+	_ = viper.BindPFlag(accessLogFormatFlagName, flags.Lookup(accessLogFormatFlagName))
+	_ = viper.BindPFlag(adminAPIFlagName, flags.Lookup(adminAPIFlagName))
+	_ = viper.BindPFlag(allowedContextFlagName, flags.Lookup(allowedContextFlagName))
+	_ = viper.BindPFlag(authTokensFlagName, flags.Lookup(authTokensFlagName))
 	_ = viper.BindPFlag(bearerTokenFlagName, flags.Lookup(bearerTokenFlagName))
+	_ = viper.BindPFlag(breakerCooldownFlagName, flags.Lookup(breakerCooldownFlagName))
+	_ = viper.BindPFlag(breakerFailuresFlagName, flags.Lookup(breakerFailuresFlagName))
+	_ = viper.BindPFlag(breakerSlowFlagName, flags.Lookup(breakerSlowFlagName))
+	_ = viper.BindPFlag(canaryPercentageFlagName, flags.Lookup(canaryPercentageFlagName))
+	_ = viper.BindPFlag(canarySoakPeriodFlagName, flags.Lookup(canarySoakPeriodFlagName))
+	_ = viper.BindPFlag(canaryURIFlagName, flags.Lookup(canaryURIFlagName))
+	_ = viper.BindPFlag(bucketingHashFlagName, flags.Lookup(bucketingHashFlagName))
+	_ = viper.BindPFlag(contextCoercionFlagName, flags.Lookup(contextCoercionFlagName))
+	_ = viper.BindPFlag(contextHeadersFlagName, flags.Lookup(contextHeadersFlagName))
+	_ = viper.BindPFlag(contextMetadataFlagName, flags.Lookup(contextMetadataFlagName))
+	_ = viper.BindPFlag(contextKeysFlagName, flags.Lookup(contextKeysFlagName))
+	_ = viper.BindPFlag(contextSamplesFlagName, flags.Lookup(contextSamplesFlagName))
 	_ = viper.BindPFlag(corsFlagName, flags.Lookup(corsFlagName))
+	_ = viper.BindPFlag(defaultVariantFlagName, flags.Lookup(defaultVariantFlagName))
+	_ = viper.BindPFlag(disableResolveFlagName, flags.Lookup(disableResolveFlagName))
+	_ = viper.BindPFlag(duplicateKeysFlagName, flags.Lookup(duplicateKeysFlagName))
+	_ = viper.BindPFlag(evaluationHashFlagName, flags.Lookup(evaluationHashFlagName))
 	_ = viper.BindPFlag(evaluatorFlagName, flags.Lookup(evaluatorFlagName))
+	_ = viper.BindPFlag(fallbackRetryFlagName, flags.Lookup(fallbackRetryFlagName))
+	_ = viper.BindPFlag(fallbackTimeoutFlagName, flags.Lookup(fallbackTimeoutFlagName))
+	_ = viper.BindPFlag(fileDebounceFlagName, flags.Lookup(fileDebounceFlagName))
+	_ = viper.BindPFlag(flagKeyCharsFlagName, flags.Lookup(flagKeyCharsFlagName))
+	_ = viper.BindPFlag(flapThresholdFlagName, flags.Lookup(flapThresholdFlagName))
+	_ = viper.BindPFlag(flapWindowFlagName, flags.Lookup(flapWindowFlagName))
+	_ = viper.BindPFlag(fractionalRemFlagName, flags.Lookup(fractionalRemFlagName))
+	_ = viper.BindPFlag(grpcWebFlagName, flags.Lookup(grpcWebFlagName))
+	_ = viper.BindPFlag(invalidKeysFlagName, flags.Lookup(invalidKeysFlagName))
+	_ = viper.BindPFlag(largeIntegersFlagName, flags.Lookup(largeIntegersFlagName))
+	_ = viper.BindPFlag(logContextKeysFlagName, flags.Lookup(logContextKeysFlagName))
+	_ = viper.BindPFlag(verboseFlagsFlagName, flags.Lookup(verboseFlagsFlagName))
 	_ = viper.BindPFlag(logFormatFlagName, flags.Lookup(logFormatFlagName))
+	_ = viper.BindPFlag(logLevelsFlagName, flags.Lookup(logLevelsFlagName))
+	_ = viper.BindPFlag(maxConcurrentFlagName, flags.Lookup(maxConcurrentFlagName))
+	_ = viper.BindPFlag(maxContextValueFlagName, flags.Lookup(maxContextValueFlagName))
+	_ = viper.BindPFlag(maxQueuedFlagName, flags.Lookup(maxQueuedFlagName))
+	_ = viper.BindPFlag(maxSubscribersFlagName, flags.Lookup(maxSubscribersFlagName))
+	_ = viper.BindPFlag(maxVariantsFlagName, flags.Lookup(maxVariantsFlagName))
 	_ = viper.BindPFlag(metricsPortFlagName, flags.Lookup(metricsPortFlagName))
+	_ = viper.BindPFlag(metricsStreamFlagName, flags.Lookup(metricsStreamFlagName))
+	_ = viper.BindPFlag(metricsDroppedFlagName, flags.Lookup(metricsDroppedFlagName))
+	_ = viper.BindPFlag(metricsReasonsFlagName, flags.Lookup(metricsReasonsFlagName))
+	_ = viper.BindPFlag(namespaceFlagName, flags.Lookup(namespaceFlagName))
+	_ = viper.BindPFlag(namespaceSepFlagName, flags.Lookup(namespaceSepFlagName))
+	_ = viper.BindPFlag(notFoundGraceFlagName, flags.Lookup(notFoundGraceFlagName))
+	_ = viper.BindPFlag(fallbackChainFlagName, flags.Lookup(fallbackChainFlagName))
+	_ = viper.BindPFlag(otelCollectorFlagName, flags.Lookup(otelCollectorFlagName))
+	_ = viper.BindPFlag(overrideSecretFlagName, flags.Lookup(overrideSecretFlagName))
+	_ = viper.BindPFlag(oversizedCtxFlagName, flags.Lookup(oversizedCtxFlagName))
+	_ = viper.BindPFlag(pinnedFlagsFlagName, flags.Lookup(pinnedFlagsFlagName))
 	_ = viper.BindPFlag(portFlagName, flags.Lookup(portFlagName))
 	_ = viper.BindPFlag(providerArgsFlagName, flags.Lookup(providerArgsFlagName))
+	_ = viper.BindPFlag(replicaRPCsFlagName, flags.Lookup(replicaRPCsFlagName))
+	_ = viper.BindPFlag(replicaStalenessFlagName, flags.Lookup(replicaStalenessFlagName))
+	_ = viper.BindPFlag(ruleStatisticsFlagName, flags.Lookup(ruleStatisticsFlagName))
+	_ = viper.BindPFlag(ruleWarmupFlagName, flags.Lookup(ruleWarmupFlagName))
+	_ = viper.BindPFlag(schemaMismatchFlagName, flags.Lookup(schemaMismatchFlagName))
 	_ = viper.BindPFlag(serverCertPathFlagName, flags.Lookup(serverCertPathFlagName))
 	_ = viper.BindPFlag(serverKeyPathFlagName, flags.Lookup(serverKeyPathFlagName))
+	_ = viper.BindPFlag(serverReadFlagName, flags.Lookup(serverReadFlagName))
+	_ = viper.BindPFlag(serverWriteFlagName, flags.Lookup(serverWriteFlagName))
+	_ = viper.BindPFlag(serverIdleFlagName, flags.Lookup(serverIdleFlagName))
+	_ = viper.BindPFlag(serverCompressFlagName, flags.Lookup(serverCompressFlagName))
+	_ = viper.BindPFlag(serverHeaderFlagName, flags.Lookup(serverHeaderFlagName))
+	_ = viper.BindPFlag(serverRequestFlagName, flags.Lookup(serverRequestFlagName))
+	_ = viper.BindPFlag(signatureKeyFlagName, flags.Lookup(signatureKeyFlagName))
 	_ = viper.BindPFlag(socketPathFlagName, flags.Lookup(socketPathFlagName))
+	_ = viper.BindPFlag(socketWithPortFlagName, flags.Lookup(socketWithPortFlagName))
 	_ = viper.BindPFlag(syncProviderFlagName, flags.Lookup(syncProviderFlagName))
+	_ = viper.BindPFlag(syncTimeoutFlagName, flags.Lookup(syncTimeoutFlagName))
+	_ = viper.BindPFlag(sourceDisconnectFlagName, flags.Lookup(sourceDisconnectFlagName))
 	_ = viper.BindPFlag(sourcesFlagName, flags.Lookup(sourcesFlagName))
+	_ = viper.BindPFlag(staleThresholdFlagName, flags.Lookup(staleThresholdFlagName))
+	_ = viper.BindPFlag(configAgeFlagName, flags.Lookup(configAgeFlagName))
+	_ = viper.BindPFlag(startupReadinessFlagName, flags.Lookup(startupReadinessFlagName))
+	_ = viper.BindPFlag(serveAfterStartupFlagName, flags.Lookup(serveAfterStartupFlagName))
+	_ = viper.BindPFlag(emptyConfigFlagName, flags.Lookup(emptyConfigFlagName))
+	_ = viper.BindPFlag(emptyFlagKeysFlagName, flags.Lookup(emptyFlagKeysFlagName))
+	_ = viper.BindPFlag(storeCompressionFlagName, flags.Lookup(storeCompressionFlagName))
+	_ = viper.BindPFlag(strictContextFlagName, flags.Lookup(strictContextFlagName))
+	_ = viper.BindPFlag(targetingFallbackFlagName, flags.Lookup(targetingFallbackFlagName))
+	_ = viper.BindPFlag(targetingSaltFlagName, flags.Lookup(targetingSaltFlagName))
+	_ = viper.BindPFlag(targetingReorderFlagName, flags.Lookup(targetingReorderFlagName))
+	_ = viper.BindPFlag(templateMissingFlagName, flags.Lookup(templateMissingFlagName))
+	_ = viper.BindPFlag(tenantContextKeyFlagName, flags.Lookup(tenantContextKeyFlagName))
+	_ = viper.BindPFlag(tenantURIFlagName, flags.Lookup(tenantURIFlagName))
+	_ = viper.BindPFlag(traceDefaultFlagName, flags.Lookup(traceDefaultFlagName))
+	_ = viper.BindPFlag(traceSamplingFlagName, flags.Lookup(traceSamplingFlagName))
+	_ = viper.BindPFlag(undefinedVariantsFlagName, flags.Lookup(undefinedVariantsFlagName))
+	_ = viper.BindPFlag(unknownReasonsFlagName, flags.Lookup(unknownReasonsFlagName))
+	_ = viper.BindPFlag(disabledFlagsFlagName, flags.Lookup(disabledFlagsFlagName))
+	_ = viper.BindPFlag(unsupportedCtxFlagName, flags.Lookup(unsupportedCtxFlagName))
 	_ = viper.BindPFlag(uriFlagName, flags.Lookup(uriFlagName))
+	_ = viper.BindPFlag(validationWorkersFlagName, flags.Lookup(validationWorkersFlagName))
+	_ = viper.BindPFlag(variantTypesFlagName, flags.Lookup(variantTypesFlagName))
+	_ = viper.BindPFlag(variantWindowFlagName, flags.Lookup(variantWindowFlagName))
+	_ = viper.BindPFlag(webhookBatchFlagName, flags.Lookup(webhookBatchFlagName))
+	_ = viper.BindPFlag(webhookIntervalFlagName, flags.Lookup(webhookIntervalFlagName))
+	_ = viper.BindPFlag(webhookURLFlagName, flags.Lookup(webhookURLFlagName))
 }
 
 // startCmd represents the start command
@@ -96,11 +478,12 @@ var startCmd = &cobra.Command{
 		} else {
 			level = zapcore.InfoLevel
 		}
-		l, err := logger.NewZapLogger(level, viper.GetString(logFormatFlagName))
+		// the zap logger enables the debug level, logged for verbose flags only unless --debug is set
+		l, err := logger.NewZapLogger(zapcore.DebugLevel, viper.GetString(logFormatFlagName))
 		if err != nil {
 			log.Fatalf("can't initialize zap logger: %v", err)
 		}
-		logger := logger.NewLogger(l, Debug)
+		logger := logger.NewVerboseLogger(l, level, Debug)
 		rtLogger := logger.WithFields(zap.String("component", "start"))
 
 		rtLogger.Info(fmt.Sprintf("flagd version: %s (%s), built at: %s", Version, Commit, Date))
@@ -139,15 +522,116 @@ var startCmd = &cobra.Command{
 		}
 		syncProviders = append(syncProviders, syncProvidersFromConfig...)
 
+		canarySyncProviders, err := runtime.SyncProvidersFromURIs(viper.GetStringSlice(canaryURIFlagName))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		tenantSyncProviders, err := runtime.TenantSyncProvidersFromURIs(viper.GetStringSlice(tenantURIFlagName))
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		// Build Runtime -----------------------------------------------------------
 		rt, err := runtime.FromConfig(logger, runtime.Config{
-			CORS:              viper.GetStringSlice(corsFlagName),
-			MetricsPort:       viper.GetUint16(metricsPortFlagName),
-			ServiceCertPath:   viper.GetString(serverCertPathFlagName),
-			ServiceKeyPath:    viper.GetString(serverKeyPathFlagName),
-			ServicePort:       viper.GetUint16(portFlagName),
-			ServiceSocketPath: viper.GetString(socketPathFlagName),
-			SyncProviders:     syncProviders,
+			CanaryPercentage:            viper.GetInt(canaryPercentageFlagName),
+			CanarySoakPeriod:            viper.GetDuration(canarySoakPeriodFlagName),
+			AccessLog:                   viper.GetBool(accessLogFlagName),
+			AccessLogFormat:             viper.GetString(accessLogFormatFlagName),
+			AllowedContextKeys:          viper.GetStringSlice(allowedContextFlagName),
+			AuthTokens:                  viper.GetStringSlice(authTokensFlagName),
+			BucketingHash:               viper.GetString(bucketingHashFlagName),
+			CanarySyncProviders:         canarySyncProviders,
+			CircuitBreakerCooldown:      viper.GetDuration(breakerCooldownFlagName),
+			CircuitBreakerFailures:      viper.GetInt(breakerFailuresFlagName),
+			CircuitBreakerSlow:          viper.GetDuration(breakerSlowFlagName),
+			ConfigAgeMetadata:           viper.GetBool(configAgeFlagName),
+			ContextCoercion:             viper.GetString(contextCoercionFlagName),
+			ContextHeaders:              viper.GetStringMapString(contextHeadersFlagName),
+			ContextKeyNormalization:     viper.GetString(contextKeysFlagName),
+			ContextKeysMetadata:         viper.GetBool(contextMetadataFlagName),
+			ContextSamples:              viper.GetInt(contextSamplesFlagName),
+			CORS:                        viper.GetStringSlice(corsFlagName),
+			DefaultTraceSampling:        viper.GetFloat64(traceDefaultFlagName),
+			DefaultVariantFallback:      viper.GetBool(defaultVariantFlagName),
+			DisableGRPCWeb:              !viper.GetBool(grpcWebFlagName),
+			DisabledFlags:               viper.GetString(disabledFlagsFlagName),
+			DisabledResolveTypes:        viper.GetStringSlice(disableResolveFlagName),
+			DuplicateFlagKeys:           viper.GetString(duplicateKeysFlagName),
+			EmptyConfiguration:          viper.GetString(emptyConfigFlagName),
+			EmptyFlagKeys:               viper.GetString(emptyFlagKeysFlagName),
+			EnableAdminAPI:              viper.GetBool(adminAPIFlagName),
+			EvaluationHash:              viper.GetBool(evaluationHashFlagName),
+			EvaluationWebhookBatch:      viper.GetInt(webhookBatchFlagName),
+			EvaluationWebhookInterval:   viper.GetDuration(webhookIntervalFlagName),
+			EvaluationWebhookURL:        viper.GetString(webhookURLFlagName),
+			FallbackChain:               viper.GetStringSlice(fallbackChainFlagName),
+			FileSyncDebounce:            viper.GetDuration(fileDebounceFlagName),
+			FlagKeyCharacters:           viper.GetString(flagKeyCharsFlagName),
+			FlapThreshold:               viper.GetInt(flapThresholdFlagName),
+			FlapWindow:                  viper.GetDuration(flapWindowFlagName),
+			FractionalRemainder:         viper.GetString(fractionalRemFlagName),
+			InvalidFlagKeys:             viper.GetString(invalidKeysFlagName),
+			LargeIntegers:               viper.GetString(largeIntegersFlagName),
+			LogContextKeys:              viper.GetStringSlice(logContextKeysFlagName),
+			LogLevels:                   viper.GetStringMapString(logLevelsFlagName),
+			MaxConcurrentEvaluations:    viper.GetInt(maxConcurrentFlagName),
+			MaxContextValueBytes:        viper.GetInt(maxContextValueFlagName),
+			MaxQueuedEvaluations:        viper.GetInt(maxQueuedFlagName),
+			MaxStreamSubscribers:        viper.GetInt(maxSubscribersFlagName),
+			MaxVariants:                 viper.GetInt(maxVariantsFlagName),
+			NamespaceFallthrough:        viper.GetBool(namespaceFlagName),
+			NamespaceSeparator:          viper.GetString(namespaceSepFlagName),
+			MetricsDroppedFlagKeys:      viper.GetStringSlice(metricsDroppedFlagName),
+			MetricsMaxReasons:           viper.GetInt(metricsReasonsFlagName),
+			MetricsPort:                 viper.GetUint16(metricsPortFlagName),
+			MetricsStreamInterval:       viper.GetDuration(metricsStreamFlagName),
+			NotFoundGracePeriod:         viper.GetDuration(notFoundGraceFlagName),
+			OtelCollectorURI:            viper.GetString(otelCollectorFlagName),
+			OverrideTokenSecret:         viper.GetString(overrideSecretFlagName),
+			OversizedContextValues:      viper.GetString(oversizedCtxFlagName),
+			PinnedFlags:                 viper.GetStringSlice(pinnedFlagsFlagName),
+			ReadReplicaRPCs:             viper.GetStringSlice(replicaRPCsFlagName),
+			ReadReplicaStaleness:        viper.GetDuration(replicaStalenessFlagName),
+			RuleStatistics:              viper.GetBool(ruleStatisticsFlagName),
+			RuleWarmup:                  viper.GetBool(ruleWarmupFlagName),
+			SchemaMismatch:              viper.GetString(schemaMismatchFlagName),
+			ServiceCertPath:             viper.GetString(serverCertPathFlagName),
+			ServiceCompressMinBytes:     viper.GetInt(serverCompressFlagName),
+			ServiceIdleTimeout:          viper.GetDuration(serverIdleFlagName),
+			ServiceKeyPath:              viper.GetString(serverKeyPathFlagName),
+			ServiceMaxHeaderBytes:       viper.GetInt(serverHeaderFlagName),
+			ServiceMaxRequestBytes:      viper.GetInt64(serverRequestFlagName),
+			ServicePort:                 viper.GetUint16(portFlagName),
+			ServiceReadTimeout:          viper.GetDuration(serverReadFlagName),
+			ServiceSocketPath:           viper.GetString(socketPathFlagName),
+			ServiceSocketWithPort:       viper.GetBool(socketWithPortFlagName),
+			ServiceWriteTimeout:         viper.GetDuration(serverWriteFlagName),
+			SignaturePublicKeyPath:      viper.GetString(signatureKeyFlagName),
+			SourceDisconnectThreshold:   viper.GetDuration(sourceDisconnectFlagName),
+			StaleThreshold:              viper.GetDuration(staleThresholdFlagName),
+			StartupReadiness:            viper.GetBool(startupReadinessFlagName),
+			ServeAfterStartup:           viper.GetBool(serveAfterStartupFlagName),
+			StoreCompression:            viper.GetBool(storeCompressionFlagName),
+			StrictContextConversion:     viper.GetBool(strictContextFlagName),
+			SourceFallbackRetryInterval: viper.GetDuration(fallbackRetryFlagName),
+			SourceFallbackTimeout:       viper.GetDuration(fallbackTimeoutFlagName),
+			SyncProviders:               syncProviders,
+			SyncTimeout:                 viper.GetDuration(syncTimeoutFlagName),
+			TargetingKeyFallback:        viper.GetString(targetingFallbackFlagName),
+			TargetingKeySalt:            viper.GetString(targetingSaltFlagName),
+			TargetingReordering:         viper.GetBool(targetingReorderFlagName),
+			TemplateMissingKeys:         viper.GetString(templateMissingFlagName),
+			TenantContextKey:            viper.GetString(tenantContextKeyFlagName),
+			TenantSyncProviders:         tenantSyncProviders,
+			TraceSamplingRates:          viper.GetStringMapString(traceSamplingFlagName),
+			UndefinedVariants:           viper.GetString(undefinedVariantsFlagName),
+			UnknownReasons:              viper.GetString(unknownReasonsFlagName),
+			UnsupportedContextValues:    viper.GetString(unsupportedCtxFlagName),
+			ValidationWorkers:           viper.GetInt(validationWorkersFlagName),
+			VariantDistributionWindow:   viper.GetDuration(variantWindowFlagName),
+			VariantTypeMismatch:         viper.GetString(variantTypesFlagName),
+			VerboseFlags:                viper.GetStringSlice(verboseFlagsFlagName),
 		})
 		if err != nil {
 			rtLogger.Fatal(err.Error())